@@ -8,6 +8,7 @@ import (
 	"github.com/airplanedev/cli/pkg/analytics"
 	"github.com/airplanedev/cli/pkg/cmd/root"
 	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
 	"github.com/airplanedev/cli/pkg/trap"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/pkg/errors"
@@ -30,6 +31,13 @@ func main() {
 			return
 		}
 
+		var exitErr utils.ErrorExitCode
+		if errors.As(err, &exitErr) {
+			// The plugin or child process has already reported its errors.
+			analytics.Close()
+			os.Exit(exitErr.ExitCode())
+		}
+
 		logger.Debug("Error: %+v", err)
 		logger.Log("")
 		if exerr, ok := errors.Cause(err).(utils.ErrorExplained); ok {
@@ -40,6 +48,7 @@ func main() {
 			logger.Error(capitalize(errors.Cause(err).Error()))
 		}
 		logger.Log("")
+		print.Error(err)
 
 		analytics.ReportError(err)
 
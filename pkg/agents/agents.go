@@ -0,0 +1,62 @@
+// Package agents provides helpers for targeting the agents that execute runs.
+package agents
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+)
+
+// NoMatchingAgentError is returned when no live agent satisfies a
+// task's run constraints.
+type NoMatchingAgentError struct {
+	Constraints api.RunConstraints
+}
+
+// Error implementation.
+func (err NoMatchingAgentError) Error() string {
+	return fmt.Sprintf("no online agent matches the task's constraints (%s)", formatLabels(err.Constraints))
+}
+
+// ExplainError implementation.
+func (err NoMatchingAgentError) ExplainError() string {
+	return "Runs of this task would stay queued until a matching agent comes online.\n" +
+		"To see the registered agents and their labels:\n  airplane agents list"
+}
+
+// CheckConstraints verifies that at least one online agent matches
+// the given run constraints.
+//
+// Tasks without constraints can run on any agent, so they are never checked.
+// If the agents cannot be listed (f.e. the API key lacks access), the check is
+// skipped rather than blocking the caller.
+func CheckConstraints(ctx context.Context, client api.APIClient, constraints api.RunConstraints) error {
+	if constraints.IsEmpty() {
+		return nil
+	}
+
+	resp, err := client.ListAgents(ctx)
+	if err != nil {
+		logger.Debug("Skipping agent constraint check, unable to list agents: %v", err)
+		return nil
+	}
+
+	for _, agent := range resp.Agents {
+		if agent.Status == api.AgentOnline && constraints.Matches(agent.Labels) {
+			return nil
+		}
+	}
+
+	return &NoMatchingAgentError{Constraints: constraints}
+}
+
+func formatLabels(constraints api.RunConstraints) string {
+	labels := make([]string, len(constraints.Labels))
+	for i, l := range constraints.Labels {
+		labels[i] = l.Key + ":" + l.Value
+	}
+	return strings.Join(labels, ", ")
+}
@@ -0,0 +1,657 @@
+// Package apiserver serves the in-memory fake of the Airplane API from
+// package apitest over HTTP, so that the CLI itself can run without the
+// network: in integration tests, by plugin authors and for demos.
+//
+//	fake := apitest.New()
+//	fake.AddTask(api.Task{Slug: "hello", Name: "Hello"})
+//	srv := httptest.NewServer(apiserver.New(fake, apiserver.Options{}))
+//	defer srv.Close()
+//
+//	// Then: airplane --host <srv.URL> tasks execute hello
+//
+// Tasks, runs, configs, env groups and schedules are kept by the fake.
+// Builds are simulated by the server: uploads are accepted and discarded,
+// and every build succeeds with Options.BuildLogs as its logs.
+package apiserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/pkg/errors"
+)
+
+// Options configures a Server.
+type Options struct {
+	// LogInterval, if set, streams the logs of runs and builds: they are
+	// revealed one at a time, this long apart, as if they were being
+	// written, and runs and builds stay active until all of them are. If
+	// zero, runs and builds end straight away with all of their logs.
+	LogInterval time.Duration
+
+	// BuildLogs are the logs of every build. If nil, builds log a few lines
+	// like those of a remote builder.
+	BuildLogs []string
+}
+
+var defaultBuildLogs = []string{
+	"[builder] Starting build...",
+	"[builder] Building image...",
+	"[builder] Pushing image...",
+}
+
+// Server is an HTTP server of the Airplane API, backed by an apitest fake.
+//
+// It is safe for concurrent use.
+type Server struct {
+	fake   *apitest.Client
+	opts   Options
+	routes map[string]handler
+
+	mu     sync.Mutex
+	ids    int
+	builds map[string]*build
+}
+
+// build is a simulated build.
+type build struct {
+	api.Build
+	logs []api.LogItem
+}
+
+// handler handles a request to an endpoint, returning the response to
+// encode as JSON.
+type handler func(r *http.Request) (interface{}, error)
+
+var _ http.Handler = &Server{}
+
+// New returns a server of the fake's state.
+func New(fake *apitest.Client, opts Options) *Server {
+	if opts.BuildLogs == nil {
+		opts.BuildLogs = defaultBuildLogs
+	}
+	s := &Server{
+		fake:   fake,
+		opts:   opts,
+		builds: map[string]*build{},
+	}
+	s.routes = map[string]handler{
+		"GET /auth/info":          s.authInfo,
+		"POST /registry/getToken": s.getRegistryToken,
+
+		"POST /tasks/create":       s.createTask,
+		"POST /tasks/update":       s.updateTask,
+		"GET /tasks/get":           s.getTask,
+		"GET /tasks/list":          s.listTasks,
+		"GET /tasks/listRevisions": s.listTaskRevisions,
+		"GET /tasks/getUniqueSlug": s.getUniqueSlug,
+		"POST /tasks/pause":        s.pauseTask,
+		"POST /tasks/execute":      s.runTask,
+
+		"GET /runs/list":       s.listRuns,
+		"GET /runs/get":        s.getRun,
+		"GET /runs/getLogs":    s.getLogs,
+		"GET /runs/getOutputs": s.getOutputs,
+		"POST /runs/cancel":    s.cancelRun,
+
+		"POST /configs/get": s.getConfig,
+		"POST /configs/set": s.setConfig,
+		"GET /configs/list": s.listConfigs,
+
+		"GET /envGroups/list":    s.listEnvGroups,
+		"GET /envGroups/get":     s.getEnvGroup,
+		"POST /envGroups/set":    s.setEnvGroup,
+		"POST /envGroups/delete": s.deleteEnvGroup,
+
+		"POST /schedules/create": s.createSchedule,
+		"GET /schedules/list":    s.listSchedules,
+		"POST /schedules/pause":  s.pauseSchedule,
+		"POST /schedules/delete": s.deleteSchedule,
+
+		"POST /builds/createUpload": s.createBuildUpload,
+		"POST /builds/create":       s.createBuild,
+		"GET /builds/get":           s.getBuild,
+		"GET /builds/getLogs":       s.getBuildLogs,
+
+		"GET /agents/list":        s.listAgents,
+		"GET /resources/list":     s.listResources,
+		"POST /apiKeys/create":    s.createAPIKey,
+		"GET /apiKeys/list":       s.listAPIKeys,
+		"POST /apiKeys/delete":    s.deleteAPIKey,
+		"POST /permissions/check": s.checkPermissions,
+	}
+	return s
+}
+
+// ServeHTTP implementation.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if id := r.Header.Get("X-Airplane-Request-ID"); id != "" {
+		w.Header().Set("X-Request-ID", id)
+	}
+
+	// Uploads are sent to signed URLs, without the API's credentials.
+	if strings.HasPrefix(r.URL.Path, "/uploads/") && r.Method == "PUT" {
+		io.Copy(ioutil.Discard, r.Body)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Header.Get("X-Airplane-Token") == "" && r.Header.Get("X-Airplane-API-Key") == "" {
+		writeError(w, api.Error{Code: http.StatusUnauthorized, Message: "authentication is missing"})
+		return
+	}
+	h, ok := s.routes[r.Method+" "+strings.TrimPrefix(r.URL.Path, "/v0")]
+	if !ok {
+		writeError(w, api.Error{Code: http.StatusNotFound, Message: fmt.Sprintf("%s %s is not served by the fake API", r.Method, r.URL.Path)})
+		return
+	}
+
+	res, err := h(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if res == nil {
+		res = struct{}{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// writeError replies with err's status code, and its message in the format
+// that api.Error decodes.
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	var apiErr api.Error
+	var taskErr *api.TaskMissingError
+	var envGroupErr *api.EnvGroupMissingError
+	var pausedErr *api.TaskPausedError
+	switch {
+	case errors.As(err, &apiErr):
+		code, err = apiErr.Code, errors.New(apiErr.Message)
+	case errors.As(err, &taskErr), errors.As(err, &envGroupErr):
+		code = http.StatusNotFound
+	case errors.As(err, &pausedErr):
+		code = http.StatusLocked
+	case errors.Is(err, apitest.ErrNotSupported):
+		code = http.StatusNotImplemented
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// decode reads the JSON body of r into v.
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return api.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid body: %v", err)}
+	}
+	return nil
+}
+
+// intParam returns the integer query parameter key of r, or 0 if unset.
+func intParam(r *http.Request, key string) (int, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, api.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid %s: %s", key, v)}
+	}
+	return n, nil
+}
+
+// timeParam returns the RFC 3339 query parameter key of r, or the zero time
+// if unset.
+func timeParam(r *http.Request, key string) (time.Time, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, api.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid %s: %s", key, v)}
+	}
+	return t, nil
+}
+
+// page returns the items [page*limit, (page+1)*limit) of n, as the API
+// paginates lists.
+func page(r *http.Request, n int) (int, int, error) {
+	p, err := intParam(r, "page")
+	if err != nil {
+		return 0, 0, err
+	}
+	limit, err := intParam(r, "limit")
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit <= 0 {
+		return 0, n, nil
+	}
+	start, end := p*limit, (p+1)*limit
+	if start > n {
+		start = n
+	}
+	if end > n {
+		end = n
+	}
+	return start, end, nil
+}
+
+// revealed returns how many of n logs written since start have been revealed.
+func (s *Server) revealed(start time.Time, n int) int {
+	if s.opts.LogInterval <= 0 {
+		return n
+	}
+	if k := int(time.Since(start)/s.opts.LogInterval) + 1; k < n {
+		return k
+	}
+	return n
+}
+
+func (s *Server) newID(prefix string) string {
+	s.ids++
+	return fmt.Sprintf("%s%d", prefix, s.ids)
+}
+
+// baseURL returns the URL the server was reached at.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func (s *Server) authInfo(r *http.Request) (interface{}, error) {
+	return s.fake.AuthInfo(r.Context())
+}
+
+func (s *Server) getRegistryToken(r *http.Request) (interface{}, error) {
+	return api.RegistryTokenResponse{
+		Token:      "apiserver",
+		Expiration: time.Now().Add(time.Hour).Format(time.RFC3339),
+		Repo:       r.Host + "/airplane",
+	}, nil
+}
+
+func (s *Server) createTask(r *http.Request) (interface{}, error) {
+	var req api.CreateTaskRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return s.fake.CreateTask(r.Context(), req)
+}
+
+func (s *Server) updateTask(r *http.Request) (interface{}, error) {
+	var req api.UpdateTaskRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return s.fake.UpdateTask(r.Context(), req)
+}
+
+func (s *Server) getTask(r *http.Request) (interface{}, error) {
+	return s.fake.GetTask(r.Context(), r.URL.Query().Get("slug"))
+}
+
+func (s *Server) listTasks(r *http.Request) (interface{}, error) {
+	resp, err := s.fake.ListTasks(r.Context(), api.ListTasksRequest{Owner: r.URL.Query().Get("owner")})
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := page(r, len(resp.Tasks))
+	if err != nil {
+		return nil, err
+	}
+	return api.ListTasksResponse{Tasks: resp.Tasks[start:end]}, nil
+}
+
+func (s *Server) listTaskRevisions(r *http.Request) (interface{}, error) {
+	limit, err := intParam(r, "limit")
+	if err != nil {
+		return nil, err
+	}
+	return s.fake.ListTaskRevisions(r.Context(), r.URL.Query().Get("taskID"), limit)
+}
+
+func (s *Server) getUniqueSlug(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	return s.fake.GetUniqueSlug(r.Context(), q.Get("name"), q.Get("slug"))
+}
+
+func (s *Server) pauseTask(r *http.Request) (interface{}, error) {
+	var req api.PauseTaskRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.PauseTask(r.Context(), req)
+}
+
+func (s *Server) runTask(r *http.Request) (interface{}, error) {
+	var req api.RunTaskRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return s.fake.RunTask(r.Context(), req)
+}
+
+func (s *Server) listRuns(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	req := api.ListRunsRequest{
+		TaskID:    q.Get("taskID"),
+		CreatorID: q.Get("creatorID"),
+		Query:     q.Get("query"),
+	}
+	var err error
+	if req.Since, err = timeParam(r, "since"); err != nil {
+		return nil, err
+	}
+	if req.Until, err = timeParam(r, "until"); err != nil {
+		return nil, err
+	}
+	for _, label := range q["label"] {
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) != 2 {
+			return nil, api.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid label: %s", label)}
+		}
+		if req.Labels == nil {
+			req.Labels = map[string]string{}
+		}
+		req.Labels[parts[0]] = parts[1]
+	}
+	for _, status := range q["status"] {
+		req.Statuses = append(req.Statuses, api.RunStatus(status))
+	}
+
+	resp, err := s.fake.ListRuns(r.Context(), req)
+	if err != nil {
+		return nil, err
+	}
+	for i, run := range resp.Runs {
+		resp.Runs[i] = s.streamRun(r, run)
+	}
+	start, end, err := page(r, len(resp.Runs))
+	if err != nil {
+		return nil, err
+	}
+	return api.ListRunsResponse{Runs: resp.Runs[start:end]}, nil
+}
+
+func (s *Server) getRun(r *http.Request) (interface{}, error) {
+	resp, err := s.fake.GetRun(r.Context(), r.URL.Query().Get("runID"))
+	if err != nil {
+		return nil, err
+	}
+	resp.Run = s.streamRun(r, resp.Run)
+	return resp, nil
+}
+
+// streamRun shows run as active until all of its logs are revealed.
+func (s *Server) streamRun(r *http.Request, run api.Run) api.Run {
+	if s.opts.LogInterval <= 0 || !run.Status.Stopped() || run.Status == api.RunCancelled {
+		return run
+	}
+	logs, err := s.fake.GetLogs(r.Context(), run.RunID, "")
+	if err != nil || s.revealed(run.CreatedAt, len(logs.Logs)) == len(logs.Logs) {
+		return run
+	}
+	run.Status = api.RunActive
+	run.SucceededAt, run.FailedAt = nil, nil
+	return run
+}
+
+func (s *Server) getLogs(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	runID, token := q.Get("runID"), q.Get("prev_token")
+	run, err := s.fake.GetRun(r.Context(), runID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.fake.GetLogs(r.Context(), runID, "")
+	if err != nil {
+		return nil, err
+	}
+	from, err := intParam(r, "prev_token")
+	if err != nil || from > len(all.Logs) {
+		return nil, api.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid prev_token: %s", token)}
+	}
+	to := s.revealed(run.Run.CreatedAt, len(all.Logs))
+	if run.Run.Status == api.RunCancelled {
+		to = len(all.Logs)
+	}
+	if to < from {
+		to = from
+	}
+	return api.GetLogsResponse{
+		RunID:         runID,
+		Logs:          all.Logs[from:to],
+		PrevPageToken: strconv.Itoa(to),
+	}, nil
+}
+
+func (s *Server) getOutputs(r *http.Request) (interface{}, error) {
+	return s.fake.GetOutputs(r.Context(), r.URL.Query().Get("runID"))
+}
+
+func (s *Server) cancelRun(r *http.Request) (interface{}, error) {
+	var req api.CancelRunRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.CancelRun(r.Context(), req.RunID)
+}
+
+func (s *Server) getConfig(r *http.Request) (interface{}, error) {
+	var req api.GetConfigRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return s.fake.GetConfig(r.Context(), req)
+}
+
+func (s *Server) setConfig(r *http.Request) (interface{}, error) {
+	var req api.SetConfigRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.SetConfig(r.Context(), req)
+}
+
+func (s *Server) listConfigs(r *http.Request) (interface{}, error) {
+	showSecret, _ := strconv.ParseBool(r.URL.Query().Get("showSecret"))
+	return s.fake.ListConfigs(r.Context(), api.ListConfigsRequest{ShowSecret: showSecret})
+}
+
+func (s *Server) listEnvGroups(r *http.Request) (interface{}, error) {
+	return s.fake.ListEnvGroups(r.Context())
+}
+
+func (s *Server) getEnvGroup(r *http.Request) (interface{}, error) {
+	return s.fake.GetEnvGroup(r.Context(), r.URL.Query().Get("name"))
+}
+
+func (s *Server) setEnvGroup(r *http.Request) (interface{}, error) {
+	var req api.SetEnvGroupRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.SetEnvGroup(r.Context(), req)
+}
+
+func (s *Server) deleteEnvGroup(r *http.Request) (interface{}, error) {
+	var req api.DeleteEnvGroupRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.DeleteEnvGroup(r.Context(), req)
+}
+
+func (s *Server) createSchedule(r *http.Request) (interface{}, error) {
+	var req api.CreateScheduleRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return s.fake.CreateSchedule(r.Context(), req)
+}
+
+func (s *Server) listSchedules(r *http.Request) (interface{}, error) {
+	return s.fake.ListSchedules(r.Context(), api.ListSchedulesRequest{TaskID: r.URL.Query().Get("taskID")})
+}
+
+func (s *Server) pauseSchedule(r *http.Request) (interface{}, error) {
+	var req api.PauseScheduleRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.PauseSchedule(r.Context(), req)
+}
+
+func (s *Server) deleteSchedule(r *http.Request) (interface{}, error) {
+	var req api.DeleteScheduleRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.DeleteSchedule(r.Context(), req)
+}
+
+func (s *Server) createBuildUpload(r *http.Request) (interface{}, error) {
+	var req api.CreateBuildUploadRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	id := s.newID("upl")
+	s.mu.Unlock()
+	url := baseURL(r) + "/uploads/" + id
+	// Uploads are always single PUTs, which is simpler to serve than a
+	// resumable session.
+	return api.CreateBuildUploadResponse{
+		Upload:       api.Upload{ID: id, URL: url},
+		WriteOnlyURL: url,
+	}, nil
+}
+
+func (s *Server) createBuild(r *http.Request) (interface{}, error) {
+	var req api.CreateBuildRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sum := sha256.Sum256([]byte(req.SourceUploadID))
+	b := &build{
+		Build: api.Build{
+			ID:             s.newID("bld"),
+			SourceUploadID: req.SourceUploadID,
+			CreatedAt:      time.Now(),
+			ImageDigest:    "sha256:" + hex.EncodeToString(sum[:]),
+		},
+	}
+	for i, text := range s.opts.BuildLogs {
+		b.logs = append(b.logs, api.LogItem{
+			Timestamp: b.CreatedAt.Add(time.Duration(i) * s.opts.LogInterval),
+			InsertID:  fmt.Sprintf("%06d", i),
+			Text:      text,
+			Level:     api.LogLevelInfo,
+		})
+	}
+	s.builds[b.ID] = b
+	return api.CreateBuildResponse{Build: s.buildState(b)}, nil
+}
+
+// buildState returns b as of now: active until all of its logs are
+// revealed, and succeeded after. s.mu must be held.
+func (s *Server) buildState(b *build) api.Build {
+	res := b.Build
+	if s.revealed(b.CreatedAt, len(b.logs)) < len(b.logs) {
+		res.Status = api.BuildActive
+	} else {
+		res.Status = api.BuildSucceeded
+	}
+	return res
+}
+
+func (s *Server) getBuild(r *http.Request) (interface{}, error) {
+	id := r.URL.Query().Get("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.builds[id]
+	if !ok {
+		return nil, api.Error{Code: http.StatusNotFound, Message: fmt.Sprintf("build %s does not exist", id)}
+	}
+	return api.GetBuildResponse{Build: s.buildState(b)}, nil
+}
+
+func (s *Server) getBuildLogs(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	id, token := q.Get("buildID"), q.Get("prev_token")
+	from, err := intParam(r, "prev_token")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.builds[id]
+	if !ok {
+		return nil, api.Error{Code: http.StatusNotFound, Message: fmt.Sprintf("build %s does not exist", id)}
+	}
+	if err != nil || from > len(b.logs) {
+		return nil, api.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid prev_token: %s", token)}
+	}
+	to := s.revealed(b.CreatedAt, len(b.logs))
+	if to < from {
+		to = from
+	}
+	return api.GetBuildLogsResponse{
+		BuildID:       id,
+		Logs:          b.logs[from:to],
+		PrevPageToken: strconv.Itoa(to),
+	}, nil
+}
+
+func (s *Server) listAgents(r *http.Request) (interface{}, error) {
+	return s.fake.ListAgents(r.Context())
+}
+
+func (s *Server) listResources(r *http.Request) (interface{}, error) {
+	return s.fake.ListResources(r.Context())
+}
+
+func (s *Server) createAPIKey(r *http.Request) (interface{}, error) {
+	var req api.CreateAPIKeyRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return s.fake.CreateAPIKey(r.Context(), req)
+}
+
+func (s *Server) listAPIKeys(r *http.Request) (interface{}, error) {
+	return s.fake.ListAPIKeys(r.Context())
+}
+
+func (s *Server) deleteAPIKey(r *http.Request) (interface{}, error) {
+	var req api.DeleteAPIKeyRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return nil, s.fake.DeleteAPIKey(r.Context(), req)
+}
+
+func (s *Server) checkPermissions(r *http.Request) (interface{}, error) {
+	var req api.CheckPermissionsRequest
+	if err := decode(r, &req); err != nil {
+		return nil, err
+	}
+	return s.fake.CheckPermissions(r.Context(), req)
+}
@@ -0,0 +1,148 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/airplanedev/ojson"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, opts Options) (*apitest.Client, api.Client) {
+	fake := apitest.New()
+	srv := httptest.NewServer(New(fake, opts))
+	t.Cleanup(srv.Close)
+	return fake, api.Client{Host: srv.URL, Token: "tkn"}
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("auth", func(t *testing.T) {
+		var assert = require.New(t)
+		_, client := newServer(t, Options{})
+
+		resp, err := http.Get(client.Host + "/v0/agents/list")
+		assert.NoError(err)
+		resp.Body.Close()
+		assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("tasks", func(t *testing.T) {
+		var assert = require.New(t)
+		_, client := newServer(t, Options{})
+
+		_, err := client.GetTask(ctx, "hello")
+		assert.IsType(&api.TaskMissingError{}, err)
+
+		res, err := client.CreateTask(ctx, api.CreateTaskRequest{Slug: "hello", Name: "Hello"})
+		assert.NoError(err)
+		task, err := client.GetTask(ctx, "hello")
+		assert.NoError(err)
+		assert.Equal(res.TaskID, task.ID)
+		assert.Equal("Hello", task.Name)
+
+		tasks, err := client.ListTasks(ctx, api.ListTasksRequest{})
+		assert.NoError(err)
+		assert.Len(tasks.Tasks, 1)
+
+		assert.NoError(client.PauseTask(ctx, api.PauseTaskRequest{TaskID: task.ID, Paused: true}))
+		_, err = client.RunTask(ctx, api.RunTaskRequest{TaskID: task.ID})
+		assert.IsType(&api.TaskPausedError{}, err)
+		assert.Contains(err.Error(), "hello is paused")
+	})
+
+	t.Run("runs", func(t *testing.T) {
+		var assert = require.New(t)
+		fake, client := newServer(t, Options{})
+		fake.Run = func(task api.Task, req api.RunTaskRequest) (api.RunStatus, []string, api.Outputs) {
+			return api.RunSucceeded, []string{"one", "two"}, api.Outputs{V: ojson.NewObject()}
+		}
+		task := fake.AddTask(api.Task{Slug: "hello"})
+
+		w, err := client.Watcher(ctx, api.RunTaskRequest{TaskID: task.ID})
+		assert.NoError(err)
+		var logs []string
+		var state api.RunState
+		for {
+			state = w.Next()
+			assert.NoError(state.Err())
+			for _, l := range state.Logs {
+				logs = append(logs, l.Text)
+			}
+			if state.Stopped() {
+				break
+			}
+		}
+		assert.False(state.Failed())
+		assert.Equal([]string{"one", "two"}, logs)
+
+		runs, err := client.ListRuns(ctx, api.ListRunsRequest{TaskID: task.ID})
+		assert.NoError(err)
+		assert.Len(runs.Runs, 1)
+	})
+
+	t.Run("streamed logs", func(t *testing.T) {
+		var assert = require.New(t)
+		fake, client := newServer(t, Options{LogInterval: time.Hour})
+		task := fake.AddTask(api.Task{Slug: "hello"})
+		run := fake.AddRun(api.Run{TaskID: task.ID, Status: api.RunSucceeded, CreatedAt: time.Now()}, []string{"one", "two"}, api.Outputs{})
+
+		// Only the first log has been written, so the run is still going.
+		res, err := client.GetRun(ctx, run.RunID)
+		assert.NoError(err)
+		assert.Equal(api.RunActive, res.Run.Status)
+		logs, err := client.GetLogs(ctx, run.RunID, "")
+		assert.NoError(err)
+		assert.Len(logs.Logs, 1)
+		assert.Equal("one", logs.Logs[0].Text)
+		logs, err = client.GetLogs(ctx, run.RunID, logs.PrevPageToken)
+		assert.NoError(err)
+		assert.Len(logs.Logs, 0)
+	})
+
+	t.Run("builds", func(t *testing.T) {
+		var assert = require.New(t)
+		_, client := newServer(t, Options{BuildLogs: []string{"building"}})
+
+		upload, err := client.CreateBuildUpload(ctx, api.CreateBuildUploadRequest{SizeBytes: 4})
+		assert.NoError(err)
+		req, err := http.NewRequest("PUT", upload.WriteOnlyURL, bytes.NewReader([]byte("data")))
+		assert.NoError(err)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(err)
+		resp.Body.Close()
+		assert.Equal(http.StatusOK, resp.StatusCode)
+
+		b, err := client.CreateBuild(ctx, api.CreateBuildRequest{SourceUploadID: upload.Upload.ID})
+		assert.NoError(err)
+		got, err := client.GetBuild(ctx, b.Build.ID)
+		assert.NoError(err)
+		assert.Equal(api.BuildSucceeded, got.Build.Status)
+		assert.NotEmpty(got.Build.ImageDigest)
+		logs, err := client.GetBuildLogs(ctx, b.Build.ID, "")
+		assert.NoError(err)
+		assert.Len(logs.Logs, 1)
+		assert.Equal("building", logs.Logs[0].Text)
+
+		_, err = client.GetBuild(ctx, "bld_missing")
+		assert.Error(err)
+	})
+
+	t.Run("configs", func(t *testing.T) {
+		var assert = require.New(t)
+		_, client := newServer(t, Options{})
+
+		assert.NoError(client.SetConfig(ctx, api.SetConfigRequest{Name: "db", Value: "pg://", IsSecret: true}))
+		res, err := client.ListConfigs(ctx, api.ListConfigsRequest{})
+		assert.NoError(err)
+		assert.Len(res.Configs, 1)
+		assert.Equal("", res.Configs[0].Value)
+	})
+}
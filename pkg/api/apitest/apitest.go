@@ -0,0 +1,904 @@
+// Package apitest implements an in-memory fake of the Airplane API, so that
+// code which talks to the API can be tested without the network.
+//
+// The fake keeps tasks, task revisions, runs, configs, env groups, schedules,
+// resources and downloads in memory. Every other endpoint either returns an empty result or
+// ErrNotSupported.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/pkg/errors"
+)
+
+// ErrNotSupported is returned by endpoints the fake does not implement, such
+// as builds.
+var ErrNotSupported = errors.New("apitest: not supported by the fake client")
+
+// RunFunc decides how a run started with req ends. It returns the run's final
+// status, the text of its logs and its outputs.
+type RunFunc func(task api.Task, req api.RunTaskRequest) (api.RunStatus, []string, api.Outputs)
+
+// Client is an in-memory fake of the Airplane API.
+//
+// It is safe for concurrent use.
+type Client struct {
+	// Host is used to build URLs. If empty, it uses the global `api.Host`.
+	Host string
+
+	// Run decides how new runs end. If nil, runs succeed straight away,
+	// with no logs or outputs.
+	Run RunFunc
+
+	// Admin lets runs of paused tasks start, as the API does for team
+	// admins.
+	Admin bool
+
+	mu        sync.Mutex
+	ids       int
+	tasks     map[string]api.Task
+	runs      map[string]*run
+	configs   map[string]api.Config
+	envGroups map[string]api.EnvGroup
+	schedules map[string]api.Schedule
+	downloads map[string][]byte
+	resources []api.Resource
+	// revisions holds the revisions of tasks by task ID, oldest first.
+	revisions map[string][]api.TaskRevision
+	// idempotent maps the idempotency keys of runs to their IDs.
+	idempotent map[string]string
+}
+
+// run is a run with its logs and outputs.
+type run struct {
+	api.Run
+	logs    []api.LogItem
+	outputs api.Outputs
+	// seq orders runs created at the same time.
+	seq int
+}
+
+var _ api.APIClient = &Client{}
+
+// New returns an empty fake client.
+func New() *Client {
+	return &Client{
+		tasks:      map[string]api.Task{},
+		revisions:  map[string][]api.TaskRevision{},
+		runs:       map[string]*run{},
+		configs:    map[string]api.Config{},
+		envGroups:  map[string]api.EnvGroup{},
+		schedules:  map[string]api.Schedule{},
+		downloads:  map[string][]byte{},
+		idempotent: map[string]string{},
+	}
+}
+
+// AddTask adds or replaces a task, assigning it an ID if it has none, and
+// returns it.
+func (c *Client) AddTask(task api.Task) api.Task {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addTask(task)
+}
+
+// addTask adds task. c.mu must be held.
+func (c *Client) addTask(task api.Task) api.Task {
+	if task.ID == "" {
+		task.ID = c.newID("tsk")
+	}
+	task.URL = c.urls().TaskURL(task.Slug)
+	c.tasks[task.Slug] = task
+	return task
+}
+
+// AddTaskRevision adds a revision of the task with the given ID, as a deploy
+// does, and returns it.
+func (c *Client) AddTaskRevision(taskID string) api.TaskRevision {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addTaskRevision(taskID, nil)
+}
+
+// addTaskRevision adds a revision of the task with the given ID. c.mu must be
+// held.
+func (c *Client) addTaskRevision(taskID string, annotation *api.DeployAnnotation) api.TaskRevision {
+	rev := api.TaskRevision{
+		ID:         c.newID("tskrev"),
+		TaskID:     taskID,
+		CreatedAt:  time.Now(),
+		Annotation: annotation,
+	}
+	c.revisions[taskID] = append(c.revisions[taskID], rev)
+	return rev
+}
+
+// AddRun adds or replaces a run with its logs and outputs, assigning it an ID
+// if it has none, and returns it.
+func (c *Client) AddRun(r api.Run, logs []string, outputs api.Outputs) api.Run {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r.RunID == "" {
+		r.RunID = c.newID("run")
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	c.ids++
+	c.runs[r.RunID] = &run{Run: r, logs: logItems(logs), outputs: outputs, seq: c.ids}
+	return r
+}
+
+// AddResource adds a resource, assigning it an ID if it has none, and returns
+// it.
+func (c *Client) AddResource(r api.Resource) api.Resource {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r.ID == "" {
+		r.ID = c.newID("res")
+	}
+	c.resources = append(c.resources, r)
+	return r
+}
+
+// AddDownload serves data at the signed URL url.
+func (c *Client) AddDownload(url string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.downloads[url] = data
+}
+
+// Runs returns every run, oldest first.
+func (c *Client) Runs() []api.Run {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	runs := make([]*run, 0, len(c.runs))
+	for _, r := range c.runs {
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].CreatedAt.Equal(runs[j].CreatedAt) {
+			return runs[i].CreatedAt.Before(runs[j].CreatedAt)
+		}
+		return runs[i].seq < runs[j].seq
+	})
+	res := make([]api.Run, len(runs))
+	for i, r := range runs {
+		res[i] = r.Run
+	}
+	return res
+}
+
+// AppURL implementation.
+func (c *Client) AppURL(path string) string {
+	return c.urls().AppURL(path)
+}
+
+// LoginURL implementation.
+func (c *Client) LoginURL(uri string) string {
+	return c.urls().LoginURL(uri)
+}
+
+// LoginSuccessURL implementation.
+func (c *Client) LoginSuccessURL() string {
+	return c.urls().LoginSuccessURL()
+}
+
+// RunURL implementation.
+func (c *Client) RunURL(id string) string {
+	return c.urls().RunURL(id)
+}
+
+// TaskURL implementation.
+func (c *Client) TaskURL(slug string) string {
+	return c.urls().TaskURL(slug)
+}
+
+// AuthInfo implementation.
+func (c *Client) AuthInfo(ctx context.Context) (api.AuthInfoResponse, error) {
+	return api.AuthInfoResponse{}, nil
+}
+
+// GetRegistryToken implementation.
+func (c *Client) GetRegistryToken(ctx context.Context) (api.RegistryTokenResponse, error) {
+	return api.RegistryTokenResponse{}, ErrNotSupported
+}
+
+// CreateTask implementation.
+func (c *Client) CreateTask(ctx context.Context, req api.CreateTaskRequest) (api.CreateTaskResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.tasks[req.Slug]; ok {
+		return api.CreateTaskResponse{}, api.Error{Code: 409, Message: fmt.Sprintf("task %s already exists", req.Slug)}
+	}
+
+	task := c.addTask(api.Task{
+		Name:             req.Name,
+		Slug:             req.Slug,
+		Description:      req.Description,
+		Image:            req.Image,
+		Command:          req.Command,
+		Arguments:        req.Arguments,
+		Parameters:       req.Parameters,
+		Form:             req.Form,
+		Constraints:      req.Constraints,
+		Env:              req.Env,
+		EnvGroups:        req.EnvGroups,
+		ResourceRequests: req.ResourceRequests,
+		Resources:        req.Resources,
+		Kind:             req.Kind,
+		KindOptions:      req.KindOptions,
+		Repo:             req.Repo,
+		Concurrency:      req.Concurrency,
+		Confirm:          req.Confirm,
+		Dependencies:     req.Dependencies,
+		Timeout:          req.Timeout,
+	})
+	rev := c.addTaskRevision(task.ID, nil)
+	return api.CreateTaskResponse{TaskID: task.ID, Slug: task.Slug, TaskRevisionID: rev.ID}, nil
+}
+
+// UpdateTask implementation.
+func (c *Client) UpdateTask(ctx context.Context, req api.UpdateTaskRequest) (api.UpdateTaskResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	task, ok := c.tasks[req.Slug]
+	if !ok {
+		return api.UpdateTaskResponse{}, c.taskMissing(req.Slug)
+	}
+	task.Name = req.Name
+	task.Description = req.Description
+	task.Image = req.Image
+	task.Command = req.Command
+	task.Arguments = req.Arguments
+	task.Parameters = req.Parameters
+	task.Form = req.Form
+	task.Constraints = req.Constraints
+	task.Env = req.Env
+	task.EnvGroups = req.EnvGroups
+	task.ResourceRequests = req.ResourceRequests
+	task.Resources = req.Resources
+	task.Kind = req.Kind
+	task.KindOptions = req.KindOptions
+	task.Repo = req.Repo
+	task.RequireExplicitPermissions = req.RequireExplicitPermissions
+	task.Permissions = req.Permissions
+	task.Concurrency = req.Concurrency
+	task.Confirm = req.Confirm
+	task.Dependencies = req.Dependencies
+	task.Timeout = req.Timeout
+	task.InterpolationMode = req.InterpolationMode
+	c.tasks[req.Slug] = task
+	rev := c.addTaskRevision(task.ID, req.Annotation)
+	return api.UpdateTaskResponse{TaskRevisionID: rev.ID}, nil
+}
+
+// GetTask implementation.
+func (c *Client) GetTask(ctx context.Context, slug string) (api.Task, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	task, ok := c.tasks[slug]
+	if !ok {
+		return api.Task{}, c.taskMissing(slug)
+	}
+	return task, nil
+}
+
+// GetTaskID implementation.
+func (c *Client) GetTaskID(ctx context.Context, slug string) (string, error) {
+	task, err := c.GetTask(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+	return task.ID, nil
+}
+
+// GetTaskSlug implementation.
+func (c *Client) GetTaskSlug(ctx context.Context, id string) (string, error) {
+	task, err := api.TaskByID(ctx, c, id)
+	if err != nil {
+		return "", err
+	}
+	return task.Slug, nil
+}
+
+// ListTasks implementation. Tasks are sorted by slug.
+func (c *Client) ListTasks(ctx context.Context, req api.ListTasksRequest) (api.ListTasksResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tasks := make([]api.Task, 0, len(c.tasks))
+	for _, t := range c.tasks {
+		if req.Owner == "" || strings.EqualFold(t.OwnerEmail(), req.Owner) {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Slug < tasks[j].Slug })
+	if req.Limit > 0 && len(tasks) > req.Limit {
+		tasks = tasks[:req.Limit]
+	}
+	return api.ListTasksResponse{Tasks: tasks}, nil
+}
+
+// ListTaskRevisions implementation. Revisions are listed newest first.
+func (c *Client) ListTaskRevisions(ctx context.Context, taskID string, limit int) (api.ListTaskRevisionsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	revs := c.revisions[taskID]
+	res := make([]api.TaskRevision, 0, len(revs))
+	for i := len(revs) - 1; i >= 0; i-- {
+		if limit > 0 && len(res) == limit {
+			break
+		}
+		res = append(res, revs[i])
+	}
+	return api.ListTaskRevisionsResponse{Revisions: res}, nil
+}
+
+// GetUniqueSlug implementation.
+func (c *Client) GetUniqueSlug(ctx context.Context, name, preferredSlug string) (api.GetUniqueSlugResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slug := preferredSlug
+	for i := 2; ; i++ {
+		if _, ok := c.tasks[slug]; !ok {
+			return api.GetUniqueSlugResponse{Slug: slug}, nil
+		}
+		slug = fmt.Sprintf("%s_%d", preferredSlug, i)
+	}
+}
+
+// PauseTask implementation.
+func (c *Client) PauseTask(ctx context.Context, req api.PauseTaskRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for slug, task := range c.tasks {
+		if task.ID != req.TaskID {
+			continue
+		}
+		task.Pause = nil
+		if req.Paused {
+			task.Pause = &api.TaskPause{
+				Reason:   req.Reason,
+				PausedAt: time.Now(),
+				Until:    req.Until,
+			}
+		}
+		c.tasks[slug] = task
+		return nil
+	}
+	return api.Error{Code: 404, Message: fmt.Sprintf("task %s does not exist", req.TaskID)}
+}
+
+// RunTask implementation. The run ends straight away, as decided by c.Run.
+func (c *Client) RunTask(ctx context.Context, req api.RunTaskRequest) (api.RunTaskResponse, error) {
+	var task api.Task
+	var found bool
+	c.mu.Lock()
+	for _, t := range c.tasks {
+		if t.ID == req.TaskID {
+			task, found = t, true
+			break
+		}
+	}
+	runID, repeated := c.idempotent[req.IdempotencyKey]
+	c.mu.Unlock()
+	if repeated && req.IdempotencyKey != "" {
+		return api.RunTaskResponse{RunID: runID}, nil
+	}
+	if !found {
+		return api.RunTaskResponse{}, api.Error{Code: 404, Message: fmt.Sprintf("task %s does not exist", req.TaskID)}
+	}
+	if task.Pause.Active(time.Now()) && !c.Admin {
+		return api.RunTaskResponse{}, api.NewTaskPausedError(fmt.Sprintf("task %s is paused for maintenance", task.Slug))
+	}
+
+	status, logs, outputs := api.RunSucceeded, []string(nil), api.Outputs{}
+	if c.Run != nil {
+		status, logs, outputs = c.Run(task, req)
+	}
+	now := time.Now()
+	r := api.Run{
+		TaskID:      task.ID,
+		TaskName:    task.Name,
+		Status:      status,
+		ParamValues: req.ParamValues,
+		CreatedAt:   now,
+		Labels:      req.Labels,
+		Priority:    req.Priority,
+	}
+	switch status {
+	case api.RunSucceeded:
+		r.SucceededAt = &now
+	case api.RunFailed:
+		r.FailedAt = &now
+	case api.RunCancelled:
+		r.CancelledAt = &now
+	}
+	r = c.AddRun(r, logs, outputs)
+	if req.IdempotencyKey != "" {
+		c.mu.Lock()
+		c.idempotent[req.IdempotencyKey] = r.RunID
+		c.mu.Unlock()
+	}
+	return api.RunTaskResponse{RunID: r.RunID}, nil
+}
+
+// Watcher implementation.
+func (c *Client) Watcher(ctx context.Context, req api.RunTaskRequest, opts ...api.WatcherOption) (*api.Watcher, error) {
+	resp, err := c.RunTask(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return api.NewWatcher(ctx, c, resp.RunID, opts...), nil
+}
+
+// ListRuns implementation. Runs are returned newest first.
+func (c *Client) ListRuns(ctx context.Context, req api.ListRunsRequest) (api.ListRunsResponse, error) {
+	runs := c.Runs()
+	var res []api.Run
+	for i := len(runs) - 1; i >= 0; i-- {
+		r := runs[i]
+		if req.TaskID != "" && r.TaskID != req.TaskID {
+			continue
+		}
+		if !req.Since.IsZero() && r.CreatedAt.Before(req.Since) {
+			continue
+		}
+		if !req.Until.IsZero() && r.CreatedAt.After(req.Until) {
+			continue
+		}
+		if !hasLabels(r, req.Labels) {
+			continue
+		}
+		if len(req.Statuses) > 0 && !hasStatus(r, req.Statuses) {
+			continue
+		}
+		if req.CreatorID != "" && r.CreatorID != req.CreatorID {
+			continue
+		}
+		if req.Query != "" && !matchesQuery(r, req.Query) {
+			continue
+		}
+		res = append(res, r)
+		if req.Limit > 0 && len(res) == req.Limit {
+			break
+		}
+	}
+	return api.ListRunsResponse{Runs: res}, nil
+}
+
+// GetRun implementation.
+func (c *Client) GetRun(ctx context.Context, id string) (api.GetRunResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.runs[id]
+	if !ok {
+		return api.GetRunResponse{}, runMissing(id)
+	}
+	return api.GetRunResponse{Run: r.Run}, nil
+}
+
+// GetLogs implementation. Every log after prevToken is returned in one page.
+func (c *Client) GetLogs(ctx context.Context, runID, prevToken string) (api.GetLogsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.runs[runID]
+	if !ok {
+		return api.GetLogsResponse{}, runMissing(runID)
+	}
+	var from int
+	if prevToken != "" {
+		n, err := strconv.Atoi(prevToken)
+		if err != nil || n < 0 || n > len(r.logs) {
+			return api.GetLogsResponse{}, api.Error{Code: 400, Message: "invalid prev_token"}
+		}
+		from = n
+	}
+	logs := append([]api.LogItem(nil), r.logs[from:]...)
+	return api.GetLogsResponse{
+		RunID:         runID,
+		Logs:          logs,
+		PrevPageToken: strconv.Itoa(len(r.logs)),
+	}, nil
+}
+
+// GetOutputs implementation.
+func (c *Client) GetOutputs(ctx context.Context, runID string) (api.GetOutputsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.runs[runID]
+	if !ok {
+		return api.GetOutputsResponse{}, runMissing(runID)
+	}
+	return api.GetOutputsResponse{Outputs: r.outputs}, nil
+}
+
+// CancelRun implementation.
+func (c *Client) CancelRun(ctx context.Context, runID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.runs[runID]
+	if !ok {
+		return runMissing(runID)
+	}
+	if r.Status.Stopped() {
+		return api.Error{Code: 400, Message: fmt.Sprintf("run %s has already stopped", runID)}
+	}
+	now := time.Now()
+	r.Status = api.RunCancelled
+	r.CancelledAt = &now
+	return nil
+}
+
+// GetConfig implementation.
+func (c *Client) GetConfig(ctx context.Context, req api.GetConfigRequest) (api.GetConfigResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfg, ok := c.configs[configKey(req.Name, req.Tag)]
+	if !ok {
+		return api.GetConfigResponse{}, api.Error{Code: 404, Message: fmt.Sprintf("config %s does not exist", configKey(req.Name, req.Tag))}
+	}
+	if cfg.IsSecret && !req.ShowSecret {
+		cfg.Value = ""
+	}
+	return api.GetConfigResponse{Config: cfg}, nil
+}
+
+// SetConfig implementation.
+func (c *Client) SetConfig(ctx context.Context, req api.SetConfigRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.configs[configKey(req.Name, req.Tag)] = api.Config{
+		Name:     req.Name,
+		Tag:      req.Tag,
+		Value:    req.Value,
+		IsSecret: req.IsSecret,
+	}
+	return nil
+}
+
+// ListConfigs implementation. Configs are sorted by name, then tag.
+func (c *Client) ListConfigs(ctx context.Context, req api.ListConfigsRequest) (api.ListConfigsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cfgs := make([]api.Config, 0, len(c.configs))
+	for _, cfg := range c.configs {
+		if cfg.IsSecret && !req.ShowSecret {
+			cfg.Value = ""
+		}
+		cfgs = append(cfgs, cfg)
+	}
+	sort.Slice(cfgs, func(i, j int) bool {
+		if cfgs[i].Name != cfgs[j].Name {
+			return cfgs[i].Name < cfgs[j].Name
+		}
+		return cfgs[i].Tag < cfgs[j].Tag
+	})
+	return api.ListConfigsResponse{Configs: cfgs}, nil
+}
+
+// ListEnvGroups implementation. Env groups are sorted by name.
+func (c *Client) ListEnvGroups(ctx context.Context) (api.ListEnvGroupsResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	groups := make([]api.EnvGroup, 0, len(c.envGroups))
+	for _, g := range c.envGroups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return api.ListEnvGroupsResponse{EnvGroups: groups}, nil
+}
+
+// GetEnvGroup implementation.
+func (c *Client) GetEnvGroup(ctx context.Context, name string) (api.GetEnvGroupResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g, ok := c.envGroups[name]
+	if !ok {
+		return api.GetEnvGroupResponse{}, api.NewEnvGroupMissingError(name)
+	}
+	return api.GetEnvGroupResponse{EnvGroup: g}, nil
+}
+
+// SetEnvGroup implementation.
+func (c *Client) SetEnvGroup(ctx context.Context, req api.SetEnvGroupRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	g, ok := c.envGroups[req.Name]
+	if !ok {
+		g = api.EnvGroup{ID: c.newID("eg"), Name: req.Name, CreatedAt: now}
+	}
+	g.Env = req.Env
+	g.UpdatedAt = now
+	c.envGroups[req.Name] = g
+	return nil
+}
+
+// DeleteEnvGroup implementation.
+func (c *Client) DeleteEnvGroup(ctx context.Context, req api.DeleteEnvGroupRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.envGroups[req.Name]; !ok {
+		return api.NewEnvGroupMissingError(req.Name)
+	}
+	delete(c.envGroups, req.Name)
+	return nil
+}
+
+// CreateSchedule implementation. It fails if the task already has a schedule
+// with the same slug.
+func (c *Client) CreateSchedule(ctx context.Context, req api.CreateScheduleRequest) (api.CreateScheduleResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.hasTaskID(req.TaskID) {
+		return api.CreateScheduleResponse{}, api.Error{Code: 404, Message: fmt.Sprintf("task %s does not exist", req.TaskID)}
+	}
+	for _, s := range c.schedules {
+		if s.TaskID == req.TaskID && req.Slug != "" && s.Slug == req.Slug {
+			return api.CreateScheduleResponse{}, api.Error{Code: 409, Message: fmt.Sprintf("schedule %s already exists", req.Slug)}
+		}
+	}
+	now := time.Now()
+	s := api.Schedule{
+		ID:          c.newID("sch"),
+		TaskID:      req.TaskID,
+		Slug:        req.Slug,
+		Name:        req.Name,
+		Description: req.Description,
+		CronExpr:    req.CronExpr,
+		Timezone:    req.Timezone,
+		ParamValues: req.ParamValues,
+		Paused:      req.Paused,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	c.schedules[s.ID] = s
+	return api.CreateScheduleResponse{ScheduleID: s.ID}, nil
+}
+
+// ListSchedules implementation. Schedules are sorted by slug, then ID.
+func (c *Client) ListSchedules(ctx context.Context, req api.ListSchedulesRequest) (api.ListSchedulesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	schedules := []api.Schedule{}
+	for _, s := range c.schedules {
+		if req.TaskID == "" || s.TaskID == req.TaskID {
+			schedules = append(schedules, s)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Slug != schedules[j].Slug {
+			return schedules[i].Slug < schedules[j].Slug
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+	return api.ListSchedulesResponse{Schedules: schedules}, nil
+}
+
+// PauseSchedule implementation.
+func (c *Client) PauseSchedule(ctx context.Context, req api.PauseScheduleRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.schedules[req.ScheduleID]
+	if !ok {
+		return scheduleMissing(req.ScheduleID)
+	}
+	s.Paused = req.Paused
+	s.UpdatedAt = time.Now()
+	c.schedules[s.ID] = s
+	return nil
+}
+
+// DeleteSchedule implementation.
+func (c *Client) DeleteSchedule(ctx context.Context, req api.DeleteScheduleRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.schedules[req.ScheduleID]; !ok {
+		return scheduleMissing(req.ScheduleID)
+	}
+	delete(c.schedules, req.ScheduleID)
+	return nil
+}
+
+// ListAgents implementation. The fake has no agents.
+func (c *Client) ListAgents(ctx context.Context) (api.ListAgentsResponse, error) {
+	return api.ListAgentsResponse{}, nil
+}
+
+// ListResources implementation. It lists resources added with AddResource.
+func (c *Client) ListResources(ctx context.Context) (api.ListResourcesResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return api.ListResourcesResponse{Resources: append([]api.Resource(nil), c.resources...)}, nil
+}
+
+// GetBuild implementation.
+func (c *Client) GetBuild(ctx context.Context, id string) (api.GetBuildResponse, error) {
+	return api.GetBuildResponse{}, ErrNotSupported
+}
+
+// CreateBuild implementation.
+func (c *Client) CreateBuild(ctx context.Context, req api.CreateBuildRequest) (api.CreateBuildResponse, error) {
+	return api.CreateBuildResponse{}, ErrNotSupported
+}
+
+// CreateBuildUpload implementation.
+func (c *Client) CreateBuildUpload(ctx context.Context, req api.CreateBuildUploadRequest) (api.CreateBuildUploadResponse, error) {
+	return api.CreateBuildUploadResponse{}, ErrNotSupported
+}
+
+// GetBuildLogs implementation.
+func (c *Client) GetBuildLogs(ctx context.Context, buildID string, prevToken string) (api.GetBuildLogsResponse, error) {
+	return api.GetBuildLogsResponse{}, ErrNotSupported
+}
+
+// Download implementation. It serves data added with AddDownload, checking
+// it against opts.SHA256 if set.
+func (c *Client) Download(ctx context.Context, url string, w io.Writer, opts api.DownloadOptions) (int64, error) {
+	c.mu.Lock()
+	data, ok := c.downloads[url]
+	c.mu.Unlock()
+	if !ok {
+		return 0, errors.Errorf("downloading: 404 Not Found: %s", url)
+	}
+	n, err := w.Write(data)
+	if err != nil {
+		return int64(n), err
+	}
+	if opts.Progress != nil {
+		opts.Progress(int64(n), int64(len(data)))
+	}
+	if opts.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != strings.ToLower(opts.SHA256) {
+			return int64(n), &api.ChecksumError{Algorithm: "sha256", Want: opts.SHA256, Got: got}
+		}
+	}
+	return int64(n), nil
+}
+
+// DownloadFile implementation.
+func (c *Client) DownloadFile(ctx context.Context, url string, path string, opts api.DownloadOptions) (int64, error) {
+	var buf bytes.Buffer
+	n, err := c.Download(ctx, url, &buf, opts)
+	if err != nil {
+		return n, err
+	}
+	return n, ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// CreateAPIKey implementation.
+func (c *Client) CreateAPIKey(ctx context.Context, req api.CreateAPIKeyRequest) (api.CreateAPIKeyResponse, error) {
+	return api.CreateAPIKeyResponse{}, ErrNotSupported
+}
+
+// ListAPIKeys implementation. The fake has no API keys.
+func (c *Client) ListAPIKeys(ctx context.Context) (api.ListAPIKeysResponse, error) {
+	return api.ListAPIKeysResponse{}, nil
+}
+
+// DeleteAPIKey implementation.
+func (c *Client) DeleteAPIKey(ctx context.Context, req api.DeleteAPIKeyRequest) error {
+	return ErrNotSupported
+}
+
+// CheckPermissions implementation. Every check is allowed.
+func (c *Client) CheckPermissions(ctx context.Context, req api.CheckPermissionsRequest) (api.CheckPermissionsResponse, error) {
+	var res api.CheckPermissionsResponse
+	for _, check := range req.Checks {
+		res.Results = append(res.Results, api.PermissionCheckResult{PermissionCheck: check, Allowed: true})
+	}
+	return res, nil
+}
+
+// RequirePermissions implementation. Every check is allowed.
+func (c *Client) RequirePermissions(ctx context.Context, checks ...api.PermissionCheck) error {
+	return nil
+}
+
+// urls returns a real client, which builds the same URLs as the API.
+func (c *Client) urls() api.Client {
+	return api.Client{Host: c.Host}
+}
+
+// newID returns a new ID with the given prefix. c.mu must be held.
+func (c *Client) newID(prefix string) string {
+	c.ids++
+	return fmt.Sprintf("%s%d", prefix, c.ids)
+}
+
+func (c *Client) taskMissing(slug string) error {
+	// The error links to the app, which is the host of any app URL.
+	u, _ := url.Parse(c.urls().TaskURL(slug))
+	u.Path = ""
+	return api.NewTaskMissingError(u.String(), slug)
+}
+
+// hasTaskID reports whether a task with the given ID exists. c.mu must be
+// held.
+func (c *Client) hasTaskID(id string) bool {
+	for _, t := range c.tasks {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func scheduleMissing(id string) error {
+	return api.Error{Code: 404, Message: fmt.Sprintf("schedule %s does not exist", id)}
+}
+
+func runMissing(id string) error {
+	return api.Error{Code: 404, Message: fmt.Sprintf("run %s does not exist", id)}
+}
+
+func configKey(name, tag string) string {
+	if tag == "" {
+		return name
+	}
+	return name + ":" + tag
+}
+
+func hasLabels(r api.Run, labels map[string]string) bool {
+	for k, v := range labels {
+		if r.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func hasStatus(r api.Run, statuses []api.RunStatus) bool {
+	for _, s := range statuses {
+		if r.Status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesQuery reports whether r's ID, task name or labels contain query,
+// ignoring case, as the API matches them.
+func matchesQuery(r api.Run, query string) bool {
+	query = strings.ToLower(query)
+	fields := []string{r.RunID, r.TaskName}
+	for k, v := range r.Labels {
+		fields = append(fields, k+"="+v)
+	}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), query) {
+			return true
+		}
+	}
+	return false
+}
+
+func logItems(texts []string) []api.LogItem {
+	var logs []api.LogItem
+	now := time.Now()
+	for i, text := range texts {
+		logs = append(logs, api.LogItem{
+			Timestamp: now.Add(time.Duration(i) * time.Millisecond),
+			InsertID:  fmt.Sprintf("%06d", i),
+			Text:      text,
+			Level:     api.LogLevelInfo,
+		})
+	}
+	return logs
+}
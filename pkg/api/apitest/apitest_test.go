@@ -0,0 +1,198 @@
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/ojson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("tasks", func(t *testing.T) {
+		var assert = require.New(t)
+		var c = New()
+
+		_, err := c.GetTask(ctx, "hello")
+		assert.IsType(&api.TaskMissingError{}, err)
+
+		res, err := c.CreateTask(ctx, api.CreateTaskRequest{Slug: "hello", Name: "Hello"})
+		assert.NoError(err)
+		_, err = c.CreateTask(ctx, api.CreateTaskRequest{Slug: "hello"})
+		assert.Error(err)
+
+		upd, err := c.UpdateTask(ctx, api.UpdateTaskRequest{Slug: "hello", Name: "Hello World"})
+		assert.NoError(err)
+		task, err := c.GetTask(ctx, "hello")
+		assert.NoError(err)
+		assert.Equal(res.TaskID, task.ID)
+		assert.Equal("Hello World", task.Name)
+
+		// Revisions are listed newest first.
+		revs, err := c.ListTaskRevisions(ctx, task.ID, 0)
+		assert.NoError(err)
+		assert.Len(revs.Revisions, 2)
+		assert.Equal(upd.TaskRevisionID, revs.Revisions[0].ID)
+		assert.Equal(res.TaskRevisionID, revs.Revisions[1].ID)
+		rev := c.AddTaskRevision(task.ID)
+		revs, err = c.ListTaskRevisions(ctx, task.ID, 1)
+		assert.NoError(err)
+		assert.Equal([]api.TaskRevision{rev}, revs.Revisions)
+
+		slug, err := c.GetUniqueSlug(ctx, "Hello", "hello")
+		assert.NoError(err)
+		assert.Equal("hello_2", slug.Slug)
+	})
+
+	t.Run("paused tasks", func(t *testing.T) {
+		var assert = require.New(t)
+		var c = New()
+		task := c.AddTask(api.Task{Slug: "hello"})
+
+		assert.NoError(c.PauseTask(ctx, api.PauseTaskRequest{TaskID: task.ID, Paused: true, Reason: "migrating"}))
+		task, err := c.GetTask(ctx, "hello")
+		assert.NoError(err)
+		assert.True(task.Pause.Active(time.Now()))
+		assert.Equal("migrating", task.Pause.Reason)
+		_, err = c.RunTask(ctx, api.RunTaskRequest{TaskID: task.ID})
+		assert.IsType(&api.TaskPausedError{}, err)
+
+		// Admins can still execute paused tasks.
+		c.Admin = true
+		_, err = c.RunTask(ctx, api.RunTaskRequest{TaskID: task.ID})
+		assert.NoError(err)
+		c.Admin = false
+
+		// Windows end on their own.
+		past := time.Now().Add(-time.Minute)
+		assert.NoError(c.PauseTask(ctx, api.PauseTaskRequest{TaskID: task.ID, Paused: true, Until: &past}))
+		_, err = c.RunTask(ctx, api.RunTaskRequest{TaskID: task.ID})
+		assert.NoError(err)
+
+		assert.NoError(c.PauseTask(ctx, api.PauseTaskRequest{TaskID: task.ID, Paused: false}))
+		task, err = c.GetTask(ctx, "hello")
+		assert.NoError(err)
+		assert.Nil(task.Pause)
+
+		assert.Error(c.PauseTask(ctx, api.PauseTaskRequest{TaskID: "tsk_missing", Paused: true}))
+	})
+
+	t.Run("runs", func(t *testing.T) {
+		var assert = require.New(t)
+		var c = New()
+		c.Run = func(task api.Task, req api.RunTaskRequest) (api.RunStatus, []string, api.Outputs) {
+			return api.RunFailed, []string{"one", "two"}, api.Outputs{V: ojson.NewObject()}
+		}
+		task := c.AddTask(api.Task{Slug: "hello"})
+
+		w, err := c.Watcher(ctx, api.RunTaskRequest{TaskID: task.ID, Labels: map[string]string{"env": "test"}, Priority: api.RunPriorityHigh})
+		assert.NoError(err)
+		var logs []string
+		var state api.RunState
+		for state = w.Next(); !state.Stopped(); state = w.Next() {
+			assert.NoError(state.Err())
+			for _, l := range state.Logs {
+				logs = append(logs, l.Text)
+			}
+		}
+		for _, l := range state.Logs {
+			logs = append(logs, l.Text)
+		}
+		assert.True(state.Failed())
+		assert.Equal([]string{"one", "two"}, logs)
+
+		runs, err := c.ListRuns(ctx, api.ListRunsRequest{Labels: map[string]string{"env": "test"}})
+		assert.NoError(err)
+		assert.Len(runs.Runs, 1)
+		assert.Equal(api.RunPriorityHigh, runs.Runs[0].Priority)
+		runs, err = c.ListRuns(ctx, api.ListRunsRequest{Labels: map[string]string{"env": "prod"}})
+		assert.NoError(err)
+		assert.Len(runs.Runs, 0)
+		runs, err = c.ListRuns(ctx, api.ListRunsRequest{Statuses: []api.RunStatus{api.RunFailed}, Query: "ENV=TEST"})
+		assert.NoError(err)
+		assert.Len(runs.Runs, 1)
+		runs, err = c.ListRuns(ctx, api.ListRunsRequest{Statuses: []api.RunStatus{api.RunSucceeded}})
+		assert.NoError(err)
+		assert.Len(runs.Runs, 0)
+
+		active := c.AddRun(api.Run{TaskID: task.ID, Status: api.RunActive}, nil, api.Outputs{})
+		assert.NoError(c.CancelRun(ctx, active.RunID))
+		assert.Error(c.CancelRun(ctx, active.RunID))
+		run, err := c.GetRun(ctx, active.RunID)
+		assert.NoError(err)
+		assert.Equal(api.RunCancelled, run.Run.Status)
+	})
+
+	t.Run("configs", func(t *testing.T) {
+		var assert = require.New(t)
+		var c = New()
+
+		_, err := c.GetConfig(ctx, api.GetConfigRequest{Name: "db", Tag: "prod"})
+		assert.Error(err)
+
+		assert.NoError(c.SetConfig(ctx, api.SetConfigRequest{Name: "db", Tag: "prod", Value: "secret", IsSecret: true}))
+		res, err := c.GetConfig(ctx, api.GetConfigRequest{Name: "db", Tag: "prod"})
+		assert.NoError(err)
+		assert.Equal("", res.Config.Value)
+		res, err = c.GetConfig(ctx, api.GetConfigRequest{Name: "db", Tag: "prod", ShowSecret: true})
+		assert.NoError(err)
+		assert.Equal("secret", res.Config.Value)
+
+		assert.NoError(c.SetConfig(ctx, api.SetConfigRequest{Name: "db", Value: "dev"}))
+		list, err := c.ListConfigs(ctx, api.ListConfigsRequest{})
+		assert.NoError(err)
+		assert.Equal([]api.Config{
+			{Name: "db", Value: "dev"},
+			{Name: "db", Tag: "prod", IsSecret: true},
+		}, list.Configs)
+	})
+
+	t.Run("downloads", func(t *testing.T) {
+		var assert = require.New(t)
+		var c = New()
+		c.AddDownload("https://storage.example.com/file", []byte("hello"))
+
+		var buf bytes.Buffer
+		n, err := c.Download(ctx, "https://storage.example.com/file", &buf, api.DownloadOptions{
+			SHA256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		})
+		assert.NoError(err)
+		assert.Equal(int64(5), n)
+		assert.Equal("hello", buf.String())
+
+		_, err = c.Download(ctx, "https://storage.example.com/file", &buf, api.DownloadOptions{SHA256: "00"})
+		assert.IsType(&api.ChecksumError{}, err)
+		_, err = c.Download(ctx, "https://storage.example.com/missing", &buf, api.DownloadOptions{})
+		assert.Error(err)
+	})
+
+	t.Run("schedules", func(t *testing.T) {
+		var assert = require.New(t)
+		var c = New()
+		task := c.AddTask(api.Task{Slug: "hello"})
+
+		_, err := c.CreateSchedule(ctx, api.CreateScheduleRequest{TaskID: "tsk_missing", Slug: "nightly"})
+		assert.Error(err)
+		res, err := c.CreateSchedule(ctx, api.CreateScheduleRequest{TaskID: task.ID, Slug: "nightly", CronExpr: "0 0 * * *"})
+		assert.NoError(err)
+		_, err = c.CreateSchedule(ctx, api.CreateScheduleRequest{TaskID: task.ID, Slug: "nightly"})
+		assert.Error(err)
+
+		assert.NoError(c.PauseSchedule(ctx, api.PauseScheduleRequest{ScheduleID: res.ScheduleID, Paused: true}))
+		list, err := c.ListSchedules(ctx, api.ListSchedulesRequest{TaskID: task.ID})
+		assert.NoError(err)
+		assert.Len(list.Schedules, 1)
+		assert.True(list.Schedules[0].Paused)
+		list, err = c.ListSchedules(ctx, api.ListSchedulesRequest{TaskID: "tsk_other"})
+		assert.NoError(err)
+		assert.Len(list.Schedules, 0)
+
+		assert.NoError(c.DeleteSchedule(ctx, api.DeleteScheduleRequest{ScheduleID: res.ScheduleID}))
+		assert.Error(c.DeleteSchedule(ctx, api.DeleteScheduleRequest{ScheduleID: res.ScheduleID}))
+	})
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// BuildLogsClient is the part of the API a build watcher reads builds from.
+type BuildLogsClient interface {
+	GetBuild(ctx context.Context, id string) (GetBuildResponse, error)
+	GetBuildLogs(ctx context.Context, buildID string, prevToken string) (GetBuildLogsResponse, error)
+}
+
+// BuildState is the state of a build as of a fetch, with the logs written
+// since the previous state.
+type BuildState struct {
+	Build Build
+	Logs  []LogItem
+	err   error
+}
+
+// Err returns an error if any.
+func (s BuildState) Err() error {
+	return s.err
+}
+
+// Stopped returns true if the build has stopped.
+func (s BuildState) Stopped() bool {
+	return s.Build.Status.Stopped()
+}
+
+// BuildWatcher follows a build's status and logs.
+type BuildWatcher struct {
+	ctx       context.Context
+	client    BuildLogsClient
+	buildID   string
+	prevToken string
+	ticker    *time.Ticker
+
+	// stopped and pending hold the stopped build and the logs fetched for
+	// it while its remaining logs are drained, so that a failed drain
+	// resumes on the next call instead of fetching the build again.
+	stopped *Build
+	pending []LogItem
+}
+
+// NewBuildWatcher returns a watcher of the build with the given ID.
+func NewBuildWatcher(ctx context.Context, client BuildLogsClient, buildID string) *BuildWatcher {
+	return &BuildWatcher{
+		ctx:     ctx,
+		client:  client,
+		buildID: buildID,
+		ticker:  time.NewTicker(fetchInterval),
+	}
+}
+
+// BuildID returns the build's ID.
+func (w *BuildWatcher) BuildID() string {
+	return w.buildID
+}
+
+// Next waits for the next fetch and returns the build's state.
+//
+// The build's status and logs are fetched concurrently. Once the build has
+// stopped, its remaining logs are fetched before the final state is returned,
+// since they may have been written after the previous fetch of logs. If that
+// fails, the next call resumes fetching them.
+func (w *BuildWatcher) Next() BuildState {
+	if err := w.ctx.Err(); err != nil {
+		w.ticker.Stop()
+		return BuildState{err: err}
+	}
+	select {
+	case <-w.ctx.Done():
+		w.ticker.Stop()
+		return BuildState{err: w.ctx.Err()}
+	case <-w.ticker.C:
+	}
+
+	var state BuildState
+	var logs []LogItem
+	if w.stopped != nil {
+		state.Build, logs = *w.stopped, w.pending
+	} else {
+		var err error
+		if state, logs, err = w.fetch(); err != nil {
+			return BuildState{err: err}
+		}
+	}
+
+	if state.Stopped() {
+		for {
+			more, next, err := w.fetchLogs(w.ctx, w.prevToken)
+			if err != nil {
+				// The ticker keeps running so that the next call can
+				// resume draining the logs.
+				w.stopped, w.pending = &state.Build, logs
+				return BuildState{err: err}
+			}
+			logs = append(logs, more...)
+			if len(more) == 0 || next == w.prevToken {
+				break
+			}
+			w.prevToken = next
+		}
+		w.ticker.Stop()
+		w.stopped, w.pending = nil, nil
+	}
+
+	SortLogs(logs)
+	state.Logs = logs
+	return state
+}
+
+// fetch fetches the build and the logs written since the previous fetch.
+func (w *BuildWatcher) fetch() (BuildState, []LogItem, error) {
+	var state BuildState
+	var logs []LogItem
+	var token string
+	eg, ctx := errgroup.WithContext(w.ctx)
+	eg.Go(func() error {
+		resp, err := w.client.GetBuild(ctx, w.buildID)
+		if err != nil {
+			return errors.Wrap(err, "getting build")
+		}
+		state.Build = resp.Build
+		return nil
+	})
+	eg.Go(func() error {
+		var err error
+		logs, token, err = w.fetchLogs(ctx, w.prevToken)
+		return err
+	})
+	if err := eg.Wait(); err != nil {
+		// The logs are fetched again by the next call, so that watching
+		// can resume after a failed fetch without missing any.
+		return BuildState{}, nil, err
+	}
+	w.prevToken = token
+	return state, logs, nil
+}
+
+// fetchLogs fetches the logs after token, and returns the token to fetch the
+// logs after them.
+func (w *BuildWatcher) fetchLogs(ctx context.Context, token string) ([]LogItem, string, error) {
+	resp, err := w.client.GetBuildLogs(ctx, w.buildID, token)
+	if err != nil {
+		return nil, token, errors.Wrap(err, "getting build logs")
+	}
+	if len(resp.Logs) > 0 {
+		token = resp.PrevPageToken
+	}
+	return resp.Logs, token, nil
+}
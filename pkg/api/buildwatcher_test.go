@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type buildLogsClientMock struct {
+	getBuild     func(id string) (GetBuildResponse, error)
+	getBuildLogs func(buildID, prevToken string) (GetBuildLogsResponse, error)
+}
+
+func (m buildLogsClientMock) GetBuild(ctx context.Context, id string) (GetBuildResponse, error) {
+	return m.getBuild(id)
+}
+
+func (m buildLogsClientMock) GetBuildLogs(ctx context.Context, buildID, prevToken string) (GetBuildLogsResponse, error) {
+	return m.getBuildLogs(buildID, prevToken)
+}
+
+func TestBuildWatcher(t *testing.T) {
+	require := require.New(t)
+
+	var logs []LogItem
+	for i := 0; i < 4; i++ {
+		logs = append(logs, LogItem{InsertID: fmt.Sprintf("%03d", i), Text: fmt.Sprint(i)})
+	}
+
+	// The build stops on the second fetch, with three logs left unread that
+	// are served a page at a time.
+	var fetches int64
+	m := buildLogsClientMock{
+		getBuild: func(id string) (GetBuildResponse, error) {
+			if atomic.AddInt64(&fetches, 1) < 2 {
+				return GetBuildResponse{Build: Build{ID: id, Status: BuildActive}}, nil
+			}
+			return GetBuildResponse{Build: Build{ID: id, Status: BuildSucceeded}}, nil
+		},
+		getBuildLogs: func(buildID, prevToken string) (GetBuildLogsResponse, error) {
+			next := 0
+			if prevToken != "" {
+				fmt.Sscan(prevToken, &next)
+			}
+			if next >= len(logs) || (next > 0 && atomic.LoadInt64(&fetches) < 2) {
+				return GetBuildLogsResponse{}, nil
+			}
+			return GetBuildLogsResponse{
+				Logs:          logs[next : next+1],
+				PrevPageToken: fmt.Sprint(next + 1),
+			}, nil
+		},
+	}
+
+	w := NewBuildWatcher(context.Background(), m, "bld")
+	var printed []string
+	var state BuildState
+	for {
+		state = w.Next()
+		require.NoError(state.Err())
+		for _, l := range state.Logs {
+			printed = append(printed, l.Text)
+		}
+		if state.Stopped() {
+			break
+		}
+	}
+
+	require.Equal(BuildSucceeded, state.Build.Status)
+	require.Equal([]string{"0", "1", "2", "3"}, printed)
+}
+
+func TestBuildWatcherCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := NewBuildWatcher(ctx, buildLogsClientMock{}, "bld")
+	require.ErrorIs(t, w.Next().Err(), context.Canceled)
+}
+
+func TestBuildWatcherResumes(t *testing.T) {
+	require := require.New(t)
+
+	// The first fetch of the build fails after its logs have been fetched,
+	// which must be fetched again rather than skipped.
+	var fetches int64
+	m := buildLogsClientMock{
+		getBuild: func(id string) (GetBuildResponse, error) {
+			if atomic.AddInt64(&fetches, 1) == 1 {
+				return GetBuildResponse{}, unavailableError{fmt.Errorf("connection reset by peer")}
+			}
+			return GetBuildResponse{Build: Build{ID: id, Status: BuildSucceeded}}, nil
+		},
+		getBuildLogs: func(buildID, prevToken string) (GetBuildLogsResponse, error) {
+			if prevToken != "" {
+				return GetBuildLogsResponse{}, nil
+			}
+			return GetBuildLogsResponse{
+				Logs:          []LogItem{{InsertID: "000", Text: "0"}},
+				PrevPageToken: "1",
+			}, nil
+		},
+	}
+
+	w := NewBuildWatcher(context.Background(), m, "bld")
+	state := w.Next()
+	require.True(IsUnavailable(state.Err()))
+
+	state = w.Next()
+	require.NoError(state.Err())
+	require.True(state.Stopped())
+	require.Len(state.Logs, 1)
+	require.Equal("0", state.Logs[0].Text)
+}
+
+func TestBuildWatcherResumesDrain(t *testing.T) {
+	require := require.New(t)
+
+	// The build has stopped on the first fetch, and draining its remaining
+	// logs fails once: the next call must resume the drain rather than
+	// block on a stopped ticker.
+	var drains int64
+	m := buildLogsClientMock{
+		getBuild: func(id string) (GetBuildResponse, error) {
+			return GetBuildResponse{Build: Build{ID: id, Status: BuildSucceeded}}, nil
+		},
+		getBuildLogs: func(buildID, prevToken string) (GetBuildLogsResponse, error) {
+			switch prevToken {
+			case "":
+				return GetBuildLogsResponse{
+					Logs:          []LogItem{{InsertID: "000", Text: "0"}},
+					PrevPageToken: "1",
+				}, nil
+			case "1":
+				if atomic.AddInt64(&drains, 1) == 1 {
+					return GetBuildLogsResponse{}, unavailableError{fmt.Errorf("connection reset by peer")}
+				}
+				return GetBuildLogsResponse{
+					Logs:          []LogItem{{InsertID: "001", Text: "1"}},
+					PrevPageToken: "2",
+				}, nil
+			}
+			return GetBuildLogsResponse{}, nil
+		},
+	}
+
+	w := NewBuildWatcher(context.Background(), m, "bld")
+	state := w.Next()
+	require.True(IsUnavailable(state.Err()))
+
+	done := make(chan BuildState)
+	go func() { done <- w.Next() }()
+	select {
+	case state = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next blocked after a failed drain")
+	}
+	require.NoError(state.Err())
+	require.True(state.Stopped())
+	var printed []string
+	for _, l := range state.Logs {
+		printed = append(printed, l.Text)
+	}
+	require.Equal([]string{"0", "1"}, printed)
+}
@@ -4,6 +4,8 @@ package api
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -23,26 +25,76 @@ import (
 var (
 	// Client tolerates minor outages and retries.
 	client *http.Client
+
+	retryClient *retryablehttp.Client
 )
 
+// DefaultMaxRetries is the number of times a failed request is retried by default.
+const DefaultMaxRetries = 5
+
 func init() {
-	rc := retryablehttp.NewClient()
-	rc.RetryMax = 5
-	rc.RetryWaitMin = 50 * time.Millisecond
-	rc.RetryWaitMax = 1 * time.Second
-	rc.Logger = logger.HTTPLogger{} // Logs messages as debug output
-	client = rc.StandardClient()
+	retryClient = retryablehttp.NewClient()
+	retryClient.RetryMax = DefaultMaxRetries
+	retryClient.RetryWaitMin = 50 * time.Millisecond
+	retryClient.RetryWaitMax = 1 * time.Second
+	retryClient.Logger = logger.HTTPLogger{MaxRetries: DefaultMaxRetries} // Logs retries as info, everything else as debug output
+	client = retryClient.StandardClient()
+}
+
+// SetMaxRetries sets how many times failed requests are retried.
+// Zero disables retries.
+func SetMaxRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	retryClient.RetryMax = n
+	retryClient.Logger = logger.HTTPLogger{MaxRetries: n}
 }
 
 // Error represents an API error.
 type Error struct {
 	Code    int
 	Message string `json:"error"`
+
+	// RequestID is the ID the CLI attached to the failed request.
+	RequestID string `json:"-"`
+	// ServerRequestID is the ID the API assigned to the request, if any.
+	ServerRequestID string `json:"-"`
 }
 
 // Error implementation.
 func (err Error) Error() string {
-	return fmt.Sprintf("api: %d - %s", err.Code, err.Message)
+	return fmt.Sprintf("api: %d - %s%s", err.Code, err.Message, requestIDSuffix(err.RequestID, err.ServerRequestID))
+}
+
+const (
+	// requestIDHeader carries the ID generated by the CLI for every request.
+	requestIDHeader = "X-Airplane-Request-ID"
+	// serverRequestIDHeader carries the ID assigned to a request by the API.
+	serverRequestIDHeader = "X-Request-ID"
+)
+
+// newRequestID returns a random ID to attach to a request, so that support
+// can find a failed CLI action in server logs.
+func newRequestID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+func requestIDSuffix(id, serverID string) string {
+	switch {
+	case id == "" && serverID == "":
+		return ""
+	case serverID == "" || serverID == id:
+		return fmt.Sprintf(" (request ID: %s)", id)
+	case id == "":
+		return fmt.Sprintf(" (server request ID: %s)", serverID)
+	default:
+		return fmt.Sprintf(" (request ID: %s, server request ID: %s)", id, serverID)
+	}
 }
 
 const (
@@ -76,10 +128,9 @@ type Client struct {
 
 // AppURL returns the app URL.
 func (c Client) appURL() *url.URL {
-	apphost := c.host()
-	apphost = strings.ReplaceAll(apphost, "api.airstage.app", "web.airstage.app")
-	apphost = strings.ReplaceAll(apphost, "api", "app")
-	u, _ := url.Parse("https://" + apphost)
+	u, _ := url.Parse(c.baseURL())
+	u.Host = strings.ReplaceAll(u.Host, "api.airstage.app", "web.airstage.app")
+	u.Host = strings.ReplaceAll(u.Host, "api", "app")
 	return u
 }
 
@@ -91,25 +142,26 @@ func (c Client) LoginURL(uri string) string {
 	return u.String()
 }
 
-// LoginSuccessURL returns a URL showing a message that logging in was successful.
-func (c Client) LoginSuccessURL() string {
+// AppURL returns the URL of path in the web app, such as "/settings".
+func (c Client) AppURL(path string) string {
 	u := c.appURL()
-	u.Path = "/cli/success"
+	u.Path = path
 	return u.String()
 }
 
+// LoginSuccessURL returns a URL showing a message that logging in was successful.
+func (c Client) LoginSuccessURL() string {
+	return c.AppURL("/cli/success")
+}
+
 // RunURL returns a run URL for a run ID.
 func (c Client) RunURL(id string) string {
-	u := c.appURL()
-	u.Path = "/runs/" + id
-	return u.String()
+	return c.AppURL("/runs/" + id)
 }
 
 // TaskURL returns a task URL for a task slug.
 func (c Client) TaskURL(slug string) string {
-	u := c.appURL()
-	u.Path = "/t/" + slug
-	return u.String()
+	return c.AppURL("/t/" + slug)
 }
 
 // AuthInfo responds with the currently authenticated details.
@@ -127,6 +179,9 @@ func (c Client) GetRegistryToken(ctx context.Context) (res RegistryTokenResponse
 // CreateTask creates a task with the given request.
 func (c Client) CreateTask(ctx context.Context, req CreateTaskRequest) (res CreateTaskResponse, err error) {
 	err = c.do(ctx, "POST", "/tasks/create", req, &res)
+	if err == nil {
+		c.rememberTaskID(res.Slug, res.TaskID)
+	}
 	return
 }
 
@@ -136,18 +191,75 @@ func (c Client) UpdateTask(ctx context.Context, req UpdateTaskRequest) (res Upda
 	return
 }
 
-// ListTasks lists all tasks.
-func (c Client) ListTasks(ctx context.Context) (res ListTasksResponse, err error) {
-	err = c.do(ctx, "GET", "/tasks/list", nil, &res)
-	if err != nil {
-		return
-	}
-	for j, t := range res.Tasks {
-		res.Tasks[j].URL = c.TaskURL(t.Slug)
+// ListTaskRevisions lists a task's revisions, newest first.
+func (c Client) ListTaskRevisions(ctx context.Context, taskID string, limit int) (res ListTaskRevisionsResponse, err error) {
+	q := url.Values{"taskID": []string{taskID}}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
 	}
+	err = c.do(ctx, "GET", "/tasks/listRevisions?"+q.Encode(), nil, &res)
 	return
 }
 
+// ListTasks lists tasks, fetching as many pages as needed to satisfy the
+// request's limit.
+func (c Client) ListTasks(ctx context.Context, req ListTasksRequest) (ListTasksResponse, error) {
+	q := url.Values{}
+	pageLimit := 100
+	if req.Limit > 0 && req.Limit < 100 {
+		// If a user provides a smaller limit, fetch exactly that many items.
+		pageLimit = req.Limit
+	}
+	q.Set("limit", strconv.FormatInt(int64(pageLimit), 10))
+	if req.Owner != "" {
+		q.Set("owner", req.Owner)
+	}
+
+	var resp ListTasksResponse
+	for i := req.Page; ; i++ {
+		q.Set("page", strconv.FormatInt(int64(i), 10))
+		var page ListTasksResponse
+		if err := c.do(ctx, "GET", "/tasks/list?"+q.Encode(), nil, &page); err != nil {
+			return ListTasksResponse{}, err
+		}
+		tasks := page.Tasks
+		if req.Owner != "" {
+			// Filter here too, for APIs that ignore the owner parameter.
+			tasks = filterByOwner(tasks, req.Owner)
+		}
+		if req.Limit > 0 && len(resp.Tasks)+len(tasks) > req.Limit {
+			// Truncate the response if we over-fetched items:
+			tasks = tasks[:req.Limit-len(resp.Tasks)]
+		}
+		resp.Tasks = append(resp.Tasks, tasks...)
+
+		// There are no more items to fetch:
+		if len(page.Tasks) != pageLimit {
+			break
+		}
+		// We have reached the requested limit of items to fetch:
+		if req.Limit > 0 && len(resp.Tasks) == req.Limit {
+			break
+		}
+	}
+
+	for j, t := range resp.Tasks {
+		resp.Tasks[j].URL = c.TaskURL(t.Slug)
+	}
+	return resp, nil
+}
+
+// filterByOwner returns the tasks whose OwnerEmail is email, ignoring case.
+func filterByOwner(tasks []Task, email string) []Task {
+	var res []Task
+	for _, t := range tasks {
+		if strings.EqualFold(t.OwnerEmail(), email) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
 // GetUniqueSlug gets a unique slug based on the given name.
 func (c Client) GetUniqueSlug(ctx context.Context, name, preferredSlug string) (res GetUniqueSlugResponse, err error) {
 	q := url.Values{
@@ -177,6 +289,18 @@ func (c Client) ListRuns(ctx context.Context, req ListRunsRequest) (ListRunsResp
 	if !req.Until.IsZero() {
 		q.Set("until", req.Until.Format(time.RFC3339))
 	}
+	for k, v := range req.Labels {
+		q.Add("label", k+"="+v)
+	}
+	for _, status := range req.Statuses {
+		q.Add("status", string(status))
+	}
+	if req.CreatorID != "" {
+		q.Set("creatorID", req.CreatorID)
+	}
+	if req.Query != "" {
+		q.Set("query", req.Query)
+	}
 
 	var resp ListRunsResponse
 	var page ListRunsResponse
@@ -207,19 +331,29 @@ func (c Client) ListRuns(ctx context.Context, req ListRunsRequest) (ListRunsResp
 	return resp, nil
 }
 
+// PauseTask pauses a task for maintenance, or resumes it.
+func (c Client) PauseTask(ctx context.Context, req PauseTaskRequest) (err error) {
+	err = c.do(ctx, "POST", "/tasks/pause", req, nil)
+	return
+}
+
 // RunTask runs a task.
 func (c Client) RunTask(ctx context.Context, req RunTaskRequest) (res RunTaskResponse, err error) {
 	err = c.do(ctx, "POST", "/tasks/execute", req, &res)
+	// The API refuses runs of paused tasks with 423 Locked.
+	if apiErr, ok := err.(Error); ok && apiErr.Code == 423 {
+		return res, NewTaskPausedError(apiErr.Message)
+	}
 	return
 }
 
 // Watcher runs a task with the given arguments and returns a run watcher.
-func (c Client) Watcher(ctx context.Context, req RunTaskRequest) (*Watcher, error) {
+func (c Client) Watcher(ctx context.Context, req RunTaskRequest, opts ...WatcherOption) (*Watcher, error) {
 	resp, err := c.RunTask(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-	return newWatcher(ctx, c, resp.RunID), nil
+	return NewWatcher(ctx, c, resp.RunID, opts...), nil
 }
 
 // GetRun returns a run by id.
@@ -249,12 +383,19 @@ func (c Client) GetOutputs(ctx context.Context, runID string) (res GetOutputsRes
 	return
 }
 
+// CancelRun cancels a queued or active run.
+func (c Client) CancelRun(ctx context.Context, runID string) (err error) {
+	err = c.do(ctx, "POST", "/runs/cancel", CancelRunRequest{RunID: runID}, nil)
+	return
+}
+
 // GetTask returns a task by its slug.
 func (c Client) GetTask(ctx context.Context, slug string) (res Task, err error) {
 	q := url.Values{"slug": []string{slug}}
 	err = c.do(ctx, "GET", "/tasks/get?"+q.Encode(), nil, &res)
 
 	if err, ok := err.(Error); ok && err.Code == 404 {
+		c.forgetTaskID(slug)
 		return res, &TaskMissingError{
 			appURL: c.appURL().String(),
 			slug:   slug,
@@ -265,12 +406,16 @@ func (c Client) GetTask(ctx context.Context, slug string) (res Task, err error)
 		return
 	}
 	res.URL = c.TaskURL(res.Slug)
+	c.rememberTaskID(slug, res.ID)
 	return
 }
 
 // GetConfig returns a config by name and tag.
 func (c Client) GetConfig(ctx context.Context, req GetConfigRequest) (res GetConfigResponse, err error) {
 	err = c.do(ctx, "POST", "/configs/get", req, &res)
+	if err == nil && req.ShowSecret && res.Config.IsSecret {
+		logger.Redact(res.Config.Value)
+	}
 	return
 }
 
@@ -280,6 +425,84 @@ func (c Client) SetConfig(ctx context.Context, req SetConfigRequest) (err error)
 	return
 }
 
+// ListConfigs lists all configs.
+func (c Client) ListConfigs(ctx context.Context, req ListConfigsRequest) (res ListConfigsResponse, err error) {
+	q := url.Values{"showSecret": []string{strconv.FormatBool(req.ShowSecret)}}
+	err = c.do(ctx, "GET", "/configs/list?"+q.Encode(), nil, &res)
+	if err == nil && req.ShowSecret {
+		for _, cfg := range res.Configs {
+			if cfg.IsSecret {
+				logger.Redact(cfg.Value)
+			}
+		}
+	}
+	return
+}
+
+// ListEnvGroups lists all env groups.
+func (c Client) ListEnvGroups(ctx context.Context) (res ListEnvGroupsResponse, err error) {
+	err = c.do(ctx, "GET", "/envGroups/list", nil, &res)
+	return
+}
+
+// GetEnvGroup returns an env group by name.
+func (c Client) GetEnvGroup(ctx context.Context, name string) (res GetEnvGroupResponse, err error) {
+	q := url.Values{"name": []string{name}}
+	err = c.do(ctx, "GET", "/envGroups/get?"+q.Encode(), nil, &res)
+
+	if err, ok := err.(Error); ok && err.Code == 404 {
+		return res, &EnvGroupMissingError{name: name}
+	}
+	return
+}
+
+// SetEnvGroup sets an env group, creating it if new and replacing its env
+// vars if it already exists.
+func (c Client) SetEnvGroup(ctx context.Context, req SetEnvGroupRequest) (err error) {
+	err = c.do(ctx, "POST", "/envGroups/set", req, nil)
+	return
+}
+
+// DeleteEnvGroup deletes an env group.
+func (c Client) DeleteEnvGroup(ctx context.Context, req DeleteEnvGroupRequest) (err error) {
+	err = c.do(ctx, "POST", "/envGroups/delete", req, nil)
+	return
+}
+
+// CreateSchedule creates a schedule for a task.
+func (c Client) CreateSchedule(ctx context.Context, req CreateScheduleRequest) (res CreateScheduleResponse, err error) {
+	err = c.do(ctx, "POST", "/schedules/create", req, &res)
+	return
+}
+
+// ListSchedules lists schedules, optionally only those of one task.
+func (c Client) ListSchedules(ctx context.Context, req ListSchedulesRequest) (res ListSchedulesResponse, err error) {
+	q := url.Values{}
+	if req.TaskID != "" {
+		q.Set("taskID", req.TaskID)
+	}
+	err = c.do(ctx, "GET", "/schedules/list?"+q.Encode(), nil, &res)
+	return
+}
+
+// PauseSchedule pauses or resumes a schedule.
+func (c Client) PauseSchedule(ctx context.Context, req PauseScheduleRequest) (err error) {
+	err = c.do(ctx, "POST", "/schedules/pause", req, nil)
+	return
+}
+
+// DeleteSchedule deletes a schedule.
+func (c Client) DeleteSchedule(ctx context.Context, req DeleteScheduleRequest) (err error) {
+	err = c.do(ctx, "POST", "/schedules/delete", req, nil)
+	return
+}
+
+// ListAgents lists the agents registered with the team.
+func (c Client) ListAgents(ctx context.Context) (res ListAgentsResponse, err error) {
+	err = c.do(ctx, "GET", "/agents/list", nil, &res)
+	return
+}
+
 // GetBuild returns metadata about a hosted build.
 func (c Client) GetBuild(ctx context.Context, id string) (res GetBuildResponse, err error) {
 	q := url.Values{"id": []string{id}}
@@ -290,6 +513,13 @@ func (c Client) GetBuild(ctx context.Context, id string) (res GetBuildResponse,
 // CreateBuild creates an Airplane build and returns metadata about it.
 func (c Client) CreateBuild(ctx context.Context, req CreateBuildRequest) (res CreateBuildResponse, err error) {
 	err = c.do(ctx, "POST", "/builds/create", req, &res)
+	if apiErr, ok := err.(Error); ok && req.Builder != nil && (apiErr.Code == 402 || apiErr.Code == 403) {
+		return res, &BuilderNotAllowedError{
+			appURL:  c.appURL().String(),
+			builder: *req.Builder,
+			message: apiErr.Message,
+		}
+	}
 	return
 }
 
@@ -317,6 +547,42 @@ func (c Client) DeleteAPIKey(ctx context.Context, req DeleteAPIKeyRequest) (err
 	return
 }
 
+// CheckPermissions reports whether the caller may perform each of the checks.
+func (c Client) CheckPermissions(ctx context.Context, req CheckPermissionsRequest) (res CheckPermissionsResponse, err error) {
+	err = c.do(ctx, "POST", "/permissions/check", req, &res)
+	return
+}
+
+// RequirePermissions returns a PermissionDeniedError listing every check the
+// caller fails, so that commands can stop before doing slow work that the API
+// would reject at the end.
+//
+// APIs without the permissions endpoint pass every check, and the command
+// finds out from the request that needs the permission, as before.
+func (c Client) RequirePermissions(ctx context.Context, checks ...PermissionCheck) error {
+	if len(checks) == 0 {
+		return nil
+	}
+	res, err := c.CheckPermissions(ctx, CheckPermissionsRequest{Checks: checks})
+	if apiErr, ok := err.(Error); ok && apiErr.Code == 404 {
+		logger.Debug("permissions check unavailable, skipping: %v", apiErr)
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "checking permissions")
+	}
+
+	var denied []PermissionCheck
+	for _, r := range res.Results {
+		if !r.Allowed {
+			denied = append(denied, r.PermissionCheck)
+		}
+	}
+	if len(denied) > 0 {
+		return &PermissionDeniedError{Denied: denied}
+	}
+	return nil
+}
+
 func (c Client) GetBuildLogs(ctx context.Context, buildID string, prevToken string) (res GetBuildLogsResponse, err error) {
 	q := url.Values{
 		"buildID": []string{buildID},
@@ -338,7 +604,7 @@ func (c Client) ListResources(ctx context.Context) (res ListResourcesResponse, e
 
 // Do sends a request with `method`, `path`, `payload` and `reply`.
 func (c Client) do(ctx context.Context, method, path string, payload, reply interface{}) error {
-	var url = "https://" + c.host() + "/v0" + path
+	var url = c.baseURL() + "/v0" + path
 	var body io.Reader
 
 	if payload != nil {
@@ -370,6 +636,8 @@ func (c Client) do(ctx context.Context, method, path string, payload, reply inte
 
 	req.Header.Set("X-Airplane-Client", "cli")
 	req.Header.Set("X-Airplane-Version", version.Get())
+	requestID := newRequestID()
+	req.Header.Set(requestIDHeader, requestID)
 
 	resp, err := client.Do(req)
 
@@ -381,18 +649,26 @@ func (c Client) do(ctx context.Context, method, path string, payload, reply inte
 	}
 
 	if err != nil {
-		return errors.Wrapf(err, "api: %s %s", method, url)
+		return errors.Wrapf(unavailableError{err}, "api: %s %s%s", method, url, requestIDSuffix(requestID, ""))
 	}
 
 	if resp.StatusCode >= 400 && resp.StatusCode < 600 {
 		var errt Error
+		serverRequestID := resp.Header.Get(serverRequestIDHeader)
 
 		if err := json.NewDecoder(resp.Body).Decode(&errt); err == nil {
 			errt.Code = resp.StatusCode
+			errt.RequestID = requestID
+			errt.ServerRequestID = serverRequestID
 			return errt
 		}
 
-		return errors.Errorf("api: %s %s - %s", method, url, resp.Status)
+		return Error{
+			Code:            resp.StatusCode,
+			Message:         fmt.Sprintf("%s %s - %s", method, url, resp.Status),
+			RequestID:       requestID,
+			ServerRequestID: serverRequestID,
+		}
 	}
 
 	if reply != nil {
@@ -411,3 +687,13 @@ func (c Client) host() string {
 	}
 	return Host
 }
+
+// baseURL returns the scheme and host of the API. The host may include a
+// scheme, such as http://localhost:5000 for a local or self-hosted API, and
+// otherwise uses https.
+func (c Client) baseURL() string {
+	if h := c.host(); strings.Contains(h, "://") {
+		return strings.TrimSuffix(h, "/")
+	}
+	return "https://" + c.host()
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+var (
+	// downloadRetries is how many times a download is resumed after a
+	// failure before it is given up on.
+	downloadRetries = 5
+
+	// downloadRetryWait is how long to wait before the first retry. Later
+	// retries back off exponentially.
+	downloadRetryWait = time.Second
+
+	// downloadClient sends download requests. Signed URLs carry their own
+	// credentials, so they don't go through the API's client, which would
+	// also restart failed downloads from the first byte.
+	downloadClient = DirectClient
+)
+
+// DownloadOptions configures a download.
+type DownloadOptions struct {
+	// SHA256 is the hex digest the downloaded file must have. If empty, the
+	// file is checked against the MD5 the storage service reports in its
+	// x-goog-hash or Content-MD5 header, if any.
+	SHA256 string
+	// Progress, if set, is called as bytes are written, with the bytes
+	// written so far and the file's size, or -1 if the size is unknown.
+	Progress func(written, size int64)
+}
+
+// ChecksumError is returned when a downloaded file doesn't match its
+// expected checksum.
+type ChecksumError struct {
+	Algorithm string
+	Want      string
+	Got       string
+}
+
+// Error implementation.
+func (err *ChecksumError) Error() string {
+	return fmt.Sprintf("download is corrupt: expected %s %s, got %s", err.Algorithm, err.Want, err.Got)
+}
+
+// Download downloads the file at a signed URL, such as a run's upload or a
+// build's artifact, to w.
+//
+// A download that fails part way is resumed with a range request for the
+// bytes that are still missing, with backoff between attempts. Once every
+// byte is written, the file is verified against its checksum; w will have
+// received the whole file even when a ChecksumError is returned.
+func (c Client) Download(ctx context.Context, url string, w io.Writer, opts DownloadOptions) (int64, error) {
+	d := &download{url: url, w: w, opts: opts, size: -1, sha: sha256.New()}
+	for attempt := 0; ; attempt++ {
+		err := d.fetch(ctx)
+		if err == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return d.written, ctx.Err()
+		}
+		if attempt >= downloadRetries || !retryableDownload(err) {
+			return d.written, errors.Wrap(err, "downloading")
+		}
+		wait := downloadRetryWait << attempt
+		logger.Log(logger.Gray("Download interrupted (%v), retrying in %s...", err, wait))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return d.written, ctx.Err()
+		}
+	}
+	return d.written, d.verify()
+}
+
+// DownloadFile downloads the file at a signed URL to path. The file is only
+// written to path once it is complete and verified, so that an interrupted
+// download never leaves a partial file behind.
+func (c Client) DownloadFile(ctx context.Context, url string, path string, opts DownloadOptions) (int64, error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, errors.Wrap(err, "creating download file")
+	}
+	defer os.Remove(f.Name())
+
+	n, err := c.Download(ctx, url, f, opts)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = errors.Wrap(cerr, "writing download file")
+	}
+	if err != nil {
+		return n, err
+	}
+	return n, errors.Wrap(os.Rename(f.Name(), path), "saving download")
+}
+
+// download is the state of a download across attempts.
+type download struct {
+	url     string
+	w       io.Writer
+	opts    DownloadOptions
+	written int64
+	size    int64
+	sha     hash.Hash
+	md5     hash.Hash
+	wantMD5 string
+}
+
+// fetch requests the bytes that haven't been written yet and writes them.
+func (d *download) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", d.url, nil)
+	if err != nil {
+		return errors.Wrap(err, "creating download request")
+	}
+	if d.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", d.written))
+	}
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var skip int64
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, so the bytes already written are
+		// read again and dropped.
+		skip = d.written
+		if resp.ContentLength >= 0 {
+			d.size = resp.ContentLength
+		}
+	case resp.StatusCode == http.StatusPartialContent && d.written > 0:
+		if size, ok := rangeSize(resp.Header.Get("Content-Range")); ok {
+			d.size = size
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && d.written > 0 && d.written == d.size:
+		return nil
+	default:
+		buf, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(buf))}
+	}
+	if d.md5 == nil {
+		if want := reportedMD5(resp.Header); want != "" {
+			d.md5, d.wantMD5 = md5.New(), want
+		}
+	}
+
+	if skip > 0 {
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, skip); err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, 32<<10)
+	for {
+		n, rerr := resp.Body.Read(buf)
+		if n > 0 {
+			if _, err := d.w.Write(buf[:n]); err != nil {
+				// Failing to write locally isn't worth retrying.
+				return &writeError{err}
+			}
+			d.sha.Write(buf[:n])
+			if d.md5 != nil {
+				d.md5.Write(buf[:n])
+			}
+			d.written += int64(n)
+			if d.opts.Progress != nil {
+				d.opts.Progress(d.written, d.size)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		} else if rerr != nil {
+			return rerr
+		}
+	}
+	if d.size >= 0 && d.written < d.size {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// verify checks the downloaded bytes against their expected checksum.
+func (d *download) verify() error {
+	if want := strings.ToLower(d.opts.SHA256); want != "" {
+		if got := hex.EncodeToString(d.sha.Sum(nil)); got != want {
+			return &ChecksumError{Algorithm: "sha256", Want: want, Got: got}
+		}
+		return nil
+	}
+	if d.md5 != nil {
+		if got := base64.StdEncoding.EncodeToString(d.md5.Sum(nil)); got != d.wantMD5 {
+			return &ChecksumError{Algorithm: "md5", Want: d.wantMD5, Got: got}
+		}
+	}
+	return nil
+}
+
+// reportedMD5 returns the base64 MD5 of an object that GCS reports in
+// x-goog-hash, or that other storage services report in Content-MD5.
+func reportedMD5(h http.Header) string {
+	for _, v := range h.Values("X-Goog-Hash") {
+		for _, part := range strings.Split(v, ",") {
+			if md5 := strings.TrimPrefix(strings.TrimSpace(part), "md5="); md5 != strings.TrimSpace(part) {
+				return md5
+			}
+		}
+	}
+	return h.Get("Content-MD5")
+}
+
+// rangeSize returns the complete length from a Content-Range header, such as
+// 1000 from "bytes 200-999/1000".
+func rangeSize(contentRange string) (int64, bool) {
+	i := strings.LastIndex(contentRange, "/")
+	if i < 0 {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	return size, err == nil
+}
+
+type statusError struct {
+	code int
+	body string
+}
+
+func (err *statusError) Error() string {
+	if err.body == "" {
+		return fmt.Sprintf("%d %s", err.code, http.StatusText(err.code))
+	}
+	return fmt.Sprintf("%d %s: %s", err.code, http.StatusText(err.code), err.body)
+}
+
+type writeError struct {
+	err error
+}
+
+func (err *writeError) Error() string {
+	return err.err.Error()
+}
+
+// retryableDownload reports whether a failed attempt is worth retrying:
+// network errors and server errors are, but an expired or forbidden signed
+// URL is not.
+func retryableDownload(err error) bool {
+	var werr *writeError
+	if errors.As(err, &werr) {
+		return false
+	}
+	var serr *statusError
+	if errors.As(err, &serr) {
+		return serr.code == http.StatusTooManyRequests || serr.code >= 500
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownload(t *testing.T) {
+	downloadRetryWait = time.Millisecond
+	data := bytes.Repeat([]byte("airplane"), 10000)
+	sha := sha256.Sum256(data)
+	md := md5.Sum(data)
+
+	// The server drops the connection half way through the first request,
+	// then serves the rest with a range request.
+	var requests int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&requests, 1)
+		w.Header().Set("X-Goog-Hash", "crc32c=AAAAAA==,md5="+base64.StdEncoding.EncodeToString(md[:]))
+		if r.URL.Path == "/expired" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		start := 0
+		if rng := r.Header.Get("Range"); rng != "" {
+			start, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)-start))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		}
+		if n == 1 {
+			w.Write(data[:len(data)/2])
+			w.(http.Flusher).Flush()
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Write(data[start:])
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	var c Client
+
+	t.Run("resumes", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 0)
+		var buf bytes.Buffer
+		var progress int64
+		n, err := c.Download(ctx, srv.URL+"/file", &buf, DownloadOptions{
+			SHA256:   hex.EncodeToString(sha[:]),
+			Progress: func(written, size int64) { progress = written },
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(len(data)), n)
+		require.Equal(t, int64(len(data)), progress)
+		require.Equal(t, data, buf.Bytes())
+		require.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("checks reported md5", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 1)
+		var buf bytes.Buffer
+		_, err := c.Download(ctx, srv.URL+"/file", &buf, DownloadOptions{})
+		require.NoError(t, err)
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 1)
+		var buf bytes.Buffer
+		_, err := c.Download(ctx, srv.URL+"/file", &buf, DownloadOptions{SHA256: strings.Repeat("0", 64)})
+		var cerr *ChecksumError
+		require.ErrorAs(t, err, &cerr)
+		require.Equal(t, "sha256", cerr.Algorithm)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 1)
+		var buf bytes.Buffer
+		_, err := c.Download(ctx, srv.URL+"/expired", &buf, DownloadOptions{})
+		require.EqualError(t, err, "downloading: 403 Forbidden")
+		require.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	})
+
+	t.Run("file", func(t *testing.T) {
+		atomic.StoreInt64(&requests, 1)
+		path := filepath.Join(t.TempDir(), "file")
+		_, err := c.DownloadFile(ctx, srv.URL+"/file", path, DownloadOptions{})
+		require.NoError(t, err)
+		buf, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, data, buf)
+
+		_, err = c.DownloadFile(ctx, srv.URL+"/expired", filepath.Join(filepath.Dir(path), "expired"), DownloadOptions{})
+		require.Error(t, err)
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+}
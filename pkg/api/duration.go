@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is a length of time in whole seconds, such as a task's timeout.
+//
+// It is sent to the API, and written to definitions, as a number of seconds,
+// but can also be read from a string such as "120s", "5m" or "2h".
+type Duration int
+
+// MaxTimeout is the longest timeout a task can have.
+const MaxTimeout Duration = 3600
+
+// ParseDuration parses a number of seconds, such as "120", or a duration
+// such as "5m" or "1h30m", as understood by time.ParseDuration. Negative
+// durations are rejected.
+func ParseDuration(s string) (Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return 0, errors.Errorf("invalid duration %q, expected a positive duration", s)
+		}
+		return Duration(n), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid duration %q, expected a number of seconds or a duration such as 5m", s)
+	}
+	if d < 0 {
+		return 0, errors.Errorf("invalid duration %q, expected a positive duration", s)
+	}
+	if d%time.Second != 0 {
+		return 0, errors.Errorf("invalid duration %q, expected a whole number of seconds", s)
+	}
+	return Duration(d / time.Second), nil
+}
+
+// ValidateTimeout returns an error unless d is a valid task timeout, more
+// than zero and at most MaxTimeout.
+func (d Duration) ValidateTimeout() error {
+	if d <= 0 || d > MaxTimeout {
+		return errors.Errorf("timeout must be more than 0s and at most %s, got %s", MaxTimeout, d)
+	}
+	return nil
+}
+
+// Std returns d as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d) * time.Second
+}
+
+// String returns d formatted like a time.Duration, such as "5m0s".
+func (d Duration) String() string {
+	return d.Std().String()
+}
+
+// UnmarshalJSON implementation.
+func (d *Duration) UnmarshalJSON(buf []byte) error {
+	var s string
+	if err := json.Unmarshal(buf, &s); err != nil {
+		var n int
+		if err := json.Unmarshal(buf, &n); err != nil {
+			return errors.New("expected a number of seconds or a duration such as 5m")
+		} else if n < 0 {
+			return errors.Errorf("invalid duration %d, expected a positive duration", n)
+		}
+		*d = Duration(n)
+		return nil
+	}
+	v, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// UnmarshalYAML implementation.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return errors.Errorf("line %d: expected a number of seconds or a duration such as 5m", node.Line)
+	}
+	v, err := ParseDuration(node.Value)
+	if err != nil {
+		return errors.Wrapf(err, "line %d", node.Line)
+	}
+	*d = v
+	return nil
+}
+
+// Set implements pflag.Value, so that a Duration can be passed as a flag.
+func (d *Duration) Set(s string) error {
+	v, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Type implements pflag.Value.
+func (d *Duration) Type() string {
+	return "duration"
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDuration(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want Duration
+	}{
+		{"120", 120},
+		{"120s", 120},
+		{"5m", 300},
+		{"1h30m", 5400},
+	} {
+		d, err := ParseDuration(test.in)
+		require.NoError(t, err, test.in)
+		require.Equal(t, test.want, d, test.in)
+	}
+
+	_, err := ParseDuration("1500ms")
+	require.EqualError(t, err, `invalid duration "1500ms", expected a whole number of seconds`)
+	_, err = ParseDuration("soon")
+	require.Error(t, err)
+	for _, in := range []string{"-5", "-5m", "-1h30m"} {
+		_, err = ParseDuration(in)
+		require.EqualError(t, err, `invalid duration "`+in+`", expected a positive duration`)
+	}
+	require.Error(t, json.Unmarshal([]byte(`{"timeout":-60}`), new(struct {
+		Timeout Duration `json:"timeout"`
+	})))
+
+	for _, test := range []struct {
+		d     Duration
+		valid bool
+	}{
+		{1, true},
+		{3600, true},
+		{0, false},
+		{-60, false},
+		{3601, false},
+		{7200, false},
+	} {
+		err := test.d.ValidateTimeout()
+		require.Equal(t, test.valid, err == nil, "%d: %v", test.d, err)
+	}
+
+	var req struct {
+		Timeout Duration `json:"timeout" yaml:"timeout"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"timeout":"2h"}`), &req))
+	require.Equal(t, 2*time.Hour, req.Timeout.Std())
+	require.NoError(t, json.Unmarshal([]byte(`{"timeout":60}`), &req))
+	require.Equal(t, Duration(60), req.Timeout)
+	require.NoError(t, yaml.Unmarshal([]byte("timeout: 5m"), &req))
+	require.Equal(t, Duration(300), req.Timeout)
+
+	// Durations are always sent as seconds, which older APIs expect.
+	buf, err := json.Marshal(req)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"timeout":300}`, string(buf))
+}
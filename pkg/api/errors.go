@@ -1,6 +1,11 @@
 package api
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // TaskMissingError implements an exaplainable error.
 type TaskMissingError struct {
@@ -8,6 +13,12 @@ type TaskMissingError struct {
 	slug   string
 }
 
+// NewTaskMissingError returns the error for a missing task, linking to
+// appURL to create it.
+func NewTaskMissingError(appURL, slug string) *TaskMissingError {
+	return &TaskMissingError{appURL: appURL, slug: slug}
+}
+
 // Error implementation.
 func (err TaskMissingError) Error() string {
 	return fmt.Sprintf("task with slug %q does not exist", err.slug)
@@ -20,3 +31,132 @@ func (err TaskMissingError) ExplainError() string {
 		err.appURL+"/tasks/new",
 	)
 }
+
+// EnvGroupMissingError implements an explainable error.
+type EnvGroupMissingError struct {
+	name string
+}
+
+// NewEnvGroupMissingError returns the error for a missing env group.
+func NewEnvGroupMissingError(name string) *EnvGroupMissingError {
+	return &EnvGroupMissingError{name: name}
+}
+
+// Error implementation.
+func (err EnvGroupMissingError) Error() string {
+	return fmt.Sprintf("env group %q does not exist", err.name)
+}
+
+// ExplainError implementation.
+func (err EnvGroupMissingError) ExplainError() string {
+	return fmt.Sprintf(
+		"Create it with:\n  airplane envgroups set %s KEY=value",
+		err.name,
+	)
+}
+
+// TaskPausedError implements an explainable error, for runs refused because
+// their task is paused for maintenance.
+type TaskPausedError struct {
+	message string
+}
+
+// NewTaskPausedError returns the error for a run of a paused task, with the
+// API's message.
+func NewTaskPausedError(message string) *TaskPausedError {
+	return &TaskPausedError{message: message}
+}
+
+// Error implementation.
+func (err TaskPausedError) Error() string {
+	if err.message == "" {
+		return "the task is paused for maintenance"
+	}
+	return err.message
+}
+
+// ExplainError implementation.
+func (err TaskPausedError) ExplainError() string {
+	return "While a task is paused, only team admins can execute it. To see why it's paused, and until when:\n  airplane tasks get <slug>"
+}
+
+// BuilderNotAllowedError implements an explainable error.
+type BuilderNotAllowedError struct {
+	appURL  string
+	builder BuilderOptions
+	message string
+}
+
+// Error implementation.
+func (err BuilderNotAllowedError) Error() string {
+	var parts []string
+	if err.builder.Size != "" {
+		parts = append(parts, fmt.Sprintf("size %s", err.builder.Size))
+	}
+	if err.builder.Region != "" {
+		parts = append(parts, fmt.Sprintf("region %s", err.builder.Region))
+	}
+	if err.builder.TimeoutSeconds != 0 {
+		parts = append(parts, fmt.Sprintf("a %ds timeout", err.builder.TimeoutSeconds))
+	}
+	msg := fmt.Sprintf("your plan does not allow builds with %s", strings.Join(parts, ", "))
+	if err.message != "" {
+		msg += ": " + err.message
+	}
+	return msg
+}
+
+// ExplainError implementation.
+func (err BuilderNotAllowedError) ExplainError() string {
+	return fmt.Sprintf(
+		"Build with the default builder by dropping --builder-size, --builder-region and --build-timeout,\nor build locally with --local. To change your plan, visit:\n%s",
+		err.appURL+"/settings/billing",
+	)
+}
+
+// unavailableError wraps errors sending a request, as opposed to errors
+// returned by the API.
+type unavailableError struct {
+	error
+}
+
+func (err unavailableError) Unwrap() error {
+	return err.error
+}
+
+// IsUnavailable reports whether err means the API could not be reached or
+// failed to handle a request, so that the request may succeed later.
+func IsUnavailable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= 500 || apiErr.Code == 429
+	}
+	var uerr unavailableError
+	return errors.As(err, &uerr)
+}
+
+// PermissionDeniedError implements an explainable error.
+type PermissionDeniedError struct {
+	Denied []PermissionCheck
+}
+
+// Error implementation.
+func (err PermissionDeniedError) Error() string {
+	var parts []string
+	for _, d := range err.Denied {
+		if d.TaskSlug != "" {
+			parts = append(parts, fmt.Sprintf("you lack %s on %s", d.Action, d.TaskSlug))
+		} else {
+			parts = append(parts, fmt.Sprintf("you lack %s", d.Action))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ExplainError implementation.
+func (err PermissionDeniedError) ExplainError() string {
+	return "Ask a team admin for access, or check which account you are logged in as with:\n  airplane auth info"
+}
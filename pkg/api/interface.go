@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"io"
+)
+
+// APIClient is the Airplane API, as implemented by Client.
+//
+// Code that talks to the API should accept an APIClient rather than a
+// *Client, so that it can be tested against the in-memory fake in
+// package apitest.
+type APIClient interface {
+	AppURL(path string) string
+	LoginURL(uri string) string
+	LoginSuccessURL() string
+	RunURL(id string) string
+	TaskURL(slug string) string
+
+	AuthInfo(ctx context.Context) (AuthInfoResponse, error)
+	GetRegistryToken(ctx context.Context) (RegistryTokenResponse, error)
+
+	CreateTask(ctx context.Context, req CreateTaskRequest) (CreateTaskResponse, error)
+	UpdateTask(ctx context.Context, req UpdateTaskRequest) (UpdateTaskResponse, error)
+	GetTask(ctx context.Context, slug string) (Task, error)
+	GetTaskID(ctx context.Context, slug string) (string, error)
+	GetTaskSlug(ctx context.Context, id string) (string, error)
+	ListTasks(ctx context.Context, req ListTasksRequest) (ListTasksResponse, error)
+	ListTaskRevisions(ctx context.Context, taskID string, limit int) (ListTaskRevisionsResponse, error)
+	GetUniqueSlug(ctx context.Context, name, preferredSlug string) (GetUniqueSlugResponse, error)
+	PauseTask(ctx context.Context, req PauseTaskRequest) error
+
+	RunTask(ctx context.Context, req RunTaskRequest) (RunTaskResponse, error)
+	Watcher(ctx context.Context, req RunTaskRequest, opts ...WatcherOption) (*Watcher, error)
+	ListRuns(ctx context.Context, req ListRunsRequest) (ListRunsResponse, error)
+	GetRun(ctx context.Context, id string) (GetRunResponse, error)
+	GetLogs(ctx context.Context, runID, prevToken string) (GetLogsResponse, error)
+	GetOutputs(ctx context.Context, runID string) (GetOutputsResponse, error)
+	CancelRun(ctx context.Context, runID string) error
+
+	GetConfig(ctx context.Context, req GetConfigRequest) (GetConfigResponse, error)
+	SetConfig(ctx context.Context, req SetConfigRequest) error
+	ListConfigs(ctx context.Context, req ListConfigsRequest) (ListConfigsResponse, error)
+
+	ListEnvGroups(ctx context.Context) (ListEnvGroupsResponse, error)
+	GetEnvGroup(ctx context.Context, name string) (GetEnvGroupResponse, error)
+	SetEnvGroup(ctx context.Context, req SetEnvGroupRequest) error
+	DeleteEnvGroup(ctx context.Context, req DeleteEnvGroupRequest) error
+
+	CreateSchedule(ctx context.Context, req CreateScheduleRequest) (CreateScheduleResponse, error)
+	ListSchedules(ctx context.Context, req ListSchedulesRequest) (ListSchedulesResponse, error)
+	PauseSchedule(ctx context.Context, req PauseScheduleRequest) error
+	DeleteSchedule(ctx context.Context, req DeleteScheduleRequest) error
+
+	ListAgents(ctx context.Context) (ListAgentsResponse, error)
+	ListResources(ctx context.Context) (ListResourcesResponse, error)
+
+	GetBuild(ctx context.Context, id string) (GetBuildResponse, error)
+	CreateBuild(ctx context.Context, req CreateBuildRequest) (CreateBuildResponse, error)
+	CreateBuildUpload(ctx context.Context, req CreateBuildUploadRequest) (CreateBuildUploadResponse, error)
+	GetBuildLogs(ctx context.Context, buildID string, prevToken string) (GetBuildLogsResponse, error)
+
+	Download(ctx context.Context, url string, w io.Writer, opts DownloadOptions) (int64, error)
+	DownloadFile(ctx context.Context, url string, path string, opts DownloadOptions) (int64, error)
+
+	CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) (ListAPIKeysResponse, error)
+	DeleteAPIKey(ctx context.Context, req DeleteAPIKeyRequest) error
+
+	CheckPermissions(ctx context.Context, req CheckPermissionsRequest) (CheckPermissionsResponse, error)
+	RequirePermissions(ctx context.Context, checks ...PermissionCheck) error
+}
+
+var _ APIClient = Client{}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// logBufferMemory is roughly how many bytes of logs a watcher holds in
+	// memory before spilling them to disk.
+	logBufferMemory = 8 << 20
+
+	// maxLogBatch is the most logs a watcher hands out in a single state.
+	maxLogBatch = 1000
+)
+
+// logBuffer is a FIFO queue of logs fetched by a watcher but not yet
+// consumed. Once the logs held in memory exceed a size limit, newer logs are
+// appended to a temporary file and read back in order, so that a consumer
+// falling behind a very chatty run doesn't exhaust memory.
+//
+// It is not safe for concurrent use.
+type logBuffer struct {
+	maxMemory int
+
+	mem      []LogItem
+	memBytes int
+
+	// While any logs are on disk, new logs go to disk too, to keep order.
+	wf     *os.File
+	rf     *os.File
+	w      *bufio.Writer
+	dec    *json.Decoder
+	onDisk int
+}
+
+func newLogBuffer(maxMemory int) *logBuffer {
+	return &logBuffer{maxMemory: maxMemory}
+}
+
+// Len returns the number of buffered logs.
+func (b *logBuffer) Len() int {
+	return len(b.mem) + b.onDisk
+}
+
+// Push appends logs to the buffer.
+func (b *logBuffer) Push(logs []LogItem) error {
+	for _, l := range logs {
+		if b.onDisk == 0 && b.memBytes+logSize(l) <= b.maxMemory {
+			b.mem = append(b.mem, l)
+			b.memBytes += logSize(l)
+			continue
+		}
+		if err := b.spill(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pop removes and returns up to max of the oldest buffered logs.
+func (b *logBuffer) Pop(max int) ([]LogItem, error) {
+	var res []LogItem
+	for len(res) < max && len(b.mem) > 0 {
+		l := b.mem[0]
+		b.mem = b.mem[1:]
+		b.memBytes -= logSize(l)
+		res = append(res, l)
+	}
+	if len(b.mem) == 0 {
+		// Let the backing array go rather than keeping its high-water mark.
+		b.mem = nil
+	}
+
+	if len(res) < max && b.onDisk > 0 {
+		if err := b.w.Flush(); err != nil {
+			return nil, errors.Wrap(err, "flushing log buffer")
+		}
+		for len(res) < max && b.onDisk > 0 {
+			var l LogItem
+			if err := b.dec.Decode(&l); err != nil {
+				return nil, errors.Wrap(err, "reading log buffer")
+			}
+			b.onDisk--
+			res = append(res, l)
+		}
+		if b.onDisk == 0 {
+			// Start afresh so the file doesn't grow for the life of the run.
+			if err := b.Close(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+// Close removes the buffer's temporary file, if any.
+func (b *logBuffer) Close() error {
+	if b.wf == nil {
+		return nil
+	}
+	b.rf.Close()
+	err := b.wf.Close()
+	if rerr := os.Remove(b.wf.Name()); err == nil {
+		err = rerr
+	}
+	b.wf, b.rf, b.w, b.dec, b.onDisk = nil, nil, nil, nil, 0
+	return errors.Wrap(err, "closing log buffer")
+}
+
+func (b *logBuffer) spill(l LogItem) error {
+	if b.wf == nil {
+		wf, err := ioutil.TempFile("", "airplane-logs-*.jsonl")
+		if err != nil {
+			return errors.Wrap(err, "creating log buffer")
+		}
+		rf, err := os.Open(wf.Name())
+		if err != nil {
+			wf.Close()
+			os.Remove(wf.Name())
+			return errors.Wrap(err, "opening log buffer")
+		}
+		b.wf, b.rf = wf, rf
+		b.w = bufio.NewWriter(wf)
+		b.dec = json.NewDecoder(rf)
+	}
+	buf, err := json.Marshal(l)
+	if err != nil {
+		return errors.Wrap(err, "encoding log")
+	}
+	if _, err := b.w.Write(append(buf, '\n')); err != nil {
+		return errors.Wrap(err, "writing log buffer")
+	}
+	b.onDisk++
+	return nil
+}
+
+// logSize estimates the memory used by l.
+func logSize(l LogItem) int {
+	return len(l.Text) + len(l.InsertID) + len(l.Level) + 64
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBuffer(t *testing.T) {
+	require := require.New(t)
+
+	var logs []LogItem
+	for i := 0; i < 50; i++ {
+		logs = append(logs, LogItem{InsertID: fmt.Sprintf("%03d", i), Text: "line"})
+	}
+
+	// Room for about four logs in memory.
+	b := newLogBuffer(4 * logSize(logs[0]))
+	defer b.Close()
+
+	require.NoError(b.Push(logs[:10]))
+	require.Equal(10, b.Len())
+	require.Len(b.mem, 4)
+	require.NotNil(b.wf)
+
+	var popped []LogItem
+	got, err := b.Pop(3)
+	require.NoError(err)
+	popped = append(popped, got...)
+
+	// Logs keep going to disk while any are there, even with room in memory.
+	require.NoError(b.Push(logs[10:20]))
+	require.Len(b.mem, 1)
+
+	for b.Len() > 0 {
+		got, err := b.Pop(7)
+		require.NoError(err)
+		popped = append(popped, got...)
+	}
+	require.Equal(logs[:20], popped)
+	require.Nil(b.wf, "spill file is removed once drained")
+
+	require.NoError(b.Push(logs[20:]))
+	popped = nil
+	for b.Len() > 0 {
+		got, err := b.Pop(maxLogBatch)
+		require.NoError(err)
+		popped = append(popped, got...)
+	}
+	require.Equal(logs[20:], popped)
+}
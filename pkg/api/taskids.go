@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+var (
+	// taskIDs caches the ID of every task resolved by slug, so that commands
+	// which only need a task's ID can skip a round trip to the API.
+	taskIDs = cache.New("task-ids")
+
+	// taskSlugs caches the slug of every task in taskIDs by ID, so that
+	// commands which start from a run can name its task without listing every
+	// task.
+	taskSlugs = cache.New("task-slugs")
+)
+
+// taskIDTTL bounds how long a cached task ID is trusted. Deploys, failed
+// lookups and requests that 404 with a cached ID invalidate entries right
+// away, but tasks recreated elsewhere may otherwise go unnoticed until their
+// entry expires.
+const taskIDTTL = 24 * time.Hour
+
+type taskIDEntry struct {
+	ID       string    `json:"id"`
+	CachedAt time.Time `json:"cachedAt"`
+}
+
+type taskSlugEntry struct {
+	Slug     string    `json:"slug"`
+	CachedAt time.Time `json:"cachedAt"`
+}
+
+// GetTaskID returns the ID of the task with the given slug, from the cache
+// when possible and from the API otherwise.
+func (c Client) GetTaskID(ctx context.Context, slug string) (string, error) {
+	var entry taskIDEntry
+	if ok, err := taskIDs.Get(c.taskIDKey(slug), &entry); err != nil {
+		logger.Debug("reading cached task ID: %v", err)
+	} else if ok && entry.ID != "" && time.Since(entry.CachedAt) < taskIDTTL {
+		return entry.ID, nil
+	}
+
+	task, err := c.GetTask(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+	return task.ID, nil
+}
+
+// GetTaskSlug returns the slug of the task with the given ID, from the cache
+// when possible and from the API otherwise.
+func (c Client) GetTaskSlug(ctx context.Context, id string) (string, error) {
+	var entry taskSlugEntry
+	if ok, err := taskSlugs.Get(c.taskIDKey(id), &entry); err != nil {
+		logger.Debug("reading cached task slug: %v", err)
+	} else if ok && entry.Slug != "" && time.Since(entry.CachedAt) < taskIDTTL {
+		return entry.Slug, nil
+	}
+
+	task, err := TaskByID(ctx, c, id)
+	if err != nil {
+		return "", err
+	}
+	c.rememberTaskID(task.Slug, task.ID)
+	return task.Slug, nil
+}
+
+// WithTaskID calls fn with the ID of the task with the given slug, as
+// returned by client.GetTaskID. fn reports whether it found nothing for the
+// ID: list endpoints filtered by a stale ID return empty lists rather than
+// 404s. If fn fails with a 404 or finds nothing, the ID may be cached from a
+// task that has since been deleted or recreated: the task is looked up
+// again, which refreshes or evicts the cached ID, and fn is retried if the
+// task's ID changed.
+func WithTaskID(ctx context.Context, client APIClient, slug string, fn func(taskID string) (empty bool, err error)) error {
+	id, err := client.GetTaskID(ctx, slug)
+	if err != nil {
+		return err
+	}
+	empty, err := fn(id)
+	var aerr Error
+	if err != nil && (!errors.As(err, &aerr) || aerr.Code != 404) {
+		return err
+	}
+	if err == nil && !empty {
+		return nil
+	}
+
+	task, gerr := client.GetTask(ctx, slug)
+	if gerr != nil {
+		return gerr
+	}
+	if task.ID == id {
+		return err
+	}
+	logger.Debug("Cached ID %s of task %s is stale, retrying with %s", id, slug, task.ID)
+	_, err = fn(task.ID)
+	return err
+}
+
+// rememberTaskID caches the ID of the task with the given slug.
+func (c Client) rememberTaskID(slug, id string) {
+	if slug == "" || id == "" {
+		return
+	}
+	now := time.Now()
+	if err := taskIDs.Set(c.taskIDKey(slug), taskIDEntry{ID: id, CachedAt: now}); err != nil {
+		logger.Debug("caching task ID: %v", err)
+	}
+	if err := taskSlugs.Set(c.taskIDKey(id), taskSlugEntry{Slug: slug, CachedAt: now}); err != nil {
+		logger.Debug("caching task slug: %v", err)
+	}
+}
+
+// forgetTaskID drops the cached ID of the task with the given slug.
+func (c Client) forgetTaskID(slug string) {
+	var entry taskIDEntry
+	if ok, _ := taskIDs.Get(c.taskIDKey(slug), &entry); ok && entry.ID != "" {
+		if err := taskSlugs.Delete(c.taskIDKey(entry.ID)); err != nil {
+			logger.Debug("invalidating cached task slug: %v", err)
+		}
+	}
+	if err := taskIDs.Delete(c.taskIDKey(slug)); err != nil {
+		logger.Debug("invalidating cached task ID: %v", err)
+	}
+}
+
+// taskIDKey scopes a slug or ID to the host and credentials of c, since the
+// same slug names different tasks in different teams.
+func (c Client) taskIDKey(slug string) string {
+	identity := c.TeamID
+	if c.Token != "" {
+		sum := sha256.Sum256([]byte(c.Token))
+		identity = hex.EncodeToString(sum[:8])
+	}
+	return c.host() + "/" + identity + "/" + slug
+}
+
+// TaskByID returns the task with the given ID. Runs only reference their
+// task by ID, and tasks are otherwise looked up by slug.
+func TaskByID(ctx context.Context, client APIClient, id string) (Task, error) {
+	res, err := client.ListTasks(ctx, ListTasksRequest{})
+	if err != nil {
+		return Task{}, errors.Wrap(err, "listing tasks")
+	}
+	for _, t := range res.Tasks {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return Task{}, errors.Errorf("the task of this run (%s) no longer exists", id)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTasks serves /tasks/get and /tasks/list for the tasks it holds, by slug.
+type fakeTasks struct {
+	mu       sync.Mutex
+	tasks    map[string]string
+	requests []string
+}
+
+func (f *fakeTasks) set(slug, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id == "" {
+		delete(f.tasks, slug)
+	} else {
+		f.tasks[slug] = id
+	}
+}
+
+func (f *fakeTasks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.URL.Path)
+
+	switch r.URL.Path {
+	case "/v0/tasks/get":
+		slug := r.URL.Query().Get("slug")
+		id, ok := f.tasks[slug]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"task not found"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(Task{ID: id, Slug: slug})
+	case "/v0/tasks/list":
+		var res ListTasksResponse
+		for slug, id := range f.tasks {
+			res.Tasks = append(res.Tasks, Task{ID: id, Slug: slug})
+		}
+		json.NewEncoder(w).Encode(res)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeTasks) takeRequests() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := f.requests
+	f.requests = nil
+	return r
+}
+
+func newTaskIDsClient(t *testing.T) (Client, *fakeTasks) {
+	t.Setenv("HOME", t.TempDir())
+	oldIDs, oldSlugs := taskIDs, taskSlugs
+	taskIDs, taskSlugs = cache.New("task-ids"), cache.New("task-slugs")
+	t.Cleanup(func() {
+		taskIDs, taskSlugs = oldIDs, oldSlugs
+	})
+
+	f := &fakeTasks{tasks: map[string]string{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return Client{Host: srv.URL, Token: "tkn"}, f
+}
+
+func TestGetTaskID(t *testing.T) {
+	ctx := context.Background()
+	c, f := newTaskIDsClient(t)
+	f.set("hello", "tsk1")
+
+	id, err := c.GetTaskID(ctx, "hello")
+	require.NoError(t, err)
+	require.Equal(t, "tsk1", id)
+	require.Equal(t, []string{"/v0/tasks/get"}, f.takeRequests())
+
+	// The second lookup is served from the cache.
+	id, err = c.GetTaskID(ctx, "hello")
+	require.NoError(t, err)
+	require.Equal(t, "tsk1", id)
+	require.Empty(t, f.takeRequests())
+
+	// Other credentials don't share entries.
+	other := c
+	other.Token = "other"
+	_, err = other.GetTaskID(ctx, "hello")
+	require.NoError(t, err)
+	require.Equal(t, []string{"/v0/tasks/get"}, f.takeRequests())
+
+	// A 404 evicts the entry.
+	f.set("hello", "")
+	_, err = c.GetTask(ctx, "hello")
+	require.Error(t, err)
+	f.takeRequests()
+	_, err = c.GetTaskID(ctx, "hello")
+	require.Error(t, err)
+	require.Equal(t, []string{"/v0/tasks/get"}, f.takeRequests())
+}
+
+func TestGetTaskSlug(t *testing.T) {
+	ctx := context.Background()
+	c, f := newTaskIDsClient(t)
+	f.set("hello", "tsk1")
+	f.set("world", "tsk2")
+
+	// IDs resolved by slug are cached both ways.
+	_, err := c.GetTaskID(ctx, "hello")
+	require.NoError(t, err)
+	f.takeRequests()
+	slug, err := c.GetTaskSlug(ctx, "tsk1")
+	require.NoError(t, err)
+	require.Equal(t, "hello", slug)
+	require.Empty(t, f.takeRequests())
+
+	// Other IDs are found by listing tasks, once.
+	for i := 0; i < 2; i++ {
+		slug, err = c.GetTaskSlug(ctx, "tsk2")
+		require.NoError(t, err)
+		require.Equal(t, "world", slug)
+	}
+	require.Equal(t, []string{"/v0/tasks/list"}, f.takeRequests())
+
+	_, err = c.GetTaskSlug(ctx, "tsk3")
+	require.EqualError(t, err, "the task of this run (tsk3) no longer exists")
+}
+
+func TestWithTaskID(t *testing.T) {
+	ctx := context.Background()
+	c, f := newTaskIDsClient(t)
+	f.set("hello", "tsk1")
+
+	// fn 404s unless called with the current ID of hello.
+	var calls []string
+	fn := func(taskID string) (bool, error) {
+		calls = append(calls, taskID)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.tasks["hello"] != taskID {
+			return false, Error{Code: 404, Message: "task not found"}
+		}
+		return false, nil
+	}
+
+	require.NoError(t, WithTaskID(ctx, c, "hello", fn))
+	require.Equal(t, []string{"tsk1"}, calls)
+
+	t.Run("recreated task", func(t *testing.T) {
+		calls = nil
+		f.set("hello", "tsk2")
+		require.NoError(t, WithTaskID(ctx, c, "hello", fn))
+		require.Equal(t, []string{"tsk1", "tsk2"}, calls)
+
+		// The fresh ID replaced the stale one.
+		calls = nil
+		f.takeRequests()
+		require.NoError(t, WithTaskID(ctx, c, "hello", fn))
+		require.Equal(t, []string{"tsk2"}, calls)
+		require.Empty(t, f.takeRequests())
+	})
+
+	t.Run("deleted task", func(t *testing.T) {
+		calls = nil
+		f.set("hello", "")
+		err := WithTaskID(ctx, c, "hello", fn)
+		require.Error(t, err)
+		require.IsType(t, &TaskMissingError{}, err)
+		require.Equal(t, []string{"tsk2"}, calls)
+
+		// The stale ID was evicted.
+		_, err = c.GetTaskID(ctx, "hello")
+		require.Error(t, err)
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		f.set("hello", "tsk3")
+		f.takeRequests()
+		err := WithTaskID(ctx, c, "hello", func(taskID string) (bool, error) {
+			return false, Error{Code: 500, Message: "internal error"}
+		})
+		require.EqualError(t, err, "api: 500 - internal error")
+		// Only the lookup of hello's ID, which wasn't cached.
+		require.Equal(t, []string{"/v0/tasks/get"}, f.takeRequests())
+	})
+
+	t.Run("empty results", func(t *testing.T) {
+		// Lists filtered by a stale ID are empty rather than 404s.
+		var calls []string
+		list := func(taskID string) (bool, error) {
+			calls = append(calls, taskID)
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			return f.tasks["hello"] != taskID, nil
+		}
+		f.set("hello", "tsk4")
+		require.NoError(t, WithTaskID(ctx, c, "hello", list))
+		require.Equal(t, []string{"tsk3", "tsk4"}, calls)
+
+		// Empty results for the current ID are only checked once.
+		calls = nil
+		f.takeRequests()
+		require.NoError(t, WithTaskID(ctx, c, "hello", func(taskID string) (bool, error) {
+			calls = append(calls, taskID)
+			return true, nil
+		}))
+		require.Equal(t, []string{"tsk4"}, calls)
+		require.Equal(t, []string{"/v0/tasks/get"}, f.takeRequests())
+	})
+}
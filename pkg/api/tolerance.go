@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/airplanedev/cli/pkg/logger"
+)
+
+// The API adds fields and enum values over time, and older versions of the
+// CLI must keep working against it. Responses decode with unknown fields
+// ignored and unknown enum values kept as they are. Both are logged with
+// --debug, once each, so that it's clear what an outdated CLI is missing.
+
+// loggedUnknown records the unknown fields and values already logged.
+var loggedUnknown sync.Map
+
+func logUnknownOnce(key, format string, args ...interface{}) {
+	if _, logged := loggedUnknown.LoadOrStore(key, true); !logged {
+		logger.Debug(format, args...)
+	}
+}
+
+// logUnknownFields logs the fields of the JSON object in buf that v, a
+// pointer to a struct, has no field for.
+func logUnknownFields(name string, buf []byte, v interface{}) {
+	if !logger.EnableDebug {
+		return
+	}
+	for _, field := range unknownFields(buf, v) {
+		logUnknownOnce(name+"."+field, "The API returned a %s field this version of the CLI doesn't know: %s", name, field)
+	}
+}
+
+// unknownFields returns the keys of the JSON object in buf that v, a pointer
+// to a struct, has no field for, sorted.
+func unknownFields(buf []byte, v interface{}) []string {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &obj); err != nil {
+		return nil
+	}
+	known := jsonFields(reflect.TypeOf(v).Elem())
+	var unknown []string
+	for key := range obj {
+		// Like encoding/json, match field names case-insensitively.
+		if !known[strings.ToLower(key)] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// jsonFields returns the lowercased JSON names of the fields of struct type t.
+func jsonFields(t reflect.Type) map[string]bool {
+	fields := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && name == f.Name {
+			for n := range jsonFields(f.Type) {
+				fields[n] = true
+			}
+			continue
+		}
+		fields[strings.ToLower(name)] = true
+	}
+	return fields
+}
+
+// UnmarshalJSON implementation. Unknown statuses are kept, so that they can
+// still be shown.
+func (s *RunStatus) UnmarshalJSON(buf []byte) error {
+	var v string
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return err
+	}
+	*s = RunStatus(v)
+	if v != "" && !s.Known() {
+		logUnknownOnce("RunStatus."+v, "The API returned a run status this version of the CLI doesn't know: %s", v)
+	}
+	return nil
+}
+
+// Known reports whether s is one of the run statuses this version of the CLI
+// knows about.
+func (s RunStatus) Known() bool {
+	switch s {
+	case RunNotStarted, RunQueued, RunActive, RunSucceeded, RunFailed, RunCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnmarshalJSON implementation. Unknown statuses are kept, so that they can
+// still be shown.
+func (s *BuildStatus) UnmarshalJSON(buf []byte) error {
+	var v string
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return err
+	}
+	*s = BuildStatus(v)
+	if v != "" && !s.Known() {
+		logUnknownOnce("BuildStatus."+v, "The API returned a build status this version of the CLI doesn't know: %s", v)
+	}
+	return nil
+}
+
+// Known reports whether s is one of the build statuses this version of the
+// CLI knows about.
+func (s BuildStatus) Known() bool {
+	switch s {
+	case BuildNotStarted, BuildActive, BuildSucceeded, BuildFailed, BuildCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnmarshalJSON implementation. It keeps the task's JSON in Raw.
+func (t *Task) UnmarshalJSON(buf []byte) error {
+	type task Task
+	if err := json.Unmarshal(buf, (*task)(t)); err != nil {
+		return err
+	}
+	t.Raw = append(json.RawMessage(nil), buf...)
+	logUnknownFields("task", buf, t)
+	return nil
+}
+
+// UnmarshalJSON implementation. It keeps the run's JSON in Raw.
+func (r *Run) UnmarshalJSON(buf []byte) error {
+	type run Run
+	if err := json.Unmarshal(buf, (*run)(r)); err != nil {
+		return err
+	}
+	r.Raw = append(json.RawMessage(nil), buf...)
+	logUnknownFields("run", buf, r)
+	return nil
+}
+
+// UnmarshalJSON implementation. It keeps the build's JSON in Raw.
+func (b *Build) UnmarshalJSON(buf []byte) error {
+	type build Build
+	if err := json.Unmarshal(buf, (*build)(b)); err != nil {
+		return err
+	}
+	b.Raw = append(json.RawMessage(nil), buf...)
+	logUnknownFields("build", buf, b)
+	return nil
+}
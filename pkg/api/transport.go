@@ -0,0 +1,142 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+)
+
+// DirectClient sends requests that don't go through the API's retrying
+// client, such as to signed upload and download URLs. It uses the transport
+// set by ConfigureTransport.
+var DirectClient = &http.Client{}
+
+// DefaultKeepAlive is how often idle connections are probed by default.
+// NATs and proxies commonly drop connections idle for a few minutes, which
+// breaks the long uploads and builds of large tasks.
+const DefaultKeepAlive = 15 * time.Second
+
+// TransportOptions configures how the CLI connects to the API and to storage,
+// such as from behind a corporate proxy or to a self-hosted API.
+type TransportOptions struct {
+	// Proxy is the URL of a proxy to send requests through. If empty, the
+	// proxy is read from $HTTPS_PROXY, $HTTP_PROXY and $NO_PROXY.
+	Proxy string
+
+	// CACertFile is a PEM bundle of certificate authorities to trust in
+	// addition to the system's, such as a proxy's or a self-hosted API's.
+	CACertFile string
+
+	// ClientCertFile and ClientKeyFile are a PEM certificate and key to
+	// authenticate the CLI to servers that require mutual TLS.
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// InsecureSkipVerify turns off verification of server certificates.
+	// Anyone on the network can then read and change requests, tokens
+	// included, so it's only for trying out self-hosted APIs.
+	InsecureSkipVerify bool
+
+	// KeepAlive is how often idle connections are probed, with TCP
+	// keepalives and HTTP/2 pings, so that NATs and proxies keep them open
+	// and dropped connections are noticed. It defaults to DefaultKeepAlive.
+	KeepAlive time.Duration
+
+	// Wrap, if set, wraps the transport that requests are sent with, such as
+	// to record them.
+	Wrap func(http.RoundTripper) http.RoundTripper
+}
+
+// NewTransport returns an HTTP transport configured with opts.
+func NewTransport(opts TransportOptions) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	if opts.Proxy != "" {
+		u, err := url.Parse(opts.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, errors.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		t.Proxy = http.ProxyURL(u)
+	} else {
+		t.Proxy = http.ProxyFromEnvironment
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CACertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			// The system pool isn't available on every platform.
+			pool = x509.NewCertPool()
+		}
+		buf, err := ioutil.ReadFile(opts.CACertFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading CA certificates")
+		}
+		if !pool.AppendCertsFromPEM(buf) {
+			return nil, errors.Errorf("no PEM certificates found in %s", opts.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case opts.ClientCertFile != "" && opts.ClientKeyFile != "":
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "loading client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case opts.ClientCertFile != "" || opts.ClientKeyFile != "":
+		return nil, errors.New("a client certificate and its key must be set together")
+	}
+
+	t.TLSClientConfig = tlsConfig
+
+	keepAlive := opts.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = DefaultKeepAlive
+	}
+	t.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: keepAlive,
+	}).DialContext
+	// Pooled connections are closed before a NAT is likely to have dropped
+	// them, rather than failing the next request sent on them.
+	t.IdleConnTimeout = 4 * keepAlive
+
+	// TCP keepalives don't reach past a proxy, so HTTP/2 connections are
+	// also pinged once they've been quiet for a while.
+	h2, err := http2.ConfigureTransports(t)
+	if err != nil {
+		return nil, errors.Wrap(err, "configuring HTTP/2")
+	}
+	h2.ReadIdleTimeout = 2 * keepAlive
+	h2.PingTimeout = keepAlive
+	return t, nil
+}
+
+// ConfigureTransport sets the transport that API requests, uploads and
+// downloads are sent with.
+func ConfigureTransport(opts TransportOptions) error {
+	t, err := NewTransport(opts)
+	if err != nil {
+		return err
+	}
+	var rt http.RoundTripper = t
+	if opts.Wrap != nil {
+		rt = opts.Wrap(t)
+	}
+	retryClient.HTTPClient.Transport = rt
+	DirectClient.Transport = rt
+	return nil
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	get := func(opts TransportOptions) error {
+		tr, err := NewTransport(opts)
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: tr}).Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("untrusted", func(t *testing.T) {
+		require.Error(t, get(TransportOptions{}))
+	})
+
+	t.Run("ca cert", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ca.pem")
+		buf := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		require.NoError(t, ioutil.WriteFile(path, buf, 0600))
+		require.NoError(t, get(TransportOptions{CACertFile: path}))
+	})
+
+	t.Run("insecure", func(t *testing.T) {
+		require.NoError(t, get(TransportOptions{InsecureSkipVerify: true}))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "empty.pem")
+		require.NoError(t, ioutil.WriteFile(path, []byte("not a certificate"), 0600))
+		_, err := NewTransport(TransportOptions{CACertFile: path})
+		require.EqualError(t, err, "no PEM certificates found in "+path)
+
+		_, err = NewTransport(TransportOptions{ClientCertFile: "cert.pem"})
+		require.EqualError(t, err, "a client certificate and its key must be set together")
+
+		_, err = NewTransport(TransportOptions{Proxy: "not a url"})
+		require.Error(t, err)
+	})
+}
+
+func TestClientSchemeHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v0/auth/info", r.URL.Path)
+		w.Write([]byte(`{"user":{"id":"usr1"}}`))
+	}))
+	defer srv.Close()
+
+	c := Client{Host: srv.URL, Token: "tkn"}
+	_, err := c.AuthInfo(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, "https://app.airplane.dev/runs/run1", Client{Host: "api.airplane.dev"}.RunURL("run1"))
+}
@@ -18,15 +18,19 @@ type CreateTaskRequest struct {
 	Command          []string          `json:"command"`
 	Arguments        []string          `json:"arguments"`
 	Parameters       Parameters        `json:"parameters"`
+	Form             *Form             `json:"form"`
 	Constraints      RunConstraints    `json:"constraints"`
 	Env              TaskEnv           `json:"env"`
+	EnvGroups        []string          `json:"envGroups"`
 	ResourceRequests map[string]string `json:"resourceRequests"`
 	Resources        map[string]string `json:"resources"`
 	Kind             build.TaskKind    `json:"kind"`
 	KindOptions      build.KindOptions `json:"kindOptions"`
 	Repo             string            `json:"repo"`
-	// TODO(amir): friendly type here (120s, 5m ...)
-	Timeout int `json:"timeout"`
+	Concurrency      *Concurrency      `json:"concurrency"`
+	Confirm          string            `json:"confirm"`
+	Dependencies     *Dependencies     `json:"dependencies"`
+	Timeout          Duration          `json:"timeout"`
 }
 
 // UpdateTaskRequest updates a task.
@@ -38,8 +42,10 @@ type UpdateTaskRequest struct {
 	Command                    []string          `json:"command"`
 	Arguments                  []string          `json:"arguments"`
 	Parameters                 Parameters        `json:"parameters"`
+	Form                       *Form             `json:"form"`
 	Constraints                RunConstraints    `json:"constraints"`
 	Env                        TaskEnv           `json:"env"`
+	EnvGroups                  []string          `json:"envGroups"`
 	ResourceRequests           map[string]string `json:"resourceRequests"`
 	Resources                  map[string]string `json:"resources"`
 	Kind                       build.TaskKind    `json:"kind"`
@@ -47,13 +53,33 @@ type UpdateTaskRequest struct {
 	Repo                       string            `json:"repo"`
 	RequireExplicitPermissions bool              `json:"requireExplicitPermissions"`
 	Permissions                Permissions       `json:"permissions"`
-	// TODO(amir): friendly type here (120s, 5m ...)
-	Timeout int     `json:"timeout"`
-	BuildID *string `json:"buildID"`
+	Concurrency                *Concurrency      `json:"concurrency"`
+	Confirm                    string            `json:"confirm"`
+	Dependencies               *Dependencies     `json:"dependencies"`
+	Timeout                    Duration          `json:"timeout"`
+	BuildID                    *string           `json:"buildID"`
+	// ImageSignature is the reference of the image's cosign signature, if signed.
+	ImageSignature *string `json:"imageSignature"`
+	// Annotation records where the deploy came from.
+	Annotation *DeployAnnotation `json:"annotation,omitempty"`
 
 	InterpolationMode string `json:"interpolationMode" yaml:"-"`
 }
 
+// DeployAnnotation describes the environment a task revision was deployed
+// from, so that it can be traced back to the commit and CLI that produced it.
+type DeployAnnotation struct {
+	CLIVersion string `json:"cliVersion" yaml:"cliVersion"`
+	OS         string `json:"os" yaml:"os"`
+	// GitSHA is the commit the task was deployed from, if it was in a repo.
+	GitSHA string `json:"gitSHA,omitempty" yaml:"gitSHA,omitempty"`
+	// GitDirty is set if the deploy included uncommitted changes.
+	GitDirty bool `json:"gitDirty,omitempty" yaml:"gitDirty,omitempty"`
+	// DefinitionHash is a hash of the task's definition, which identifies
+	// deploys of the same definition from different commits or machines.
+	DefinitionHash string `json:"definitionHash" yaml:"definitionHash"`
+}
+
 type Permissions []Permission
 
 type Permission struct {
@@ -68,6 +94,21 @@ type UpdateTaskResponse struct {
 	TaskRevisionID string `json:"taskRevisionID"`
 }
 
+// ListTaskRevisionsResponse represents a list task revisions response.
+type ListTaskRevisionsResponse struct {
+	Revisions []TaskRevision `json:"revisions"`
+}
+
+// TaskRevision is a deployed version of a task.
+type TaskRevision struct {
+	ID         string            `json:"id" yaml:"id"`
+	TaskID     string            `json:"taskID" yaml:"taskID"`
+	CreatedAt  time.Time         `json:"createdAt" yaml:"createdAt"`
+	CreatedBy  string            `json:"createdBy" yaml:"createdBy"`
+	BuildID    string            `json:"buildID,omitempty" yaml:"buildID,omitempty"`
+	Annotation *DeployAnnotation `json:"annotation,omitempty" yaml:"annotation,omitempty"`
+}
+
 // GetLogsResponse represents a get logs response.
 type GetLogsResponse struct {
 	RunID         string    `json:"runID"`
@@ -87,7 +128,7 @@ type GetBuildLogsResponse struct {
 // Outputs represents outputs.
 //
 // It has custom UnmarshalJSON/MarshalJSON methods in order to proxy to the underlying
-// ojson.Value methods.
+// ojson.Value methods, and a MarshalYAML method that keeps the order of their keys.
 type Outputs ojson.Value
 
 func (o *Outputs) UnmarshalJSON(buf []byte) error {
@@ -104,6 +145,49 @@ func (o Outputs) MarshalJSON() ([]byte, error) {
 	return json.Marshal(ojson.Value(o))
 }
 
+// MarshalYAML implementation. Objects are *ojson.Object values, which have
+// no exported fields and would otherwise encode as {}.
+func (o Outputs) MarshalYAML() (interface{}, error) {
+	return yamlNode(ojson.Value(o).V)
+}
+
+// yamlNode converts a decoded ojson value to a YAML node.
+func yamlNode(v interface{}) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case *ojson.Object:
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range v.KeyOrder() {
+			var key yaml.Node
+			if err := key.Encode(k); err != nil {
+				return nil, err
+			}
+			val, _ := v.Get(k)
+			value, err := yamlNode(val)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, &key, value)
+		}
+		return n, nil
+	case []interface{}:
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, e := range v {
+			value, err := yamlNode(e)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, value)
+		}
+		return n, nil
+	default:
+		var n yaml.Node
+		if err := n.Encode(v); err != nil {
+			return nil, err
+		}
+		return &n, nil
+	}
+}
+
 // Represents a line of the output
 type OutputRow struct {
 	OutputName string      `json:"name" yaml:"name"`
@@ -177,6 +261,8 @@ const (
 	TypeDate      Type = "date"
 	TypeDatetime  Type = "datetime"
 	TypeConfigVar Type = "configvar"
+	// TypeEnum parameters take one of the values in their constraint options.
+	TypeEnum Type = "enum"
 )
 
 // Parameter represents a task parameter.
@@ -188,6 +274,8 @@ type Parameter struct {
 	Component   Component   `json:"component" yaml:"component,omitempty"`
 	Default     Value       `json:"default" yaml:"default,omitempty"`
 	Constraints Constraints `json:"constraints" yaml:"constraints,omitempty"`
+	// Multi parameters accept a list of values.
+	Multi bool `json:"multi,omitempty" yaml:"multi,omitempty"`
 }
 
 // Constraints represent constraints.
@@ -226,6 +314,47 @@ type AgentLabel struct {
 	Value string `json:"value" yaml:"value"`
 }
 
+// IsEmpty returns true if there are no constraints.
+func (c RunConstraints) IsEmpty() bool {
+	return len(c.Labels) == 0
+}
+
+// Matches returns true if an agent with the given labels satisfies
+// every constraint.
+func (c RunConstraints) Matches(labels map[string]string) bool {
+	for _, l := range c.Labels {
+		if v, ok := labels[l.Key]; !ok || v != l.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// AgentStatus enumerates agent statuses.
+type AgentStatus string
+
+// All AgentStatus types.
+const (
+	AgentOnline  AgentStatus = "online"
+	AgentOffline AgentStatus = "offline"
+)
+
+// Agent represents an agent that executes runs.
+type Agent struct {
+	ID              string            `json:"id" yaml:"id"`
+	Hostname        string            `json:"hostname" yaml:"hostname"`
+	Labels          map[string]string `json:"labels" yaml:"labels"`
+	Status          AgentStatus       `json:"status" yaml:"status"`
+	Version         string            `json:"version" yaml:"version"`
+	LastHeartbeatAt *time.Time        `json:"lastHeartbeatAt" yaml:"lastHeartbeatAt"`
+	CreatedAt       time.Time         `json:"createdAt" yaml:"createdAt"`
+}
+
+// ListAgentsResponse represents a list agents response.
+type ListAgentsResponse struct {
+	Agents []Agent `json:"agents"`
+}
+
 // AuthInfoResponse represents info about authenticated user.
 type AuthInfoResponse struct {
 	User *UserInfo `json:"user"`
@@ -233,8 +362,8 @@ type AuthInfoResponse struct {
 }
 
 type UserInfo struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
+	ID    string `json:"id" yaml:"id"`
+	Email string `json:"email" yaml:"email"`
 }
 
 type TeamInfo struct {
@@ -249,6 +378,15 @@ type CreateTaskResponse struct {
 	TaskRevisionID string `json:"taskRevisionID"`
 }
 
+// ListTasksRequest represents a list tasks request.
+type ListTasksRequest struct {
+	Page int `json:"page"`
+	// Limit is the most tasks to return. If zero, all tasks are returned.
+	Limit int `json:"limit"`
+	// Owner only includes tasks whose OwnerEmail is this email.
+	Owner string `json:"owner"`
+}
+
 // ListTasksResponse represents a list tasks response.
 type ListTasksResponse struct {
 	Tasks []Task `json:"tasks"`
@@ -332,8 +470,10 @@ type Task struct {
 	Command                    []string          `json:"command" yaml:"command"`
 	Arguments                  []string          `json:"arguments" yaml:"arguments"`
 	Parameters                 Parameters        `json:"parameters" yaml:"parameters"`
+	Form                       *Form             `json:"form" yaml:"form,omitempty"`
 	Constraints                RunConstraints    `json:"constraints" yaml:"constraints"`
 	Env                        TaskEnv           `json:"env" yaml:"env"`
+	EnvGroups                  []string          `json:"envGroups" yaml:"envGroups"`
 	ResourceRequests           ResourceRequests  `json:"resourceRequests" yaml:"resourceRequests"`
 	Resources                  Resources         `json:"resources" yaml:"resources"`
 	Kind                       build.TaskKind    `json:"kind" yaml:"kind"`
@@ -341,8 +481,113 @@ type Task struct {
 	Repo                       string            `json:"repo" yaml:"repo"`
 	RequireExplicitPermissions bool              `json:"requireExplicitPermissions" yaml:"-"`
 	Permissions                Permissions       `json:"permissions" yaml:"-"`
-	Timeout                    int               `json:"timeout" yaml:"timeout"`
+	Concurrency                *Concurrency      `json:"concurrency" yaml:"concurrency,omitempty"`
+	Confirm                    string            `json:"confirm" yaml:"confirm,omitempty"`
+	Dependencies               *Dependencies     `json:"dependencies" yaml:"dependencies,omitempty"`
+	Timeout                    Duration          `json:"timeout" yaml:"timeout"`
 	InterpolationMode          string            `json:"interpolationMode" yaml:"-"`
+
+	// Creator is the user who created the task, and Owner is the user
+	// responsible for it, if one has been set.
+	Creator *UserInfo `json:"creator,omitempty" yaml:"creator,omitempty"`
+	Owner   *UserInfo `json:"owner,omitempty" yaml:"owner,omitempty"`
+
+	// Pause is set if the task has been paused for maintenance.
+	Pause *TaskPause `json:"pause,omitempty" yaml:"pause,omitempty"`
+
+	// Raw is the task as the API returned it, including any fields this
+	// version of the CLI doesn't know about.
+	Raw json.RawMessage `json:"-" yaml:"-"`
+}
+
+// OwnerEmail returns the email of the task's owner, or of its creator if it
+// has no owner.
+func (t Task) OwnerEmail() string {
+	switch {
+	case t.Owner != nil:
+		return t.Owner.Email
+	case t.Creator != nil:
+		return t.Creator.Email
+	default:
+		return ""
+	}
+}
+
+// TaskPause is a maintenance window of a task: while it's in effect, the
+// task's schedules don't run and only team admins can execute it.
+type TaskPause struct {
+	Reason   string    `json:"reason,omitempty" yaml:"reason,omitempty"`
+	PausedBy string    `json:"pausedBy,omitempty" yaml:"pausedBy,omitempty"`
+	PausedAt time.Time `json:"pausedAt" yaml:"pausedAt"`
+	// Until is when the task resumes on its own. If nil, it stays paused
+	// until it's resumed.
+	Until *time.Time `json:"until,omitempty" yaml:"until,omitempty"`
+}
+
+// Active reports whether the pause is in effect at now. A nil pause is
+// never in effect.
+func (p *TaskPause) Active(now time.Time) bool {
+	return p != nil && (p.Until == nil || now.Before(*p.Until))
+}
+
+// PauseTaskRequest represents a pause task request. Paused is false to
+// resume the task.
+type PauseTaskRequest struct {
+	TaskID string     `json:"taskID"`
+	Paused bool       `json:"paused"`
+	Until  *time.Time `json:"until,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// Form describes how a task's parameters are laid out when prompting
+// for values, so that CLI prompts follow the same logic as the web form.
+type Form struct {
+	// Groups are prompted in order. Parameters not listed in any group
+	// are prompted afterwards, in definition order.
+	Groups []FormGroup `json:"groups,omitempty" yaml:"groups,omitempty"`
+	// Conditions control whether a parameter is shown at all.
+	Conditions []FormCondition `json:"conditions,omitempty" yaml:"conditions,omitempty"`
+}
+
+// FormGroup is an ordered set of parameters shown under a shared title.
+type FormGroup struct {
+	Title      string   `json:"title,omitempty" yaml:"title,omitempty"`
+	Parameters []string `json:"parameters" yaml:"parameters"`
+}
+
+// FormCondition only shows Parameter when the parameter identified by
+// When currently has the value Equals.
+type FormCondition struct {
+	Parameter string `json:"parameter" yaml:"parameter"`
+	When      string `json:"when" yaml:"when"`
+	Equals    string `json:"equals" yaml:"equals"`
+}
+
+// ConcurrencyBehavior enumerates what happens to a new run when a task
+// is already at its concurrency limit.
+type ConcurrencyBehavior string
+
+// All ConcurrencyBehavior types.
+const (
+	ConcurrencyQueue          ConcurrencyBehavior = "queue"
+	ConcurrencySkip           ConcurrencyBehavior = "skip"
+	ConcurrencyCancelPrevious ConcurrencyBehavior = "cancel-previous"
+)
+
+// Concurrency limits how many runs of a task can be active at once.
+type Concurrency struct {
+	// Limit is the maximum number of concurrent runs. Zero means unlimited.
+	Limit int `json:"limit" yaml:"limit"`
+	// Behavior defaults to queueing when not set.
+	Behavior ConcurrencyBehavior `json:"behavior,omitempty" yaml:"behavior,omitempty"`
+}
+
+// Dependencies are what a task relies on beyond what its definition already
+// references, such as configs read at runtime or tasks it runs.
+type Dependencies struct {
+	Configs   []string `json:"configs,omitempty" yaml:"configs,omitempty"`
+	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Tasks     []string `json:"tasks,omitempty" yaml:"tasks,omitempty"`
 }
 
 type ResourceRequests map[string]string
@@ -385,8 +630,30 @@ type Values = map[string]interface{}
 type RunTaskRequest struct {
 	TaskID      string `json:"taskID"`
 	ParamValues Values `json:"paramValues"`
+	// Labels are key/value metadata attached to the run, such as why it
+	// was started.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Priority is the queue the run waits in for an agent. If empty, the
+	// run is queued with normal priority.
+	Priority RunPriority `json:"priority,omitempty"`
+	// IdempotencyKey identifies the request, so that sending it again, such
+	// as after a timeout, returns the run it already started instead of
+	// starting another.
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
 }
 
+// RunPriority enumerates the queues runs wait in for an agent: high priority
+// runs, such as remediations during an incident, start ahead of bulk work
+// such as scheduled runs, which can be queued with low priority.
+type RunPriority string
+
+// All RunPriority types.
+const (
+	RunPriorityHigh   RunPriority = "high"
+	RunPriorityNormal RunPriority = "normal"
+	RunPriorityLow    RunPriority = "low"
+)
+
 // RunTaskResponse represents a run task response.
 type RunTaskResponse struct {
 	RunID string `json:"runID"`
@@ -410,22 +677,79 @@ const (
 	RunCancelled  RunStatus = "Cancelled"
 )
 
+// Stopped returns true if the run has finished.
+func (s RunStatus) Stopped() bool {
+	switch s {
+	case RunCancelled, RunFailed, RunSucceeded:
+		return true
+	default:
+		return false
+	}
+}
+
 // Run represents a run.
 type Run struct {
-	RunID       string     `json:"runID"`
-	TaskID      string     `json:"taskID"`
-	TaskName    string     `json:"taskName"`
-	TeamID      string     `json:"teamID"`
-	Status      RunStatus  `json:"status"`
-	ParamValues Values     `json:"paramValues"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	CreatorID   string     `json:"creatorID"`
-	QueuedAt    *time.Time `json:"queuedAt"`
-	ActiveAt    *time.Time `json:"activeAt"`
-	SucceededAt *time.Time `json:"succeededAt"`
-	FailedAt    *time.Time `json:"failedAt"`
-	CancelledAt *time.Time `json:"cancelledAt"`
-	CancelledBy *string    `json:"cancelledBy"`
+	RunID       string     `json:"runID" yaml:"runID"`
+	TaskID      string     `json:"taskID" yaml:"taskID"`
+	TaskName    string     `json:"taskName" yaml:"taskName"`
+	TeamID      string     `json:"teamID" yaml:"teamID"`
+	Status      RunStatus  `json:"status" yaml:"status"`
+	ParamValues Values     `json:"paramValues" yaml:"paramValues"`
+	CreatedAt   time.Time  `json:"createdAt" yaml:"createdAt"`
+	CreatorID   string     `json:"creatorID" yaml:"creatorID"`
+	QueuedAt    *time.Time `json:"queuedAt" yaml:"queuedAt"`
+	ActiveAt    *time.Time `json:"activeAt" yaml:"activeAt"`
+	SucceededAt *time.Time `json:"succeededAt" yaml:"succeededAt"`
+	FailedAt    *time.Time `json:"failedAt" yaml:"failedAt"`
+	CancelledAt *time.Time `json:"cancelledAt" yaml:"cancelledAt"`
+	CancelledBy *string    `json:"cancelledBy" yaml:"cancelledBy"`
+
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Priority RunPriority       `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// Raw is the run as the API returned it, including any fields this
+	// version of the CLI doesn't know about.
+	Raw json.RawMessage `json:"-" yaml:"-"`
+}
+
+// EndedAt returns when the run succeeded, failed or was cancelled, or nil if
+// it hasn't stopped.
+func (r Run) EndedAt() *time.Time {
+	switch {
+	case r.SucceededAt != nil:
+		return r.SucceededAt
+	case r.FailedAt != nil:
+		return r.FailedAt
+	default:
+		return r.CancelledAt
+	}
+}
+
+// QueuedDuration returns how long the run waited before it started. For a
+// run that is still waiting, it is the time until now. It reports false for
+// a run cancelled before it started.
+func (r Run) QueuedDuration(now time.Time) (time.Duration, bool) {
+	switch {
+	case r.ActiveAt != nil:
+		return r.ActiveAt.Sub(r.CreatedAt), true
+	case r.EndedAt() != nil:
+		return 0, false
+	default:
+		return now.Sub(r.CreatedAt), true
+	}
+}
+
+// RunDuration returns how long the run has been running for. For a run that
+// is still active, it is the time until now. It reports false for a run that
+// never started.
+func (r Run) RunDuration(now time.Time) (time.Duration, bool) {
+	if r.ActiveAt == nil {
+		return 0, false
+	}
+	if ended := r.EndedAt(); ended != nil {
+		return ended.Sub(*r.ActiveAt), true
+	}
+	return now.Sub(*r.ActiveAt), true
 }
 
 // ListRunsRequest represents a list runs request.
@@ -435,6 +759,15 @@ type ListRunsRequest struct {
 	Until  time.Time `json:"until"`
 	Page   int       `json:"page"`
 	Limit  int       `json:"limit"`
+	// Labels only includes runs that have all of these labels.
+	Labels map[string]string `json:"labels"`
+	// Statuses only includes runs with one of these statuses.
+	Statuses []RunStatus `json:"statuses"`
+	// CreatorID only includes runs started by this user.
+	CreatorID string `json:"creatorID"`
+	// Query only includes runs whose ID, task name or labels contain this
+	// text, ignoring case.
+	Query string `json:"query"`
 }
 
 // ListRunsResponse represents a list runs response.
@@ -442,6 +775,11 @@ type ListRunsResponse struct {
 	Runs []Run `json:"runs"`
 }
 
+// CancelRunRequest represents a cancel run request.
+type CancelRunRequest struct {
+	RunID string `json:"runID"`
+}
+
 // GetConfigRequest represents a get config request
 type GetConfigRequest struct {
 	Name       string `json:"name"`
@@ -459,10 +797,10 @@ type SetConfigRequest struct {
 
 // Config represents a config var.
 type Config struct {
-	Name     string `json:"name"`
-	Tag      string `json:"tag"`
-	Value    string `json:"value"`
-	IsSecret bool   `json:"isSecret"`
+	Name     string `json:"name" yaml:"name"`
+	Tag      string `json:"tag" yaml:"tag"`
+	Value    string `json:"value" yaml:"value"`
+	IsSecret bool   `json:"isSecret" yaml:"isSecret"`
 }
 
 // GetConfigResponse represents a get config response.
@@ -470,6 +808,108 @@ type GetConfigResponse struct {
 	Config Config `json:"config"`
 }
 
+// ListConfigsRequest represents a list configs request.
+type ListConfigsRequest struct {
+	// ShowSecret includes the values of secret configs.
+	ShowSecret bool `json:"showSecret"`
+}
+
+// ListConfigsResponse represents a list configs response.
+type ListConfigsResponse struct {
+	Configs []Config `json:"configs"`
+}
+
+// EnvGroup represents a named set of env vars that can be shared
+// across tasks.
+type EnvGroup struct {
+	ID        string    `json:"id" yaml:"id"`
+	Name      string    `json:"name" yaml:"name"`
+	Env       TaskEnv   `json:"env" yaml:"env"`
+	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" yaml:"updatedAt"`
+}
+
+// ListEnvGroupsResponse represents a list env groups response.
+type ListEnvGroupsResponse struct {
+	EnvGroups []EnvGroup `json:"envGroups"`
+}
+
+// GetEnvGroupResponse represents a get env group response.
+type GetEnvGroupResponse struct {
+	EnvGroup EnvGroup `json:"envGroup"`
+}
+
+// SetEnvGroupRequest represents a set env group request.
+type SetEnvGroupRequest struct {
+	Name string  `json:"name"`
+	Env  TaskEnv `json:"env"`
+}
+
+// DeleteEnvGroupRequest represents a delete env group request.
+type DeleteEnvGroupRequest struct {
+	Name string `json:"name"`
+}
+
+// Schedule runs a task on a cron schedule, with a fixed set of parameters.
+type Schedule struct {
+	ID     string `json:"id" yaml:"id"`
+	TaskID string `json:"taskID" yaml:"taskID"`
+	// Slug identifies the schedule among its task's schedules. Schedules
+	// declared in a task definition are matched to existing ones by slug.
+	Slug        string `json:"slug" yaml:"slug"`
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+	CronExpr    string `json:"cronExpr" yaml:"cronExpr"`
+	// Timezone is an IANA time zone, such as America/New_York, that the cron
+	// expression is evaluated in. Defaults to UTC.
+	Timezone    string     `json:"timezone" yaml:"timezone"`
+	ParamValues Values     `json:"paramValues" yaml:"paramValues"`
+	Paused      bool       `json:"paused" yaml:"paused"`
+	NextRunAt   *time.Time `json:"nextRunAt" yaml:"nextRunAt"`
+	CreatedAt   time.Time  `json:"createdAt" yaml:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt" yaml:"updatedAt"`
+}
+
+// CreateScheduleRequest represents a create schedule request.
+type CreateScheduleRequest struct {
+	TaskID      string `json:"taskID"`
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CronExpr    string `json:"cronExpr"`
+	Timezone    string `json:"timezone"`
+	ParamValues Values `json:"paramValues"`
+	Paused      bool   `json:"paused"`
+}
+
+// CreateScheduleResponse represents a create schedule response.
+type CreateScheduleResponse struct {
+	ScheduleID string `json:"scheduleID"`
+}
+
+// ListSchedulesRequest represents a list schedules request.
+type ListSchedulesRequest struct {
+	// TaskID only includes the schedules of this task, if set.
+	TaskID string `json:"taskID"`
+}
+
+// ListSchedulesResponse represents a list schedules response.
+type ListSchedulesResponse struct {
+	Schedules []Schedule `json:"schedules"`
+}
+
+// PauseScheduleRequest represents a pause schedule request. Paused is false
+// to resume the schedule.
+type PauseScheduleRequest struct {
+	ScheduleID string `json:"scheduleID"`
+	Paused     bool   `json:"paused"`
+}
+
+// DeleteScheduleRequest represents a delete schedule request.
+type DeleteScheduleRequest struct {
+	ScheduleID string `json:"scheduleID"`
+}
+
 type GetBuildResponse struct {
 	Build Build `json:"build"`
 }
@@ -479,6 +919,35 @@ type CreateBuildRequest struct {
 	SourceUploadID string       `json:"sourceUploadID"`
 	Env            TaskEnv      `json:"env"`
 	GitMeta        BuildGitMeta `json:"gitMeta"`
+
+	// Builder selects the remote builder. If unset, the team's default is used.
+	Builder *BuilderOptions `json:"builder,omitempty"`
+
+	// ContentTag, derived from the build context and configuration, is also
+	// tagged on the built image, so that identical builds can be found.
+	ContentTag string `json:"contentTag,omitempty"`
+
+	// Kind and KindOptions, if set, are built instead of the task's own
+	// kind and options, which are left unchanged.
+	Kind        build.TaskKind    `json:"kind,omitempty"`
+	KindOptions build.KindOptions `json:"kindOptions,omitempty"`
+}
+
+// BuilderSize is the resource class of a remote builder.
+type BuilderSize string
+
+// All BuilderSize types.
+const (
+	BuilderSizeSmall BuilderSize = "small"
+	BuilderSizeLarge BuilderSize = "large"
+)
+
+// BuilderOptions selects the remote builder a build runs on. Zero values
+// fall back to the team's defaults.
+type BuilderOptions struct {
+	Size           BuilderSize `json:"size,omitempty"`
+	Region         string      `json:"region,omitempty"`
+	TimeoutSeconds int         `json:"timeoutSeconds,omitempty"`
 }
 
 type BuildGitMeta struct {
@@ -504,6 +973,12 @@ type Build struct {
 	QueuedAt       *time.Time  `json:"queuedAt"`
 	QueuedBy       *string     `json:"queuedBy"`
 	SourceUploadID string      `json:"sourceUploadID"`
+	// ImageDigest is set once the build has pushed its image.
+	ImageDigest string `json:"imageDigest"`
+
+	// Raw is the build as the API returned it, including any fields this
+	// version of the CLI doesn't know about.
+	Raw json.RawMessage `json:"-"`
 }
 
 type BuildStatus string
@@ -522,11 +997,18 @@ func (s BuildStatus) Stopped() bool {
 
 type CreateBuildUploadRequest struct {
 	SizeBytes int `json:"sizeBytes"`
+	// Compression is the format of the uploaded archive: gzip or zstd.
+	Compression string `json:"compression,omitempty"`
+	// Resumable asks for a resumable upload session, if the API supports it.
+	Resumable bool `json:"resumable,omitempty"`
 }
 
 type CreateBuildUploadResponse struct {
 	Upload       Upload `json:"upload"`
 	WriteOnlyURL string `json:"writeOnlyURL"`
+	// Resumable is set if WriteOnlyURL is a resumable upload session, which
+	// is uploaded to in chunks rather than with a single PUT.
+	Resumable bool `json:"resumable,omitempty"`
 }
 
 type Upload struct {
@@ -536,8 +1018,27 @@ type Upload struct {
 
 type CreateAPIKeyRequest struct {
 	Name string `json:"name"`
+	// Scopes limits what the key can be used for. If empty, the key can do
+	// anything its creator can.
+	Scopes []APIKeyScope `json:"scopes,omitempty"`
+	// ExpiresAt is when the key stops working, or nil if it never expires.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
 }
 
+// APIKeyScope limits what an API key can be used for, so that keys for CI can
+// only do what CI needs.
+type APIKeyScope string
+
+const (
+	// APIKeyScopeDeploy allows deploying tasks.
+	APIKeyScopeDeploy APIKeyScope = "deploy"
+	// APIKeyScopeExecute allows executing tasks and reading their runs.
+	APIKeyScopeExecute APIKeyScope = "execute"
+)
+
+// APIKeyScopes lists the valid API key scopes.
+var APIKeyScopes = []APIKeyScope{APIKeyScopeDeploy, APIKeyScopeExecute}
+
 type CreateAPIKeyResponse struct {
 	APIKey APIKey `json:"apiKey"`
 }
@@ -556,6 +1057,10 @@ type APIKey struct {
 	Name      string    `json:"name" yaml:"name"`
 	CreatedAt time.Time `json:"createdAt" yaml:"createdAt"`
 	Key       string    `json:"key" yaml:"key"`
+
+	// Scopes and ExpiresAt are as requested in CreateAPIKeyRequest.
+	Scopes    []APIKeyScope `json:"scopes" yaml:"scopes"`
+	ExpiresAt *time.Time    `json:"expiresAt" yaml:"expiresAt"`
 }
 
 type GetUniqueSlugResponse struct {
@@ -565,3 +1070,30 @@ type GetUniqueSlugResponse struct {
 type ListResourcesResponse struct {
 	Resources []Resource `json:"resources"`
 }
+
+// Permission actions that commands check for before doing slow work.
+const (
+	PermissionTasksCreate  = "tasks.create"
+	PermissionTasksUpdate  = "tasks.update"
+	PermissionTasksExecute = "tasks.execute"
+)
+
+// PermissionCheck asks whether the caller may perform an action, optionally
+// on a specific task.
+type PermissionCheck struct {
+	Action   string `json:"action"`
+	TaskSlug string `json:"taskSlug,omitempty"`
+}
+
+type CheckPermissionsRequest struct {
+	Checks []PermissionCheck `json:"checks"`
+}
+
+type PermissionCheckResult struct {
+	PermissionCheck
+	Allowed bool `json:"allowed"`
+}
+
+type CheckPermissionsResponse struct {
+	Results []PermissionCheckResult `json:"results"`
+}
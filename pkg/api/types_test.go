@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRunDurations(t *testing.T) {
+	var created = time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	var at = func(d time.Duration) *time.Time {
+		t := created.Add(d)
+		return &t
+	}
+	var now = created.Add(time.Hour)
+
+	for _, test := range []struct {
+		name           string
+		run            Run
+		queued, active time.Duration
+		started, done  bool
+	}{
+		{
+			name:    "queued",
+			run:     Run{Status: RunQueued},
+			queued:  time.Hour,
+			started: false,
+		},
+		{
+			name:    "active",
+			run:     Run{Status: RunActive, ActiveAt: at(time.Minute)},
+			queued:  time.Minute,
+			active:  59 * time.Minute,
+			started: true,
+		},
+		{
+			name:    "succeeded",
+			run:     Run{Status: RunSucceeded, ActiveAt: at(time.Minute), SucceededAt: at(3 * time.Minute)},
+			queued:  time.Minute,
+			active:  2 * time.Minute,
+			started: true,
+		},
+		{
+			name: "cancelled while queued",
+			run:  Run{Status: RunCancelled, CancelledAt: at(time.Minute)},
+			done: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			test.run.CreatedAt = created
+
+			queued, ok := test.run.QueuedDuration(now)
+			require.Equal(!test.done, ok)
+			require.Equal(test.queued, queued)
+
+			active, ok := test.run.RunDuration(now)
+			require.Equal(test.started, ok)
+			require.Equal(test.active, active)
+		})
+	}
+}
+
+func TestAdditiveChanges(t *testing.T) {
+	buf := []byte(`{"runID":"run1","status":"Paused","paramValues":{"name":"x"},"attempt":3}`)
+	var run Run
+	require.NoError(t, json.Unmarshal(buf, &run))
+	require.Equal(t, "run1", run.RunID)
+	require.Equal(t, RunStatus("Paused"), run.Status)
+	require.False(t, run.Status.Known())
+	require.JSONEq(t, string(buf), string(run.Raw))
+	require.Equal(t, []string{"attempt"}, unknownFields(buf, &run))
+
+	var task Task
+	require.NoError(t, json.Unmarshal([]byte(`{"taskID":"tsk1","Slug":"hello","runtime":"workflow"}`), &task))
+	require.Equal(t, "tsk1", task.ID)
+	require.Equal(t, "hello", task.Slug)
+	require.Equal(t, []string{"runtime"}, unknownFields(task.Raw, &task))
+
+	var builds []Build
+	require.NoError(t, json.Unmarshal([]byte(`[{"id":"bld1","status":"Retrying"}]`), &builds))
+	require.Equal(t, BuildStatus("Retrying"), builds[0].Status)
+	require.False(t, builds[0].Status.Known())
+	require.True(t, BuildSucceeded.Known())
+}
+
+func TestOutputsYAML(t *testing.T) {
+	var outputs Outputs
+	require.NoError(t, json.Unmarshal([]byte(`{"rows":[{"name":"b","id":1},{"name":"a","id":2.5}],"ok":true,"note":null,"true":"yes"}`), &outputs))
+
+	buf, err := yaml.Marshal(struct {
+		Outputs *Outputs `yaml:"outputs"`
+	}{&outputs})
+	require.NoError(t, err)
+	// Keys keep their order and values their types.
+	require.Equal(t, `outputs:
+    rows:
+        - name: b
+          id: 1
+        - name: a
+          id: 2.5
+    ok: true
+    note: null
+    "true": "yes"
+`, string(buf))
+}
@@ -15,8 +15,8 @@ var (
 	fetchInterval = 1 * time.Second
 )
 
-// LogsClient represents a logs client.
-type logsClient interface {
+// LogsClient is the part of the API a watcher reads runs from.
+type LogsClient interface {
 	GetLogs(ctx context.Context, runID, prevToken string) (GetLogsResponse, error)
 	GetOutputs(ctx context.Context, runID string) (GetOutputsResponse, error)
 	GetRun(ctx context.Context, runID string) (GetRunResponse, error)
@@ -38,12 +38,7 @@ func (r RunState) Err() error {
 
 // Stopped returns true if the run has stopped.
 func (r RunState) Stopped() bool {
-	switch r.Status {
-	case RunCancelled, RunFailed, RunSucceeded:
-		return true
-	default:
-		return false
-	}
+	return r.Status.Stopped()
 }
 
 // Failed returns true if the task has failed.
@@ -54,19 +49,36 @@ func (r RunState) Failed() bool {
 // Watcher represents a run watcher.
 type Watcher struct {
 	ctx    context.Context
-	client logsClient
+	client LogsClient
 	runID  string
 	state  chan RunState
+	// logFilter, if set, rewrites the text of every log as it is fetched.
+	logFilter func(string) string
+}
+
+// WatcherOption configures a watcher.
+type WatcherOption func(*Watcher)
+
+// WithLogFilter rewrites the text of every log before it is handed out, such
+// as to redact secrets. Logs are filtered as they are fetched, so nothing that
+// consumes the watcher sees the original text.
+func WithLogFilter(f func(string) string) WatcherOption {
+	return func(w *Watcher) {
+		w.logFilter = f
+	}
 }
 
 // NewWatcher returns a new watcher with the given runID and context.
-func newWatcher(ctx context.Context, client logsClient, runID string) *Watcher {
+func NewWatcher(ctx context.Context, client LogsClient, runID string, opts ...WatcherOption) *Watcher {
 	w := &Watcher{
 		ctx:    ctx,
 		client: client,
 		runID:  runID,
 		state:  make(chan RunState),
 	}
+	for _, opt := range opts {
+		opt(w)
+	}
 	go w.watch()
 	return w
 }
@@ -84,42 +96,85 @@ func (w *Watcher) Next() RunState {
 // Watch implements a watcher go-routine.
 //
 // On every tick the method attempts to fetch the most recent
-// logs and run status and sends them on an internal "state" channel
-// on fetch failure, or when the task is canceled a special state
-// is sent with an error.
+// logs and run status. Fetched logs are queued in a logBuffer, which spills
+// to disk once it grows large, and handed out in batches on an internal
+// "state" channel as fast as they are consumed, so that fetching never waits
+// on a slow consumer. The run is only reported as stopped once every log has
+// been consumed.
+//
+// On fetch failure, or when the task is canceled, a special state
+// is sent with an error once the logs fetched until then are consumed.
 func (w *Watcher) watch() {
 	var ticker = time.NewTicker(fetchInterval)
+	defer ticker.Stop()
+	var buf = newLogBuffer(logBufferMemory)
+	defer buf.Close()
+
+	// prev is the latest fetched state, and fetched is set when it hasn't
+	// been reported yet.
 	var prev RunState
+	var fetched bool
+	var fetchErr error
+
+	// next is the state waiting to be consumed, if pending.
+	var next RunState
+	var pending bool
 
 	for {
+		if !pending && (fetched || buf.Len() > 0 || fetchErr != nil) {
+			logs, err := buf.Pop(maxLogBatch)
+			switch {
+			case err != nil:
+				next = RunState{err: err}
+			case len(logs) == 0 && buf.Len() == 0 && fetchErr != nil:
+				next = RunState{err: fetchErr}
+			default:
+				next = prev
+				next.Logs = logs
+				next.err = nil
+				if next.Stopped() && buf.Len() > 0 {
+					// Hold back the final status until the logs are drained.
+					next.Status = RunActive
+					next.Outputs = Outputs{}
+				}
+			}
+			pending, fetched = true, false
+		}
+
+		var out chan RunState
+		if pending {
+			out = w.state
+		}
+
 		select {
 		case <-w.ctx.Done():
 			// TODO(amir): actually send a cancel request
 			// and wait for the API state change.
-			w.send(w.ctx, RunState{})
+			w.state <- RunState{err: w.ctx.Err()}
+			return
 
 		case <-ticker.C:
+			if prev.Stopped() || fetchErr != nil {
+				continue
+			}
 			state, err := w.fetch(w.ctx, prev)
 			if err != nil {
-				w.send(w.ctx, RunState{
-					err: err,
-				})
-				return
+				fetchErr = err
+				continue
 			}
-
-			w.send(w.ctx, state)
+			if err := buf.Push(state.Logs); err != nil {
+				fetchErr = err
+				continue
+			}
+			state.Logs = nil
 			prev = state
-		}
-	}
-}
+			fetched = true
 
-// Send sends the given state with context.
-func (w *Watcher) send(ctx context.Context, state RunState) {
-	select {
-	case w.state <- state:
-	case <-ctx.Done():
-		w.state <- RunState{
-			err: ctx.Err(),
+		case out <- next:
+			pending = false
+			if next.err != nil || next.Stopped() {
+				return
+			}
 		}
 	}
 }
@@ -137,7 +192,7 @@ func (w *Watcher) fetch(ctx context.Context, prev RunState) (RunState, error) {
 
 		state.Status = run.Run.Status
 
-		if state.Stopped() {
+		if run.Run.Status.Stopped() {
 			resp, err := w.client.GetOutputs(subctx, w.runID)
 			if err != nil {
 				return errors.Wrap(err, "get outputs")
@@ -154,6 +209,11 @@ func (w *Watcher) fetch(ctx context.Context, prev RunState) (RunState, error) {
 			return errors.Wrap(err, "get logs")
 		}
 		SortLogs(resp.Logs)
+		if w.logFilter != nil {
+			for i := range resp.Logs {
+				resp.Logs[i].Text = w.logFilter(resp.Logs[i].Text)
+			}
+		}
 
 		state.Logs = resp.Logs
 		state.PrevToken = prev.PrevToken
@@ -84,7 +84,7 @@ func TestWatcher(t *testing.T) {
 		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
-		var w = newWatcher(ctx, lcm, "run_id")
+		var w = NewWatcher(ctx, lcm, "run_id")
 		var state RunState
 		var printed []string
 
@@ -124,3 +124,53 @@ func (lcm logsClientMock) GetRun(ctx context.Context, runID string) (GetRunRespo
 func (lcm logsClientMock) GetOutputs(ctx context.Context, runID string) (GetOutputsResponse, error) {
 	return lcm.getOutputs(runID)
 }
+
+func TestWatcherDrainsLogsBeforeStopping(t *testing.T) {
+	require := require.New(t)
+	defer func(memory, batch int) {
+		logBufferMemory, maxLogBatch = memory, batch
+	}(logBufferMemory, maxLogBatch)
+	logBufferMemory, maxLogBatch = 1, 2
+
+	var logs []LogItem
+	for i := 0; i < 7; i++ {
+		logs = append(logs, LogItem{InsertID: fmt.Sprintf("%03d", i), Text: fmt.Sprint(i)})
+	}
+
+	lcm := logsClientMock{
+		getLogs: func(runID, prevToken string) (GetLogsResponse, error) {
+			if prevToken == "" {
+				return GetLogsResponse{Logs: logs, PrevPageToken: "done"}, nil
+			}
+			return GetLogsResponse{}, nil
+		},
+		getRun: func(string) (GetRunResponse, error) {
+			return GetRunResponse{Run{Status: RunSucceeded}}, nil
+		},
+		getOutputs: func(string) (GetOutputsResponse, error) {
+			return GetOutputsResponse{}, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w := NewWatcher(ctx, lcm, "run_id")
+	var printed []string
+	var states int
+	for {
+		state := w.Next()
+		require.NoError(state.Err())
+		states++
+		require.LessOrEqual(len(state.Logs), 2)
+		for _, l := range state.Logs {
+			printed = append(printed, l.Text)
+		}
+		if state.Stopped() {
+			break
+		}
+	}
+
+	require.Equal([]string{"0", "1", "2", "3", "4", "5", "6"}, printed)
+	require.Equal(4, states)
+}
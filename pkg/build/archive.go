@@ -0,0 +1,131 @@
+package build
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"runtime"
+
+	"github.com/airplanedev/archiver"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Compression is the format a build context is compressed with before it is
+// uploaded to a remote builder.
+type Compression string
+
+// All Compression types.
+const (
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression parses a compression format and level, where a level of
+// zero picks the format's default.
+func ParseCompression(format string, level int) (Compression, error) {
+	c := Compression(format)
+	if c == "" {
+		c = CompressionGzip
+	}
+	var max int
+	switch c {
+	case CompressionGzip:
+		max = gzip.BestCompression
+	case CompressionZstd:
+		max = 22
+	default:
+		return "", errors.Errorf("unknown compression %q: expected gzip or zstd", format)
+	}
+	if level < 0 || level > max {
+		return "", errors.Errorf("%s compression level must be between 1 and %d, or 0 for the default", c, max)
+	}
+	return c, nil
+}
+
+// ext returns the file extension of a tar archive compressed with c.
+func (c Compression) ext() string {
+	if c == CompressionZstd {
+		return ".tar.zst"
+	}
+	return ".tar.gz"
+}
+
+// archiveTaskDir writes the contents of root, less any ignored files, to a
+// tarball at archivePath compressed with compression at the given level.
+// Both formats compress in parallel.
+func archiveTaskDir(root string, archivePath string, compression Compression, level int) error {
+	// mholt/archiver takes a list of "sources" (files/directories) that will
+	// be included in the root of the archive. In our case, we want the root of
+	// the archive to be the contents of the task directory, rather than the
+	// task directory itself.
+	var sources []string
+	if files, err := ioutil.ReadDir(root); err != nil {
+		return errors.Wrap(err, "inspecting files in task root")
+	} else {
+		for _, f := range files {
+			sources = append(sources, path.Join(root, f.Name()))
+		}
+	}
+
+	include, err := includeFunc(root)
+	if err != nil {
+		return err
+	}
+
+	if compression != CompressionZstd {
+		arch := archiver.NewTarGz()
+		arch.Tar.IncludeFunc = include
+		if level != 0 {
+			arch.CompressionLevel = level
+		}
+		if err := arch.Archive(sources, archivePath); err != nil {
+			return errors.Wrap(err, "building archive")
+		}
+		return nil
+	}
+
+	// archiver's zstd support can't be tuned, so write a plain tarball and
+	// compress it separately.
+	tarPath := archivePath + ".tar"
+	defer os.Remove(tarPath)
+	arch := archiver.NewTar()
+	arch.IncludeFunc = include
+	if err := arch.Archive(sources, tarPath); err != nil {
+		return errors.Wrap(err, "building archive")
+	}
+	return compressZstd(tarPath, archivePath, level)
+}
+
+func compressZstd(src, dst string, level int) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrap(err, "opening archive")
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return errors.Wrap(err, "creating archive")
+	}
+	defer out.Close()
+
+	opts := []zstd.EOption{zstd.WithEncoderConcurrency(runtime.GOMAXPROCS(0))}
+	if level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	w, err := zstd.NewWriter(out, opts...)
+	if err != nil {
+		return errors.Wrap(err, "creating zstd writer")
+	}
+	if _, err := io.Copy(w, in); err != nil {
+		w.Close()
+		return errors.Wrap(err, "compressing archive")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "compressing archive")
+	}
+	return errors.Wrap(out.Close(), "writing archive")
+}
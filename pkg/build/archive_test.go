@@ -0,0 +1,32 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCompression(t *testing.T) {
+	for _, test := range []struct {
+		format string
+		level  int
+		want   Compression
+		err    string
+	}{
+		{format: "", level: 0, want: CompressionGzip},
+		{format: "gzip", level: 0, want: CompressionGzip},
+		{format: "gzip", level: 9, want: CompressionGzip},
+		{format: "zstd", level: 22, want: CompressionZstd},
+		{format: "gzip", level: 10, err: "gzip compression level must be between 1 and 9, or 0 for the default"},
+		{format: "zstd", level: -1, err: "zstd compression level must be between 1 and 22, or 0 for the default"},
+		{format: "xz", level: 0, err: `unknown compression "xz": expected gzip or zstd`},
+	} {
+		c, err := ParseCompression(test.format, test.level)
+		if test.err != "" {
+			require.EqualError(t, err, test.err, "%s %d", test.format, test.level)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, test.want, c)
+	}
+}
@@ -0,0 +1,242 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	libBuild "github.com/airplanedev/lib/pkg/build"
+	"github.com/airplanedev/lib/pkg/utils/fsx"
+	"github.com/pkg/errors"
+)
+
+// Severity is how severe a dependency vulnerability is, as reported by npm.
+type Severity string
+
+// All Severity types, from least to most severe.
+const (
+	SeverityLow      Severity = "low"
+	SeverityModerate Severity = "moderate"
+	SeverityHigh     Severity = "high"
+	SeverityCritical Severity = "critical"
+)
+
+var severities = []Severity{SeverityLow, SeverityModerate, SeverityHigh, SeverityCritical}
+
+// ParseSeverity parses a --audit-level flag. An empty level is valid and
+// means that vulnerabilities are reported without failing the build.
+func ParseSeverity(s string) (Severity, error) {
+	if s == "" {
+		return "", nil
+	}
+	for _, sev := range severities {
+		if Severity(s) == sev {
+			return sev, nil
+		}
+	}
+	return "", errors.Errorf("unknown audit level %q: expected low, moderate, high or critical", s)
+}
+
+// AuditOptions configures the dependency audit that runs before a build.
+type AuditOptions struct {
+	// FailOn fails the build if any vulnerability is at least this severe.
+	// If empty, vulnerabilities are only reported.
+	FailOn Severity
+}
+
+// AuditReport summarizes the vulnerabilities found in a task's dependencies.
+type AuditReport struct {
+	// Tool is the auditing command that was run.
+	Tool string
+	// Counts are the number of vulnerabilities of each severity.
+	Counts map[Severity]int
+	// Unrated is the number of vulnerabilities reported without a severity.
+	// pip-audit does not rate vulnerabilities, so all of its findings are
+	// unrated.
+	Unrated int
+}
+
+// Total returns the number of vulnerabilities found.
+func (r AuditReport) Total() int {
+	n := r.Unrated
+	for _, c := range r.Counts {
+		n += c
+	}
+	return n
+}
+
+func (r AuditReport) String() string {
+	var parts []string
+	for i := len(severities) - 1; i >= 0; i-- {
+		if c := r.Counts[severities[i]]; c > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c, severities[i]))
+		}
+	}
+	if r.Unrated > 0 {
+		parts = append(parts, fmt.Sprintf("%d unrated", r.Unrated))
+	}
+	if len(parts) == 0 {
+		return "no vulnerabilities"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Fails reports whether the report has any vulnerability at least as severe
+// as level. Unrated vulnerabilities fail any level.
+func (r AuditReport) Fails(level Severity) bool {
+	if level == "" {
+		return false
+	}
+	if r.Unrated > 0 {
+		return true
+	}
+	failing := false
+	for _, sev := range severities {
+		if sev == level {
+			failing = true
+		}
+		if failing && r.Counts[sev] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditError is returned when a task's dependencies have vulnerabilities at
+// or above the requested level.
+type AuditError struct {
+	Report AuditReport
+	Level  Severity
+}
+
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("%s found %s, failing on %s or above", e.Report.Tool, e.Report, e.Level)
+}
+
+// ExplainError implementation.
+func (e *AuditError) ExplainError() string {
+	return fmt.Sprintf("Run %s in the task's directory for details, or raise --audit-level.", e.Report.Tool)
+}
+
+// audit checks the dependencies of the task at root for known
+// vulnerabilities. Only Node tasks with a package-lock.json and Python tasks
+// with a requirements.txt are audited; other tasks are skipped.
+func audit(ctx context.Context, req Request) error {
+	kind, _, err := req.Def.GetKindAndOptions()
+	if err != nil {
+		return err
+	}
+
+	var tool string
+	var args []string
+	var parse func([]byte) (AuditReport, error)
+	switch kind {
+	case libBuild.TaskKindNode:
+		if !fsx.Exists(filepath.Join(req.Root, "package-lock.json")) {
+			logger.Debug("Skipping audit: no package-lock.json in %s", req.Root)
+			return nil
+		}
+		tool, args, parse = "npm audit", []string{"npm", "audit", "--json", "--package-lock-only"}, parseNPMAudit
+	case libBuild.TaskKindPython:
+		if !fsx.Exists(filepath.Join(req.Root, "requirements.txt")) {
+			logger.Debug("Skipping audit: no requirements.txt in %s", req.Root)
+			return nil
+		}
+		tool, args, parse = "pip-audit", []string{"pip-audit", "-r", "requirements.txt", "-f", "json", "--progress-spinner", "off"}, parsePipAudit
+	default:
+		return nil
+	}
+
+	loader := logger.NewLoader(logger.LoaderOpts{HideLoader: true})
+	buildLog(ctx, PhaseAuditing, api.LogLevelInfo, loader, logger.Gray, "Auditing dependencies with %s...", tool)
+
+	report, err := runAudit(ctx, req.Root, args, parse)
+	if err != nil {
+		if req.Audit.FailOn != "" {
+			return errors.Wrapf(err, "auditing dependencies with %s", tool)
+		}
+		logger.Warning("Unable to audit dependencies with %s: %v", tool, err)
+		return nil
+	}
+	report.Tool = tool
+
+	if report.Total() == 0 {
+		buildLog(ctx, PhaseAuditing, api.LogLevelInfo, loader, logger.Gray, "No known vulnerabilities.")
+		return nil
+	}
+	if report.Fails(req.Audit.FailOn) {
+		return &AuditError{Report: report, Level: req.Audit.FailOn}
+	}
+	logger.Warning("%s found %s in %s", tool, report, req.Def.GetSlug())
+	return nil
+}
+
+// runAudit runs an audit command in dir. Audit tools exit non-zero when they
+// find vulnerabilities, so the exit code is ignored whenever the report can
+// be parsed.
+func runAudit(ctx context.Context, dir string, args []string, parse func([]byte) (AuditReport, error)) (AuditReport, error) {
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return AuditReport{}, errors.Errorf("%s is not installed", args[0])
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	report, err := parse(stdout.Bytes())
+	if err != nil {
+		if runErr != nil {
+			return AuditReport{}, errors.Errorf("%v: %s", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return AuditReport{}, err
+	}
+	return report, nil
+}
+
+func parseNPMAudit(buf []byte) (AuditReport, error) {
+	var res struct {
+		Metadata struct {
+			Vulnerabilities map[string]int `json:"vulnerabilities"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(buf, &res); err != nil {
+		return AuditReport{}, errors.Wrap(err, "reading npm audit report")
+	}
+	if res.Metadata.Vulnerabilities == nil {
+		return AuditReport{}, errors.New("reading npm audit report: no vulnerability counts")
+	}
+	report := AuditReport{Counts: map[Severity]int{}}
+	for _, sev := range severities {
+		report.Counts[sev] = res.Metadata.Vulnerabilities[string(sev)]
+	}
+	return report, nil
+}
+
+func parsePipAudit(buf []byte) (AuditReport, error) {
+	type dependency struct {
+		Vulns []json.RawMessage `json:"vulns"`
+	}
+	var deps []dependency
+	// pip-audit 2.0 wraps the dependencies in an object.
+	var wrapped struct {
+		Dependencies []dependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(buf, &wrapped); err == nil {
+		deps = wrapped.Dependencies
+	} else if err := json.Unmarshal(buf, &deps); err != nil {
+		return AuditReport{}, errors.Wrap(err, "reading pip-audit report")
+	}
+	report := AuditReport{Counts: map[Severity]int{}}
+	for _, d := range deps {
+		report.Unrated += len(d.Vulns)
+	}
+	return report, nil
+}
@@ -0,0 +1,166 @@
+package build
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeverity(t *testing.T) {
+	for _, s := range []string{"", "low", "moderate", "high", "critical"} {
+		sev, err := ParseSeverity(s)
+		require.NoError(t, err)
+		require.Equal(t, Severity(s), sev)
+	}
+	_, err := ParseSeverity("severe")
+	require.EqualError(t, err, `unknown audit level "severe": expected low, moderate, high or critical`)
+}
+
+func TestAuditReport(t *testing.T) {
+	report := AuditReport{Counts: map[Severity]int{SeverityLow: 2, SeverityHigh: 1}}
+	require.Equal(t, 3, report.Total())
+	require.Equal(t, "1 high, 2 low", report.String())
+	require.False(t, report.Fails(""))
+	require.True(t, report.Fails(SeverityLow))
+	require.True(t, report.Fails(SeverityHigh))
+	require.False(t, report.Fails(SeverityCritical))
+
+	unrated := AuditReport{Counts: map[Severity]int{}, Unrated: 2}
+	require.Equal(t, 2, unrated.Total())
+	require.Equal(t, "2 unrated", unrated.String())
+	// Unrated vulnerabilities fail any level.
+	require.True(t, unrated.Fails(SeverityCritical))
+
+	require.Equal(t, "no vulnerabilities", AuditReport{}.String())
+}
+
+func TestParseNPMAudit(t *testing.T) {
+	report, err := parseNPMAudit([]byte(`{
+		"auditReportVersion": 2,
+		"metadata": {"vulnerabilities": {"info": 4, "low": 1, "moderate": 0, "high": 2, "critical": 1, "total": 8}}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, map[Severity]int{SeverityLow: 1, SeverityModerate: 0, SeverityHigh: 2, SeverityCritical: 1}, report.Counts)
+
+	_, err = parseNPMAudit([]byte(`{"error": {"code": "ENOLOCK"}}`))
+	require.EqualError(t, err, "reading npm audit report: no vulnerability counts")
+	_, err = parseNPMAudit([]byte(`npm ERR!`))
+	require.Error(t, err)
+}
+
+func TestParsePipAudit(t *testing.T) {
+	for name, out := range map[string]string{
+		"list":   `[{"name": "flask", "version": "0.5", "vulns": [{"id": "PYSEC-1"}, {"id": "PYSEC-2"}]}, {"name": "click", "version": "8.0", "vulns": []}]`,
+		"object": `{"dependencies": [{"name": "flask", "version": "0.5", "vulns": [{"id": "PYSEC-1"}, {"id": "PYSEC-2"}]}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			report, err := parsePipAudit([]byte(out))
+			require.NoError(t, err)
+			require.Equal(t, 2, report.Unrated)
+			require.Equal(t, 2, report.Total())
+		})
+	}
+	_, err := parsePipAudit([]byte(`ERROR: no such file`))
+	require.Error(t, err)
+}
+
+func TestRunAudit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// The exit code is ignored when the report parses.
+	report, err := runAudit(ctx, dir, []string{"sh", "-c", `echo '[{"vulns": [{}]}]'; exit 1`}, parsePipAudit)
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Unrated)
+
+	_, err = runAudit(ctx, dir, []string{"sh", "-c", `echo "bad lockfile" >&2; exit 2`}, parsePipAudit)
+	require.EqualError(t, err, "exit status 2: bad lockfile")
+
+	_, err = runAudit(ctx, dir, []string{"not-an-audit-tool"}, parsePipAudit)
+	require.EqualError(t, err, "not-an-audit-tool is not installed")
+}
+
+func TestAudit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script in place of npm")
+	}
+	ctx := context.Background()
+
+	// fakeNPM puts an npm on the PATH that prints out and exits with code.
+	fakeNPM := func(t *testing.T, out string, code string) {
+		bin := t.TempDir()
+		script := "#!/bin/sh\ncat <<'EOF'\n" + out + "\nEOF\nexit " + code + "\n"
+		require.NoError(t, ioutil.WriteFile(filepath.Join(bin, "npm"), []byte(script), 0755))
+		t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	}
+	// request returns a request to build a Node task, with a package-lock.json
+	// if lock is set.
+	request := func(t *testing.T, lock bool, failOn Severity) Request {
+		root := t.TempDir()
+		if lock {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(root, "package-lock.json"), []byte("{}"), 0644))
+		}
+		return Request{
+			Root: root,
+			Def: &definitions.Definition{
+				Slug: "hello",
+				Node: &definitions.NodeDefinition{Entrypoint: "main.js"},
+			},
+			Audit: &AuditOptions{FailOn: failOn},
+		}
+	}
+	const vulnerable = `{"metadata": {"vulnerabilities": {"low": 0, "moderate": 1, "high": 1, "critical": 0}}}`
+
+	t.Run("fails at the level", func(t *testing.T) {
+		// npm audit exits non-zero when it finds vulnerabilities.
+		fakeNPM(t, vulnerable, "1")
+		err := audit(ctx, request(t, true, SeverityHigh))
+		require.Error(t, err)
+		var aerr *AuditError
+		require.ErrorAs(t, err, &aerr)
+		require.Equal(t, "npm audit found 1 high, 1 moderate, failing on high or above", err.Error())
+	})
+
+	t.Run("reports below the level", func(t *testing.T) {
+		fakeNPM(t, vulnerable, "1")
+		require.NoError(t, audit(ctx, request(t, true, SeverityCritical)))
+	})
+
+	t.Run("no vulnerabilities", func(t *testing.T) {
+		fakeNPM(t, `{"metadata": {"vulnerabilities": {}}}`, "0")
+		require.NoError(t, audit(ctx, request(t, true, SeverityLow)))
+	})
+
+	t.Run("no lockfile", func(t *testing.T) {
+		fakeNPM(t, `not json`, "1")
+		require.NoError(t, audit(ctx, request(t, false, SeverityLow)))
+	})
+
+	t.Run("audit fails", func(t *testing.T) {
+		fakeNPM(t, `not json`, "1")
+		err := audit(ctx, request(t, true, SeverityLow))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "auditing dependencies with npm audit")
+
+		// Without a level, failing to audit is only a warning.
+		require.NoError(t, audit(ctx, request(t, true, "")))
+	})
+
+	t.Run("other kinds", func(t *testing.T) {
+		req := request(t, true, SeverityLow)
+		req.Def = &definitions.Definition{
+			Slug:  "hello",
+			Image: &definitions.ImageDefinition{Image: "ubuntu:20.04"},
+		}
+		require.NoError(t, audit(ctx, req))
+	})
+}
@@ -5,32 +5,92 @@ import (
 
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/taskdir/definitions"
-	"github.com/airplanedev/lib/pkg/build"
 )
 
 // Request represents a build request.
 type Request struct {
 	Local   bool
-	Client  *api.Client
+	Client  api.APIClient
 	Root    string
 	Def     definitions.DefinitionInterface
 	TaskID  string
 	TaskEnv api.TaskEnv
 	Shim    bool
 	GitMeta api.BuildGitMeta
+
+	// Compression and CompressionLevel tune how the build context is
+	// compressed for remote builds. They default to gzip at its default level.
+	Compression      Compression
+	CompressionLevel int
+
+	// BuildOnly, if set, builds the image without changing the task. Remote
+	// builds send the definition's kind and options with the build instead
+	// of updating the task's.
+	BuildOnly bool
+
+	// Builder selects the remote builder, or nil for the team's default.
+	// It is ignored for local builds.
+	Builder *api.BuilderOptions
+
+	// Audit, if set, checks the task's dependencies for known
+	// vulnerabilities before it is built.
+	Audit *AuditOptions
+
+	// Lint, if set, checks the Dockerfile of a Dockerfile task for common
+	// problems before it is built.
+	Lint *LintOptions
+
+	// Healthcheck, if set, starts the runtime in a locally built image's
+	// entrypoint, without running the task, before pushing it, when the
+	// image was built under emulation. It is ignored for remote builds.
+	Healthcheck bool
+
+	// Progress, if set, receives progress events instead of the build
+	// logging to stderr. It is not closed when the build finishes.
+	Progress chan<- Event
 }
 
 // Response represents a build response.
 type Response struct {
 	ImageURL string
 	// Optional, only if applicable
-	BuildID string
+	BuildID     string
+	ImageDigest string
 }
 
 // Run runs the build and returns an image URL.
-func Run(ctx context.Context, deployer *Deployer, req Request) (*build.Response, error) {
-	if req.Local {
-		return deployer.local(ctx, req)
+//
+// Images are tagged by the content of their build, so if deployer, or an
+// earlier invocation of the CLI, has already built an identical build
+// context, that image is returned instead.
+func Run(ctx context.Context, deployer *Deployer, req Request) (*Response, error) {
+	ctx = context.WithValue(ctx, taskSlugContextKey, req.Def.GetSlug())
+	if req.Progress != nil {
+		ctx = context.WithValue(ctx, progressContextKey, req.Progress)
+	}
+
+	tag, err := contentTag(req)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := deployer.getRegistryToken(ctx, req.Client)
+	if err != nil {
+		return nil, err
 	}
-	return deployer.remote(ctx, req)
+	return deployer.buildOnce(ctx, registry.Repo, tag, func() (*Response, error) {
+		if req.Audit != nil {
+			if err := audit(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		if req.Lint != nil {
+			if err := lintDockerfile(ctx, req); err != nil {
+				return nil, err
+			}
+		}
+		if req.Local {
+			return deployer.local(ctx, req, tag)
+		}
+		return deployer.remote(ctx, req, tag)
+	})
 }
@@ -0,0 +1,31 @@
+package build
+
+import (
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/pkg/errors"
+)
+
+// ParseBuilder parses the remote builder flags into options for the API. It
+// returns nil if none were set, so that the team's default builder is used.
+func ParseBuilder(size, region string, timeout time.Duration) (*api.BuilderOptions, error) {
+	if size == "" && region == "" && timeout == 0 {
+		return nil, nil
+	}
+
+	switch s := api.BuilderSize(size); s {
+	case "", api.BuilderSizeSmall, api.BuilderSizeLarge:
+	default:
+		return nil, errors.Errorf("unknown builder size %q: expected %s or %s", size, api.BuilderSizeSmall, api.BuilderSizeLarge)
+	}
+	if timeout < 0 || timeout%time.Second != 0 {
+		return nil, errors.Errorf("build timeout must be a positive number of seconds, such as 30m: got %s", timeout)
+	}
+
+	return &api.BuilderOptions{
+		Size:           api.BuilderSize(size),
+		Region:         region,
+		TimeoutSeconds: int(timeout / time.Second),
+	}, nil
+}
@@ -0,0 +1,77 @@
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// Checksum returns a checksum of the build context at root, along with any
+// additional values (such as the task definition) that affect a deploy.
+//
+// Files are filtered with the same ignore rules used when packaging the build
+// context, including root's .airplaneignore, so files that are never uploaded
+// don't change the checksum.
+func Checksum(root string, values ...interface{}) (string, error) {
+	include, err := includeFunc(root)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root {
+			if ok, err := include(path, info); err != nil {
+				return err
+			} else if !ok {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s\x00%o\x00", filepath.ToSlash(rel), info.Mode().Perm())
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		h.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "hashing build context")
+	}
+
+	for _, v := range values {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return "", errors.Wrap(err, "hashing definition")
+		}
+		h.Write(buf)
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,350 @@
+package build
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	libBuild "github.com/airplanedev/lib/pkg/build"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// LintSeverity is how severe a Dockerfile lint finding is. They follow
+// hadolint's levels, so that a .hadolint.yaml can be reused as is.
+type LintSeverity string
+
+// All LintSeverity types, from least to most severe.
+const (
+	LintStyle   LintSeverity = "style"
+	LintInfo    LintSeverity = "info"
+	LintWarning LintSeverity = "warning"
+	LintError   LintSeverity = "error"
+)
+
+var lintSeverities = []LintSeverity{LintStyle, LintInfo, LintWarning, LintError}
+
+func (s LintSeverity) rank() int {
+	for i, sev := range lintSeverities {
+		if s == sev {
+			return i
+		}
+	}
+	return -1
+}
+
+// LintOptions configures the Dockerfile lint that runs before a build of a
+// Dockerfile task.
+type LintOptions struct {
+	// Strict fails the build if any finding is at least as severe as the
+	// failure threshold, which is warning unless configured otherwise.
+	// Otherwise findings are only reported.
+	Strict bool
+}
+
+// LintFinding is a problem found in a Dockerfile.
+type LintFinding struct {
+	Rule     string
+	Severity LintSeverity
+	Line     int
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("line %d: %s %s: %s", f.Line, f.Rule, f.Severity, f.Message)
+}
+
+// DockerfileLintError is returned when a Dockerfile has findings at or above
+// the failure threshold and the lint is strict.
+type DockerfileLintError struct {
+	Dockerfile string
+	Findings   []LintFinding
+	Threshold  LintSeverity
+}
+
+func (e *DockerfileLintError) Error() string {
+	return fmt.Sprintf("%s has %d lint findings at %s or above", e.Dockerfile, len(e.Findings), e.Threshold)
+}
+
+// ExplainError implementation.
+func (e *DockerfileLintError) ExplainError() string {
+	return "Fix the findings above, or ignore or lower their rules in a .hadolint.yaml next to the Dockerfile."
+}
+
+// lintConfig is the subset of hadolint's configuration file that is
+// supported:
+//
+//	ignored: [DL3007]
+//	override:
+//	  error: [DL3009]
+//	failure-threshold: warning
+type lintConfig struct {
+	Ignored          []string                  `yaml:"ignored"`
+	Override         map[LintSeverity][]string `yaml:"override"`
+	FailureThreshold LintSeverity              `yaml:"failure-threshold"`
+}
+
+// readLintConfig reads the .hadolint.yaml in dir, if any.
+func readLintConfig(dir string) (lintConfig, error) {
+	var cfg lintConfig
+	for _, name := range []string{".hadolint.yaml", ".hadolint.yml"} {
+		buf, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return cfg, errors.Wrapf(err, "reading %s", name)
+		}
+		if err := yaml.Unmarshal(buf, &cfg); err != nil {
+			return cfg, errors.Wrapf(err, "parsing %s", name)
+		}
+		for sev := range cfg.Override {
+			if sev.rank() < 0 {
+				return cfg, errors.Errorf("%s: unknown severity %q: expected error, warning, info or style", name, sev)
+			}
+		}
+		if cfg.FailureThreshold != "" && cfg.FailureThreshold != "none" && cfg.FailureThreshold.rank() < 0 {
+			return cfg, errors.Errorf("%s: unknown failure-threshold %q: expected error, warning, info, style or none", name, cfg.FailureThreshold)
+		}
+		break
+	}
+	return cfg, nil
+}
+
+// apply drops ignored findings and overrides the severity of the others.
+func (c lintConfig) apply(findings []LintFinding) []LintFinding {
+	ignored := map[string]bool{}
+	for _, rule := range c.Ignored {
+		ignored[rule] = true
+	}
+	var res []LintFinding
+	for _, f := range findings {
+		if ignored[f.Rule] {
+			continue
+		}
+		for sev, rules := range c.Override {
+			for _, rule := range rules {
+				if rule == f.Rule {
+					f.Severity = sev
+				}
+			}
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+// threshold returns the least severe level that fails a strict lint, or ""
+// if nothing does. It defaults to warning, the most severe level of the
+// built-in rules.
+func (c lintConfig) threshold() LintSeverity {
+	switch c.FailureThreshold {
+	case "":
+		return LintWarning
+	case "none":
+		return ""
+	default:
+		return c.FailureThreshold
+	}
+}
+
+// lintDockerfile checks the Dockerfile of a Dockerfile task for common
+// problems before it is built. Other tasks are skipped.
+func lintDockerfile(ctx context.Context, req Request) error {
+	kind, options, err := req.Def.GetKindAndOptions()
+	if err != nil {
+		return err
+	}
+	if kind != libBuild.TaskKindDockerfile {
+		return nil
+	}
+	dockerfile, _ := options["dockerfile"].(string)
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	path := filepath.Join(req.Root, dockerfile)
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		// The build reports a missing Dockerfile more clearly.
+		logger.Debug("Skipping Dockerfile lint: %v", err)
+		return nil
+	}
+	cfg, err := readLintConfig(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	findings := cfg.apply(LintDockerfile(string(buf)))
+	loader := logger.NewLoader(logger.LoaderOpts{HideLoader: true})
+	if len(findings) == 0 {
+		buildLog(ctx, PhaseLinting, api.LogLevelInfo, loader, logger.Gray, "No problems found in %s.", dockerfile)
+		return nil
+	}
+
+	var failing []LintFinding
+	threshold := cfg.threshold()
+	for _, f := range findings {
+		logger.Warning("%s %s", dockerfile, f)
+		if threshold != "" && f.Severity.rank() >= threshold.rank() {
+			failing = append(failing, f)
+		}
+	}
+	if req.Lint.Strict && len(failing) > 0 {
+		return &DockerfileLintError{Dockerfile: dockerfile, Findings: failing, Threshold: threshold}
+	}
+	return nil
+}
+
+// dockerInstruction is a single instruction of a Dockerfile, with its line
+// continuations joined.
+type dockerInstruction struct {
+	line int
+	cmd  string
+	args string
+}
+
+func parseDockerfile(s string) []dockerInstruction {
+	var insts []dockerInstruction
+	var cur *dockerInstruction
+	scanner := bufio.NewScanner(strings.NewReader(s))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		// Like Docker, skip blank lines and comments, even within a continuation.
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cont := strings.HasSuffix(line, `\`)
+		line = strings.TrimSuffix(line, `\`)
+		if cur == nil {
+			parts := strings.SplitN(line, " ", 2)
+			cur = &dockerInstruction{line: n, cmd: strings.ToUpper(parts[0])}
+			if len(parts) == 2 {
+				cur.args = strings.TrimSpace(parts[1])
+			}
+		} else {
+			cur.args = strings.TrimSpace(cur.args + " " + line)
+		}
+		if !cont {
+			insts = append(insts, *cur)
+			cur = nil
+		}
+	}
+	if cur != nil {
+		insts = append(insts, *cur)
+	}
+	return insts
+}
+
+// LintDockerfile checks a Dockerfile against a few of hadolint's rules, and
+// one of its own for images that never set a USER. Only the final stage of a
+// multi-stage build is checked for USER and apt caches, since the others
+// don't end up in the image.
+func LintDockerfile(dockerfile string) []LintFinding {
+	var findings []LintFinding
+	stages := map[string]bool{}
+	var final []dockerInstruction
+
+	for _, inst := range parseDockerfile(dockerfile) {
+		if inst.cmd != "FROM" {
+			final = append(final, inst)
+			continue
+		}
+		final = []dockerInstruction{inst}
+
+		fields := strings.Fields(inst.args)
+		var image string
+		for i := 0; i < len(fields); i++ {
+			if strings.HasPrefix(fields[i], "--") {
+				continue
+			}
+			image = fields[i]
+			if i+2 < len(fields) && strings.EqualFold(fields[i+1], "as") {
+				stages[strings.ToLower(fields[i+2])] = true
+			}
+			break
+		}
+		if f, ok := lintImage(image, stages); ok {
+			f.Line = inst.line
+			findings = append(findings, f)
+		}
+	}
+
+	var lastUser *dockerInstruction
+	for i, inst := range final {
+		switch inst.cmd {
+		case "USER":
+			lastUser = &final[i]
+		case "RUN":
+			if strings.Contains(inst.args, "apt-get install") && !strings.Contains(inst.args, "/var/lib/apt/lists") {
+				findings = append(findings, LintFinding{
+					Rule:     "DL3009",
+					Severity: LintInfo,
+					Line:     inst.line,
+					Message:  "Delete the apt-get lists after installing something, with rm -rf /var/lib/apt/lists/* in the same RUN.",
+				})
+			}
+		}
+	}
+	switch {
+	case len(final) == 0:
+	case lastUser == nil:
+		findings = append(findings, LintFinding{
+			Rule:     "AP001",
+			Severity: LintWarning,
+			Line:     final[0].line,
+			Message:  "No USER is set, so the task runs as root. Add a USER instruction for a non-root user.",
+		})
+	case isRootUser(lastUser.args):
+		findings = append(findings, LintFinding{
+			Rule:     "DL3002",
+			Severity: LintWarning,
+			Line:     lastUser.line,
+			Message:  "Last USER should not be root.",
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
+
+// lintImage checks the image of a FROM instruction.
+func lintImage(image string, stages map[string]bool) (LintFinding, bool) {
+	// Earlier stages, scratch and images built from ARGs can't be pinned here.
+	if image == "" || stages[strings.ToLower(image)] || image == "scratch" || strings.Contains(image, "$") {
+		return LintFinding{}, false
+	}
+	if strings.Contains(image, "@") {
+		return LintFinding{}, false
+	}
+	// A colon after the last slash is a tag; one before is a registry port.
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	switch {
+	case i < 0:
+		return LintFinding{
+			Rule:     "DL3006",
+			Severity: LintWarning,
+			Message:  fmt.Sprintf("Always tag the version of an image explicitly: %s has no tag.", image),
+		}, true
+	case name[i+1:] == "latest":
+		return LintFinding{
+			Rule:     "DL3007",
+			Severity: LintWarning,
+			Message:  fmt.Sprintf("Using latest is prone to errors if the image ever updates: pin %s to a version.", image),
+		}, true
+	}
+	return LintFinding{}, false
+}
+
+func isRootUser(user string) bool {
+	u := strings.SplitN(strings.TrimSpace(user), ":", 2)[0]
+	return u == "root" || u == "0"
+}
@@ -0,0 +1,103 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintDockerfile(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		dockerfile string
+		// findings are the rules found, as rule@line.
+		findings []LintFinding
+	}{
+		{
+			name: "clean",
+			dockerfile: `FROM node:16-alpine
+USER node
+`,
+		},
+		{
+			name:       "untagged image, no user",
+			dockerfile: "FROM ubuntu\nRUN echo hi\n",
+			findings: []LintFinding{
+				{Rule: "DL3006", Severity: LintWarning, Line: 1},
+				{Rule: "AP001", Severity: LintWarning, Line: 1},
+			},
+		},
+		{
+			name:       "latest",
+			dockerfile: "FROM registry.example.com:5000/team/app:latest\nUSER app\n",
+			findings: []LintFinding{
+				{Rule: "DL3007", Severity: LintWarning, Line: 1},
+			},
+		},
+		{
+			name:       "registry port is not a tag",
+			dockerfile: "FROM registry.example.com:5000/team/app\nUSER app\n",
+			findings: []LintFinding{
+				{Rule: "DL3006", Severity: LintWarning, Line: 1},
+			},
+		},
+		{
+			name:       "digests, scratch and args",
+			dockerfile: "ARG BASE=node:16\nFROM $BASE\nFROM scratch\nFROM node@sha256:abc\nUSER 1000\n",
+		},
+		{
+			name:       "root user",
+			dockerfile: "FROM node:16\nUSER node\nUSER root:root\n",
+			findings: []LintFinding{
+				{Rule: "DL3002", Severity: LintWarning, Line: 3},
+			},
+		},
+		{
+			name: "apt lists, across continuations and comments",
+			dockerfile: `FROM debian:11
+RUN apt-get update && \
+    # curl is needed to fetch the CLI
+    apt-get install -y curl
+RUN apt-get update && apt-get install -y git && rm -rf /var/lib/apt/lists/*
+USER nobody
+`,
+			findings: []LintFinding{
+				{Rule: "DL3009", Severity: LintInfo, Line: 2},
+			},
+		},
+		{
+			name: "only the final stage",
+			dockerfile: `FROM golang:1.17 AS build
+RUN apt-get install -y make
+FROM build
+FROM debian:11
+USER nobody
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			findings := LintDockerfile(test.dockerfile)
+			for i := range findings {
+				require.NotEmpty(t, findings[i].Message)
+				findings[i].Message = ""
+			}
+			require.Equal(t, test.findings, findings)
+		})
+	}
+}
+
+func TestLintThreshold(t *testing.T) {
+	require.Equal(t, LintWarning, lintConfig{}.threshold())
+	require.Equal(t, LintInfo, lintConfig{FailureThreshold: LintInfo}.threshold())
+	require.Equal(t, LintSeverity(""), lintConfig{FailureThreshold: "none"}.threshold())
+
+	cfg := lintConfig{
+		Ignored:  []string{"DL3007"},
+		Override: map[LintSeverity][]string{LintError: {"DL3006"}},
+	}
+	findings := cfg.apply([]LintFinding{
+		{Rule: "DL3006", Severity: LintWarning},
+		{Rule: "DL3007", Severity: LintWarning},
+	})
+	require.Equal(t, []LintFinding{{Rule: "DL3006", Severity: LintError}}, findings)
+}
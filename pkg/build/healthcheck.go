@@ -0,0 +1,172 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// imagePlatform is the platform task images are built for and run on.
+const imagePlatform = "linux/amd64"
+
+// healthcheckTimeout bounds how long an image's runtime may take to start.
+// Emulation is slow, but not this slow.
+const healthcheckTimeout = 2 * time.Minute
+
+// Emulated reports whether images built on this machine run under emulation,
+// as on Apple Silicon or Windows on ARM, where Docker emulates linux/amd64.
+func Emulated() bool {
+	return runtime.GOARCH == "arm64" && (runtime.GOOS == "darwin" || runtime.GOOS == "windows")
+}
+
+// runtimeChecks maps the runtimes that task images' entrypoints start to
+// arguments that start the runtime without loading the task.
+var runtimeChecks = map[string][]string{
+	"bash":    {"--version"},
+	"deno":    {"--version"},
+	"node":    {"--version"},
+	"python":  {"--version"},
+	"python3": {"--version"},
+	"sh":      {"-c", "true"},
+}
+
+// moduleChecks maps runtimes to arguments that load the task's module, and
+// with it the task's dependencies, without calling the task. The path of the
+// module follows the arguments.
+var moduleChecks = map[string]struct {
+	exts []string
+	args []string
+}{
+	"node": {
+		exts: []string{".js", ".cjs", ".mjs"},
+		args: []string{"-e", `import(require("url").pathToFileURL(process.argv[1]).href).catch(err => { console.error(err); process.exit(1) })`},
+	},
+	"python": {
+		exts: []string{".py"},
+		args: []string{"-c", `import os, runpy, sys; sys.path.insert(0, os.path.dirname(sys.argv[1])); runpy.run_path(sys.argv[1], run_name="airplane_healthcheck")`},
+	},
+}
+
+func init() {
+	moduleChecks["python3"] = moduleChecks["python"]
+}
+
+// taskModule returns the path in the image of the module of a task whose
+// options name its entrypoint, or "" if they don't. Builders copy the task's
+// root to /airplane.
+func taskModule(options map[string]interface{}) string {
+	entrypoint, _ := options["entrypoint"].(string)
+	if entrypoint == "" {
+		return ""
+	}
+	return path.Join("/airplane", filepath.ToSlash(entrypoint))
+}
+
+// runtimeCheck returns the command that starts the runtime of an image with
+// the given entrypoint, or false if the entrypoint doesn't start a known
+// runtime, such as when it is the task's own binary. If the runtime can load
+// module, the task's module in the image, the command loads it, so that
+// dependencies built for the wrong architecture fail to load.
+func runtimeCheck(entrypoint []string, module string) ([]string, bool) {
+	if len(entrypoint) == 0 {
+		return nil, false
+	}
+	bin := path.Base(entrypoint[0])
+	if mc, ok := moduleChecks[bin]; ok && module != "" {
+		for _, ext := range mc.exts {
+			if path.Ext(module) == ext {
+				check := append([]string{entrypoint[0]}, mc.args...)
+				return append(check, module), true
+			}
+		}
+	}
+	args, ok := runtimeChecks[bin]
+	if !ok {
+		return nil, false
+	}
+	return append([]string{entrypoint[0]}, args...), true
+}
+
+// healthcheck starts the runtime in image's entrypoint, such as node or
+// python, and loads module, the task's module in the image, without calling
+// the task. Runtimes that can't load the module, such as shells, or modules
+// they can't load directly, such as TypeScript, only print the runtime's
+// version.
+//
+// Images built under emulation can be based on, or copy in, binaries for the
+// host's architecture, which only fail once the task runs. Starting the
+// runtime and loading the task's dependencies catches those before the image
+// is pushed. Images whose entrypoint is the task's own binary are skipped,
+// since they can't be started without running the task.
+func healthcheck(ctx context.Context, image, module string) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return errors.New("docker is required to check built images")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthcheckTimeout)
+	defer cancel()
+
+	entrypoint, err := imageEntrypoint(ctx, image)
+	if err != nil {
+		return err
+	}
+	check, ok := runtimeCheck(entrypoint, module)
+	if !ok {
+		logger.Warning("Skipping the image healthcheck: its entrypoint %q doesn't start a known runtime, and can't be started without running the task.", strings.Join(entrypoint, " "))
+		return nil
+	}
+
+	args := []string{
+		"run", "--rm",
+		"--platform", imagePlatform,
+		"--network", "none",
+		"--entrypoint", check[0],
+		image,
+	}
+	args = append(args, check[1:]...)
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	logger.Debug("Running docker %s", strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.Errorf("image's %s runtime did not start within %s under %s emulation", check[0], healthcheckTimeout, imagePlatform)
+		}
+		var output string
+		if o := strings.TrimSpace(out.String()); o != "" {
+			output = "\n\n" + o
+		}
+		return errors.Errorf(
+			"image's %s runtime failed to start or load the task under %s emulation: %s\n"+
+				"This usually means the image contains a binary built for this machine's architecture, "+
+				"such as a base image pulled for this machine's platform or a dependency installed for it.%s",
+			check[0], imagePlatform, err, output,
+		)
+	}
+	return nil
+}
+
+// imageEntrypoint returns the entrypoint image was built with.
+func imageEntrypoint(ctx context.Context, image string) ([]string, error) {
+	args := []string{"image", "inspect", "--format", "{{json .Config.Entrypoint}}", image}
+	logger.Debug("Running docker %s", strings.Join(args, " "))
+	out, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "inspecting %s", image)
+	}
+	var entrypoint []string
+	if err := json.Unmarshal(bytes.TrimSpace(out), &entrypoint); err != nil {
+		return nil, errors.Wrapf(err, "reading the entrypoint of %s", image)
+	}
+	return entrypoint, nil
+}
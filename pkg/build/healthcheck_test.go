@@ -0,0 +1,96 @@
+package build
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeCheck(t *testing.T) {
+	const (
+		nodeLoad   = `import(require("url").pathToFileURL(process.argv[1]).href).catch(err => { console.error(err); process.exit(1) })`
+		pythonLoad = `import os, runpy, sys; sys.path.insert(0, os.path.dirname(sys.argv[1])); runpy.run_path(sys.argv[1], run_name="airplane_healthcheck")`
+	)
+	for _, test := range []struct {
+		entrypoint []string
+		module     string
+		check      []string
+	}{
+		{[]string{"node", "/airplane/.airplane/dist/universal-shim.js"}, "", []string{"node", "--version"}},
+		{[]string{"node", "/airplane/.airplane/dist/universal-shim.js"}, "/airplane/main.js", []string{"node", "-e", nodeLoad, "/airplane/main.js"}},
+		{[]string{"node", "/airplane/.airplane/dist/universal-shim.js"}, "/airplane/main.ts", []string{"node", "--version"}},
+		{[]string{"/usr/local/bin/python", "/airplane/.airplane/shim.py"}, "", []string{"/usr/local/bin/python", "--version"}},
+		{[]string{"/usr/local/bin/python", "/airplane/.airplane/shim.py"}, "/airplane/main.py", []string{"/usr/local/bin/python", "-c", pythonLoad, "/airplane/main.py"}},
+		{[]string{"/bin/sh", "-c", "./main"}, "/airplane/main.sh", []string{"/bin/sh", "-c", "true"}},
+		{[]string{"/airplane/main"}, "", nil},
+		{nil, "", nil},
+	} {
+		check, ok := runtimeCheck(test.entrypoint, test.module)
+		require.Equal(t, test.check != nil, ok, "%v", test.entrypoint)
+		require.Equal(t, test.check, check, "%v", test.entrypoint)
+	}
+}
+
+func TestTaskModule(t *testing.T) {
+	require.Equal(t, "/airplane/src/main.py", taskModule(map[string]interface{}{"entrypoint": "src/main.py"}))
+	require.Equal(t, "", taskModule(map[string]interface{}{"image": "ubuntu"}))
+}
+
+// TestModuleChecks runs the module checks with the runtimes installed on
+// this machine, as they run in images.
+func TestModuleChecks(t *testing.T) {
+	for _, test := range []struct {
+		runtime string
+		ext     string
+		// ok loads, and would fail the check if it ran the task. missing
+		// imports a dependency that isn't installed.
+		ok, missing string
+	}{
+		{
+			runtime: "node",
+			ext:     ".js",
+			ok:      "module.exports = async () => { process.exit(3) }\n",
+			missing: "require('airplane-missing-dependency')\nmodule.exports = async () => {}\n",
+		},
+		{
+			runtime: "node",
+			ext:     ".mjs",
+			ok:      "import path from 'path'\nexport default async () => { process.exit(3) }\n",
+			missing: "import 'airplane-missing-dependency'\nexport default async () => {}\n",
+		},
+		{
+			runtime: "python3",
+			ext:     ".py",
+			ok:      "import sibling\n\ndef main(params):\n    raise SystemExit(3)\n\nif __name__ == '__main__':\n    main({})\n",
+			missing: "import airplane_missing_dependency\n\ndef main(params):\n    pass\n",
+		},
+	} {
+		t.Run(test.runtime+test.ext, func(t *testing.T) {
+			bin, err := exec.LookPath(test.runtime)
+			if err != nil {
+				t.Skipf("%s is not installed", test.runtime)
+			}
+			dir := t.TempDir()
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sibling.py"), nil, 0644))
+			write := func(name, content string) string {
+				path := filepath.Join(dir, name+test.ext)
+				require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+				return path
+			}
+
+			check, ok := runtimeCheck([]string{bin}, write("ok", test.ok))
+			require.True(t, ok)
+			out, err := exec.Command(check[0], check[1:]...).CombinedOutput()
+			require.NoError(t, err, string(out))
+
+			check, ok = runtimeCheck([]string{bin}, write("missing", test.missing))
+			require.True(t, ok)
+			out, err = exec.Command(check[0], check[1:]...).CombinedOutput()
+			require.Error(t, err)
+			require.Regexp(t, `airplane.missing.dependency`, string(out))
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package build
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/airplanedev/lib/pkg/build/ignore"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/pkg/errors"
+)
+
+// ignoreFiles list paths to leave out of a task's build context, relative to
+// the task's root. Only the first of them that exists is read, so a
+// .dockerignore written for other images is only used when there's no
+// .airplaneignore.
+var ignoreFiles = []struct {
+	name  string
+	parse func(r io.Reader) (ignoreMatcher, error)
+}{
+	{name: ".airplaneignore", parse: parseAirplaneignore},
+	{name: ".dockerignore", parse: parseDockerignore},
+}
+
+// ignoreMatcher reports whether a path in a task's root, relative to it and
+// slash-separated, is ignored.
+type ignoreMatcher func(rel string, isDir bool) (bool, error)
+
+// includeFunc returns whether a file under root belongs in its build context.
+// It leaves out the files ignored by default, such as node_modules, and the
+// files matched by root's ignore file.
+func includeFunc(root string) (func(string, os.FileInfo) (bool, error), error) {
+	include, err := ignore.Func(root)
+	if err != nil {
+		return nil, err
+	}
+	ignored, err := readIgnoreFile(root)
+	if err != nil {
+		return nil, err
+	}
+	if ignored == nil {
+		return include, nil
+	}
+
+	return func(path string, info os.FileInfo) (bool, error) {
+		if include != nil {
+			if ok, err := include(path, info); err != nil || !ok {
+				return ok, err
+			}
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return false, err
+		}
+		if rel == "." {
+			return true, nil
+		}
+		ok, err := ignored(filepath.ToSlash(rel), info.IsDir())
+		return !ok, err
+	}, nil
+}
+
+// readIgnoreFile parses root's ignore file, or returns nil if it has none.
+func readIgnoreFile(root string) (ignoreMatcher, error) {
+	for _, f := range ignoreFiles {
+		r, err := os.Open(filepath.Join(root, f.name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "opening %s", f.name)
+		}
+		defer r.Close()
+
+		m, err := f.parse(r)
+		return m, errors.Wrapf(err, "reading %s", f.name)
+	}
+	return nil, nil
+}
+
+// parseAirplaneignore parses an .airplaneignore, which has the same patterns
+// as a .gitignore.
+func parseAirplaneignore(r io.Reader) (ignoreMatcher, error) {
+	var patterns []gitignore.Pattern
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	m := gitignore.NewMatcher(patterns)
+	return func(rel string, isDir bool) (bool, error) {
+		return m.Match(strings.Split(rel, "/"), isDir), nil
+	}, nil
+}
+
+// parseDockerignore parses a .dockerignore the way docker build does, so that
+// it leaves out the same files. Unlike in a .gitignore, patterns are relative
+// to the root rather than matching at any depth: "*.log" only matches logs in
+// the root, and "**/*.log" matches them anywhere.
+func parseDockerignore(r io.Reader) (ignoreMatcher, error) {
+	var patterns []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		pattern := s.Text()
+		if strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		// Paths are relative to the root even if they start with a /.
+		invert := pattern[0] == '!'
+		if invert {
+			pattern = strings.TrimSpace(pattern[1:])
+		}
+		if pattern != "" {
+			pattern = filepath.ToSlash(filepath.Clean(pattern))
+			if len(pattern) > 1 && pattern[0] == '/' {
+				pattern = pattern[1:]
+			}
+		}
+		if invert {
+			pattern = "!" + pattern
+		}
+		patterns = append(patterns, pattern)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	pm, err := fileutils.NewPatternMatcher(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return func(rel string, isDir bool) (bool, error) {
+		ok, err := pm.Matches(rel)
+		if err != nil {
+			return false, err
+		}
+		// An ignored directory is still walked if a ! pattern may include
+		// files under it again.
+		if ok && isDir && pm.Exclusions() {
+			return false, nil
+		}
+		return ok, nil
+	}, nil
+}
+
+// hasIgnoreFile reports whether root has an ignore file.
+func hasIgnoreFile(root string) bool {
+	for _, f := range ignoreFiles {
+		if _, err := os.Stat(filepath.Join(root, f.name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// copyContext copies the build context at root, less any ignored files, to a
+// new temporary directory that the caller must remove.
+//
+// Local builds copy the task's root into the image as is, so an ignore file
+// is honored by building from a filtered copy of it instead.
+func copyContext(root string) (string, error) {
+	include, err := includeFunc(root)
+	if err != nil {
+		return "", err
+	}
+	dir, err := ioutil.TempDir("", "airplane-build-")
+	if err != nil {
+		return "", errors.Wrap(err, "creating build context directory")
+	}
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if include != nil {
+			if ok, err := include(path, info); err != nil {
+				return err
+			} else if !ok {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dir, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(dst, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(target, dst)
+		case info.Mode().IsRegular():
+			return copyFile(path, dst, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", errors.Wrap(err, "copying build context")
+	}
+	return dir, nil
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
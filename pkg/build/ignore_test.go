@@ -0,0 +1,171 @@
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTree writes files, with their paths slash-separated and relative to a
+// new directory, and returns the directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+	return root
+}
+
+// included returns the files under root that include leaves in, sorted and
+// slash-separated.
+func included(t *testing.T, root string, include func(string, os.FileInfo) (bool, error)) []string {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		require.NoError(t, err)
+		if path == root {
+			return nil
+		}
+		if include != nil {
+			ok, err := include(path, info)
+			require.NoError(t, err)
+			if !ok {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if !info.IsDir() {
+			rel, err := filepath.Rel(root, path)
+			require.NoError(t, err)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(files)
+	return files
+}
+
+func TestIncludeFunc(t *testing.T) {
+	tree := map[string]string{
+		"main.py":            "",
+		"debug.log":          "",
+		"data/big.csv":       "",
+		"data/keep.csv":      "",
+		"lib/data/small.csv": "",
+		"lib/trace.log":      "",
+	}
+
+	for _, test := range []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "no ignore file",
+			want: []string{"data/big.csv", "data/keep.csv", "debug.log", "lib/data/small.csv", "lib/trace.log", "main.py"},
+		},
+		{
+			name: "airplaneignore matches at any depth",
+			files: map[string]string{".airplaneignore": `
+# Logs and data
+*.log
+data/
+!data/keep.csv
+`},
+			want: []string{".airplaneignore", "main.py"},
+		},
+		{
+			name: "dockerignore matches from the root",
+			files: map[string]string{".dockerignore": `
+# Logs and data
+*.log
+/data
+`},
+			want: []string{".dockerignore", "lib/data/small.csv", "lib/trace.log", "main.py"},
+		},
+		{
+			name: "dockerignore globstar",
+			files: map[string]string{".dockerignore": `
+**/*.log
+**/data
+`},
+			want: []string{".dockerignore", "main.py"},
+		},
+		{
+			name: "dockerignore exclusions",
+			files: map[string]string{".dockerignore": `
+data
+!data/keep.csv
+lib
+`},
+			want: []string{".dockerignore", "data/keep.csv", "debug.log", "main.py"},
+		},
+		{
+			name: "airplaneignore takes precedence",
+			files: map[string]string{
+				".airplaneignore": "*.log\n",
+				".dockerignore":   "data\n",
+			},
+			want: []string{".airplaneignore", ".dockerignore", "data/big.csv", "data/keep.csv", "lib/data/small.csv", "main.py"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			files := map[string]string{}
+			for name, content := range tree {
+				files[name] = content
+			}
+			for name, content := range test.files {
+				files[name] = content
+			}
+			root := writeTree(t, files)
+
+			include, err := includeFunc(root)
+			require.NoError(t, err)
+			require.Equal(t, test.want, included(t, root, include))
+		})
+	}
+
+	t.Run("invalid dockerignore", func(t *testing.T) {
+		root := writeTree(t, map[string]string{".dockerignore": "!\n"})
+		_, err := includeFunc(root)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "reading .dockerignore")
+	})
+}
+
+func TestCopyContext(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		".airplaneignore": "*.log\nfixtures/\n",
+		"main.py":         "print('hello')",
+		"debug.log":       "",
+		"lib/util.py":     "",
+		"fixtures/a.json": "",
+	})
+	require.NoError(t, os.Chmod(filepath.Join(root, "main.py"), 0755))
+	require.NoError(t, os.Symlink("lib/util.py", filepath.Join(root, "util.py")))
+
+	dir, err := copyContext(root)
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.Equal(t, []string{".airplaneignore", "lib/util.py", "main.py", "util.py"}, included(t, dir, nil))
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "main.py"))
+	require.NoError(t, err)
+	require.Equal(t, "print('hello')", string(buf))
+	info, err := os.Stat(filepath.Join(dir, "main.py"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	target, err := os.Readlink(filepath.Join(dir, "util.py"))
+	require.NoError(t, err)
+	require.Equal(t, "lib/util.py", target)
+}
@@ -0,0 +1,206 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/logger"
+	libBuild "github.com/airplanedev/lib/pkg/build"
+	"github.com/airplanedev/lib/pkg/utils/fsx"
+	"github.com/pkg/errors"
+)
+
+// InstallStep is the command that installs a task's dependencies, as the
+// builder would install them into the task's image.
+type InstallStep struct {
+	// Dir is the directory the command runs in: the directory of the task's
+	// root that the builder runs it in.
+	Dir string
+	// Manifest is the file the package manager was detected from.
+	Manifest string
+	Args     []string
+}
+
+// String returns the step's command as it would be typed.
+func (s InstallStep) String() string {
+	return strings.Join(s.Args, " ")
+}
+
+// Run runs the step, passing through its output with secrets masked.
+func (s InstallStep) Run(ctx context.Context) error {
+	if _, err := exec.LookPath(s.Args[0]); err != nil {
+		return errors.Errorf("%s is not installed", s.Args[0])
+	}
+	stdout, stderr := logger.NewRedactWriter(os.Stdout), logger.NewRedactWriter(os.Stderr)
+	defer stdout.Close()
+	defer stderr.Close()
+
+	cmd := exec.CommandContext(ctx, s.Args[0], s.Args[1:]...)
+	cmd.Dir = s.Dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return errors.Wrapf(cmd.Run(), "running %s", s)
+}
+
+// builderWorkdir is the directory the builder copies a task's root to.
+const builderWorkdir = "/airplane"
+
+// installCommands are the commands, in the Dockerfiles the builder generates,
+// that install a task's dependencies, with the manifests each installs from
+// in order of precedence.
+var installCommands = []struct {
+	prefix    []string
+	manifests []string
+}{
+	{prefix: []string{"pnpm", "install"}, manifests: []string{"pnpm-lock.yaml", "package.json"}},
+	{prefix: []string{"yarn", "install"}, manifests: []string{"yarn.lock", "package.json"}},
+	{prefix: []string{"yarn"}, manifests: []string{"yarn.lock", "package.json"}},
+	{prefix: []string{"npm", "ci"}, manifests: []string{"package-lock.json"}},
+	{prefix: []string{"npm", "install"}, manifests: []string{"package-lock.json", "package.json"}},
+	{prefix: []string{"npm", "i"}, manifests: []string{"package-lock.json", "package.json"}},
+	{prefix: []string{"poetry", "install"}, manifests: []string{"poetry.lock", "pyproject.toml"}},
+	{prefix: []string{"pip", "install"}},
+	{prefix: []string{"pip3", "install"}},
+	{prefix: []string{"go", "mod", "download"}, manifests: []string{"go.mod"}},
+}
+
+// DetectInstall returns how to install the dependencies of a task of the
+// given kind, rooted at root. It generates the Dockerfile the builder would
+// build the task with, and returns the step of it that installs dependencies,
+// to run in the same directory of the task locally.
+//
+// It returns nil if the task has no dependencies to install, either because
+// its kind isn't built or because its Dockerfile installs none.
+func DetectInstall(kind libBuild.TaskKind, options libBuild.KindOptions, root string) (*InstallStep, error) {
+	if ok, err := libBuild.NeedsBuilding(kind); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, nil
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.Wrap(err, "resolving task root")
+	}
+
+	dockerfile, err := libBuild.BuildDockerfile(libBuild.DockerfileConfig{
+		Builder: string(kind),
+		Root:    root,
+		Options: options,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "generating Dockerfile")
+	}
+	return installStep(dockerfile, root), nil
+}
+
+// installStep returns the first command of dockerfile that installs
+// dependencies, run from the directory of root that the command runs in
+// within the image, or nil if there is none.
+func installStep(dockerfile, root string) *InstallStep {
+	workdir := "/"
+	dockerfile = strings.ReplaceAll(dockerfile, "\\\n", " ")
+	for _, line := range strings.Split(dockerfile, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "WORKDIR":
+			workdir = containerPath(workdir, fields[1])
+		case "RUN":
+			dir := workdir
+			for _, args := range runCommands(strings.TrimSpace(line[len(fields[0]):])) {
+				if args[0] == "cd" && len(args) > 1 {
+					dir = containerPath(dir, args[1])
+					continue
+				}
+				if step := matchInstall(args, localPath(root, dir)); step != nil {
+					return step
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// runCommands splits the commands of a RUN instruction into their arguments.
+// Shell form commands are split on &&, || and ;, but quotes are not parsed.
+func runCommands(cmd string) [][]string {
+	if strings.HasPrefix(cmd, "[") {
+		var args []string
+		if err := json.Unmarshal([]byte(cmd), &args); err != nil || len(args) == 0 {
+			return nil
+		}
+		return [][]string{args}
+	}
+
+	var cmds [][]string
+	for _, part := range strings.FieldsFunc(strings.NewReplacer("&&", ";", "||", ";").Replace(cmd), func(r rune) bool { return r == ';' }) {
+		if args := strings.Fields(part); len(args) > 0 {
+			cmds = append(cmds, args)
+		}
+	}
+	return cmds
+}
+
+// matchInstall returns args as an InstallStep run in dir, if they install
+// dependencies. Global installs, such as of a compiler, and pip installs of
+// packages rather than of a requirements file don't.
+func matchInstall(args []string, dir string) *InstallStep {
+	for _, c := range installCommands {
+		if len(args) < len(c.prefix) || strings.Join(args[:len(c.prefix)], " ") != strings.Join(c.prefix, " ") {
+			continue
+		}
+		// Bare yarn installs, but yarn <command> runs something else.
+		if len(c.prefix) == 1 && len(args) > 1 && !strings.HasPrefix(args[1], "-") {
+			continue
+		}
+
+		manifests := c.manifests
+		for i, arg := range args {
+			if arg == "-g" || arg == "--global" {
+				return nil
+			}
+			if (arg == "-r" || arg == "--requirement") && i+1 < len(args) {
+				manifests = []string{args[i+1]}
+			}
+		}
+		if len(manifests) == 0 {
+			return nil
+		}
+
+		step := &InstallStep{Dir: dir, Args: args}
+		for _, m := range manifests {
+			step.Manifest = filepath.Join(dir, m)
+			if fsx.Exists(step.Manifest) {
+				break
+			}
+		}
+		return step
+	}
+	return nil
+}
+
+// containerPath resolves to against dir, in the image.
+func containerPath(dir, to string) string {
+	if path.IsAbs(to) {
+		return path.Clean(to)
+	}
+	return path.Join(dir, to)
+}
+
+// localPath returns the directory of root that dir, in the image, was copied
+// from. Directories outside of the task's root resolve to root.
+func localPath(root, dir string) string {
+	rel := strings.TrimPrefix(dir, builderWorkdir)
+	if rel == dir || (rel != "" && !strings.HasPrefix(rel, "/")) {
+		return root
+	}
+	return filepath.Join(root, filepath.FromSlash(rel))
+}
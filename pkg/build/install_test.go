@@ -0,0 +1,128 @@
+package build
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallStep(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "api"), 0755))
+	for _, name := range []string{"package.json", "yarn.lock", "api/requirements.txt"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(root, name), nil, 0644))
+	}
+
+	for _, test := range []struct {
+		name       string
+		dockerfile string
+		step       *InstallStep
+	}{
+		{
+			name: "yarn",
+			dockerfile: heredoc.Doc(`
+				FROM node:16-buster
+				WORKDIR /airplane
+				RUN npm install -g typescript@4.2
+				COPY package.json yarn.lock /airplane/
+				RUN yarn install --non-interactive --frozen-lockfile
+				COPY . /airplane
+			`),
+			step: &InstallStep{
+				Dir:      root,
+				Manifest: filepath.Join(root, "yarn.lock"),
+				Args:     []string{"yarn", "install", "--non-interactive", "--frozen-lockfile"},
+			},
+		},
+		{
+			name: "npm without a lockfile",
+			dockerfile: heredoc.Doc(`
+				FROM node:16-buster
+				WORKDIR /airplane
+				RUN npm i -g esbuild && npm install --production
+			`),
+			step: &InstallStep{
+				Dir:      root,
+				Manifest: filepath.Join(root, "package.json"),
+				Args:     []string{"npm", "install", "--production"},
+			},
+		},
+		{
+			name: "pip in a subdirectory",
+			dockerfile: heredoc.Doc(`
+				FROM python:3.9-buster
+				WORKDIR /airplane
+				RUN pip install --upgrade pip
+				WORKDIR api
+				COPY api/requirements.txt .
+				RUN pip install \
+					--no-cache-dir \
+					-r requirements.txt
+			`),
+			step: &InstallStep{
+				Dir:      filepath.Join(root, "api"),
+				Manifest: filepath.Join(root, "api", "requirements.txt"),
+				Args:     []string{"pip", "install", "--no-cache-dir", "-r", "requirements.txt"},
+			},
+		},
+		{
+			name: "cd",
+			dockerfile: heredoc.Doc(`
+				FROM golang:1.17
+				WORKDIR /
+				RUN cd /airplane/api; go mod download
+			`),
+			step: &InstallStep{
+				Dir:      filepath.Join(root, "api"),
+				Manifest: filepath.Join(root, "api", "go.mod"),
+				Args:     []string{"go", "mod", "download"},
+			},
+		},
+		{
+			name: "exec form",
+			dockerfile: heredoc.Doc(`
+				FROM python:3.9-buster
+				WORKDIR /airplane
+				RUN ["poetry", "install", "--no-dev"]
+			`),
+			step: &InstallStep{
+				Dir:      root,
+				Manifest: filepath.Join(root, "pyproject.toml"),
+				Args:     []string{"poetry", "install", "--no-dev"},
+			},
+		},
+		{
+			name: "outside the task's root",
+			dockerfile: heredoc.Doc(`
+				FROM node:16-buster
+				WORKDIR /opt/shim
+				RUN yarn
+			`),
+			step: &InstallStep{
+				Dir:      root,
+				Manifest: filepath.Join(root, "yarn.lock"),
+				Args:     []string{"yarn"},
+			},
+		},
+		{
+			name: "no dependencies",
+			dockerfile: heredoc.Doc(`
+				FROM node:16-buster
+				WORKDIR /airplane
+				RUN yarn global add typescript && yarn build
+				RUN pip3 install airplanesdk
+			`),
+		},
+		{
+			name: "empty",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.step, installStep(test.dockerfile, root))
+		})
+	}
+}
@@ -2,6 +2,8 @@ package build
 
 import (
 	"context"
+	"os"
+	"runtime"
 
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/configs"
@@ -10,7 +12,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func (d *Deployer) local(ctx context.Context, req Request) (*build.Response, error) {
+func (d *Deployer) local(ctx context.Context, req Request, tag string) (*Response, error) {
 	registry, err := d.getRegistryToken(ctx, req.Client)
 
 	env, err := req.Def.GetEnv()
@@ -31,8 +33,16 @@ func (d *Deployer) local(ctx context.Context, req Request) (*build.Response, err
 		options["shim"] = "true"
 	}
 
+	root := req.Root
+	if hasIgnoreFile(root) {
+		if root, err = copyContext(root); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(root)
+	}
+
 	b, err := build.New(build.LocalConfig{
-		Root:    req.Root,
+		Root:    root,
 		Builder: string(kind),
 		Options: options,
 		Auth: &build.RegistryAuth{
@@ -46,23 +56,45 @@ func (d *Deployer) local(ctx context.Context, req Request) (*build.Response, err
 	}
 	defer b.Close()
 
-	logger.Log("Building...")
-	resp, err := b.Build(ctx, req.TaskID, "latest")
+	if !emitf(ctx, PhaseBuilding, "Building...") {
+		logger.Log(logPrefix(ctx) + "Building...")
+	}
+	resp, err := b.Build(ctx, req.TaskID, tag)
 	if err != nil {
 		return nil, errors.Wrap(err, "build")
 	}
 
-	logger.Log("Pushing...")
+	if req.Healthcheck {
+		if Emulated() {
+			if !emitf(ctx, PhaseBuilding, "Checking the image starts under emulation...") {
+				logger.Log(logPrefix(ctx) + "Checking the image starts under emulation...")
+			}
+			if err := healthcheck(ctx, resp.ImageURL, taskModule(options)); err != nil {
+				return nil, err
+			}
+		} else {
+			logger.Debug("Skipping the image healthcheck, since images aren't emulated on %s/%s", runtime.GOOS, runtime.GOARCH)
+		}
+	}
+
+	if !emitf(ctx, PhasePushing, "Pushing...") {
+		logger.Log(logPrefix(ctx) + "Pushing...")
+	}
 	if err := b.Push(ctx, resp.ImageURL); err != nil {
 		return nil, errors.Wrap(err, "push")
 	}
 
-	return resp, nil
+	emit(ctx, Event{Phase: PhaseDone, Percent: 100, Message: resp.ImageURL})
+
+	return &Response{
+		ImageURL: resp.ImageURL,
+		BuildID:  resp.BuildID,
+	}, nil
 }
 
 // Retrieves a build env from def - looks for env vars starting with BUILD_ and either uses the
 // string literal or looks up the config value.
-func getBuildEnv(ctx context.Context, client *api.Client, taskEnv api.TaskEnv) (map[string]string, error) {
+func getBuildEnv(ctx context.Context, client api.APIClient, taskEnv api.TaskEnv) (map[string]string, error) {
 	buildEnv := make(map[string]string)
 	for k, v := range taskEnv {
 		if v.Value != nil {
@@ -0,0 +1,86 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// Phase identifies a step of a build.
+type Phase string
+
+// All Phase types.
+const (
+	PhaseAuditing       Phase = "auditing"
+	PhaseLinting        Phase = "linting"
+	PhaseAuthenticating Phase = "authenticating"
+	PhasePackaging      Phase = "packaging"
+	PhaseUploading      Phase = "uploading"
+	PhaseBuilding       Phase = "building"
+	PhasePushing        Phase = "pushing"
+	PhaseDone           Phase = "done"
+)
+
+// Event reports the progress of a build. Its message is plain text, without
+// terminal colours.
+type Event struct {
+	TaskSlug string `json:"taskSlug"`
+	Phase    Phase  `json:"phase"`
+	// Percent is how far along the current phase is, from 0 to 100,
+	// or -1 if unknown.
+	Percent int    `json:"percent"`
+	Message string `json:"message,omitempty"`
+}
+
+const progressContextKey contextKey = "progress"
+
+// emit sends ev to the progress channel of the build running in ctx. It
+// reports false if the build has no progress channel, in which case callers
+// should fall back to logging.
+//
+// Sending blocks until the event is received or ctx is done, so consumers
+// must keep reading until the build returns.
+func emit(ctx context.Context, ev Event) bool {
+	ch, ok := ctx.Value(progressContextKey).(chan<- Event)
+	if !ok || ch == nil {
+		return false
+	}
+	if slug, ok := ctx.Value(taskSlugContextKey).(string); ok {
+		ev.TaskSlug = slug
+	}
+	select {
+	case ch <- ev:
+	case <-ctx.Done():
+	}
+	return true
+}
+
+// emitf is like emit for an event of unknown completion.
+func emitf(ctx context.Context, phase Phase, msg string, args ...interface{}) bool {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return emit(ctx, Event{Phase: phase, Percent: -1, Message: msg})
+}
+
+// progressReader emits upload progress as it is read, in steps of 10%.
+type progressReader struct {
+	ctx   context.Context
+	r     io.Reader
+	size  int64
+	read  int64
+	last  int
+	phase Phase
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if p.size > 0 {
+		if pct := int(p.read * 100 / p.size); pct >= p.last+10 || (pct == 100 && p.last != 100) {
+			p.last = pct
+			emit(p.ctx, Event{Phase: p.phase, Percent: pct})
+		}
+	}
+	return n, err
+}
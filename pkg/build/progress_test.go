@@ -0,0 +1,30 @@
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLogProgress(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	events := make(chan Event, 1)
+	ctx := context.WithValue(context.Background(), progressContextKey, (chan<- Event)(events))
+	ctx = context.WithValue(ctx, taskSlugContextKey, "hello")
+	loader := logger.NewLoader(logger.LoaderOpts{HideLoader: true})
+
+	buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, bold(logger.Red), "Build failed: %s", "exit 1")
+	require.Equal(t, Event{
+		TaskSlug: "hello",
+		Phase:    PhaseBuilding,
+		Percent:  -1,
+		Message:  "Build failed: exit 1",
+	}, <-events)
+}
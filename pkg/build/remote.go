@@ -3,8 +3,6 @@ package build
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,14 +10,12 @@ import (
 	"sync"
 	"time"
 
-	"github.com/airplanedev/archiver"
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/airplanedev/cli/pkg/taskdir/definitions"
 	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/airplanedev/cli/pkg/workspace"
 	libBuild "github.com/airplanedev/lib/pkg/build"
-	"github.com/airplanedev/lib/pkg/build/ignore"
-	"github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/singleflight"
 )
@@ -36,49 +32,65 @@ type Deployer struct {
 
 	uploadArchiveSingleFlightGroup singleflight.Group
 	uploadedArchives               map[string]string
+
+	buildSingleFlightGroup singleflight.Group
+	builtImagesMutex       sync.Mutex
+	builtImages            map[string]*Response
 }
 
 func NewDeployer() *Deployer {
 	return &Deployer{
 		uploadedArchives: make(map[string]string),
+		builtImages:      make(map[string]*Response),
 	}
 }
 
-func (d *Deployer) remote(ctx context.Context, req Request) (*libBuild.Response, error) {
-	ctx = context.WithValue(ctx, taskSlugContextKey, req.Def.GetSlug())
+func (d *Deployer) remote(ctx context.Context, req Request, tag string) (*Response, error) {
 	if err := confirmBuildRoot(req.Root); err != nil {
 		return nil, err
 	}
-	loader := logger.NewLoader(logger.LoaderOpts{HideLoader: logger.EnableDebug})
+	loader := logger.NewLoader(logger.LoaderOpts{HideLoader: logger.EnableDebug || req.Progress != nil})
 	defer loader.Stop()
 	loader.Start()
 
 	// Before performing a remote build, we must first update kind/kindOptions
 	// since the remote build relies on pulling those from the tasks table (for now).
-	if err := updateKindAndOptions(ctx, req.Client, req.Def, req.Shim); err != nil {
+	// Build-only runs send them with the build instead, leaving the task as is.
+	var kind libBuild.TaskKind
+	var kindOptions libBuild.KindOptions
+	if req.BuildOnly {
+		var err error
+		if kind, kindOptions, err = buildKindAndOptions(req.Def, req.Shim); err != nil {
+			return nil, err
+		}
+	} else if err := updateKindAndOptions(ctx, req.Client, req.Def, req.Shim); err != nil {
 		return nil, err
 	}
 
-	buildLog(ctx, api.LogLevelInfo, loader, logger.Gray("Authenticating with Airplane..."))
+	buildLog(ctx, PhaseAuthenticating, api.LogLevelInfo, loader, logger.Gray, "Authenticating with Airplane...")
 	registry, err := d.getRegistryToken(ctx, req.Client)
 	if err != nil {
 		return nil, err
 	}
 
-	tmpdir, err := ioutil.TempDir("", "airplane-builds-")
+	tmpdir, err := workspace.TempDir("build-")
 	if err != nil {
 		return nil, errors.Wrap(err, "creating temporary directory for remote build")
 	}
 	defer os.RemoveAll(tmpdir)
 
-	archivePath := path.Join(tmpdir, "archive.tar.gz")
-	buildLog(ctx, api.LogLevelInfo, loader, logger.Gray("Packaging and uploading %s to build the task...", req.Root))
-	if err := archiveTaskDir(req.Root, archivePath); err != nil {
+	compression, err := ParseCompression(string(req.Compression), req.CompressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	archivePath := path.Join(tmpdir, "archive"+compression.ext())
+	buildLog(ctx, PhasePackaging, api.LogLevelInfo, loader, logger.Gray, "Packaging and uploading %s to build the task...", req.Root)
+	if err := archiveTaskDir(req.Root, archivePath, compression, req.CompressionLevel); err != nil {
 		return nil, err
 	}
 
 	uploadIDRes, err, _ := d.uploadArchiveSingleFlightGroup.Do(req.Root, func() (interface{}, error) {
-		return d.uploadArchive(ctx, req.Client, archivePath, req.Root, loader)
+		return d.uploadArchive(ctx, req.Client, archivePath, req.Root, compression, loader)
 	})
 
 	if err != nil {
@@ -91,13 +103,18 @@ func (d *Deployer) remote(ctx context.Context, req Request) (*libBuild.Response,
 		SourceUploadID: uploadID,
 		Env:            req.TaskEnv,
 		GitMeta:        req.GitMeta,
+		Builder:        req.Builder,
+		ContentTag:     tag,
+		Kind:           kind,
+		KindOptions:    kindOptions,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "creating build")
 	}
 	logger.Debug("Created build with id=%s", build.Build.ID)
 
-	if err := waitForBuild(ctx, loader, req.Client, build.Build.ID); err != nil {
+	finished, err := waitForBuild(ctx, loader, req.Client, build.Build.ID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -107,13 +124,16 @@ func (d *Deployer) remote(ctx context.Context, req Request) (*libBuild.Response,
 		build.Build.ID,
 	)
 
-	return &libBuild.Response{
-		ImageURL: imageURL,
-		BuildID:  build.Build.ID,
+	emit(ctx, Event{Phase: PhaseDone, Percent: 100, Message: imageURL})
+
+	return &Response{
+		ImageURL:    imageURL,
+		BuildID:     build.Build.ID,
+		ImageDigest: finished.ImageDigest,
 	}, nil
 }
 
-func (d *Deployer) getRegistryToken(ctx context.Context, client *api.Client) (registryToken api.RegistryTokenResponse, err error) {
+func (d *Deployer) getRegistryToken(ctx context.Context, client api.APIClient) (registryToken api.RegistryTokenResponse, err error) {
 	d.getRegistryTokenMutex.Lock()
 	defer d.getRegistryTokenMutex.Unlock()
 	if d.cachedRegistryToken != nil {
@@ -128,15 +148,12 @@ func (d *Deployer) getRegistryToken(ctx context.Context, client *api.Client) (re
 	return registryToken, nil
 }
 
-func updateKindAndOptions(ctx context.Context, client *api.Client, def definitions.DefinitionInterface, shim bool) error {
-	task, err := client.GetTask(ctx, def.GetSlug())
-	if err != nil {
-		return err
-	}
-
+// buildKindAndOptions returns the kind and options the remote builder builds
+// def with.
+func buildKindAndOptions(def definitions.DefinitionInterface, shim bool) (libBuild.TaskKind, libBuild.KindOptions, error) {
 	kind, kindOptions, err := def.GetKindAndOptions()
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 
 	// Conditionally instruct the remote builder API to perform a shim-based build.
@@ -149,6 +166,19 @@ func updateKindAndOptions(ctx context.Context, client *api.Client, def definitio
 	if ep, ok := kindOptions["entrypoint"].(string); ok {
 		kindOptions["entrypoint"] = filepath.ToSlash(ep)
 	}
+	return kind, kindOptions, nil
+}
+
+func updateKindAndOptions(ctx context.Context, client api.APIClient, def definitions.DefinitionInterface, shim bool) error {
+	task, err := client.GetTask(ctx, def.GetSlug())
+	if err != nil {
+		return err
+	}
+
+	kind, kindOptions, err := buildKindAndOptions(def, shim)
+	if err != nil {
+		return err
+	}
 
 	_, err = client.UpdateTask(ctx, api.UpdateTaskRequest{
 		Kind:        kind,
@@ -178,35 +208,7 @@ func updateKindAndOptions(ctx context.Context, client *api.Client, def definitio
 	return nil
 }
 
-func archiveTaskDir(root string, archivePath string) error {
-	// mholt/archiver takes a list of "sources" (files/directories) that will
-	// be included in the root of the archive. In our case, we want the root of
-	// the archive to be the contents of the task directory, rather than the
-	// task directory itself.
-	var sources []string
-	if files, err := ioutil.ReadDir(root); err != nil {
-		return errors.Wrap(err, "inspecting files in task root")
-	} else {
-		for _, f := range files {
-			sources = append(sources, path.Join(root, f.Name()))
-		}
-	}
-
-	var err error
-	arch := archiver.NewTarGz()
-	arch.Tar.IncludeFunc, err = ignore.Func(root)
-	if err != nil {
-		return err
-	}
-
-	if err := arch.Archive(sources, archivePath); err != nil {
-		return errors.Wrap(err, "building archive")
-	}
-
-	return nil
-}
-
-func (d *Deployer) uploadArchive(ctx context.Context, client *api.Client, archivePath, rootPath string, loader logger.Loader) (string, error) {
+func (d *Deployer) uploadArchive(ctx context.Context, client api.APIClient, archivePath, rootPath string, compression Compression, loader logger.Loader) (string, error) {
 	// Check if anyone has uploaded an archive for this path.
 	uid, ok := d.uploadedArchives[rootPath]
 	if ok {
@@ -228,28 +230,28 @@ func (d *Deployer) uploadArchive(ctx context.Context, client *api.Client, archiv
 	}
 	sizeBytes := int(info.Size())
 
-	buildLog(ctx, api.LogLevelInfo, loader, logger.Gray("Uploading %s build archive...",
-		humanize.Bytes(uint64(sizeBytes)),
-	))
+	buildLog(ctx, PhaseUploading, api.LogLevelInfo, loader, logger.Gray, "Uploading %s build archive (%s)...",
+		utils.FormatBytes(int64(sizeBytes)),
+		compression,
+	)
 
 	upload, err := client.CreateBuildUpload(ctx, api.CreateBuildUploadRequest{
-		SizeBytes: sizeBytes,
+		SizeBytes:   sizeBytes,
+		Compression: string(compression),
+		Resumable:   true,
 	})
 	if err != nil {
 		return "", errors.Wrap(err, "creating upload")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "PUT", upload.WriteOnlyURL, archive)
-	if err != nil {
-		return "", errors.Wrap(err, "creating GCS upload request")
+	if upload.Resumable {
+		err = uploadResumable(ctx, loader, upload.WriteOnlyURL, archive, info.Size())
+	} else {
+		err = uploadSingle(ctx, loader, upload.WriteOnlyURL, archive, info.Size())
 	}
-	req.Header.Add("X-Goog-Content-Length-Range", fmt.Sprintf("0,%d", sizeBytes))
-
-	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", errors.Wrap(err, "uploading to GCS")
+		return "", err
 	}
-	defer resp.Body.Close()
 
 	logger.Debug("Upload complete: %s", upload.Upload.URL)
 	uploadID := upload.Upload.ID
@@ -260,71 +262,112 @@ func (d *Deployer) uploadArchive(ctx context.Context, client *api.Client, archiv
 	return uploadID, nil
 }
 
-func waitForBuild(ctx context.Context, loader logger.Loader, client *api.Client, buildID string) error {
-	loader.Start()
-	buildLog(ctx, api.LogLevelInfo, loader, logger.Gray("Waiting for builder..."))
+var (
+	// buildReconnectTimeout is how long the API may be unreachable while
+	// waiting for a build before the deploy fails. The build carries on
+	// remotely meanwhile, so it's worth waiting through network blips.
+	buildReconnectTimeout = 5 * time.Minute
 
-	t := time.NewTicker(time.Second)
+	// buildHeartbeatInterval is how often a build that logs nothing reports
+	// that it's still running, so that CI jobs which stop after a period
+	// without output don't stop long builds.
+	buildHeartbeatInterval = time.Minute
+)
 
-	var prevToken string
+func waitForBuild(ctx context.Context, loader logger.Loader, client api.APIClient, buildID string) (api.Build, error) {
+	loader.Start()
+	buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, logger.Gray, "Waiting for builder...")
+
+	start := time.Now()
+	lastOutput := start
+	var disconnected time.Time
+	w := api.NewBuildWatcher(ctx, client, buildID)
 	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-t.C:
-			r, err := client.GetBuildLogs(ctx, buildID, prevToken)
-			if err != nil {
-				return errors.Wrap(err, "getting build logs")
+		state := w.Next()
+		if err := state.Err(); err != nil {
+			if ctx.Err() != nil || !api.IsUnavailable(err) {
+				return api.Build{}, err
 			}
-
-			if len(r.Logs) > 0 {
-				prevToken = r.PrevPageToken
+			// The watcher resumes from the last logs it fetched, so keep
+			// calling it until the API is reachable again.
+			if disconnected.IsZero() {
+				disconnected = time.Now()
+				buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, logger.Yellow, "Lost connection to the API (%v), reconnecting...", err)
+			} else if time.Since(disconnected) > buildReconnectTimeout {
+				return api.Build{}, errors.Wrapf(err, "unable to reach the API for %s", buildReconnectTimeout)
 			}
+			continue
+		}
+		if !disconnected.IsZero() {
+			disconnected = time.Time{}
+			buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, logger.Gray, "Reconnected.")
+		}
 
-			api.SortLogs(r.Logs)
-			for _, l := range r.Logs {
-				text := l.Text
-				if strings.HasPrefix(l.Text, "[builder] ") {
-					text = logger.Gray(strings.TrimPrefix(text, "[builder] "))
-				}
+		if len(state.Logs) > 0 {
+			lastOutput = time.Now()
+		} else if time.Since(lastOutput) >= buildHeartbeatInterval {
+			lastOutput = time.Now()
+			buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, logger.Gray, "Still building after %s...", time.Since(start).Round(time.Second))
+		}
 
-				buildLog(ctx, l.Level, loader, text)
+		for _, l := range state.Logs {
+			text, style := l.Text, logStyle(nil)
+			if strings.HasPrefix(l.Text, "[builder] ") {
+				text, style = strings.TrimPrefix(text, "[builder] "), logger.Gray
 			}
 
-			b, err := client.GetBuild(ctx, buildID)
-			if err != nil {
-				return errors.Wrap(err, "getting build")
-			}
+			buildLog(ctx, PhaseBuilding, l.Level, loader, style, "%s", text)
+		}
 
-			if b.Build.Status.Stopped() {
-				loader.Stop()
-				switch b.Build.Status {
-				case api.BuildCancelled:
-					buildLog(ctx, api.LogLevelInfo, loader, logger.Bold(logger.Yellow("cancelled")))
-					return errors.New("Build cancelled")
-				case api.BuildFailed:
-					buildLog(ctx, api.LogLevelInfo, loader, logger.Bold(logger.Red("failed")))
-					return errors.New("Build failed")
-				case api.BuildSucceeded:
-					buildLog(ctx, api.LogLevelInfo, loader, logger.Bold(logger.Green("succeeded")))
-				}
-
-				return nil
+		if state.Stopped() {
+			loader.Stop()
+			switch state.Build.Status {
+			case api.BuildCancelled:
+				buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, bold(logger.Yellow), "cancelled")
+				return api.Build{}, errors.New("Build cancelled")
+			case api.BuildFailed:
+				buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, bold(logger.Red), "failed")
+				return api.Build{}, errors.New("Build failed")
+			case api.BuildSucceeded:
+				buildLog(ctx, PhaseBuilding, api.LogLevelInfo, loader, bold(logger.Green), "succeeded")
 			}
-			loader.Start()
+
+			return state.Build, nil
 		}
+		loader.Start()
 	}
 }
 
-func buildLog(ctx context.Context, level api.LogLevel, loader logger.Loader, msg string, args ...interface{}) {
-	taskSlug := ctx.Value(taskSlugContextKey).(string)
+// logStyle colours a build message for the terminal, such as logger.Gray.
+type logStyle func(format string, args ...interface{}) string
+
+// bold returns style in bold.
+func bold(style logStyle) logStyle {
+	return func(format string, args ...interface{}) string {
+		return logger.Bold(style(format, args...))
+	}
+}
+
+// buildLog logs a message from a build in style, or emits it as a progress
+// event if the build reports progress. Events hold the plain message, since
+// they are rendered by their consumer.
+func buildLog(ctx context.Context, phase Phase, level api.LogLevel, loader logger.Loader, style logStyle, msg string, args ...interface{}) {
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	if level != api.LogLevelDebug && emit(ctx, Event{Phase: phase, Percent: -1, Message: msg}) {
+		return
+	}
+	if style != nil {
+		msg = style("%s", msg)
+	}
 	loaderActive := loader.IsActive()
 	loader.Stop()
-	buildMsg := fmt.Sprintf("[%s %s] ", logger.Yellow("build"), taskSlug)
+	buildMsg := logPrefix(ctx)
 	if level == api.LogLevelDebug {
-		logger.Log(buildMsg+"["+logger.Blue("debug")+"] "+msg, args...)
+		logger.Log("%s", buildMsg+"["+logger.Blue("debug")+"] "+msg)
 	} else {
-		logger.Log(buildMsg+msg, args...)
+		logger.Log("%s", buildMsg+msg)
 	}
 	if loaderActive {
 		loader.Start()
@@ -346,3 +389,10 @@ func confirmBuildRoot(root string) error {
 	}
 	return nil
 }
+
+// logPrefix returns the prefix of the build's log lines, which tells apart the
+// logs of builds that run concurrently.
+func logPrefix(ctx context.Context) string {
+	taskSlug, _ := ctx.Value(taskSlugContextKey).(string)
+	return fmt.Sprintf("[%s %s] ", logger.Yellow("build"), taskSlug)
+}
@@ -0,0 +1,159 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is how many builds a Scheduler runs at once by default.
+const DefaultConcurrency = 4
+
+// Scheduler runs builds concurrently, at most a fixed number at a time.
+// Builds start in the order they were scheduled.
+//
+// Each build is named, typically by its task's slug, and its logs are
+// prefixed with that name as usual. Unlike an errgroup, one build failing
+// doesn't cancel the others: every failure is collected and returned by Wait.
+type Scheduler struct {
+	concurrency int
+	wg          sync.WaitGroup
+
+	mu      sync.Mutex
+	running int
+	queue   []*scheduled
+	total   int
+	errors  map[string]error
+}
+
+// scheduled is a build waiting for a slot.
+type scheduled struct {
+	// start is closed once the build has a slot.
+	start   chan struct{}
+	started bool
+}
+
+// NewScheduler returns a scheduler that runs up to concurrency builds at
+// once. A concurrency below 1 runs them one at a time.
+func NewScheduler(concurrency int) *Scheduler {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Scheduler{
+		concurrency: concurrency,
+		errors:      make(map[string]error),
+	}
+}
+
+// Go schedules fn to run as the build named name, once a slot is free.
+//
+// If ctx is done before a slot frees up, fn is not run and the build fails
+// with ctx's error.
+func (s *Scheduler) Go(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	b := &scheduled{start: make(chan struct{})}
+	s.mu.Lock()
+	s.total++
+	s.queue = append(s.queue, b)
+	s.dispatch()
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		select {
+		case <-b.start:
+		case <-ctx.Done():
+			s.mu.Lock()
+			started := b.started
+			if !started {
+				s.dequeue(b)
+			}
+			s.mu.Unlock()
+			if !started {
+				s.fail(name, ctx.Err())
+				return
+			}
+		}
+		defer s.release()
+
+		if err := fn(ctx); err != nil {
+			s.fail(name, err)
+		}
+	}()
+}
+
+// dispatch starts queued builds, in order, while slots are free. s.mu must
+// be held.
+func (s *Scheduler) dispatch() {
+	for s.running < s.concurrency && len(s.queue) > 0 {
+		b := s.queue[0]
+		s.queue = s.queue[1:]
+		b.started = true
+		s.running++
+		close(b.start)
+	}
+}
+
+// dequeue removes b from the queue. s.mu must be held.
+func (s *Scheduler) dequeue(b *scheduled) {
+	for i, q := range s.queue {
+		if q == b {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// release frees the slot of a finished build.
+func (s *Scheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	s.dispatch()
+}
+
+func (s *Scheduler) fail(name string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[name] = err
+}
+
+// Wait waits for every scheduled build to finish. If any failed, it returns
+// a *SchedulerError with all of their failures.
+func (s *Scheduler) Wait() error {
+	s.wg.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.errors) == 0 {
+		return nil
+	}
+	errs := make(map[string]error, len(s.errors))
+	for name, err := range s.errors {
+		errs[name] = err
+	}
+	return &SchedulerError{Total: s.total, Errors: errs}
+}
+
+// SchedulerError is returned by Scheduler.Wait when builds failed.
+type SchedulerError struct {
+	// Total is how many builds were scheduled.
+	Total int
+	// Errors holds the failure of each build that failed, by name.
+	Errors map[string]error
+}
+
+// Names returns the names of the failed builds, sorted.
+func (e *SchedulerError) Names() []string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (e *SchedulerError) Error() string {
+	return fmt.Sprintf("%d of %d builds failed: %s", len(e.Errors), e.Total, strings.Join(e.Names(), ", "))
+}
@@ -0,0 +1,127 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("concurrency", func(t *testing.T) {
+		s := NewScheduler(3)
+		var mu sync.Mutex
+		running, max, ran := 0, 0, 0
+		for i := 0; i < 10; i++ {
+			s.Go(ctx, fmt.Sprint(i), func(ctx context.Context) error {
+				mu.Lock()
+				running++
+				if running > max {
+					max = running
+				}
+				mu.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				ran++
+				mu.Unlock()
+				return nil
+			})
+		}
+		require.NoError(t, s.Wait())
+		require.Equal(t, 10, ran)
+		require.Equal(t, 3, max)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		// A concurrency below 1 runs builds one at a time.
+		s := NewScheduler(0)
+		// Hold the only slot until every build is scheduled.
+		hold := make(chan struct{})
+		s.Go(ctx, "first", func(ctx context.Context) error {
+			<-hold
+			return nil
+		})
+
+		var mu sync.Mutex
+		var order []string
+		var want []string
+		for i := 0; i < 10; i++ {
+			name := fmt.Sprint(i)
+			want = append(want, name)
+			s.Go(ctx, name, func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				order = append(order, name)
+				return nil
+			})
+		}
+		close(hold)
+		require.NoError(t, s.Wait())
+		require.Equal(t, want, order)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		s := NewScheduler(2)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			name := name
+			s.Go(ctx, name, func(ctx context.Context) error {
+				if name == "b" || name == "d" {
+					return errors.Errorf("%s failed", name)
+				}
+				return nil
+			})
+		}
+		err := s.Wait()
+		require.Error(t, err)
+		var serr *SchedulerError
+		require.ErrorAs(t, err, &serr)
+		require.Equal(t, 4, serr.Total)
+		require.Equal(t, []string{"b", "d"}, serr.Names())
+		require.EqualError(t, serr.Errors["b"], "b failed")
+		require.EqualError(t, err, "2 of 4 builds failed: b, d")
+	})
+
+	t.Run("canceled while queued", func(t *testing.T) {
+		s := NewScheduler(1)
+		hold := make(chan struct{})
+		s.Go(ctx, "running", func(ctx context.Context) error {
+			<-hold
+			return nil
+		})
+
+		cctx, cancel := context.WithCancel(ctx)
+		ran := false
+		s.Go(cctx, "queued", func(ctx context.Context) error {
+			ran = true
+			return nil
+		})
+		cancel()
+		// A later build still gets the slot once it frees up.
+		later := false
+		s.Go(ctx, "later", func(ctx context.Context) error {
+			later = true
+			return nil
+		})
+
+		// Give the queued build time to see the cancellation before the slot
+		// frees up.
+		time.Sleep(10 * time.Millisecond)
+		close(hold)
+		err := s.Wait()
+		var serr *SchedulerError
+		require.ErrorAs(t, err, &serr)
+		require.Equal(t, []string{"queued"}, serr.Names())
+		require.ErrorIs(t, serr.Errors["queued"], context.Canceled)
+		require.False(t, ran)
+		require.True(t, later)
+	})
+}
@@ -0,0 +1,86 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// signMu serializes signing, since cosign may prompt (e.g. for a key password
+// or an OIDC login) and concurrent deploys would interleave those prompts.
+var signMu sync.Mutex
+
+// SignOptions configures how images are signed.
+type SignOptions struct {
+	// Key is a path to a cosign private key, or a KMS URI. If empty,
+	// keyless signing is used, which prompts for an OIDC login.
+	Key string
+}
+
+// Sign signs image with cosign and returns the reference of the pushed
+// signature. If digest is set, the image is signed by digest rather than by
+// tag, since tags can be moved after signing.
+//
+// Signing uses the local cosign binary and the local registry credentials.
+func Sign(ctx context.Context, image, digest string, opts SignOptions) (string, error) {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return "", errors.New("cosign is required to sign images: https://docs.sigstore.dev/cosign/installation")
+	}
+
+	signMu.Lock()
+	defer signMu.Unlock()
+
+	ref := image
+	if digest != "" {
+		ref = imageRepo(image) + "@" + digest
+	}
+
+	args := []string{"sign"}
+	if opts.Key != "" {
+		args = append(args, "--key", opts.Key)
+	}
+	args = append(args, ref)
+
+	cmd := exec.CommandContext(ctx, "cosign", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if opts.Key == "" {
+		cmd.Env = append(cmd.Env, "COSIGN_EXPERIMENTAL=1")
+	}
+	logger.Debug("Running cosign %s", strings.Join(args, " "))
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "signing %s", ref)
+	}
+
+	// Triangulate returns where cosign stored the signature for ref.
+	var out bytes.Buffer
+	cmd = exec.CommandContext(ctx, "cosign", "triangulate", ref)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", errors.Wrapf(err, "locating signature for %s", ref)
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
+
+// imageRepo strips the tag or digest from an image reference.
+func imageRepo(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[:i]
+	}
+	// A colon after the last slash separates the tag, whereas one before it
+	// is part of the registry host (e.g. localhost:5000/image).
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i]
+	}
+	return image
+}
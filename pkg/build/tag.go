@@ -0,0 +1,91 @@
+package build
+
+import (
+	"context"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/airplanedev/cli/pkg/logger"
+)
+
+// builtImages remembers the image built for each content tag, so that later
+// invocations reuse it too instead of building the same context again.
+var builtImages = cache.New("images")
+
+// builtImageTTL bounds how long a remembered image is reused. Registries may
+// expire old images, so they are built again after this long.
+const builtImageTTL = 7 * 24 * time.Hour
+
+type builtImageEntry struct {
+	Response Response  `json:"response"`
+	BuiltAt  time.Time `json:"builtAt"`
+}
+
+// contentTag returns an image tag derived from everything that goes into a
+// build: the build context and the builder configuration. Builds with the same
+// tag produce equivalent images, so an image can be shared between tasks and
+// its tag never needs to be moved.
+func contentTag(req Request) (string, error) {
+	kind, options, err := req.Def.GetKindAndOptions()
+	if err != nil {
+		return "", err
+	}
+	env, err := req.Def.GetEnv()
+	if err != nil {
+		return "", err
+	}
+	sum, err := Checksum(req.Root, kind, options, req.Shim, env, req.TaskEnv)
+	if err != nil {
+		return "", err
+	}
+	// Docker tags are limited to 128 characters, and 32 hex characters are
+	// plenty to avoid collisions.
+	return "src-" + sum[:32], nil
+}
+
+// buildOnce runs build at most once per content tag. Later builds with the
+// same tag, including ones for other tasks and ones in later invocations of
+// the CLI, reuse the first build's image. Images are remembered per registry,
+// given by repo, so that teams never share them.
+func (d *Deployer) buildOnce(ctx context.Context, repo, tag string, build func() (*Response, error)) (*Response, error) {
+	var reused = true
+	key := repo + ":" + tag
+	res, err, _ := d.buildSingleFlightGroup.Do(key, func() (interface{}, error) {
+		d.builtImagesMutex.Lock()
+		resp, ok := d.builtImages[key]
+		d.builtImagesMutex.Unlock()
+		if ok {
+			return resp, nil
+		}
+		var entry builtImageEntry
+		if ok, err := builtImages.Get(key, &entry); err != nil {
+			logger.Debug("reading built images: %v", err)
+		} else if ok && entry.Response.ImageURL != "" && time.Since(entry.BuiltAt) < builtImageTTL {
+			return &entry.Response, nil
+		}
+
+		reused = false
+		resp, err := build()
+		if err != nil {
+			return nil, err
+		}
+		d.builtImagesMutex.Lock()
+		d.builtImages[key] = resp
+		d.builtImagesMutex.Unlock()
+		if err := builtImages.Set(key, builtImageEntry{Response: *resp, BuiltAt: time.Now()}); err != nil {
+			logger.Debug("remembering built image: %v", err)
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := res.(*Response)
+	if reused {
+		if !emit(ctx, Event{Phase: PhaseDone, Percent: 100, Message: resp.ImageURL}) {
+			logger.Log("Reusing image %s, built from an identical build context.", resp.ImageURL)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,41 @@
+package build
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildOnce(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("HOME", t.TempDir())
+	defer func(b cache.Bucket) { builtImages = b }(builtImages)
+	builtImages = cache.New("images")
+	ctx := context.Background()
+
+	var builds int
+	build := func() (*Response, error) {
+		builds++
+		return &Response{ImageURL: "registry/team/task-tsk:src-abc", BuildID: "bld"}, nil
+	}
+
+	resp, err := NewDeployer().buildOnce(ctx, "registry/team", "src-abc", build)
+	require.NoError(err)
+	require.Equal("bld", resp.BuildID)
+	require.Equal(1, builds)
+
+	// A later invocation, with a new deployer, reuses the image.
+	resp, err = NewDeployer().buildOnce(ctx, "registry/team", "src-abc", build)
+	require.NoError(err)
+	require.Equal("registry/team/task-tsk:src-abc", resp.ImageURL)
+	require.Equal(1, builds)
+
+	// Other registries and other contexts are built.
+	_, err = NewDeployer().buildOnce(ctx, "registry/other", "src-abc", build)
+	require.NoError(err)
+	_, err = NewDeployer().buildOnce(ctx, "registry/team", "src-def", build)
+	require.NoError(err)
+	require.Equal(3, builds)
+}
@@ -0,0 +1,242 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var (
+	// uploadChunkSize is the size of each chunk of a resumable upload. GCS
+	// requires every chunk but the last to be a multiple of 256 KiB.
+	uploadChunkSize int64 = 8 << 20
+
+	// uploadChunkRetries is how many times a chunk, or a single-request
+	// upload, is retried before the upload fails.
+	uploadChunkRetries = 5
+
+	// uploadRetryWait is how long to wait before the first retry of a chunk.
+	// Later retries back off exponentially.
+	uploadRetryWait = time.Second
+
+	// uploadClient sends upload requests. Uploads don't go through the API's
+	// retrying client, which would buffer each request body in memory.
+	uploadClient = api.DirectClient
+)
+
+// uploadSingle uploads archive to url with a single PUT.
+//
+// A failed upload is retried from the start, with the same backoff as the
+// chunks of a resumable upload.
+func uploadSingle(ctx context.Context, loader logger.Loader, url string, archive *os.File, size int64) error {
+	for attempt := 0; ; attempt++ {
+		err := putSingle(ctx, url, io.NewSectionReader(archive, 0, size), size)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || attempt >= uploadChunkRetries || !retryableUpload(err) {
+			return err
+		}
+
+		wait := uploadRetryWait << attempt
+		buildLog(ctx, PhaseUploading, api.LogLevelInfo, loader, logger.Gray, "Upload interrupted (%v), retrying in %s...", err, wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// putSingle uploads size bytes from body to url with a single PUT.
+func putSingle(ctx context.Context, url string, body io.Reader, size int64) error {
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, &progressReader{
+		ctx:   ctx,
+		r:     body,
+		size:  size,
+		phase: PhaseUploading,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating GCS upload request")
+	}
+	req.ContentLength = size
+	req.Header.Add("X-Goog-Content-Length-Range", fmt.Sprintf("0,%d", size))
+
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "uploading to GCS")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return uploadError(resp)
+	}
+	return nil
+}
+
+// uploadResumable uploads archive to the GCS resumable upload session at url,
+// in chunks of uploadChunkSize.
+//
+// A chunk that fails is retried with backoff. Before each retry, GCS is asked
+// how much of the archive it has, so that the upload resumes from there
+// rather than from the start of the chunk.
+func uploadResumable(ctx context.Context, loader logger.Loader, url string, archive *os.File, size int64) error {
+	progress := &progressReader{ctx: ctx, size: size, phase: PhaseUploading}
+	reported := 0
+
+	var offset int64
+	for offset < size {
+		end := offset + uploadChunkSize
+		if end > size {
+			end = size
+		}
+
+		var done bool
+		var err error
+		for attempt := 0; ; attempt++ {
+			progress.r = io.NewSectionReader(archive, offset, end-offset)
+			progress.read = offset
+			var next int64
+			next, done, err = putChunk(ctx, url, progress, offset, end, size)
+			if err == nil {
+				offset = next
+				break
+			}
+			if ctx.Err() != nil || attempt >= uploadChunkRetries || !retryableUpload(err) {
+				return errors.Wrapf(err, "uploading bytes %d-%d", offset, end-1)
+			}
+
+			wait := uploadRetryWait << attempt
+			buildLog(ctx, PhaseUploading, api.LogLevelInfo, loader, logger.Gray, "Upload interrupted (%v), retrying in %s...", err, wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			// The failed chunk may have been partly received.
+			if next, done, err := uploadStatus(ctx, url, size); err == nil {
+				if done {
+					return nil
+				}
+				offset = next
+				if offset >= end {
+					break
+				}
+			} else {
+				logger.Debug("Checking upload status: %v", err)
+			}
+		}
+		if done {
+			return nil
+		}
+
+		if pct := int(offset * 100 / size); pct >= reported+25 && pct < 100 {
+			reported = pct - pct%25
+			buildLog(ctx, PhaseUploading, api.LogLevelInfo, loader, logger.Gray, "Uploaded %s of %s",
+				utils.FormatBytes(offset),
+				utils.FormatBytes(size),
+			)
+		}
+	}
+	return nil
+}
+
+// putChunk uploads bytes [start, end) of a size-byte upload from body. It
+// returns the offset GCS expects next, and whether the upload is complete.
+func putChunk(ctx context.Context, url string, body io.Reader, start, end, size int64) (int64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, body)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "creating GCS upload request")
+	}
+	req.ContentLength = end - start
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, size))
+	return doUploadRequest(req)
+}
+
+// uploadStatus asks GCS how much of a size-byte upload it has received.
+func uploadStatus(ctx context.Context, url string, size int64) (int64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, nil)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "creating GCS upload status request")
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	return doUploadRequest(req)
+}
+
+// doUploadRequest sends a resumable upload request. GCS replies 308 with the
+// range it has so far while the upload is incomplete, and 200 or 201 when it
+// is complete.
+func doUploadRequest(req *http.Request) (int64, bool, error) {
+	resp, err := uploadClient.Do(req)
+	if err != nil {
+		return 0, false, errors.Wrap(err, "uploading to GCS")
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusCreated:
+		return 0, true, nil
+	case resp.StatusCode == http.StatusPermanentRedirect:
+		next, err := parseUploadRange(resp.Header.Get("Range"))
+		return next, false, err
+	default:
+		return 0, false, uploadError(resp)
+	}
+}
+
+// parseUploadRange returns the offset after a Range header such as
+// "bytes=0-1048575", or 0 if the header is empty.
+func parseUploadRange(h string) (int64, error) {
+	if h == "" {
+		return 0, nil
+	}
+	i := strings.LastIndex(h, "-")
+	if !strings.HasPrefix(h, "bytes=") || i < 0 {
+		return 0, errors.Errorf("unexpected upload range %q", h)
+	}
+	last, err := strconv.ParseInt(h[i+1:], 10, 64)
+	if err != nil {
+		return 0, errors.Errorf("unexpected upload range %q", h)
+	}
+	return last + 1, nil
+}
+
+// uploadStatusError is an upload request that GCS rejected.
+type uploadStatusError struct {
+	code int
+	msg  string
+}
+
+func (err uploadStatusError) Error() string {
+	return fmt.Sprintf("uploading to GCS: %d - %s", err.code, err.msg)
+}
+
+func uploadError(resp *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return uploadStatusError{code: resp.StatusCode, msg: msg}
+}
+
+// retryableUpload reports whether an upload request that failed with err may
+// succeed if sent again: connection errors, timeouts and server errors.
+func retryableUpload(err error) bool {
+	var serr uploadStatusError
+	if !errors.As(err, &serr) {
+		return true
+	}
+	return serr.code == http.StatusRequestTimeout || serr.code == http.StatusTooManyRequests || serr.code >= 500
+}
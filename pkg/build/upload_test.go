@@ -0,0 +1,277 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpload is a GCS resumable upload session.
+type fakeUpload struct {
+	mu       sync.Mutex
+	data     []byte
+	done     bool
+	requests int
+	// fail is called with the number of each request, and the request fails
+	// with the status it returns unless it's 0. If partial is also set, the
+	// first half of the failed request's body is kept.
+	fail    func(n int) int
+	partial bool
+}
+
+func (u *fakeUpload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.requests++
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	cr := strings.TrimPrefix(r.Header.Get("Content-Range"), "bytes ")
+	i := strings.LastIndex(cr, "/")
+	size, _ := strconv.ParseInt(cr[i+1:], 10, 64)
+	if cr[:i] != "*" {
+		var start, end int64
+		fmt.Sscanf(cr[:i], "%d-%d", &start, &end)
+		if start != int64(len(u.data)) || end-start+1 != int64(len(body)) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "unexpected range %s with %d bytes", cr, len(body))
+			return
+		}
+		if u.fail != nil {
+			if code := u.fail(u.requests); code != 0 {
+				if u.partial {
+					u.data = append(u.data, body[:len(body)/2]...)
+				}
+				w.WriteHeader(code)
+				return
+			}
+		}
+		u.data = append(u.data, body...)
+	}
+
+	if int64(len(u.data)) == size {
+		u.done = true
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if len(u.data) > 0 {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(u.data)-1))
+	}
+	w.WriteHeader(http.StatusPermanentRedirect)
+}
+
+func withUploadSettings(t *testing.T, chunkSize int64) {
+	oldSize, oldWait := uploadChunkSize, uploadRetryWait
+	uploadChunkSize, uploadRetryWait = chunkSize, time.Millisecond
+	t.Cleanup(func() {
+		uploadChunkSize, uploadRetryWait = oldSize, oldWait
+	})
+}
+
+func writeArchive(t *testing.T, content string) *os.File {
+	path := filepath.Join(t.TempDir(), "archive.tar.gz")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestUploadResumable(t *testing.T) {
+	const content = "0123456789abcdefghijklmnopqrstuvwxyz"
+	ctx := context.Background()
+	loader := &logger.NoopLoader{}
+
+	for _, test := range []struct {
+		name     string
+		fail     func(n int) int
+		partial  bool
+		requests int
+		err      string
+	}{
+		{
+			name:     "in chunks",
+			requests: 4,
+		},
+		{
+			name: "retries failed chunks",
+			fail: func(n int) int {
+				if n == 2 || n == 4 {
+					return http.StatusServiceUnavailable
+				}
+				return 0
+			},
+			// Each failure is followed by a retry and a status request.
+			requests: 8,
+		},
+		{
+			name: "resumes from partly received chunks",
+			fail: func(n int) int {
+				if n == 2 {
+					return http.StatusInternalServerError
+				}
+				return 0
+			},
+			partial: true,
+			// Bytes 10-14 are received before the failure, so the chunk
+			// resumes from there: 0-9, 10-19 (failed), status, 15-19,
+			// 20-29, 30-35.
+			requests: 6,
+		},
+		{
+			name:     "gives up on client errors",
+			fail:     func(n int) int { return http.StatusForbidden },
+			requests: 1,
+			err:      "uploading bytes 0-9: uploading to GCS: 403 - Forbidden",
+		},
+		{
+			name:     "gives up after retries",
+			fail:     func(n int) int { return http.StatusBadGateway },
+			requests: 1 + 2*uploadChunkRetries,
+			err:      "uploading bytes 0-9: uploading to GCS: 502 - Bad Gateway",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			withUploadSettings(t, 10)
+			u := &fakeUpload{fail: test.fail, partial: test.partial}
+			srv := httptest.NewServer(u)
+			defer srv.Close()
+
+			err := uploadResumable(ctx, loader, srv.URL, writeArchive(t, content), int64(len(content)))
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				require.False(t, u.done)
+			} else {
+				require.NoError(t, err)
+				require.True(t, u.done)
+				require.Equal(t, content, string(u.data))
+			}
+			require.Equal(t, test.requests, u.requests)
+		})
+	}
+}
+
+func TestUploadSingle(t *testing.T) {
+	const content = "0123456789"
+	ctx := context.Background()
+	loader := &logger.NoopLoader{}
+
+	for _, test := range []struct {
+		name     string
+		codes    []int
+		requests int
+		err      string
+	}{
+		{name: "uploads", codes: []int{200}, requests: 1},
+		{name: "retries server errors", codes: []int{503, 500, 200}, requests: 3},
+		{name: "retries rate limits", codes: []int{429, 200}, requests: 2},
+		{name: "gives up on client errors", codes: []int{400}, requests: 1, err: "uploading to GCS: 400 - Bad Request"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			withUploadSettings(t, 10)
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				require.NoError(t, err)
+				// Every attempt sends the whole archive.
+				require.Equal(t, content, string(body))
+				require.Equal(t, "0,10", r.Header.Get("X-Goog-Content-Length-Range"))
+				w.WriteHeader(test.codes[requests])
+				requests++
+			}))
+			defer srv.Close()
+
+			err := uploadSingle(ctx, loader, srv.URL, writeArchive(t, content), int64(len(content)))
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, test.requests, requests)
+		})
+	}
+}
+
+func TestPutChunk(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name   string
+		status int
+		rng    string
+		next   int64
+		done   bool
+		err    string
+	}{
+		{name: "incomplete", status: http.StatusPermanentRedirect, rng: "bytes=0-9", next: 10},
+		{name: "nothing received", status: http.StatusPermanentRedirect, next: 0},
+		{name: "complete", status: http.StatusOK, done: true},
+		{name: "created", status: http.StatusCreated, done: true},
+		{name: "bad range", status: http.StatusPermanentRedirect, rng: "0-9", err: `unexpected upload range "0-9"`},
+		{name: "rejected", status: http.StatusGone, err: "uploading to GCS: 410 - Gone"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "PUT", r.Method)
+				require.Equal(t, "bytes 10-19/36", r.Header.Get("Content-Range"))
+				require.Equal(t, int64(10), r.ContentLength)
+				if test.rng != "" {
+					w.Header().Set("Range", test.rng)
+				}
+				w.WriteHeader(test.status)
+			}))
+			defer srv.Close()
+
+			next, done, err := putChunk(ctx, srv.URL, strings.NewReader("abcdefghij"), 10, 20, 36)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.next, next)
+			require.Equal(t, test.done, done)
+		})
+	}
+}
+
+func TestParseUploadRange(t *testing.T) {
+	for _, test := range []struct {
+		header string
+		next   int64
+		err    bool
+	}{
+		{header: "", next: 0},
+		{header: "bytes=0-0", next: 1},
+		{header: "bytes=0-1048575", next: 1048576},
+		{header: "0-1048575", err: true},
+		{header: "bytes=0", err: true},
+		{header: "bytes=0-", err: true},
+		{header: "bytes=0-x", err: true},
+	} {
+		t.Run(test.header, func(t *testing.T) {
+			next, err := parseUploadRange(test.header)
+			if test.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.next, next)
+		})
+	}
+}
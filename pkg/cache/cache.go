@@ -0,0 +1,145 @@
+// Package cache implements a small on-disk key-value store for data the CLI
+// can safely lose, such as checksums of previous deploys.
+//
+// Values are grouped into buckets. Each bucket is stored as a single JSON
+// file under ~/.airplane/cache and is rewritten in full on every change.
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/pkg/errors"
+)
+
+// mu serializes read-modify-write cycles within this process. Concurrent CLI
+// processes may still race, in which case the last write wins.
+var mu sync.Mutex
+
+// Dir returns the directory cached data is stored in.
+func Dir() string {
+	return filepath.Join(conf.Dir(), "cache")
+}
+
+// Bucket is a named set of cached values.
+type Bucket struct {
+	path string
+}
+
+// New returns the bucket with the given name.
+func New(name string) Bucket {
+	return NewIn(Dir(), name)
+}
+
+// NewIn returns the bucket with the given name, stored in dir instead of the
+// cache directory, for data that should outlive the cache.
+func NewIn(dir, name string) Bucket {
+	return Bucket{path: filepath.Join(dir, name+".json")}
+}
+
+// Get reads the value stored under key into v. It reports false if no value
+// is stored under key.
+func (b Bucket) Get(key string, v interface{}) (bool, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := b.read()
+	if err != nil {
+		return false, err
+	}
+	raw, ok := entries[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, errors.Wrapf(err, "cache: unmarshal %s", key)
+	}
+	return true, nil
+}
+
+// Set stores v under key, replacing any existing value.
+func (b Bucket) Set(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrapf(err, "cache: marshal %s", key)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := b.read()
+	if err != nil {
+		return err
+	}
+	entries[key] = raw
+	return b.write(entries)
+}
+
+// Delete removes the value stored under key, if any.
+func (b Bucket) Delete(key string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, err := b.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return nil
+	}
+	delete(entries, key)
+	return b.write(entries)
+}
+
+func (b Bucket) read() (map[string]json.RawMessage, error) {
+	entries := map[string]json.RawMessage{}
+
+	buf, err := ioutil.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "cache: read")
+	}
+
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		// A corrupt cache is as good as an empty one.
+		return map[string]json.RawMessage{}, nil
+	}
+	return entries, nil
+}
+
+// write replaces the bucket's file atomically, so that a crash mid-write
+// never leaves a truncated file behind.
+func (b Bucket) write(entries map[string]json.RawMessage) error {
+	buf, err := json.Marshal(entries)
+	if err != nil {
+		return errors.Wrap(err, "cache: marshal")
+	}
+
+	dir := filepath.Dir(b.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "cache: mkdir")
+	}
+
+	f, err := ioutil.TempFile(dir, filepath.Base(b.path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "cache: create temp file")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return errors.Wrap(err, "cache: write")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "cache: close")
+	}
+	if err := os.Rename(f.Name(), b.path); err != nil {
+		return errors.Wrap(err, "cache: rename")
+	}
+	return nil
+}
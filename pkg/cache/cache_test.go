@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket(t *testing.T) {
+	t.Run("get missing", func(t *testing.T) {
+		var assert = require.New(t)
+		var b = Bucket{path: filepath.Join(tempdir(t), "cache", "test.json")}
+
+		var v string
+		ok, err := b.Get("foo", &v)
+		assert.NoError(err)
+		assert.False(ok)
+	})
+
+	t.Run("set and get", func(t *testing.T) {
+		var assert = require.New(t)
+		var b = Bucket{path: filepath.Join(tempdir(t), "cache", "test.json")}
+
+		assert.NoError(b.Set("foo", "bar"))
+		assert.NoError(b.Set("baz", "qux"))
+
+		var v string
+		ok, err := b.Get("foo", &v)
+		assert.NoError(err)
+		assert.True(ok)
+		assert.Equal("bar", v)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		var assert = require.New(t)
+		var b = Bucket{path: filepath.Join(tempdir(t), "cache", "test.json")}
+
+		assert.NoError(b.Set("foo", "bar"))
+		assert.NoError(b.Delete("foo"))
+		assert.NoError(b.Delete("missing"))
+
+		var v string
+		ok, err := b.Get("foo", &v)
+		assert.NoError(err)
+		assert.False(ok)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		var assert = require.New(t)
+		var path = filepath.Join(tempdir(t), "test.json")
+		var b = Bucket{path: path}
+		assert.NoError(ioutil.WriteFile(path, []byte("{not json"), 0644))
+
+		var v string
+		ok, err := b.Get("foo", &v)
+		assert.NoError(err)
+		assert.False(ok)
+
+		assert.NoError(b.Set("foo", "bar"))
+		ok, err = b.Get("foo", &v)
+		assert.NoError(err)
+		assert.True(ok)
+	})
+}
+
+func tempdir(t testing.TB) string {
+	name, err := ioutil.TempDir("", "cli_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(name)
+	})
+	return name
+}
@@ -0,0 +1,370 @@
+// Package cassette records the CLI's HTTP requests and their responses to a
+// file, called a cassette, and replays them from it, so that a run of the CLI
+// can be reproduced without the API: for bug reports, and for deterministic
+// tests of flows such as deploys of several tasks.
+//
+// Cassettes hold one JSON interaction per line. They are sanitized as they
+// are written: request headers aren't recorded, so neither are tokens and API
+// keys, and secrets in bodies and signed URLs are replaced with Redacted.
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// Redacted replaces the secrets removed from cassettes.
+const Redacted = "[redacted]"
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request. Requests are matched by method and URL, and
+// then by body.
+type Request struct {
+	Method string `json:"method"`
+	// URL is the request's path and query, without a host, so that cassettes
+	// replay against any host.
+	URL  string `json:"url"`
+	Body string `json:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	// Base64 is set if Body is base64, as it is for bodies that aren't text.
+	Base64 bool `json:"base64,omitempty"`
+}
+
+// recordedHeaders are the response headers kept in cassettes.
+var recordedHeaders = []string{"Content-Type", "X-Request-ID", "Location"}
+
+// Recorder is a transport that records every request sent through it, and
+// its response, to a cassette.
+type Recorder struct {
+	// Next is the transport that requests are sent with. If nil,
+	// http.DefaultTransport is used.
+	Next http.RoundTripper
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+var _ http.RoundTripper = &Recorder{}
+
+// NewRecorder returns a recorder to a new cassette at path, replacing any
+// that is there.
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrap(err, "creating cassette")
+	}
+	return &Recorder{f: f}, nil
+}
+
+// RoundTrip implementation.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	i := Interaction{
+		Request: newRequest(req, reqBody),
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: map[string]string{},
+		},
+	}
+	for _, h := range recordedHeaders {
+		if v := resp.Header.Get(h); v != "" {
+			i.Response.Headers[h] = v
+		}
+	}
+	if utf8.Valid(respBody) {
+		i.Response.Body = sanitizeBody(respBody)
+	} else {
+		i.Response.Body = base64.StdEncoding.EncodeToString(respBody)
+		i.Response.Base64 = true
+	}
+
+	line, err := json.Marshal(i)
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding interaction")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.f.Write(append(line, '\n')); err != nil {
+		return nil, errors.Wrap(err, "recording interaction")
+	}
+	return resp, nil
+}
+
+// Close closes the cassette.
+func (r *Recorder) Close() error {
+	return r.f.Close()
+}
+
+// Replayer is a transport that responds to requests with the responses
+// recorded in a cassette, without sending them.
+//
+// Each request is answered by the first unused interaction with the same
+// method, URL and body, or else method and URL. Interactions are used in
+// order, so that polling replays the recorded progress. Once every matching
+// interaction has been used, the last one is repeated, since requests may
+// be polled more often when replayed than when recorded.
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+var _ http.RoundTripper = &Replayer{}
+
+// NewReplayer returns a replayer of the cassette at path.
+func NewReplayer(path string) (*Replayer, error) {
+	interactions, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{
+		interactions: interactions,
+		used:         make([]bool, len(interactions)),
+	}, nil
+}
+
+// Load reads the interactions of the cassette at path.
+func Load(path string) ([]Interaction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening cassette")
+	}
+	defer f.Close()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20)
+	for n := 1; scanner.Scan(); n++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var i Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return nil, errors.Wrapf(err, "%s:%d", path, n)
+		}
+		interactions = append(interactions, i)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading cassette")
+	}
+	return interactions, nil
+}
+
+// RoundTrip implementation.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	want := newRequest(req, body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// In order of preference: an unused interaction with the same body, an
+	// unused one, and the last one.
+	match, unused, last := -1, -1, -1
+	for j, i := range r.interactions {
+		if i.Request.Method != want.Method || i.Request.URL != want.URL {
+			continue
+		}
+		last = j
+		if r.used[j] {
+			continue
+		}
+		if unused < 0 {
+			unused = j
+		}
+		if match < 0 && i.Request.Body == want.Body {
+			match = j
+		}
+	}
+	switch {
+	case match >= 0:
+	case unused >= 0:
+		match = unused
+	case last >= 0:
+		match = last
+	default:
+		return nil, errors.Errorf("cassette has no response to %s %s", want.Method, want.URL)
+	}
+	r.used[match] = true
+	return newResponse(req, r.interactions[match].Response)
+}
+
+// newRequest returns the sanitized recording of req.
+func newRequest(req *http.Request, body []byte) Request {
+	u := *req.URL
+	u.Scheme, u.Host, u.User = "", "", nil
+	r := Request{
+		Method: req.Method,
+		URL:    sanitizeURL(u.String()),
+	}
+	// Only JSON bodies are recorded: others, such as uploaded archives, are
+	// large and rarely tell requests apart.
+	if json.Valid(body) {
+		r.Body = sanitizeBody(body)
+	}
+	return r
+}
+
+// newResponse returns the response to req that was recorded as r.
+func newResponse(req *http.Request, r Response) (*http.Response, error) {
+	body := []byte(r.Body)
+	if r.Base64 {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(r.Body); err != nil {
+			return nil, errors.Wrap(err, "decoding recorded body")
+		}
+	}
+	header := http.Header{}
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readBody reads all of *body, and replaces it with a reader of the same
+// bytes.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	buf, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading body")
+	}
+	*body = ioutil.NopCloser(bytes.NewReader(buf))
+	return buf, nil
+}
+
+// secretFields are the JSON fields whose values are always secret.
+var secretFields = map[string]bool{
+	"token":    true,
+	"password": true,
+	"secret":   true,
+}
+
+// sanitizeBody returns body with its secrets redacted: the values registered
+// with logger.Redact, and in JSON, secret fields, the values of secret
+// configs, the keys of API keys and the signatures of signed URLs.
+func sanitizeBody(body []byte) string {
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return logger.Redacted(string(body))
+	}
+	buf, err := json.Marshal(sanitizeValue("", v))
+	if err != nil {
+		return logger.Redacted(string(body))
+	}
+	return logger.Redacted(string(buf))
+}
+
+// sanitizeValue redacts the secrets of v, the value of the field named field.
+func sanitizeValue(field string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		secret, _ := v["isSecret"].(bool)
+		for k, e := range v {
+			switch {
+			case secretFields[k],
+				k == "value" && secret,
+				k == "key" && (field == "apiKey" || field == "apiKeys"):
+				if _, ok := e.(string); ok {
+					v[k] = Redacted
+					continue
+				}
+			}
+			v[k] = sanitizeValue(k, e)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = sanitizeValue(field, e)
+		}
+		return v
+	case string:
+		return sanitizeURL(v)
+	default:
+		return v
+	}
+}
+
+// sanitizeURL redacts the signatures and credentials of a signed URL. Other
+// strings are returned as is.
+func sanitizeURL(s string) string {
+	if !strings.Contains(s, "?") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") {
+		return s
+	}
+	q := u.Query()
+	changed := false
+	for k := range q {
+		lower := strings.ToLower(k)
+		if strings.Contains(lower, "signature") || strings.Contains(lower, "credential") || (strings.Contains(lower, "token") && lower != "prev_token") {
+			q.Set(k, Redacted)
+			changed = true
+		}
+	}
+	if !changed {
+		return s
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
@@ -0,0 +1,131 @@
+package cassette
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	var assert = require.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		switch r.URL.Path {
+		case "/v0/configs/get":
+			w.Write([]byte(`{"config":{"name":"db","value":"pg://hunter2","isSecret":true}}`))
+		case "/v0/uploads/create":
+			w.Write([]byte(`{"writeOnlyURL":"https://storage/upload?X-Goog-Signature=abc&X-Goog-Credential=def&name=x"}`))
+		case "/v0/archive":
+			w.Write([]byte{0xff, 0xfe, 0x00})
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "test.cassette")
+	rec, err := NewRecorder(path)
+	assert.NoError(err)
+	client := &http.Client{Transport: rec}
+
+	req, err := http.NewRequest("POST", srv.URL+"/v0/configs/get", strings.NewReader(`{"name":"db","token":"tkn"}`))
+	assert.NoError(err)
+	req.Header.Set("X-Airplane-Token", "tkn")
+	resp, err := client.Do(req)
+	assert.NoError(err)
+	// The response is still readable after it's been recorded.
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Contains(string(body), "hunter2")
+
+	for _, p := range []string{"/v0/uploads/create", "/v0/archive"} {
+		resp, err := client.Get(srv.URL + p)
+		assert.NoError(err)
+		resp.Body.Close()
+	}
+	assert.NoError(rec.Close())
+
+	raw, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	for _, secret := range []string{"tkn", "hunter2", "abc", "def", "session", "127.0.0.1"} {
+		assert.NotContains(string(raw), secret)
+	}
+
+	interactions, err := Load(path)
+	assert.NoError(err)
+	assert.Len(interactions, 3)
+	assert.Equal(Request{
+		Method: "POST",
+		URL:    "/v0/configs/get",
+		Body:   `{"name":"db","token":"[redacted]"}`,
+	}, interactions[0].Request)
+	assert.Equal(`{"config":{"isSecret":true,"name":"db","value":"[redacted]"}}`, interactions[0].Response.Body)
+	assert.Equal(map[string]string{"Content-Type": "application/json"}, interactions[0].Response.Headers)
+	assert.Contains(interactions[1].Response.Body, "name=x")
+	assert.True(interactions[2].Response.Base64)
+}
+
+func TestReplayer(t *testing.T) {
+	var assert = require.New(t)
+	path := filepath.Join(t.TempDir(), "test.cassette")
+	assert.NoError(ioutil.WriteFile(path, []byte(strings.Join([]string{
+		`{"request":{"method":"GET","url":"/v0/runs/get?id=run1"},"response":{"status":200,"body":"active"}}`,
+		`{"request":{"method":"GET","url":"/v0/runs/get?id=run1"},"response":{"status":200,"body":"succeeded"}}`,
+		`{"request":{"method":"POST","url":"/v0/tasks/create","body":"{\"slug\":\"a\"}"},"response":{"status":200,"body":"a"}}`,
+		`{"request":{"method":"POST","url":"/v0/tasks/create","body":"{\"slug\":\"b\"}"},"response":{"status":200,"body":"b"}}`,
+		``,
+		`{"request":{"method":"GET","url":"/v0/archive"},"response":{"status":200,"body":"//4A","base64":true}}`,
+	}, "\n")), os.ModePerm))
+
+	r, err := NewReplayer(path)
+	assert.NoError(err)
+	client := &http.Client{Transport: r}
+	do := func(method, url, body string) (int, string, error) {
+		req, err := http.NewRequest(method, "http://any.host"+url, strings.NewReader(body))
+		assert.NoError(err)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, "", err
+		}
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		assert.NoError(err)
+		return resp.StatusCode, string(b), nil
+	}
+
+	t.Run("in order, then repeating the last", func(t *testing.T) {
+		for _, want := range []string{"active", "succeeded", "succeeded"} {
+			status, body, err := do("GET", "/v0/runs/get?id=run1", "")
+			assert.NoError(err)
+			assert.Equal(200, status)
+			assert.Equal(want, body)
+		}
+	})
+
+	t.Run("matching bodies", func(t *testing.T) {
+		_, body, err := do("POST", "/v0/tasks/create", `{"slug":"b"}`)
+		assert.NoError(err)
+		assert.Equal("b", body)
+		_, body, err = do("POST", "/v0/tasks/create", `{"slug":"a"}`)
+		assert.NoError(err)
+		assert.Equal("a", body)
+	})
+
+	t.Run("base64", func(t *testing.T) {
+		_, body, err := do("GET", "/v0/archive", "")
+		assert.NoError(err)
+		assert.Equal(string([]byte{0xff, 0xfe, 0x00}), body)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, _, err := do("GET", "/v0/runs/get?id=run2", "")
+		assert.Error(err)
+		assert.Contains(err.Error(), "cassette has no response to GET /v0/runs/get?id=run2")
+	})
+}
@@ -0,0 +1,30 @@
+package agents
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/agents/list"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new cobra command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "agents",
+		Short:   "Manage agents",
+		Long:    "Inspect the agents that execute your team's runs.",
+		Aliases: []string{"agent"},
+		Example: heredoc.Doc(`
+			airplane agents list
+		`),
+		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}),
+	}
+
+	cmd.AddCommand(list.New(c))
+
+	return cmd
+}
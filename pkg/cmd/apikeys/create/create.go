@@ -5,37 +5,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 	"time"
 
+	"github.com/MakeNowJust/heredoc"
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/mattn/go-isatty"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+type config struct {
+	root      *cli.Config
+	name      string
+	scopes    []string
+	expiresIn time.Duration
+}
+
 // New returns a new create command.
 func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
 	cmd := &cobra.Command{
 		Use:   "create [<key_name>]",
 		Short: "Generates a new API key for self-hosting agents and building custom integrations",
-		Args:  cobra.RangeArgs(0, 1),
+		Example: heredoc.Doc(`
+			$ airplane apikeys create
+			$ airplane apikeys create ci --scope deploy --expires-in 2160h
+		`),
+		Args: cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			var name string
 			if len(args) > 0 {
-				name = args[0]
+				cfg.name = args[0]
 			}
-			return run(cmd.Root().Context(), c, name)
+			return run(cmd.Root().Context(), cfg)
 		},
 	}
+	cmd.Flags().StringSliceVar(&cfg.scopes, "scope", nil, "Only allow the key to deploy or execute tasks. Can be repeated. Defaults to everything you can do.")
+	cmd.Flags().DurationVar(&cfg.expiresIn, "expires-in", 0, "How long until the key stops working, such as 720h. Defaults to never.")
 	return cmd
 }
 
 // Run runs the create command.
-func run(ctx context.Context, c *cli.Config, name string) error {
-	var client = c.Client
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
 
+	name := cfg.name
 	if name == "" {
 		name = fmt.Sprintf("API Key (created %s)", time.Now().Format(time.RFC3339))
 	}
@@ -43,6 +61,19 @@ func run(ctx context.Context, c *cli.Config, name string) error {
 	req := api.CreateAPIKeyRequest{
 		Name: name,
 	}
+	for _, s := range cfg.scopes {
+		scope, err := parseScope(s)
+		if err != nil {
+			return err
+		}
+		req.Scopes = append(req.Scopes, scope)
+	}
+	if cfg.expiresIn < 0 {
+		return errors.New("--expires-in must be positive")
+	} else if cfg.expiresIn > 0 {
+		expiresAt := time.Now().Add(cfg.expiresIn).UTC()
+		req.ExpiresAt = &expiresAt
+	}
 	logger.Log("  Creating API key named %s...", logger.Blue(req.Name))
 	resp, err := client.CreateAPIKey(ctx, req)
 	if err != nil {
@@ -50,7 +81,18 @@ func run(ctx context.Context, c *cli.Config, name string) error {
 	}
 
 	apiKey := resp.APIKey
+	if err := checkKey(req, apiKey); err != nil {
+		// The key doesn't have the limits that were asked for, so don't
+		// leave it usable.
+		if derr := client.DeleteAPIKey(ctx, api.DeleteAPIKeyRequest{KeyID: apiKey.ID}); derr != nil {
+			return errors.Wrapf(err, "unable to delete the key (%v), delete it with airplane apikeys delete %s", derr, apiKey.ID)
+		}
+		return errors.Wrap(err, "the key was deleted")
+	}
 	logger.Log("  Done!")
+	if apiKey.ExpiresAt != nil {
+		logger.Log("  The key expires at %s.", utils.FormatTime(*apiKey.ExpiresAt))
+	}
 	logger.Debug("  API key ID: %s", apiKey.ID)
 	if isatty.IsTerminal(os.Stdout.Fd()) {
 		logger.Log(`
@@ -66,3 +108,43 @@ func run(ctx context.Context, c *cli.Config, name string) error {
 
 	return nil
 }
+
+// checkKey returns an error if key doesn't have the scopes and expiry that
+// req asked for, such as when the API doesn't support them and ignored them.
+func checkKey(req api.CreateAPIKeyRequest, key api.APIKey) error {
+	want := map[api.APIKeyScope]bool{}
+	for _, s := range req.Scopes {
+		want[s] = true
+	}
+	got := map[api.APIKeyScope]bool{}
+	for _, s := range key.Scopes {
+		got[s] = true
+	}
+	if !reflect.DeepEqual(want, got) {
+		return errors.Errorf("created an API key with scopes %v, not the requested %v", key.Scopes, req.Scopes)
+	}
+
+	switch {
+	case req.ExpiresAt == nil && key.ExpiresAt != nil:
+		return errors.Errorf("created an API key that expires at %s, not one that never expires", utils.FormatTime(*key.ExpiresAt))
+	case req.ExpiresAt != nil && key.ExpiresAt == nil:
+		return errors.Errorf("created an API key that never expires, not one that expires at %s", utils.FormatTime(*req.ExpiresAt))
+	case req.ExpiresAt != nil:
+		// Allow for the API storing expiries to the second.
+		if d := key.ExpiresAt.Sub(*req.ExpiresAt); d > time.Second || d < -time.Second {
+			return errors.Errorf("created an API key that expires at %s, not the requested %s", utils.FormatTime(*key.ExpiresAt), utils.FormatTime(*req.ExpiresAt))
+		}
+	}
+	return nil
+}
+
+func parseScope(s string) (api.APIKeyScope, error) {
+	var names []string
+	for _, scope := range api.APIKeyScopes {
+		if string(scope) == s {
+			return scope, nil
+		}
+		names = append(names, string(scope))
+	}
+	return "", errors.Errorf("unknown scope %q, expected one of: %s", s, strings.Join(names, ", "))
+}
@@ -0,0 +1,40 @@
+package create
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckKey(t *testing.T) {
+	at := time.Date(2022, 4, 16, 6, 0, 0, 500, time.UTC)
+	rounded := at.Truncate(time.Second)
+	later := at.Add(time.Hour)
+	deploy := []api.APIKeyScope{api.APIKeyScopeDeploy}
+
+	for _, test := range []struct {
+		name string
+		req  api.CreateAPIKeyRequest
+		key  api.APIKey
+		ok   bool
+	}{
+		{"unlimited", api.CreateAPIKeyRequest{}, api.APIKey{}, true},
+		{"as requested", api.CreateAPIKeyRequest{Scopes: deploy, ExpiresAt: &at}, api.APIKey{Scopes: deploy, ExpiresAt: &rounded}, true},
+		{"scopes ignored", api.CreateAPIKeyRequest{Scopes: deploy}, api.APIKey{}, false},
+		{"extra scopes", api.CreateAPIKeyRequest{Scopes: deploy}, api.APIKey{Scopes: api.APIKeyScopes}, false},
+		{"expiry ignored", api.CreateAPIKeyRequest{ExpiresAt: &at}, api.APIKey{}, false},
+		{"unrequested expiry", api.CreateAPIKeyRequest{}, api.APIKey{ExpiresAt: &at}, false},
+		{"other expiry", api.CreateAPIKeyRequest{ExpiresAt: &at}, api.APIKey{ExpiresAt: &later}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkKey(test.req, test.key)
+			if test.ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
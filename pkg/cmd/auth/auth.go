@@ -6,6 +6,7 @@ import (
 	"github.com/airplanedev/cli/pkg/cmd/auth/info"
 	"github.com/airplanedev/cli/pkg/cmd/auth/login"
 	"github.com/airplanedev/cli/pkg/cmd/auth/logout"
+	"github.com/airplanedev/cli/pkg/cmd/auth/token"
 	"github.com/spf13/cobra"
 )
 
@@ -17,12 +18,14 @@ func New(c *cli.Config) *cobra.Command {
 		Example: heredoc.Doc(`
 			$ airplane auth login
 			$ airplane auth logout
+			$ airplane auth token --show
 		`),
 	}
 
 	cmd.AddCommand(info.New(c))
 	cmd.AddCommand(login.New(c))
 	cmd.AddCommand(logout.New(c))
+	cmd.AddCommand(token.New(c))
 
 	return cmd
 }
@@ -52,6 +52,11 @@ func validateToken(ctx context.Context, c *cli.Config) (bool, error) {
 	if e, ok := err.(api.Error); ok && e.Code == 401 {
 		logger.Debug("Found an expired token. Re-authenticating.")
 		return false, nil
+	} else if api.IsUnavailable(err) {
+		// The token can't be checked, but commands that work offline may
+		// still succeed, and the rest will report the API is unreachable.
+		logger.Debug("Unable to validate token: %v", err)
+		return true, nil
 	} else if err != nil {
 		return false, err
 	}
@@ -64,6 +69,11 @@ func validateAPIKey(ctx context.Context) bool {
 }
 
 func EnsureLoggedIn(ctx context.Context, c *cli.Config) error {
+	if conf.GetReplay() != "" {
+		// Replayed commands are answered by their cassette, whoever recorded it.
+		return nil
+	}
+
 	if ok, err := validateToken(ctx, c); err != nil {
 		return err
 	} else if ok {
@@ -111,15 +121,8 @@ func login(ctx context.Context, c *cli.Config) error {
 
 	case token := <-srv.Token():
 		c.Client.Token = token
-		cfg, err := conf.ReadDefault()
-		if err != nil && !errors.Is(err, conf.ErrMissing) {
-			return err
-		}
-		if cfg.Tokens == nil {
-			cfg.Tokens = map[string]string{}
-		}
-		cfg.Tokens[c.Client.Host] = token
-		if err := conf.WriteDefault(cfg); err != nil {
+		logger.Redact(token)
+		if err := conf.Credentials().Set(c.Client.Host, token); err != nil {
 			return err
 		}
 	}
@@ -22,17 +22,8 @@ func New(c *cli.Config) *cobra.Command {
 }
 
 func run(ctx context.Context, c *cli.Config) error {
-	cfg, err := conf.ReadDefault()
-	if !errors.Is(err, conf.ErrMissing) {
-		if err != nil {
-			return err
-		}
-
-		delete(cfg.Tokens, c.Client.Host)
-
-		if err := conf.WriteDefault(cfg); err != nil {
-			return err
-		}
+	if err := conf.Credentials().Delete(c.Client.Host); err != nil {
+		return errors.Wrap(err, "removing token")
 	}
 
 	logger.Log("Logged out.")
@@ -0,0 +1,56 @@
+package token
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func New(c *cli.Config) *cobra.Command {
+	var show bool
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Show where the CLI's auth token is stored",
+		Long: heredoc.Doc(`
+			Show where the CLI's auth token is stored: the OS keychain when one is
+			available, or the CLI's config file otherwise.
+
+			The token itself is only printed with --show, to stdout, so that it can
+			be passed to other tools.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane auth token
+			$ airplane auth token --show | pbcopy
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c, show)
+		},
+	}
+	cmd.Flags().BoolVar(&show, "show", false, "Print the token to stdout.")
+	return cmd
+}
+
+func run(ctx context.Context, c *cli.Config, show bool) error {
+	store := conf.Credentials()
+	token, err := store.Get(c.Client.Host)
+	if errors.Is(err, conf.ErrNoCredential) {
+		return errors.Errorf("no token is stored for %s. To login, run:\n    airplane login", c.Client.Host)
+	} else if err != nil {
+		return err
+	}
+
+	if show {
+		fmt.Println(token)
+		return nil
+	}
+	logger.Log("  Token for %s is stored in %s", logger.Blue(c.Client.Host), logger.Bold(store.Name()))
+	logger.Log("  Print it with %s", logger.Bold("airplane auth token --show"))
+	return nil
+}
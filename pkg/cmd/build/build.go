@@ -0,0 +1,193 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/build"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/cmd/build/printdockerfile"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/utils"
+	libBuild "github.com/airplanedev/lib/pkg/build"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root             *cli.Config
+	file             string
+	local            bool
+	compression      string
+	compressionLevel int
+
+	builderSize   string
+	builderRegion string
+	buildTimeout  time.Duration
+
+	auditDeps  bool
+	auditLevel string
+
+	healthcheck bool
+}
+
+// New returns a new build command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+
+	cmd := &cobra.Command{
+		Use:   "build ./path/to/definition",
+		Short: "Build a task's image",
+		Long: heredoc.Doc(`
+			Build the image for a task from its definition, without deploying it.
+
+			The task must already exist, and is left unchanged. The built image is
+			printed on completion, so that pipelines can build a task and release
+			it in separate steps.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane build ./my_task.task.yaml
+			$ airplane build ./airplane.yml --local
+			$ airplane build ./my_task.task.yaml --local --healthcheck
+			$ airplane build ./my_task.task.yaml -o json | jq -r .image
+
+			$ airplane build print-dockerfile ./my_task.task.yaml
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Root().Context()
+			for _, name := range []string{"builder-size", "builder-region", "build-timeout"} {
+				if cmd.Flags().Changed(name) && cfg.local {
+					return errors.Errorf("--%s only applies to remote builds, not --local", name)
+				}
+			}
+			if err := login.EnsureLoggedIn(ctx, c); err != nil {
+				return err
+			}
+			cfg.file = args[0]
+			return run(ctx, cfg)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&cfg.local, "local", "L", false, "use a local Docker daemon (instead of an Airplane-hosted builder)")
+	cmd.Flags().BoolVar(&cfg.healthcheck, "healthcheck", false, "With --local on an ARM Mac or Windows machine, start the runtime in the image's entrypoint, such as node or python, under emulation before pushing it, to catch binaries built for the wrong architecture. No task code runs.")
+	cmd.Flags().StringVar(&cfg.compression, "compression", "gzip", "Format to compress the build context with for remote builds: gzip or zstd.")
+	cmd.Flags().IntVar(&cfg.compressionLevel, "compression-level", 0, "Compression level for remote builds: 1-9 for gzip, 1-22 for zstd, or 0 for the format's default.")
+	cmd.Flags().StringVar(&cfg.builderSize, "builder-size", conf.GetBuilderSize(), "Size of the remote builder: small or large. Defaults to $AP_BUILDER_SIZE, or the team's default.")
+	cmd.Flags().StringVar(&cfg.builderRegion, "builder-region", conf.GetBuilderRegion(), "Region to run the remote builder in. Defaults to $AP_BUILDER_REGION, or the team's default.")
+	cmd.Flags().DurationVar(&cfg.buildTimeout, "build-timeout", 0, "How long a remote build may run before it is stopped, such as 45m. Defaults to the team's limit.")
+
+	cmd.Flags().BoolVar(&cfg.auditDeps, "audit", false, "Check Node and Python dependencies for known vulnerabilities with npm audit or pip-audit before building.")
+	cmd.Flags().StringVar(&cfg.auditLevel, "audit-level", "", "Fail the build if --audit finds a vulnerability at least this severe: low, moderate, high or critical. Implies --audit.")
+
+	cmd.AddCommand(printdockerfile.New(c))
+
+	return cmd
+}
+
+// result is the outcome of a build.
+type result struct {
+	Slug        string `json:"slug" yaml:"slug"`
+	Image       string `json:"image" yaml:"image"`
+	ImageDigest string `json:"imageDigest,omitempty" yaml:"imageDigest,omitempty"`
+	BuildID     string `json:"buildID,omitempty" yaml:"buildID,omitempty"`
+}
+
+func run(ctx context.Context, cfg config) error {
+	client := cfg.root.Client
+
+	compression, err := build.ParseCompression(cfg.compression, cfg.compressionLevel)
+	if err != nil {
+		return err
+	}
+	// With --local, $AP_BUILDER_SIZE and $AP_BUILDER_REGION are ignored.
+	var builder *api.BuilderOptions
+	if !cfg.local {
+		if builder, err = build.ParseBuilder(cfg.builderSize, cfg.builderRegion, cfg.buildTimeout); err != nil {
+			return err
+		}
+	}
+	if cfg.healthcheck && !cfg.local {
+		return errors.New("--healthcheck only applies to --local builds")
+	}
+	level, err := build.ParseSeverity(cfg.auditLevel)
+	if err != nil {
+		return err
+	}
+	var audit *build.AuditOptions
+	if cfg.auditDeps || level != "" {
+		audit = &build.AuditOptions{FailOn: level}
+	}
+
+	dir, err := taskdir.Open(cfg.file, definitions.IsTaskDef(cfg.file))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	def, err := dir.ReadAnyDefinition()
+	if err != nil {
+		return err
+	}
+
+	kind, _, err := def.GetKindAndOptions()
+	if err != nil {
+		return err
+	}
+	if ok, err := libBuild.NeedsBuilding(kind); err != nil {
+		return err
+	} else if !ok {
+		return errors.Errorf("%s tasks are not built", kind)
+	}
+
+	task, err := client.GetTask(ctx, def.GetSlug())
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	resp, err := build.Run(ctx, build.NewDeployer(), build.Request{
+		Local:  cfg.local,
+		Client: client,
+		Root:   dir.DefinitionRootPath(),
+		Def:    def,
+		TaskID: task.ID,
+		// Match deploy, which does not shim tasks defined in airplane.yml files.
+		Shim:             definitions.IsTaskDef(cfg.file),
+		BuildOnly:        true,
+		Compression:      compression,
+		CompressionLevel: cfg.compressionLevel,
+		Builder:          builder,
+		Audit:            audit,
+		Healthcheck:      cfg.healthcheck,
+	})
+	if err != nil {
+		return err
+	}
+
+	elapsed := time.Since(start)
+
+	res := result{
+		Slug:        task.Slug,
+		Image:       resp.ImageURL,
+		ImageDigest: resp.ImageDigest,
+		BuildID:     resp.BuildID,
+	}
+	print.Print(res, func() {
+		logger.Log("Built %s in %s", logger.Bold(res.Slug), utils.FormatDuration(elapsed))
+		// Print the image to stdout so that it can be captured by scripts.
+		fmt.Println(res.Image)
+		if res.ImageDigest != "" {
+			logger.Log("Digest: %s", res.ImageDigest)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,94 @@
+package printdockerfile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	libBuild "github.com/airplanedev/lib/pkg/build"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	file string
+	shim bool
+}
+
+// New returns a new print-dockerfile command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg config
+
+	cmd := &cobra.Command{
+		Use:   "print-dockerfile ./path/to/definition",
+		Short: "Print the Dockerfile used to build a task",
+		Long: heredoc.Doc(`
+			Print the Dockerfile that Airplane generates to build a task, without
+			running the build.
+
+			This is useful for understanding and debugging what the builder will do
+			for a task's kind and options.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane build print-dockerfile ./my_task.task.yaml
+			$ airplane build print-dockerfile ./airplane.yml
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.file = args[0]
+			if !cmd.Flags().Changed("shim") {
+				// Match deploy, which does not shim tasks defined in airplane.yml files.
+				cfg.shim = definitions.IsTaskDef(cfg.file)
+			}
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+
+	cmd.Flags().BoolVar(&cfg.shim, "shim", false, "Include the Airplane shim in the generated Dockerfile. Defaults to the behavior used by deploy.")
+
+	return cmd
+}
+
+func run(ctx context.Context, cfg config) error {
+	dir, err := taskdir.Open(cfg.file, definitions.IsTaskDef(cfg.file))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	def, err := dir.ReadAnyDefinition()
+	if err != nil {
+		return err
+	}
+
+	kind, options, err := def.GetKindAndOptions()
+	if err != nil {
+		return err
+	}
+	if ok, err := libBuild.NeedsBuilding(kind); err != nil {
+		return err
+	} else if !ok {
+		return errors.Errorf("%s tasks are not built, so they do not have a Dockerfile", kind)
+	}
+
+	if cfg.shim {
+		options["shim"] = "true"
+	}
+
+	dockerfile, err := libBuild.BuildDockerfile(libBuild.DockerfileConfig{
+		Builder: string(kind),
+		Root:    dir.DefinitionRootPath(),
+		Options: options,
+	})
+	if err != nil {
+		return errors.Wrap(err, "generating Dockerfile")
+	}
+
+	// Print the Dockerfile to stdout so that it can be piped, f.e. into `docker build -f -`.
+	fmt.Println(dockerfile)
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/cache/gc"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new cobra command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage local CLI state",
+		Long:  "Manage the workspaces and caches the CLI keeps on disk.",
+		Example: heredoc.Doc(`
+			$ airplane cache gc
+		`),
+	}
+
+	cmd.AddCommand(gc.New(c))
+
+	return cmd
+}
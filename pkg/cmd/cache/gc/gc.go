@@ -0,0 +1,61 @@
+package gc
+
+import (
+	"context"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/workspace"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	maxAge time.Duration
+}
+
+// New returns a new gc command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg config
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove stale build workspaces",
+		Long: heredoc.Doc(`
+			Remove build workspaces left behind by previous CLI invocations.
+
+			Stale workspaces are also removed automatically when the CLI starts.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane cache gc
+			$ airplane cache gc --max-age 1h
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+
+	cmd.Flags().DurationVar(&cfg.maxAge, "max-age", workspace.DefaultMaxAge, "Remove workspaces that have not been used within this duration.")
+
+	return cmd
+}
+
+func run(ctx context.Context, cfg config) error {
+	removed, err := workspace.GC(cfg.maxAge)
+	for _, path := range removed {
+		logger.Debug("Removed %s", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(removed) == 0 {
+		logger.Log("No stale workspaces to remove.")
+	} else {
+		logger.Log("Removed %d stale workspace(s) from %s.", len(removed), workspace.Dir())
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/cmd/config/task"
+	"github.com/airplanedev/cli/pkg/cmd/configs"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new config command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage local CLI preferences",
+		Long:  "Manage the preferences the CLI keeps on this machine. Config variables are managed with airplane configs.",
+		Example: heredoc.Doc(`
+			$ airplane config task my_task
+			$ airplane config task my_task --clear
+		`),
+	}
+
+	cmd.AddCommand(task.New(c))
+
+	// `airplane config` used to be an alias of `airplane configs`, so its
+	// subcommands keep working here, hidden.
+	legacy := configs.New(c)
+	for _, sub := range legacy.Commands() {
+		legacy.RemoveCommand(sub)
+		sub.Hidden = true
+		sub.Deprecated = fmt.Sprintf("use airplane configs %s instead.", sub.Name())
+		sub.PreRunE = func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}
+		cmd.AddCommand(sub)
+	}
+
+	return cmd
+}
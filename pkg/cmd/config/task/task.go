@@ -0,0 +1,66 @@
+package task
+
+import (
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskprefs"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new task command.
+func New(c *cli.Config) *cobra.Command {
+	var clear bool
+	cmd := &cobra.Command{
+		Use:   "task <slug>",
+		Short: "Show or forget the flags remembered for a task",
+		Long: heredoc.Doc(`
+			Show the flags remembered for executing a task, or forget them with --clear.
+
+			When a task is executed with --output, or with --log-sink and --remember,
+			they are remembered and used the next time it's executed, or its logs
+			are printed, without them.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane config task my_task
+			$ airplane config task my_task --clear
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(args[0], clear)
+		},
+	}
+	cmd.Flags().BoolVar(&clear, "clear", false, "Forget the task's remembered flags.")
+	return cmd
+}
+
+func run(slug string, clear bool) error {
+	if clear {
+		if err := taskprefs.Clear(slug); err != nil {
+			return err
+		}
+		logger.Log("Forgot the flags remembered for %s.", logger.Bold(slug))
+		return nil
+	}
+
+	prefs, err := taskprefs.Get(slug)
+	if err != nil {
+		return err
+	}
+	print.Print(prefs, func() {
+		if prefs.IsEmpty() {
+			logger.Log("No flags are remembered for %s.", logger.Bold(slug))
+			return
+		}
+		if prefs.Output != "" {
+			logger.Log("--output %s", prefs.Output)
+		}
+		if len(prefs.LogSinks) > 0 {
+			logger.Log("--log-sink %s", strings.Join(prefs.LogSinks, " --log-sink "))
+		}
+	})
+	return nil
+}
@@ -0,0 +1,244 @@
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/configs"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+type config struct {
+	root   *cli.Config
+	file   string
+	dryRun bool
+}
+
+// New returns a new apply command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "apply <manifest.yaml>",
+		Short: "Set config variables in bulk from a YAML manifest",
+		Long: heredoc.Doc(`
+			Set every config variable listed in a YAML manifest.
+
+			Each entry has a name, and optionally a tag, a value, an environment
+			variable to read the value from (fromEnv) and whether it is a secret.
+			Values that are neither inline nor in the environment are prompted for.
+			Configs that already have the given value are left alone.
+		`),
+		Example: heredoc.Doc(`
+			$ cat configs.yaml
+			configs:
+			  - name: db_url
+			    tag: prod
+			    secret: true
+			    fromEnv: PROD_DB_URL
+			  - name: region
+			    value: us-west-2
+
+			# Review the changes without making them
+			$ airplane configs apply configs.yaml --dry-run
+
+			$ airplane configs apply configs.yaml
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.file = args[0]
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false, "Print the changes without making them.")
+	return cmd
+}
+
+type changeKind int
+
+const (
+	unchanged changeKind = iota
+	create
+	update
+)
+
+type change struct {
+	kind   changeKind
+	nt     configs.NameTag
+	secret bool
+	// value is the value to set, or nil if it will be prompted for.
+	value   *string
+	current api.Config
+}
+
+func run(ctx context.Context, cfg config) error {
+	m, err := configs.ReadManifest(cfg.file)
+	if err != nil {
+		return err
+	}
+	return Apply(ctx, cfg.root.Client, m, Options{
+		Source: cfg.file,
+		DryRun: cfg.dryRun,
+	})
+}
+
+// Options configures Apply.
+type Options struct {
+	// Source names where the configs came from, such as a file, in messages.
+	Source string
+	// DryRun prints the changes without making them.
+	DryRun bool
+	// Confirm asks before making the changes.
+	Confirm bool
+}
+
+// maxConcurrentSets is how many configs Apply sets at once.
+const maxConcurrentSets = 8
+
+// Apply sets every config in m that differs from the one stored by the API,
+// after printing a preview of the changes.
+func Apply(ctx context.Context, client api.APIClient, m configs.Manifest, opts Options) error {
+	if len(m.Configs) == 0 {
+		logger.Log("No configs found in %s.", opts.Source)
+		return nil
+	}
+
+	var changes []change
+	var counts [3]int
+	for _, e := range m.Configs {
+		ch, err := plan(ctx, client, e)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, ch)
+		counts[ch.kind]++
+		logger.Log("%s", describe(ch))
+	}
+	logger.Log("\n%d to create, %d to update, %d unchanged.", counts[create], counts[update], counts[unchanged])
+
+	if opts.DryRun {
+		logger.Log("Dry run: no configs were changed.")
+		return nil
+	}
+	if counts[create]+counts[update] == 0 {
+		return nil
+	}
+	if opts.Confirm {
+		if !utils.CanPrompt() {
+			return errors.New("cannot confirm the changes without a terminal: pass --yes to apply them")
+		}
+		if ok, err := utils.Confirm("Apply these changes?"); err != nil {
+			return err
+		} else if !ok {
+			return errors.New("cancelled")
+		}
+	}
+
+	// Prompts are asked one by one, before any config is set.
+	var reqs []api.SetConfigRequest
+	for _, ch := range changes {
+		if ch.kind == unchanged {
+			continue
+		}
+		value := ch.value
+		if value == nil {
+			if !utils.CanPrompt() {
+				return errors.Errorf("%s has no value: set value or fromEnv in %s", configs.JoinName(ch.nt), opts.Source)
+			}
+			v, err := configs.ReadValueFromPrompt(fmt.Sprintf("Value for %s", configs.JoinName(ch.nt)), ch.secret)
+			if err != nil {
+				return err
+			}
+			value = &v
+		}
+		reqs = append(reqs, api.SetConfigRequest{
+			Name:     ch.nt.Name,
+			Tag:      ch.nt.Tag,
+			Value:    *value,
+			IsSecret: ch.secret,
+		})
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentSets)
+	for _, req := range reqs {
+		req := req
+		eg.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := client.SetConfig(ctx, req); err != nil {
+				return errors.Wrapf(err, "set config %s", configs.JoinName(configs.NameTag{Name: req.Name, Tag: req.Tag}))
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	logger.Log("Applied %d configs.", len(reqs))
+	return nil
+}
+
+// plan compares e against the config currently stored by the API.
+func plan(ctx context.Context, client api.APIClient, e configs.ManifestEntry) (change, error) {
+	ch := change{nt: e.NameTag(), secret: e.Secret}
+	value, ok, err := e.ResolveValue()
+	if err != nil {
+		return change{}, err
+	}
+	if ok {
+		ch.value = &value
+	}
+
+	// Secret values are fetched only to compare them and are never printed.
+	resp, err := client.GetConfig(ctx, api.GetConfigRequest{
+		Name:       ch.nt.Name,
+		Tag:        ch.nt.Tag,
+		ShowSecret: true,
+	})
+	if apiErr, ok := errors.Cause(err).(api.Error); ok && apiErr.Code == 404 {
+		ch.kind = create
+		return ch, nil
+	} else if err != nil {
+		return change{}, errors.Wrapf(err, "get config %s", configs.JoinName(ch.nt))
+	}
+
+	ch.current = resp.Config
+	if ch.value != nil && *ch.value == ch.current.Value && ch.secret == ch.current.IsSecret {
+		ch.kind = unchanged
+	} else {
+		ch.kind = update
+	}
+	return ch, nil
+}
+
+func describe(ch change) string {
+	name := logger.Bold(configs.JoinName(ch.nt))
+	switch ch.kind {
+	case create:
+		return fmt.Sprintf("%s %s = %s", logger.Green("+"), name, displayValue(ch.value, ch.secret))
+	case update:
+		old := displayValue(&ch.current.Value, ch.current.IsSecret)
+		return fmt.Sprintf("%s %s: %s -> %s", logger.Yellow("~"), name, old, displayValue(ch.value, ch.secret))
+	default:
+		return logger.Gray("  %s (unchanged)", configs.JoinName(ch.nt))
+	}
+}
+
+// displayValue avoids printing back secrets.
+func displayValue(v *string, secret bool) string {
+	switch {
+	case v == nil:
+		return logger.Gray("<prompted>")
+	case secret:
+		return "<secret value>"
+	default:
+		return fmt.Sprintf("%q", *v)
+	}
+}
@@ -4,21 +4,28 @@ import (
 	"github.com/MakeNowJust/heredoc"
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/cmd/configs/apply"
+	"github.com/airplanedev/cli/pkg/cmd/configs/export"
 	"github.com/airplanedev/cli/pkg/cmd/configs/get"
+	"github.com/airplanedev/cli/pkg/cmd/configs/importcmd"
 	"github.com/airplanedev/cli/pkg/cmd/configs/set"
+	"github.com/airplanedev/cli/pkg/cmd/configs/usage"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/spf13/cobra"
 )
 
 func New(c *cli.Config) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "configs",
-		Short:   "Manage config variables",
-		Long:    "Manage config variables",
-		Aliases: []string{"config"},
+		Use:   "configs",
+		Short: "Manage config variables",
+		Long:  "Manage config variables",
 		Example: heredoc.Doc(`
 			$ airplane configs set my_database_url postgresql://my_database
 			$ airplane configs get my_config_name
+			$ airplane configs apply configs.yaml --dry-run
+			$ airplane configs usage my_database_url
+			$ airplane configs export --format dotenv > .env
+			$ airplane configs import .env
 		`),
 		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
 			return login.EnsureLoggedIn(cmd.Root().Context(), c)
@@ -27,6 +34,10 @@ func New(c *cli.Config) *cobra.Command {
 
 	cmd.AddCommand(set.New(c))
 	cmd.AddCommand(get.New(c))
+	cmd.AddCommand(apply.New(c))
+	cmd.AddCommand(usage.New(c))
+	cmd.AddCommand(export.New(c))
+	cmd.AddCommand(importcmd.New(c))
 
 	return cmd
 }
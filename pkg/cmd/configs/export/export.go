@@ -0,0 +1,78 @@
+package export
+
+import (
+	"context"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/configs"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root   *cli.Config
+	format string
+	tag    string
+}
+
+// New returns a new export command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export config variables to a .env file",
+		Long: heredoc.Doc(`
+			Print the config variables with the given tag, or without a tag by
+			default, in dotenv format. Secrets are included in plain text and marked
+			with a "# secret" comment, so that "airplane configs import" sets them
+			as secrets again.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane configs export --format dotenv > .env
+			$ airplane configs export --format dotenv --tag prod > prod.env
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().StringVar(&cfg.format, "format", "dotenv", `The format to export to. Only "dotenv" is supported.`)
+	cmd.Flags().StringVar(&cfg.tag, "tag", "", "Export the config variables with this tag.")
+	return cmd
+}
+
+func run(ctx context.Context, cfg config) error {
+	if cfg.format != "dotenv" {
+		return errors.Errorf("unsupported format %q: expected dotenv", cfg.format)
+	}
+
+	resp, err := cfg.root.Client.ListConfigs(ctx, api.ListConfigsRequest{ShowSecret: true})
+	if err != nil {
+		return errors.Wrap(err, "list configs")
+	}
+
+	var cfgs []api.Config
+	for _, c := range resp.Configs {
+		if c.Tag != cfg.tag {
+			continue
+		}
+		if !configs.IsDotenvName(c.Name) {
+			logger.Warning("Skipping %s: its name can't be written to a .env file.", configs.JoinName(configs.NameTag{Name: c.Name, Tag: c.Tag}))
+			continue
+		}
+		cfgs = append(cfgs, c)
+	}
+	if len(cfgs) == 0 {
+		logger.Log("No configs to export.")
+		return nil
+	}
+
+	if _, err := os.Stdout.Write(configs.FormatDotenv(cfgs)); err != nil {
+		return errors.Wrap(err, "writing configs")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package importcmd
+
+import (
+	"context"
+	"io/ioutil"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/configs/apply"
+	"github.com/airplanedev/cli/pkg/configs"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root      *cli.Config
+	file      string
+	tag       string
+	secret    bool
+	dryRun    bool
+	assumeYes bool
+}
+
+// New returns a new import command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "import <file.env>",
+		Short: "Set config variables from a .env file",
+		Long: heredoc.Doc(`
+			Set a config variable for every NAME=value line in a .env file, after
+			previewing the changes and asking for confirmation.
+
+			Lines ending in a "# secret" comment are set as secrets, as are all of
+			them with --secret. Configs that already have the given value are left
+			alone.
+		`),
+		Example: heredoc.Doc(`
+			$ cat .env
+			REGION=us-west-2
+			DB_URL="postgresql://my_database" # secret
+
+			$ airplane configs import .env
+			$ airplane configs import prod.env --tag prod --yes
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.file = args[0]
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().StringVar(&cfg.tag, "tag", "", "Set the config variables with this tag.")
+	cmd.Flags().BoolVar(&cfg.secret, "secret", false, "Set every config variable as a secret.")
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false, "Print the changes without making them.")
+	cmd.Flags().BoolVarP(&cfg.assumeYes, "yes", "y", false, "Apply the changes without asking for confirmation.")
+	return cmd
+}
+
+func run(ctx context.Context, cfg config) error {
+	buf, err := ioutil.ReadFile(cfg.file)
+	if err != nil {
+		return errors.Wrap(err, "reading file")
+	}
+	m, err := configs.ParseDotenv(buf)
+	if err != nil {
+		return errors.Wrap(err, cfg.file)
+	}
+	for i := range m.Configs {
+		m.Configs[i].Tag = cfg.tag
+		m.Configs[i].Secret = m.Configs[i].Secret || cfg.secret
+	}
+
+	return apply.Apply(ctx, cfg.root.Client, m, apply.Options{
+		Source:  cfg.file,
+		DryRun:  cfg.dryRun,
+		Confirm: !cfg.assumeYes,
+	})
+}
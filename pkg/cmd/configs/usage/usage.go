@@ -0,0 +1,145 @@
+package usage
+
+import (
+	"context"
+	"os"
+	"sort"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/configs"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root  *cli.Config
+	name  string
+	paths []string
+}
+
+// New returns a new usage command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "usage <name> [paths...]",
+		Short: "List the tasks that use a config variable",
+		Long: heredoc.Doc(`
+			List the deployed tasks, and the task definitions in the given files and
+			directories or the current directory, whose environment variables use a
+			config variable. Check this before changing or deleting a config, to know
+			which tasks it affects.
+
+			Without a tag, uses of the config with any tag are listed.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane configs usage my_database_url
+			$ airplane configs usage my_database_url:prod ./tasks
+		`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.name = args[0]
+			cfg.paths = args[1:]
+			if len(cfg.paths) == 0 {
+				cfg.paths = []string{"."}
+			}
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	return cmd
+}
+
+// use is an environment variable that references a config variable.
+type use struct {
+	// Task is the slug of the deployed task, and File the task definition,
+	// that the environment variable is set in.
+	Task   string `json:"task,omitempty" yaml:"task,omitempty"`
+	File   string `json:"file,omitempty" yaml:"file,omitempty"`
+	EnvVar string `json:"envVar" yaml:"envVar"`
+	Config string `json:"config" yaml:"config"`
+}
+
+func run(ctx context.Context, cfg config) error {
+	nt, err := configs.ParseName(cfg.name)
+	if err == configs.ErrInvalidConfigName {
+		return errors.Errorf("invalid config name: %s - expected my_config or my_config:tag", cfg.name)
+	}
+
+	resp, err := cfg.root.Client.ListTasks(ctx, api.ListTasksRequest{})
+	if err != nil {
+		return errors.Wrap(err, "listing tasks")
+	}
+	uses := []use{}
+	for _, task := range resp.Tasks {
+		for _, u := range envUses(nt, task.Env) {
+			u.Task = task.Slug
+			uses = append(uses, u)
+		}
+	}
+
+	files, err := taskdir.FindDefinitions(cfg.paths)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		def, err := taskdir.ReadAnyDefinitionFile(file)
+		if err != nil {
+			logger.Warning("Skipping %s: %v", file, err)
+			continue
+		}
+		env, err := def.GetEnv()
+		if err != nil {
+			logger.Warning("Skipping %s: %v", file, err)
+			continue
+		}
+		for _, u := range envUses(nt, env) {
+			u.File = file
+			uses = append(uses, u)
+		}
+	}
+
+	print.Print(uses, func() {
+		if len(uses) == 0 {
+			logger.Log("No tasks use config %s.", logger.Bold(cfg.name))
+			return
+		}
+		tw := tablewriter.NewWriter(os.Stdout)
+		tw.SetBorder(false)
+		tw.SetAutoWrapText(false)
+		tw.SetHeader([]string{"task", "env var", "config"})
+		for _, u := range uses {
+			source := u.Task
+			if u.File != "" {
+				source = u.File
+			}
+			tw.Append([]string{source, u.EnvVar, u.Config})
+		}
+		tw.Render()
+	})
+	return nil
+}
+
+// envUses returns the environment variables in env that reference the config
+// nt, sorted by name. If nt has no tag, references with any tag match.
+func envUses(nt configs.NameTag, env api.TaskEnv) []use {
+	var uses []use
+	for name, v := range env {
+		if v.Config == nil {
+			continue
+		}
+		ref, err := configs.ParseName(*v.Config)
+		if err != nil || ref.Name != nt.Name || (nt.Tag != "" && ref.Tag != nt.Tag) {
+			continue
+		}
+		uses = append(uses, use{EnvVar: name, Config: *v.Config})
+	}
+	sort.Slice(uses, func(i, j int) bool {
+		return uses[i].EnvVar < uses[j].EnvVar
+	})
+	return uses
+}
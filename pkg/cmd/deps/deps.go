@@ -0,0 +1,97 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/deps"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new deps command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps <slug>",
+		Short: "Show what a task depends on",
+		Long: heredoc.Doc(`
+			Show the configs, resources and tasks a task depends on, following
+			task dependencies transitively.
+
+			Dependencies include configs referenced by the task's env, resources
+			attached to it, and anything listed under dependencies in its definition.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane deps my_task
+			$ airplane deps my_task -o json
+		`),
+		Args: cobra.ExactArgs(1),
+		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c, args[0])
+		},
+	}
+	return cmd
+}
+
+func run(ctx context.Context, c *cli.Config, slug string) error {
+	var client = c.Client
+
+	node, err := deps.Graph(ctx, client.GetTask, deps.ResourceNames(ctx, client), slug)
+	if err != nil {
+		return err
+	}
+
+	print.Print(node, func() {
+		printNode(node, "", "")
+	})
+	return nil
+}
+
+// printNode prints node as a tree, prefixing its first line with first and
+// the rest with rest.
+func printNode(node *deps.Node, first, rest string) {
+	label := logger.Bold(node.Slug)
+	switch {
+	case node.Cycle:
+		label += logger.Gray(" (cycle)")
+	case node.Missing:
+		label += logger.Red(" (missing)")
+	}
+	fmt.Fprintln(os.Stdout, first+label)
+
+	type line struct {
+		text  string
+		child *deps.Node
+	}
+	var lines []line
+	for _, c := range node.Configs {
+		lines = append(lines, line{text: logger.Gray("config ") + c})
+	}
+	for _, r := range node.Resources {
+		lines = append(lines, line{text: logger.Gray("resource ") + r})
+	}
+	for _, child := range node.Dependencies {
+		lines = append(lines, line{child: child})
+	}
+
+	for i, l := range lines {
+		branch, indent := "├── ", "│   "
+		if i == len(lines)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if l.child != nil {
+			printNode(l.child, rest+branch+logger.Gray("task "), rest+indent)
+		} else {
+			fmt.Fprintln(os.Stdout, rest+branch+l.text)
+		}
+	}
+}
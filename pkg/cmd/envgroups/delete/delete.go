@@ -0,0 +1,38 @@
+package delete
+
+import (
+	"context"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new delete command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <name>...",
+		Short: "Deletes one or more env groups by name",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c, args)
+		},
+	}
+	return cmd
+}
+
+// Run runs the delete command.
+func run(ctx context.Context, c *cli.Config, names []string) error {
+	var client = c.Client
+
+	for _, name := range names {
+		logger.Log("  Deleting env group %s...", logger.Red(name))
+		if err := client.DeleteEnvGroup(ctx, api.DeleteEnvGroupRequest{Name: name}); err != nil {
+			return errors.Wrapf(err, "deleting env group %s", name)
+		}
+	}
+	logger.Log("  Done.")
+	return nil
+}
@@ -0,0 +1,39 @@
+package envgroups
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/cmd/envgroups/delete"
+	"github.com/airplanedev/cli/pkg/cmd/envgroups/get"
+	"github.com/airplanedev/cli/pkg/cmd/envgroups/list"
+	"github.com/airplanedev/cli/pkg/cmd/envgroups/set"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new cobra command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "envgroups",
+		Short:   "Manage env groups",
+		Long:    "Manage groups of env vars that can be shared across tasks by referencing them in a task definition's envGroups field.",
+		Aliases: []string{"envgroup"},
+		Example: heredoc.Doc(`
+			$ airplane envgroups set datadog DD_SITE=datadoghq.com --config DD_API_KEY=datadog_api_key
+			$ airplane envgroups list
+			$ airplane envgroups get datadog
+			$ airplane envgroups delete datadog
+		`),
+		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}),
+	}
+
+	cmd.AddCommand(list.New(c))
+	cmd.AddCommand(get.New(c))
+	cmd.AddCommand(set.New(c))
+	cmd.AddCommand(delete.New(c))
+
+	return cmd
+}
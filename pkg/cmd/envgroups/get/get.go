@@ -0,0 +1,35 @@
+package get
+
+import (
+	"context"
+
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new get command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <name>",
+		Short: "Get an env group's env vars",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c, args[0])
+		},
+	}
+	return cmd
+}
+
+// Run runs the get command.
+func run(ctx context.Context, c *cli.Config, name string) error {
+	var client = c.Client
+
+	resp, err := client.GetEnvGroup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	print.EnvGroup(resp.EnvGroup)
+	return nil
+}
@@ -0,0 +1,113 @@
+package set
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/configs"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	name    string
+	values  []string
+	configs []string
+	unset   []string
+}
+
+// New returns a new set command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg config
+
+	cmd := &cobra.Command{
+		Use:   "set <name> [KEY=VALUE...]",
+		Short: "Create or update an env group",
+		Long: heredoc.Doc(`
+			Create or update an env group.
+
+			Env vars are merged into the group's existing env vars, if any.
+		`),
+		Example: heredoc.Doc(`
+			# Set literal values
+			$ airplane envgroups set aws-ro AWS_REGION=us-west-2
+
+			# Reference a config variable
+			$ airplane envgroups set aws-ro --config AWS_SECRET_ACCESS_KEY=aws_secret_key
+
+			# Remove an env var from the group
+			$ airplane envgroups set aws-ro --unset AWS_REGION
+		`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.name = args[0]
+			cfg.values = args[1:]
+			return run(cmd.Root().Context(), c, cfg)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&cfg.configs, "config", nil, "Set KEY to reference a config variable, in the form KEY=config_name[:tag]. Can be repeated.")
+	cmd.Flags().StringArrayVar(&cfg.unset, "unset", nil, "Remove KEY from the env group. Can be repeated.")
+
+	return cmd
+}
+
+// Run runs the set command.
+func run(ctx context.Context, c *cli.Config, cfg config) error {
+	var client = c.Client
+
+	env := api.TaskEnv{}
+	resp, err := client.GetEnvGroup(ctx, cfg.name)
+	if _, ok := err.(*api.EnvGroupMissingError); ok {
+		logger.Debug("Env group %s does not exist yet, creating it", cfg.name)
+	} else if err != nil {
+		return errors.Wrap(err, "getting env group")
+	} else if resp.EnvGroup.Env != nil {
+		env = resp.EnvGroup.Env
+	}
+
+	for _, kv := range cfg.values {
+		k, v, err := splitKeyValue(kv)
+		if err != nil {
+			return err
+		}
+		env[k] = api.EnvVarValue{Value: &v}
+	}
+
+	for _, kv := range cfg.configs {
+		k, v, err := splitKeyValue(kv)
+		if err != nil {
+			return err
+		}
+		if _, err := configs.ParseName(v); err != nil {
+			return errors.Errorf("invalid config name: %s - expected my_config or my_config:tag", v)
+		}
+		env[k] = api.EnvVarValue{Config: &v}
+	}
+
+	for _, k := range cfg.unset {
+		delete(env, k)
+	}
+
+	if err := client.SetEnvGroup(ctx, api.SetEnvGroupRequest{
+		Name: cfg.name,
+		Env:  env,
+	}); err != nil {
+		return errors.Wrap(err, "setting env group")
+	}
+
+	logger.Log("Updated env group %s (%d env vars).", logger.Bold(cfg.name), len(env))
+	return nil
+}
+
+func splitKeyValue(kv string) (string, string, error) {
+	parts := strings.SplitN(kv, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", errors.Errorf("invalid env var %q: expected KEY=VALUE", kv)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,160 @@
+package editorconfig
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/ojson"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// schemaDir is where schemas are written, relative to the workspace.
+	schemaDir = ".vscode/airplane"
+
+	taskSchemaFile     = "task.schema.json"
+	airplaneSchemaFile = "airplane.schema.json"
+)
+
+// New returns a new editor-config command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "editor-config [dir]",
+		Short: "Configure VS Code to validate task definitions",
+		Long: heredoc.Doc(`
+			Writes the JSON Schemas that the CLI validates task definitions with to .vscode/airplane,
+			and points .vscode/settings.json at them, so that VS Code completes and checks
+			*.task.yaml, *.task.json and airplane.yml files.
+
+			YAML support requires the Red Hat YAML extension (redhat.vscode-yaml).
+		`),
+		Example: heredoc.Doc(`
+			$ airplane generate editor-config
+			$ airplane generate editor-config ./my/workspace
+		`),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			return run(dir)
+		},
+	}
+	return cmd
+}
+
+func run(dir string) error {
+	if err := os.MkdirAll(filepath.Join(dir, schemaDir), 0755); err != nil {
+		return errors.Wrap(err, "creating schema directory")
+	}
+
+	airplaneSchema, err := definitions.Schema()
+	if err != nil {
+		return errors.Wrap(err, "generating schema")
+	}
+	for _, s := range []struct {
+		file   string
+		schema []byte
+	}{
+		{taskSchemaFile, definitions.Schema_0_3()},
+		{airplaneSchemaFile, airplaneSchema},
+	} {
+		path := filepath.Join(dir, schemaDir, s.file)
+		if err := ioutil.WriteFile(path, s.schema, 0644); err != nil {
+			return errors.Wrap(err, "writing schema")
+		}
+		logger.Step("Wrote %s", path)
+	}
+
+	path := filepath.Join(dir, ".vscode", "settings.json")
+	settings, err := readSettings(path)
+	if err != nil {
+		return err
+	}
+	configure(settings)
+
+	buf, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling settings")
+	}
+	if err := ioutil.WriteFile(path, append(buf, '\n'), 0644); err != nil {
+		return errors.Wrap(err, "writing settings")
+	}
+	logger.Step("Updated %s", path)
+	return nil
+}
+
+// readSettings reads VS Code settings, keeping the order of existing keys.
+func readSettings(path string) (*ojson.Object, error) {
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ojson.NewObject(), nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "reading settings")
+	}
+
+	var v ojson.Value
+	if err := json.Unmarshal(buf, &v); err != nil {
+		// VS Code allows comments in settings, which we can't preserve.
+		return nil, errors.Wrapf(err, "parsing %s: remove any comments or trailing commas and try again", path)
+	}
+	obj, ok := v.V.(*ojson.Object)
+	if !ok {
+		return nil, errors.Errorf("%s must contain a JSON object", path)
+	}
+	return obj, nil
+}
+
+// configure associates task definition files with their schemas, leaving
+// unrelated settings as they are.
+func configure(settings *ojson.Object) {
+	taskSchema := "./" + schemaDir + "/" + taskSchemaFile
+	airplaneSchema := "./" + schemaDir + "/" + airplaneSchemaFile
+
+	yamlSchemas := object(settings, "yaml.schemas")
+	yamlSchemas.Set(taskSchema, []interface{}{"*.task.yaml", "*.task.yml"})
+	yamlSchemas.Set(airplaneSchema, []interface{}{"airplane.yml", "airplane.yaml"})
+
+	// json.schemas is a list, so replace any entry from a previous run.
+	var jsonSchemas []interface{}
+	if v, ok := settings.Get("json.schemas"); ok {
+		existing, _ := v.([]interface{})
+		for _, s := range existing {
+			if o, ok := s.(*ojson.Object); ok {
+				if url, _ := o.Get("url"); url == taskSchema {
+					continue
+				}
+			}
+			jsonSchemas = append(jsonSchemas, s)
+		}
+	}
+	jsonSchemas = append(jsonSchemas, ojson.NewObject().
+		SetAndReturn("fileMatch", []interface{}{"*.task.json"}).
+		SetAndReturn("url", taskSchema))
+	settings.Set("json.schemas", jsonSchemas)
+
+	associations := object(settings, "files.associations")
+	associations.Set("*.task.yaml", "yaml")
+	associations.Set("*.task.yml", "yaml")
+	associations.Set("*.task.json", "json")
+}
+
+// object returns the object at key in settings, creating it if needed.
+func object(settings *ojson.Object, key string) *ojson.Object {
+	if v, ok := settings.Get(key); ok {
+		if o, ok := v.(*ojson.Object); ok {
+			return o
+		}
+	}
+	o := ojson.NewObject()
+	settings.Set(key, o)
+	return o
+}
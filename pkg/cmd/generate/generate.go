@@ -0,0 +1,24 @@
+package generate
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/generate/editorconfig"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new cobra command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate files for working with Airplane",
+		Long:  "Generate files for working with Airplane, such as editor configuration.",
+		Example: heredoc.Doc(`
+			$ airplane generate editor-config
+		`),
+	}
+
+	cmd.AddCommand(editorconfig.New(c))
+
+	return cmd
+}
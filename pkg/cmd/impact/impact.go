@@ -0,0 +1,84 @@
+package impact
+
+import (
+	"context"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/deps"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new impact command.
+func New(c *cli.Config) *cobra.Command {
+	var target deps.Target
+	cmd := &cobra.Command{
+		Use:   "impact",
+		Short: "List tasks affected by changing a config, resource or task",
+		Long: heredoc.Doc(`
+			List the tasks that depend on a config, resource or task, directly
+			or through the tasks they depend on.
+
+			A config name without a tag matches every tag of the config.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane impact --config db_url
+			$ airplane impact --config db_url:prod
+			$ airplane impact --resource warehouse
+			$ airplane impact --task fetch_orders
+		`),
+		Args: cobra.NoArgs,
+		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var n int
+			for _, v := range []string{target.Config, target.Resource, target.Task} {
+				if v != "" {
+					n++
+				}
+			}
+			if n != 1 {
+				return errors.New("expected exactly one of --config, --resource or --task")
+			}
+			return run(cmd.Root().Context(), c, target)
+		},
+	}
+	cmd.Flags().StringVar(&target.Config, "config", "", "Config name, optionally with a tag: name or name:tag.")
+	cmd.Flags().StringVar(&target.Resource, "resource", "", "Resource name.")
+	cmd.Flags().StringVar(&target.Task, "task", "", "Task slug.")
+	return cmd
+}
+
+func run(ctx context.Context, c *cli.Config, target deps.Target) error {
+	var client = c.Client
+
+	res, err := client.ListTasks(ctx, api.ListTasksRequest{})
+	if err != nil {
+		return errors.Wrap(err, "list tasks")
+	}
+
+	affected := deps.Impact(res.Tasks, deps.ResourceNames(ctx, client), target)
+	print.Print(affected, func() {
+		if len(affected) == 0 {
+			logger.Log("No tasks are affected.")
+			return
+		}
+		tw := tablewriter.NewWriter(os.Stdout)
+		tw.SetBorder(false)
+		tw.SetHeader([]string{"slug", "name", "via"})
+		for _, a := range affected {
+			tw.Append([]string{a.Slug, a.Name, a.Via})
+		}
+		tw.Render()
+	})
+	return nil
+}
@@ -0,0 +1,106 @@
+package lint
+
+import (
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	paths        []string
+	deprecations bool
+}
+
+// New returns a new lint command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg config
+	cmd := &cobra.Command{
+		Use:   "lint [paths...]",
+		Short: "Check task definitions for problems",
+		Long: heredoc.Doc(`
+			Check the task definitions in the given files and directories, or the current
+			directory, without deploying them.
+
+			Directories are searched for *.task.yaml, *.task.json and airplane.yml files.
+			Definitions that can't be read are reported, and with --deprecations, so are
+			the deprecated fields they use, so that they can be migrated before they're
+			removed.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane lint
+			$ airplane lint --deprecations ./tasks
+			$ airplane lint --deprecations -o json
+		`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.paths = args
+			if len(cfg.paths) == 0 {
+				cfg.paths = []string{"."}
+			}
+			return run(cfg)
+		},
+	}
+	cmd.Flags().BoolVar(&cfg.deprecations, "deprecations", false, "List the deprecated fields each definition uses, with what replaces them.")
+	return cmd
+}
+
+// finding is a problem with a task definition.
+type finding struct {
+	Path        string `json:"path" yaml:"path"`
+	Field       string `json:"field,omitempty" yaml:"field,omitempty"`
+	Replacement string `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func run(cfg config) error {
+	files, err := taskdir.FindDefinitions(cfg.paths)
+	if err != nil {
+		return err
+	}
+
+	findings := []finding{}
+	for _, file := range files {
+		def, err := taskdir.ReadAnyDefinitionFile(file)
+		if err != nil {
+			findings = append(findings, finding{Path: file, Error: err.Error()})
+			continue
+		}
+		if !cfg.deprecations {
+			continue
+		}
+		for _, d := range def.GetDeprecations() {
+			findings = append(findings, finding{Path: file, Field: d.Field, Replacement: d.Replacement})
+		}
+	}
+
+	print.Print(findings, func() {
+		if len(findings) == 0 {
+			logger.Log("Checked %d task definition(s), no problems found.", len(files))
+			return
+		}
+		tw := tablewriter.NewWriter(os.Stdout)
+		tw.SetBorder(false)
+		tw.SetAutoWrapText(false)
+		tw.SetHeader([]string{"path", "problem"})
+		for _, f := range findings {
+			problem := f.Error
+			if f.Field != "" {
+				problem = definitions.Deprecation{Field: f.Field, Replacement: f.Replacement}.String()
+			}
+			tw.Append([]string{f.Path, problem})
+		}
+		tw.Render()
+	})
+
+	if len(findings) > 0 {
+		return errors.Errorf("found %d problem(s) in %d task definition(s)", len(findings), len(files))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package open
+
+import (
+	"context"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new open command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "open [target]",
+		Short: "Open a task, run or page of the app in your browser",
+		Long: heredoc.Doc(`
+			Open a task, run or page of the app in your browser. Tasks and runs are
+			looked up first, so that a typo doesn't open a missing page.
+
+			Targets are:
+			  task/<slug>   a task
+			  run/<id>      a run
+			  settings      your team's settings
+			Without a target, the app's home page is opened.
+		`),
+		Example: heredoc.Doc(`
+			$ airplane open task/echo
+			$ airplane open run/run20220111zkx4bqj
+			$ airplane open settings
+		`),
+		Args: cobra.MaximumNArgs(1),
+		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var target string
+			if len(args) > 0 {
+				target = args[0]
+			}
+			return run(cmd.Root().Context(), c, target)
+		},
+	}
+	return cmd
+}
+
+func run(ctx context.Context, c *cli.Config, target string) error {
+	url, err := resolve(ctx, c.Client, target)
+	if err != nil {
+		return err
+	}
+
+	logger.Log("Opening %s", url)
+	if !utils.Open(url) {
+		logger.Log("Could not open browser - try copying and pasting the above URL")
+	}
+	return nil
+}
+
+// resolve returns the app URL of target, checking that the task or run it
+// names exists.
+func resolve(ctx context.Context, client api.APIClient, target string) (string, error) {
+	kind, id := target, ""
+	if i := strings.Index(target, "/"); i >= 0 {
+		kind, id = target[:i], target[i+1:]
+	}
+
+	switch kind {
+	case "":
+		return client.AppURL(""), nil
+
+	case "settings":
+		if id != "" {
+			return "", errors.Errorf("unknown target %q, expected settings", target)
+		}
+		return client.AppURL("/settings"), nil
+
+	case "task", "tasks":
+		if id == "" {
+			return "", errors.New("expected a task slug, such as task/my_task")
+		}
+		// Resolving the ID checks that the task exists, usually without a request.
+		if _, err := client.GetTaskID(ctx, id); err != nil {
+			return "", errors.Wrap(err, "get task")
+		}
+		return client.TaskURL(id), nil
+
+	case "run", "runs":
+		if id == "" {
+			return "", errors.New("expected a run ID, such as run/run20220111zkx4bqj")
+		}
+		if _, err := client.GetRun(ctx, id); err != nil {
+			return "", errors.Wrap(err, "get run")
+		}
+		return client.RunURL(id), nil
+
+	default:
+		return "", errors.Errorf("unknown target %q, expected task/<slug>, run/<id> or settings", target)
+	}
+}
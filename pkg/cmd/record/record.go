@@ -0,0 +1,88 @@
+package record
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new record command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "record <cassette> -- <command...>",
+		Short: "Record the API requests of a command",
+		Long: heredoc.Doc(`
+			Run a command of the CLI, recording its API requests and their responses
+			to a cassette file, which airplane replay runs the command against
+			without the API.
+
+			Cassettes don't include tokens, API keys, secret config values or the
+			signatures of upload URLs, but do include task definitions, run
+			parameters and logs: review a cassette before sharing it, such as in
+			a bug report.
+
+			Requests are also recorded when $AP_RECORD is set to a cassette's path.
+		`),
+		Example: heredoc.Doc(`
+			airplane record deploy.cassette -- tasks deploy ./tasks
+			airplane replay deploy.cassette -- tasks deploy ./tasks
+		`),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, command, err := ParseArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+			// Failed commands are recorded too, since they're what bug
+			// reports are about.
+			err = Exec(cmd.Root().Context(), "AP_RECORD", path, command)
+			logger.Log("Recorded to %s. Review it before sharing it: it may include parameters and logs.", path)
+			return err
+		},
+	}
+	return cmd
+}
+
+// ParseArgs returns the cassette and the command of `<cassette> -- <command...>`.
+func ParseArgs(cmd *cobra.Command, args []string) (string, []string, error) {
+	if dash := cmd.ArgsLenAtDash(); dash != 1 {
+		return "", nil, errors.Errorf("expected a cassette and a command: airplane %s <cassette> -- <command...>", cmd.Name())
+	}
+	return args[0], args[1:], nil
+}
+
+// Exec runs the CLI again with args, and with env set to the absolute path of
+// the cassette at path, connected to the CLI's standard input and output.
+func Exec(ctx context.Context, env, path string, args []string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return errors.Wrap(err, "resolving cassette")
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return errors.Wrap(err, "finding the CLI's executable")
+	}
+
+	c := exec.CommandContext(ctx, self, args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(), fmt.Sprintf("%s=%s", env, abs))
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			// The command has already reported its errors.
+			return exitErr
+		}
+		return errors.Wrap(err, "running command")
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package replay
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/record"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new replay command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay <cassette> -- <command...>",
+		Short: "Run a command against recorded API responses",
+		Long: heredoc.Doc(`
+			Run a command of the CLI against the API responses recorded in a
+			cassette by airplane record, without sending any requests, such as to
+			reproduce a bug report.
+
+			Requests are answered with the responses recorded for the same method
+			and URL, in the order they were recorded. Requests that weren't recorded
+			fail.
+
+			Requests are also replayed when $AP_REPLAY is set to a cassette's path.
+		`),
+		Example: heredoc.Doc(`
+			airplane replay deploy.cassette -- tasks deploy ./tasks
+		`),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, command, err := record.ParseArgs(cmd, args)
+			if err != nil {
+				return err
+			}
+			return record.Exec(cmd.Root().Context(), "AP_REPLAY", path, command)
+		},
+	}
+	return cmd
+}
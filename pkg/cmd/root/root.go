@@ -2,18 +2,36 @@ package root
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/airplanedev/cli/pkg/analytics"
 	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cassette"
 	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/agents"
 	"github.com/airplanedev/cli/pkg/cmd/apikeys"
 	"github.com/airplanedev/cli/pkg/cmd/auth"
 	"github.com/airplanedev/cli/pkg/cmd/auth/login"
 	"github.com/airplanedev/cli/pkg/cmd/auth/logout"
+	"github.com/airplanedev/cli/pkg/cmd/build"
+	"github.com/airplanedev/cli/pkg/cmd/cache"
+	"github.com/airplanedev/cli/pkg/cmd/config"
 	"github.com/airplanedev/cli/pkg/cmd/configs"
+	"github.com/airplanedev/cli/pkg/cmd/deps"
+	"github.com/airplanedev/cli/pkg/cmd/envgroups"
+	"github.com/airplanedev/cli/pkg/cmd/generate"
+	"github.com/airplanedev/cli/pkg/cmd/impact"
+	"github.com/airplanedev/cli/pkg/cmd/lint"
+	"github.com/airplanedev/cli/pkg/cmd/open"
+	"github.com/airplanedev/cli/pkg/cmd/record"
+	"github.com/airplanedev/cli/pkg/cmd/replay"
 	"github.com/airplanedev/cli/pkg/cmd/runs"
+	"github.com/airplanedev/cli/pkg/cmd/schedules"
 	"github.com/airplanedev/cli/pkg/cmd/tasks"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/deploy"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/dev"
@@ -22,15 +40,26 @@ import (
 	"github.com/airplanedev/cli/pkg/cmd/version"
 	"github.com/airplanedev/cli/pkg/conf"
 	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/plugins"
 	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/runqueue"
 	"github.com/airplanedev/cli/pkg/trap"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/airplanedev/cli/pkg/workspace"
 	isatty "github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // New returns a new root cobra command.
 func New() *cobra.Command {
 	var output string
+	var noRetry bool
+	var maxRetries int
+	var utc bool
+	var plain bool
+	var noInput bool
+	var transport api.TransportOptions
 	var cfg = &cli.Config{
 		Client: &api.Client{},
 	}
@@ -44,33 +73,73 @@ func New() *cobra.Command {
 			airplane deploy ./path/to/script
 		`),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			if c, err := conf.ReadDefault(); err == nil {
-				cfg.Client.Token = c.Tokens[cfg.Client.Host]
+			if token, err := conf.Credentials().Get(cfg.Client.Host); err == nil {
+				cfg.Client.Token = token
 			}
 			cfg.Client.APIKey = conf.GetAPIKey()
 			cfg.Client.TeamID = conf.GetTeamID()
+			logger.Redact(cfg.Client.Token, cfg.Client.APIKey)
+			replaying := conf.GetReplay() != ""
+			if replaying && cfg.Client.Token == "" && cfg.Client.APIKey == "" {
+				// Replayed requests are answered without credentials, so
+				// cassettes can be replayed without logging in.
+				cfg.Client.Token = "replay"
+			}
 			if err := analytics.Init(cfg); err != nil {
 				logger.Debug("error in analytics.Init: %v", err)
 			}
 
-			switch output {
-			case "json":
-				print.DefaultFormatter = print.NewJSONFormatter()
-			case "yaml":
-				print.DefaultFormatter = print.YAML{}
-			case "table":
-				print.DefaultFormatter = print.Table{}
-			default:
+			f, err := print.ParseFormatter(output)
+			if err != nil {
 				return errors.New("--output must be (json|yaml|table)")
 			}
+			print.DefaultFormatter = f
 
 			logger.EnableDebug = cfg.DebugMode
+			logger.SetPlain(plain)
+			utils.SetNoInput(noInput)
+			if utc {
+				utils.Location = time.UTC
+			}
+			if transport.Wrap, err = cassetteTransport(conf.GetRecord(), conf.GetReplay()); err != nil {
+				return err
+			}
+			if err := api.ConfigureTransport(transport); err != nil {
+				return err
+			}
+			if transport.InsecureSkipVerify {
+				logger.Warning("Server certificates are not being verified. Only use --insecure-skip-verify with servers you trust on a network you trust.")
+			}
+			if noRetry || replaying {
+				// Replayed responses don't change when retried.
+				api.SetMaxRetries(0)
+			} else {
+				api.SetMaxRetries(maxRetries)
+			}
 			trap.Printf = logger.Log
 
 			// Log the version every time the CLI is run with `--debug`. This aligns
 			// customer debugging output with a specific release of the CLI.
 			logger.Debug(version.Version())
 
+			// Clean up any build workspaces leaked by previous invocations, f.e.
+			// if the CLI crashed or was killed mid-deploy.
+			if removed, err := workspace.GC(workspace.DefaultMaxAge); err != nil {
+				logger.Debug("error cleaning up stale workspaces: %v", err)
+			} else if len(removed) > 0 {
+				logger.Debug("Removed %d stale workspace(s)", len(removed))
+			}
+
+			// Remind users of runs saved while offline, except while saving or
+			// submitting them.
+			if p := cmd.Parent(); p == nil || p.Name() != "runs" || (cmd.Name() != "enqueue" && cmd.Name() != "flush") {
+				if entries, err := runqueue.Default().List(cfg.Client.Host); err != nil {
+					logger.Debug("error reading run queue: %v", err)
+				} else if len(entries) > 0 {
+					logger.Banner(logger.Gray("You have %d saved run(s). Submit them with airplane runs flush", len(entries)))
+				}
+			}
+
 			return nil
 		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
@@ -90,7 +159,13 @@ func New() *cobra.Command {
 	cmd.SetVersionTemplate(version.Version() + "\n")
 
 	// Persistent flags, set globally to all commands.
-	cmd.PersistentFlags().StringVarP(&cfg.Client.Host, "host", "", api.Host, "Airplane API Host.")
+	cmd.PersistentFlags().StringVarP(&cfg.Client.Host, "host", "", api.Host, "Airplane API Host. Include a scheme, such as http://localhost:5000, to use one other than https.")
+	cmd.PersistentFlags().StringVar(&transport.Proxy, "proxy", "", "URL of a proxy to send requests through. Defaults to $HTTPS_PROXY or $HTTP_PROXY.")
+	cmd.PersistentFlags().StringVar(&transport.CACertFile, "ca-cert", conf.GetCACert(), "Path to a PEM bundle of CA certificates to trust, such as a proxy's. Defaults to $AP_CA_CERT.")
+	cmd.PersistentFlags().StringVar(&transport.ClientCertFile, "client-cert", conf.GetClientCert(), "Path to a PEM client certificate for servers that require mutual TLS. Defaults to $AP_CLIENT_CERT.")
+	cmd.PersistentFlags().StringVar(&transport.ClientKeyFile, "client-key", conf.GetClientKey(), "Path to the PEM key of --client-cert. Defaults to $AP_CLIENT_KEY.")
+	cmd.PersistentFlags().BoolVar(&transport.InsecureSkipVerify, "insecure-skip-verify", conf.GetInsecureSkipVerify(), "Don't verify server certificates. Insecure: only for trying out self-hosted APIs. Defaults to $AP_INSECURE_SKIP_VERIFY.")
+	cmd.PersistentFlags().DurationVar(&transport.KeepAlive, "keepalive", api.DefaultKeepAlive, "How often to probe idle connections, so that NATs and proxies which drop idle connections don't interrupt long uploads and builds.")
 	defaultFormat := "table"
 	if !isatty.IsTerminal(os.Stdout.Fd()) {
 		defaultFormat = "json"
@@ -99,6 +174,11 @@ func New() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(&cfg.DebugMode, "debug", false, "Whether to produce debugging output.")
 	cmd.PersistentFlags().BoolVar(&cfg.WithTelemetry, "with-telemetry", false, "Whether to send debug telemetry to Airplane.")
 	cmd.PersistentFlags().BoolVarP(&cfg.Version, "version", "v", false, "Print the CLI version.")
+	cmd.PersistentFlags().BoolVar(&noRetry, "no-retry", false, "Fail immediately instead of retrying failed API requests.")
+	cmd.PersistentFlags().IntVar(&maxRetries, "max-retries", api.DefaultMaxRetries, "Maximum number of times to retry a failed API request.")
+	cmd.PersistentFlags().BoolVar(&plain, "plain", conf.GetPlain(), "Only print essential output, without banners, spinners or colors, for parsing by scripts. Defaults to $AP_PLAIN.")
+	cmd.PersistentFlags().BoolVar(&noInput, "no-input", conf.GetNoInput(), "Never prompt: use defaults or fail instead, as when not run from a terminal. Defaults to $AP_NO_INPUT.")
+	cmd.PersistentFlags().BoolVar(&utc, "utc", false, "Show and read times in UTC instead of the local time zone.")
 	// Aliases for popular namespaced commands:
 	cmd.AddCommand(initcmd.New(cfg))
 	cmd.AddCommand(deploy.New(cfg))
@@ -108,12 +188,130 @@ func New() *cobra.Command {
 	cmd.AddCommand(logout.New(cfg))
 
 	// Sub-commands:
+	cmd.AddCommand(agents.New(cfg))
 	cmd.AddCommand(apikeys.New(cfg))
 	cmd.AddCommand(auth.New(cfg))
+	cmd.AddCommand(build.New(cfg))
+	cmd.AddCommand(cache.New(cfg))
+	cmd.AddCommand(config.New(cfg))
 	cmd.AddCommand(configs.New(cfg))
+	cmd.AddCommand(deps.New(cfg))
+	cmd.AddCommand(envgroups.New(cfg))
+	cmd.AddCommand(generate.New(cfg))
+	cmd.AddCommand(impact.New(cfg))
+	cmd.AddCommand(lint.New(cfg))
+	cmd.AddCommand(open.New(cfg))
+	cmd.AddCommand(record.New(cfg))
+	cmd.AddCommand(replay.New(cfg))
 	cmd.AddCommand(tasks.New(cfg))
 	cmd.AddCommand(runs.New(cfg))
+	cmd.AddCommand(schedules.New(cfg))
 	cmd.AddCommand(version.New(cfg))
 
+	// Cobra runs the command in os.Args when it isn't given other arguments.
+	addPlugins(cmd, cfg, os.Args[1:])
+
 	return cmd
 }
+
+// cassetteTransport returns a wrapper of the API's transport that records
+// requests to the cassette at record, or replays them from the one at
+// replay, if either is set.
+func cassetteTransport(record, replay string) (func(http.RoundTripper) http.RoundTripper, error) {
+	switch {
+	case record != "" && replay != "":
+		return nil, errors.New("$AP_RECORD and $AP_REPLAY can't both be set")
+	case record != "":
+		r, err := cassette.NewRecorder(record)
+		if err != nil {
+			return nil, err
+		}
+		return func(next http.RoundTripper) http.RoundTripper {
+			r.Next = next
+			return r
+		}, nil
+	case replay != "":
+		r, err := cassette.NewReplayer(replay)
+		if err != nil {
+			return nil, err
+		}
+		return func(http.RoundTripper) http.RoundTripper {
+			return r
+		}, nil
+	}
+	return nil, nil
+}
+
+// addPlugins adds a command for the plugin that args run, if they don't run
+// a built-in command, or for every installed plugin if they show the CLI's
+// help. Other commands don't look for plugins, which would read every
+// directory on $PATH.
+func addPlugins(cmd *cobra.Command, cfg *cli.Config, args []string) {
+	name := commandName(cmd, args)
+	if name == "" || name == "help" {
+		for _, p := range plugins.List() {
+			if isCommand(cmd, p.Name) {
+				logger.Debug("Ignoring plugin %s, which has the name of a built-in command", p.Path)
+				continue
+			}
+			addPlugin(cmd, cfg, p)
+		}
+		return
+	}
+	if isCommand(cmd, name) {
+		return
+	}
+	if p, ok := plugins.Find(name); ok {
+		addPlugin(cmd, cfg, p)
+	}
+}
+
+// addPlugin adds a command that runs p.
+func addPlugin(cmd *cobra.Command, cfg *cli.Config, p plugins.Plugin) {
+	cmd.AddCommand(&cobra.Command{
+		Use:   p.Name,
+		Short: fmt.Sprintf("Plugin (%s)", p.Path),
+		// Flags are the plugin's to parse, so global flags such as --host
+		// must come before the plugin's name.
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return plugins.Run(cmd.Root().Context(), p, *cfg.Client, args)
+		},
+	})
+}
+
+// isCommand reports whether name is a built-in command of cmd.
+func isCommand(cmd *cobra.Command, name string) bool {
+	c, _, err := cmd.Find([]string{name})
+	return err == nil && c != cmd
+}
+
+// commandName returns the first argument in args that isn't a global flag
+// or a flag's value, which names the command to run, or "" if there is none.
+func commandName(cmd *cobra.Command, args []string) string {
+	flags := cmd.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return ""
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if strings.Contains(arg, "=") {
+				continue
+			}
+			var f *pflag.Flag
+			if strings.HasPrefix(arg, "--") {
+				f = flags.Lookup(arg[2:])
+			} else if len(arg) == 2 {
+				f = flags.ShorthandLookup(arg[1:])
+			}
+			if f != nil && f.NoOptDefVal == "" {
+				// The flag's value is the next argument.
+				i++
+			}
+		default:
+			return arg
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package root
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandName(t *testing.T) {
+	cmd := New()
+	for _, test := range []struct {
+		args []string
+		name string
+	}{
+		{nil, ""},
+		{[]string{"--help"}, ""},
+		{[]string{"deploy", "./task.yml"}, "deploy"},
+		{[]string{"--host", "localhost:5000", "deploy-all", "--force"}, "deploy-all"},
+		{[]string{"--host=localhost:5000", "-o", "json", "audit"}, "audit"},
+		{[]string{"--debug", "audit"}, "audit"},
+		{[]string{"--", "audit"}, ""},
+	} {
+		require.Equal(t, test.name, commandName(cmd, test.args), "%v", test.args)
+	}
+}
+
+func TestAddPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by extension on Windows")
+	}
+	t.Setenv("HOME", t.TempDir())
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	for _, name := range []string{"airplane-audit", "airplane-notes", "airplane-deploy"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0755))
+	}
+
+	for _, test := range []struct {
+		args    []string
+		plugins []string
+	}{
+		{nil, []string{"audit", "notes"}},
+		{[]string{"help"}, []string{"audit", "notes"}},
+		{[]string{"audit", "--all"}, []string{"audit"}},
+		{[]string{"deploy", "./task.yml"}, nil},
+		{[]string{"missing"}, nil},
+	} {
+		cmd := New()
+		for _, c := range cmd.Commands() {
+			if c.DisableFlagParsing {
+				cmd.RemoveCommand(c)
+			}
+		}
+		addPlugins(cmd, &cli.Config{}, test.args)
+
+		var added []string
+		for _, c := range cmd.Commands() {
+			if c.DisableFlagParsing {
+				added = append(added, c.Name())
+			}
+		}
+		require.Equal(t, test.plugins, added, "%v", test.args)
+	}
+}
@@ -0,0 +1,47 @@
+package cancel
+
+import (
+	"context"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new cancel command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel <id>",
+		Short: "Cancel a queued or active run",
+		Example: heredoc.Doc(`
+			airplane runs cancel <id>
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c, args[0])
+		},
+	}
+	return cmd
+}
+
+// Run runs the cancel command.
+func run(ctx context.Context, c *cli.Config, id string) error {
+	var client = c.Client
+
+	resp, err := client.GetRun(ctx, id)
+	if err != nil {
+		return err
+	}
+	if resp.Run.Status.Stopped() {
+		logger.Log("Run %s has already finished: %s", id, resp.Run.Status)
+		return nil
+	}
+
+	if err := client.CancelRun(ctx, id); err != nil {
+		return errors.Wrap(err, "cancel run")
+	}
+	logger.Log("Cancelled run: %s", client.RunURL(id))
+	return nil
+}
@@ -0,0 +1,102 @@
+package enqueue
+
+import (
+	"context"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/params"
+	"github.com/airplanedev/cli/pkg/runqueue"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root    *cli.Config
+	slug    string
+	args    []string
+	offline bool
+}
+
+// New returns a new enqueue command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "enqueue <slug> [-- <parameters...>]",
+		Short: "Execute a task, or save the request if Airplane is unreachable",
+		Long: heredoc.Doc(`
+			Execute a task without waiting for it to finish. If Airplane can't be
+			reached, the request is saved locally instead, to be submitted later
+			with "airplane runs flush".
+
+			Parameters of saved requests are checked when they are submitted.
+		`),
+		Example: heredoc.Doc(`
+			airplane runs enqueue my_task -- --name=World
+			airplane runs enqueue my_task --offline -- --name=World
+			airplane runs flush
+		`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.slug = args[0]
+			cfg.args = args[1:]
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().BoolVar(&cfg.offline, "offline", false, "Save the request without trying to submit it.")
+	return cmd
+}
+
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
+
+	// The entry's ID is sent as the run's idempotency key, so that if the
+	// request reached the API before failing, flushing it doesn't start
+	// another run.
+	e, err := runqueue.NewEntry(client.Host, cfg.slug, cfg.args)
+	if err != nil {
+		return err
+	}
+	if !cfg.offline {
+		runID, err := submit(ctx, client, cfg, e.ID)
+		if err == nil {
+			logger.Log("Queued run: %s", client.RunURL(runID))
+			return nil
+		} else if !api.IsUnavailable(err) {
+			return err
+		}
+		logger.Debug("Unable to submit run: %v", err)
+	}
+
+	if err := runqueue.Default().Push(e); err != nil {
+		return err
+	}
+	if !cfg.offline {
+		logger.Warning("Airplane is unreachable, so the run was saved locally.")
+	}
+	logger.Log("Saved run request %s for %s.", e.ID, logger.Bold(cfg.slug))
+	logger.Suggest("⚡ To submit saved runs:", "airplane runs flush")
+	return nil
+}
+
+func submit(ctx context.Context, client api.APIClient, cfg config, idempotencyKey string) (string, error) {
+	task, err := client.GetTask(ctx, cfg.slug)
+	if err != nil {
+		return "", err
+	}
+	values, err := params.FromFlags(task, cfg.args)
+	if err != nil {
+		return "", err
+	}
+	res, err := client.RunTask(ctx, api.RunTaskRequest{
+		TaskID:         task.ID,
+		ParamValues:    values,
+		IdempotencyKey: idempotencyKey,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "executing task")
+	}
+	return res.RunID, nil
+}
@@ -0,0 +1,75 @@
+package flush
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/runqueue"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new flush command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "flush",
+		Short: "Submit runs saved while Airplane was unreachable",
+		Long: heredoc.Doc(`
+			Submit the run requests saved by "airplane runs enqueue", oldest first.
+
+			Requests that can't be submitted, such as because their task no longer
+			exists, are reported and discarded. If Airplane is still unreachable,
+			the remaining requests are kept for next time.
+		`),
+		Example: heredoc.Doc(`
+			airplane runs flush
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c)
+		},
+	}
+	return cmd
+}
+
+func run(ctx context.Context, c *cli.Config) error {
+	var client = c.Client
+
+	q := runqueue.Default()
+	results, err := runqueue.Flush(ctx, client, client.Host, q)
+	var failed int
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			logger.Error("Discarded %s run %s: %v", r.Entry.TaskSlug, r.Entry.ID, r.Err)
+			continue
+		}
+		logger.Log("Submitted %s: %s", logger.Bold(r.Entry.TaskSlug), client.RunURL(r.RunID))
+	}
+
+	if api.IsUnavailable(err) || runqueue.IsAuthError(err) {
+		remaining, lerr := q.List(client.Host)
+		if lerr != nil {
+			return lerr
+		}
+		if runqueue.IsAuthError(err) {
+			return errors.Wrapf(err, "%d run(s) remain saved: log in with an account that can run them, then flush again", len(remaining))
+		}
+		return errors.Wrapf(err, "Airplane is unreachable: %d run(s) remain saved", len(remaining))
+	} else if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		logger.Log("No saved runs.")
+		return nil
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d saved run(s) could not be submitted", failed)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package get
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// upload is a file passed to a run as a parameter. Uploads are passed to
+// tasks as objects with the URL of the file.
+type upload struct {
+	Param string
+	Name  string
+	URL   string
+}
+
+// runUploads returns the files uploaded as the run's parameters, sorted by
+// parameter.
+func runUploads(run api.Run) []upload {
+	var uploads []upload
+	for param, v := range run.ParamValues {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		url, _ := m["url"].(string)
+		if url == "" {
+			continue
+		}
+		name, _ := m["name"].(string)
+		if name == "" {
+			name = param
+		}
+		uploads = append(uploads, upload{Param: param, Name: filepath.Base(name), URL: url})
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		return uploads[i].Param < uploads[j].Param
+	})
+	return uploads
+}
+
+// downloadUploads downloads the files uploaded as the run's parameters to
+// dir, as <param>-<name>.
+func downloadUploads(ctx context.Context, client api.APIClient, run api.Run, dir string) error {
+	uploads := runUploads(run)
+	if len(uploads) == 0 {
+		logger.Log("Run %s has no uploaded files.", run.RunID)
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "creating download directory")
+	}
+	for _, u := range uploads {
+		path := filepath.Join(dir, u.Param+"-"+u.Name)
+		n, err := client.DownloadFile(ctx, u.URL, path, api.DownloadOptions{
+			Progress: progress(u.Param),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "downloading %s", u.Param)
+		}
+		logger.Step("Downloaded %s to %s (%s)", u.Param, path, utils.FormatBytes(n))
+	}
+	return nil
+}
+
+// progress returns a download progress func that logs each tenth of the
+// file written, or every written byte at debug level if the size is
+// unknown.
+func progress(name string) func(written, size int64) {
+	last := int64(0)
+	return func(written, size int64) {
+		if size <= 0 {
+			logger.Debug("Downloading %s: %s", name, utils.FormatBytes(written))
+			return
+		}
+		if pct := written * 100 / size; pct/10 > last/10 {
+			last = pct
+			logger.Log(logger.Gray("Downloading %s: %d%% of %s", name, pct, utils.FormatBytes(size)))
+		}
+	}
+}
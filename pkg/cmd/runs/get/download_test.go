@@ -0,0 +1,41 @@
+package get
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadUploads(t *testing.T) {
+	ctx := context.Background()
+	client := apitest.New()
+	client.AddDownload("https://storage.example.com/report", []byte("a,b\n1,2\n"))
+	run := client.AddRun(api.Run{
+		ParamValues: api.Values{
+			"report": map[string]interface{}{"id": "upl1", "url": "https://storage.example.com/report", "name": "data/report.csv"},
+			"name":   "hello",
+			"other":  map[string]interface{}{"id": "upl2"},
+		},
+	}, nil, api.Outputs{})
+
+	require.Equal(t, []upload{
+		{Param: "report", Name: "report.csv", URL: "https://storage.example.com/report"},
+	}, runUploads(run))
+
+	dir := filepath.Join(t.TempDir(), "uploads")
+	require.NoError(t, downloadUploads(ctx, client, run, dir))
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "report-report.csv"))
+	require.NoError(t, err)
+	require.Equal(t, "a,b\n1,2\n", string(buf))
+
+	// Uploads that can't be downloaded fail the command.
+	run.ParamValues["report"] = map[string]interface{}{"url": "https://storage.example.com/expired"}
+	err = downloadUploads(ctx, client, run, dir)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "downloading report")
+}
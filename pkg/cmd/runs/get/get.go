@@ -2,15 +2,34 @@ package get
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/redact"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+type config struct {
+	root     *cli.Config
+	id       string
+	logs     int
+	outputs  bool
+	web      bool
+	download string
+}
+
 // New returns a new get command.
 func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
 	cmd := &cobra.Command{
 		Use:   "get",
 		Short: "Get information about a run",
@@ -18,24 +37,136 @@ func New(c *cli.Config) *cobra.Command {
 			airplane runs get <id>
 			airplane runs get <id> -o yaml
 			airplane runs get <id> -o json
+
+			# Show the run with its last 50 log lines and its outputs
+			airplane runs get <id> --logs 50 --outputs
+
+			# Open the run in the browser
+			airplane runs get <id> --web
+
+			# Download the files uploaded as the run's parameters
+			airplane runs get <id> --download ./uploads
 		`),
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return run(cmd.Root().Context(), c, args[0])
+			cfg.id = args[0]
+			return run(cmd.Root().Context(), cfg)
 		},
 	}
+	cmd.Flags().IntVar(&cfg.logs, "logs", 0, "Include the run's last N log lines.")
+	cmd.Flags().BoolVar(&cfg.outputs, "outputs", false, "Include the run's outputs.")
+	cmd.Flags().BoolVar(&cfg.web, "web", false, "Open the run in the browser.")
+	cmd.Flags().StringVar(&cfg.download, "download", "", "Download the files uploaded as the run's parameters to this directory.")
 	return cmd
 }
 
+// details is a run with its logs and outputs, when requested.
+type details struct {
+	Run     api.Run       `json:"run" yaml:"run"`
+	Logs    []api.LogItem `json:"logs,omitempty" yaml:"logs,omitempty"`
+	Outputs *api.Outputs  `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+}
+
 // Run runs the get command.
-func run(ctx context.Context, c *cli.Config, id string) error {
-	var client = c.Client
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
+
+	if cfg.logs < 0 {
+		return errors.New("--logs must be a positive number of lines")
+	}
 
-	resp, err := client.GetRun(ctx, id)
+	resp, err := client.GetRun(ctx, cfg.id)
 	if err != nil {
 		return err
 	}
 
-	print.Run(resp.Run)
+	if cfg.web {
+		runURL := client.RunURL(cfg.id)
+		logger.Banner("Opening %s", runURL)
+		if !utils.Open(runURL) {
+			logger.Log("Could not open browser - try copying and pasting the above URL")
+		}
+	}
+
+	if cfg.download != "" {
+		if err := downloadUploads(ctx, client, resp.Run, cfg.download); err != nil {
+			return err
+		}
+	}
+
+	if cfg.logs == 0 && !cfg.outputs {
+		print.Run(resp.Run)
+		return nil
+	}
+
+	d := details{Run: resp.Run}
+	if cfg.logs > 0 {
+		d.Logs, err = tailLogs(ctx, client, cfg.id, cfg.logs)
+		if err != nil {
+			return err
+		}
+		if c, err := conf.ReadDefault(); err == nil {
+			redactor, err := redact.Compile(c.Redact)
+			if err != nil {
+				return errors.Wrap(err, "reading redaction rules")
+			}
+			for i := range d.Logs {
+				d.Logs[i].Text = redactor.Redact(d.Logs[i].Text)
+			}
+		}
+	}
+	var outputs api.Outputs
+	if cfg.outputs {
+		res, err := client.GetOutputs(ctx, cfg.id)
+		if err != nil {
+			return errors.Wrap(err, "get outputs")
+		}
+		outputs = res.Outputs
+		d.Outputs = &outputs
+	}
+
+	print.Print(d, func() {
+		print.Run(d.Run)
+		if cfg.logs > 0 {
+			fmt.Fprintln(os.Stdout, "")
+			fmt.Fprintln(os.Stdout, logger.Bold("Logs"))
+			if len(d.Logs) == 0 {
+				fmt.Fprintln(os.Stdout, logger.Gray("No logs."))
+			}
+			for _, l := range d.Logs {
+				fmt.Fprintf(os.Stdout, "%s %s\n", logger.Gray(utils.FormatTime(l.Timestamp)), strings.TrimRight(l.Text, "\n"))
+			}
+		}
+		if cfg.outputs {
+			print.Outputs(outputs)
+		}
+	})
 	return nil
 }
+
+// tailLogs returns the last n logs of a run, paging through all of them.
+func tailLogs(ctx context.Context, client api.APIClient, runID string, n int) ([]api.LogItem, error) {
+	var logs []api.LogItem
+	var token string
+	for {
+		resp, err := client.GetLogs(ctx, runID, token)
+		if err != nil {
+			return nil, errors.Wrap(err, "get logs")
+		}
+		if len(resp.Logs) == 0 || resp.PrevPageToken == "" || resp.PrevPageToken == token {
+			logs = append(logs, resp.Logs...)
+			break
+		}
+		logs = append(logs, resp.Logs...)
+		token = resp.PrevPageToken
+		if len(logs) > n {
+			logs = logs[len(logs)-n:]
+		}
+	}
+
+	api.SortLogs(logs)
+	if len(logs) > n {
+		logs = logs[len(logs)-n:]
+	}
+	return logs, nil
+}
@@ -2,6 +2,8 @@ package list
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/MakeNowJust/heredoc"
@@ -14,10 +16,15 @@ import (
 )
 
 type config struct {
-	slug  string
-	limit int
-	since utils.TimeValue
-	until utils.TimeValue
+	slug     string
+	limit    int
+	since    utils.TimeValue
+	until    utils.TimeValue
+	label    utils.LabelsValue
+	statuses []string
+	creator  string
+	query    string
+	sort     string
 }
 
 // New returns a new list command.
@@ -31,6 +38,16 @@ func New(c *cli.Config) *cobra.Command {
 			airplane runs list
 			airplane runs list --task <slug>
 			airplane runs list --task <slug> -o json
+			airplane runs list --label reason=incident-1234
+
+			# Show today's failed runs started by you
+			airplane runs list --status failed --creator me --since 24h
+
+			# Search run IDs, task names and labels
+			airplane runs list --query incident-1234
+
+			# Show the longest runs first, including ones still running
+			airplane runs list --sort duration
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return run(cmd.Root().Context(), c, cfg)
@@ -39,8 +56,13 @@ func New(c *cli.Config) *cobra.Command {
 
 	cmd.Flags().StringVarP(&cfg.slug, "task", "t", "", "Filter runs by task slug")
 	cmd.Flags().IntVar(&cfg.limit, "limit", 100, "If >0, returns at most --limit items.")
-	cmd.Flags().Var(&cfg.since, "since", "Include only runs created after the given time")
-	cmd.Flags().Var(&cfg.until, "until", "Include only runs created before the given time")
+	cmd.Flags().Var(&cfg.since, "since", "Include only runs created after the given time, or within the given duration, such as 24h")
+	cmd.Flags().Var(&cfg.until, "until", "Include only runs created before the given time, or the given duration ago")
+	cmd.Flags().Var(&cfg.label, "label", "Include only runs with the given key=value label. May be repeated.")
+	cmd.Flags().StringSliceVar(&cfg.statuses, "status", nil, "Include only runs with the given status, such as failed or active. May be repeated.")
+	cmd.Flags().StringVar(&cfg.creator, "creator", "", "Include only runs started by the given user ID, or by you with \"me\".")
+	cmd.Flags().StringVar(&cfg.query, "query", "", "Include only runs whose ID, task name or labels contain the given text.")
+	cmd.Flags().StringVar(&cfg.sort, "sort", "created", "Order to list runs in: created for newest first, or duration for longest running first.")
 
 	return cmd
 }
@@ -49,26 +71,101 @@ func New(c *cli.Config) *cobra.Command {
 func run(ctx context.Context, c *cli.Config, cfg config) error {
 	var client = c.Client
 
-	req := api.ListRunsRequest{
-		Limit: cfg.limit,
-		Since: time.Time(cfg.since),
-		Until: time.Time(cfg.until),
+	if cfg.sort != "created" && cfg.sort != "duration" {
+		return errors.Errorf("unsupported --sort %q: expected created or duration", cfg.sort)
 	}
 
-	// If a task slug was provided, look up its task ID:
-	if cfg.slug != "" {
-		task, err := client.GetTask(ctx, cfg.slug)
+	req := api.ListRunsRequest{
+		Limit:     cfg.limit,
+		Since:     cfg.since.Time(),
+		Until:     cfg.until.Time(),
+		Labels:    cfg.label,
+		CreatorID: cfg.creator,
+		Query:     cfg.query,
+	}
+	for _, s := range cfg.statuses {
+		status, err := parseStatus(s)
 		if err != nil {
 			return err
 		}
-		req.TaskID = task.ID
+		req.Statuses = append(req.Statuses, status)
 	}
+	creatorID, err := resolveCreator(ctx, client, cfg.creator)
+	if err != nil {
+		return err
+	}
+	req.CreatorID = creatorID
 
-	resp, err := client.ListRuns(ctx, req)
+	var resp api.ListRunsResponse
+	list := func(taskID string) (bool, error) {
+		var err error
+		req.TaskID = taskID
+		resp, err = client.ListRuns(ctx, req)
+		return len(resp.Runs) == 0, err
+	}
+	// If a task slug was provided, list the runs of its task:
+	if cfg.slug != "" {
+		err = api.WithTaskID(ctx, client, cfg.slug, list)
+	} else {
+		_, err = list("")
+	}
 	if err != nil {
 		return errors.Wrap(err, "list runs")
 	}
 
+	if cfg.sort == "duration" {
+		sortByDuration(resp.Runs, time.Now())
+	}
+
 	print.Runs(resp.Runs)
 	return nil
 }
+
+// resolveCreator returns the ID of the user that --creator names: "me" is
+// the logged in user, and anything else is a user ID.
+func resolveCreator(ctx context.Context, client api.APIClient, creator string) (string, error) {
+	if creator != "me" {
+		return creator, nil
+	}
+	info, err := client.AuthInfo(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "getting current user")
+	}
+	if info.User == nil {
+		return "", errors.New("--creator me requires logging in as a user rather than with an API key")
+	}
+	return info.User.ID, nil
+}
+
+// statuses lists the statuses runs can be filtered by.
+var statuses = []api.RunStatus{
+	api.RunNotStarted,
+	api.RunQueued,
+	api.RunActive,
+	api.RunSucceeded,
+	api.RunFailed,
+	api.RunCancelled,
+}
+
+// parseStatus parses a run status, ignoring case, so that "failed" matches
+// "Failed".
+func parseStatus(s string) (api.RunStatus, error) {
+	var names []string
+	for _, status := range statuses {
+		if strings.EqualFold(string(status), s) {
+			return status, nil
+		}
+		names = append(names, strings.ToLower(string(status)))
+	}
+	return "", errors.Errorf("unknown --status %q: expected one of %s", s, strings.Join(names, ", "))
+}
+
+// sortByDuration orders runs by how long they ran for, longest first. Runs
+// that never started come last, in their original order.
+func sortByDuration(runs []api.Run, now time.Time) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		di, _ := runs[i].RunDuration(now)
+		dj, _ := runs[j].RunDuration(now)
+		return di > dj
+	})
+}
@@ -0,0 +1,51 @@
+package list
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatus(t *testing.T) {
+	for _, s := range []string{"failed", "Failed", "FAILED"} {
+		status, err := parseStatus(s)
+		require.NoError(t, err)
+		require.Equal(t, api.RunFailed, status)
+	}
+
+	_, err := parseStatus("broken")
+	require.EqualError(t, err, `unknown --status "broken": expected one of notstarted, queued, active, succeeded, failed, cancelled`)
+}
+
+// authInfo is a client logged in as user, or with an API key if it's nil.
+type authInfo struct {
+	api.APIClient
+	user *api.UserInfo
+}
+
+func (c authInfo) AuthInfo(ctx context.Context) (api.AuthInfoResponse, error) {
+	return api.AuthInfoResponse{User: c.user}, nil
+}
+
+func TestResolveCreator(t *testing.T) {
+	ctx := context.Background()
+	client := authInfo{APIClient: apitest.New(), user: &api.UserInfo{ID: "usr123"}}
+
+	id, err := resolveCreator(ctx, client, "me")
+	require.NoError(t, err)
+	require.Equal(t, "usr123", id)
+
+	id, err = resolveCreator(ctx, client, "usr456")
+	require.NoError(t, err)
+	require.Equal(t, "usr456", id)
+
+	id, err = resolveCreator(ctx, client, "")
+	require.NoError(t, err)
+	require.Equal(t, "", id)
+
+	_, err = resolveCreator(ctx, authInfo{APIClient: apitest.New()}, "me")
+	require.EqualError(t, err, "--creator me requires logging in as a user rather than with an API key")
+}
@@ -0,0 +1,192 @@
+package logs
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/execute"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/logsink"
+	"github.com/airplanedev/cli/pkg/redact"
+	"github.com/airplanedev/cli/pkg/taskprefs"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root     *cli.Config
+	id       string
+	follow   bool
+	since    utils.TimeValue
+	logSinks []string
+}
+
+// New returns a new logs command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "logs <id>",
+		Short: "Print the logs of a run",
+		Long: heredoc.Doc(`
+			Print the logs of a run, such as one started by a schedule or by a
+			teammate. With --follow, keep printing logs until the run finishes.
+		`),
+		Example: heredoc.Doc(`
+			airplane runs logs <id>
+
+			# Print logs as they are written, until the run finishes
+			airplane runs logs <id> --follow
+
+			# Only print the logs of the last 10 minutes
+			airplane runs logs <id> --follow --since 10m
+
+			# Also append the logs to a file
+			airplane runs logs <id> --log-sink file:run.log
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.id = args[0]
+			ctx := cmd.Root().Context()
+			if !cmd.Flags().Changed("log-sink") {
+				cfg.logSinks = rememberedLogSinks(ctx, cfg.root.Client, cfg.id)
+			}
+			return run(ctx, cfg)
+		},
+	}
+	cmd.Flags().BoolVarP(&cfg.follow, "follow", "f", false, "Keep printing logs as they are written, until the run finishes.")
+	cmd.Flags().StringArrayVar(&cfg.logSinks, "log-sink", nil, "Also write the run's logs to a sink: "+logsink.Usage+". May be repeated. Defaults to the sinks remembered for the run's task.")
+	cmd.Flags().Var(&cfg.since, "since", "Only print logs written after the given time, or within the given duration, such as 10m")
+	return cmd
+}
+
+// Run runs the logs command.
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
+
+	var rules []string
+	if c, err := conf.ReadDefault(); err == nil {
+		rules = c.Redact
+	}
+	redactor, err := redact.Compile(rules)
+	if err != nil {
+		return errors.Wrap(err, "reading redaction rules")
+	}
+	since := cfg.since.Time()
+
+	sinks, err := logsink.OpenAll(cfg.logSinks)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := sinks.Close(); err != nil {
+			logger.Warning("%v", err)
+		}
+	}()
+
+	if !cfg.follow {
+		return printLogs(ctx, client, cfg.id, since, redactor.Redact, sinks)
+	}
+
+	w := api.NewWatcher(ctx, client, cfg.id, api.WithLogFilter(redactor.Redact))
+	logger.Banner(logger.Gray("Following run: %s", client.RunURL(cfg.id)))
+
+	var state api.RunState
+	for {
+		if state = w.Next(); state.Err() != nil {
+			return state.Err()
+		}
+		logs := afterTime(state.Logs, since)
+		sinks.WriteLogs(logs)
+		for _, l := range logs {
+			logger.Log(execute.FormatLog(l))
+		}
+		if state.Stopped() {
+			break
+		}
+	}
+
+	switch state.Status {
+	case api.RunFailed:
+		return errors.New("Run has failed")
+	case api.RunCancelled:
+		return errors.New("Run was cancelled")
+	}
+	return nil
+}
+
+// printLogs prints the logs the run has written so far, paging through all
+// of them.
+func printLogs(ctx context.Context, client api.APIClient, runID string, since time.Time, filter func(string) string, sinks *logsink.Fanout) error {
+	resp, err := client.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	var token string
+	for {
+		page, err := client.GetLogs(ctx, runID, token)
+		if err != nil {
+			return errors.Wrap(err, "get logs")
+		}
+		api.SortLogs(page.Logs)
+		logs := afterTime(page.Logs, since)
+		for i := range logs {
+			logs[i].Text = filter(logs[i].Text)
+		}
+		sinks.WriteLogs(logs)
+		for _, l := range logs {
+			logger.Log(execute.FormatLog(l))
+		}
+		if len(page.Logs) == 0 || page.PrevPageToken == "" || page.PrevPageToken == token {
+			break
+		}
+		token = page.PrevPageToken
+	}
+
+	if !resp.Run.Status.Stopped() {
+		logger.Banner(logger.Gray("The run is still %s. To follow its logs:\n  airplane runs logs %s --follow", strings.ToLower(string(resp.Run.Status)), runID))
+	}
+	return nil
+}
+
+// afterTime returns the logs written at or after since.
+func afterTime(logs []api.LogItem, since time.Time) []api.LogItem {
+	var res []api.LogItem
+	for _, l := range logs {
+		if !l.Timestamp.Before(since) {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// rememberedLogSinks returns the log sinks remembered for the task of the run
+// with the given ID, with airplane execute --remember. Failing to look them
+// up only means they aren't used.
+func rememberedLogSinks(ctx context.Context, client api.APIClient, runID string) []string {
+	resp, err := client.GetRun(ctx, runID)
+	if err != nil {
+		logger.Debug("Getting run for remembered flags: %v", err)
+		return nil
+	}
+	slug, err := client.GetTaskSlug(ctx, resp.Run.TaskID)
+	if err != nil {
+		logger.Debug("Getting task for remembered flags: %v", err)
+		return nil
+	}
+	prefs, err := taskprefs.Get(slug)
+	if err != nil {
+		logger.Debug("Reading remembered flags: %v", err)
+		return nil
+	}
+	if len(prefs.LogSinks) > 0 {
+		logger.Banner(logger.Gray("Using the flags last passed for %s: --log-sink %s (forget them with airplane config task %s --clear)", slug, strings.Join(prefs.LogSinks, " --log-sink "), slug))
+	}
+	return prefs.LogSinks
+}
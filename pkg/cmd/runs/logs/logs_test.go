@@ -0,0 +1,29 @@
+package logs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/airplanedev/cli/pkg/taskprefs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRememberedLogSinks(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("HOME", t.TempDir())
+
+	client := apitest.New()
+	hello := client.AddTask(api.Task{Slug: "hello"})
+	world := client.AddTask(api.Task{Slug: "world"})
+	helloRun := client.AddRun(api.Run{TaskID: hello.ID}, nil, api.Outputs{})
+	worldRun := client.AddRun(api.Run{TaskID: world.ID}, nil, api.Outputs{})
+	require.NoError(t, taskprefs.Set("hello", taskprefs.Prefs{LogSinks: []string{"file:run.log"}}))
+	// Only sinks are remembered for runs logs, not the output format.
+	require.NoError(t, taskprefs.Set("world", taskprefs.Prefs{Output: "json"}))
+
+	require.Equal(t, []string{"file:run.log"}, rememberedLogSinks(ctx, client, helloRun.RunID))
+	require.Empty(t, rememberedLogSinks(ctx, client, worldRun.RunID))
+	require.Empty(t, rememberedLogSinks(ctx, client, "run_missing"))
+}
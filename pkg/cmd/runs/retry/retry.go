@@ -0,0 +1,131 @@
+package retry
+
+import (
+	"context"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/execute"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root    *cli.Config
+	id      string
+	args    []string
+	watch   bool
+	confirm string
+	// watchOpts configures how the new run is followed with --watch.
+	watchOpts execute.WatchOptions
+}
+
+// New returns a new retry command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "retry <id> [-- <parameters...>]",
+		Short: "Run a task again with a previous run's parameters",
+		Long: heredoc.Doc(`
+			Run a task again with the parameters and labels of an earlier run, such
+			as one that failed. Parameters passed as flags after -- replace the
+			original run's values.
+		`),
+		Example: heredoc.Doc(`
+			airplane runs retry <id>
+			airplane runs retry <id> --watch
+			airplane runs retry <id> --watch --redact keyword:hunter2 --log-sink file:retry.log
+			airplane runs retry <id> -- --dry-run=false
+		`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.id = args[0]
+			cfg.args = args[1:]
+			for _, name := range []string{"log-sink", "output-hook", "redact", "heartbeat", "stall-warning", "cancel-on-interrupt"} {
+				if cmd.Flags().Changed(name) && !cfg.watch {
+					return errors.Errorf("--%s only applies with --watch", name)
+				}
+			}
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().BoolVar(&cfg.watch, "watch", false, "Stream the new run's logs and print its outputs, as execute does.")
+	execute.AddWatchFlags(cmd, &cfg.watchOpts)
+	cmd.Flags().StringVar(&cfg.confirm, "confirm", "", "Confirmation phrase required by the task, to run it without being prompted")
+	return cmd
+}
+
+// Run runs the retry command.
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
+
+	resp, err := client.GetRun(ctx, cfg.id)
+	if err != nil {
+		return err
+	}
+	orig := resp.Run
+	if !orig.Status.Stopped() {
+		logger.Warning("Run %s is still %s.", cfg.id, orig.Status)
+	}
+
+	slug, err := client.GetTaskSlug(ctx, orig.TaskID)
+	if err != nil {
+		return err
+	}
+	task, err := client.GetTask(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if task.ID != orig.TaskID {
+		return errors.Errorf("the task of this run (%s) no longer exists: %s is now a different task", orig.TaskID, slug)
+	}
+	if err := client.RequirePermissions(ctx, api.PermissionCheck{
+		Action:   api.PermissionTasksExecute,
+		TaskSlug: task.Slug,
+	}); err != nil {
+		return err
+	}
+
+	overrides, err := params.ParseFlags(task, cfg.args)
+	if err != nil {
+		return err
+	}
+	req := api.RunTaskRequest{
+		TaskID:      task.ID,
+		ParamValues: params.Merge(orig.ParamValues, overrides),
+		Labels:      orig.Labels,
+	}
+	// The task's parameters may have changed since the original run.
+	if err := params.Preflight(task, req.ParamValues); err != nil {
+		return err
+	}
+	if err := execute.ConfirmRun(task, cfg.confirm); err != nil {
+		return err
+	}
+
+	if !cfg.watch {
+		res, err := client.RunTask(ctx, req)
+		if err != nil {
+			return errors.Wrap(err, "executing task")
+		}
+		logger.Log("Queued run: %s", client.RunURL(res.RunID))
+		return nil
+	}
+
+	logger.Banner("Retrying %s task: %s", logger.Bold(task.Name), logger.Gray(client.TaskURL(task.Slug)))
+	state, err := execute.Watch(ctx, client, req, cfg.watchOpts)
+	if err != nil {
+		return err
+	}
+
+	switch state.Status {
+	case api.RunFailed:
+		return errors.New("Run has failed")
+	case api.RunCancelled:
+		return errors.New("Run was cancelled")
+	}
+	return nil
+}
@@ -4,8 +4,13 @@ import (
 	"github.com/MakeNowJust/heredoc"
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/cmd/runs/cancel"
+	"github.com/airplanedev/cli/pkg/cmd/runs/enqueue"
+	"github.com/airplanedev/cli/pkg/cmd/runs/flush"
 	"github.com/airplanedev/cli/pkg/cmd/runs/get"
 	"github.com/airplanedev/cli/pkg/cmd/runs/list"
+	"github.com/airplanedev/cli/pkg/cmd/runs/logs"
+	"github.com/airplanedev/cli/pkg/cmd/runs/retry"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/spf13/cobra"
 )
@@ -20,6 +25,10 @@ func New(c *cli.Config) *cobra.Command {
 		Example: heredoc.Doc(`
 			airplane runs list --task my-task
 			airplane runs get <id>
+			airplane runs logs <id> --follow
+			airplane runs cancel <id>
+			airplane runs retry <id> --watch
+			airplane runs enqueue my-task
 		`),
 		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
 			return login.EnsureLoggedIn(cmd.Root().Context(), c)
@@ -28,6 +37,11 @@ func New(c *cli.Config) *cobra.Command {
 
 	cmd.AddCommand(list.New(c))
 	cmd.AddCommand(get.New(c))
+	cmd.AddCommand(logs.New(c))
+	cmd.AddCommand(cancel.New(c))
+	cmd.AddCommand(retry.New(c))
+	cmd.AddCommand(enqueue.New(c))
+	cmd.AddCommand(flush.New(c))
 
 	return cmd
 }
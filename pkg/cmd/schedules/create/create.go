@@ -0,0 +1,104 @@
+package create
+
+import (
+	"context"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/params"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Config is the create config.
+type config struct {
+	root        *cli.Config
+	task        string
+	cron        string
+	slug        string
+	name        string
+	description string
+	timezone    string
+	paused      bool
+	args        []string
+}
+
+// New returns a new create command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "create --task <slug> --cron <expr> [-- [parameters]]",
+		Short: "Creates a schedule",
+		Long: heredoc.Doc(`
+			Creates a schedule that runs a task on a cron. The task's parameters are
+			passed after --, as with airplane execute.
+		`),
+		Example: heredoc.Doc(`
+			airplane schedules create --task my_task --cron "0 9 * * 1-5"
+			airplane schedules create --task my_task --cron "*/15 * * * *" --slug every_15m -- --region=us
+		`),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if dash := cmd.ArgsLenAtDash(); dash != 0 && len(args) > 0 {
+				return errors.New("task parameters must be passed after --")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.args = args
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().StringVar(&cfg.task, "task", "", "Slug of the task to schedule.")
+	cmd.Flags().StringVar(&cfg.cron, "cron", "", `Five-field cron expression, such as "0 9 * * 1-5".`)
+	cmd.Flags().StringVar(&cfg.slug, "slug", "", "Slug of the schedule, used to match it to a task definition's schedules block.")
+	cmd.Flags().StringVar(&cfg.name, "name", "", "Name of the schedule.")
+	cmd.Flags().StringVar(&cfg.description, "description", "", "Description of the schedule.")
+	cmd.Flags().StringVar(&cfg.timezone, "timezone", "", "IANA time zone to evaluate the cron expression in. Defaults to UTC.")
+	cmd.Flags().BoolVar(&cfg.paused, "paused", false, "Create the schedule paused.")
+	cli.Must(cmd.MarkFlagRequired("task"))
+	cli.Must(cmd.MarkFlagRequired("cron"))
+	return cmd
+}
+
+// Run runs the create command.
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
+
+	if cfg.timezone != "" {
+		if _, err := time.LoadLocation(cfg.timezone); err != nil {
+			return errors.Errorf("unknown --timezone %q", cfg.timezone)
+		}
+	}
+
+	task, err := client.GetTask(ctx, cfg.task)
+	if err != nil {
+		return err
+	}
+
+	values, err := params.CLI(cfg.args, client, task)
+	if errors.Is(err, params.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	resp, err := client.CreateSchedule(ctx, api.CreateScheduleRequest{
+		TaskID:      task.ID,
+		Slug:        cfg.slug,
+		Name:        cfg.name,
+		Description: cfg.description,
+		CronExpr:    cfg.cron,
+		Timezone:    cfg.timezone,
+		ParamValues: values,
+		Paused:      cfg.paused,
+	})
+	if err != nil {
+		return errors.Wrap(err, "creating schedule")
+	}
+
+	logger.Log("Created schedule %s for %s.", logger.Bold(resp.ScheduleID), logger.Bold(task.Slug))
+	return nil
+}
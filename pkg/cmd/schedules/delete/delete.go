@@ -0,0 +1,39 @@
+package delete
+
+import (
+	"context"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new delete command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <id>...",
+		Short: "Deletes one or more schedules by ID",
+		Long:  "Deletes one or more schedules by ID. Schedules declared in a task definition are recreated the next time the task is deployed.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c, args)
+		},
+	}
+	return cmd
+}
+
+// Run runs the delete command.
+func run(ctx context.Context, c *cli.Config, ids []string) error {
+	var client = c.Client
+
+	for _, id := range ids {
+		logger.Log("  Deleting schedule %s...", logger.Red(id))
+		if err := client.DeleteSchedule(ctx, api.DeleteScheduleRequest{ScheduleID: id}); err != nil {
+			return errors.Wrapf(err, "deleting schedule %s", id)
+		}
+	}
+	logger.Log("  Done.")
+	return nil
+}
@@ -0,0 +1,50 @@
+package list
+
+import (
+	"context"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new list command.
+func New(c *cli.Config) *cobra.Command {
+	var task string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists schedules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c, task)
+		},
+	}
+	cmd.Flags().StringVar(&task, "task", "", "Only list the schedules of the task with this slug.")
+	return cmd
+}
+
+// Run runs the list command.
+func run(ctx context.Context, c *cli.Config, slug string) error {
+	var client = c.Client
+
+	var resp api.ListSchedulesResponse
+	list := func(taskID string) (bool, error) {
+		var err error
+		resp, err = client.ListSchedules(ctx, api.ListSchedulesRequest{TaskID: taskID})
+		return len(resp.Schedules) == 0, err
+	}
+	var err error
+	if slug != "" {
+		err = api.WithTaskID(ctx, client, slug, list)
+	} else {
+		_, err = list("")
+	}
+	if err != nil {
+		return errors.Wrap(err, "listing schedules")
+	}
+
+	print.Schedules(resp.Schedules)
+	return nil
+}
@@ -0,0 +1,41 @@
+package schedules
+
+import (
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/cmd/schedules/create"
+	"github.com/airplanedev/cli/pkg/cmd/schedules/delete"
+	"github.com/airplanedev/cli/pkg/cmd/schedules/list"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new cobra command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedules",
+		Short: "Manage schedules",
+		Long: heredoc.Doc(`
+			Manage schedules that run tasks on a cron.
+
+			Schedules can also be declared in a task definition's schedules block, in
+			which case deploying the task keeps its schedules in sync with the block.
+		`),
+		Aliases: []string{"schedule"},
+		Example: heredoc.Doc(`
+			$ airplane schedules list --task my_task
+			$ airplane schedules create --task my_task --cron "0 9 * * 1-5" --timezone America/New_York -- --dry-run=false
+			$ airplane schedules delete sch_xxx
+		`),
+		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}),
+	}
+
+	cmd.AddCommand(list.New(c))
+	cmd.AddCommand(create.New(c))
+	cmd.AddCommand(delete.New(c))
+
+	return cmd
+}
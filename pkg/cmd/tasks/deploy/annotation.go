@@ -0,0 +1,36 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/version"
+)
+
+// newAnnotation describes the environment of a deploy of def, for the
+// task's revision history.
+func newAnnotation(def interface{}, gitMeta api.BuildGitMeta) *api.DeployAnnotation {
+	return &api.DeployAnnotation{
+		CLIVersion:     version.Get(),
+		OS:             runtime.GOOS + "/" + runtime.GOARCH,
+		GitSHA:         gitMeta.CommitHash,
+		GitDirty:       gitMeta.IsDirty,
+		DefinitionHash: definitionHash(def),
+	}
+}
+
+// definitionHash returns a hash of def, or an empty string if it cannot be
+// computed.
+func definitionHash(def interface{}) string {
+	buf, err := json.Marshal(def)
+	if err != nil {
+		logger.Debug("Unable to hash definition: %+v", err)
+		return ""
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,48 @@
+package deploy
+
+import (
+	"context"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+)
+
+// taskChanges returns the fields that a deploy changed, by comparing the task
+// from before the deploy with the task it deployed. Changes are only listed
+// for tasks that can be described by a definition.
+func taskChanges(ctx context.Context, client api.APIClient, before api.Task) []definitions.Change {
+	after, err := client.GetTask(ctx, before.Slug)
+	if err != nil {
+		logger.Debug("Unable to fetch the deployed task %s to list its changes: %v", before.Slug, err)
+		return nil
+	}
+	from, err := definitions.NewDefinitionFromTask(before)
+	if err != nil {
+		logger.Debug("Unable to list the changes to %s: %v", before.Slug, err)
+		return nil
+	}
+	to, err := definitions.NewDefinitionFromTask(after)
+	if err != nil {
+		logger.Debug("Unable to list the changes to %s: %v", before.Slug, err)
+		return nil
+	}
+	changes, err := definitions.Diff(from, to)
+	if err != nil {
+		logger.Debug("Unable to list the changes to %s: %v", before.Slug, err)
+		return nil
+	}
+	return changes
+}
+
+// logTaskChanges logs the fields that a deploy changed, one per line, so that
+// deploy logs record what each deploy did.
+func logTaskChanges(slug string, changes []definitions.Change) {
+	if len(changes) == 0 {
+		return
+	}
+	logger.Log("Changed %s:", logger.Bold(slug))
+	for _, change := range changes {
+		logger.Log("  %s", change)
+	}
+}
@@ -0,0 +1,96 @@
+package deploy
+
+import (
+	"context"
+
+	"github.com/airplanedev/cli/pkg/build"
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+)
+
+// deployChecksums stores, per task ID, the checksum of the last successful
+// deploy from this machine, as a deployRecord.
+var deployChecksums = cache.New("deploys")
+
+// deployRecord is the checksum of a successful deploy from this machine, with
+// the ID of the task revision it created. The task may have been deployed
+// since from elsewhere, such as by another machine or in the UI, in which
+// case its latest revision is a different one.
+type deployRecord struct {
+	Checksum   string `json:"checksum"`
+	RevisionID string `json:"revisionID"`
+}
+
+// deployChecksum returns the checksum of a deploy of def, or an empty string
+// if it could not be computed, in which case the deploy is never skipped.
+//
+// The definition's schedules block is hashed on its own too, so that a
+// deploy is never skipped while its schedules have changed, however the
+// definition hashes them. So are the values of the git template variables
+// it uses, which the definition only holds unexpanded: a new commit changes
+// ${{ git.sha }}, and so has to be deployed.
+func deployChecksum(root string, def definitions.DefinitionInterface, interpolationMode string) string {
+	values := []interface{}{def, interpolationMode}
+	if schedules := def.GetSchedules(); schedules != nil {
+		values = append(values, schedules)
+	}
+	git, err := definitions.UsedGitVars(def, root)
+	if err != nil {
+		logger.Debug("Unable to compute deploy checksum: %+v", err)
+		return ""
+	}
+	if git != nil {
+		values = append(values, git)
+	}
+	sum, err := build.Checksum(root, values...)
+	if err != nil {
+		logger.Debug("Unable to compute deploy checksum: %+v", err)
+		return ""
+	}
+	return sum
+}
+
+// isUpToDate reports whether the task was last deployed from this machine
+// with the same checksum, and hasn't been deployed from elsewhere since.
+func isUpToDate(ctx context.Context, cfg config, taskID, checksum string) bool {
+	if cfg.force || checksum == "" {
+		return false
+	}
+
+	var last deployRecord
+	ok, err := deployChecksums.Get(taskID, &last)
+	if err != nil {
+		logger.Debug("Unable to read deploy checksum: %+v", err)
+		return false
+	}
+	if !ok || last.Checksum != checksum || last.RevisionID == "" {
+		return false
+	}
+
+	res, err := cfg.client.ListTaskRevisions(ctx, taskID, 1)
+	if err != nil {
+		logger.Debug("Unable to list task revisions: %+v", err)
+		return false
+	}
+	if len(res.Revisions) == 0 || res.Revisions[0].ID != last.RevisionID {
+		logger.Debug("Task %s was deployed elsewhere since its last deploy from this machine", taskID)
+		return false
+	}
+	return true
+}
+
+// recordChecksum remembers the checksum of a successful deploy, which created
+// the given task revision.
+func recordChecksum(taskID, checksum, revisionID string) {
+	if checksum == "" {
+		return
+	}
+	if err := deployChecksums.Set(taskID, deployRecord{Checksum: checksum, RevisionID: revisionID}); err != nil {
+		logger.Debug("Unable to save deploy checksum: %+v", err)
+	}
+}
+
+func logUpToDate(slug string) {
+	logger.Log("%s is up to date, skipping deploy. Pass --force to deploy anyway.", logger.Bold(slug))
+}
@@ -0,0 +1,65 @@
+package deploy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/utils/pointers"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployChecksumGitVars(t *testing.T) {
+	repoDir := t.TempDir()
+	root := filepath.Join(repoDir, "task")
+	require.NoError(t, os.MkdirAll(root, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "main.sh"), []byte("echo hello"), 0644))
+
+	repo, err := git.PlainInit(repoDir, false)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+	// commit commits a change to a file outside of the task's root, which
+	// only changes the task by its SHA.
+	commit := func(t *testing.T, content string) string {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(repoDir, "CHANGELOG"), []byte(content), 0644))
+		_, err := w.Add(".")
+		require.NoError(t, err)
+		h, err := w.Commit(content, &git.CommitOptions{
+			Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		})
+		require.NoError(t, err)
+		return h.String()
+	}
+
+	withSHA := &definitions.Definition{
+		Slug:  "hello",
+		Shell: &definitions.ShellDefinition{Entrypoint: "main.sh"},
+		Env:   api.TaskEnv{"GIT_SHA": {Value: pointers.String("${{ git.sha }}")}},
+	}
+	without := &definitions.Definition{
+		Slug:  "hello",
+		Shell: &definitions.ShellDefinition{Entrypoint: "main.sh"},
+		Env:   api.TaskEnv{"GIT_SHA": {Value: pointers.String("unset")}},
+	}
+
+	first := commit(t, "one")
+	sumWith, sumWithout := deployChecksum(root, withSHA, "jst"), deployChecksum(root, without, "jst")
+	require.NotEmpty(t, sumWith)
+	require.NotEmpty(t, sumWithout)
+
+	second := commit(t, "two")
+	require.NotEqual(t, first, second)
+	// A new SHA is a new deploy of a definition that uses it.
+	require.NotEqual(t, sumWith, deployChecksum(root, withSHA, "jst"))
+	require.Equal(t, sumWithout, deployChecksum(root, without, "jst"))
+
+	// The same commit gives the same checksum.
+	require.Equal(t, deployChecksum(root, withSHA, "jst"), deployChecksum(root, withSHA, "jst"))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/airplanedev/cli/pkg/analytics"
@@ -19,6 +20,9 @@ import (
 	"github.com/pkg/errors"
 )
 
+// promptMu serializes prompts of definitions deployed concurrently.
+var promptMu sync.Mutex
+
 // deployFromTaskDefn deploys from a task definition file.
 func deployFromTaskDefn(ctx context.Context, cfg config) error {
 	client := cfg.client
@@ -27,12 +31,28 @@ func deployFromTaskDefn(ctx context.Context, cfg config) error {
 		return err
 	}
 	defer dir.Close()
+	dir.Strict = cfg.strict
 
 	def, err := dir.ReadDefinition_0_3()
 	if err != nil {
 		return err
 	}
+	if err := readDescription(&def.Description, dir.DefinitionPath()); err != nil {
+		return err
+	}
+	if err := definitions.ValidateSchedules(def.Schedules); err != nil {
+		return err
+	}
+	definitions.WarnDeprecations(relpath(dir.DefinitionPath()), def.GetDeprecations())
+
+	if err := ensureEnvGroupsExist(ctx, client, def.EnvGroups); err != nil {
+		return err
+	}
+	if def.Constraints != nil {
+		warnIfNoMatchingAgent(ctx, client, def.Slug, *def.Constraints)
+	}
 
+	action := deployUpdated
 	task, err := client.GetTask(ctx, def.Slug)
 	if _, ok := err.(*api.TaskMissingError); ok {
 		if !utils.CanPrompt() {
@@ -41,13 +61,19 @@ func deployFromTaskDefn(ctx context.Context, cfg config) error {
 		}
 
 		question := fmt.Sprintf("Task with slug %s does not exist. Would you like to create a new task?", def.Slug)
-		if ok, err := utils.ConfirmWithAssumptions(question, cfg.assumeYes, cfg.assumeNo); err != nil {
+		promptMu.Lock()
+		create, err := utils.ConfirmWithAssumptions(question, cfg.assumeYes, cfg.assumeNo)
+		promptMu.Unlock()
+		if err != nil {
 			return err
-		} else if !ok {
+		} else if !create {
 			// User answered "no", so bail here.
 			return nil
 		}
 
+		if err := ensureCanDeploy(ctx, client, []string{def.Slug}, nil); err != nil {
+			return err
+		}
 		logger.Log("Creating task...")
 		utr, err := def.GetUpdateTaskRequest(ctx, client, nil)
 		if err != nil {
@@ -62,14 +88,19 @@ func deployFromTaskDefn(ctx context.Context, cfg config) error {
 			Command:          utr.Command,
 			Arguments:        utr.Arguments,
 			Parameters:       utr.Parameters,
+			Form:             utr.Form,
 			Constraints:      utr.Constraints,
 			Env:              utr.Env,
+			EnvGroups:        utr.EnvGroups,
 			ResourceRequests: utr.ResourceRequests,
 			Resources:        utr.Resources,
 			Kind:             utr.Kind,
 			KindOptions:      utr.KindOptions,
 			Repo:             utr.Repo,
 			Timeout:          utr.Timeout,
+			Concurrency:      utr.Concurrency,
+			Confirm:          utr.Confirm,
+			Dependencies:     utr.Dependencies,
 		})
 		if err != nil {
 			return errors.Wrapf(err, "creating task %s", def.Slug)
@@ -79,28 +110,45 @@ func deployFromTaskDefn(ctx context.Context, cfg config) error {
 		if err != nil {
 			return errors.Wrap(err, "fetching created task")
 		}
+		action = deployCreated
 	} else if err != nil {
 		return errors.Wrap(err, "getting task")
+	} else if err := ensureCanDeploy(ctx, client, nil, []string{def.Slug}); err != nil {
+		return err
 	}
 
-	tc, err := getTaskConfigFromDefn(ctx, *client, def, task, dir.DefinitionRootPath())
+	tc, err := getTaskConfigFromDefn(ctx, client, def, task, dir.DefinitionRootPath())
 	if err != nil {
 		return err
 	}
 
-	if err := deploySingleTaskFromTaskDefn(ctx, cfg, tc); err != nil {
+	entry, err := deploySingleTaskFromTaskDefn(ctx, cfg, tc)
+	if entry.Action == deployUpdated {
+		entry.Action = action
+	}
+	if err != nil {
 		logger.Log("\n" + logger.Bold(tc.def.GetSlug()))
 		logger.Log("Status: " + logger.Bold(logger.Red("failed")))
 		logger.Error(err.Error())
-		return err
+	} else {
+		logger.Log("\n" + logger.Bold(tc.def.GetSlug()))
+		logger.Log("Status: %s", logger.Bold(logger.Green("succeeded")))
+		logger.Banner("Execute the task: %s", client.TaskURL(tc.def.GetSlug()))
 	}
-	logger.Log("\n" + logger.Bold(tc.def.GetSlug()))
-	logger.Log("Status: %s", logger.Bold(logger.Green("succeeded")))
-	logger.Log("Execute the task: %s", client.TaskURL(tc.def.GetSlug()))
-	return nil
+
+	if cfg.summary != nil {
+		cfg.summary.add(entry, err)
+	} else if cfg.summaryFile != "" {
+		var summary deploySummary
+		summary.add(entry, err)
+		if err := summary.write(cfg.summaryFile); err != nil {
+			return err
+		}
+	}
+	return err
 }
 
-func deploySingleTaskFromTaskDefn(ctx context.Context, cfg config, tc taskConfig) (rErr error) {
+func deploySingleTaskFromTaskDefn(ctx context.Context, cfg config, tc taskConfig) (entry deployedTask, rErr error) {
 	client := cfg.client
 	props := taskDeployedProps{
 		from: "defn",
@@ -120,19 +168,20 @@ func deploySingleTaskFromTaskDefn(ctx context.Context, cfg config, tc taskConfig
 	}()
 
 	task := tc.task
+	entry = deployedTask{Slug: tc.def.GetSlug(), Action: deployUpdated}
 
 	props.taskSlug = tc.def.GetSlug()
 	props.taskID = task.ID
 	props.taskName = task.Name
 
-	logger.Log(logger.Bold(tc.task.Slug))
-	logger.Log("Type: %s", tc.kind)
-	logger.Log("Root directory: %s", relpath(tc.taskRoot))
+	logger.Banner(logger.Bold(tc.task.Slug))
+	logger.Banner("Type: %s", tc.kind)
+	logger.Banner("Root directory: %s", relpath(tc.taskRoot))
 	if tc.workingDirectory != tc.taskRoot {
-		logger.Log("Working directory: %s", relpath(tc.workingDirectory))
+		logger.Banner("Working directory: %s", relpath(tc.workingDirectory))
 	}
-	logger.Log("URL: %s", cfg.client.TaskURL(tc.task.Slug))
-	logger.Log("")
+	logger.Banner("URL: %s", cfg.client.TaskURL(tc.task.Slug))
+	logger.Banner("")
 
 	interpolationMode := task.InterpolationMode
 	if interpolationMode != "jst" {
@@ -141,7 +190,7 @@ func deploySingleTaskFromTaskDefn(ctx context.Context, cfg config, tc taskConfig
 More information: https://apn.sh/jst-upgrade`)
 			interpolationMode = "jst"
 			if err := tc.def.UpgradeJST(); err != nil {
-				return err
+				return entry, err
 			}
 		} else {
 			logger.Warning(`Tasks are migrating from handlebars to Airplane JS Templates! Your task has not
@@ -154,6 +203,18 @@ More information: https://apn.sh/jst-upgrade`)
 		}
 	}
 
+	checksum := deployChecksum(tc.taskRoot, tc.def, interpolationMode)
+	if isUpToDate(ctx, cfg, task.ID, checksum) {
+		logUpToDate(task.Slug)
+		entry.Action = deployUnchanged
+		// Schedules may have been changed since, such as in the UI, so a
+		// definition that manages them still syncs them.
+		if err := syncSchedules(ctx, client, task.ID, tc.def.GetSchedules()); err != nil {
+			return entry, errors.Wrapf(err, "updating schedules of %s", tc.def.GetSlug())
+		}
+		return entry, nil
+	}
+
 	gitMeta, err := getGitMetadata(tc.taskFilePath)
 	if err != nil {
 		logger.Debug("failed to gather git metadata: %v", err)
@@ -161,52 +222,88 @@ More information: https://apn.sh/jst-upgrade`)
 	}
 	gitMeta.User = conf.GetGitUser()
 	gitMeta.Repository = conf.GetGitRepo()
+	// Hash the definition as written, before git variables are expanded.
+	annotation := newAnnotation(tc.def, gitMeta)
+	if err := definitions.ExpandGitVars(tc.def, tc.taskRoot); err != nil {
+		return entry, err
+	}
 
 	var image *string
 	var buildID string
+	var signature *string
 	kind, _, err := tc.def.GetKindAndOptions()
 	if err != nil {
-		return err
+		return entry, err
 	}
 	if ok, err := libBuild.NeedsBuilding(kind); err != nil {
-		return err
+		return entry, err
 	} else if ok {
+		buildStart := time.Now()
 		resp, err := build.Run(ctx, build.NewDeployer(), build.Request{
-			Local:   cfg.local,
-			Client:  client,
-			TaskID:  task.ID,
-			Root:    tc.taskRoot,
-			Def:     tc.def,
-			Shim:    true,
-			GitMeta: gitMeta,
+			Local:            cfg.local,
+			Client:           client,
+			TaskID:           task.ID,
+			Root:             tc.taskRoot,
+			Def:              tc.def,
+			Shim:             true,
+			GitMeta:          gitMeta,
+			Compression:      build.Compression(cfg.compression),
+			CompressionLevel: cfg.compressionLevel,
+			Builder:          cfg.builder,
+			Audit:            cfg.audit,
+			Lint:             &build.LintOptions{Strict: cfg.lintStrict},
+			Healthcheck:      cfg.healthcheck,
+			Progress:         cfg.progressEvents,
 		})
 		props.buildLocal = cfg.local
 		if resp != nil {
 			props.buildID = resp.BuildID
 			buildID = resp.BuildID
+			entry.BuildID = resp.BuildID
 		}
 		if err != nil {
-			return err
+			return entry, err
 		}
 		image = &resp.ImageURL
+		entry.BuildDurationSeconds = time.Since(buildStart).Seconds()
+		entry.Image = resp.ImageURL
+		entry.ImageDigest = resp.ImageDigest
+
+		signature, err = signImage(ctx, cfg, resp)
+		if err != nil {
+			return entry, err
+		}
 	}
 
 	updateTaskRequest, err := tc.def.GetUpdateTaskRequest(ctx, client, image)
 	if err != nil {
-		return err
+		return entry, err
 	}
 
 	updateTaskRequest.BuildID = pointers.String(buildID)
+	updateTaskRequest.ImageSignature = signature
+	if signature != nil {
+		entry.ImageSignature = *signature
+	}
 	updateTaskRequest.InterpolationMode = interpolationMode
+	updateTaskRequest.Annotation = annotation
 
-	if _, err = client.UpdateTask(ctx, updateTaskRequest); err != nil {
-		return errors.Wrapf(err, "updating task %s", tc.def.GetSlug())
+	res, err := client.UpdateTask(ctx, updateTaskRequest)
+	if err != nil {
+		return entry, errors.Wrapf(err, "updating task %s", tc.def.GetSlug())
+	}
+	entry.TaskRevisionID = res.TaskRevisionID
+	entry.Changes = taskChanges(ctx, client, task)
+	logTaskChanges(task.Slug, entry.Changes)
+	if err := syncSchedules(ctx, client, task.ID, tc.def.GetSchedules()); err != nil {
+		return entry, errors.Wrapf(err, "updating schedules of %s", tc.def.GetSlug())
 	}
-	return nil
+	recordChecksum(task.ID, checksum, res.TaskRevisionID)
+	return entry, nil
 }
 
-func getTaskConfigFromDefn(ctx context.Context, client api.Client, def definitions.Definition_0_3, task api.Task, root string) (taskConfig, error) {
-	utr, err := def.GetUpdateTaskRequest(ctx, &client, nil)
+func getTaskConfigFromDefn(ctx context.Context, client api.APIClient, def definitions.Definition_0_3, task api.Task, root string) (taskConfig, error) {
+	utr, err := def.GetUpdateTaskRequest(ctx, client, nil)
 	if err != nil {
 		return taskConfig{}, err
 	}
@@ -2,33 +2,93 @@ package deploy
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/build"
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/conf"
 	"github.com/airplanedev/cli/pkg/logger"
-	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/taskdir"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/airplanedev/cli/pkg/version/latest"
-	"github.com/airplanedev/lib/pkg/build"
+	libBuild "github.com/airplanedev/lib/pkg/build"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 type config struct {
 	root         *cli.Config
-	client       *api.Client
+	client       api.APIClient
 	paths        []string
 	local        bool
 	changedFiles utils.NewlineFileValue
+	summaryFile  string
 
 	upgradeInterpolation bool
 
+	compression      string
+	compressionLevel int
+
+	builderSize   string
+	builderRegion string
+	buildTimeout  time.Duration
+	// builder is parsed from the builder flags above.
+	builder *api.BuilderOptions
+
+	auditDeps  bool
+	auditLevel string
+	// audit is parsed from the audit flags above, or nil to skip auditing.
+	audit *build.AuditOptions
+
+	force     bool
+	sign      bool
+	signKey   string
 	dev       bool
 	assumeYes bool
 	assumeNo  bool
+
+	// interactive asks which of the discovered tasks to deploy.
+	interactive bool
+
+	// summary, if set, collects the outcome of each definition when several
+	// are deployed at once, instead of each writing its own summary file.
+	summary *deploySummary
+
+	// run runs the task once it has deployed, with runArgs as its parameters.
+	run     bool
+	runArgs []string
+
+	// resume skips the tasks that completed in the last, unfinished deploy
+	// of the same paths.
+	resume bool
+
+	// strict rejects definitions with fields that aren't part of the format.
+	strict bool
+
+	// lintStrict rejects Dockerfiles with lint findings at or above the
+	// failure threshold.
+	lintStrict bool
+
+	// buildConcurrency is how many tasks of a directory are built at once.
+	buildConcurrency int
+
+	// healthcheck starts locally built images under emulation before
+	// pushing them.
+	healthcheck bool
+
+	// dryRun prints what the deploy would do, without building or updating
+	// anything.
+	dryRun bool
+
+	// progress is the format to report build progress to stdout in, or
+	// empty to log builds to stderr.
+	progress       string
+	progressEvents chan<- build.Event
 }
 
 func New(c *cli.Config) *cobra.Command {
@@ -47,14 +107,40 @@ func New(c *cli.Config) *cobra.Command {
 			airplane tasks deploy ./my-task.yml
 			airplane tasks deploy my-directory
 			airplane tasks deploy ./my-task1.yml ./my-task2.yml
+			airplane tasks deploy './tasks/**/*.task.yml'
+			airplane tasks deploy -i my-directory
+			airplane tasks deploy ./my-task.yml --audit-level high
+
+			# Deploy a task, then run it and follow its logs after the build's
+			airplane tasks deploy ./my-task.yml --run [-- <parameters...>]
+
+			# Build the tasks of a directory 8 at a time
+			airplane tasks deploy my-directory --build-concurrency 8
+
+			# Continue a deploy of several tasks that failed partway through
+			airplane tasks deploy my-directory --resume
+
+			# Show what a deploy would change, without building or deploying
+			airplane tasks deploy ./my-task.yml --dry-run
+
+			# Report build progress as JSON lines, such as for a CI dashboard
+			airplane tasks deploy my-directory --progress json
 		`),
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				args, cfg.runArgs = args[:dash], args[dash:]
+			}
 			if len(args) > 0 {
 				cfg.paths = args
 			} else {
 				return errors.New("expected 1 argument: airplane deploy ./path/to/file")
 			}
+			for _, name := range []string{"builder-size", "builder-region", "build-timeout"} {
+				if cmd.Flags().Changed(name) && cfg.local {
+					return errors.Errorf("--%s only applies to remote builds, not --local", name)
+				}
+			}
 			return run(cmd.Root().Context(), cfg)
 		},
 		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
@@ -63,8 +149,28 @@ func New(c *cli.Config) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&cfg.local, "local", "L", false, "use a local Docker daemon (instead of an Airplane-hosted builder)")
+	cmd.Flags().BoolVar(&cfg.healthcheck, "healthcheck", false, "With --local on an ARM Mac or Windows machine, start the runtime in each image's entrypoint, such as node or python, and load the task's module under emulation before pushing it, to catch binaries built for the wrong architecture. The task itself isn't run.")
 	cmd.Flags().BoolVar(&cfg.upgradeInterpolation, "jst", false, "Upgrade interpolation to JST")
 	cmd.Flags().Var(&cfg.changedFiles, "changed-files", "A file with a list of file paths that were changed, one path per line. Only tasks with changed files will be deployed")
+	cmd.Flags().StringVar(&cfg.compression, "compression", "gzip", "Format to compress the build context with for remote builds: gzip or zstd.")
+	cmd.Flags().IntVar(&cfg.compressionLevel, "compression-level", 0, "Compression level for remote builds: 1-9 for gzip, 1-22 for zstd, or 0 for the format's default.")
+	cmd.Flags().StringVar(&cfg.builderSize, "builder-size", conf.GetBuilderSize(), "Size of the remote builder: small or large. Defaults to $AP_BUILDER_SIZE, or the team's default.")
+	cmd.Flags().StringVar(&cfg.builderRegion, "builder-region", conf.GetBuilderRegion(), "Region to run the remote builder in. Defaults to $AP_BUILDER_REGION, or the team's default.")
+	cmd.Flags().DurationVar(&cfg.buildTimeout, "build-timeout", 0, "How long a remote build may run before it is stopped, such as 45m. Defaults to the team's limit.")
+	cmd.Flags().BoolVar(&cfg.auditDeps, "audit", false, "Check Node and Python dependencies for known vulnerabilities with npm audit or pip-audit before building.")
+	cmd.Flags().StringVar(&cfg.auditLevel, "audit-level", "", "Fail the deploy if --audit finds a vulnerability at least this severe: low, moderate, high or critical. Implies --audit.")
+	cmd.Flags().BoolVar(&cfg.force, "force", false, "Deploy tasks even if nothing changed since they were last deployed.")
+	cmd.Flags().BoolVarP(&cfg.interactive, "interactive", "i", false, "Pick which of the discovered tasks to deploy. Tasks changed since their last deploy are selected by default.")
+	cmd.Flags().BoolVar(&cfg.sign, "sign", false, "Sign built images with cosign. Uses keyless signing unless --sign-key is set.")
+	cmd.Flags().StringVar(&cfg.signKey, "sign-key", "", "Path or KMS URI of a cosign key to sign built images with. Implies --sign.")
+	cmd.Flags().BoolVar(&cfg.run, "run", false, "Run the task once it deploys, following its logs after the build's. Parameters are passed as flags after --.")
+	cmd.Flags().BoolVar(&cfg.resume, "resume", false, "Continue the last deploy of the same paths that failed or was interrupted, skipping the tasks it already deployed.")
+	cmd.Flags().BoolVar(&cfg.strict, "strict", false, "Fail if a task definition has fields that aren't part of the definition format, such as a misspelled parameters, instead of ignoring them.")
+	cmd.Flags().BoolVar(&cfg.lintStrict, "lint-strict", false, "Fail if a Dockerfile task's Dockerfile has lint findings at or above the failure-threshold of its .hadolint.yaml, or warnings by default, instead of only reporting them.")
+	cmd.Flags().IntVar(&cfg.buildConcurrency, "build-concurrency", build.DefaultConcurrency, "How many tasks to build at once when deploying several definitions, scripts or a directory. Their logs are prefixed with each task's slug.")
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false, "Print a plan of what deploying airplane.yml definitions would do, including the configs and resources they reference and the fields that would change, without building images or creating or updating tasks.")
+	cmd.Flags().StringVar(&cfg.progress, "progress", "", "Report build progress to stdout in this format instead of logging builds to stderr: json writes one event per line with the task's slug, build phase, percent and message.")
+	cmd.Flags().StringVar(&cfg.summaryFile, "summary-file", "", "Path to write a JSON summary of deployed tasks to, including build IDs, images and revision IDs.")
 	// Remove dev flag + unhide these flags before release!
 	cmd.Flags().BoolVar(&cfg.dev, "dev", false, "Dev mode: warning, not guaranteed to work and subject to change.")
 	cmd.Flags().BoolVarP(&cfg.assumeYes, "yes", "y", false, "True to specify automatic yes to prompts.")
@@ -86,7 +192,7 @@ func New(c *cli.Config) *cobra.Command {
 // Set of properties to track when deploying
 type taskDeployedProps struct {
 	from       string
-	kind       build.TaskKind
+	kind       libBuild.TaskKind
 	taskID     string
 	taskSlug   string
 	taskName   string
@@ -101,15 +207,123 @@ func run(ctx context.Context, cfg config) error {
 	if cfg.assumeYes && cfg.assumeNo {
 		return errors.New("Cannot specify both --yes and --no")
 	}
+	if cfg.interactive && !utils.CanPrompt() {
+		return errors.New("--interactive requires a terminal")
+	}
+	if _, err := build.ParseCompression(cfg.compression, cfg.compressionLevel); err != nil {
+		return err
+	}
+	// With --local, $AP_BUILDER_SIZE and $AP_BUILDER_REGION are ignored.
+	if !cfg.local {
+		builder, err := build.ParseBuilder(cfg.builderSize, cfg.builderRegion, cfg.buildTimeout)
+		if err != nil {
+			return err
+		}
+		cfg.builder = builder
+	}
+	if cfg.healthcheck && !cfg.local {
+		return errors.New("--healthcheck only applies to --local builds")
+	}
+	if cfg.buildConcurrency < 1 {
+		return errors.New("--build-concurrency must be at least 1")
+	}
+	level, err := build.ParseSeverity(cfg.auditLevel)
+	if err != nil {
+		return err
+	}
+	if cfg.auditDeps || level != "" {
+		cfg.audit = &build.AuditOptions{FailOn: level}
+	}
+
+	var paths []string
+	for _, p := range cfg.paths {
+		if !taskdir.IsGlob(p) {
+			paths = append(paths, p)
+			continue
+		}
+		matches, err := taskdir.Glob(p)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, matches...)
+	}
+	cfg.paths = paths
+
+	if len(cfg.runArgs) > 0 && !cfg.run {
+		return errors.New("task parameters after -- require --run")
+	}
+	if cfg.progress != "" {
+		events, stop, err := startProgress(cfg.progress, os.Stdout)
+		if err != nil {
+			return err
+		}
+		defer stop()
+		cfg.progressEvents = events
+	}
+	if cfg.dryRun {
+		if cfg.run || cfg.resume {
+			return errors.New("--dry-run can't be combined with --run or --resume")
+		}
+		return dryRun(ctx, cfg)
+	}
+	if cfg.run {
+		return deployAndRun(ctx, cfg)
+	}
+	return deploy(ctx, cfg)
+}
+
+// deploy deploys the tasks at cfg.paths.
+func deploy(ctx context.Context, cfg config) error {
+	if len(cfg.paths) > 1 && allDefinitions(cfg, cfg.paths) {
+		return deployDefinitions(ctx, cfg)
+	}
+
+	if cfg.resume && isDefinition(cfg, cfg.paths[0]) {
+		return errors.New("--resume only applies to deploys of several definitions, or of scripts")
+	}
+	return deployPath(ctx, cfg)
+}
 
-	if cfg.dev && definitions.IsTaskDef(cfg.paths[0]) {
+// deployPath deploys the task at cfg.paths[0], or the scripts at cfg.paths,
+// with the deploy that applies to the kind of file it is.
+func deployPath(ctx context.Context, cfg config) error {
+	if isTaskDefn(cfg, cfg.paths[0]) {
 		return deployFromTaskDefn(ctx, cfg)
 	}
 
-	ext := filepath.Ext(cfg.paths[0])
-	if ext == ".yml" || ext == ".yaml" {
+	if ext := filepath.Ext(cfg.paths[0]); ext == ".yml" || ext == ".yaml" {
 		return deployFromYaml(ctx, cfg)
 	}
 
 	return NewDeployer().deployFromScript(ctx, cfg)
 }
+
+// deployAndRun deploys a single task and then runs it.
+func deployAndRun(ctx context.Context, cfg config) error {
+	if len(cfg.paths) != 1 {
+		return errors.New("--run deploys a single task, but several paths were given")
+	}
+
+	summary := &deploySummary{}
+	cfg.summary = summary
+	err := deploy(ctx, cfg)
+	if cfg.summaryFile != "" {
+		if werr := summary.write(cfg.summaryFile); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	tasks := summary.sorted()
+	switch {
+	case len(tasks) == 0:
+		return errors.New("--run: no task was deployed")
+	case len(tasks) > 1:
+		return errors.Errorf("--run deploys a single task, but %d were found", len(tasks))
+	case tasks[0].Action == deployFailed:
+		return errors.New(tasks[0].Error)
+	}
+	return runDeployed(ctx, cfg.client, tasks[0].Slug, cfg.runArgs)
+}
@@ -0,0 +1,25 @@
+package deploy
+
+import (
+	"path/filepath"
+
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+)
+
+// readDescription replaces a description written as file:<path> with the
+// contents of that file, relative to the definition at defPath.
+func readDescription(desc *string, defPath string) error {
+	if !definitions.IsDescriptionFile(*desc) {
+		return nil
+	}
+	s, truncated, err := definitions.ReadDescription(*desc, filepath.Dir(defPath))
+	if err != nil {
+		return err
+	}
+	if truncated {
+		logger.Warning("The description in %s is longer than %d characters and was truncated.", *desc, definitions.MaxDescriptionLength)
+	}
+	*desc = s
+	return nil
+}
@@ -0,0 +1,146 @@
+package deploy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// deployJournals stores, per set of deployed paths, the progress of the last
+// deploy of those paths that did not finish cleanly.
+var deployJournals = cache.New("deploy-journals")
+
+// deployJournal records which tasks of a deploy have completed and which
+// failed, so that a rerun with --resume can skip the completed ones.
+//
+// Tasks are keyed by definition path when deploying several definitions, and
+// by slug when deploying scripts. It is safe for concurrent use.
+type deployJournal struct {
+	Paths     []string                `json:"paths"`
+	Completed map[string]deployedTask `json:"completed"`
+	Failed    map[string]string       `json:"failed"`
+	UpdatedAt time.Time               `json:"updatedAt"`
+
+	key string
+	mu  sync.Mutex
+}
+
+// openJournal starts a journal for a deploy of paths. If resume is set, it
+// instead continues the journal left behind by the last deploy of the same
+// paths from the same directory.
+func openJournal(paths []string, resume bool) (*deployJournal, error) {
+	key, err := journalKey(paths)
+	if err != nil {
+		return nil, err
+	}
+	j := &deployJournal{
+		Paths:     paths,
+		Completed: map[string]deployedTask{},
+		Failed:    map[string]string{},
+		key:       key,
+	}
+	if !resume {
+		// Save the journal up front, so that a deploy interrupted before
+		// any task finishes can still be resumed.
+		j.UpdatedAt = time.Now()
+		if err := deployJournals.Set(key, j); err != nil {
+			logger.Debug("Unable to save deploy journal: %+v", err)
+		}
+		return j, nil
+	}
+
+	ok, err := deployJournals.Get(key, j)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading deploy journal")
+	}
+	if !ok {
+		return nil, errors.New("--resume: there is no unfinished deploy of these paths to resume")
+	}
+	if j.Completed == nil {
+		j.Completed = map[string]deployedTask{}
+	}
+	// Failed tasks are retried, so their failures start over.
+	j.Failed = map[string]string{}
+	logger.Log("Resuming the deploy from %s: %d task(s) already deployed.", j.UpdatedAt.Local().Format(time.RFC1123), len(j.Completed))
+	return j, nil
+}
+
+// journalKey identifies a deploy by the absolute paths it was given.
+func journalKey(paths []string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", errors.Wrap(err, "getting working directory")
+	}
+	abs := make([]string, len(paths))
+	for i, p := range paths {
+		if filepath.IsAbs(p) {
+			abs[i] = filepath.Clean(p)
+		} else {
+			abs[i] = filepath.Join(wd, p)
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(abs, "\n")))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// completed returns the outcome of a task that completed in an earlier run of
+// this deploy, if it did.
+func (j *deployJournal) completed(key string) (deployedTask, bool) {
+	if j == nil {
+		return deployedTask{}, false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	t, ok := j.Completed[key]
+	return t, ok
+}
+
+// record saves the outcome of a task to disk, so that it survives the CLI
+// being interrupted.
+func (j *deployJournal) record(key string, t deployedTask, err error) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err != nil {
+		j.Failed[key] = err.Error()
+	} else {
+		delete(j.Failed, key)
+		j.Completed[key] = t
+	}
+	j.UpdatedAt = time.Now()
+	if err := deployJournals.Set(j.key, j); err != nil {
+		logger.Debug("Unable to save deploy journal: %+v", err)
+	}
+}
+
+// finish discards the journal once every task has deployed. Otherwise it is
+// kept, and the failed tasks can be retried with --resume.
+func (j *deployJournal) finish() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if len(j.Failed) > 0 {
+		logger.Log("Fix the failed tasks, then rerun with --resume to deploy just those.")
+		return
+	}
+	if err := deployJournals.Delete(j.key); err != nil {
+		logger.Debug("Unable to remove deploy journal: %+v", err)
+	}
+}
+
+// logResumed notes a task that is skipped because it already deployed.
+func logResumed(name string) {
+	logger.Log("%s already deployed before the deploy was interrupted, skipping.", logger.Bold(name))
+}
@@ -0,0 +1,122 @@
+package deploy
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"github.com/airplanedev/cli/pkg/build"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/pkg/errors"
+)
+
+// isTaskDefn reports whether path is a task definition that is deployed
+// from its definition alone, which is only the case with --dev.
+func isTaskDefn(cfg config, path string) bool {
+	return cfg.dev && definitions.IsTaskDef(path)
+}
+
+// isDefinition reports whether path is a task definition, rather than a
+// script or directory.
+func isDefinition(cfg config, path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".yml" || ext == ".yaml" || isTaskDefn(cfg, path)
+}
+
+// allDefinitions reports whether every path is a task definition.
+func allDefinitions(cfg config, paths []string) bool {
+	for _, p := range paths {
+		if !isDefinition(cfg, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// deployDefinitions deploys several definition files, cfg.buildConcurrency
+// at a time, so that one failing doesn't stop the rest, then prints a summary
+// of them all.
+func deployDefinitions(ctx context.Context, cfg config) error {
+	journal, err := openJournal(cfg.paths, cfg.resume)
+	if err != nil {
+		return err
+	}
+
+	summary := &deploySummary{}
+	var mu sync.Mutex
+	// reported holds the definitions whose deploys reported their own
+	// failure.
+	reported := map[string]bool{}
+	sched := build.NewScheduler(cfg.buildConcurrency)
+	for _, p := range cfg.paths {
+		if t, ok := journal.completed(p); ok {
+			logResumed(p)
+			summary.add(t, nil)
+			continue
+		}
+
+		p := p
+		sched.Go(ctx, p, func(ctx context.Context) error {
+			c := cfg
+			c.paths = []string{p}
+			// Definitions deploy concurrently, so each records its tasks
+			// on its own before they are added to the summary.
+			c.summary = &deploySummary{}
+
+			err := deployPath(ctx, c)
+			tasks := c.summary.since(0)
+			var entry deployedTask
+			if len(tasks) > 0 {
+				entry = tasks[len(tasks)-1]
+			}
+			journal.record(p, entry, err)
+			for _, t := range tasks {
+				summary.add(t, nil)
+			}
+			if err == nil {
+				return nil
+			}
+
+			if len(tasks) == 0 {
+				// The definition failed before its task was known.
+				summary.add(deployedTask{Slug: p}, err)
+			} else if isTaskDefn(cfg, p) {
+				// Task definitions report their own failures.
+				mu.Lock()
+				reported[p] = true
+				mu.Unlock()
+			}
+			return err
+		})
+	}
+
+	// Failures are printed together, since the deploys' logs were
+	// interleaved.
+	schedErr := sched.Wait()
+	var schedErrs *build.SchedulerError
+	if errors.As(schedErr, &schedErrs) {
+		for _, p := range schedErrs.Names() {
+			if reported[p] {
+				continue
+			}
+			logger.Log("\n" + logger.Bold(p))
+			logger.Log("Status: " + logger.Bold(logger.Red("failed")))
+			logger.Error(schedErrs.Errors[p].Error())
+		}
+	}
+
+	logger.Log("")
+	summary.print()
+	journal.finish()
+	if cfg.summaryFile != "" {
+		if err := summary.write(cfg.summaryFile); err != nil {
+			return err
+		}
+	}
+
+	if schedErrs != nil {
+		return errors.Errorf("%d of %d task definitions failed to deploy", len(schedErrs.Errors), len(cfg.paths))
+	}
+	return nil
+}
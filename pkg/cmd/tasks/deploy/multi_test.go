@@ -0,0 +1,85 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployDefinitions(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("HOME", t.TempDir())
+	oldChecksums, oldJournals := deployChecksums, deployJournals
+	deployChecksums, deployJournals = cache.New("deploys"), cache.New("deploy-journals")
+	t.Cleanup(func() { deployChecksums, deployJournals = oldChecksums, oldJournals })
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	hello := write("hello.yml", `
+slug: hello
+name: Hello
+image:
+  image: ubuntu:20.04
+  command: ["echo", "hello"]
+timeout: 300
+`)
+	world := write("world.task.json", `{
+  "slug": "world",
+  "name": "World",
+  "rest": {"resource": "API", "method": "GET", "path": "/world", "bodyType": "json"},
+  "timeout": 600
+}`)
+	broken := write("broken.yml", "slug: broken\nname: Broken\n")
+	summaryFile := filepath.Join(dir, "summary.json")
+
+	client := apitest.New()
+	client.AddTask(api.Task{Slug: "hello", Name: "Hello", Kind: "image"})
+	client.AddTask(api.Task{Slug: "world", Name: "World", Kind: "rest"})
+	client.AddResource(api.Resource{Name: "API"})
+
+	err := deployDefinitions(ctx, config{
+		root:             &cli.Config{},
+		client:           client,
+		paths:            []string{hello, world, broken},
+		dev:              true,
+		buildConcurrency: 2,
+		summaryFile:      summaryFile,
+	})
+	require.EqualError(t, err, "1 of 3 task definitions failed to deploy")
+
+	// The .task.json definition deployed as a task definition.
+	task, err := client.GetTask(ctx, "hello")
+	require.NoError(t, err)
+	require.Equal(t, api.Duration(300), task.Timeout)
+	task, err = client.GetTask(ctx, "world")
+	require.NoError(t, err)
+	require.Equal(t, api.Duration(600), task.Timeout)
+
+	buf, err := ioutil.ReadFile(summaryFile)
+	require.NoError(t, err)
+	var summary struct {
+		Tasks []deployedTask `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(buf, &summary))
+	actions := map[string]deployAction{}
+	for _, t := range summary.Tasks {
+		actions[t.Slug] = t.Action
+	}
+	require.Equal(t, map[string]deployAction{
+		broken:  deployFailed,
+		"hello": deployUpdated,
+		"world": deployUpdated,
+	}, actions)
+}
@@ -0,0 +1,317 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/configs"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	libBuild "github.com/airplanedev/lib/pkg/build"
+	"github.com/pkg/errors"
+)
+
+// deployPlan is what a deploy of some definitions would do, printed by
+// --dry-run.
+type deployPlan struct {
+	Tasks []plannedTask `json:"tasks" yaml:"tasks"`
+}
+
+// plannedTask is what a deploy would do to one task.
+type plannedTask struct {
+	Path   string       `json:"path" yaml:"path"`
+	Slug   string       `json:"slug" yaml:"slug"`
+	Action deployAction `json:"action" yaml:"action"`
+
+	Kind        libBuild.TaskKind    `json:"kind" yaml:"kind"`
+	KindOptions libBuild.KindOptions `json:"kindOptions,omitempty" yaml:"kindOptions,omitempty"`
+	// Build is set if the deploy would build an image.
+	Build bool `json:"build" yaml:"build"`
+
+	Configs   []plannedConfig   `json:"configs,omitempty" yaml:"configs,omitempty"`
+	Resources []plannedResource `json:"resources,omitempty" yaml:"resources,omitempty"`
+	EnvGroups []string          `json:"envGroups,omitempty" yaml:"envGroups,omitempty"`
+
+	// Changes are the fields that differ from the deployed task.
+	Changes []definitions.Change `json:"changes,omitempty" yaml:"changes,omitempty"`
+	// Problems would make the deploy fail.
+	Problems []string `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+// plannedConfig is a config that a task's environment references.
+type plannedConfig struct {
+	Env    string `json:"env" yaml:"env"`
+	Config string `json:"config" yaml:"config"`
+	Exists bool   `json:"exists" yaml:"exists"`
+}
+
+// plannedResource is a resource that a task references, with the ID it
+// resolves to, or no ID if it doesn't exist.
+type plannedResource struct {
+	Ref  string `json:"ref" yaml:"ref"`
+	Name string `json:"name" yaml:"name"`
+	ID   string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// dryRun prints what deploying the definitions at cfg.paths would do, without
+// building images or creating or updating tasks, and fails if any of them
+// would fail to deploy.
+func dryRun(ctx context.Context, cfg config) error {
+	for _, p := range cfg.paths {
+		if ext := strings.ToLower(filepath.Ext(p)); (ext != ".yml" && ext != ".yaml") || definitions.IsTaskDef(p) {
+			return errors.Errorf("--dry-run only applies to airplane.yml definitions, not %s", p)
+		}
+	}
+
+	resp, err := cfg.client.ListResources(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fetching resources")
+	}
+
+	var plan deployPlan
+	var problems int
+	for _, p := range cfg.paths {
+		t, err := planDefinition(ctx, cfg, p, resp.Resources)
+		if err != nil {
+			return errors.Wrapf(err, "planning %s", relpath(p))
+		}
+		plan.Tasks = append(plan.Tasks, t)
+		problems += len(t.Problems)
+	}
+
+	print.Print(plan, func() {
+		printPlan(cfg, plan)
+	})
+	if problems > 0 {
+		return errors.Errorf("the deploy would fail: %d problem(s) found", problems)
+	}
+	return nil
+}
+
+// planDefinition plans the deploy of the definition at path.
+func planDefinition(ctx context.Context, cfg config, path string, resources []api.Resource) (plannedTask, error) {
+	client := cfg.client
+	t := plannedTask{Path: relpath(path)}
+
+	dir, err := taskdir.Open(path, false)
+	if err != nil {
+		return t, err
+	}
+	defer dir.Close()
+	dir.Strict = cfg.strict
+
+	def, err := dir.ReadDefinition()
+	if err != nil {
+		return t, err
+	}
+	if def, err = def.Validate(); err != nil {
+		return t, err
+	}
+	if err := readDescription(&def.Description, dir.DefinitionPath()); err != nil {
+		return t, err
+	}
+	if err := definitions.ValidateSchedules(def.Schedules); err != nil {
+		return t, err
+	}
+	t.Slug = def.Slug
+
+	if t.Kind, t.KindOptions, err = def.GetKindAndOptions(); err != nil {
+		return t, err
+	}
+	if t.Build, err = libBuild.NeedsBuilding(t.Kind); err != nil {
+		return t, err
+	}
+
+	var envs []string
+	for env, v := range def.Env {
+		if v.Config != nil {
+			envs = append(envs, env)
+		}
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		v := def.Env[env]
+		exists, err := configExists(ctx, client, *v.Config)
+		if err != nil {
+			return t, err
+		}
+		t.Configs = append(t.Configs, plannedConfig{Env: env, Config: *v.Config, Exists: exists})
+		if !exists {
+			t.Problems = append(t.Problems, fmt.Sprintf("config %s does not exist", *v.Config))
+		}
+	}
+
+	resourcesByName := map[string]api.Resource{}
+	for _, r := range resources {
+		resourcesByName[r.Name] = r
+	}
+	var refs []string
+	for ref := range def.Resources {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		name := def.Resources[ref]
+		r := plannedResource{Ref: ref, Name: name, ID: resourcesByName[name].ID}
+		t.Resources = append(t.Resources, r)
+		if r.ID == "" {
+			t.Problems = append(t.Problems, fmt.Sprintf("unknown resource: %s", name))
+		}
+	}
+
+	t.EnvGroups = def.EnvGroups
+	for _, name := range def.EnvGroups {
+		if _, err := client.GetEnvGroup(ctx, name); err != nil {
+			var missing *api.EnvGroupMissingError
+			if !errors.As(err, &missing) {
+				return t, err
+			}
+			t.Problems = append(t.Problems, err.Error())
+		}
+	}
+
+	task, err := client.GetTask(ctx, def.Slug)
+	var deployed *api.Task
+	if _, ok := err.(*api.TaskMissingError); ok {
+		t.Action = deployCreated
+		if err := ensureCanDeploy(ctx, client, []string{def.Slug}, nil); err != nil {
+			t.Problems = append(t.Problems, err.Error())
+		}
+	} else if err != nil {
+		return t, errors.Wrap(err, "getting task")
+	} else {
+		t.Action = deployUpdated
+		if err := ensureCanDeploy(ctx, client, nil, []string{def.Slug}); err != nil {
+			t.Problems = append(t.Problems, err.Error())
+		}
+		deployed = &task
+	}
+
+	changes, err := definitions.DiffTask(def, dir.DefinitionRootPath(), deployed, resources)
+	if err != nil {
+		return t, err
+	}
+	for _, c := range changes {
+		if t.Action == deployCreated {
+			// Every field of a new task is added, rather than changed from
+			// its zero value.
+			if c.New == nil {
+				continue
+			}
+			c.Old = nil
+		}
+		t.Changes = append(t.Changes, c)
+	}
+
+	if t.Action == deployUpdated {
+		interpolationMode := task.InterpolationMode
+		if interpolationMode != "jst" && cfg.upgradeInterpolation {
+			interpolationMode = "jst"
+			if err := def.UpgradeJST(); err != nil {
+				return t, err
+			}
+		}
+		// Deploys of builds are skipped by checksum, and others when nothing
+		// changed.
+		checksum := deployChecksum(dir.DefinitionRootPath(), &def, interpolationMode)
+		if isUpToDate(ctx, cfg, task.ID, checksum) || (!t.Build && len(t.Changes) == 0) {
+			t.Action = deployUnchanged
+			t.Build = false
+		}
+	}
+	return t, nil
+}
+
+// configExists reports whether the config named by name, with its optional
+// tag, exists.
+func configExists(ctx context.Context, client api.APIClient, name string) (bool, error) {
+	cn, err := configs.ParseName(name)
+	if err != nil {
+		return false, err
+	}
+	_, err = client.GetConfig(ctx, api.GetConfigRequest{Name: cn.Name, Tag: cn.Tag})
+	if apiErr, ok := errors.Cause(err).(api.Error); ok && apiErr.Code == 404 {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrapf(err, "getting config %s", name)
+	}
+	return true, nil
+}
+
+// printPlan prints a plan the way Terraform does: a block per task, headed by
+// what would be done to it, then a count of each action.
+func printPlan(cfg config, plan deployPlan) {
+	counts := map[deployAction]int{}
+	var problems int
+	for _, t := range plan.Tasks {
+		counts[t.Action]++
+		problems += len(t.Problems)
+
+		switch t.Action {
+		case deployCreated:
+			fmt.Println(logger.Green("+ task %s will be created", logger.Bold(t.Slug)))
+		case deployUpdated:
+			fmt.Println(logger.Yellow("~ task %s will be updated", logger.Bold(t.Slug)))
+		default:
+			fmt.Println(logger.Gray("  task %s is up to date", logger.Bold(t.Slug)))
+		}
+		fmt.Printf("    definition: %s\n", t.Path)
+		fmt.Printf("    kind:       %s\n", t.Kind)
+		var options []string
+		for k := range t.KindOptions {
+			options = append(options, k)
+		}
+		sort.Strings(options)
+		for _, k := range options {
+			fmt.Printf("      %s: %v\n", k, t.KindOptions[k])
+		}
+		if t.Build {
+			fmt.Println("    build:      an image would be built")
+		}
+		if len(t.Configs) > 0 {
+			fmt.Println("    configs:")
+			for _, c := range t.Configs {
+				line := fmt.Sprintf("      %s = config %s", c.Env, c.Config)
+				if !c.Exists {
+					line = logger.Red("%s (does not exist)", line)
+				}
+				fmt.Println(line)
+			}
+		}
+		if len(t.Resources) > 0 {
+			fmt.Println("    resources:")
+			for _, r := range t.Resources {
+				line := fmt.Sprintf("      %s = %s", r.Ref, r.Name)
+				if r.ID == "" {
+					line = logger.Red("%s (does not exist)", line)
+				}
+				fmt.Println(line)
+			}
+		}
+		if len(t.EnvGroups) > 0 {
+			fmt.Printf("    env groups: %s\n", strings.Join(t.EnvGroups, ", "))
+		}
+		if len(t.Changes) > 0 {
+			fmt.Println("    changes:")
+			for _, c := range t.Changes {
+				fmt.Printf("      %s\n", c.Colored())
+			}
+		}
+		for _, p := range t.Problems {
+			fmt.Println(logger.Red("    error: %s", p))
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%s %d to create, %d to update, %d unchanged.\n",
+		logger.Bold("Plan:"), counts[deployCreated], counts[deployUpdated], counts[deployUnchanged])
+	if problems == 0 && counts[deployCreated]+counts[deployUpdated] > 0 {
+		logger.Suggest("⚡ To deploy:", "airplane tasks deploy "+strings.Join(cfg.paths, " "))
+	}
+}
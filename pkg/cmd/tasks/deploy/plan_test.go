@@ -0,0 +1,203 @@
+package deploy
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/utils/pointers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanDefinition(t *testing.T) {
+	ctx := context.Background()
+	t.Setenv("HOME", t.TempDir())
+	old := deployChecksums
+	deployChecksums = cache.New("deploys")
+	t.Cleanup(func() { deployChecksums = old })
+
+	// write writes a definition of the hello task and returns its path.
+	write := func(t *testing.T, def string) string {
+		path := filepath.Join(t.TempDir(), "hello.yml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(def), 0644))
+		return path
+	}
+	const hello = `
+slug: hello
+name: Hello
+image:
+  image: ubuntu:20.04
+  command: ["echo", "hello"]
+`
+	deployed := api.Task{
+		Slug:    "hello",
+		Name:    "Hello",
+		Kind:    "image",
+		Image:   pointers.String("ubuntu:20.04"),
+		Command: []string{"echo", "hello"},
+	}
+
+	t.Run("create", func(t *testing.T) {
+		require := require.New(t)
+		client := apitest.New()
+
+		task, err := planDefinition(ctx, config{client: client}, write(t, hello), nil)
+		require.NoError(err)
+		require.Equal("hello", task.Slug)
+		require.Equal(deployCreated, task.Action)
+		require.Empty(task.Problems)
+		// Every field of a new task is added.
+		require.NotEmpty(task.Changes)
+		for _, c := range task.Changes {
+			require.True(c.Added(), c.String())
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		require := require.New(t)
+		client := apitest.New()
+		client.AddTask(deployed)
+
+		task, err := planDefinition(ctx, config{client: client}, write(t, hello+"timeout: 300\n"), nil)
+		require.NoError(err)
+		require.Equal(deployUpdated, task.Action)
+		require.Equal([]definitions.Change{{Path: "timeout", New: 300}}, task.Changes)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		require := require.New(t)
+		client := apitest.New()
+		client.AddTask(deployed)
+
+		task, err := planDefinition(ctx, config{client: client}, write(t, hello), nil)
+		require.NoError(err)
+		require.Equal(deployUnchanged, task.Action)
+		require.Empty(task.Changes)
+		require.False(task.Build)
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		require := require.New(t)
+		client := apitest.New()
+		tsk := client.AddTask(deployed)
+
+		// A checksum matching the last deploy skips the deploy, even if the
+		// task changed since.
+		path := write(t, hello+"timeout: 300\n")
+		first, err := planDefinition(ctx, config{client: client}, path, nil)
+		require.NoError(err)
+		require.Equal(deployUpdated, first.Action)
+
+		dir, err := taskdir.Open(path, false)
+		require.NoError(err)
+		defer dir.Close()
+		def, err := dir.ReadDefinition()
+		require.NoError(err)
+		def, err = def.Validate()
+		require.NoError(err)
+		rev := client.AddTaskRevision(tsk.ID)
+		recordChecksum(tsk.ID, deployChecksum(dir.DefinitionRootPath(), &def, tsk.InterpolationMode), rev.ID)
+
+		task, err := planDefinition(ctx, config{client: client}, path, nil)
+		require.NoError(err)
+		require.Equal(deployUnchanged, task.Action)
+
+		// Unless --force is set.
+		task, err = planDefinition(ctx, config{client: client, force: true}, path, nil)
+		require.NoError(err)
+		require.Equal(deployUpdated, task.Action)
+
+		// Or the task was deployed from elsewhere since.
+		client.AddTaskRevision(tsk.ID)
+		task, err = planDefinition(ctx, config{client: client}, path, nil)
+		require.NoError(err)
+		require.Equal(deployUpdated, task.Action)
+	})
+
+	t.Run("references", func(t *testing.T) {
+		require := require.New(t)
+		client := apitest.New()
+		require.NoError(client.SetConfig(ctx, api.SetConfigRequest{Name: "db_url", Value: "postgres://"}))
+		require.NoError(client.SetEnvGroup(ctx, api.SetEnvGroupRequest{Name: "prod"}))
+		resources := []api.Resource{{ID: "res1", Name: "Main DB"}}
+		tsk := deployed
+		tsk.Resources = api.Resources{"db": "res1"}
+		client.AddTask(tsk)
+
+		task, err := planDefinition(ctx, config{client: client}, write(t, hello+`
+env:
+  DB_URL: {config: db_url}
+  API_KEY: {config: api_key}
+  DEBUG: {value: "1"}
+resources:
+  db: Main DB
+  cache: Redis
+envGroups: [prod, staging]
+`), resources)
+		require.NoError(err)
+		require.Equal([]plannedConfig{
+			{Env: "API_KEY", Config: "api_key", Exists: false},
+			{Env: "DB_URL", Config: "db_url", Exists: true},
+		}, task.Configs)
+		require.Equal([]plannedResource{
+			{Ref: "cache", Name: "Redis"},
+			{Ref: "db", Name: "Main DB", ID: "res1"},
+		}, task.Resources)
+		require.Equal([]string{"prod", "staging"}, task.EnvGroups)
+		// The db resource is compared by name with the deployed resource ID.
+		require.Equal([]definitions.Change{
+			{Path: "env", New: map[string]interface{}{
+				"API_KEY": map[string]interface{}{"config": "api_key"},
+				"DB_URL":  map[string]interface{}{"config": "db_url"},
+				"DEBUG":   map[string]interface{}{"value": "1"},
+			}},
+			{Path: "envGroups", New: []interface{}{"prod", "staging"}},
+			{Path: "resources.cache", New: "Redis"},
+		}, task.Changes)
+		require.Len(task.Problems, 3)
+		require.Equal("config api_key does not exist", task.Problems[0])
+		require.Equal("unknown resource: Redis", task.Problems[1])
+		require.Contains(task.Problems[2], "staging")
+	})
+
+	t.Run("normalized", func(t *testing.T) {
+		require := require.New(t)
+		client := apitest.New()
+		resources := []api.Resource{{ID: "res1", Name: "Main DB"}}
+		tsk := deployed
+		tsk.Resources = api.Resources{"db": "res1"}
+		empty := ""
+		tsk.Env = api.TaskEnv{"GIT_BRANCH": {Value: &empty}}
+		client.AddTask(tsk)
+
+		// Resources are compared by name, schedules are synced separately,
+		// x-cli is never deployed and git variables are expanded, which
+		// are empty outside of a repository.
+		task, err := planDefinition(ctx, config{client: client}, write(t, hello+`
+env:
+  GIT_BRANCH: {value: "${{ git.branch }}"}
+resources:
+  db: Main DB
+schedules:
+  daily:
+    cron: 0 9 * * *
+x-cli:
+  output: json
+`), resources)
+		require.NoError(err)
+		require.Equal(deployUnchanged, task.Action)
+		require.Empty(task.Changes)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		client := apitest.New()
+		_, err := planDefinition(ctx, config{client: client}, write(t, "slug: hello\nname: Hello\n"), nil)
+		require.Error(t, err)
+	})
+}
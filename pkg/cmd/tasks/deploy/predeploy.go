@@ -3,9 +3,9 @@ package deploy
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/airplanedev/cli/pkg/agents"
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/configs"
 	"github.com/airplanedev/cli/pkg/logger"
@@ -15,7 +15,7 @@ import (
 )
 
 // ensureConfigsExist checks for config references in env and asks users to create any missing ones
-func ensureConfigsExist(ctx context.Context, client *api.Client, def definitions.Definition) error {
+func ensureConfigsExist(ctx context.Context, client api.APIClient, def definitions.Definition) error {
 	// Check if configs exist
 	for k, v := range def.Env {
 		if v.Config != nil {
@@ -27,7 +27,7 @@ func ensureConfigsExist(ctx context.Context, client *api.Client, def definitions
 	return nil
 }
 
-func ensureConfigExists(ctx context.Context, client *api.Client, envName, configName string) error {
+func ensureConfigExists(ctx context.Context, client api.APIClient, envName, configName string) error {
 	cn, err := configs.ParseName(configName)
 	if err != nil {
 		return err
@@ -61,16 +61,15 @@ func ensureConfigExists(ctx context.Context, client *api.Client, envName, config
 	}
 }
 
-func createConfig(ctx context.Context, client *api.Client, cn configs.NameTag) error {
+func createConfig(ctx context.Context, client api.APIClient, cn configs.NameTag) error {
 	var secret bool
-	if err := survey.AskOne(
+	if err := utils.AskOne(
 		&survey.Confirm{
 			Message: "Is this config a secret?",
 			Help:    "Secret config values are not shown to users",
 			Default: false,
 		},
 		&secret,
-		survey.WithStdio(os.Stdin, os.Stderr, os.Stderr),
 	); err != nil {
 		return errors.Wrap(err, "prompting value")
 	}
@@ -80,3 +79,36 @@ func createConfig(ctx context.Context, client *api.Client, cn configs.NameTag) e
 	}
 	return configs.SetConfig(ctx, client, cn, value, secret)
 }
+
+// ensureEnvGroupsExist checks that every env group referenced by a task exists.
+func ensureEnvGroupsExist(ctx context.Context, client api.APIClient, names []string) error {
+	for _, name := range names {
+		if _, err := client.GetEnvGroup(ctx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warnIfNoMatchingAgent warns when no online agent can execute runs of a task
+// with the given constraints. Deploys are not blocked, since a matching agent
+// may be brought online after the task is deployed.
+func warnIfNoMatchingAgent(ctx context.Context, client api.APIClient, slug string, constraints api.RunConstraints) {
+	if err := agents.CheckConstraints(ctx, client, constraints); err != nil {
+		logger.Warning("Task %s: %s. Runs will be queued until a matching agent is online.", slug, err.Error())
+	}
+}
+
+// ensureCanDeploy checks that the user may create or update each task before
+// anything is built, since a build can take minutes before the API rejects
+// the deploy.
+func ensureCanDeploy(ctx context.Context, client api.APIClient, created []string, updated []string) error {
+	var checks []api.PermissionCheck
+	if len(created) > 0 {
+		checks = append(checks, api.PermissionCheck{Action: api.PermissionTasksCreate})
+	}
+	for _, slug := range updated {
+		checks = append(checks, api.PermissionCheck{Action: api.PermissionTasksUpdate, TaskSlug: slug})
+	}
+	return client.RequirePermissions(ctx, checks...)
+}
@@ -0,0 +1,44 @@
+package deploy
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/airplanedev/cli/pkg/build"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// progressFormats are the accepted values of --progress.
+var progressFormats = []string{"json"}
+
+// startProgress starts reporting the progress of builds in format to w, and
+// returns the channel to pass to builds and a function that stops reporting
+// once they have all returned.
+func startProgress(format string, w io.Writer) (chan<- build.Event, func(), error) {
+	if format != "json" {
+		return nil, nil, errors.Errorf("unknown --progress %q, expected one of %v", format, progressFormats)
+	}
+	events := make(chan build.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writeProgressJSON(w, events)
+	}()
+	return events, func() {
+		close(events)
+		<-done
+	}, nil
+}
+
+// writeProgressJSON writes each event it receives to w as a line of JSON,
+// until events is closed.
+func writeProgressJSON(w io.Writer, events <-chan build.Event) {
+	enc := json.NewEncoder(w)
+	for ev := range events {
+		ev.Message = logger.Redacted(ev.Message)
+		if err := enc.Encode(ev); err != nil {
+			logger.Debug("writing progress: %v", err)
+		}
+	}
+}
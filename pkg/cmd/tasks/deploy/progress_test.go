@@ -0,0 +1,24 @@
+package deploy
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/build"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	var buf bytes.Buffer
+	events, stop, err := startProgress("json", &buf)
+	require.NoError(t, err)
+	events <- build.Event{TaskSlug: "hello", Phase: build.PhaseUploading, Percent: 50}
+	events <- build.Event{TaskSlug: "hello", Phase: build.PhaseDone, Percent: 100, Message: "example.com/hello:abc"}
+	stop()
+	require.Equal(t, `{"taskSlug":"hello","phase":"uploading","percent":50}
+{"taskSlug":"hello","phase":"done","percent":100,"message":"example.com/hello:abc"}
+`, buf.String())
+
+	_, _, err = startProgress("yaml", &buf)
+	require.EqualError(t, err, `unknown --progress "yaml", expected one of [json]`)
+}
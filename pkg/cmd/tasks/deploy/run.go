@@ -0,0 +1,77 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/params"
+	"github.com/pkg/errors"
+)
+
+// runDeployed runs a task that was just deployed and follows its logs.
+//
+// The run's logs are prefixed like the build's, so that a deploy followed by
+// a run reads as one stream: [build slug] lines, then [run slug] lines.
+func runDeployed(ctx context.Context, client api.APIClient, slug string, args []string) error {
+	task, err := client.GetTask(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if err := client.RequirePermissions(ctx, api.PermissionCheck{
+		Action:   api.PermissionTasksExecute,
+		TaskSlug: task.Slug,
+	}); err != nil {
+		return err
+	}
+
+	values, err := params.CLI(args, client, task)
+	if errors.Is(err, params.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	w, err := client.Watcher(ctx, api.RunTaskRequest{
+		TaskID:      task.ID,
+		ParamValues: values,
+	})
+	if err != nil {
+		return err
+	}
+
+	prefix := fmt.Sprintf("[%s %s] ", logger.Blue("run"), task.Slug)
+	logger.Banner("")
+	logger.Banner(prefix+"Queued run: %s", logger.Gray(client.RunURL(w.RunID())))
+
+	var state api.RunState
+	for {
+		if state = w.Next(); state.Err() != nil {
+			return state.Err()
+		}
+		for _, l := range state.Logs {
+			text := l.Text
+			if strings.HasPrefix(text, "[agent]") {
+				text = logger.Gray(strings.TrimLeft(strings.TrimPrefix(text, "[agent]"), " "))
+			}
+			logger.Log(prefix + text)
+		}
+		if state.Stopped() {
+			break
+		}
+	}
+
+	switch state.Status {
+	case api.RunFailed:
+		logger.Log(prefix + logger.Bold(logger.Red("failed")))
+		return errors.New("Run has failed")
+	case api.RunCancelled:
+		logger.Log(prefix + logger.Bold(logger.Yellow("cancelled")))
+		return errors.New("Run was cancelled")
+	default:
+		logger.Log(prefix + logger.Bold(logger.Green("succeeded")))
+	}
+	return nil
+}
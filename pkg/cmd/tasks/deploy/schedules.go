@@ -0,0 +1,173 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/pkg/errors"
+)
+
+// syncSchedules makes a task's schedules match the schedules block of its
+// definition, matching schedules by slug. Schedules without a slug, such as
+// ones created in the UI, can't match the block and are deleted.
+//
+// Definitions without a schedules block leave the task's schedules alone, so
+// that schedules created in the UI or with `airplane schedules create` are
+// only managed from git once a definition opts in.
+func syncSchedules(ctx context.Context, client api.APIClient, taskID string, defs map[string]definitions.ScheduleDefinition) error {
+	if defs == nil {
+		return nil
+	}
+
+	resp, err := client.ListSchedules(ctx, api.ListSchedulesRequest{TaskID: taskID})
+	if err != nil {
+		return errors.Wrap(err, "listing schedules")
+	}
+	existing := map[string]api.Schedule{}
+	var removed []api.Schedule
+	for _, s := range resp.Schedules {
+		if s.Slug == "" {
+			removed = append(removed, s)
+			continue
+		}
+		existing[s.Slug] = s
+	}
+
+	slugs := make([]string, 0, len(defs))
+	for slug := range defs {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	for _, slug := range slugs {
+		def := defs[slug]
+		s, ok := existing[slug]
+		delete(existing, slug)
+
+		switch {
+		case !ok:
+			if err := createSchedule(ctx, client, taskID, slug, def); err != nil {
+				return err
+			}
+			logger.Log("Created schedule %s (%s).", logger.Bold(slug), def.CronExpr)
+		case !sameSchedule(s, def):
+			// Schedules can't be edited in place, so a changed schedule is
+			// replaced. Its next run is computed afresh from the new cron.
+			if err := replaceSchedule(ctx, client, taskID, s, def); err != nil {
+				return err
+			}
+			logger.Log("Updated schedule %s (%s).", logger.Bold(slug), def.CronExpr)
+		case s.Paused != def.Paused:
+			if err := client.PauseSchedule(ctx, api.PauseScheduleRequest{ScheduleID: s.ID, Paused: def.Paused}); err != nil {
+				return errors.Wrapf(err, "pausing schedule %s", slug)
+			}
+			if def.Paused {
+				logger.Log("Paused schedule %s.", logger.Bold(slug))
+			} else {
+				logger.Log("Resumed schedule %s.", logger.Bold(slug))
+			}
+		}
+	}
+
+	for _, s := range existing {
+		removed = append(removed, s)
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		if removed[i].Slug != removed[j].Slug {
+			return removed[i].Slug < removed[j].Slug
+		}
+		return removed[i].ID < removed[j].ID
+	})
+	for _, s := range removed {
+		if err := client.DeleteSchedule(ctx, api.DeleteScheduleRequest{ScheduleID: s.ID}); err != nil {
+			return errors.Wrapf(err, "deleting schedule %s", scheduleName(s))
+		}
+		logger.Log("Deleted schedule %s.", logger.Bold(scheduleName(s)))
+	}
+	return nil
+}
+
+// scheduleName names s in logs: by slug, or by name and ID for schedules
+// without one.
+func scheduleName(s api.Schedule) string {
+	switch {
+	case s.Slug != "":
+		return s.Slug
+	case s.Name != "":
+		return fmt.Sprintf("%q (%s)", s.Name, s.ID)
+	}
+	return s.ID
+}
+
+func createSchedule(ctx context.Context, client api.APIClient, taskID, slug string, def definitions.ScheduleDefinition) error {
+	_, err := client.CreateSchedule(ctx, api.CreateScheduleRequest{
+		TaskID:      taskID,
+		Slug:        slug,
+		Name:        def.Name,
+		Description: def.Description,
+		CronExpr:    def.CronExpr,
+		Timezone:    def.Timezone,
+		ParamValues: def.ParamValues,
+		Paused:      def.Paused,
+	})
+	return errors.Wrapf(err, "creating schedule %s", slug)
+}
+
+// replaceSchedule replaces old with a schedule of the same slug as def
+// describes. Slugs are unique, so old is deleted first: if the new schedule
+// can't be created, such as because its cron is invalid, old is restored.
+func replaceSchedule(ctx context.Context, client api.APIClient, taskID string, old api.Schedule, def definitions.ScheduleDefinition) error {
+	if err := client.DeleteSchedule(ctx, api.DeleteScheduleRequest{ScheduleID: old.ID}); err != nil {
+		return errors.Wrapf(err, "deleting schedule %s", old.Slug)
+	}
+	err := createSchedule(ctx, client, taskID, old.Slug, def)
+	if err == nil {
+		return nil
+	}
+	if _, rerr := client.CreateSchedule(ctx, api.CreateScheduleRequest{
+		TaskID:      taskID,
+		Slug:        old.Slug,
+		Name:        old.Name,
+		Description: old.Description,
+		CronExpr:    old.CronExpr,
+		Timezone:    old.Timezone,
+		ParamValues: old.ParamValues,
+		Paused:      old.Paused,
+	}); rerr != nil {
+		return errors.Errorf("%v, and restoring the previous schedule failed: %v", err, rerr)
+	}
+	return err
+}
+
+// sameSchedule reports whether s already runs as def describes, ignoring
+// whether it is paused.
+func sameSchedule(s api.Schedule, def definitions.ScheduleDefinition) bool {
+	tz := func(tz string) string {
+		if tz == "" {
+			return "UTC"
+		}
+		return tz
+	}
+	// Compare param values as JSON, since the API returns numbers as floats
+	// that a YAML definition decodes as ints.
+	values := func(v api.Values) string {
+		if len(v) == 0 {
+			return ""
+		}
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(buf)
+	}
+	return s.Name == def.Name &&
+		s.Description == def.Description &&
+		s.CronExpr == def.CronExpr &&
+		tz(s.Timezone) == tz(def.Timezone) &&
+		values(s.ParamValues) == values(def.ParamValues)
+}
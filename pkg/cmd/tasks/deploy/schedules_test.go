@@ -0,0 +1,129 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncSchedules(t *testing.T) {
+	ctx := context.Background()
+
+	// setup returns a fake with a task that has a daily and an hourly
+	// schedule, and one without a slug created in the UI.
+	setup := func(t *testing.T) (*apitest.Client, api.Task) {
+		client := apitest.New()
+		task := client.AddTask(api.Task{Slug: "sync_accounts"})
+		for _, req := range []api.CreateScheduleRequest{
+			{Slug: "daily", Name: "Daily", CronExpr: "0 9 * * *"},
+			{Slug: "hourly", Name: "Hourly", CronExpr: "0 * * * *"},
+			{Name: "From the UI", CronExpr: "*/5 * * * *"},
+		} {
+			req.TaskID = task.ID
+			_, err := client.CreateSchedule(ctx, req)
+			require.NoError(t, err)
+		}
+		return client, task
+	}
+	list := func(t *testing.T, client *apitest.Client, task api.Task) map[string]api.Schedule {
+		resp, err := client.ListSchedules(ctx, api.ListSchedulesRequest{TaskID: task.ID})
+		require.NoError(t, err)
+		schedules := map[string]api.Schedule{}
+		for _, s := range resp.Schedules {
+			schedules[s.Slug] = s
+		}
+		require.Len(t, schedules, len(resp.Schedules))
+		return schedules
+	}
+
+	t.Run("without a schedules block", func(t *testing.T) {
+		client, task := setup(t)
+		require.NoError(t, syncSchedules(ctx, client, task.ID, nil))
+		resp, err := client.ListSchedules(ctx, api.ListSchedulesRequest{TaskID: task.ID})
+		require.NoError(t, err)
+		require.Len(t, resp.Schedules, 3)
+	})
+
+	t.Run("create, update and delete", func(t *testing.T) {
+		require := require.New(t)
+		client, task := setup(t)
+		before := list(t, client, task)
+
+		require.NoError(syncSchedules(ctx, client, task.ID, map[string]definitions.ScheduleDefinition{
+			"daily":  {Name: "Daily", CronExpr: "0 9 * * *"},
+			"hourly": {Name: "Hourly", CronExpr: "30 * * * *"},
+			"weekly": {Name: "Weekly", CronExpr: "0 9 * * 1", ParamValues: map[string]interface{}{"full": true}},
+		}))
+
+		after := list(t, client, task)
+		require.Len(after, 3)
+		// Unchanged schedules are kept as is.
+		require.Equal(before["daily"].ID, after["daily"].ID)
+		// Changed schedules are replaced.
+		require.NotEqual(before["hourly"].ID, after["hourly"].ID)
+		require.Equal("30 * * * *", after["hourly"].CronExpr)
+		// New schedules are created.
+		require.Equal("0 9 * * 1", after["weekly"].CronExpr)
+		require.Equal(api.Values{"full": true}, after["weekly"].ParamValues)
+		// Schedules missing from the block, including the one without a
+		// slug, are deleted.
+		_, ok := after[""]
+		require.False(ok)
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		require := require.New(t)
+		client, task := setup(t)
+		before := list(t, client, task)
+
+		require.NoError(syncSchedules(ctx, client, task.ID, map[string]definitions.ScheduleDefinition{
+			"daily": {Name: "Daily", CronExpr: "0 9 * * *", Paused: true},
+		}))
+
+		after := list(t, client, task)
+		require.Len(after, 1)
+		require.Equal(before["daily"].ID, after["daily"].ID)
+		require.True(after["daily"].Paused)
+	})
+
+	t.Run("failed update", func(t *testing.T) {
+		require := require.New(t)
+		client, task := setup(t)
+		before := list(t, client, task)
+
+		err := syncSchedules(ctx, failingCreate{client}, task.ID, map[string]definitions.ScheduleDefinition{
+			"daily":  {Name: "Daily", CronExpr: "0 9 * * *"},
+			"hourly": {Name: "Hourly", CronExpr: "invalid"},
+		})
+		require.EqualError(err, "creating schedule hourly: invalid cron expression")
+
+		// The previous schedule was restored.
+		after := list(t, client, task)
+		require.Len(after, 3)
+		require.Equal("Hourly", after["hourly"].Name)
+		require.Equal(before["hourly"].CronExpr, after["hourly"].CronExpr)
+	})
+
+	t.Run("empty block", func(t *testing.T) {
+		client, task := setup(t)
+		require.NoError(t, syncSchedules(ctx, client, task.ID, map[string]definitions.ScheduleDefinition{}))
+		require.Empty(t, list(t, client, task))
+	})
+}
+
+// failingCreate rejects schedules with an invalid cron expression.
+type failingCreate struct {
+	api.APIClient
+}
+
+func (c failingCreate) CreateSchedule(ctx context.Context, req api.CreateScheduleRequest) (api.CreateScheduleResponse, error) {
+	if req.CronExpr == "invalid" {
+		return api.CreateScheduleResponse{}, errors.New("invalid cron expression")
+	}
+	return c.APIClient.CreateSchedule(ctx, req)
+}
@@ -22,7 +22,6 @@ import (
 	"github.com/airplanedev/lib/pkg/runtime"
 	"github.com/go-git/go-git/v5"
 	"github.com/pkg/errors"
-	"golang.org/x/sync/errgroup"
 )
 
 var ignoredDirectories = map[string]bool{
@@ -34,15 +33,14 @@ var ignoredDirectories = map[string]bool{
 type scriptDeployer struct {
 	deployer *build.Deployer
 
-	erroredTaskSlugs  map[string]error
 	deployedTaskSlugs []string
+	summary           deploySummary
 	mu                sync.Mutex
 }
 
 func NewDeployer() *scriptDeployer {
 	return &scriptDeployer{
-		deployer:         build.NewDeployer(),
-		erroredTaskSlugs: make(map[string]error),
+		deployer: build.NewDeployer(),
 	}
 }
 
@@ -58,7 +56,7 @@ func (d *scriptDeployer) deployFromScript(ctx context.Context, cfg config) error
 
 	var taskConfigs []taskConfig
 	for _, script := range scriptsToDeploy {
-		tc, err := getTaskConfigFromScript(ctx, *cfg.client, script)
+		tc, err := getTaskConfigFromScript(ctx, cfg.client, script)
 		if err != nil {
 			return err
 		}
@@ -83,63 +81,126 @@ func (d *scriptDeployer) deployFromScript(ctx context.Context, cfg config) error
 		taskConfigs = filteredTaskConfigs
 	}
 
+	if cfg.interactive && len(taskConfigs) > 1 {
+		taskConfigs, err = selectTasks(ctx, cfg, taskConfigs)
+		if err != nil {
+			return err
+		}
+		// Deploy what was picked, even if it is unchanged.
+		cfg.force = true
+	}
+
 	if len(taskConfigs) == 0 {
 		logger.Log("No tasks to deploy")
 		return nil
 	}
 
+	journal, err := openJournal(cfg.paths, cfg.resume)
+	if err != nil {
+		return err
+	}
+	var remaining []taskConfig
+	for _, tc := range taskConfigs {
+		if t, ok := journal.completed(tc.task.Slug); ok {
+			logResumed(tc.task.Slug)
+			d.summary.add(t, nil)
+			continue
+		}
+		remaining = append(remaining, tc)
+	}
+	if len(remaining) == 0 {
+		logger.Log("All tasks already deployed")
+		journal.finish()
+		return nil
+	}
+	resumed := len(taskConfigs) - len(remaining)
+	taskConfigs = remaining
+
+	var slugs []string
+	for _, tc := range taskConfigs {
+		slugs = append(slugs, tc.task.Slug)
+	}
+	if err := ensureCanDeploy(ctx, cfg.client, nil, slugs); err != nil {
+		return err
+	}
+
 	// Print out a summary before deploying.
 	noun := "task"
 	if len(taskConfigs) > 1 {
 		noun = fmt.Sprintf("%ss", noun)
 	}
-	logger.Log("Deploying %v %v:\n", len(taskConfigs), noun)
+	logger.Banner("Deploying %v %v:\n", len(taskConfigs), noun)
 	for _, tc := range taskConfigs {
-		logger.Log(logger.Bold(tc.task.Slug))
-		logger.Log("Type: %s", tc.task.Kind)
-		logger.Log("Root directory: %s", relpath(tc.taskRoot))
+		logger.Banner(logger.Bold(tc.task.Slug))
+		logger.Banner("Type: %s", tc.task.Kind)
+		logger.Banner("Root directory: %s", relpath(tc.taskRoot))
 		if tc.workingDirectory != tc.taskRoot {
-			logger.Log("Working directory: %s", relpath(tc.workingDirectory))
+			logger.Banner("Working directory: %s", relpath(tc.workingDirectory))
 		}
-		logger.Log("URL: %s", cfg.client.TaskURL(tc.task.Slug))
-		logger.Log("")
+		logger.Banner("URL: %s", cfg.client.TaskURL(tc.task.Slug))
+		logger.Banner("")
 	}
 
-	g := new(errgroup.Group)
-	// Concurrently deploy the tasks.
+	// Concurrently deploy the tasks, cfg.buildConcurrency at a time.
+	sched := build.NewScheduler(cfg.buildConcurrency)
 	for _, tc := range taskConfigs {
 		tc := tc
-		g.Go(func() error {
-			err := d.deploySingleTaskFromScript(ctx, cfg, tc)
+		sched.Go(ctx, tc.task.Slug, func(ctx context.Context) error {
+			entry, err := d.deploySingleTaskFromScript(ctx, cfg, tc)
+			if errors.As(err, &runtime.ErrNotLinked{}) {
+				journal.record(tc.task.Slug, entry, nil)
+			} else {
+				journal.record(tc.task.Slug, entry, err)
+			}
 			d.mu.Lock()
 			defer d.mu.Unlock()
 			if err != nil {
 				if !errors.As(err, &runtime.ErrNotLinked{}) {
-					d.erroredTaskSlugs[tc.task.Slug] = err
+					d.summary.add(entry, err)
 					return err
 				}
 			} else {
 				d.deployedTaskSlugs = append(d.deployedTaskSlugs, tc.task.Slug)
+				d.summary.add(entry, nil)
 			}
 			return nil
 		})
 	}
 
-	groupErr := g.Wait()
+	schedErr := sched.Wait()
 
-	// All of the deploys have finished.
-	for taskSlug, err := range d.erroredTaskSlugs {
-		logger.Log("\n" + logger.Bold(taskSlug))
-		logger.Log("Status: " + logger.Bold(logger.Red("failed")))
-		logger.Error(err.Error())
+	// All of the deploys have finished. Their failures are printed together,
+	// since their logs were interleaved.
+	var schedErrs *build.SchedulerError
+	if errors.As(schedErr, &schedErrs) {
+		for _, taskSlug := range schedErrs.Names() {
+			logger.Log("\n" + logger.Bold(taskSlug))
+			logger.Log("Status: " + logger.Bold(logger.Red("failed")))
+			logger.Error(schedErrs.Errors[taskSlug].Error())
+		}
 	}
 	for _, slug := range d.deployedTaskSlugs {
 		logger.Log("\n" + logger.Bold(slug))
 		logger.Log("Status: %s", logger.Bold(logger.Green("succeeded")))
-		logger.Log("Execute the task: %s", cfg.client.TaskURL(slug))
+		logger.Banner("Execute the task: %s", cfg.client.TaskURL(slug))
 	}
 
-	return groupErr
+	if len(taskConfigs)+resumed > 1 {
+		logger.Log("")
+		d.summary.print()
+	}
+	journal.finish()
+	if cfg.summary != nil {
+		for _, t := range d.summary.sorted() {
+			cfg.summary.add(t, nil)
+		}
+	} else if cfg.summaryFile != "" {
+		if err := d.summary.write(cfg.summaryFile); err != nil {
+			return err
+		}
+	}
+
+	return schedErr
 }
 
 type script struct {
@@ -193,7 +254,7 @@ func (d *scriptDeployer) discoverScripts(ctx context.Context, paths ...string) (
 	return scripts, nil
 }
 
-func (d *scriptDeployer) deploySingleTaskFromScript(ctx context.Context, cfg config, tc taskConfig) (rErr error) {
+func (d *scriptDeployer) deploySingleTaskFromScript(ctx context.Context, cfg config, tc taskConfig) (entry deployedTask, rErr error) {
 	client := cfg.client
 	tp := taskDeployedProps{
 		from: "script",
@@ -213,6 +274,7 @@ func (d *scriptDeployer) deploySingleTaskFromScript(ctx context.Context, cfg con
 	}()
 
 	task := tc.task
+	entry = deployedTask{Slug: task.Slug, Action: deployUpdated}
 
 	tp.kind = tc.kind
 	tp.taskID = task.ID
@@ -227,7 +289,7 @@ func (d *scriptDeployer) deploySingleTaskFromScript(ctx context.Context, cfg con
 More information: https://apn.sh/jst-upgrade`)
 			interpolationMode = "jst"
 			if err := tc.def.UpgradeJST(); err != nil {
-				return err
+				return entry, err
 			}
 		} else {
 			logger.Warning(`Tasks are migrating from handlebars to Airplane JS Templates! Your task has not
@@ -240,6 +302,13 @@ More information: https://apn.sh/jst-upgrade`)
 		}
 	}
 
+	checksum := deployChecksum(tc.taskRoot, tc.def, interpolationMode)
+	if isUpToDate(ctx, cfg, task.ID, checksum) {
+		logUpToDate(task.Slug)
+		entry.Action = deployUnchanged
+		return entry, nil
+	}
+
 	gitMeta, err := getGitMetadata(tc.taskFilePath)
 	if err != nil {
 		logger.Debug("failed to gather git metadata: %v", err)
@@ -250,35 +319,64 @@ More information: https://apn.sh/jst-upgrade`)
 
 	env, err := tc.def.GetEnv()
 	if err != nil {
-		return err
+		return entry, err
 	}
+	buildStart := time.Now()
 	resp, err := build.Run(ctx, d.deployer, build.Request{
-		Local:   cfg.local,
-		Client:  client,
-		TaskID:  task.ID,
-		Root:    tc.taskRoot,
-		Def:     tc.def,
-		TaskEnv: env,
-		Shim:    true,
-		GitMeta: gitMeta,
+		Local:            cfg.local,
+		Client:           client,
+		TaskID:           task.ID,
+		Root:             tc.taskRoot,
+		Def:              tc.def,
+		TaskEnv:          env,
+		Shim:             true,
+		GitMeta:          gitMeta,
+		Compression:      build.Compression(cfg.compression),
+		CompressionLevel: cfg.compressionLevel,
+		Builder:          cfg.builder,
+		Audit:            cfg.audit,
+		Lint:             &build.LintOptions{Strict: cfg.lintStrict},
+		Healthcheck:      cfg.healthcheck,
+		Progress:         cfg.progressEvents,
 	})
 	if err != nil {
-		return err
+		return entry, err
 	}
 	tp.buildID = resp.BuildID
+	entry.BuildID = resp.BuildID
+	entry.BuildDurationSeconds = time.Since(buildStart).Seconds()
+	entry.Image = resp.ImageURL
+	entry.ImageDigest = resp.ImageDigest
+
+	signature, err := signImage(ctx, cfg, resp)
+	if err != nil {
+		return entry, err
+	}
+	if signature != nil {
+		entry.ImageSignature = *signature
+	}
 
 	utr, err := tc.def.GetUpdateTaskRequest(ctx, client, &resp.ImageURL)
 	if err != nil {
-		return err
+		return entry, err
 	}
 
 	utr.BuildID = pointers.String(resp.BuildID)
+	utr.ImageSignature = signature
 	utr.InterpolationMode = interpolationMode
 	utr.RequireExplicitPermissions = task.RequireExplicitPermissions
 	utr.Permissions = task.Permissions
+	utr.Annotation = newAnnotation(tc.def, gitMeta)
 
-	_, err = client.UpdateTask(ctx, utr)
-	return err
+	res, err := client.UpdateTask(ctx, utr)
+	if err != nil {
+		return entry, err
+	}
+	entry.TaskRevisionID = res.TaskRevisionID
+	entry.Changes = taskChanges(ctx, client, task)
+	logTaskChanges(task.Slug, entry.Changes)
+	recordChecksum(task.ID, checksum, res.TaskRevisionID)
+	return entry, nil
 }
 
 type taskConfig struct {
@@ -292,7 +390,7 @@ type taskConfig struct {
 }
 
 // getTaskConfig a task and associated information from a script.
-func getTaskConfigFromScript(ctx context.Context, client api.Client, script script) (taskConfig, error) {
+func getTaskConfigFromScript(ctx context.Context, client api.APIClient, script script) (taskConfig, error) {
 	task, err := client.GetTask(ctx, script.taskSlug)
 	if err != nil {
 		return taskConfig{}, err
@@ -303,6 +401,7 @@ func getTaskConfigFromScript(ctx context.Context, client api.Client, script scri
 		return taskConfig{}, errors.Wrapf(err, "cannot determine how to deploy %q - check your CLI is up to date", script.file)
 	}
 
+	definitions.WarnDeprecations(task.Slug, definitions.TaskDeprecations(task))
 	def, err := definitions.NewDefinitionFromTask(task)
 	if err != nil {
 		return taskConfig{}, err
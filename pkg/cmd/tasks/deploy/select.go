@@ -0,0 +1,55 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// selectTasks asks the user which of taskConfigs to deploy. Tasks that
+// changed since they were last deployed are selected by default.
+func selectTasks(ctx context.Context, cfg config, taskConfigs []taskConfig) ([]taskConfig, error) {
+	var options, defaults []string
+	byOption := make(map[string]taskConfig, len(taskConfigs))
+	for _, tc := range taskConfigs {
+		option := fmt.Sprintf("%s (%s)", tc.task.Slug, relpath(tc.taskFilePath))
+		options = append(options, option)
+		byOption[option] = tc
+		if hasChanged(ctx, cfg, tc) {
+			defaults = append(defaults, option)
+		}
+	}
+
+	var selected []string
+	if err := utils.AskOne(
+		&survey.MultiSelect{
+			Message:  "Which tasks would you like to deploy?",
+			Options:  options,
+			Default:  defaults,
+			PageSize: 15,
+		},
+		&selected,
+	); err != nil {
+		return nil, errors.Wrap(err, "selecting tasks")
+	}
+
+	res := make([]taskConfig, 0, len(selected))
+	for _, option := range selected {
+		res = append(res, byOption[option])
+	}
+	return res, nil
+}
+
+// hasChanged reports whether a task would be redeployed, judging by the
+// checksum of its last deploy.
+func hasChanged(ctx context.Context, cfg config, tc taskConfig) bool {
+	interpolationMode := tc.task.InterpolationMode
+	if interpolationMode != "jst" && cfg.upgradeInterpolation {
+		// Upgrading changes the task's definition.
+		return true
+	}
+	return !isUpToDate(ctx, cfg, tc.task.ID, deployChecksum(tc.taskRoot, tc.def, interpolationMode))
+}
@@ -0,0 +1,25 @@
+package deploy
+
+import (
+	"context"
+
+	"github.com/airplanedev/cli/pkg/build"
+	"github.com/airplanedev/cli/pkg/logger"
+)
+
+// signImage signs a freshly built image when signing was requested and
+// returns the signature reference to record on the task revision.
+func signImage(ctx context.Context, cfg config, resp *build.Response) (*string, error) {
+	if !cfg.sign && cfg.signKey == "" {
+		return nil, nil
+	}
+
+	sig, err := build.Sign(ctx, resp.ImageURL, resp.ImageDigest, build.SignOptions{
+		Key: cfg.signKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Log("Signed image: %s", logger.Gray(sig))
+	return &sig, nil
+}
@@ -0,0 +1,134 @@
+package deploy
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+)
+
+// deployAction describes what a deploy did to a task.
+type deployAction string
+
+const (
+	deployCreated deployAction = "created"
+	deployUpdated deployAction = "updated"
+	// deployUnchanged is recorded when a deploy is skipped because nothing
+	// changed since the last deploy.
+	deployUnchanged deployAction = "unchanged"
+	deployFailed    deployAction = "failed"
+)
+
+// deployedTask records the outcome of deploying a single task.
+type deployedTask struct {
+	Slug                 string       `json:"slug"`
+	Action               deployAction `json:"action"`
+	BuildID              string       `json:"buildID,omitempty"`
+	BuildDurationSeconds float64      `json:"buildDurationSeconds,omitempty"`
+	Image                string       `json:"image,omitempty"`
+	ImageDigest          string       `json:"imageDigest,omitempty"`
+	ImageSignature       string       `json:"imageSignature,omitempty"`
+	TaskRevisionID       string       `json:"taskRevisionID,omitempty"`
+	// Changes are the fields of the task that the deploy changed.
+	Changes []definitions.Change `json:"changes,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// deploySummary collects the outcome of every task in a deploy.
+//
+// It is safe for concurrent use.
+type deploySummary struct {
+	mu    sync.Mutex
+	tasks []deployedTask
+}
+
+// add records the outcome of deploying a task. If err is non-nil, the task
+// is recorded as failed regardless of t.Action.
+func (s *deploySummary) add(t deployedTask, err error) {
+	if err != nil {
+		t.Action = deployFailed
+		t.Error = err.Error()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks = append(s.tasks, t)
+}
+
+// sorted returns the recorded tasks ordered by slug.
+func (s *deploySummary) sorted() []deployedTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := make([]deployedTask, len(s.tasks))
+	copy(tasks, s.tasks)
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Slug < tasks[j].Slug
+	})
+	return tasks
+}
+
+// since returns the tasks recorded after the first n, in the order they were
+// recorded.
+func (s *deploySummary) since(n int) []deployedTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n >= len(s.tasks) {
+		return nil
+	}
+	tasks := make([]deployedTask, len(s.tasks)-n)
+	copy(tasks, s.tasks[n:])
+	return tasks
+}
+
+// print writes the summary as a table to stderr, alongside the rest of the
+// deploy output.
+func (s *deploySummary) print() {
+	tw := tablewriter.NewWriter(os.Stderr)
+	tw.SetBorder(false)
+	tw.SetAutoWrapText(false)
+	tw.SetHeader([]string{"task", "action", "build", "image digest", "revision"})
+
+	for _, t := range s.sorted() {
+		build := "-"
+		if t.BuildDurationSeconds > 0 {
+			build = utils.FormatDuration(time.Duration(t.BuildDurationSeconds * float64(time.Second)))
+		}
+		tw.Append([]string{
+			t.Slug,
+			string(t.Action),
+			build,
+			orDash(t.ImageDigest),
+			orDash(t.TaskRevisionID),
+		})
+	}
+
+	tw.Render()
+}
+
+// write writes the summary as JSON to the given path.
+func (s *deploySummary) write(path string) error {
+	buf, err := json.MarshalIndent(struct {
+		Tasks []deployedTask `json:"tasks"`
+	}{s.sorted()}, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshalling deploy summary")
+	}
+	if err := ioutil.WriteFile(path, append(buf, '\n'), 0644); err != nil {
+		return errors.Wrapf(err, "writing deploy summary to %s", path)
+	}
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
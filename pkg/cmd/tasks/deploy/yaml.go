@@ -10,6 +10,7 @@ import (
 	"github.com/airplanedev/cli/pkg/build"
 	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
 	libBuild "github.com/airplanedev/lib/pkg/build"
 	"github.com/pkg/errors"
 )
@@ -20,8 +21,18 @@ func deployFromYaml(ctx context.Context, cfg config) (rErr error) {
 	props := taskDeployedProps{
 		from: "yaml",
 	}
+	entry := deployedTask{Action: deployUpdated}
 	start := time.Now()
 	defer func() {
+		if cfg.summary != nil && entry.Slug != "" {
+			cfg.summary.add(entry, rErr)
+		} else if cfg.summaryFile != "" && entry.Slug != "" {
+			var summary deploySummary
+			summary.add(entry, rErr)
+			if err := summary.write(cfg.summaryFile); err != nil && rErr == nil {
+				rErr = err
+			}
+		}
 		analytics.Track(cfg.root, "Task Deployed", map[string]interface{}{
 			"from":             props.from,
 			"kind":             props.kind,
@@ -39,6 +50,7 @@ func deployFromYaml(ctx context.Context, cfg config) (rErr error) {
 		return err
 	}
 	defer dir.Close()
+	dir.Strict = cfg.strict
 
 	def, err := dir.ReadDefinition()
 	if err != nil {
@@ -49,12 +61,24 @@ func deployFromYaml(ctx context.Context, cfg config) (rErr error) {
 	if err != nil {
 		return err
 	}
+	if err := readDescription(&def.Description, dir.DefinitionPath()); err != nil {
+		return err
+	}
+	if err := definitions.ValidateSchedules(def.Schedules); err != nil {
+		return err
+	}
+	definitions.WarnDeprecations(relpath(dir.DefinitionPath()), def.GetDeprecations())
 	props.taskSlug = def.Slug
+	entry.Slug = def.Slug
 
 	err = ensureConfigsExist(ctx, client, def)
 	if err != nil {
 		return err
 	}
+	if err := ensureEnvGroupsExist(ctx, client, def.EnvGroups); err != nil {
+		return err
+	}
+	warnIfNoMatchingAgent(ctx, client, def.Slug, def.Constraints)
 
 	kind, kindOptions, err := def.GetKindAndOptions()
 	if err != nil {
@@ -82,6 +106,7 @@ func deployFromYaml(ctx context.Context, cfg config) (rErr error) {
 
 	var image *string
 	var command []string
+	var signature *string
 	if def.Image != nil {
 		image = &def.Image.Image
 		command = def.Image.Command
@@ -90,6 +115,9 @@ func deployFromYaml(ctx context.Context, cfg config) (rErr error) {
 	task, err := client.GetTask(ctx, def.Slug)
 	if _, ok := err.(*api.TaskMissingError); ok {
 		// A task with this slug does not exist, so we should create one.
+		if err := ensureCanDeploy(ctx, client, []string{def.Slug}, nil); err != nil {
+			return err
+		}
 		logger.Log("Creating task...")
 		_, err = client.CreateTask(ctx, api.CreateTaskRequest{
 			Slug:             def.Slug,
@@ -99,14 +127,19 @@ func deployFromYaml(ctx context.Context, cfg config) (rErr error) {
 			Command:          command,
 			Arguments:        def.Arguments,
 			Parameters:       def.Parameters,
+			Form:             def.Form,
 			Constraints:      def.Constraints,
 			Env:              def.Env,
+			EnvGroups:        def.EnvGroups,
 			ResourceRequests: def.ResourceRequests,
 			Resources:        resources,
 			Kind:             kind,
 			KindOptions:      kindOptions,
 			Repo:             def.Repo,
 			Timeout:          def.Timeout,
+			Concurrency:      def.Concurrency,
+			Confirm:          def.Confirm,
+			Dependencies:     def.Dependencies,
 		})
 		if err != nil {
 			return errors.Wrapf(err, "creating task %s", def.Slug)
@@ -116,8 +149,11 @@ func deployFromYaml(ctx context.Context, cfg config) (rErr error) {
 		if err != nil {
 			return errors.Wrap(err, "fetching created task")
 		}
+		entry.Action = deployCreated
 	} else if err != nil {
 		return errors.Wrap(err, "getting task")
+	} else if err := ensureCanDeploy(ctx, client, nil, []string{def.Slug}); err != nil {
+		return err
 	}
 	props.taskID = task.ID
 	props.taskName = task.Name
@@ -142,27 +178,68 @@ More information: https://apn.sh/jst-upgrade`)
 		}
 	}
 
+	checksum := deployChecksum(dir.DefinitionRootPath(), &def, interpolationMode)
+	if isUpToDate(ctx, cfg, task.ID, checksum) {
+		logUpToDate(def.Slug)
+		entry.Action = deployUnchanged
+		// Schedules may have been changed since, such as in the UI, so a
+		// definition that manages them still syncs them.
+		if err := syncSchedules(ctx, client, task.ID, def.Schedules); err != nil {
+			return errors.Wrapf(err, "updating schedules of %s", def.Slug)
+		}
+		return nil
+	}
+	gitMeta, err := getGitMetadata(dir.DefinitionPath())
+	if err != nil {
+		logger.Debug("failed to gather git metadata: %v", err)
+	}
+	// Hash the definition as written, before git variables are expanded.
+	annotation := newAnnotation(def, gitMeta)
+	if err := definitions.ExpandGitVars(&def, dir.DefinitionRootPath()); err != nil {
+		return err
+	}
+
 	if ok, err := libBuild.NeedsBuilding(kind); err != nil {
 		return err
 	} else if ok {
+		buildStart := time.Now()
 		resp, err := build.Run(ctx, build.NewDeployer(), build.Request{
-			Local:  cfg.local,
-			Client: client,
-			Root:   dir.DefinitionRootPath(),
-			Def:    &def,
-			TaskID: task.ID,
+			Local:            cfg.local,
+			Client:           client,
+			Root:             dir.DefinitionRootPath(),
+			Def:              &def,
+			TaskID:           task.ID,
+			Compression:      build.Compression(cfg.compression),
+			CompressionLevel: cfg.compressionLevel,
+			Builder:          cfg.builder,
+			Audit:            cfg.audit,
+			Lint:             &build.LintOptions{Strict: cfg.lintStrict},
+			Healthcheck:      cfg.healthcheck,
+			Progress:         cfg.progressEvents,
 		})
 		props.buildLocal = cfg.local
 		if resp != nil {
 			props.buildID = resp.BuildID
+			entry.BuildID = resp.BuildID
 		}
 		if err != nil {
 			return err
 		}
 		image = &resp.ImageURL
+		entry.BuildDurationSeconds = time.Since(buildStart).Seconds()
+		entry.Image = resp.ImageURL
+		entry.ImageDigest = resp.ImageDigest
+
+		signature, err = signImage(ctx, cfg, resp)
+		if err != nil {
+			return err
+		}
+		if signature != nil {
+			entry.ImageSignature = *signature
+		}
 	}
 
-	_, err = client.UpdateTask(ctx, api.UpdateTaskRequest{
+	res, err := client.UpdateTask(ctx, api.UpdateTaskRequest{
 		Slug:                       def.Slug,
 		Name:                       def.Name,
 		Description:                def.Description,
@@ -170,8 +247,10 @@ More information: https://apn.sh/jst-upgrade`)
 		Command:                    command,
 		Arguments:                  def.Arguments,
 		Parameters:                 def.Parameters,
+		Form:                       def.Form,
 		Constraints:                def.Constraints,
 		Env:                        def.Env,
+		EnvGroups:                  def.EnvGroups,
 		ResourceRequests:           def.ResourceRequests,
 		Resources:                  resources,
 		Kind:                       kind,
@@ -180,11 +259,23 @@ More information: https://apn.sh/jst-upgrade`)
 		RequireExplicitPermissions: task.RequireExplicitPermissions,
 		Permissions:                task.Permissions,
 		Timeout:                    def.Timeout,
+		Concurrency:                def.Concurrency,
+		Confirm:                    def.Confirm,
+		Dependencies:               def.Dependencies,
 		InterpolationMode:          interpolationMode,
+		ImageSignature:             signature,
+		Annotation:                 annotation,
 	})
 	if err != nil {
 		return errors.Wrapf(err, "updating task %s", def.Slug)
 	}
+	entry.TaskRevisionID = res.TaskRevisionID
+	entry.Changes = taskChanges(ctx, client, task)
+	logTaskChanges(task.Slug, entry.Changes)
+	if err := syncSchedules(ctx, client, task.ID, def.Schedules); err != nil {
+		return errors.Wrapf(err, "updating schedules of %s", def.Slug)
+	}
+	recordChecksum(task.ID, checksum, res.TaskRevisionID)
 
 	// Leave off `-- [parameters]` for simplicity - user will get prompted.
 	cmd := fmt.Sprintf("airplane exec %s", def.Slug)
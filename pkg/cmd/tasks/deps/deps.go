@@ -0,0 +1,19 @@
+package deps
+
+import (
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/deps/install"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new deps command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Manage a task's dependencies for local development",
+	}
+
+	cmd.AddCommand(install.New(c))
+
+	return cmd
+}
@@ -0,0 +1,96 @@
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/build"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/spf13/cobra"
+)
+
+// Config is the install config.
+type config struct {
+	root   *cli.Config
+	file   string
+	dryRun bool
+}
+
+// New returns a new install command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "install ./path/to/definition",
+		Short: "Install a task's dependencies locally",
+		Long: heredoc.Doc(`
+			Install a task's dependencies locally with the package manager the builder
+			would use, so that the task runs against the same dependencies locally as
+			it does once deployed.
+
+			The install command is the one in the Dockerfile the builder generates
+			for the task, as printed by airplane build print-dockerfile, such as
+			yarn install, npm ci, pip install or go mod download. It runs in the
+			directory of the task that the builder runs it in.
+		`),
+		Example: heredoc.Doc(`
+			airplane tasks deps install ./my_task.task.yaml
+			airplane tasks deps install ./airplane.yml --dry-run
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.file = args[0]
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().BoolVar(&cfg.dryRun, "dry-run", false, "Print the install command without running it.")
+	return cmd
+}
+
+// Run runs the install command.
+func run(ctx context.Context, cfg config) error {
+	dir, err := taskdir.Open(cfg.file, definitions.IsTaskDef(cfg.file))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	def, err := dir.ReadAnyDefinition()
+	if err != nil {
+		return err
+	}
+	kind, options, err := def.GetKindAndOptions()
+	if err != nil {
+		return err
+	}
+
+	step, err := build.DetectInstall(kind, options, dir.DefinitionRootPath())
+	if err != nil {
+		return err
+	}
+	if step == nil {
+		logger.Log("%s has no dependencies to install.", logger.Bold(def.GetSlug()))
+		return nil
+	}
+
+	logger.Log("Installing dependencies from %s with %s", relpath(step.Manifest), logger.Bold(step.String()))
+	if cfg.dryRun {
+		return nil
+	}
+	return step.Run(ctx)
+}
+
+// relpath returns path relative to the working directory, if it's inside it.
+func relpath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rp, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rp, "..") {
+			return "./" + rp
+		}
+	}
+	return path
+}
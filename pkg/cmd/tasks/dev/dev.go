@@ -2,7 +2,6 @@ package dev
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/airplanedev/cli/pkg/api"
@@ -18,6 +18,8 @@ import (
 	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/airplanedev/cli/pkg/params"
 	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/airplanedev/lib/pkg/outputs"
 	"github.com/airplanedev/lib/pkg/runtime"
@@ -31,9 +33,12 @@ import (
 )
 
 type config struct {
-	root *cli.Config
-	file string
-	args []string
+	root        *cli.Config
+	file        string
+	args        []string
+	gracePeriod time.Duration
+	// timeout overrides the task's timeout, if set.
+	timeout api.Duration
 }
 
 func New(c *cli.Config) *cobra.Command {
@@ -42,10 +47,17 @@ func New(c *cli.Config) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "dev ./path/to/file",
 		Short: "Locally run a task",
-		Long:  "Locally runs a task, optionally with specific parameters.",
+		Long: heredoc.Doc(`
+			Locally runs a task, optionally with specific parameters.
+
+			The task can be given by its script or by its definition. Tasks run from
+			a definition use its parameters and options, so changes to them can be
+			tried out before they are deployed.
+		`),
 		Example: heredoc.Doc(`
 			airplane dev ./task.js [-- <parameters...>]
 			airplane dev ./task.ts [-- <parameters...>]
+			airplane dev ./my_task.task.yaml [-- <parameters...>]
 		`),
 		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
 			// TODO: update the `dev` command to work w/out internet access
@@ -62,6 +74,9 @@ func New(c *cli.Config) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Var(&cfg.timeout, "timeout", "How long the task may run before it is stopped, such as 120s or 5m, in place of its own timeout.")
+	cmd.Flags().DurationVar(&cfg.gracePeriod, "grace-period", 10*time.Second, "How long to wait for the task to exit after it is interrupted or times out, before killing it.")
+
 	return cmd
 }
 
@@ -70,23 +85,33 @@ func run(ctx context.Context, cfg config) error {
 		return errors.Errorf("Unable to open file: %s", cfg.file)
 	}
 
-	slug, err := slugFromScript(cfg.file)
-	if err != nil {
-		return err
-	}
+	var task api.Task
+	entrypoint := cfg.file
+	if ext := filepath.Ext(cfg.file); ext == ".yml" || ext == ".yaml" || definitions.IsTaskDef(cfg.file) {
+		var err error
+		task, entrypoint, err = taskFromDefinition(ctx, cfg.root.Client, cfg.file)
+		if err != nil {
+			return err
+		}
+	} else {
+		slug, err := slugFromScript(cfg.file)
+		if err != nil {
+			return err
+		}
 
-	task, err := cfg.root.Client.GetTask(ctx, slug)
-	if err != nil {
-		return errors.Wrap(err, "getting task")
+		task, err = cfg.root.Client.GetTask(ctx, slug)
+		if err != nil {
+			return errors.Wrap(err, "getting task")
+		}
 	}
 
-	r, err := runtime.Lookup(cfg.file, task.Kind)
+	r, err := runtime.Lookup(entrypoint, task.Kind)
 	if err != nil {
-		return errors.Wrapf(err, "unsupported file type: %s", filepath.Base(cfg.file))
+		return errors.Wrapf(err, "unsupported file type: %s", filepath.Base(entrypoint))
 	}
 
 	paramValues, err := params.CLI(cfg.args, cfg.root.Client, task)
-	if errors.Is(err, flag.ErrHelp) {
+	if errors.Is(err, params.ErrHelp) {
 		return nil
 	} else if err != nil {
 		return err
@@ -95,9 +120,9 @@ func run(ctx context.Context, cfg config) error {
 	logger.Log("Locally running %s task %s", logger.Bold(task.Name), logger.Gray("("+cfg.root.Client.TaskURL(task.Slug)+")"))
 	logger.Log("")
 
-	path, err := filepath.Abs(cfg.file)
+	path, err := filepath.Abs(entrypoint)
 	if err != nil {
-		return errors.Wrapf(err, "absolute path of %s", cfg.file)
+		return errors.Wrapf(err, "absolute path of %s", entrypoint)
 	}
 
 	cmds, closer, err := r.PrepareRun(ctx, &logger.StdErrLogger{}, runtime.PrepareRunOptions{
@@ -112,7 +137,18 @@ func run(ctx context.Context, cfg config) error {
 		defer closer.Close()
 	}
 
-	cmd := exec.CommandContext(ctx, cmds[0], cmds[1:]...)
+	// Mirror the timeout that is enforced when the task runs on Airplane.
+	if cfg.timeout > 0 {
+		task.Timeout = cfg.timeout
+	}
+	runCtx := ctx
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, task.Timeout.Std())
+		defer cancel()
+	}
+
+	cmd := exec.Command(cmds[0], cmds[1:]...)
 	logger.Debug("Running %s", logger.Bold(strings.Join(cmd.Args, " ")))
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -139,6 +175,10 @@ func run(ctx context.Context, cfg config) error {
 		return errors.Wrap(err, "starting")
 	}
 
+	exited := make(chan struct{})
+	defer close(exited)
+	go terminateOnDone(runCtx, cmd.Process, cfg.gracePeriod, exited)
+
 	// mu guards o and chunks
 	var mu sync.Mutex
 	var o ojson.Value
@@ -183,6 +223,9 @@ func run(ctx context.Context, cfg config) error {
 	}
 
 	if err := cmd.Wait(); err != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return errors.Errorf("task timed out after %s", task.Timeout)
+		}
 		return errors.Wrap(err, "waiting")
 	}
 
@@ -191,6 +234,32 @@ func run(ctx context.Context, cfg config) error {
 	return nil
 }
 
+// terminateOnDone stops the process once ctx is done.
+//
+// The process is first interrupted so that it can clean up and flush any
+// pending outputs. If it has not exited within gracePeriod, it is killed.
+func terminateOnDone(ctx context.Context, p *os.Process, gracePeriod time.Duration, exited <-chan struct{}) {
+	select {
+	case <-exited:
+		return
+	case <-ctx.Done():
+	}
+
+	logger.Debug("Interrupting task, waiting up to %s for it to exit", gracePeriod)
+	if err := interrupt(p); err != nil {
+		logger.Debug("error interrupting task: %v", err)
+	}
+
+	select {
+	case <-exited:
+	case <-time.After(gracePeriod):
+		logger.Warning("Task did not exit within %s, killing it.", gracePeriod)
+		if err := p.Kill(); err != nil {
+			logger.Debug("error killing task: %v", err)
+		}
+	}
+}
+
 // getDevEnv will return a map of env vars, loading from .env and airplane.env
 // files inside the task root.
 //
@@ -234,6 +303,53 @@ func getDevEnv(r runtime.Interface, path string) (map[string]string, error) {
 	return env, errors.Wrap(err, "reading .env")
 }
 
+// taskFromDefinition reads the task defined at file, as it would be once
+// deployed, and returns it with the path to its entrypoint.
+func taskFromDefinition(ctx context.Context, client api.APIClient, file string) (api.Task, string, error) {
+	dir, err := taskdir.Open(file, definitions.IsTaskDef(file))
+	if err != nil {
+		return api.Task{}, "", err
+	}
+	defer dir.Close()
+
+	def, err := dir.ReadAnyDefinition()
+	if err != nil {
+		return api.Task{}, "", err
+	}
+	req, err := def.GetUpdateTaskRequest(ctx, client, nil)
+	if err != nil {
+		return api.Task{}, "", err
+	}
+
+	// Entrypoints are relative to the definition in the newer format, and to
+	// the task's root in the older one.
+	var entrypoint string
+	switch d := def.(type) {
+	case *definitions.Definition_0_3:
+		ep, err := d.Entrypoint()
+		if err != nil {
+			return api.Task{}, "", err
+		}
+		entrypoint = filepath.Join(filepath.Dir(file), ep)
+	default:
+		ep, _ := req.KindOptions["entrypoint"].(string)
+		entrypoint = filepath.Join(dir.DefinitionRootPath(), ep)
+	}
+	if !fsx.Exists(entrypoint) {
+		return api.Task{}, "", errors.Errorf("Unable to open entrypoint of %s: %s", file, entrypoint)
+	}
+
+	return api.Task{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Parameters:  req.Parameters,
+		Form:        req.Form,
+		Kind:        req.Kind,
+		KindOptions: req.KindOptions,
+		Timeout:     req.Timeout,
+	}, entrypoint, nil
+}
+
 // slugFromScript attempts to extract a slug from a file based on its contents.
 func slugFromScript(file string) (string, error) {
 	slug, ok := runtime.Slug(file)
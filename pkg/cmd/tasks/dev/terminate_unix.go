@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package dev
+
+import (
+	"os"
+	"syscall"
+)
+
+// interrupt asks the process to shut down, giving it a chance to clean up.
+func interrupt(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
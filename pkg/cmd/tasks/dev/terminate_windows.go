@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package dev
+
+import (
+	"os"
+)
+
+// interrupt asks the process to shut down.
+//
+// Windows does not support sending SIGTERM to a process, so the process is
+// killed immediately.
+func interrupt(p *os.Process) error {
+	return p.Kill()
+}
@@ -0,0 +1,133 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new diff command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <definition.yml>",
+		Short: "Compare a task definition with the deployed task",
+		Long: heredoc.Doc(`
+			Compare a task definition with the task it deploys to, and list the fields
+			that differ, such as added or removed parameters, a changed image or
+			changed environment variables.
+
+			Exits with status 2 if the deployed task has drifted from its definition,
+			and 1 if it couldn't be compared, so that CI can tell the two apart.
+
+			Only airplane.yml definitions can be compared. Task definition files,
+			such as my_task.task.yaml, describe fields that deployed tasks don't
+			keep, such as resources by name, and aren't supported yet.
+		`),
+		Example: heredoc.Doc(`
+			airplane tasks diff ./airplane.yml
+			airplane tasks diff ./airplane.yml -o json
+
+			# Fail a CI job on drift, but not on other errors
+			airplane tasks diff ./airplane.yml || [ $? -ne 2 ]
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cmd.Root().Context(), c.Client, args[0])
+		},
+	}
+	return cmd
+}
+
+func run(ctx context.Context, client api.APIClient, file string) error {
+	if definitions.IsTaskDef(file) {
+		return errors.Errorf("only airplane.yml definitions can be compared with deployed tasks, not task definition files such as %s", file)
+	}
+
+	dir, err := taskdir.Open(file, false)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	local, err := dir.ReadDefinition()
+	if err != nil {
+		return err
+	}
+	if local.Slug == "" {
+		return errors.Errorf("%s has no slug", file)
+	}
+	// Compare the definition as deploy would send it.
+	if local, err = local.Validate(); err != nil {
+		return err
+	}
+	if local.Description, _, err = definitions.ReadDescription(local.Description, filepath.Dir(dir.DefinitionPath())); err != nil {
+		return err
+	}
+
+	task, err := client.GetTask(ctx, local.Slug)
+	if err != nil {
+		return err
+	}
+	resp, err := client.ListResources(ctx)
+	if err != nil {
+		return errors.Wrap(err, "fetching resources")
+	}
+
+	changes, err := definitions.DiffTask(local, dir.DefinitionRootPath(), &task, resp.Resources)
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		changes = []definitions.Change{}
+	}
+
+	print.Print(changes, func() {
+		if len(changes) == 0 {
+			logger.Log("Task %s matches %s.", logger.Bold(task.Slug), file)
+			return
+		}
+		logger.Log("Task %s differs from %s:", logger.Bold(task.Slug), file)
+		for _, change := range changes {
+			fmt.Println(change.Colored())
+		}
+	})
+
+	if len(changes) > 0 {
+		return driftError{slug: task.Slug, file: file, changes: len(changes)}
+	}
+	return nil
+}
+
+// driftExitCode is the exit code of a diff that found drift.
+const driftExitCode = 2
+
+// driftError is returned when a deployed task has drifted from its
+// definition. Its changes have already been printed, so it only sets the
+// exit code.
+type driftError struct {
+	slug    string
+	file    string
+	changes int
+}
+
+var _ utils.ErrorExitCode = driftError{}
+
+func (e driftError) Error() string {
+	return fmt.Sprintf("task %s has drifted from %s: %d field(s) differ", e.slug, e.file, e.changes)
+}
+
+// ExitCode implements utils.ErrorExitCode.
+func (e driftError) ExitCode() int {
+	return driftExitCode
+}
@@ -0,0 +1,124 @@
+package diff
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/airplanedev/cli/pkg/utils/pointers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+
+	// write writes files, including the definition of the hello task as
+	// hello.yml, and returns the definition's path.
+	write := func(t *testing.T, def string, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		path := filepath.Join(dir, "hello.yml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(def), 0644))
+		return path
+	}
+	const hello = `
+slug: hello
+name: Hello
+image:
+  image: ubuntu:20.04
+  command: ["echo", "hello"]
+`
+	deployed := api.Task{
+		Slug:    "hello",
+		Name:    "Hello",
+		Kind:    "image",
+		Image:   pointers.String("ubuntu:20.04"),
+		Command: []string{"echo", "hello"},
+	}
+
+	for _, test := range []struct {
+		name  string
+		def   string
+		files map[string]string
+		task  func(task *api.Task, db api.Resource)
+		drift bool
+	}{
+		{
+			name: "matches",
+			def:  hello,
+		},
+		{
+			name:  "drifted",
+			def:   hello + "timeout: 300\n",
+			drift: true,
+		},
+		{
+			name: "resources by name",
+			def:  hello + "resources:\n  db: Main DB\n",
+			task: func(task *api.Task, db api.Resource) {
+				task.Resources = api.Resources{"db": db.ID}
+			},
+		},
+		{
+			name:  "changed resource",
+			def:   hello + "resources:\n  db: Main DB\n",
+			task:  func(task *api.Task, db api.Resource) { task.Resources = api.Resources{"db": "res_other"} },
+			drift: true,
+		},
+		{
+			name: "schedules",
+			def:  hello + "schedules:\n  daily:\n    cron: 0 9 * * *\n",
+		},
+		{
+			name: "x-cli",
+			def:  hello + "x-cli:\n  output: json\n",
+		},
+		{
+			name:  "description file",
+			def:   hello + "description: file:README.md\n",
+			files: map[string]string{"README.md": "Says hello."},
+			task:  func(task *api.Task, db api.Resource) { task.Description = "Says hello." },
+		},
+		{
+			name: "git vars",
+			def:  hello + "env:\n  GIT_BRANCH:\n    value: ${{ git.branch }}\n",
+			// Outside of a repository, git variables are empty.
+			task: func(task *api.Task, db api.Resource) {
+				empty := ""
+				task.Env = api.TaskEnv{"GIT_BRANCH": {Value: &empty}}
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			client := apitest.New()
+			db := client.AddResource(api.Resource{Name: "Main DB"})
+			task := deployed
+			if test.task != nil {
+				test.task(&task, db)
+			}
+			client.AddTask(task)
+
+			err := run(ctx, client, write(t, test.def, test.files))
+			if !test.drift {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.IsType(t, driftError{}, err)
+			require.Equal(t, driftExitCode, err.(driftError).ExitCode())
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		client := apitest.New()
+		client.AddTask(deployed)
+		err := run(ctx, client, write(t, "slug: hello\nname: Hello\n", nil))
+		require.Error(t, err)
+		require.NotEqual(t, driftError{}, err)
+	})
+}
@@ -2,21 +2,28 @@ package execute
 
 import (
 	"context"
-	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"time"
 
+	"github.com/AlecAivazis/survey/v2"
 	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/agents"
 	"github.com/airplanedev/cli/pkg/analytics"
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/pause"
 	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/outputhook"
 	"github.com/airplanedev/cli/pkg/params"
 	"github.com/airplanedev/cli/pkg/print"
 	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/taskprefs"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/airplanedev/lib/pkg/runtime"
 	"github.com/pkg/errors"
@@ -29,6 +36,34 @@ type config struct {
 	// task reference could be a script file, yaml definition or a slug.
 	task string
 	args []string
+	// confirm is the task's confirmation phrase, for running without a prompt.
+	confirm string
+	// watch configures how the run is followed: log sinks, output hooks,
+	// redaction and heartbeats.
+	watch WatchOptions
+	// labels are attached to the run.
+	labels utils.LabelsValue
+	// priority is the queue the run waits in for an agent: high, normal or
+	// low.
+	priority string
+	// describe prints the task's parameters instead of running it.
+	describe bool
+	// noWait queues the run and exits, without waiting for it to finish.
+	noWait bool
+	// paramFile is a JSON or YAML file of parameter values, which flags
+	// after -- override.
+	paramFile string
+	// paramsFromStdin reads parameter values from the outputs of another
+	// run piped to stdin, with paramMap mapping param=output.
+	paramsFromStdin bool
+	paramMap        []string
+	// changed reports whether a flag was passed, so that the defaults in a
+	// definition's x-cli block don't override it.
+	changed func(name string) bool
+	// output is the --output flag, remembered for the task when passed.
+	output string
+	// remember remembers the --log-sink flags passed for the task.
+	remember bool
 }
 
 // New returns a new execute cobra command.
@@ -39,11 +74,41 @@ func New(c *cli.Config) *cobra.Command {
 		Use:     "execute <slug>",
 		Short:   "Execute a task",
 		Aliases: []string{"exec"},
-		Long:    "Execute a task from the CLI, optionally with specific parameters.",
+		Long: heredoc.Doc(`
+			Execute a task from the CLI, optionally with specific parameters.
+
+			Parameters are passed as flags after --. To see the parameters a task
+			takes, with their types, defaults and constraints, use --describe.
+		`),
 		Example: heredoc.Doc(`
 			airplane execute ./task.js [-- <parameters...>]
 			airplane execute hello_world [-- <parameters...>]
 			airplane execute ./airplane.yml [-- <parameters...>]
+			airplane execute hello_world --label reason=incident-1234 [-- <parameters...>]
+
+			# Start ahead of queued scheduled and bulk runs, such as during an incident
+			airplane execute hello_world --priority high [-- <parameters...>]
+
+			# Read parameters from a file, overriding some with flags
+			airplane execute hello_world --param-file params.yaml [-- <parameters...>]
+
+			# Pass the outputs of one run to the parameters of another
+			airplane execute extract -o json | airplane execute load --params-from-stdin --map rows=records
+
+			# Queue a run and print its ID, without waiting for it to finish
+			airplane execute hello_world --no-wait [-- <parameters...>]
+
+			# List a task's parameters without running it
+			airplane execute hello_world --describe
+			airplane execute hello_world --describe -o json
+
+			# Defaults for running a task can be kept in its definition:
+			#   x-cli:
+			#     output: json
+			#     logSinks: ["webhook:https://hooks.example.com/ops"]
+			#     stallWarning: 30m
+			#     outputHooks: ["csv:report.csv"]
+			#     redact: ["keyword:password", "regex:sk_live_[0-9a-zA-Z]+"]
 		`),
 		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
 			return login.EnsureLoggedIn(cmd.Root().Context(), c)
@@ -59,6 +124,8 @@ func New(c *cli.Config) *cobra.Command {
 			} else {
 				return errors.New("expected 1 argument: airplane execute [./path/to/file | task slug]")
 			}
+			cfg.changed = cmd.Flags().Changed
+			cfg.output, _ = cmd.Flags().GetString("output")
 
 			return run(cmd.Root().Context(), cfg)
 		},
@@ -66,6 +133,17 @@ func New(c *cli.Config) *cobra.Command {
 
 	cmd.Flags().StringVarP(&cfg.task, "file", "f", "", "File to deploy (.yaml, .yml, .js, .ts)")
 	cli.Must(cmd.Flags().MarkHidden("file")) // --file is deprecated
+	AddWatchFlags(cmd, &cfg.watch)
+	cmd.Flags().Var(&cfg.labels, "label", "Attach a key=value label to the run, such as reason=incident-1234. May be repeated.")
+	cmd.Flags().StringVar(&cfg.priority, "priority", "", "Queue the run with the given priority: high to start ahead of other queued runs, or low to start after them. Defaults to normal.")
+	cmd.Flags().BoolVar(&cfg.noWait, "no-wait", false, "Queue the run, print its ID and URL, and exit without waiting for it to finish.")
+	cmd.Flags().BoolVar(&cfg.noWait, "detach", false, "Alias for --no-wait.")
+	cmd.Flags().BoolVar(&cfg.describe, "describe", false, "Print the task's parameters, grouped into required and optional, instead of running it.")
+	cmd.Flags().StringVar(&cfg.paramFile, "param-file", "", "JSON or YAML file of parameter values, keyed by parameter slug. Parameters passed as flags after -- take precedence.")
+	cmd.Flags().BoolVar(&cfg.paramsFromStdin, "params-from-stdin", false, "Read parameter values from the outputs of another run piped to stdin, as printed by airplane execute -o json. Outputs named after a parameter are passed to it.")
+	cmd.Flags().StringArrayVar(&cfg.paramMap, "map", nil, "With --params-from-stdin, pass an output to a parameter of another name, as param=output. May be repeated.")
+	cmd.Flags().BoolVar(&cfg.remember, "remember", false, "Remember the --log-sink flags passed, for the next runs of this task and for airplane runs logs. Clear them with airplane config task <slug> --clear.")
+	cmd.Flags().StringVar(&cfg.confirm, "confirm", "", "Confirmation phrase required by the task, to run it without being prompted")
 
 	return cmd
 }
@@ -74,122 +152,399 @@ func New(c *cli.Config) *cobra.Command {
 func run(ctx context.Context, cfg config) error {
 	var client = cfg.root.Client
 
+	priority, err := parsePriority(cfg.priority)
+	if err != nil {
+		return err
+	}
+	paramMap, err := parseParamMap(cfg.paramMap)
+	if err != nil {
+		return err
+	}
+	if len(paramMap) > 0 && !cfg.paramsFromStdin {
+		return errors.New("--map only applies with --params-from-stdin")
+	}
+	if cfg.paramsFromStdin && utils.StdinIsTerminal() {
+		return errors.New("--params-from-stdin expects the outputs of a run piped to stdin, such as:\n  airplane execute extract -o json | airplane execute load --params-from-stdin")
+	}
+
 	var slug string
-	var err error
+	var defaults *definitions.CLIDefaults
 	if f, err := os.Stat(cfg.task); errors.Is(err, os.ErrNotExist) || f.IsDir() {
 		// Not a file, assume it's a slug.
 		slug = cfg.task
 	} else {
 		// It's a file, look up the slug form the file.
-		slug, err = slugFrom(cfg.task)
+		slug, defaults, err = slugFrom(cfg.task)
 		if err != nil {
 			return err
 		}
 	}
+	// Flags take precedence over remembered flags, which take precedence
+	// over the definition's x-cli block.
+	if err := applyPrefs(&cfg, slug); err != nil {
+		return err
+	}
+	if defaults != nil {
+		if err := applyDefaults(&cfg, *defaults); err != nil {
+			return errors.Wrapf(err, "reading x-cli in %s", cfg.task)
+		}
+	}
 	task, err := client.GetTask(ctx, slug)
 	if err != nil {
 		return err
 	}
 
+	if cfg.describe {
+		print.Print(params.Describe(task), func() {
+			params.PrintUsage(task)
+		})
+		return nil
+	}
+
 	if task.Image == nil {
 		return &notDeployedError{
 			task: cfg.task,
 		}
 	}
 
+	if err := client.RequirePermissions(ctx, api.PermissionCheck{
+		Action:   api.PermissionTasksExecute,
+		TaskSlug: task.Slug,
+	}); err != nil {
+		return err
+	}
+
+	if err := agents.CheckConstraints(ctx, client, task.Constraints); err != nil {
+		return err
+	}
+
+	warnIfAtConcurrencyLimit(ctx, client, task)
+	if task.Pause.Active(time.Now()) {
+		logger.Warning("%s is paused%s. Only team admins can execute it until it's resumed.", task.Slug, pause.Describe(task.Pause))
+	}
+
 	req := api.RunTaskRequest{
 		TaskID:      task.ID,
 		ParamValues: make(api.Values),
+		Labels:      cfg.labels,
+		Priority:    priority,
 	}
 
-	logger.Log("Executing %s task: %s", logger.Bold(task.Name), logger.Gray(client.TaskURL(task.Slug)))
+	logger.Banner("Executing %s task: %s", logger.Bold(task.Name), logger.Gray(client.TaskURL(task.Slug)))
 
-	req.ParamValues, err = params.CLI(cfg.args, client, task)
-	if errors.Is(err, flag.ErrHelp) {
+	var fileValues api.Values
+	if cfg.paramFile != "" {
+		if fileValues, err = params.ReadFile(task, cfg.paramFile); err != nil {
+			return err
+		}
+	}
+	if cfg.paramsFromStdin {
+		// Piped outputs take precedence over the file, and flags over both.
+		outputValues, err := params.ReadOutputs(task, os.Stdin, paramMap)
+		if err != nil {
+			return errors.Wrap(err, "reading parameters from stdin")
+		}
+		fileValues = params.Merge(fileValues, outputValues)
+	}
+	req.ParamValues, err = params.CLIWithValues(cfg.args, client, task, fileValues)
+	if errors.Is(err, params.ErrHelp) {
 		return nil
 	} else if err != nil {
 		return err
 	}
+	if cfg.paramsFromStdin {
+		// Outputs are passed as they were printed, so check that they're
+		// valid for their parameters.
+		if err := params.Preflight(task, req.ParamValues); err != nil {
+			return err
+		}
+	}
+	if cfg.paramFile != "" || cfg.paramsFromStdin {
+		params.LogValues(task, req.ParamValues)
+	}
+
+	if err := ConfirmRun(task, cfg.confirm); err != nil {
+		return err
+	}
+
+	if cfg.noWait {
+		return runDetached(ctx, cfg, task, req)
+	}
 
-	w, err := client.Watcher(ctx, req)
+	state, err := Watch(ctx, client, req, cfg.watch)
 	if err != nil {
 		return err
 	}
 
-	logger.Log(logger.Gray("Queued run: %s", client.RunURL(w.RunID())))
+	analytics.Track(cfg.root, "Run Executed", map[string]interface{}{
+		"task_id":   task.ID,
+		"task_name": task.Name,
+		"status":    state.Status,
+	})
 
-	var state api.RunState
-	agentPrefix := "[agent]"
+	switch state.Status {
+	case api.RunFailed:
+		return errors.New("Run has failed")
+	}
+	return nil
+}
 
-	for {
-		if state = w.Next(); state.Err() != nil {
-			break
-		}
+// agentPrefix starts the logs written by the agent, rather than the task.
+const agentPrefix = "[agent]"
 
-		for _, l := range state.Logs {
-			var loggedText string
-			if strings.HasPrefix(l.Text, agentPrefix) {
-				// De-emphasize agent logs and remove prefix
-				loggedText = logger.Gray(strings.TrimLeft(strings.TrimPrefix(l.Text, agentPrefix), " "))
-			} else {
-				// Try to leave user logs alone, so they can apply their own colors
-				loggedText = fmt.Sprintf("[%s] %s", logger.Gray("log"), l.Text)
-			}
-			logger.Log(loggedText)
-		}
-
-		if state.Stopped() {
-			break
-		}
+// FormatLog formats a run's log line for the terminal.
+func FormatLog(l api.LogItem) string {
+	if strings.HasPrefix(l.Text, agentPrefix) {
+		// De-emphasize agent logs and remove prefix
+		return logger.Gray(strings.TrimLeft(strings.TrimPrefix(l.Text, agentPrefix), " "))
 	}
+	// Try to leave user logs alone, so they can apply their own colors
+	return fmt.Sprintf("[%s] %s", logger.Gray("log"), l.Text)
+}
+
+// detachedRun is printed for a run that was queued with --no-wait.
+type detachedRun struct {
+	RunID string `json:"runID" yaml:"runID"`
+	URL   string `json:"url" yaml:"url"`
+}
 
-	if err := state.Err(); err != nil {
+// runDetached queues a run and prints its ID, on stdout so that scripts can
+// capture it, and its URL.
+func runDetached(ctx context.Context, cfg config, task api.Task, req api.RunTaskRequest) error {
+	var client = cfg.root.Client
+
+	resp, err := client.RunTask(ctx, req)
+	if err != nil {
 		return err
 	}
 
-	print.Outputs(state.Outputs)
+	run := detachedRun{RunID: resp.RunID, URL: client.RunURL(resp.RunID)}
+	print.Print(run, func() {
+		fmt.Println(run.RunID)
+		logger.Banner(logger.Gray("Queued run: %s", run.URL))
+	})
 
 	analytics.Track(cfg.root, "Run Executed", map[string]interface{}{
 		"task_id":   task.ID,
 		"task_name": task.Name,
-		"status":    state.Status,
+		"detached":  true,
 	})
+	return nil
+}
 
-	switch state.Status {
-	case api.RunFailed:
-		return errors.New("Run has failed")
+// cancelRun cancels an interrupted run. The command's context is already
+// canceled by then, so it uses its own.
+func cancelRun(client api.APIClient, runID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := client.CancelRun(ctx, runID); err != nil {
+		logger.Warning("Unable to cancel run %s: %v", client.RunURL(runID), err)
+		return
+	}
+	logger.Log("Cancelled run: %s", client.RunURL(runID))
+}
+
+// ConfirmRun requires the task's confirmation phrase, if it has one, to be
+// typed in or passed with --confirm before the task is run.
+func ConfirmRun(task api.Task, phrase string) error {
+	if task.Confirm == "" {
+		return nil
+	}
+
+	if phrase == "" {
+		if !utils.CanPrompt() {
+			return errors.Errorf("task %s requires confirmation: pass --confirm=%q to run it", task.Slug, task.Confirm)
+		}
+		logger.Warning("%s is marked as dangerous.", task.Name)
+		if err := utils.AskOne(
+			&survey.Input{Message: fmt.Sprintf("Type %q to confirm:", task.Confirm)},
+			&phrase,
+		); err != nil {
+			return errors.Wrap(err, "confirming run")
+		}
+	}
+
+	if strings.TrimSpace(phrase) != task.Confirm {
+		return errors.New("confirmation phrase does not match, not running task")
+	}
+	return nil
+}
+
+// applyDefaults applies the defaults from a definition's x-cli block to cfg,
+// for every flag that wasn't passed.
+func applyDefaults(cfg *config, d definitions.CLIDefaults) error {
+	changed := cfg.changed
+	if changed == nil {
+		changed = func(string) bool { return false }
+	}
+
+	if d.Output != "" && !changed("output") {
+		f, err := print.ParseFormatter(d.Output)
+		if err != nil {
+			return err
+		}
+		print.DefaultFormatter = f
+	}
+	cfg.watch.LogSinks = append(d.LogSinks, cfg.watch.LogSinks...)
+	var hooks []string
+	for _, spec := range d.OutputHooks {
+		ok, err := allowDefinitionHook(cfg.task, spec)
+		if err != nil {
+			return err
+		}
+		if ok {
+			hooks = append(hooks, spec)
+		}
+	}
+	cfg.watch.OutputHooks = append(hooks, cfg.watch.OutputHooks...)
+	cfg.watch.Redact = append(d.Redact, cfg.watch.Redact...)
+	if v, ok, err := d.HeartbeatDuration(); err != nil {
+		return err
+	} else if ok && !changed("heartbeat") {
+		cfg.watch.Heartbeat = v
+	}
+	if v, ok, err := d.StallWarningDuration(); err != nil {
+		return err
+	} else if ok && !changed("stall-warning") {
+		cfg.watch.StallWarning = v
+	}
+	return nil
+}
+
+// allowDefinitionHook reports whether the output hook spec, from the x-cli
+// block of the definition at path, may be used. Its csv files must be within
+// the working directory, and hooks that run commands are only used once the
+// user confirms them, since anyone can check a definition into a repo: to
+// always use one, pass it with --output-hook or add it to the user's config
+// instead.
+func allowDefinitionHook(path, spec string) (bool, error) {
+	if err := outputhook.CheckUntrusted(spec); err != nil {
+		return false, errors.Wrapf(err, "%s", path)
+	}
+	if !outputhook.RunsCommands(spec) {
+		return true, nil
+	}
+	if !utils.CanPrompt() {
+		logger.Warning("Skipping the output hook %s from %s: hooks that run commands must be confirmed, or passed with --output-hook.", spec, path)
+		return false, nil
+	}
+	var ok bool
+	if err := utils.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("%s pipes the run's outputs through a local command: %s. Run it?", path, spec),
+		Default: false,
+	}, &ok); err != nil {
+		return false, errors.Wrap(err, "confirming output hook")
+	}
+	if !ok {
+		logger.Log("Skipping the output hook %s.", spec)
+	}
+	return ok, nil
+}
+
+// applyPrefs applies the flags remembered for the task with slug, and
+// remembers the ones that were passed for next time: --output always, and
+// --log-sink only with --remember, since sinks copy the task's logs
+// elsewhere.
+func applyPrefs(cfg *config, slug string) error {
+	changed := cfg.changed
+	if changed == nil {
+		changed = func(string) bool { return false }
+	}
+
+	prefs, err := taskprefs.Get(slug)
+	if err != nil {
+		logger.Debug("Reading remembered flags: %v", err)
+		return nil
+	}
+	remembered := prefs
+
+	var applied []string
+	if changed("output") {
+		prefs.Output = cfg.output
+	} else if prefs.Output != "" {
+		f, err := print.ParseFormatter(prefs.Output)
+		if err != nil {
+			return err
+		}
+		print.DefaultFormatter = f
+		applied = append(applied, "--output "+prefs.Output)
+	}
+	switch {
+	case changed("log-sink"):
+		// Sinks passed without --remember apply to this run only.
+		if cfg.remember {
+			prefs.LogSinks = cfg.watch.LogSinks
+		}
+	case cfg.remember:
+		return errors.New("--remember only applies with --log-sink")
+	case len(prefs.LogSinks) > 0:
+		cfg.watch.LogSinks = prefs.LogSinks
+		for _, s := range prefs.LogSinks {
+			applied = append(applied, "--log-sink "+s)
+		}
+	}
+
+	// Remembered flags count as passed, so that x-cli doesn't override them.
+	cfg.changed = func(name string) bool {
+		return changed(name) || (name == "output" && prefs.Output != "")
+	}
+
+	if len(applied) > 0 {
+		logger.Banner(logger.Gray("Using the flags last passed for %s: %s (forget them with airplane config task %s --clear)", slug, strings.Join(applied, " "), slug))
+	}
+	if !reflect.DeepEqual(prefs, remembered) {
+		if err := taskprefs.Set(slug, prefs); err != nil {
+			logger.Debug("Remembering flags: %v", err)
+		} else if saved := prefsFlags(prefs); len(saved) > 0 {
+			logger.Banner(logger.Gray("Remembered for the next runs of %s: %s", slug, strings.Join(saved, " ")))
+		}
 	}
 	return nil
 }
 
-// SlugFrom returns the slug from the given file.
-func slugFrom(file string) (string, error) {
-	switch ext := filepath.Ext(file); ext {
-	case ".yml", ".yaml":
-		return slugFromYaml(file)
+// prefsFlags returns the flags p stands for.
+func prefsFlags(p taskprefs.Prefs) []string {
+	var flags []string
+	if p.Output != "" {
+		flags = append(flags, "--output "+p.Output)
+	}
+	for _, s := range p.LogSinks {
+		flags = append(flags, "--log-sink "+s)
+	}
+	return flags
+}
+
+// SlugFrom returns the slug from the given file, and the defaults for
+// running it, if the file is a definition that has any.
+func slugFrom(file string) (string, *definitions.CLIDefaults, error) {
+	switch ext := filepath.Ext(file); {
+	case definitions.IsTaskDef(file), ext == ".yml", ext == ".yaml":
+		return slugFromDefinition(file)
 	default:
-		return slugFromScript(file)
+		slug, err := slugFromScript(file)
+		return slug, nil, err
 	}
 }
 
-// slugFromYaml attempts to extract a slug from a yaml definition.
-func slugFromYaml(file string) (string, error) {
-	dir, err := taskdir.Open(file, false)
+// slugFromDefinition attempts to extract a slug and x-cli defaults from a
+// task definition, in any of its formats.
+func slugFromDefinition(file string) (string, *definitions.CLIDefaults, error) {
+	dir, err := taskdir.Open(file, definitions.IsTaskDef(file))
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	defer dir.Close()
 
-	def, err := dir.ReadDefinition()
+	def, err := dir.ReadAnyDefinition()
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	if def.Slug == "" {
-		return "", errors.Errorf("no task slug found in task definition at %s", file)
+	if def.GetSlug() == "" {
+		return "", nil, errors.Errorf("no task slug found in task definition at %s", file)
 	}
 
-	return def.Slug, nil
+	return def.GetSlug(), def.GetCLIDefaults(), nil
 }
 
 // slugFromScript attempts to extract a slug from a script.
@@ -202,6 +557,42 @@ func slugFromScript(file string) (string, error) {
 	return slug, nil
 }
 
+// warnIfAtConcurrencyLimit warns when the task already has as many active
+// runs as its concurrency limit allows, since the new run won't start right away.
+func warnIfAtConcurrencyLimit(ctx context.Context, client api.APIClient, task api.Task) {
+	if task.Concurrency == nil || task.Concurrency.Limit <= 0 {
+		return
+	}
+
+	res, err := client.ListRuns(ctx, api.ListRunsRequest{
+		TaskID: task.ID,
+		Limit:  100,
+	})
+	if err != nil {
+		logger.Debug("Unable to check active runs: %+v", err)
+		return
+	}
+
+	var active int
+	for _, r := range res.Runs {
+		if !r.Status.Stopped() {
+			active++
+		}
+	}
+	if active < task.Concurrency.Limit {
+		return
+	}
+
+	switch task.Concurrency.Behavior {
+	case api.ConcurrencySkip:
+		logger.Warning("Task %s already has %d active run(s) (limit %d): this run will be skipped.", task.Slug, active, task.Concurrency.Limit)
+	case api.ConcurrencyCancelPrevious:
+		logger.Warning("Task %s already has %d active run(s) (limit %d): the oldest active run will be cancelled.", task.Slug, active, task.Concurrency.Limit)
+	default:
+		logger.Warning("Task %s already has %d active run(s) (limit %d): this run will be queued until one finishes.", task.Slug, active, task.Concurrency.Limit)
+	}
+}
+
 type notDeployedError struct {
 	task string
 }
@@ -215,3 +606,39 @@ func (err notDeployedError) Error() string {
 func (err notDeployedError) ExplainError() string {
 	return fmt.Sprintf("to deploy the task:\n  airplane deploy %s", err.task)
 }
+
+// priorities lists the priorities runs can be queued with.
+var priorities = []api.RunPriority{
+	api.RunPriorityHigh,
+	api.RunPriorityNormal,
+	api.RunPriorityLow,
+}
+
+// parsePriority parses a --priority, ignoring case. Normal priority is sent
+// as no priority, so that APIs without priorities still accept the run.
+func parsePriority(s string) (api.RunPriority, error) {
+	if s == "" || strings.EqualFold(s, string(api.RunPriorityNormal)) {
+		return "", nil
+	}
+	var names []string
+	for _, p := range priorities {
+		if strings.EqualFold(string(p), s) {
+			return p, nil
+		}
+		names = append(names, string(p))
+	}
+	return "", errors.Errorf("unknown --priority %q: expected one of %s", s, strings.Join(names, ", "))
+}
+
+// parseParamMap parses --map flags, each mapping param=output.
+func parseParamMap(flags []string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, f := range flags {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, errors.Errorf("invalid --map %q: expected param=output", f)
+		}
+		m[parts[0]] = parts[1]
+	}
+	return m, nil
+}
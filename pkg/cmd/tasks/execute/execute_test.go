@@ -0,0 +1,153 @@
+package execute
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/taskprefs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugFrom(t *testing.T) {
+	defaults := &definitions.CLIDefaults{Output: "json", LogSinks: []string{"file:run.log"}}
+
+	for _, test := range []struct {
+		name, def string
+	}{
+		{
+			name: "hello.yml",
+			def: `
+slug: hello
+name: Hello
+python:
+  entrypoint: main.py
+x-cli:
+  output: json
+  logSinks: [file:run.log]
+`,
+		},
+		{
+			name: "hello.task.yaml",
+			def: `
+slug: hello
+name: Hello
+python:
+  entrypoint: main.py
+x-cli:
+  output: json
+  logSinks: [file:run.log]
+`,
+		},
+		{
+			name: "hello.task.json",
+			def: `{
+	"slug": "hello",
+	"name": "Hello",
+	"python": {"entrypoint": "main.py"},
+	"x-cli": {"output": "json", "logSinks": ["file:run.log"]}
+}`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.py"), []byte("def main(params):\n    pass\n"), 0644))
+			path := filepath.Join(dir, test.name)
+			require.NoError(t, ioutil.WriteFile(path, []byte(test.def), 0644))
+
+			slug, got, err := slugFrom(path)
+			require.NoError(t, err)
+			require.Equal(t, "hello", slug)
+			require.Equal(t, defaults, got)
+		})
+	}
+}
+
+func TestAllowDefinitionHook(t *testing.T) {
+	ok, err := allowDefinitionHook("hello.yml", "csv:rows.csv")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = allowDefinitionHook("hello.yml", "csv:/tmp/rows.csv")
+	require.EqualError(t, err, `hello.yml: csv output hook path "/tmp/rows.csv" must be within the working directory`)
+}
+
+func TestApplyPrefs(t *testing.T) {
+	// setup remembers prefs for the hello task, and returns a config with
+	// the given flags passed.
+	setup := func(t *testing.T, prefs taskprefs.Prefs, flags ...string) *config {
+		t.Setenv("HOME", t.TempDir())
+		formatter := print.DefaultFormatter
+		t.Cleanup(func() { print.DefaultFormatter = formatter })
+		print.DefaultFormatter = print.Table{}
+		require.NoError(t, taskprefs.Set("hello", prefs))
+
+		passed := map[string]bool{}
+		for _, f := range flags {
+			passed[f] = true
+		}
+		return &config{changed: func(name string) bool { return passed[name] }}
+	}
+	remembered := func(t *testing.T) taskprefs.Prefs {
+		p, err := taskprefs.Get("hello")
+		require.NoError(t, err)
+		return p
+	}
+
+	t.Run("flag over remembered over x-cli", func(t *testing.T) {
+		cfg := setup(t, taskprefs.Prefs{Output: "yaml"}, "output")
+		cfg.output = "json"
+		print.DefaultFormatter = print.NewJSONFormatter()
+		require.NoError(t, applyPrefs(cfg, "hello"))
+		require.NoError(t, applyDefaults(cfg, definitions.CLIDefaults{Output: "table"}))
+		require.Equal(t, print.NewJSONFormatter(), print.DefaultFormatter)
+		// The flag is remembered for next time.
+		require.Equal(t, "json", remembered(t).Output)
+	})
+
+	t.Run("remembered over x-cli", func(t *testing.T) {
+		cfg := setup(t, taskprefs.Prefs{Output: "yaml"})
+		require.NoError(t, applyPrefs(cfg, "hello"))
+		require.NoError(t, applyDefaults(cfg, definitions.CLIDefaults{Output: "json"}))
+		require.Equal(t, print.YAML{}, print.DefaultFormatter)
+	})
+
+	t.Run("x-cli", func(t *testing.T) {
+		cfg := setup(t, taskprefs.Prefs{})
+		require.NoError(t, applyPrefs(cfg, "hello"))
+		require.NoError(t, applyDefaults(cfg, definitions.CLIDefaults{Output: "json"}))
+		require.Equal(t, print.NewJSONFormatter(), print.DefaultFormatter)
+	})
+
+	t.Run("log sinks without --remember", func(t *testing.T) {
+		cfg := setup(t, taskprefs.Prefs{LogSinks: []string{"file:old.log"}}, "log-sink")
+		cfg.watch.LogSinks = []string{"file:run.log"}
+		require.NoError(t, applyPrefs(cfg, "hello"))
+		require.Equal(t, []string{"file:run.log"}, cfg.watch.LogSinks)
+		// Sinks passed without --remember aren't remembered.
+		require.Equal(t, []string{"file:old.log"}, remembered(t).LogSinks)
+	})
+
+	t.Run("log sinks with --remember", func(t *testing.T) {
+		cfg := setup(t, taskprefs.Prefs{LogSinks: []string{"file:old.log"}}, "log-sink", "remember")
+		cfg.watch.LogSinks = []string{"file:run.log"}
+		cfg.remember = true
+		require.NoError(t, applyPrefs(cfg, "hello"))
+		require.Equal(t, []string{"file:run.log"}, remembered(t).LogSinks)
+	})
+
+	t.Run("remembered log sinks", func(t *testing.T) {
+		cfg := setup(t, taskprefs.Prefs{LogSinks: []string{"file:old.log"}})
+		require.NoError(t, applyPrefs(cfg, "hello"))
+		require.NoError(t, applyDefaults(cfg, definitions.CLIDefaults{LogSinks: []string{"file:defn.log"}}))
+		require.Equal(t, []string{"file:defn.log", "file:old.log"}, cfg.watch.LogSinks)
+	})
+
+	t.Run("--remember without --log-sink", func(t *testing.T) {
+		cfg := setup(t, taskprefs.Prefs{}, "remember")
+		cfg.remember = true
+		require.EqualError(t, applyPrefs(cfg, "hello"), "--remember only applies with --log-sink")
+	})
+}
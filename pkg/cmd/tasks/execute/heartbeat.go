@@ -0,0 +1,76 @@
+package execute
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/utils"
+)
+
+// heartbeat reports on active runs that have gone quiet, so that a hung task
+// doesn't look the same as a CLI that has stopped updating.
+type heartbeat struct {
+	// interval is how long a run may go without logs before a heartbeat is
+	// printed, and how often heartbeats repeat. Zero disables heartbeats.
+	interval time.Duration
+	// stallAfter is how long a run may go without logs before it is reported
+	// as possibly stalled. Zero disables the warning.
+	stallAfter time.Duration
+
+	started  time.Time
+	lastLog  time.Time
+	lastBeat time.Time
+	warned   bool
+}
+
+// observe records state as of now. It returns a heartbeat message and a
+// stall warning to print, either of which may be empty.
+func (h *heartbeat) observe(state api.RunState, now time.Time) (beat, stall string) {
+	if len(state.Logs) > 0 {
+		h.lastLog = now
+		h.warned = false
+	}
+	if state.Status != api.RunActive {
+		return "", ""
+	}
+	if h.started.IsZero() {
+		h.started = now
+	}
+
+	quietSince := h.started
+	if !h.lastLog.IsZero() {
+		quietSince = h.lastLog
+	}
+	quiet := now.Sub(quietSince)
+
+	if h.interval > 0 && quiet >= h.interval {
+		since := quietSince
+		if h.lastBeat.After(since) {
+			since = h.lastBeat
+		}
+		if now.Sub(since) >= h.interval {
+			h.lastBeat = now
+			if h.lastLog.IsZero() {
+				beat = fmt.Sprintf("Still running (%s), no logs yet", formatElapsed(now.Sub(h.started)))
+			} else {
+				beat = fmt.Sprintf("Still running (%s), last log %s ago", formatElapsed(now.Sub(h.started)), formatElapsed(quiet))
+			}
+		}
+	}
+
+	if h.stallAfter > 0 && quiet >= h.stallAfter && !h.warned {
+		h.warned = true
+		stall = fmt.Sprintf("No logs for %s: the run may be stalled", formatElapsed(quiet))
+	}
+	return beat, stall
+}
+
+// formatElapsed formats d at the precision a person would say it: seconds
+// under a minute, minutes under an hour, and hours and minutes beyond.
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return utils.FormatDuration(d.Truncate(time.Second))
+	}
+	return utils.FormatDuration(d.Truncate(time.Minute))
+}
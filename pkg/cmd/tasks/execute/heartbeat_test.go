@@ -0,0 +1,61 @@
+package execute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeat(t *testing.T) {
+	require := require.New(t)
+	start := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+	active := api.RunState{Status: api.RunActive}
+	logged := api.RunState{Status: api.RunActive, Logs: []api.LogItem{{Text: "hi"}}}
+
+	h := heartbeat{interval: time.Minute, stallAfter: 5 * time.Minute}
+
+	beat, stall := h.observe(api.RunState{Status: api.RunQueued}, at(0))
+	require.Empty(beat)
+	require.Empty(stall)
+
+	beat, _ = h.observe(active, at(0))
+	require.Empty(beat)
+	beat, _ = h.observe(active, at(59*time.Second))
+	require.Empty(beat)
+	beat, _ = h.observe(active, at(time.Minute))
+	require.Equal("Still running (1m), no logs yet", beat)
+	beat, _ = h.observe(active, at(90*time.Second))
+	require.Empty(beat)
+
+	beat, _ = h.observe(logged, at(2*time.Minute))
+	require.Empty(beat)
+	beat, _ = h.observe(active, at(150*time.Second))
+	require.Empty(beat)
+	beat, _ = h.observe(active, at(3*time.Minute))
+	require.Equal("Still running (3m), last log 1m ago", beat)
+
+	beat, stall = h.observe(active, at(7*time.Minute))
+	require.Equal("Still running (7m), last log 5m ago", beat)
+	require.Equal("No logs for 5m: the run may be stalled", stall)
+	_, stall = h.observe(active, at(20*time.Minute))
+	require.Empty(stall)
+
+	// New logs re-arm the stall warning.
+	h.observe(logged, at(21*time.Minute))
+	_, stall = h.observe(active, at(26*time.Minute))
+	require.Equal("No logs for 5m: the run may be stalled", stall)
+
+	beat, stall = h.observe(api.RunState{Status: api.RunSucceeded}, at(2*time.Hour))
+	require.Empty(beat)
+	require.Empty(stall)
+}
+
+func TestFormatElapsed(t *testing.T) {
+	require := require.New(t)
+	require.Equal("45s", formatElapsed(45*time.Second))
+	require.Equal("12m", formatElapsed(12*time.Minute+30*time.Second))
+	require.Equal("1h5m", formatElapsed(65*time.Minute))
+}
@@ -0,0 +1,121 @@
+package execute
+
+import (
+	"context"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/logsink"
+	"github.com/airplanedev/cli/pkg/outputhook"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/redact"
+	"github.com/spf13/cobra"
+)
+
+// WatchOptions configure how Watch follows a run.
+type WatchOptions struct {
+	// LogSinks are extra destinations the run's logs are copied to.
+	LogSinks []string
+	// OutputHooks reshape the run's outputs before they are printed.
+	OutputHooks []string
+	// Redact are rules masking secrets in the run's logs.
+	Redact []string
+	// Heartbeat and StallWarning configure reporting on quiet runs.
+	Heartbeat    time.Duration
+	StallWarning time.Duration
+	// CancelOnInterrupt cancels the run if the CLI is interrupted while
+	// watching it.
+	CancelOnInterrupt bool
+}
+
+// AddWatchFlags adds the flags that set opts to cmd.
+func AddWatchFlags(cmd *cobra.Command, opts *WatchOptions) {
+	cmd.Flags().StringArrayVar(&opts.LogSinks, "log-sink", nil, "Also write the run's logs to a sink: "+logsink.Usage+". May be repeated.")
+	cmd.Flags().StringArrayVar(&opts.OutputHooks, "output-hook", nil, "Reshape the run's outputs before they are printed: "+outputhook.Usage+". May be repeated.")
+	cmd.Flags().StringArrayVar(&opts.Redact, "redact", nil, "Mask matches in the run's logs before they are printed or sent to log sinks: "+redact.Usage+". May be repeated.")
+	cmd.Flags().DurationVar(&opts.Heartbeat, "heartbeat", time.Minute, "How long an active run may go without logs before a heartbeat is printed, and how often it repeats. Zero disables heartbeats.")
+	cmd.Flags().DurationVar(&opts.StallWarning, "stall-warning", 0, "Warn once an active run has gone this long without logs, such as 30m. Disabled by default.")
+	cmd.Flags().BoolVar(&opts.CancelOnInterrupt, "cancel-on-interrupt", false, "Cancel the run if the CLI is interrupted, such as with Ctrl-C, instead of leaving it running.")
+}
+
+// Watch queues a run of req and follows it until it stops: its logs are
+// redacted, copied to log sinks and printed, heartbeats are printed while it
+// is quiet, and its outputs are printed through the output hooks. The hooks
+// and redaction rules in the user's config apply before the ones in opts.
+//
+// It returns the run's final state.
+func Watch(ctx context.Context, client api.APIClient, req api.RunTaskRequest, opts WatchOptions) (api.RunState, error) {
+	sinks, err := logsink.OpenAll(opts.LogSinks)
+	if err != nil {
+		return api.RunState{}, err
+	}
+	defer func() {
+		if err := sinks.Close(); err != nil {
+			logger.Warning("%v", err)
+		}
+	}()
+
+	// Hooks and rules configured for every run apply before the task's own.
+	hookSpecs, redactRules := opts.OutputHooks, opts.Redact
+	if c, err := conf.ReadDefault(); err == nil {
+		hookSpecs = append(c.OutputHooks, hookSpecs...)
+		redactRules = append(c.Redact, redactRules...)
+	}
+	hooks, err := outputhook.OpenAll(hookSpecs)
+	if err != nil {
+		return api.RunState{}, err
+	}
+	redactor, err := redact.Compile(redactRules)
+	if err != nil {
+		return api.RunState{}, err
+	}
+
+	w, err := client.Watcher(ctx, req, api.WithLogFilter(redactor.Redact))
+	if err != nil {
+		return api.RunState{}, err
+	}
+
+	logger.Banner(logger.Gray("Queued run: %s", client.RunURL(w.RunID())))
+
+	var state api.RunState
+	hb := heartbeat{interval: opts.Heartbeat, stallAfter: opts.StallWarning}
+
+	for {
+		if state = w.Next(); state.Err() != nil {
+			break
+		}
+
+		sinks.WriteLogs(state.Logs)
+		for _, l := range state.Logs {
+			logger.Log(FormatLog(l))
+		}
+
+		beat, stall := hb.observe(state, time.Now())
+		if beat != "" {
+			logger.Banner(logger.Gray("%s", beat))
+		}
+		if stall != "" {
+			logger.Warning("%s: %s", stall, client.RunURL(w.RunID()))
+		}
+
+		if state.Stopped() {
+			break
+		}
+	}
+
+	if err := state.Err(); err != nil {
+		if opts.CancelOnInterrupt && ctx.Err() != nil {
+			cancelRun(client, w.RunID())
+		}
+		return state, err
+	}
+
+	outputs, err := hooks.Transform(ctx, state.Outputs)
+	if err != nil {
+		logger.Warning("%v", err)
+	}
+	print.Outputs(outputs)
+	return state, nil
+}
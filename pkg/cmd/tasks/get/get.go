@@ -6,6 +6,7 @@ import (
 	"github.com/MakeNowJust/heredoc"
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
 	"github.com/spf13/cobra"
 )
 
@@ -36,6 +37,7 @@ func run(ctx context.Context, c *cli.Config, slug string) error {
 		return err
 	}
 
+	definitions.WarnDeprecations(task.Slug, definitions.TaskDeprecations(task))
 	print.Task(task)
 	return nil
 }
@@ -0,0 +1,90 @@
+package history
+
+import (
+	"context"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/print"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root  *cli.Config
+	slug  string
+	limit int
+}
+
+// New returns a new history command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "history <slug>",
+		Short: "List a task's deployed revisions",
+		Long: heredoc.Doc(`
+			List a task's revisions, newest first, with the CLI version, OS, git
+			commit and definition hash each was deployed from.
+		`),
+		Example: heredoc.Doc(`
+			airplane tasks history my_task
+			airplane tasks history my_task --limit 5 -o json
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.slug = args[0]
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().IntVar(&cfg.limit, "limit", 20, "Maximum number of revisions to list.")
+	return cmd
+}
+
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
+
+	var res api.ListTaskRevisionsResponse
+	if err := api.WithTaskID(ctx, client, cfg.slug, func(taskID string) (bool, error) {
+		var err error
+		res, err = client.ListTaskRevisions(ctx, taskID, cfg.limit)
+		return len(res.Revisions) == 0, err
+	}); err != nil {
+		return errors.Wrap(err, "list revisions")
+	}
+
+	print.Print(res.Revisions, func() {
+		if len(res.Revisions) == 0 {
+			logger.Log("No revisions found.")
+			return
+		}
+		tw := tablewriter.NewWriter(os.Stdout)
+		tw.SetBorder(false)
+		tw.SetHeader([]string{"revision", "deployed at", "deployed by", "cli", "os", "commit", "definition"})
+		for _, r := range res.Revisions {
+			var cliVersion, goos, commit, defHash string
+			if a := r.Annotation; a != nil {
+				cliVersion, goos, defHash = a.CLIVersion, a.OS, short(a.DefinitionHash)
+				commit = short(a.GitSHA)
+				if a.GitDirty {
+					commit += " (dirty)"
+				}
+			}
+			tw.Append([]string{r.ID, utils.FormatTime(r.CreatedAt), r.CreatedBy, cliVersion, goos, commit, defHash})
+		}
+		tw.Render()
+	})
+	return nil
+}
+
+// short abbreviates a hash the way git does.
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -1,4 +1,5 @@
-// initcmd defines the implementation of the `airplane tasks init` command.
+// initcmd defines the implementation of the `airplane tasks init` command,
+// as well as `airplane tasks scaffold-from`, which shares its file generation.
 //
 // Even though the command is called "init", we can't name the package "init"
 // since that conflicts with the Go init function.
@@ -33,7 +34,7 @@ import (
 )
 
 type config struct {
-	client *api.Client
+	client api.APIClient
 	file   string
 	slug   string
 
@@ -43,13 +44,19 @@ type config struct {
 	assumeYes bool
 	assumeNo  bool
 
+	// kind and params are the raw --kind and --param flags.
+	kind   string
+	params []string
+
 	newTaskInfo newTaskInfo
 }
 
 type newTaskInfo struct {
 	name       string
+	slug       string
 	kind       build.TaskKind
 	entrypoint string
+	params     []definitions.ParameterDefinition_0_3
 }
 
 func New(c *cli.Config) *cobra.Command {
@@ -62,6 +69,7 @@ func New(c *cli.Config) *cobra.Command {
 			$ airplane tasks init --slug task-slug
 			$ airplane tasks init --slug task-slug ./my/task.js
 			$ airplane tasks init --slug task-slug ./my/task.ts
+			$ airplane tasks init --name "Hello world" --kind node --entrypoint ./hello.js --param "name:shorttext:Who to greet" -y
 		`),
 		Args: cobra.MaximumNArgs(1),
 		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
@@ -75,7 +83,13 @@ func New(c *cli.Config) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&cfg.slug, "slug", "", "Slug of an existing task to generate from.")
+	cmd.Flags().StringVar(&cfg.slug, "slug", "", "Slug of an existing task to generate from. With --name or --kind, the slug of the new task instead.")
+
+	// New task flags, so that init can run without prompting.
+	cmd.Flags().StringVar(&cfg.newTaskInfo.name, "name", "", "Name of a new task to create a definition for.")
+	cmd.Flags().StringVar(&cfg.kind, "kind", "", "Kind of the new task, e.g. node, python, shell, image, sql or rest.")
+	cmd.Flags().StringVar(&cfg.newTaskInfo.entrypoint, "entrypoint", "", "Path of the new task's script. Defaults to the definition's path, or the slug with the kind's extension.")
+	cmd.Flags().StringArrayVar(&cfg.params, "param", nil, `Parameter of the new task, as "name:type[:description]". May be repeated.`)
 
 	// Remove dev flag + unhide these flags + deprecate `slug` before release!
 	cmd.Flags().BoolVar(&cfg.dev, "dev", false, "Dev mode: warning, not guaranteed to work and subject to change.")
@@ -94,21 +108,14 @@ func New(c *cli.Config) *cobra.Command {
 	if err := cmd.Flags().MarkHidden("code-only"); err != nil {
 		logger.Debug("error: %s", err)
 	}
-	if err := cmd.Flags().MarkHidden("def-format"); err != nil {
-		logger.Debug("error: %s", err)
-	}
-	if err := cmd.Flags().MarkHidden("yes"); err != nil {
-		logger.Debug("error: %s", err)
-	}
-	if err := cmd.Flags().MarkHidden("no"); err != nil {
-		logger.Debug("error: %s", err)
-	}
 
 	return cmd
 }
 
 func run(ctx context.Context, cfg config) error {
-	if !cfg.dev {
+	// Passing any new task information opts into creating a task definition.
+	newTask := cfg.newTaskInfo.name != "" || cfg.kind != ""
+	if !cfg.dev && !newTask {
 		return initCodeOnly(ctx, cfg)
 	}
 
@@ -119,6 +126,31 @@ func run(ctx context.Context, cfg config) error {
 	if cfg.assumeYes && cfg.assumeNo {
 		return errors.New("Cannot specify both --yes and --no")
 	}
+	if newTask && cfg.codeOnly {
+		return errors.New("Cannot specify --code-only for a new task")
+	}
+
+	if newTask {
+		// --slug names the new task rather than an existing one.
+		cfg.newTaskInfo.slug, cfg.slug = cfg.slug, ""
+		if cfg.newTaskInfo.slug != "" && !utils.IsSlug(cfg.newTaskInfo.slug) {
+			return errors.Errorf("Invalid slug %q: slugs may only contain lowercase letters, numbers and underscores", cfg.newTaskInfo.slug)
+		}
+	}
+	if cfg.kind != "" {
+		kind, err := parseKind(cfg.kind)
+		if err != nil {
+			return err
+		}
+		cfg.newTaskInfo.kind = kind
+	}
+	for _, p := range cfg.params {
+		param, err := parseParam(p)
+		if err != nil {
+			return err
+		}
+		cfg.newTaskInfo.params = append(cfg.newTaskInfo.params, param)
+	}
 
 	// Extrapolate defFormat from the specified file, if it's a definition file.
 	defFormat := definitions.GetTaskDefFormat(cfg.file)
@@ -127,7 +159,7 @@ func run(ctx context.Context, cfg config) error {
 	}
 
 	if cfg.slug == "" {
-		// Prompt for new task information.
+		// Prompt for any new task information that wasn't passed as flags.
 		if err := promptForNewTask(cfg.file, &cfg.newTaskInfo); err != nil {
 			return err
 		}
@@ -174,7 +206,26 @@ func initWithTaskDef(ctx context.Context, cfg config) error {
 		name = cfg.newTaskInfo.name
 		kind = cfg.newTaskInfo.kind
 		entrypoint = cfg.newTaskInfo.entrypoint
-		slug = utils.MakeSlug(name)
+		slug = cfg.newTaskInfo.slug
+		if slug == "" {
+			slug = utils.MakeSlug(name)
+		}
+	}
+
+	def, err := definitions.NewDefinition_0_3(name, slug, kind, entrypoint)
+	if err != nil {
+		return err
+	}
+	def.Parameters = cfg.newTaskInfo.params
+
+	// Generate the entrypoint with the new task's parameters, if any.
+	var skeleton *api.Task
+	if len(def.Parameters) > 0 {
+		params, err := def.APIParameters()
+		if err != nil {
+			return err
+		}
+		skeleton = &api.Task{Parameters: params}
 	}
 
 	r, err := runtime.Lookup(entrypoint, kind)
@@ -184,7 +235,7 @@ func initWithTaskDef(ctx context.Context, cfg config) error {
 
 	// Create entrypoint, without comment link, if it doesn't exist.
 	if !fsx.Exists(entrypoint) {
-		if err := createEntrypoint(r, entrypoint, nil); err != nil {
+		if err := createEntrypoint(r, entrypoint, skeleton); err != nil {
 			return errors.Wrapf(err, "unable to create entrypoint")
 		}
 		logger.Step("Created %s", entrypoint)
@@ -205,11 +256,6 @@ func initWithTaskDef(ctx context.Context, cfg config) error {
 		}
 	}
 
-	def, err := definitions.NewDefinition_0_3(name, slug, kind, entrypoint)
-	if err != nil {
-		return err
-	}
-
 	buf, err := def.Marshal(definitions.TaskDefFormat(cfg.defFormat))
 	if err != nil {
 		return err
@@ -238,6 +284,9 @@ func initCodeOnly(ctx context.Context, cfg config) error {
 	}
 
 	if cfg.file == "" {
+		if !utils.CanPrompt() {
+			return errors.New("Expected the path of the script to create as an argument")
+		}
 		cfg.file, err = promptForNewFileName(task)
 		if err != nil {
 			return err
@@ -256,7 +305,7 @@ func initCodeOnly(ctx context.Context, cfg config) error {
 			return nil
 		}
 
-		patch, err := patch(cfg.slug, cfg.file)
+		patch, err := patch(cfg.slug, cfg.file, cfg.assumeYes, cfg.assumeNo)
 		if err != nil {
 			return err
 		}
@@ -334,8 +383,14 @@ func suggestNextSteps(file string) {
 
 // Patch asks the user if he would like to patch a file
 // and add the airplane special comment.
-func patch(slug, file string) (ok bool, err error) {
-	err = survey.AskOne(
+func patch(slug, file string, assumeYes, assumeNo bool) (ok bool, err error) {
+	if assumeYes || assumeNo {
+		return assumeYes, nil
+	}
+	if !utils.CanPrompt() {
+		return false, errors.Errorf("Pass --yes to link %s to %s without prompting", file, slug)
+	}
+	err = utils.AskOne(
 		&survey.Confirm{
 			Message: fmt.Sprintf("Would you like to link %s to %s?", file, slug),
 			Help:    "Linking this file will add a special airplane comment.",
@@ -356,7 +411,7 @@ func promptForNewFileName(task api.Task) (string, error) {
 		fileName = filepath.Join("airplane", fileName)
 	}
 
-	if err := survey.AskOne(
+	if err := utils.AskOne(
 		&survey.Input{
 			Message: "Where should the script be created?",
 			Default: fileName,
@@ -403,17 +458,65 @@ func promptForNewTask(file string, info *newTaskInfo) error {
 	}
 
 	// Ask for a name.
-	if err := survey.AskOne(
-		&survey.Input{
-			Message: "What should this task be called?",
-			Default: base,
-		},
-		&info.name,
-	); err != nil {
-		return err
+	if info.name == "" {
+		if !utils.CanPrompt() {
+			return errors.New("Required flag(s) \"name\" not set")
+		}
+		if err := utils.AskOne(
+			&survey.Input{
+				Message: "What should this task be called?",
+				Default: base,
+			},
+			&info.name,
+		); err != nil {
+			return err
+		}
 	}
 
 	// Ask for a kind.
+	if info.kind == "" {
+		if !utils.CanPrompt() {
+			return errors.New("Required flag(s) \"kind\" not set")
+		}
+		if err := promptForKind(ext, info); err != nil {
+			return err
+		}
+	}
+
+	// Ask for an entrypoint, maybe.
+	if info.entrypoint == "" && info.kind != build.TaskKindREST && info.kind != build.TaskKindImage {
+		if file != "" && !definitions.IsTaskDef(file) {
+			info.entrypoint = file
+		} else {
+			fileName := utils.MakeSlug(info.name) + runtime.SuggestExt(info.kind)
+			if info.slug != "" {
+				fileName = info.slug + runtime.SuggestExt(info.kind)
+			}
+			if cwdIsHome, err := cwdIsHome(); err != nil {
+				return err
+			} else if cwdIsHome {
+				// Suggest a subdirectory to avoid putting a file directly into home directory.
+				fileName = filepath.Join("airplane", fileName)
+			}
+
+			if !utils.CanPrompt() {
+				info.entrypoint = fileName
+			} else if err := utils.AskOne(
+				&survey.Input{
+					Message: "Where should the script be created?",
+					Default: fileName,
+				},
+				&info.entrypoint,
+			); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func promptForKind(ext string, info *newTaskInfo) error {
 	var defaultKind interface{}
 	guessKind, err := runtime.SuggestKind(ext)
 	if err != nil {
@@ -423,7 +526,7 @@ func promptForNewTask(file string, info *newTaskInfo) error {
 	}
 
 	var selectedKindName string
-	if err := survey.AskOne(
+	if err := utils.AskOne(
 		&survey.Select{
 			Message: "What kind of task should this be?",
 			Options: orderedKindNames,
@@ -442,33 +545,43 @@ func promptForNewTask(file string, info *newTaskInfo) error {
 	if info.kind == "" {
 		return errors.Errorf("Unknown kind selected: %s", selectedKindName)
 	}
+	return nil
+}
 
-	// Ask for an entrypoint, maybe.
-	if info.kind != build.TaskKindREST && info.kind != build.TaskKindImage {
-		if file != "" && !definitions.IsTaskDef(file) {
-			info.entrypoint = file
-		} else {
-			fileName := utils.MakeSlug(info.name) + runtime.SuggestExt(info.kind)
-			if cwdIsHome, err := cwdIsHome(); err != nil {
-				return err
-			} else if cwdIsHome {
-				// Suggest a subdirectory to avoid putting a file directly into home directory.
-				fileName = filepath.Join("airplane", fileName)
-			}
-
-			if err := survey.AskOne(
-				&survey.Input{
-					Message: "Where should the script be created?",
-					Default: fileName,
-				},
-				&info.entrypoint,
-			); err != nil {
-				return err
-			}
+// parseKind parses a --kind flag, which is either a kind (e.g. "node") or
+// its display name (e.g. "Node").
+func parseKind(s string) (build.TaskKind, error) {
+	for kind, name := range namesByKind {
+		if strings.EqualFold(s, string(kind)) || strings.EqualFold(s, name) {
+			return kind, nil
 		}
 	}
+	var kinds []string
+	for _, name := range orderedKindNames {
+		kinds = append(kinds, strings.ToLower(name))
+	}
+	return "", errors.Errorf("Unknown kind %q: expected one of %s", s, strings.Join(kinds, ", "))
+}
 
-	return nil
+// parseParam parses a --param flag of the form "name:type[:description]".
+func parseParam(s string) (definitions.ParameterDefinition_0_3, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return definitions.ParameterDefinition_0_3{}, errors.Errorf("Invalid --param %q: expected name:type[:description]", s)
+	}
+	param := definitions.ParameterDefinition_0_3{
+		Name: parts[0],
+		Slug: utils.MakeSlug(parts[0]),
+		Type: parts[1],
+	}
+	if len(parts) == 3 {
+		param.Description = parts[2]
+	}
+	// Check the type with the same conversion used when deploying.
+	if _, err := (definitions.Definition_0_3{Parameters: []definitions.ParameterDefinition_0_3{param}}).APIParameters(); err != nil {
+		return definitions.ParameterDefinition_0_3{}, errors.Wrapf(err, "Invalid --param %q", s)
+	}
+	return param, nil
 }
 
 func cwdIsHome() (bool, error) {
@@ -0,0 +1,146 @@
+package initcmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/cmd/auth/login"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/airplanedev/lib/pkg/build"
+	"github.com/airplanedev/lib/pkg/runtime"
+	"github.com/airplanedev/lib/pkg/utils/fsx"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type scaffoldConfig struct {
+	client    api.APIClient
+	from      string
+	name      string
+	file      string
+	defFormat string
+	assumeYes bool
+	assumeNo  bool
+}
+
+// NewScaffoldFrom returns a new scaffold-from command.
+func NewScaffoldFrom(c *cli.Config) *cobra.Command {
+	var cfg = scaffoldConfig{client: c.Client}
+
+	cmd := &cobra.Command{
+		Use:   "scaffold-from <slug>",
+		Short: "Create a new task definition based on an existing task",
+		Long:  "Copies an existing task's parameters and settings into a new local task definition and entrypoint, under a new unique slug.",
+		Example: heredoc.Doc(`
+			$ airplane tasks scaffold-from my_task
+			$ airplane tasks scaffold-from my_task --name "My other task" --file ./other_task.ts
+		`),
+		Args: cobra.ExactArgs(1),
+		PersistentPreRunE: utils.WithParentPersistentPreRunE(func(cmd *cobra.Command, args []string) error {
+			return login.EnsureLoggedIn(cmd.Root().Context(), c)
+		}),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.from = args[0]
+			return runScaffoldFrom(cmd.Root().Context(), cfg)
+		},
+	}
+
+	cmd.Flags().StringVar(&cfg.name, "name", "", `Name of the new task. Defaults to "<name> (copy)".`)
+	cmd.Flags().StringVar(&cfg.file, "file", "", "Path of the entrypoint to create. Defaults to the new slug with the kind's extension.")
+	cmd.Flags().StringVar(&cfg.defFormat, "def-format", "yaml", `One of "json" or "yaml".`)
+	cmd.Flags().BoolVarP(&cfg.assumeYes, "yes", "y", false, "True to specify automatic yes to prompts.")
+	cmd.Flags().BoolVarP(&cfg.assumeNo, "no", "n", false, "True to specify automatic no to prompts.")
+
+	return cmd
+}
+
+func runScaffoldFrom(ctx context.Context, cfg scaffoldConfig) error {
+	client := cfg.client
+
+	if cfg.defFormat != "yaml" && cfg.defFormat != "json" {
+		return errors.Errorf("Invalid \"def-format\" specified: %s", cfg.defFormat)
+	}
+	if cfg.assumeYes && cfg.assumeNo {
+		return errors.New("Cannot specify both --yes and --no")
+	}
+
+	task, err := client.GetTask(ctx, cfg.from)
+	if err != nil {
+		return err
+	}
+
+	name := cfg.name
+	if name == "" {
+		name = fmt.Sprintf("%s (copy)", task.Name)
+	}
+	res, err := client.GetUniqueSlug(ctx, name, utils.MakeSlug(name))
+	if err != nil {
+		return errors.Wrap(err, "getting unique slug")
+	}
+	slug := res.Slug
+
+	var entrypoint string
+	if task.Kind != build.TaskKindREST && task.Kind != build.TaskKindImage {
+		entrypoint = cfg.file
+		if entrypoint == "" {
+			entrypoint = slug + runtime.SuggestExt(task.Kind)
+		}
+
+		r, err := runtime.Lookup(entrypoint, task.Kind)
+		if err != nil {
+			return errors.Wrapf(err, "unable to init %q - check that your CLI is up to date", entrypoint)
+		}
+
+		if fsx.Exists(entrypoint) {
+			logger.Step("%s already exists", entrypoint)
+		} else {
+			// The skeleton carries over the parameters, but must not link
+			// back to the task it was copied from.
+			skeleton := task
+			skeleton.URL = ""
+			if err := createEntrypoint(r, entrypoint, &skeleton); err != nil {
+				return errors.Wrap(err, "unable to create entrypoint")
+			}
+			logger.Step("Created %s", entrypoint)
+		}
+	}
+
+	defFn := fmt.Sprintf("%s.task.%s", slug, cfg.defFormat)
+	if fsx.Exists(defFn) {
+		question := fmt.Sprintf("Would you like to overwrite %s?", defFn)
+		if ok, err := utils.ConfirmWithAssumptions(question, cfg.assumeYes, cfg.assumeNo); err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+	}
+
+	definitions.WarnDeprecations(task.Slug, definitions.TaskDeprecations(task))
+	def, err := definitions.NewDefinitionFromTask_0_3(task, entrypoint)
+	if err != nil {
+		return err
+	}
+	def.Name = name
+	def.Slug = slug
+
+	buf, err := def.Marshal(definitions.TaskDefFormat(cfg.defFormat))
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(defFn, buf, 0644); err != nil {
+		return err
+	}
+	logger.Step("Created %s from %s", defFn, task.Slug)
+	if task.Kind == build.TaskKindSQL || task.Kind == build.TaskKindREST {
+		logger.Log("Resources are not copied over: set them in %s before deploying.", defFn)
+	}
+
+	suggestNextSteps(defFn)
+	return nil
+}
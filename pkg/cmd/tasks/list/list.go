@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/airplanedev/cli/pkg/print"
@@ -11,31 +12,74 @@ import (
 	"github.com/spf13/cobra"
 )
 
+type config struct {
+	root  *cli.Config
+	limit int
+	all   bool
+	mine  bool
+	owner string
+}
+
 // New returns a new list command.
 func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "Lists all tasks",
 		Example: heredoc.Doc(`
 			airplane tasks list
 			airplane tasks list -o json
+			airplane tasks list --limit 20
+			airplane tasks list --all
+
+			# List the tasks that you, or a teammate, are responsible for
+			airplane tasks list --mine
+			airplane tasks list --owner ada@example.com
 		`),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return run(cmd.Root().Context(), c)
+			if cfg.all && cmd.Flags().Changed("limit") {
+				return errors.New("only one of --limit and --all can be used")
+			}
+			if cfg.mine && cfg.owner != "" {
+				return errors.New("only one of --mine and --owner can be used")
+			}
+			return run(cmd.Root().Context(), cfg)
 		},
 	}
+	cmd.Flags().IntVar(&cfg.limit, "limit", 100, "If >0, returns at most --limit items.")
+	cmd.Flags().BoolVar(&cfg.all, "all", false, "Return all tasks.")
+	cmd.Flags().BoolVar(&cfg.mine, "mine", false, "Only return tasks that you own, or created if they have no owner.")
+	cmd.Flags().StringVar(&cfg.owner, "owner", "", "Only return tasks owned by this email, or created by it if they have no owner.")
 	return cmd
 }
 
 // Run runs the list command.
-func run(ctx context.Context, c *cli.Config) error {
-	var client = c.Client
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
 
-	res, err := client.ListTasks(ctx)
+	req := api.ListTasksRequest{Limit: cfg.limit, Owner: cfg.owner}
+	if cfg.all {
+		req.Limit = 0
+	}
+	if cfg.mine {
+		info, err := client.AuthInfo(ctx)
+		if err != nil {
+			return errors.Wrap(err, "getting current user")
+		}
+		if info.User == nil || info.User.Email == "" {
+			return errors.New("--mine needs you to be logged in as a user: use --owner <email> instead")
+		}
+		req.Owner = info.User.Email
+	}
+	res, err := client.ListTasks(ctx, req)
 	if err != nil {
 		return errors.Wrap(err, "list tasks")
 	}
 
+	if len(res.Tasks) == 0 && req.Owner != "" {
+		logger.Log("There are no tasks owned by %s.", req.Owner)
+		return nil
+	}
 	if len(res.Tasks) == 0 {
 		logger.Log(`
   There are no tasks yet. To create a sample task:
@@ -44,5 +88,8 @@ func run(ctx context.Context, c *cli.Config) error {
 	}
 
 	print.Tasks(res.Tasks)
+	if req.Limit > 0 && len(res.Tasks) == req.Limit {
+		logger.Log(logger.Gray("Showing the first %d tasks. To see all of them, use --all.", req.Limit))
+	}
 	return nil
 }
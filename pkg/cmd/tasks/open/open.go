@@ -69,12 +69,12 @@ func run(ctx context.Context, cfg config) error {
 		slug = def.Slug
 	}
 
-	task, err := client.GetTask(ctx, slug)
-	if err != nil {
+	// Resolving the ID checks that the task exists, usually without a request.
+	if _, err := client.GetTaskID(ctx, slug); err != nil {
 		return errors.Wrap(err, "get task")
 	}
 
-	taskURL := client.TaskURL(task.Slug)
+	taskURL := client.TaskURL(slug)
 	logger.Log("Opening %s", taskURL)
 	if !utils.Open(taskURL) {
 		logger.Log("Could not open browser - try copying and pasting the above URL")
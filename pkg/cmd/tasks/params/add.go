@@ -0,0 +1,54 @@
+package params
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newAdd(c *cli.Config) *cobra.Command {
+	var flags paramFlags
+	var update bool
+
+	cmd := &cobra.Command{
+		Use:   "add <def-file>",
+		Short: "Add a parameter to a task definition",
+		Example: heredoc.Doc(`
+			airplane tasks params add ./my_task.task.yaml --name "Dry run" --type boolean --default true
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if flags.name == "" {
+				return errors.New("Required flag(s) \"name\" not set")
+			}
+			var p definitions.ParameterDefinition_0_3
+			if err := flags.apply(cmd, &p); err != nil {
+				return err
+			}
+			if p.Slug == "" {
+				p.Slug = utils.MakeSlug(p.Name)
+			}
+			return runAdd(cmd.Root().Context(), c, args[0], p, update)
+		},
+	}
+
+	flags.register(cmd, true)
+	addUpdateFlag(cmd, &update)
+
+	return cmd
+}
+
+func runAdd(ctx context.Context, c *cli.Config, path string, p definitions.ParameterDefinition_0_3, update bool) error {
+	return edit(ctx, c, path, update, fmt.Sprintf("Added parameter %s to %s", p.Slug, path), func(ps []definitions.ParameterDefinition_0_3) ([]definitions.ParameterDefinition_0_3, error) {
+		if _, err := findParam(ps, p.Slug); err == nil {
+			return nil, errors.Errorf("task definition already has a parameter %q", p.Slug)
+		}
+		return append(ps, p), nil
+	})
+}
@@ -0,0 +1,52 @@
+package params
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newEdit(c *cli.Config) *cobra.Command {
+	var flags paramFlags
+	var update bool
+
+	cmd := &cobra.Command{
+		Use:   "edit <def-file> <slug>",
+		Short: "Edit a parameter of a task definition",
+		Long:  "Edits a parameter of a task definition. Only the fields whose flags are passed are changed.",
+		Example: heredoc.Doc(`
+			airplane tasks params edit ./my_task.task.yaml dry_run --default false
+			airplane tasks params edit ./my_task.task.yaml user --slug user_id --type integer
+		`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEdit(cmd.Root().Context(), c, cmd, args[0], args[1], flags, update)
+		},
+	}
+
+	flags.register(cmd, true)
+	addUpdateFlag(cmd, &update)
+
+	return cmd
+}
+
+func runEdit(ctx context.Context, c *cli.Config, cmd *cobra.Command, path, slug string, flags paramFlags, update bool) error {
+	return edit(ctx, c, path, update, fmt.Sprintf("Edited parameter %s in %s", slug, path), func(ps []definitions.ParameterDefinition_0_3) ([]definitions.ParameterDefinition_0_3, error) {
+		i, err := findParam(ps, slug)
+		if err != nil {
+			return nil, err
+		}
+		if err := flags.apply(cmd, &ps[i]); err != nil {
+			return nil, err
+		}
+		if j, err := findParam(ps, ps[i].Slug); err == nil && j != i {
+			return nil, errors.Errorf("task definition already has a parameter %q", ps[i].Slug)
+		}
+		return ps, nil
+	})
+}
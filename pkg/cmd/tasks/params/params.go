@@ -0,0 +1,159 @@
+// Package params implements the `airplane tasks params` commands, which edit
+// the parameters of a task definition in place.
+package params
+
+import (
+	"context"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	paramvalues "github.com/airplanedev/cli/pkg/params"
+	"github.com/airplanedev/cli/pkg/taskdir"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// New returns a new params command.
+func New(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "params",
+		Short: "Edit the parameters of a task definition",
+		Long:  "Adds, removes and edits parameters in a task definition file, leaving the rest of the file as it is.",
+		Example: heredoc.Doc(`
+			airplane tasks params add ./my_task.task.yaml --name "User ID" --type integer --required
+			airplane tasks params edit ./my_task.task.yaml user_id --description "ID of the user to update"
+			airplane tasks params remove ./my_task.task.yaml user_id --update
+		`),
+	}
+
+	cmd.AddCommand(newAdd(c))
+	cmd.AddCommand(newRemove(c))
+	cmd.AddCommand(newEdit(c))
+
+	return cmd
+}
+
+// paramFlags are the parameter fields that can be set by add and edit.
+type paramFlags struct {
+	name        string
+	slug        string
+	typ         string
+	description string
+	def         string
+	required    bool
+	multi       bool
+}
+
+func (f *paramFlags) register(cmd *cobra.Command, slug bool) {
+	cmd.Flags().StringVar(&f.name, "name", "", "Name of the parameter.")
+	if slug {
+		cmd.Flags().StringVar(&f.slug, "slug", "", "Slug of the parameter. Defaults to one made from its name.")
+	}
+	cmd.Flags().StringVar(&f.typ, "type", "shorttext", "Type of the parameter: shorttext, longtext, sql, boolean, upload, integer, float, date, datetime or configvar.")
+	cmd.Flags().StringVar(&f.description, "description", "", "Description of the parameter.")
+	cmd.Flags().StringVar(&f.def, "default", "", "Default value of the parameter.")
+	cmd.Flags().BoolVar(&f.required, "required", false, "Whether the parameter is required.")
+	cmd.Flags().BoolVar(&f.multi, "multi", false, "Whether the parameter accepts multiple values.")
+}
+
+// apply sets the fields of p whose flags were passed to cmd.
+func (f paramFlags) apply(cmd *cobra.Command, p *definitions.ParameterDefinition_0_3) error {
+	changed := cmd.Flags().Changed
+	if changed("name") {
+		p.Name = f.name
+	}
+	if changed("slug") {
+		p.Slug = f.slug
+	}
+	if changed("type") || p.Type == "" {
+		p.Type = f.typ
+	}
+	if changed("description") {
+		p.Description = f.description
+	}
+	if changed("required") {
+		p.Required = f.required
+	}
+	if changed("multi") {
+		p.Multi = f.multi
+	}
+
+	// Convert the parameter like a deploy would, which also checks its type.
+	converted, err := definitions.Definition_0_3{Parameters: []definitions.ParameterDefinition_0_3{*p}}.APIParameters()
+	if err != nil {
+		return err
+	}
+	if changed("default") {
+		if f.def == "" {
+			p.Default = nil
+		} else if err := paramvalues.ValidateInput(converted[0], f.def); err != nil {
+			return errors.Wrap(err, "invalid --default")
+		} else if p.Default, err = paramvalues.ParseInput(converted[0], f.def); err != nil {
+			return errors.Wrap(err, "invalid --default")
+		}
+	}
+	return nil
+}
+
+// findParam returns the index of the parameter with the given slug, or an
+// error if the definition has no such parameter.
+func findParam(ps []definitions.ParameterDefinition_0_3, slug string) (int, error) {
+	for i, p := range ps {
+		if p.Slug == slug {
+			return i, nil
+		}
+	}
+	return -1, errors.Errorf("task definition has no parameter %q", slug)
+}
+
+// updateDeployed updates the deployed task from the definition at path. The
+// task keeps its current image, so only the definition is updated.
+func updateDeployed(ctx context.Context, client api.APIClient, path string) error {
+	dir, err := taskdir.Open(path, true)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	def, err := dir.ReadDefinition_0_3()
+	if err != nil {
+		return err
+	}
+	task, err := client.GetTask(ctx, def.Slug)
+	if err != nil {
+		return err
+	}
+
+	req, err := def.GetUpdateTaskRequest(ctx, client, task.Image)
+	if err != nil {
+		return err
+	}
+	req.InterpolationMode = task.InterpolationMode
+	if _, err := client.UpdateTask(ctx, req); err != nil {
+		return errors.Wrapf(err, "updating task %s", def.Slug)
+	}
+	logger.Step("Updated the parameters of %s", client.TaskURL(def.Slug))
+	return nil
+}
+
+// edit applies fn to the parameters of the definition at path and, if
+// requested, updates the deployed task.
+func edit(ctx context.Context, c *cli.Config, path string, update bool, msg string, fn func([]definitions.ParameterDefinition_0_3) ([]definitions.ParameterDefinition_0_3, error)) error {
+	if err := definitions.EditParameters(path, fn); err != nil {
+		return err
+	}
+	logger.Step(msg)
+
+	if !update {
+		return nil
+	}
+	return updateDeployed(ctx, c.Client, path)
+}
+
+// addUpdateFlag registers the --update flag shared by every params command.
+func addUpdateFlag(cmd *cobra.Command, update *bool) {
+	cmd.Flags().BoolVar(update, "update", false, "Also update the deployed task's parameters, without rebuilding it.")
+}
@@ -0,0 +1,42 @@
+package params
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/spf13/cobra"
+)
+
+func newRemove(c *cli.Config) *cobra.Command {
+	var update bool
+
+	cmd := &cobra.Command{
+		Use:     "remove <def-file> <slug>",
+		Short:   "Remove a parameter from a task definition",
+		Aliases: []string{"rm"},
+		Example: heredoc.Doc(`
+			airplane tasks params remove ./my_task.task.yaml dry_run
+		`),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemove(cmd.Root().Context(), c, args[0], args[1], update)
+		},
+	}
+
+	addUpdateFlag(cmd, &update)
+
+	return cmd
+}
+
+func runRemove(ctx context.Context, c *cli.Config, path, slug string, update bool) error {
+	return edit(ctx, c, path, update, fmt.Sprintf("Removed parameter %s from %s", slug, path), func(ps []definitions.ParameterDefinition_0_3) ([]definitions.ParameterDefinition_0_3, error) {
+		i, err := findParam(ps, slug)
+		if err != nil {
+			return nil, err
+		}
+		return append(ps[:i], ps[i+1:]...), nil
+	})
+}
@@ -0,0 +1,157 @@
+package pause
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type config struct {
+	root   *cli.Config
+	slug   string
+	until  string
+	length time.Duration
+	reason string
+}
+
+// New returns a new pause command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "pause <slug>",
+		Short: "Pause a task for maintenance",
+		Long: heredoc.Doc(`
+			Pause a task for a maintenance window, such as while a database it
+			writes to is migrated. While a task is paused, its schedules don't run
+			and only team admins can execute it.
+
+			The task stays paused until it's resumed, or until --until or --for
+			if set.
+		`),
+		Example: heredoc.Doc(`
+			airplane tasks pause sync_accounts --reason "Migrating the accounts database"
+
+			# Resume on its own in two hours
+			airplane tasks pause sync_accounts --for 2h
+
+			# Resume on its own at a given time
+			airplane tasks pause sync_accounts --until 2022-04-16T06:00
+
+			airplane tasks resume sync_accounts
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg.slug = args[0]
+			return run(cmd.Root().Context(), cfg)
+		},
+	}
+	cmd.Flags().StringVar(&cfg.until, "until", "", "Resume the task on its own at the given time, such as 2022-04-16T06:00.")
+	cmd.Flags().DurationVar(&cfg.length, "for", 0, "Resume the task on its own after the given duration, such as 2h.")
+	cmd.Flags().StringVar(&cfg.reason, "reason", "", "Why the task is paused, shown to anyone who tries to execute it.")
+	return cmd
+}
+
+// NewResume returns a new resume command.
+func NewResume(c *cli.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resume <slug>",
+		Short: "Resume a paused task",
+		Long:  "Resume a task paused with airplane tasks pause, so that its schedules run again and anyone with access can execute it.",
+		Example: heredoc.Doc(`
+			airplane tasks resume sync_accounts
+		`),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return resume(cmd.Root().Context(), c, args[0])
+		},
+	}
+	return cmd
+}
+
+// Run runs the pause command.
+func run(ctx context.Context, cfg config) error {
+	var client = cfg.root.Client
+
+	if cfg.until != "" && cfg.length != 0 {
+		return errors.New("only one of --until and --for can be used")
+	}
+	var until *time.Time
+	if cfg.until != "" {
+		t, err := utils.ParseTime(cfg.until)
+		if err != nil {
+			return errors.Wrap(err, "invalid --until")
+		}
+		if !t.After(time.Now()) {
+			return errors.Errorf("--until %s is in the past", cfg.until)
+		}
+		until = &t
+	} else if cfg.length > 0 {
+		t := time.Now().Add(cfg.length)
+		until = &t
+	} else if cfg.length < 0 {
+		return errors.New("--for must be positive")
+	}
+
+	task, err := client.GetTask(ctx, cfg.slug)
+	if err != nil {
+		return err
+	}
+	if err := client.PauseTask(ctx, api.PauseTaskRequest{
+		TaskID: task.ID,
+		Paused: true,
+		Until:  until,
+		Reason: cfg.reason,
+	}); err != nil {
+		return errors.Wrapf(err, "pausing %s", cfg.slug)
+	}
+
+	pause := &api.TaskPause{Reason: cfg.reason, Until: until}
+	logger.Log("Paused %s%s.", logger.Bold(task.Slug), Describe(pause))
+	logger.Log("Its schedules won't run, and only team admins can execute it.")
+	logger.Suggest("⚡ To resume it:", "airplane tasks resume %s", task.Slug)
+	return nil
+}
+
+// resume runs the resume command.
+func resume(ctx context.Context, c *cli.Config, slug string) error {
+	var client = c.Client
+
+	task, err := client.GetTask(ctx, slug)
+	if err != nil {
+		return err
+	}
+	if !task.Pause.Active(time.Now()) {
+		logger.Log("%s isn't paused.", logger.Bold(task.Slug))
+		return nil
+	}
+	if err := client.PauseTask(ctx, api.PauseTaskRequest{TaskID: task.ID, Paused: false}); err != nil {
+		return errors.Wrapf(err, "resuming %s", slug)
+	}
+	logger.Log("Resumed %s: its schedules will run again, and anyone with access can execute it.", logger.Bold(task.Slug))
+	return nil
+}
+
+// Describe describes how long a task is paused for and why, such as
+// " until 2022-04-16T06:00:00Z: Migrating the accounts database", to follow
+// the task's slug.
+func Describe(p *api.TaskPause) string {
+	if p == nil {
+		return ""
+	}
+	var s string
+	if p.Until != nil {
+		s = fmt.Sprintf(" until %s", utils.FormatTime(*p.Until))
+	}
+	if p.Reason != "" {
+		s += fmt.Sprintf(": %s", p.Reason)
+	}
+	return s
+}
@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/airplanedev/cli/pkg/cli"
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Config is the schema config.
+type config struct {
+	root       *cli.Config
+	format     string
+	definition string
+}
+
+// New returns a new schema command.
+func New(c *cli.Config) *cobra.Command {
+	var cfg = config{root: c}
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the schema task definitions are validated against",
+		Long: heredoc.Doc(`
+			Print the JSON Schema that the CLI validates task definitions with, for use
+			with editors, linters and pre-commit checks.
+
+			Pass --strict to deploy to also fail on fields the schema doesn't list.
+		`),
+		Example: heredoc.Doc(`
+			airplane tasks schema --format jsonschema
+			airplane tasks schema --definition task > task.schema.json
+		`),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return run(cfg)
+		},
+	}
+	cmd.Flags().StringVar(&cfg.format, "format", "jsonschema", "Format to print the schema in. Only jsonschema is supported.")
+	cmd.Flags().StringVar(&cfg.definition, "definition", "yaml", "Definition format to print the schema of: yaml for airplane.yml files, or task for *.task.yaml and *.task.json files.")
+	return cmd
+}
+
+// Run runs the schema command.
+func run(cfg config) error {
+	if cfg.format != "jsonschema" {
+		return errors.Errorf("unsupported --format %q: expected jsonschema", cfg.format)
+	}
+
+	var buf []byte
+	switch cfg.definition {
+	case "yaml":
+		var err error
+		buf, err = definitions.Schema()
+		if err != nil {
+			return errors.Wrap(err, "generating schema")
+		}
+	case "task":
+		buf = definitions.Schema_0_3()
+	default:
+		return errors.Errorf("unsupported --definition %q: expected yaml or task", cfg.definition)
+	}
+
+	fmt.Fprintln(os.Stdout, string(buf))
+	return nil
+}
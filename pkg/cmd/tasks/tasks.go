@@ -5,12 +5,19 @@ import (
 	"github.com/airplanedev/cli/pkg/cli"
 	"github.com/airplanedev/cli/pkg/cmd/auth/login"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/deploy"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/deps"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/deps/install"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/dev"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/diff"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/execute"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/get"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/history"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/initcmd"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/list"
 	"github.com/airplanedev/cli/pkg/cmd/tasks/open"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/params"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/pause"
+	"github.com/airplanedev/cli/pkg/cmd/tasks/schema"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/spf13/cobra"
 )
@@ -34,12 +41,29 @@ func New(c *cli.Config) *cobra.Command {
 	}
 
 	cmd.AddCommand(deploy.New(c))
+	cmd.AddCommand(deps.New(c))
+	cmd.AddCommand(diff.New(c))
 	cmd.AddCommand(list.New(c))
 	cmd.AddCommand(dev.New(c))
 	cmd.AddCommand(execute.New(c))
 	cmd.AddCommand(get.New(c))
+	cmd.AddCommand(history.New(c))
 	cmd.AddCommand(initcmd.New(c))
+	cmd.AddCommand(initcmd.NewScaffoldFrom(c))
 	cmd.AddCommand(open.New(c))
+	cmd.AddCommand(params.New(c))
+	cmd.AddCommand(pause.New(c))
+	cmd.AddCommand(pause.NewResume(c))
+	cmd.AddCommand(schema.New(c))
+
+	// `airplane tasks install-deps` was briefly the name of
+	// `airplane tasks deps install`, so it keeps working, hidden.
+	installDeps := install.New(c)
+	installDeps.Use = "install-deps ./path/to/definition"
+	installDeps.Example = ""
+	installDeps.Hidden = true
+	installDeps.Deprecated = "use airplane tasks deps install instead."
+	cmd.AddCommand(installDeps)
 
 	return cmd
 }
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/pkg/errors"
 )
@@ -18,20 +19,25 @@ var (
 type Config struct {
 	Tokens          map[string]string `json:"tokens,omitempty"`
 	EnableTelemetry *bool             `json:"enableTelemetry,omitempty"`
+	// OutputHooks reshape the outputs of every run before they are printed.
+	OutputHooks []string `json:"outputHooks,omitempty"`
+	// Redact are rules masking secrets in the logs of every run.
+	Redact []string `json:"redact,omitempty"`
 }
 
-// Path returns the default config path.
-func path() string {
+// Dir returns the directory the CLI stores its state in.
+func Dir() string {
 	homedir, err := os.UserHomeDir()
 	if err != nil {
 		// TODO(amir): friendly output.
 		panic("$HOME environment variable must be set")
 	}
-	return filepath.Join(
-		homedir,
-		".airplane",
-		"config",
-	)
+	return filepath.Join(homedir, ".airplane")
+}
+
+// Path returns the default config path.
+func path() string {
+	return filepath.Join(Dir(), "config")
 }
 
 // ReadDefault reads the configuration from the default location.
@@ -92,11 +98,72 @@ func GetTeamID() string {
 	return os.Getenv("AP_TEAM_ID")
 }
 
+// GetRecord gets the path of a cassette to record API requests to from an
+// env var, if one exists.
+func GetRecord() string {
+	return os.Getenv("AP_RECORD")
+}
+
+// GetReplay gets the path of a cassette to replay API requests from from an
+// env var, if one exists.
+func GetReplay() string {
+	return os.Getenv("AP_REPLAY")
+}
+
 // GetGitRepo gets a git repo from an env var, if one exists.
 func GetGitRepo() string {
 	return os.Getenv("AP_GIT_REPO")
 }
 
+// GetBuilderSize gets the default remote builder size from an env var, if
+// one exists.
+func GetBuilderSize() string {
+	return os.Getenv("AP_BUILDER_SIZE")
+}
+
+// GetBuilderRegion gets the default remote builder region from an env var,
+// if one exists.
+func GetBuilderRegion() string {
+	return os.Getenv("AP_BUILDER_REGION")
+}
+
+// GetPlain reports whether plain output is turned on by an env var.
+func GetPlain() bool {
+	plain, _ := strconv.ParseBool(os.Getenv("AP_PLAIN"))
+	return plain
+}
+
+// GetNoInput reports whether prompting is turned off by an env var.
+func GetNoInput() bool {
+	noInput, _ := strconv.ParseBool(os.Getenv("AP_NO_INPUT"))
+	return noInput
+}
+
+// GetCACert gets the path of extra CA certificates to trust from an env var,
+// if one exists.
+func GetCACert() string {
+	return os.Getenv("AP_CA_CERT")
+}
+
+// GetClientCert gets the path of a client TLS certificate from an env var,
+// if one exists.
+func GetClientCert() string {
+	return os.Getenv("AP_CLIENT_CERT")
+}
+
+// GetClientKey gets the path of a client TLS certificate's key from an env
+// var, if one exists.
+func GetClientKey() string {
+	return os.Getenv("AP_CLIENT_KEY")
+}
+
+// GetInsecureSkipVerify reports whether server certificate verification is
+// turned off by an env var.
+func GetInsecureSkipVerify() bool {
+	insecure, _ := strconv.ParseBool(os.Getenv("AP_INSECURE_SKIP_VERIFY"))
+	return insecure
+}
+
 // GetGitUser gets a git user from an env var, if one exists.
 func GetGitUser() string {
 	return os.Getenv("AP_GIT_USER")
@@ -0,0 +1,186 @@
+package conf
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNoCredential is returned when no token is stored for a host.
+	ErrNoCredential = errors.New("conf: no credential stored")
+)
+
+// keychainService is the service auth tokens are stored under in the OS
+// keychain, with the API host as the account.
+const keychainService = "airplane-cli"
+
+// CredentialStore stores auth tokens, keyed by API host.
+type CredentialStore interface {
+	// Name describes where tokens are stored, such as "macOS Keychain".
+	Name() string
+	// Get returns the token for host, or ErrNoCredential.
+	Get(host string) (string, error)
+	Set(host, token string) error
+	// Delete removes the token for host. It succeeds if there is none.
+	Delete(host string) error
+}
+
+// Credentials returns the store auth tokens are kept in: the OS keychain when
+// one is available, and the config file otherwise.
+//
+// Set AP_CREDENTIAL_STORE=file to always use the config file, such as on
+// machines where the keychain prompts for a password that no one is there to
+// type.
+func Credentials() CredentialStore {
+	file := FileStore{Path: path()}
+	if strings.EqualFold(os.Getenv("AP_CREDENTIAL_STORE"), "file") {
+		return file
+	}
+	keychain, ok := osKeychain()
+	if !ok {
+		return file
+	}
+	return &fallbackStore{keychain: keychain, file: file}
+}
+
+// FileStore stores tokens in plaintext, in the tokens of the config file at
+// Path.
+type FileStore struct {
+	Path string
+}
+
+// Name implementation.
+func (s FileStore) Name() string {
+	return s.Path
+}
+
+// Get implementation.
+func (s FileStore) Get(host string) (string, error) {
+	cfg, err := Read(s.Path)
+	if errors.Is(err, ErrMissing) {
+		return "", ErrNoCredential
+	} else if err != nil {
+		return "", err
+	}
+	token, ok := cfg.Tokens[host]
+	if !ok || token == "" {
+		return "", ErrNoCredential
+	}
+	return token, nil
+}
+
+// Set implementation.
+func (s FileStore) Set(host, token string) error {
+	cfg, err := Read(s.Path)
+	if err != nil && !errors.Is(err, ErrMissing) {
+		return err
+	}
+	if cfg.Tokens == nil {
+		cfg.Tokens = map[string]string{}
+	}
+	cfg.Tokens[host] = token
+	return Write(s.Path, cfg)
+}
+
+// Delete implementation.
+func (s FileStore) Delete(host string) error {
+	cfg, err := Read(s.Path)
+	if errors.Is(err, ErrMissing) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, ok := cfg.Tokens[host]; !ok {
+		return nil
+	}
+	delete(cfg.Tokens, host)
+	return Write(s.Path, cfg)
+}
+
+// fallbackStore keeps tokens in the OS keychain, falling back to the config
+// file if the keychain can't be used, such as over SSH without a session bus.
+//
+// Tokens saved in the config file by earlier versions of the CLI are moved to
+// the keychain the first time they're read.
+type fallbackStore struct {
+	keychain CredentialStore
+	file     CredentialStore
+	// used is the store that held the token last read or written.
+	used CredentialStore
+}
+
+// Name implementation. It names the store that holds the token last read or
+// written, which is the config file if the keychain couldn't be used, or the
+// keychain before any token is.
+func (s *fallbackStore) Name() string {
+	if s.used != nil {
+		return s.used.Name()
+	}
+	return s.keychain.Name()
+}
+
+// Get implementation.
+func (s *fallbackStore) Get(host string) (string, error) {
+	token, err := s.keychain.Get(host)
+	if err == nil {
+		s.used = s.keychain
+		return token, nil
+	}
+	token, ferr := s.file.Get(host)
+	if ferr != nil || !errors.Is(err, ErrNoCredential) {
+		if ferr == nil {
+			s.used = s.file
+		}
+		return token, ferr
+	}
+	if err := s.keychain.Set(host, token); err == nil {
+		_ = s.file.Delete(host)
+		s.used = s.keychain
+	} else {
+		s.used = s.file
+	}
+	return token, nil
+}
+
+// Set implementation.
+func (s *fallbackStore) Set(host, token string) error {
+	if err := s.keychain.Set(host, token); err != nil {
+		s.used = s.file
+		return s.file.Set(host, token)
+	}
+	s.used = s.keychain
+	// Don't leave a stale plaintext copy behind.
+	return s.file.Delete(host)
+}
+
+// Delete implementation.
+func (s *fallbackStore) Delete(host string) error {
+	kerr := s.keychain.Delete(host)
+	if err := s.file.Delete(host); err != nil {
+		return err
+	}
+	return kerr
+}
+
+// runTool runs a keychain command line tool, returning its trimmed stdout
+// and exit code. A non-zero exit code is not an error, since the tools use
+// them to report missing items.
+func runTool(stdin string, name string, args ...string) (string, int, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", exitErr.ExitCode(), nil
+	} else if err != nil {
+		return "", 0, errors.Wrapf(err, "running %s", name)
+	}
+	return strings.TrimSpace(stdout.String()), 0, nil
+}
@@ -0,0 +1,114 @@
+package conf
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a keychain that keeps tokens in memory, or fails every
+// write if broken is set.
+type memoryStore struct {
+	tokens map[string]string
+	broken bool
+}
+
+func (s *memoryStore) Name() string { return "memory" }
+
+func (s *memoryStore) Get(host string) (string, error) {
+	if token, ok := s.tokens[host]; ok {
+		return token, nil
+	}
+	return "", ErrNoCredential
+}
+
+func (s *memoryStore) Set(host, token string) error {
+	if s.broken {
+		return errors.New("keychain is locked")
+	}
+	s.tokens[host] = token
+	return nil
+}
+
+func (s *memoryStore) Delete(host string) error {
+	delete(s.tokens, host)
+	return nil
+}
+
+func TestCredentials(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		var assert = require.New(t)
+		var store = FileStore{Path: filepath.Join(tempdir(t), ".airplane", "config")}
+
+		_, err := store.Get("airplane.dev")
+		assert.True(errors.Is(err, ErrNoCredential))
+		assert.NoError(store.Delete("airplane.dev"))
+
+		assert.NoError(store.Set("airplane.dev", "foo"))
+		token, err := store.Get("airplane.dev")
+		assert.NoError(err)
+		assert.Equal("foo", token)
+
+		assert.NoError(store.Delete("airplane.dev"))
+		_, err = store.Get("airplane.dev")
+		assert.True(errors.Is(err, ErrNoCredential))
+	})
+
+	t.Run("keychain migrates file tokens", func(t *testing.T) {
+		var assert = require.New(t)
+		var file = FileStore{Path: filepath.Join(tempdir(t), ".airplane", "config")}
+		var keychain = &memoryStore{tokens: map[string]string{}}
+		var store = &fallbackStore{keychain: keychain, file: file}
+
+		assert.NoError(file.Set("airplane.dev", "foo"))
+		token, err := store.Get("airplane.dev")
+		assert.NoError(err)
+		assert.Equal("foo", token)
+		assert.Equal("foo", keychain.tokens["airplane.dev"])
+		assert.Equal("memory", store.Name())
+		_, err = file.Get("airplane.dev")
+		assert.True(errors.Is(err, ErrNoCredential))
+
+		assert.NoError(store.Delete("airplane.dev"))
+		_, err = store.Get("airplane.dev")
+		assert.True(errors.Is(err, ErrNoCredential))
+	})
+
+	t.Run("keychain falls back to file", func(t *testing.T) {
+		var assert = require.New(t)
+		var file = FileStore{Path: filepath.Join(tempdir(t), ".airplane", "config")}
+		var store = &fallbackStore{keychain: &memoryStore{tokens: map[string]string{}, broken: true}, file: file}
+
+		assert.NoError(store.Set("airplane.dev", "foo"))
+		token, err := file.Get("airplane.dev")
+		assert.NoError(err)
+		assert.Equal("foo", token)
+		token, err = store.Get("airplane.dev")
+		assert.NoError(err)
+		assert.Equal("foo", token)
+	})
+
+	t.Run("name reports the store with the token", func(t *testing.T) {
+		var assert = require.New(t)
+		var file = FileStore{Path: filepath.Join(tempdir(t), ".airplane", "config")}
+		var keychain = &memoryStore{tokens: map[string]string{}, broken: true}
+		assert.Equal("memory", (&fallbackStore{keychain: keychain, file: file}).Name())
+
+		// The token stays in the file while the keychain can't be written.
+		assert.NoError(file.Set("airplane.dev", "foo"))
+		store := &fallbackStore{keychain: keychain, file: file}
+		_, err := store.Get("airplane.dev")
+		assert.NoError(err)
+		assert.Equal(file.Path, store.Name())
+
+		store = &fallbackStore{keychain: keychain, file: file}
+		assert.NoError(store.Set("airplane.dev", "bar"))
+		assert.Equal(file.Path, store.Name())
+
+		keychain.broken = false
+		assert.NoError(store.Set("airplane.dev", "baz"))
+		assert.Equal("memory", store.Name())
+	})
+}
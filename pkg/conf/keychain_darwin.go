@@ -0,0 +1,91 @@
+//go:build darwin
+// +build darwin
+
+package conf
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// errSecItemNotFound is the exit code of security(1) for missing items.
+const errSecItemNotFound = 44
+
+// keychain stores tokens in the macOS Keychain with security(1).
+type keychain struct{}
+
+func osKeychain() (CredentialStore, bool) {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil, false
+	}
+	return keychain{}, true
+}
+
+// Name implementation.
+func (keychain) Name() string {
+	return "macOS Keychain"
+}
+
+// Get implementation.
+func (keychain) Get(host string) (string, error) {
+	out, code, err := runTool("", "security", "find-generic-password", "-s", keychainService, "-a", host, "-w")
+	switch {
+	case err != nil:
+		return "", err
+	case code == errSecItemNotFound:
+		return "", ErrNoCredential
+	case code != 0:
+		return "", errors.Errorf("security find-generic-password exited with status %d", code)
+	}
+	return out, nil
+}
+
+// Set implementation.
+//
+// The token is passed to security(1) in interactive mode on stdin, rather
+// than as an argument, so that it isn't visible to other processes. Since
+// interactive mode doesn't report the status of its commands, the token is
+// read back to check that it was stored.
+func (k keychain) Set(host, token string) error {
+	if strings.ContainsAny(token, "\r\n") {
+		return errors.New("tokens can't contain line breaks")
+	}
+	cmd := strings.Join([]string{
+		"add-generic-password", "-U",
+		"-s", securityQuote(keychainService),
+		"-a", securityQuote(host),
+		"-l", securityQuote("Airplane CLI (" + host + ")"),
+		"-w", securityQuote(token),
+	}, " ")
+	_, code, err := runTool(cmd+"\n", "security", "-i")
+	if err != nil {
+		return err
+	} else if code != 0 {
+		return errors.Errorf("security add-generic-password exited with status %d", code)
+	}
+	if stored, err := k.Get(host); err != nil {
+		return err
+	} else if stored != token {
+		return errors.New("security add-generic-password did not store the token")
+	}
+	return nil
+}
+
+// securityQuote quotes s as an argument to a command in security(1)'s
+// interactive mode.
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Delete implementation.
+func (keychain) Delete(host string) error {
+	_, code, err := runTool("", "security", "delete-generic-password", "-s", keychainService, "-a", host)
+	if err != nil {
+		return err
+	} else if code != 0 && code != errSecItemNotFound {
+		return errors.Errorf("security delete-generic-password exited with status %d", code)
+	}
+	return nil
+}
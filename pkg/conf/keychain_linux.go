@@ -0,0 +1,61 @@
+//go:build linux
+// +build linux
+
+package conf
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// keychain stores tokens with the Secret Service, such as GNOME Keyring or
+// KWallet, through secret-tool(1) from libsecret.
+type keychain struct{}
+
+func osKeychain() (CredentialStore, bool) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil, false
+	}
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil, false
+	}
+	return keychain{}, true
+}
+
+// Name implementation.
+func (keychain) Name() string {
+	return "Secret Service"
+}
+
+// Get implementation.
+func (keychain) Get(host string) (string, error) {
+	out, code, err := runTool("", "secret-tool", "lookup", "service", keychainService, "host", host)
+	if err != nil {
+		return "", err
+	}
+	// secret-tool exits with 1, and prints nothing, for missing items.
+	if code != 0 || out == "" {
+		return "", ErrNoCredential
+	}
+	return out, nil
+}
+
+// Set implementation.
+func (keychain) Set(host, token string) error {
+	// The token is read from stdin so that it isn't visible in ps.
+	_, code, err := runTool(token, "secret-tool", "store", "--label", "Airplane CLI ("+host+")", "service", keychainService, "host", host)
+	if err != nil {
+		return err
+	} else if code != 0 {
+		return errors.Errorf("secret-tool store exited with status %d", code)
+	}
+	return nil
+}
+
+// Delete implementation.
+func (keychain) Delete(host string) error {
+	_, _, err := runTool("", "secret-tool", "clear", "service", keychainService, "host", host)
+	return err
+}
@@ -0,0 +1,10 @@
+//go:build !darwin && !linux && !windows
+// +build !darwin,!linux,!windows
+
+package conf
+
+// osKeychain reports that there's no keychain on this OS, so tokens are kept
+// in the config file.
+func osKeychain() (CredentialStore, bool) {
+	return nil, false
+}
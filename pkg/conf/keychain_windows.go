@@ -0,0 +1,119 @@
+//go:build windows
+// +build windows
+
+package conf
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	advapi32       = syscall.NewLazyDLL("advapi32.dll")
+	procCredReadW  = advapi32.NewProc("CredReadW")
+	procCredWriteW = advapi32.NewProc("CredWriteW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// credential is a CREDENTIALW.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// keychain stores tokens in the Windows Credential Manager, as generic
+// credentials named airplane-cli:<host>.
+type keychain struct{}
+
+func osKeychain() (CredentialStore, bool) {
+	if err := procCredReadW.Find(); err != nil {
+		return nil, false
+	}
+	return keychain{}, true
+}
+
+func target(host string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(keychainService + ":" + host)
+}
+
+// Name implementation.
+func (keychain) Name() string {
+	return "Windows Credential Manager"
+}
+
+// Get implementation.
+func (keychain) Get(host string) (string, error) {
+	name, err := target(host)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	ok, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ok == 0 {
+		if errors.Is(err, errorNotFound) {
+			return "", ErrNoCredential
+		}
+		return "", errors.Wrap(err, "reading credential")
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return string(blob), nil
+}
+
+// Set implementation.
+func (keychain) Set(host, token string) error {
+	name, err := target(host)
+	if err != nil {
+		return err
+	}
+	user, err := syscall.UTF16PtrFromString(host)
+	if err != nil {
+		return err
+	}
+	blob := []byte(token)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         name,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if ok, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); ok == 0 {
+		return errors.Wrap(err, "writing credential")
+	}
+	return nil
+}
+
+// Delete implementation.
+func (keychain) Delete(host string) error {
+	name, err := target(host)
+	if err != nil {
+		return err
+	}
+	if ok, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(name)), credTypeGeneric, 0); ok == 0 && !errors.Is(err, errorNotFound) {
+		return errors.Wrap(err, "deleting credential")
+	}
+	return nil
+}
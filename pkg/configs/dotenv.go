@@ -0,0 +1,131 @@
+package configs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/pkg/errors"
+)
+
+// secretMarker is the comment that marks a dotenv entry as a secret:
+//
+//	DB_URL="postgresql://..." # secret
+const secretMarker = "# secret"
+
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// IsDotenvName reports whether name can be written as a key in a dotenv file.
+func IsDotenvName(name string) bool {
+	return dotenvKey.MatchString(name)
+}
+
+// ParseDotenv parses a dotenv file into a manifest. Each line is a
+// NAME=value pair, optionally prefixed with "export". Values may be wrapped
+// in double quotes, which unescape \n, \" and \\, or in single quotes, which
+// are read as is. A trailing "# secret" comment marks the entry as a secret.
+func ParseDotenv(buf []byte) (Manifest, error) {
+	var m Manifest
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return Manifest{}, errors.Errorf("line %d: expected NAME=value", n)
+		}
+		name := strings.TrimSpace(parts[0])
+		if !IsDotenvName(name) {
+			return Manifest{}, errors.Errorf("line %d: invalid config name %q", n, name)
+		}
+		if seen[name] {
+			return Manifest{}, errors.Errorf("line %d: %s is listed more than once", n, name)
+		}
+		seen[name] = true
+
+		value, comment, err := parseDotenvValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return Manifest{}, errors.Wrapf(err, "line %d", n)
+		}
+		m.Configs = append(m.Configs, ManifestEntry{
+			Name:   name,
+			Value:  &value,
+			Secret: comment == secretMarker,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return Manifest{}, errors.Wrap(err, "reading dotenv")
+	}
+	return m, nil
+}
+
+// parseDotenvValue returns the value at the start of s and the comment that
+// follows it, if any.
+func parseDotenvValue(s string) (value, comment string, err error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch c := s[i]; {
+			case c == '"':
+				return b.String(), strings.TrimSpace(s[i+1:]), nil
+			case c == '\\' && i+1 < len(s):
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(s[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", "", errors.New("unterminated double-quoted value")
+
+	case strings.HasPrefix(s, `'`):
+		end := strings.Index(s[1:], `'`)
+		if end < 0 {
+			return "", "", errors.New("unterminated single-quoted value")
+		}
+		return s[1 : end+1], strings.TrimSpace(s[end+2:]), nil
+
+	default:
+		// Unquoted values end at a comment, which must follow a space.
+		if i := strings.Index(s, " #"); i >= 0 {
+			return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i:]), nil
+		}
+		return s, "", nil
+	}
+}
+
+// FormatDotenv formats configs as a dotenv file, with secrets marked by a
+// "# secret" comment so that ParseDotenv reads them back as secrets.
+func FormatDotenv(cfgs []api.Config) []byte {
+	var buf bytes.Buffer
+	for _, cfg := range cfgs {
+		fmt.Fprintf(&buf, "%s=%s", cfg.Name, quoteDotenv(cfg.Value))
+		if cfg.IsSecret {
+			buf.WriteString(" " + secretMarker)
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func quoteDotenv(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,95 @@
+package configs
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	str := func(s string) *string { return &s }
+	for _, test := range []struct {
+		name     string
+		dotenv   string
+		expected Manifest
+		err      string
+	}{
+		{
+			name: "entries",
+			dotenv: `
+# Production settings
+REGION=us-west-2
+export DB_URL="postgresql://db?a=b" # secret
+GREETING="hello \"world\"\nbye"
+RAW='no \n escapes' # secret
+EMPTY=
+PORT=8080 # the port
+`,
+			expected: Manifest{Configs: []ManifestEntry{
+				{Name: "REGION", Value: str("us-west-2")},
+				{Name: "DB_URL", Value: str("postgresql://db?a=b"), Secret: true},
+				{Name: "GREETING", Value: str("hello \"world\"\nbye")},
+				{Name: "RAW", Value: str(`no \n escapes`), Secret: true},
+				{Name: "EMPTY", Value: str("")},
+				{Name: "PORT", Value: str("8080")},
+			}},
+		},
+		{
+			name:     "empty",
+			dotenv:   "",
+			expected: Manifest{},
+		},
+		{
+			name:   "missing value",
+			dotenv: "A\n",
+			err:    "line 1: expected NAME=value",
+		},
+		{
+			name:   "invalid name",
+			dotenv: "\nA-B=1\n",
+			err:    `line 2: invalid config name "A-B"`,
+		},
+		{
+			name:   "unterminated",
+			dotenv: `A="x`,
+			err:    "line 1: unterminated double-quoted value",
+		},
+		{
+			name:   "duplicate",
+			dotenv: "A=1\nA=2\n",
+			err:    "line 2: A is listed more than once",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			m, err := ParseDotenv([]byte(test.dotenv))
+			if test.err != "" {
+				require.EqualError(err, test.err)
+				return
+			}
+			require.NoError(err)
+			require.Equal(test.expected, m)
+		})
+	}
+}
+
+func TestFormatDotenv(t *testing.T) {
+	require := require.New(t)
+	cfgs := []api.Config{
+		{Name: "DB_URL", Value: "postgresql://db", IsSecret: true},
+		{Name: "GREETING", Value: "hello \"world\"\nbye"},
+	}
+
+	buf := FormatDotenv(cfgs)
+	require.Equal("DB_URL=\"postgresql://db\" # secret\nGREETING=\"hello \\\"world\\\"\\nbye\"\n", string(buf))
+
+	m, err := ParseDotenv(buf)
+	require.NoError(err)
+	require.Len(m.Configs, 2)
+	for i, e := range m.Configs {
+		require.Equal(cfgs[i].Name, e.Name)
+		require.Equal(cfgs[i].Value, *e.Value)
+		require.Equal(cfgs[i].IsSecret, e.Secret)
+	}
+}
@@ -0,0 +1,109 @@
+package configs
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a file of config variables to set in bulk:
+//
+//	configs:
+//	  - name: db_url
+//	    tag: prod
+//	    secret: true
+//	    fromEnv: PROD_DB_URL
+//	  - name: region:prod
+//	    value: us-west-2
+//
+// Values may be written inline, read from an environment variable so that
+// secrets stay out of the file, or left out to be prompted for.
+type Manifest struct {
+	Configs []ManifestEntry `yaml:"configs"`
+}
+
+// ManifestEntry is a single config variable in a Manifest. Name may carry a
+// tag as in name:tag, in which case Tag must be empty.
+type ManifestEntry struct {
+	Name    string  `yaml:"name"`
+	Tag     string  `yaml:"tag,omitempty"`
+	Value   *string `yaml:"value,omitempty"`
+	FromEnv string  `yaml:"fromEnv,omitempty"`
+	Secret  bool    `yaml:"secret,omitempty"`
+}
+
+// NameTag returns the name and tag of the entry.
+func (e ManifestEntry) NameTag() NameTag {
+	nt, _ := ParseName(e.Name)
+	if e.Tag != "" {
+		nt.Tag = e.Tag
+	}
+	return nt
+}
+
+// ReadManifest reads and validates the manifest at path.
+func ReadManifest(path string) (Manifest, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Manifest{}, errors.Wrap(err, "reading manifest")
+	}
+	m, err := ParseManifest(buf)
+	if err != nil {
+		return Manifest{}, errors.Wrap(err, path)
+	}
+	return m, nil
+}
+
+// ParseManifest parses and validates a manifest. Unknown fields are errors,
+// so that typos don't silently drop settings.
+func ParseManifest(buf []byte) (Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(bytes.NewReader(buf))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && err != io.EOF {
+		return Manifest{}, errors.Wrap(err, "parsing manifest")
+	}
+
+	seen := map[NameTag]bool{}
+	for i, e := range m.Configs {
+		nt, err := ParseName(e.Name)
+		switch {
+		case e.Name == "":
+			return Manifest{}, errors.Errorf("configs[%d]: name is required", i)
+		case err != nil:
+			return Manifest{}, errors.Errorf("configs[%d]: invalid config name: %s - expected my_config or my_config:tag", i, e.Name)
+		case nt.Tag != "" && e.Tag != "":
+			return Manifest{}, errors.Errorf("configs[%d]: %s already has a tag, so tag must not be set", i, e.Name)
+		case e.Value != nil && e.FromEnv != "":
+			return Manifest{}, errors.Errorf("configs[%d]: only one of value and fromEnv may be set", i)
+		}
+
+		nt = e.NameTag()
+		if seen[nt] {
+			return Manifest{}, errors.Errorf("configs[%d]: %s is listed more than once", i, JoinName(nt))
+		}
+		seen[nt] = true
+	}
+	return m, nil
+}
+
+// ResolveValue returns the value of the entry from the manifest or the
+// environment. It reports false if the value must be prompted for.
+func (e ManifestEntry) ResolveValue() (string, bool, error) {
+	switch {
+	case e.Value != nil:
+		return *e.Value, true, nil
+	case e.FromEnv != "":
+		v, ok := os.LookupEnv(e.FromEnv)
+		if !ok {
+			return "", false, errors.Errorf("%s: environment variable %s is not set", JoinName(e.NameTag()), e.FromEnv)
+		}
+		return v, true, nil
+	default:
+		return "", false, nil
+	}
+}
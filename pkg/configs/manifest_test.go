@@ -0,0 +1,96 @@
+package configs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseManifest(t *testing.T) {
+	value := "us-west-2"
+	for _, test := range []struct {
+		name     string
+		manifest string
+		expected Manifest
+		err      string
+	}{
+		{
+			name: "entries",
+			manifest: `
+configs:
+  - name: db_url
+    tag: prod
+    secret: true
+    fromEnv: PROD_DB_URL
+  - name: region:prod
+    value: us-west-2
+  - name: api_key
+    secret: true
+`,
+			expected: Manifest{Configs: []ManifestEntry{
+				{Name: "db_url", Tag: "prod", Secret: true, FromEnv: "PROD_DB_URL"},
+				{Name: "region:prod", Value: &value},
+				{Name: "api_key", Secret: true},
+			}},
+		},
+		{
+			name:     "empty",
+			manifest: "",
+			expected: Manifest{},
+		},
+		{
+			name:     "unknown field",
+			manifest: "configs:\n  - name: a\n    secrett: true\n",
+			err:      "field secrett not found",
+		},
+		{
+			name:     "missing name",
+			manifest: "configs:\n  - value: a\n",
+			err:      "configs[0]: name is required",
+		},
+		{
+			name:     "two tags",
+			manifest: "configs:\n  - name: a:prod\n    tag: dev\n",
+			err:      "configs[0]: a:prod already has a tag, so tag must not be set",
+		},
+		{
+			name:     "value and env",
+			manifest: "configs:\n  - name: a\n    value: x\n    fromEnv: X\n",
+			err:      "configs[0]: only one of value and fromEnv may be set",
+		},
+		{
+			name:     "duplicate",
+			manifest: "configs:\n  - name: a:prod\n  - name: a\n    tag: prod\n",
+			err:      "configs[1]: a:prod is listed more than once",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			m, err := ParseManifest([]byte(test.manifest))
+			if test.err != "" {
+				require.Error(err)
+				require.Contains(err.Error(), test.err)
+				return
+			}
+			require.NoError(err)
+			require.Equal(test.expected, m)
+		})
+	}
+}
+
+func TestResolveValue(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("AIRPLANE_TEST_CONFIG", "from env")
+
+	v, ok, err := ManifestEntry{Name: "a", FromEnv: "AIRPLANE_TEST_CONFIG"}.ResolveValue()
+	require.NoError(err)
+	require.True(ok)
+	require.Equal("from env", v)
+
+	_, ok, err = ManifestEntry{Name: "a"}.ResolveValue()
+	require.NoError(err)
+	require.False(ok)
+
+	_, _, err = ManifestEntry{Name: "a", FromEnv: "AIRPLANE_TEST_CONFIG_UNSET"}.ResolveValue()
+	require.EqualError(err, "a: environment variable AIRPLANE_TEST_CONFIG_UNSET is not set")
+}
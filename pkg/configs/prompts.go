@@ -13,9 +13,10 @@ import (
 	"github.com/pkg/errors"
 )
 
-// ReadValue reads a config value from prompt if allowed, else stdin
+// ReadValue reads a config value from stdin if it's piped or redirected, else
+// from a prompt.
 func ReadValue(secret bool) (string, error) {
-	if utils.CanPrompt() {
+	if utils.StdinIsTerminal() && utils.CanPrompt() {
 		return ReadValueFromPrompt("Config value:", secret)
 	}
 	// Read from stdin
@@ -36,10 +37,9 @@ func ReadValueFromPrompt(message string, secret bool) (string, error) {
 	} else {
 		prompt = &survey.Input{Message: message}
 	}
-	if err := survey.AskOne(
+	if err := utils.AskOne(
 		prompt,
 		&value,
-		survey.WithStdio(os.Stdin, os.Stderr, os.Stderr),
 	); err != nil {
 		return "", errors.Wrap(err, "prompting value")
 	}
@@ -47,7 +47,7 @@ func ReadValueFromPrompt(message string, secret bool) (string, error) {
 }
 
 // SetConfig writes config value to API and prints progress to user
-func SetConfig(ctx context.Context, client *api.Client, nt NameTag, value string, secret bool) error {
+func SetConfig(ctx context.Context, client api.APIClient, nt NameTag, value string, secret bool) error {
 	// Avoid printing back secrets
 	var valueStr string
 	if secret {
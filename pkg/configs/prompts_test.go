@@ -0,0 +1,26 @@
+package configs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadValueFromStdin(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "value")
+	require.NoError(t, ioutil.WriteFile(path, []byte("  hunter2\n"), 0600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+
+	value, err := ReadValue(true)
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", value)
+}
@@ -0,0 +1,234 @@
+// Package deps works out what tasks depend on, and which tasks are affected
+// by a change to a config, resource or other task.
+//
+// A task's dependencies are those its definition references, such as configs
+// in its env and resources it attaches, plus any it declares explicitly.
+package deps
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+)
+
+// Direct lists what a task depends on directly.
+type Direct struct {
+	Configs   []string `json:"configs,omitempty" yaml:"configs,omitempty"`
+	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Tasks     []string `json:"tasks,omitempty" yaml:"tasks,omitempty"`
+}
+
+// Of returns the direct dependencies of task. Resources attached to the
+// task are listed by name, using resourceNames to map their IDs, and fall
+// back to their alias if they aren't in it.
+func Of(task api.Task, resourceNames map[string]string) Direct {
+	var configs, resources, tasks []string
+	for _, v := range task.Env {
+		if v.Config != nil {
+			configs = append(configs, *v.Config)
+		}
+	}
+	for alias, id := range task.Resources {
+		if name, ok := resourceNames[id]; ok {
+			resources = append(resources, name)
+		} else {
+			resources = append(resources, alias)
+		}
+	}
+	if d := task.Dependencies; d != nil {
+		configs = append(configs, d.Configs...)
+		resources = append(resources, d.Resources...)
+		tasks = append(tasks, d.Tasks...)
+	}
+	return Direct{
+		Configs:   uniq(configs),
+		Resources: uniq(resources),
+		Tasks:     uniq(tasks),
+	}
+}
+
+// ResourceNames maps resource IDs to names. If resources can't be listed,
+// it returns nil and resources are shown by alias.
+func ResourceNames(ctx context.Context, client api.APIClient) map[string]string {
+	res, err := client.ListResources(ctx)
+	if err != nil {
+		logger.Debug("listing resources: %v", err)
+		return nil
+	}
+	names := make(map[string]string, len(res.Resources))
+	for _, r := range res.Resources {
+		names[r.ID] = r.Name
+	}
+	return names
+}
+
+// Node is a task in a dependency graph.
+type Node struct {
+	Slug string `json:"slug" yaml:"slug"`
+	Direct
+	// Dependencies are the nodes of the tasks in Direct.Tasks.
+	Dependencies []*Node `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+	// Cycle is set if the task already appears above this node, in which
+	// case its dependencies are not repeated.
+	Cycle bool `json:"cycle,omitempty" yaml:"cycle,omitempty"`
+	// Missing is set if the task does not exist.
+	Missing bool `json:"missing,omitempty" yaml:"missing,omitempty"`
+}
+
+// GetTaskFunc fetches a task by slug. It returns an *api.TaskMissingError if
+// the task does not exist.
+type GetTaskFunc func(ctx context.Context, slug string) (api.Task, error)
+
+// Graph returns the dependency graph of the task with the given slug,
+// following its task dependencies transitively.
+func Graph(ctx context.Context, getTask GetTaskFunc, resourceNames map[string]string, slug string) (*Node, error) {
+	cache := map[string]api.Task{}
+	fetch := func(slug string) (api.Task, error) {
+		if task, ok := cache[slug]; ok {
+			return task, nil
+		}
+		task, err := getTask(ctx, slug)
+		if err != nil {
+			return api.Task{}, err
+		}
+		cache[slug] = task
+		return task, nil
+	}
+
+	// Unlike its dependencies, the task itself must exist.
+	if _, err := fetch(slug); err != nil {
+		return nil, err
+	}
+
+	var visit func(slug string, path map[string]bool) (*Node, error)
+	visit = func(slug string, path map[string]bool) (*Node, error) {
+		node := &Node{Slug: slug}
+		if path[slug] {
+			node.Cycle = true
+			return node, nil
+		}
+
+		task, err := fetch(slug)
+		if _, missing := err.(*api.TaskMissingError); missing {
+			node.Missing = true
+			return node, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		node.Direct = Of(task, resourceNames)
+		path[slug] = true
+		defer delete(path, slug)
+		for _, dep := range node.Tasks {
+			child, err := visit(dep, path)
+			if err != nil {
+				return nil, err
+			}
+			node.Dependencies = append(node.Dependencies, child)
+		}
+		return node, nil
+	}
+	return visit(slug, map[string]bool{})
+}
+
+// Affected is a task affected by a change.
+type Affected struct {
+	Slug string `json:"slug" yaml:"slug"`
+	Name string `json:"name" yaml:"name"`
+	// Via is the task dependency through which the change reaches this
+	// task, or empty if the task depends on the changed item directly.
+	Via string `json:"via,omitempty" yaml:"via,omitempty"`
+}
+
+// Target identifies what is being changed. Exactly one field must be set.
+type Target struct {
+	// Config is a config name, optionally with a tag. Without a tag it
+	// matches every tag of the config.
+	Config   string
+	Resource string
+	Task     string
+}
+
+// Impact lists the tasks that depend on target, directly or through other
+// tasks, sorted by slug.
+func Impact(tasks []api.Task, resourceNames map[string]string, target Target) []Affected {
+	direct := make(map[string]Direct, len(tasks))
+	for _, t := range tasks {
+		direct[t.Slug] = Of(t, resourceNames)
+	}
+
+	affected := map[string]Affected{}
+	for _, t := range tasks {
+		if matches(direct[t.Slug], target) {
+			affected[t.Slug] = Affected{Slug: t.Slug, Name: t.Name}
+		}
+	}
+
+	// Spread the change to tasks that depend on affected tasks, until
+	// nothing new is found.
+	for changed := true; changed; {
+		changed = false
+		for _, t := range tasks {
+			if _, ok := affected[t.Slug]; ok {
+				continue
+			}
+			for _, dep := range direct[t.Slug].Tasks {
+				if _, ok := affected[dep]; ok {
+					affected[t.Slug] = Affected{Slug: t.Slug, Name: t.Name, Via: dep}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	res := make([]Affected, 0, len(affected))
+	for _, a := range affected {
+		res = append(res, a)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Slug < res[j].Slug })
+	return res
+}
+
+func matches(d Direct, target Target) bool {
+	switch {
+	case target.Config != "":
+		for _, c := range d.Configs {
+			if c == target.Config || (!strings.Contains(target.Config, ":") && strings.HasPrefix(c, target.Config+":")) {
+				return true
+			}
+		}
+	case target.Resource != "":
+		return contains(d.Resources, target.Resource)
+	case target.Task != "":
+		return contains(d.Tasks, target.Task)
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// uniq sorts list and removes duplicates from it.
+func uniq(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	sort.Strings(list)
+	res := list[:1]
+	for _, s := range list[1:] {
+		if s != res[len(res)-1] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
@@ -0,0 +1,132 @@
+package deps
+
+import (
+	"context"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func str(s string) *string { return &s }
+
+var testTasks = []api.Task{
+	{
+		Slug: "report",
+		Name: "Report",
+		Env: api.TaskEnv{
+			"DB_URL": {Config: str("db_url:prod")},
+			"MODE":   {Value: str("fast")},
+		},
+		Resources:    api.Resources{"db": "res1"},
+		Dependencies: &api.Dependencies{Tasks: []string{"fetch"}},
+	},
+	{
+		Slug:         "fetch",
+		Name:         "Fetch",
+		Dependencies: &api.Dependencies{Configs: []string{"api_key"}, Tasks: []string{"report", "gone"}},
+	},
+	{
+		Slug:         "digest",
+		Name:         "Digest",
+		Dependencies: &api.Dependencies{Tasks: []string{"report"}},
+	},
+	{
+		Slug: "other",
+		Name: "Other",
+		Env:  api.TaskEnv{"DB_URL": {Config: str("db_url_v2")}},
+	},
+}
+
+var resourceNames = map[string]string{"res1": "warehouse"}
+
+func getTask(ctx context.Context, slug string) (api.Task, error) {
+	for _, t := range testTasks {
+		if t.Slug == slug {
+			return t, nil
+		}
+	}
+	return api.Task{}, &api.TaskMissingError{}
+}
+
+func TestOf(t *testing.T) {
+	require.Equal(t, Direct{
+		Configs:   []string{"db_url:prod"},
+		Resources: []string{"warehouse"},
+		Tasks:     []string{"fetch"},
+	}, Of(testTasks[0], resourceNames))
+
+	require.Equal(t, Direct{Resources: []string{"db"}}, Of(api.Task{Resources: api.Resources{"db": "res2"}}, resourceNames))
+}
+
+func TestGraph(t *testing.T) {
+	require := require.New(t)
+
+	node, err := Graph(context.Background(), getTask, resourceNames, "digest")
+	require.NoError(err)
+	require.Equal(&Node{
+		Slug:   "digest",
+		Direct: Direct{Tasks: []string{"report"}},
+		Dependencies: []*Node{{
+			Slug:   "report",
+			Direct: Of(testTasks[0], resourceNames),
+			Dependencies: []*Node{{
+				Slug:   "fetch",
+				Direct: Of(testTasks[1], resourceNames),
+				Dependencies: []*Node{
+					{Slug: "gone", Missing: true},
+					{Slug: "report", Cycle: true},
+				},
+			}},
+		}},
+	}, node)
+
+	_, err = Graph(context.Background(), getTask, resourceNames, "gone")
+	require.Error(err)
+}
+
+func TestImpact(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		target   Target
+		expected []Affected
+	}{
+		{
+			name:   "config without tag",
+			target: Target{Config: "db_url"},
+			expected: []Affected{
+				{Slug: "digest", Name: "Digest", Via: "report"},
+				{Slug: "fetch", Name: "Fetch", Via: "report"},
+				{Slug: "report", Name: "Report"},
+			},
+		},
+		{
+			name:     "config with other tag",
+			target:   Target{Config: "db_url:dev"},
+			expected: []Affected{},
+		},
+		{
+			name:   "explicit config",
+			target: Target{Config: "api_key"},
+			expected: []Affected{
+				{Slug: "digest", Name: "Digest", Via: "report"},
+				{Slug: "fetch", Name: "Fetch"},
+				{Slug: "report", Name: "Report", Via: "fetch"},
+			},
+		},
+		{
+			name:     "resource",
+			target:   Target{Resource: "warehouse"},
+			expected: []Affected{{Slug: "digest", Name: "Digest", Via: "report"}, {Slug: "fetch", Name: "Fetch", Via: "report"}, {Slug: "report", Name: "Report"}},
+		},
+		{
+			name:     "task",
+			target:   Target{Task: "gone"},
+			expected: []Affected{{Slug: "digest", Name: "Digest", Via: "report"}, {Slug: "fetch", Name: "Fetch"}, {Slug: "report", Name: "Report", Via: "fetch"}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, Impact(testTasks, resourceNames, test.target))
+		})
+	}
+}
@@ -1,18 +1,46 @@
 package logger
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/go-retryablehttp"
 )
 
 // HTTPLogger is a wrapper around the default debug logger that
 // can be used for HTTP requests via `hashicorp/go-retryablehttp`.
-type HTTPLogger struct{}
+//
+// Failed requests and retries are logged at the info level, since the
+// backoff between retries otherwise looks like the CLI has stalled.
+type HTTPLogger struct {
+	// MaxRetries is the client's retryablehttp.Client.RetryMax. If set,
+	// retries are logged with their attempt number.
+	MaxRetries int
+}
 
 var _ retryablehttp.LeveledLogger = HTTPLogger{}
 
+// lastFailure is the error of the last request that failed without a
+// response. retryablehttp logs the error and the retry separately, so it is
+// kept until the retry is logged.
+var lastFailure struct {
+	sync.Mutex
+	request string
+	err     interface{}
+}
+
 func (_ HTTPLogger) Error(msg string, keyAndValues ...interface{}) {
+	if msg == "request failed" {
+		kv := toMap(keyAndValues)
+		lastFailure.Lock()
+		// Matches the description of the request in "retrying request".
+		lastFailure.request = fmt.Sprintf("%s %s", kv["method"], kv["url"])
+		lastFailure.err = kv["error"]
+		lastFailure.Unlock()
+		Debug("%s failed: %v", lastFailure.request, kv["error"])
+		return
+	}
 	format, args := toFormatAndArgs(msg, keyAndValues)
 	Debug(format, args...)
 }
@@ -22,7 +50,11 @@ func (_ HTTPLogger) Info(msg string, keyAndValues ...interface{}) {
 	Debug(format, args...)
 }
 
-func (_ HTTPLogger) Debug(msg string, keyAndValues ...interface{}) {
+func (l HTTPLogger) Debug(msg string, keyAndValues ...interface{}) {
+	if msg == "retrying request" {
+		Log(Gray(l.retryMessage(toMap(keyAndValues))))
+		return
+	}
 	format, args := toFormatAndArgs(msg, keyAndValues)
 	Debug(format, args...)
 }
@@ -32,6 +64,38 @@ func (_ HTTPLogger) Warn(msg string, keyAndValues ...interface{}) {
 	Debug(format, args...)
 }
 
+// retryMessage describes a retry from the key-values of "retrying request".
+// The request's description already includes its status code, if it got a
+// response; otherwise, the error of the failed request is added.
+func (l HTTPLogger) retryMessage(kv map[string]interface{}) string {
+	desc := fmt.Sprint(kv["request"])
+	lastFailure.Lock()
+	if lastFailure.request == desc {
+		desc = fmt.Sprintf("%s (error: %v)", desc, lastFailure.err)
+		lastFailure.request, lastFailure.err = "", nil
+	}
+	lastFailure.Unlock()
+
+	remaining, _ := kv["remaining"].(int)
+	if l.MaxRetries > 0 && remaining > 0 {
+		// retryablehttp makes MaxRetries+1 attempts, and remaining counts
+		// the one about to be made.
+		attempt := l.MaxRetries - remaining + 2
+		return fmt.Sprintf("%s failed, retrying in %v (attempt %d of %d)...", desc, kv["timeout"], attempt, l.MaxRetries+1)
+	}
+	return fmt.Sprintf("%s failed, retrying in %v (%v retries left)...", desc, kv["timeout"], kv["remaining"])
+}
+
+func toMap(keyAndValues []interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(keyAndValues)/2)
+	for i := 1; i < len(keyAndValues); i += 2 {
+		if k, ok := keyAndValues[i-1].(string); ok {
+			m[k] = keyAndValues[i]
+		}
+	}
+	return m
+}
+
 func toFormatAndArgs(msg string, keyAndValues []interface{}) (string, []interface{}) {
 	var keys []string
 	var args []interface{}
@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryMessage(t *testing.T) {
+	u, err := url.Parse("https://api.airplane.dev/v0/tasks/get")
+	require.NoError(t, err)
+	l := HTTPLogger{MaxRetries: 5}
+
+	// The status code is part of the request's description.
+	msg := l.retryMessage(map[string]interface{}{
+		"request":   "GET https://api.airplane.dev/v0/tasks/get (status: 503)",
+		"timeout":   100 * time.Millisecond,
+		"remaining": 5,
+	})
+	require.Equal(t, "GET https://api.airplane.dev/v0/tasks/get (status: 503) failed, retrying in 100ms (attempt 2 of 6)...", msg)
+
+	// Requests without a response are described by their error.
+	l.Error("request failed", "error", errors.New("connection refused"), "method", "GET", "url", u)
+	msg = l.retryMessage(map[string]interface{}{
+		"request":   "GET https://api.airplane.dev/v0/tasks/get",
+		"timeout":   time.Second,
+		"remaining": 1,
+	})
+	require.Equal(t, "GET https://api.airplane.dev/v0/tasks/get (error: connection refused) failed, retrying in 1s (attempt 6 of 6)...", msg)
+
+	// Errors are only used once, by the request that failed.
+	l.Error("request failed", "error", errors.New("connection refused"), "method", "POST", "url", u)
+	msg = HTTPLogger{}.retryMessage(map[string]interface{}{
+		"request":   "GET https://api.airplane.dev/v0/tasks/get",
+		"timeout":   time.Second,
+		"remaining": 3,
+	})
+	require.Equal(t, "GET https://api.airplane.dev/v0/tasks/get failed, retrying in 1s (3 retries left)...", msg)
+}
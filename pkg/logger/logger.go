@@ -8,14 +8,33 @@ import (
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
 	"golang.org/x/term"
 )
 
 var (
 	// EnableDebug determines if debug logs are emitted.
 	EnableDebug bool
+
+	// plain suppresses decorative output: banners, steps, suggestions,
+	// spinners and colors. See SetPlain.
+	plain bool
 )
 
+// SetPlain turns plain output on or off. In plain mode, only essential lines
+// are written, so that output can be parsed by scripts.
+func SetPlain(enabled bool) {
+	plain = enabled
+	if enabled {
+		color.NoColor = true
+	}
+}
+
+// IsPlain reports whether plain output is on.
+func IsPlain() bool {
+	return plain
+}
+
 type Logger interface {
 	Log(msg string, args ...interface{})
 	Warning(msg string, args ...interface{})
@@ -52,32 +71,40 @@ func (l *StdErrLogger) Suggest(title, command string, args ...interface{}) {
 // Log writes a log message to stderr, followed by a newline. Printf-style
 // formatting is applied to msg using args.
 func Log(msg string, args ...interface{}) {
-	if len(args) == 0 {
-		// Use Fprint if no args - avoids treating msg like a format string
-		fmt.Fprint(os.Stderr, msg+"\n")
-	} else {
-		fmt.Fprintf(os.Stderr, msg+"\n", args...)
+	if len(args) > 0 {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	// Use Fprint - avoids treating msg like a format string
+	fmt.Fprint(os.Stderr, Redacted(msg)+"\n")
+}
+
+// Banner writes contextual output, such as a task's URL or the run that was
+// queued, that is omitted in plain mode.
+func Banner(msg string, args ...interface{}) {
+	if plain {
+		return
 	}
+	Log(msg, args...)
 }
 
 // Step prints a step that was performed.
 func Step(msg string, args ...interface{}) {
-	Log("- "+msg, args...)
+	Banner("- "+msg, args...)
 }
 
 // Suggest suggests a command with title and args.
 func Suggest(title, command string, args ...interface{}) {
-	Log("\n"+Gray(title)+"\n  "+command, args...)
+	Banner("\n"+Gray(title)+"\n  "+command, args...)
 }
 
 // Error logs an error message.
 func Error(msg string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, Red("Error: ")+msg+"\n", args...)
+	fmt.Fprint(os.Stderr, Redacted(fmt.Sprintf(Red("Error: ")+msg+"\n", args...)))
 }
 
 // Warning logs a warning message.
 func Warning(msg string, args ...interface{}) {
-	fmt.Fprint(os.Stderr, Yellow("[warning] "+msg+"\n", args...))
+	fmt.Fprint(os.Stderr, Redacted(Yellow("[warning] "+msg+"\n", args...)))
 }
 
 // Debug writes a log message to stderr, followed by a newline, if the CLI
@@ -92,6 +119,7 @@ func Debug(msg string, args ...interface{}) {
 	if len(args) > 0 {
 		msgf = fmt.Sprintf(msg, args...)
 	}
+	msgf = Redacted(msgf)
 
 	debugPrefix := "[" + Blue("debug") + "] "
 	msgf = debugPrefix + strings.Join(strings.Split(msgf, "\n"), "\n"+debugPrefix)
@@ -120,7 +148,7 @@ type LoaderOpts struct {
 }
 
 func NewLoader(opts LoaderOpts) Loader {
-	if opts.HideLoader || !term.IsTerminal(int(os.Stderr.Fd())) {
+	if opts.HideLoader || plain || !term.IsTerminal(int(os.Stderr.Fd())) {
 		return &NoopLoader{}
 	}
 	return &SpinnerLoader{
@@ -128,12 +156,26 @@ func NewLoader(opts LoaderOpts) Loader {
 	}
 }
 
+// activeLoaders are the spinners that have been started and not stopped, so
+// that they can be paused while the user is prompted.
+var activeLoaders struct {
+	sync.Mutex
+	set map[*SpinnerLoader]bool
+}
+
 // Start starts a new loader. The loader should be stopped
 // before writing additional output to stderr.
 func (sp *SpinnerLoader) Start() {
 	sp.Lock()
 	defer sp.Unlock()
 	sp.spin.Start()
+
+	activeLoaders.Lock()
+	defer activeLoaders.Unlock()
+	if activeLoaders.set == nil {
+		activeLoaders.set = map[*SpinnerLoader]bool{}
+	}
+	activeLoaders.set[sp] = true
 }
 
 // Stop stops the loader and removes it from stderr.
@@ -143,6 +185,30 @@ func (sp *SpinnerLoader) Stop() {
 	sp.spin.Stop()
 	// Remove the spinner!
 	fmt.Fprint(os.Stderr, "\r \r")
+
+	activeLoaders.Lock()
+	defer activeLoaders.Unlock()
+	delete(activeLoaders.set, sp)
+}
+
+// PauseLoaders stops the active spinners, such as while the user is prompted,
+// and returns a function that restarts them.
+func PauseLoaders() (resume func()) {
+	activeLoaders.Lock()
+	var paused []*SpinnerLoader
+	for sp := range activeLoaders.set {
+		paused = append(paused, sp)
+	}
+	activeLoaders.Unlock()
+
+	for _, sp := range paused {
+		sp.Stop()
+	}
+	return func() {
+		for _, sp := range paused {
+			sp.Start()
+		}
+	}
 }
 
 // Returns whether the spinner is active.
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseLoaders(t *testing.T) {
+	require := require.New(t)
+	newSpinner := func() *SpinnerLoader {
+		return &SpinnerLoader{
+			spin: spinner.New(spinner.CharSets[11], 100*time.Millisecond, spinner.WithWriter(ioutil.Discard)),
+		}
+	}
+	running, stopped := newSpinner(), newSpinner()
+	running.Start()
+	defer running.Stop()
+	stopped.Start()
+	stopped.Stop()
+
+	resume := PauseLoaders()
+	require.False(running.IsActive())
+	require.False(stopped.IsActive())
+
+	// Only the spinners that were running are restarted.
+	resume()
+	require.True(running.IsActive())
+	require.False(stopped.IsActive())
+}
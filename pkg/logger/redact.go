@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// minRedactedLen is the shortest value that is redacted. Shorter values, such
+// as "1" or "true", would mask unrelated output.
+const minRedactedLen = 6
+
+var redactor struct {
+	sync.RWMutex
+	values map[string]bool
+	list   []string
+}
+
+// Redact registers sensitive values, such as tokens and secret config values,
+// that are masked in everything written by the logger from then on.
+func Redact(values ...string) {
+	redactor.Lock()
+	defer redactor.Unlock()
+
+	if redactor.values == nil {
+		redactor.values = map[string]bool{}
+	}
+	for _, v := range values {
+		if len(v) >= minRedactedLen && !redactor.values[v] {
+			redactor.values[v] = true
+			redactor.list = append(redactor.list, v)
+		}
+	}
+}
+
+// Redacted returns s with every value registered with Redact masked. Values
+// that overlap, or that contain one another, are masked as one.
+func Redacted(s string) string {
+	redactor.RLock()
+	defer redactor.RUnlock()
+	if len(redactor.list) == 0 {
+		return s
+	}
+
+	// masked[i] is set if byte i of s is part of a value.
+	var masked []bool
+	for _, v := range redactor.list {
+		for i := 0; i+len(v) <= len(s); {
+			j := strings.Index(s[i:], v)
+			if j < 0 {
+				break
+			}
+			if masked == nil {
+				masked = make([]bool, len(s))
+			}
+			for k := i + j; k < i+j+len(v); k++ {
+				masked[k] = true
+			}
+			i += j + 1
+		}
+	}
+	if masked == nil {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if !masked[i] {
+			b.WriteByte(s[i])
+			continue
+		}
+		b.WriteString("[redacted]")
+		for i+1 < len(s) && masked[i+1] {
+			i++
+		}
+	}
+	return b.String()
+}
+
+// NewRedactWriter returns a writer that masks every value registered with
+// Redact in what is written to w. Output is passed through a line at a time,
+// so that values split across writes are masked too: a trailing partial line
+// is held until it is completed or the writer is closed.
+func NewRedactWriter(w io.Writer) io.WriteCloser {
+	return &redactWriter{w: w}
+}
+
+type redactWriter struct {
+	w   io.Writer
+	buf []byte
+}
+
+func (rw *redactWriter) Write(p []byte) (int, error) {
+	rw.buf = append(rw.buf, p...)
+	// Carriage returns end the lines of progress bars.
+	i := bytes.LastIndexAny(rw.buf, "\r\n")
+	if i < 0 {
+		return len(p), nil
+	}
+	if _, err := io.WriteString(rw.w, Redacted(string(rw.buf[:i+1]))); err != nil {
+		return 0, err
+	}
+	rw.buf = append(rw.buf[:0], rw.buf[i+1:]...)
+	return len(p), nil
+}
+
+// Close writes the held partial line, if any.
+func (rw *redactWriter) Close() error {
+	if len(rw.buf) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(rw.w, Redacted(string(rw.buf)))
+	rw.buf = nil
+	return err
+}
@@ -0,0 +1,108 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// resetRedact forgets the values registered with Redact, before and after t.
+func resetRedact(t *testing.T) {
+	reset := func() {
+		redactor.Lock()
+		defer redactor.Unlock()
+		redactor.values, redactor.list = nil, nil
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestRedacted(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		secrets []string
+		in      string
+		out     string
+	}{
+		{
+			name: "nothing registered",
+			in:   "token=abcdef123",
+			out:  "token=abcdef123",
+		},
+		{
+			name:    "every occurrence",
+			secrets: []string{"abcdef123"},
+			in:      "token=abcdef123, again abcdef123",
+			out:     "token=[redacted], again [redacted]",
+		},
+		{
+			name:    "short secrets",
+			secrets: []string{"true", "12345", "hunter2"},
+			in:      "debug=true retries=12345 password=hunter2",
+			out:     "debug=true retries=12345 password=[redacted]",
+		},
+		{
+			name:    "one secret contains another",
+			secrets: []string{"secret", "secret-token"},
+			in:      "secret-token and secret",
+			out:     "[redacted] and [redacted]",
+		},
+		{
+			name:    "overlapping secrets",
+			secrets: []string{"abc123", "123xyz"},
+			in:      "abc123xyz",
+			out:     "[redacted]",
+		},
+		{
+			name:    "adjacent secrets",
+			secrets: []string{"abc123", "def456"},
+			in:      "abc123def456",
+			out:     "[redacted]",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resetRedact(t)
+			Redact(test.secrets...)
+			require.Equal(t, test.out, Redacted(test.in))
+		})
+	}
+}
+
+func TestRedactIncremental(t *testing.T) {
+	resetRedact(t)
+
+	// Values registered later are masked too.
+	Redact("first-secret")
+	require.Equal(t, "[redacted] second-secret", Redacted("first-secret second-secret"))
+	Redact("second-secret", "", "first-secret")
+	require.Equal(t, "[redacted] [redacted]", Redacted("first-secret second-secret"))
+}
+
+func TestRedactWriter(t *testing.T) {
+	resetRedact(t)
+	Redact("abcdef123")
+
+	var buf bytes.Buffer
+	w := NewRedactWriter(&buf)
+
+	// A secret split across writes is held until its line is complete.
+	for _, s := range []string{"token=abc", "def", "123\nnext"} {
+		_, err := w.Write([]byte(s))
+		require.NoError(t, err)
+	}
+	require.Equal(t, "token=[redacted]\n", buf.String())
+
+	_, err := w.Write([]byte(" abcdef"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("123\rprogress abcdef"))
+	require.NoError(t, err)
+	require.Equal(t, "token=[redacted]\nnext [redacted]\r", buf.String())
+
+	// Closing writes the rest, masked.
+	_, err = w.Write([]byte("123"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.Equal(t, "token=[redacted]\nnext [redacted]\rprogress [redacted]", buf.String())
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,154 @@
+// Package logsink copies run logs to durable destinations, such as a file,
+// syslog or a webhook, while they are streamed to the terminal.
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+)
+
+// Usage documents the accepted sink specs, for use in flag descriptions.
+const Usage = "file:<path>, syslog[:<tag>] or webhook:<url>"
+
+// Open opens the sink described by spec, which is one of:
+//
+//	file:<path>     appends logs to the file at path
+//	syslog[:<tag>]  sends logs to the local syslog daemon
+//	webhook:<url>   POSTs each batch of logs to url as plain text
+func Open(spec string) (io.WriteCloser, error) {
+	kind, arg := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		kind, arg = spec[:i], spec[i+1:]
+	}
+
+	switch kind {
+	case "file":
+		if arg == "" {
+			return nil, errors.New("file log sink requires a path: file:<path>")
+		}
+		f, err := os.OpenFile(arg, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, errors.Wrap(err, "opening log file")
+		}
+		return f, nil
+	case "syslog":
+		if arg == "" {
+			arg = "airplane"
+		}
+		return openSyslog(arg)
+	case "webhook":
+		if !strings.HasPrefix(arg, "http://") && !strings.HasPrefix(arg, "https://") {
+			return nil, errors.Errorf("webhook log sink requires an http(s) URL, got %q", arg)
+		}
+		return &webhook{url: arg, client: &http.Client{Timeout: 10 * time.Second}}, nil
+	default:
+		return nil, errors.Errorf("unknown log sink %q: expected %s", spec, Usage)
+	}
+}
+
+// Fanout writes to every sink it was opened with. A sink that fails is
+// reported once and then skipped, so that an unavailable destination never
+// interrupts the run being watched.
+type Fanout struct {
+	sinks []sink
+}
+
+type sink struct {
+	spec   string
+	w      io.WriteCloser
+	failed bool
+}
+
+// OpenAll opens a Fanout to every spec. If any sink can't be opened, the
+// ones opened so far are closed.
+func OpenAll(specs []string) (*Fanout, error) {
+	f := &Fanout{}
+	for _, spec := range specs {
+		w, err := Open(spec)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.sinks = append(f.sinks, sink{spec: spec, w: w})
+	}
+	return f, nil
+}
+
+// WriteLogs writes logs to every sink, one line per log.
+func (f *Fanout) WriteLogs(logs []api.LogItem) {
+	if f == nil || len(f.sinks) == 0 || len(logs) == 0 {
+		return
+	}
+
+	var b bytes.Buffer
+	for _, l := range logs {
+		fmt.Fprintf(&b, "%s %s\n", l.Timestamp.UTC().Format(time.RFC3339Nano), l.Text)
+	}
+	f.Write(b.Bytes())
+}
+
+// Write implements io.Writer. It never returns an error.
+func (f *Fanout) Write(p []byte) (int, error) {
+	for i := range f.sinks {
+		s := &f.sinks[i]
+		if s.failed {
+			continue
+		}
+		if _, err := s.w.Write(p); err != nil {
+			s.failed = true
+			logger.Warning("Writing logs to %s failed, disabling it: %v", s.spec, err)
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes every sink.
+func (f *Fanout) Close() error {
+	if f == nil {
+		return nil
+	}
+	var first error
+	for _, s := range f.sinks {
+		if err := s.w.Close(); err != nil && first == nil {
+			first = errors.Wrapf(err, "closing log sink %s", s.spec)
+		}
+	}
+	return first
+}
+
+// webhook POSTs every write to a URL.
+type webhook struct {
+	url    string
+	client *http.Client
+}
+
+func (w *webhook) Write(p []byte) (int, error) {
+	req, err := http.NewRequestWithContext(context.Background(), "POST", w.url, bytes.NewReader(p))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return 0, errors.Errorf("webhook responded with %s", resp.Status)
+	}
+	return len(p), nil
+}
+
+func (w *webhook) Close() error {
+	return nil
+}
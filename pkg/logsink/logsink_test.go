@@ -0,0 +1,57 @@
+package logsink
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	for _, spec := range []string{"file:", "webhook:example.com", "s3:bucket", "nope"} {
+		_, err := Open(spec)
+		require.Error(t, err, spec)
+	}
+}
+
+func TestFanout(t *testing.T) {
+	require := require.New(t)
+
+	var posted int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "run.log")
+	f, err := OpenAll([]string{"file:" + path, "webhook:" + srv.URL})
+	require.NoError(err)
+
+	ts := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	f.WriteLogs([]api.LogItem{{Timestamp: ts, Text: "one"}, {Timestamp: ts, Text: "two"}})
+	f.WriteLogs([]api.LogItem{{Timestamp: ts, Text: "three"}})
+	require.NoError(f.Close())
+
+	buf, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Equal("2021-01-02T03:04:05Z one\n2021-01-02T03:04:05Z two\n2021-01-02T03:04:05Z three\n", string(buf))
+	// The failing webhook is only tried once.
+	require.Equal(1, posted)
+}
+
+func TestFanoutCloseError(t *testing.T) {
+	f := &Fanout{sinks: []sink{{spec: "test", w: failCloser{}}}}
+	require.EqualError(t, f.Close(), "closing log sink test: boom")
+}
+
+type failCloser struct{}
+
+func (failCloser) Write(p []byte) (int, error) { return len(p), nil }
+func (failCloser) Close() error                { return errors.New("boom") }
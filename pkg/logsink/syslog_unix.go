@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package logsink
+
+import (
+	"io"
+	"log/syslog"
+
+	"github.com/pkg/errors"
+)
+
+func openSyslog(tag string) (io.WriteCloser, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to syslog")
+	}
+	return w, nil
+}
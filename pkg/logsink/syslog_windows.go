@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+package logsink
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// openSyslog fails, since syslog isn't available on Windows.
+func openSyslog(tag string) (io.WriteCloser, error) {
+	return nil, errors.New("the syslog log sink is not supported on Windows")
+}
@@ -0,0 +1,288 @@
+// Package outputhook reshapes run outputs before they are printed, such as
+// by writing a rows output to a CSV file or handing outputs to a local
+// script.
+//
+// Hooks are described by specs of the form <kind>[:<arg>]. The built-in
+// kinds are csv and exec, and others can be added with Register.
+package outputhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/ojson"
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+)
+
+// Usage documents the built-in hook specs, for use in flag descriptions.
+const Usage = "csv:<path> or exec:<command>"
+
+// Hook reshapes a run's outputs. It returns the outputs to print, which may
+// be the outputs it was given.
+type Hook interface {
+	Transform(ctx context.Context, outputs api.Outputs) (api.Outputs, error)
+}
+
+// Factory creates a hook from the argument of its spec, which is empty if
+// the spec has none.
+type Factory func(arg string) (Hook, error)
+
+var factories = map[string]Factory{
+	"csv":  newCSV,
+	"exec": newExec,
+}
+
+// Register makes a kind of hook available to specs. It replaces any hook
+// of the same kind, including built-in ones.
+func Register(kind string, f Factory) {
+	factories[kind] = f
+}
+
+// RunsCommands reports whether the hook described by spec runs a local
+// command. Such hooks should only come from flags or the user's config: a
+// definition checked into a repo must not run commands on the machine of
+// whoever executes its task without their consent.
+func RunsCommands(spec string) bool {
+	kind, _ := split(spec)
+	return kind == "exec"
+}
+
+// split splits spec into its kind and argument.
+func split(spec string) (kind, arg string) {
+	if i := strings.Index(spec, ":"); i >= 0 {
+		return spec[:i], spec[i+1:]
+	}
+	return spec, ""
+}
+
+// CheckUntrusted reports an error if the hook described by spec may write
+// outside of the working directory, such as a csv hook with an absolute path.
+// Specs from a definition must pass it, so that a definition checked into a
+// repo can't overwrite files elsewhere, such as in the user's home directory.
+func CheckUntrusted(spec string) error {
+	kind, arg := split(spec)
+	if kind != "csv" {
+		return nil
+	}
+	clean := filepath.Clean(arg)
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return errors.Errorf("csv output hook path %q must be within the working directory", arg)
+	}
+	return nil
+}
+
+// Open opens the hook described by spec, which is one of:
+//
+//	csv:<path>      writes the run's rows output to a CSV file
+//	exec:<command>  pipes the outputs through a local command, whose
+//	                arguments are split like a shell's
+//
+// or a kind added with Register.
+func Open(spec string) (Hook, error) {
+	kind, arg := split(spec)
+	f, ok := factories[kind]
+	if !ok {
+		return nil, errors.Errorf("unknown output hook %q: expected %s", spec, Usage)
+	}
+	return f(arg)
+}
+
+// Chain applies hooks in order.
+type Chain struct {
+	specs []string
+	hooks []Hook
+}
+
+// OpenAll opens a Chain of every spec.
+func OpenAll(specs []string) (Chain, error) {
+	var c Chain
+	for _, spec := range specs {
+		h, err := Open(spec)
+		if err != nil {
+			return Chain{}, err
+		}
+		c.specs = append(c.specs, spec)
+		c.hooks = append(c.hooks, h)
+	}
+	return c, nil
+}
+
+// Transform passes outputs through every hook in turn. If a hook fails, it
+// returns the outputs as they were before that hook, along with the error.
+func (c Chain) Transform(ctx context.Context, outputs api.Outputs) (api.Outputs, error) {
+	for i, h := range c.hooks {
+		res, err := h.Transform(ctx, outputs)
+		if err != nil {
+			return outputs, errors.Wrapf(err, "output hook %s", c.specs[i])
+		}
+		outputs = res
+	}
+	return outputs, nil
+}
+
+// csvHook writes rows to a file and leaves the outputs unchanged.
+type csvHook struct {
+	path string
+}
+
+func newCSV(arg string) (Hook, error) {
+	if arg == "" {
+		return nil, errors.New("csv output hook requires a path: csv:<path>")
+	}
+	return csvHook{path: filepath.Clean(arg)}, nil
+}
+
+func (h csvHook) Transform(ctx context.Context, outputs api.Outputs) (api.Outputs, error) {
+	rows, ok := findRows(ojson.Value(outputs).V)
+	if !ok {
+		logger.Warning("No rows output to write to %s", h.path)
+		return outputs, nil
+	}
+
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for _, key := range row.KeyOrder() {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+
+	f, err := os.Create(h.path)
+	if err != nil {
+		return api.Outputs{}, errors.Wrap(err, "creating csv file")
+	}
+	w := csv.NewWriter(f)
+	if err := w.Write(columns); err != nil {
+		f.Close()
+		return api.Outputs{}, errors.Wrap(err, "writing csv")
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, key := range columns {
+			v, _ := row.Get(key)
+			record[i] = cell(v)
+		}
+		if err := w.Write(record); err != nil {
+			f.Close()
+			return api.Outputs{}, errors.Wrap(err, "writing csv")
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return api.Outputs{}, errors.Wrap(err, "writing csv")
+	}
+	if err := f.Close(); err != nil {
+		return api.Outputs{}, errors.Wrap(err, "closing csv file")
+	}
+	logger.Log("Wrote %d rows to %s", len(rows), h.path)
+	return outputs, nil
+}
+
+// findRows returns the first output that is a list of objects: either the
+// outputs themselves, or the first such named output.
+func findRows(v interface{}) ([]*ojson.Object, bool) {
+	switch t := v.(type) {
+	case []interface{}:
+		return toRows(t)
+	case *ojson.Object:
+		for _, key := range t.KeyOrder() {
+			child, _ := t.Get(key)
+			if list, ok := child.([]interface{}); ok {
+				if rows, ok := toRows(list); ok {
+					return rows, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+func toRows(list []interface{}) ([]*ojson.Object, bool) {
+	if len(list) == 0 {
+		return nil, false
+	}
+	rows := make([]*ojson.Object, 0, len(list))
+	for _, v := range list {
+		o, ok := v.(*ojson.Object)
+		if !ok {
+			return nil, false
+		}
+		rows = append(rows, o)
+	}
+	return rows, true
+}
+
+func cell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		buf, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(buf)
+	}
+}
+
+// execHook runs a command with the outputs as JSON on its stdin. If the
+// command prints anything, it must be JSON, which replaces the outputs.
+// The command's stderr is passed through.
+type execHook struct {
+	args []string
+}
+
+func newExec(arg string) (Hook, error) {
+	args, err := shellquote.Split(arg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing exec output hook command %q", arg)
+	}
+	if len(args) == 0 {
+		return nil, errors.New("exec output hook requires a command: exec:<command>")
+	}
+	return execHook{args: args}, nil
+}
+
+func (h execHook) Transform(ctx context.Context, outputs api.Outputs) (api.Outputs, error) {
+	in, err := json.Marshal(outputs)
+	if err != nil {
+		return api.Outputs{}, errors.Wrap(err, "encoding outputs")
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.args[0], h.args[1:]...)
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return api.Outputs{}, errors.Wrapf(err, "running %s", h.args[0])
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return outputs, nil
+	}
+	var res api.Outputs
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		return api.Outputs{}, errors.Wrapf(err, "%s printed invalid JSON", h.args[0])
+	}
+	return res, nil
+}
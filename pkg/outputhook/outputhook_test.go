@@ -0,0 +1,126 @@
+package outputhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/ojson"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	for _, spec := range []string{"csv:", "exec:", "exec: ", `exec:jq "unterminated`, "xlsx:out.xlsx", "nope"} {
+		_, err := Open(spec)
+		require.Error(t, err, spec)
+	}
+	// Explicit hooks may write anywhere.
+	for _, spec := range []string{"csv:/tmp/rows.csv", "csv:../rows.csv"} {
+		_, err := Open(spec)
+		require.NoError(t, err, spec)
+	}
+}
+
+func TestCheckUntrusted(t *testing.T) {
+	for _, spec := range []string{"csv:rows.csv", "csv:out/rows.csv", "exec:jq ."} {
+		require.NoError(t, CheckUntrusted(spec), spec)
+	}
+	for _, spec := range []string{"csv:/tmp/rows.csv", "csv:../rows.csv", "csv:a/../../rows.csv", "csv:.."} {
+		require.Error(t, CheckUntrusted(spec), spec)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	require := require.New(t)
+
+	wd, err := os.Getwd()
+	require.NoError(err)
+	dir := t.TempDir()
+	require.NoError(os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	c, err := OpenAll([]string{"csv:rows.csv"})
+	require.NoError(err)
+
+	in := outputs(t, `{"count":2,"rows":[{"id":1,"name":"a, b"},{"id":2,"tags":["x"]}]}`)
+	out, err := c.Transform(context.Background(), in)
+	require.NoError(err)
+	require.Equal(in, out)
+
+	buf, err := ioutil.ReadFile(filepath.Join(dir, "rows.csv"))
+	require.NoError(err)
+	require.Equal("id,name,tags\n1,\"a, b\",\n2,,\"[\"\"x\"\"]\"\n", string(buf))
+}
+
+func TestRunsCommands(t *testing.T) {
+	require.True(t, RunsCommands("exec:jq ."))
+	require.False(t, RunsCommands("csv:rows.csv"))
+	require.False(t, RunsCommands("execute"))
+}
+
+func TestExec(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat is not available")
+	}
+	require := require.New(t)
+
+	c, err := OpenAll([]string{"exec:cat"})
+	require.NoError(err)
+
+	in := outputs(t, `{"output":["hello"]}`)
+	out, err := c.Transform(context.Background(), in)
+	require.NoError(err)
+	require.Equal(`{"output":["hello"]}`, marshal(t, out))
+}
+
+func TestExecQuoting(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	require := require.New(t)
+
+	c, err := OpenAll([]string{`exec:sh -c 'echo "{\"n\": 1}"'`})
+	require.NoError(err)
+
+	out, err := c.Transform(context.Background(), outputs(t, `{}`))
+	require.NoError(err)
+	require.Equal(`{"n":1}`, marshal(t, out))
+}
+
+func TestRegister(t *testing.T) {
+	require := require.New(t)
+
+	Register("test", func(arg string) (Hook, error) {
+		return replace(arg), nil
+	})
+	defer delete(factories, "test")
+
+	c, err := OpenAll([]string{"test:1", "test:2"})
+	require.NoError(err)
+	out, err := c.Transform(context.Background(), outputs(t, `{}`))
+	require.NoError(err)
+	require.Equal(`"2"`, marshal(t, out))
+}
+
+type replace string
+
+func (r replace) Transform(ctx context.Context, outputs api.Outputs) (api.Outputs, error) {
+	return api.Outputs(ojson.Value{V: string(r)}), nil
+}
+
+func outputs(t *testing.T, s string) api.Outputs {
+	var o api.Outputs
+	require.NoError(t, json.Unmarshal([]byte(s), &o))
+	return o
+}
+
+func marshal(t *testing.T, o api.Outputs) string {
+	buf, err := json.Marshal(o)
+	require.NoError(t, err)
+	return string(buf)
+}
@@ -0,0 +1,101 @@
+package params
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/params/validate"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadFile reads parameter values for the task from a JSON or YAML file that
+// maps parameter slugs to values, such as:
+//
+//	region: us-east-1
+//	dry_run: false
+//	ids: [1, 2, 3]
+//
+// Values may be written natively or as they would be passed as flags, so both
+// `count: 3` and `count: "3"` work. They are validated like flags: unknown
+// slugs are an error, and all invalid values are reported together in a
+// ValidationError.
+func ReadFile(task api.Task, path string) (api.Values, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading parameter file")
+	}
+	// JSON is valid YAML, so one decoder reads both.
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(buf, &raw); err != nil {
+		return nil, errors.Wrapf(err, "parsing parameter file %s", path)
+	}
+
+	bySlug := make(map[string]api.Parameter, len(task.Parameters))
+	for _, p := range task.Parameters {
+		bySlug[p.Slug] = p
+	}
+	slugs := make([]string, 0, len(raw))
+	for slug := range raw {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	values := api.Values{}
+	var verr ValidationError
+	for _, slug := range slugs {
+		param, ok := bySlug[slug]
+		if !ok {
+			return nil, errors.Errorf("%s: unknown parameter %q", path, slug)
+		}
+		v, err := validate.Native(param, raw[slug])
+		if err != nil {
+			verr.Invalid = append(verr.Invalid, InvalidValue{Param: param, Err: err})
+			continue
+		}
+		if v != nil {
+			values[slug] = v
+		}
+	}
+	if len(verr.Invalid) > 0 {
+		return nil, verr
+	}
+	return values, nil
+}
+
+// Merge returns the values in base overridden by those in values.
+func Merge(base, values api.Values) api.Values {
+	merged := make(api.Values, len(base)+len(values))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+	return merged
+}
+
+// LogValues logs the values a run will be given, one per line in the order
+// the task defines its parameters.
+func LogValues(task api.Task, values api.Values) {
+	logger.Log("Parameters:")
+	for _, p := range task.Parameters {
+		v, ok := values[p.Slug]
+		if !ok {
+			continue
+		}
+		var text string
+		if s, ok := v.(string); ok {
+			text = s
+		} else if buf, err := json.Marshal(v); err == nil {
+			text = string(buf)
+		} else {
+			text = fmt.Sprint(v)
+		}
+		logger.Log("  %s: %s", p.Slug, text)
+	}
+}
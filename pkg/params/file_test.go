@@ -0,0 +1,73 @@
+package params
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFile(t *testing.T) {
+	task := api.Task{
+		Parameters: api.Parameters{
+			{Slug: "name", Type: api.TypeString},
+			{Slug: "count", Type: api.TypeInteger},
+			{Slug: "dry_run", Type: api.TypeBoolean},
+			{Slug: "ids", Type: api.TypeInteger, Multi: true, Constraints: api.Constraints{Optional: true}},
+			{Slug: "account", Type: api.TypeString, Constraints: api.Constraints{Optional: true}},
+		},
+	}
+	write := func(t *testing.T, name, content string) string {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		values, err := ReadFile(task, write(t, "params.yaml", "name: World\ncount: \"3\"\ndry_run: yes\nids: [1, 2]\n"))
+		require.NoError(t, err)
+		require.Equal(t, api.Values{
+			"name":    "World",
+			"count":   3,
+			"dry_run": true,
+			"ids":     []interface{}{1, 2},
+		}, values)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		values, err := ReadFile(task, write(t, "params.json", `{"name": "World", "count": 3, "ids": 4}`))
+		require.NoError(t, err)
+		require.Equal(t, api.Values{"name": "World", "count": 3, "ids": []interface{}{4}}, values)
+	})
+
+	t.Run("large integers", func(t *testing.T) {
+		// YAML decodes integers too large for an int64 as a uint64.
+		values, err := ReadFile(task, write(t, "params.yaml", "account: 18446744073709551615\n"))
+		require.NoError(t, err)
+		require.Equal(t, api.Values{"account": "18446744073709551615"}, values)
+	})
+
+	t.Run("unknown parameter", func(t *testing.T) {
+		_, err := ReadFile(task, write(t, "params.yaml", "nmae: World\n"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unknown parameter "nmae"`)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := ReadFile(task, write(t, "params.yaml", "count: many\nname: [a, b]\n"))
+		require.EqualError(t, err, "invalid value for --count: invalid integer; invalid value for --name: expected a single value, not a list")
+	})
+
+	t.Run("flags override", func(t *testing.T) {
+		values, err := ReadFile(task, write(t, "params.yaml", "name: World\ncount: 3\ndry_run: true\n"))
+		require.NoError(t, err)
+		values, err = CLIWithValues([]string{"--count", "5"}, nil, task, values)
+		require.NoError(t, err)
+		require.Equal(t, api.Values{"name": "World", "count": 5, "dry_run": true}, values)
+
+		_, err = CLIWithValues(nil, nil, task, api.Values{"name": "World"})
+		require.EqualError(t, err, "missing required parameters: --count, --dry_run")
+	})
+}
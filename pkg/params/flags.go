@@ -0,0 +1,216 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/cli/pkg/params/validate"
+	"github.com/pkg/errors"
+)
+
+// ErrHelp is returned when -h or --help is passed as a parameter flag,
+// after usage has been printed.
+var ErrHelp = errors.New("params: help requested")
+
+// ParseFlags parses args as flags for the task's parameters.
+//
+// Flags may be written as --slug value or --slug=value, with one or two
+// leading dashes. Boolean flags take no separate value: --slug sets true and
+// --no-slug sets false, though --slug=false works too. Any other flag always
+// consumes the following argument, so values may begin with a dash, such as
+// negative numbers. Flags for multi-value parameters may be repeated.
+//
+// Invalid values don't stop parsing: they are all reported together in a
+// ValidationError once every flag has been read.
+func ParseFlags(task api.Task, args []string) (api.Values, error) {
+	bySlug := make(map[string]api.Parameter, len(task.Parameters))
+	for _, p := range task.Parameters {
+		bySlug[p.Slug] = p
+	}
+
+	values := api.Values{}
+	var verr ValidationError
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" {
+			if i+1 < len(args) {
+				return nil, errors.Errorf("unexpected argument: %q", args[i+1])
+			}
+			break
+		}
+		if arg == "-h" || arg == "-help" || arg == "--help" {
+			PrintUsage(task)
+			return nil, ErrHelp
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return nil, errors.Errorf("unexpected argument: %q", arg)
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		var value string
+		var hasValue bool
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+
+		param, ok := bySlug[name]
+		var negated bool
+		if !ok && strings.HasPrefix(name, "no-") {
+			if p, found := bySlug[strings.TrimPrefix(name, "no-")]; found && p.Type == api.TypeBoolean {
+				param, ok, negated = p, true, true
+			}
+		}
+		if !ok {
+			return nil, errors.Errorf("unknown flag: --%s", name)
+		}
+
+		switch {
+		case negated:
+			if hasValue {
+				return nil, errors.Errorf("flag --no-%s does not take a value", param.Slug)
+			}
+			value = "false"
+		case param.Type == api.TypeBoolean:
+			if !hasValue {
+				value = "true"
+			}
+		case !hasValue:
+			if i+1 >= len(args) {
+				return nil, errors.Errorf("flag needs a value: --%s", param.Slug)
+			}
+			i++
+			value = args[i]
+		}
+
+		v, err := validate.Parse(param, value)
+		if err != nil {
+			verr.Invalid = append(verr.Invalid, InvalidValue{Param: param, Err: err})
+			continue
+		}
+
+		if param.Multi {
+			list, _ := values[param.Slug].([]interface{})
+			values[param.Slug] = append(list, v)
+		} else if _, dup := values[param.Slug]; dup {
+			return nil, errors.Errorf("flag --%s may only be specified once", param.Slug)
+		} else {
+			values[param.Slug] = v
+		}
+	}
+
+	if len(verr.Invalid) > 0 {
+		return values, verr
+	}
+	return values, nil
+}
+
+// ParamHelp describes how to pass a parameter as a flag.
+type ParamHelp struct {
+	Slug        string   `json:"slug" yaml:"slug"`
+	Name        string   `json:"name" yaml:"name"`
+	Type        api.Type `json:"type" yaml:"type"`
+	Flag        string   `json:"flag" yaml:"flag"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	Required    bool     `json:"required" yaml:"required"`
+	// Default is the parameter's default, as it would be written as a flag.
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+	// Multi parameters can be passed more than once.
+	Multi   bool     `json:"multi,omitempty" yaml:"multi,omitempty"`
+	Regex   string   `json:"regex,omitempty" yaml:"regex,omitempty"`
+	Options []string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// Describe returns help for each of the task's parameters, in the order
+// they are defined.
+func Describe(task api.Task) []ParamHelp {
+	res := make([]ParamHelp, 0, len(task.Parameters))
+	for _, p := range task.Parameters {
+		h := ParamHelp{
+			Slug:        p.Slug,
+			Name:        p.Name,
+			Type:        p.Type,
+			Description: p.Desc,
+			Required:    !p.Constraints.Optional,
+			Multi:       p.Multi,
+			Regex:       p.Constraints.Regex,
+		}
+		if options := validate.OptionNames(p); len(options) > 0 {
+			h.Options = options
+		}
+		if p.Default != nil {
+			if dv, err := APIValueToInput(p, p.Default); err == nil {
+				h.Default = dv
+			}
+		}
+		switch {
+		case p.Type == api.TypeBoolean:
+			h.Flag = fmt.Sprintf("--[no-]%s", p.Slug)
+		case len(h.Options) > 0:
+			h.Flag = fmt.Sprintf("--%s <%s>", p.Slug, strings.Join(h.Options, "|"))
+		default:
+			h.Flag = fmt.Sprintf("--%s <%s>", p.Slug, p.Type)
+		}
+		res = append(res, h)
+	}
+	return res
+}
+
+// PrintUsage prints how to pass the task's parameters as flags, with
+// required parameters listed first.
+func PrintUsage(task api.Task) {
+	var required, optional []ParamHelp
+	for _, h := range Describe(task) {
+		if h.Required {
+			required = append(required, h)
+		} else {
+			optional = append(optional, h)
+		}
+	}
+
+	logger.Log("\n%s Usage:", task.Name)
+	if len(task.Parameters) == 0 {
+		logger.Log("\nThis task has no parameters.")
+	}
+	for _, group := range []struct {
+		title  string
+		params []ParamHelp
+	}{
+		{"Required", required},
+		{"Optional", optional},
+	} {
+		if len(group.params) == 0 {
+			continue
+		}
+		logger.Log("\n%s:", group.title)
+		for _, h := range group.params {
+			logger.Log("  %s", flagUsage(h))
+		}
+	}
+	logger.Log("")
+}
+
+func flagUsage(h ParamHelp) string {
+	var b strings.Builder
+	b.WriteString(h.Flag)
+	if h.Description != "" {
+		fmt.Fprintf(&b, "  %s", h.Description)
+	}
+
+	var notes []string
+	if h.Default != "" {
+		notes = append(notes, fmt.Sprintf("default: %s", h.Default))
+	}
+	if h.Regex != "" {
+		notes = append(notes, fmt.Sprintf("must match: %s", h.Regex))
+	}
+	if h.Multi {
+		notes = append(notes, "repeatable")
+	}
+	if len(notes) > 0 {
+		b.WriteString(logger.Gray(" (%s)", strings.Join(notes, ", ")))
+	}
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package params
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFlags(t *testing.T) {
+	task := api.Task{
+		Name: "Test",
+		Parameters: api.Parameters{
+			{Slug: "name", Type: api.TypeString},
+			{Slug: "count", Type: api.TypeInteger},
+			{Slug: "dry", Type: api.TypeBoolean},
+			{Slug: "tag", Type: api.TypeString, Multi: true},
+			{Slug: "env", Type: api.TypeEnum, Constraints: api.Constraints{Options: []api.ConstraintOption{
+				{Label: "Production", Value: "prod"},
+				{Value: "staging"},
+			}}},
+		},
+	}
+
+	for _, test := range []struct {
+		name     string
+		args     []string
+		expected api.Values
+		err      string
+	}{
+		{
+			name:     "separate value",
+			args:     []string{"--name", "foo"},
+			expected: api.Values{"name": "foo"},
+		},
+		{
+			name:     "equals value",
+			args:     []string{"--name=foo=bar"},
+			expected: api.Values{"name": "foo=bar"},
+		},
+		{
+			name:     "single dash",
+			args:     []string{"-name", "foo"},
+			expected: api.Values{"name": "foo"},
+		},
+		{
+			name:     "negative number",
+			args:     []string{"--count", "-5"},
+			expected: api.Values{"count": -5},
+		},
+		{
+			name:     "value starting with dash",
+			args:     []string{"--name", "--dry"},
+			expected: api.Values{"name": "--dry"},
+		},
+		{
+			name:     "bool",
+			args:     []string{"--dry"},
+			expected: api.Values{"dry": true},
+		},
+		{
+			name:     "negated bool",
+			args:     []string{"--no-dry"},
+			expected: api.Values{"dry": false},
+		},
+		{
+			name:     "bool with value",
+			args:     []string{"--dry=no"},
+			expected: api.Values{"dry": false},
+		},
+		{
+			name:     "repeated multi",
+			args:     []string{"--tag", "a", "--tag=b"},
+			expected: api.Values{"tag": []interface{}{"a", "b"}},
+		},
+		{
+			name:     "trailing separator",
+			args:     []string{"--name", "foo", "--"},
+			expected: api.Values{"name": "foo"},
+		},
+		{
+			name:     "enum value",
+			args:     []string{"--env", "staging"},
+			expected: api.Values{"env": "staging"},
+		},
+		{
+			name:     "enum label",
+			args:     []string{"--env=Production"},
+			expected: api.Values{"env": "prod"},
+		},
+		{
+			name: "enum invalid",
+			args: []string{"--env", "dev"},
+			err:  "invalid value for --env: expected one of: Production, staging",
+		},
+		{
+			name: "repeated single",
+			args: []string{"--name", "a", "--name", "b"},
+			err:  "flag --name may only be specified once",
+		},
+		{
+			name: "unknown",
+			args: []string{"--nope"},
+			err:  "unknown flag: --nope",
+		},
+		{
+			name: "negated non-bool",
+			args: []string{"--no-name"},
+			err:  "unknown flag: --no-name",
+		},
+		{
+			name: "missing value",
+			args: []string{"--count"},
+			err:  "flag needs a value: --count",
+		},
+		{
+			name: "invalid value",
+			args: []string{"--count", "ten", "--dry=maybe", "--name", "foo"},
+			err:  "invalid value for --count: invalid integer; invalid value for --dry: expected yes, no, true, false, 1 or 0",
+		},
+		{
+			name: "positional",
+			args: []string{"foo"},
+			err:  `unexpected argument: "foo"`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			values, err := ParseFlags(task, test.args)
+			if test.err != "" {
+				require.EqualError(err, test.err)
+				return
+			}
+			require.NoError(err)
+			require.Equal(test.expected, values)
+		})
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	var assert = require.New(t)
+	task := api.Task{
+		Parameters: api.Parameters{
+			{Slug: "name", Name: "Name", Type: api.TypeString, Desc: "Who to greet.", Default: "World", Constraints: api.Constraints{Regex: "^[A-Z]"}},
+			{Slug: "dry", Name: "Dry run", Type: api.TypeBoolean, Constraints: api.Constraints{Optional: true}},
+			{Slug: "env", Name: "Env", Type: api.TypeEnum, Constraints: api.Constraints{Options: []api.ConstraintOption{
+				{Label: "Production", Value: "prod"},
+				{Value: "staging"},
+			}}},
+		},
+	}
+
+	help := Describe(task)
+	assert.Equal([]ParamHelp{
+		{Slug: "name", Name: "Name", Type: api.TypeString, Flag: "--name <string>", Description: "Who to greet.", Required: true, Default: "World", Regex: "^[A-Z]"},
+		{Slug: "dry", Name: "Dry run", Type: api.TypeBoolean, Flag: "--[no-]dry"},
+		{Slug: "env", Name: "Env", Type: api.TypeEnum, Flag: "--env <Production|staging>", Required: true, Options: []string{"Production", "staging"}},
+	}, help)
+}
@@ -0,0 +1,101 @@
+package params
+
+import (
+	"fmt"
+
+	"github.com/airplanedev/cli/pkg/api"
+)
+
+// formField is a parameter to prompt for, in form order.
+type formField struct {
+	param api.Parameter
+	// group is the title of the group this field starts, if any.
+	group string
+}
+
+// formFields returns the task's parameters in the order given by its form.
+//
+// Grouped parameters come first, in group order, followed by any ungrouped
+// parameters in definition order. Unknown or repeated slugs are ignored.
+func formFields(task api.Task) []formField {
+	if task.Form == nil {
+		fields := make([]formField, len(task.Parameters))
+		for i, p := range task.Parameters {
+			fields[i] = formField{param: p}
+		}
+		return fields
+	}
+
+	bySlug := make(map[string]api.Parameter, len(task.Parameters))
+	for _, p := range task.Parameters {
+		bySlug[p.Slug] = p
+	}
+
+	var fields []formField
+	seen := make(map[string]bool, len(task.Parameters))
+	for _, g := range task.Form.Groups {
+		title := g.Title
+		for _, slug := range g.Parameters {
+			p, ok := bySlug[slug]
+			if !ok || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			fields = append(fields, formField{param: p, group: title})
+			// Only the first field of a group carries its title.
+			title = ""
+		}
+	}
+	for _, p := range task.Parameters {
+		if !seen[p.Slug] {
+			fields = append(fields, formField{param: p})
+		}
+	}
+
+	return fields
+}
+
+// isVisible reports whether param should be prompted for, given the values
+// entered so far. A parameter is shown only if every condition that targets
+// it is satisfied; conditions on parameters that have not been answered yet
+// are treated as unsatisfied.
+func isVisible(task api.Task, param api.Parameter, values api.Values) bool {
+	if task.Form == nil {
+		return true
+	}
+
+	for _, c := range task.Form.Conditions {
+		if c.Parameter != param.Slug {
+			continue
+		}
+
+		when, ok := findParam(task.Parameters, c.When)
+		if !ok {
+			continue
+		}
+		v, ok := values[c.When]
+		if !ok {
+			if when.Default == nil {
+				return false
+			}
+			v = when.Default
+		}
+		want, err := ParseInput(when, c.Equals)
+		// Compare formatted values: defaults decoded from JSON are float64,
+		// while integers parsed from input are ints.
+		if err != nil || fmt.Sprint(v) != fmt.Sprint(want) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func findParam(params api.Parameters, slug string) (api.Parameter, bool) {
+	for _, p := range params {
+		if p.Slug == slug {
+			return p, true
+		}
+	}
+	return api.Parameter{}, false
+}
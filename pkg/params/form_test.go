@@ -0,0 +1,71 @@
+package params
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormFields(t *testing.T) {
+	require := require.New(t)
+
+	task := api.Task{
+		Parameters: api.Parameters{
+			{Slug: "name", Type: api.TypeString},
+			{Slug: "backend", Type: api.TypeString},
+			{Slug: "bucket", Type: api.TypeString},
+			{Slug: "dry", Type: api.TypeBoolean},
+		},
+		Form: &api.Form{
+			Groups: []api.FormGroup{
+				{Title: "Storage", Parameters: []string{"backend", "bucket", "missing"}},
+			},
+		},
+	}
+
+	var slugs, groups []string
+	for _, f := range formFields(task) {
+		slugs = append(slugs, f.param.Slug)
+		groups = append(groups, f.group)
+	}
+	require.Equal([]string{"backend", "bucket", "name", "dry"}, slugs)
+	require.Equal([]string{"Storage", "", "", ""}, groups)
+}
+
+func TestIsVisible(t *testing.T) {
+	task := api.Task{
+		Parameters: api.Parameters{
+			{Slug: "backend", Type: api.TypeString},
+			{Slug: "bucket", Type: api.TypeString},
+			{Slug: "retries", Type: api.TypeInteger, Default: float64(3)},
+			{Slug: "backoff", Type: api.TypeString},
+		},
+		Form: &api.Form{
+			Conditions: []api.FormCondition{
+				{Parameter: "bucket", When: "backend", Equals: "s3"},
+				{Parameter: "backoff", When: "retries", Equals: "3"},
+			},
+		},
+	}
+	bucket := task.Parameters[1]
+	backoff := task.Parameters[3]
+
+	for _, test := range []struct {
+		name     string
+		param    api.Parameter
+		values   api.Values
+		expected bool
+	}{
+		{"unanswered", bucket, api.Values{}, false},
+		{"matching", bucket, api.Values{"backend": "s3"}, true},
+		{"not matching", bucket, api.Values{"backend": "gcs"}, false},
+		{"default", backoff, api.Values{}, true},
+		{"parsed integer", backoff, api.Values{"retries": 3}, true},
+		{"unconditional", task.Parameters[0], api.Values{}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, isVisible(task, test.param, test.values))
+		})
+	}
+}
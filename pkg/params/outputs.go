@@ -0,0 +1,96 @@
+package params
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/params/validate"
+	"github.com/pkg/errors"
+)
+
+// ReadOutputs reads parameter values for the task from the outputs of another
+// run, as printed to r by `airplane execute -o json`, so that runs can be
+// chained without a workflow:
+//
+//	airplane execute extract -o json | airplane execute load --params-from-stdin --map rows=records
+//
+// Outputs named after one of the task's parameters are passed to it, and
+// mapping, of parameter slugs to output names, passes the others. Outputs
+// that aren't passed to a parameter are ignored. Lists and objects passed to
+// a single string parameter, such as a table of rows, are passed as JSON.
+func ReadOutputs(task api.Task, r io.Reader, mapping map[string]string) (api.Values, error) {
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading outputs")
+	}
+	var outputs map[string]interface{}
+	if err := json.Unmarshal(buf, &outputs); err != nil || outputs == nil {
+		return nil, errors.New("expected the outputs of a run, as printed by airplane execute -o json: a JSON object of named outputs")
+	}
+
+	bySlug := make(map[string]api.Parameter, len(task.Parameters))
+	for _, p := range task.Parameters {
+		bySlug[p.Slug] = p
+	}
+	// sources maps parameter slugs to the outputs they're read from.
+	sources := map[string]string{}
+	for _, p := range task.Parameters {
+		if _, ok := outputs[p.Slug]; ok {
+			sources[p.Slug] = p.Slug
+		}
+	}
+	for slug, output := range mapping {
+		if _, ok := bySlug[slug]; !ok {
+			return nil, errors.Errorf("--map %s=%s: unknown parameter %q", slug, output, slug)
+		}
+		if _, ok := outputs[output]; !ok {
+			names := make([]string, 0, len(outputs))
+			for name := range outputs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, errors.Errorf("--map %s=%s: no output named %q, expected one of: %s", slug, output, output, strings.Join(names, ", "))
+		}
+		sources[slug] = output
+	}
+
+	values := api.Values{}
+	var verr ValidationError
+	for _, p := range task.Parameters {
+		output, ok := sources[p.Slug]
+		if !ok {
+			continue
+		}
+		v, err := validate.Native(p, outputValue(p, outputs[output]))
+		if err != nil {
+			verr.Invalid = append(verr.Invalid, InvalidValue{Param: p, Err: err})
+			continue
+		}
+		if v != nil {
+			values[p.Slug] = v
+		}
+	}
+	if len(verr.Invalid) > 0 {
+		return nil, verr
+	}
+	return values, nil
+}
+
+// outputValue returns an output as it's passed to param: lists and objects
+// are passed to single string parameters as JSON.
+func outputValue(param api.Parameter, v interface{}) interface{} {
+	if param.Type != api.TypeString || param.Multi {
+		return v
+	}
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		if buf, err := json.Marshal(v); err == nil {
+			return string(buf)
+		}
+	}
+	return v
+}
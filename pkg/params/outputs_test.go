@@ -0,0 +1,59 @@
+package params
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOutputs(t *testing.T) {
+	task := api.Task{
+		Parameters: api.Parameters{
+			{Slug: "rows", Type: api.TypeString},
+			{Slug: "count", Type: api.TypeInteger},
+			{Slug: "ids", Type: api.TypeInteger, Multi: true, Constraints: api.Constraints{Optional: true}},
+		},
+	}
+	const outputs = `{"records": [{"id": 1}, {"id": 2}], "count": 2, "ids": [1, 2], "extra": true}`
+
+	t.Run("by name and mapping", func(t *testing.T) {
+		values, err := ReadOutputs(task, strings.NewReader(outputs), map[string]string{"rows": "records"})
+		require.NoError(t, err)
+		require.Equal(t, api.Values{
+			"rows":  `[{"id":1},{"id":2}]`,
+			"count": 2,
+			"ids":   []interface{}{1, 2},
+		}, values)
+	})
+
+	t.Run("mapping overrides names", func(t *testing.T) {
+		values, err := ReadOutputs(task, strings.NewReader(`{"count": 2, "total": 5}`), map[string]string{"count": "total"})
+		require.NoError(t, err)
+		require.Equal(t, api.Values{"count": 5}, values)
+	})
+
+	t.Run("unknown parameter", func(t *testing.T) {
+		_, err := ReadOutputs(task, strings.NewReader(outputs), map[string]string{"rwos": "records"})
+		require.EqualError(t, err, `--map rwos=records: unknown parameter "rwos"`)
+	})
+
+	t.Run("unknown output", func(t *testing.T) {
+		_, err := ReadOutputs(task, strings.NewReader(outputs), map[string]string{"rows": "recs"})
+		require.EqualError(t, err, `--map rows=recs: no output named "recs", expected one of: count, extra, ids, records`)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := ReadOutputs(task, strings.NewReader(`{"count": "many"}`), nil)
+		require.EqualError(t, err, "invalid value for --count: invalid integer")
+	})
+
+	t.Run("not outputs", func(t *testing.T) {
+		for _, in := range []string{"", "[1, 2]", "Executing task..."} {
+			_, err := ReadOutputs(task, strings.NewReader(in), nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "expected the outputs of a run")
+		}
+	})
+}
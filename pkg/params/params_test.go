@@ -0,0 +1,38 @@
+package params
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInputDatetime(t *testing.T) {
+	defer func(l *time.Location) { utils.Location = l }(utils.Location)
+	utils.Location = time.FixedZone("PDT", -7*60*60)
+	param := api.Parameter{Slug: "at", Type: api.TypeDatetime}
+
+	for _, test := range []struct {
+		in       string
+		expected string
+	}{
+		{in: "2021-04-16T01:30:59Z", expected: "2021-04-16T01:30:59Z"},
+		{in: "2021-04-16T01:30:59+02:00", expected: "2021-04-15T23:30:59Z"},
+		{in: "2021-04-16T01:30", expected: "2021-04-16T08:30:00Z"},
+	} {
+		t.Run(test.in, func(t *testing.T) {
+			require.NoError(t, ValidateInput(param, test.in))
+			v, err := ParseInput(param, test.in)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, v)
+		})
+	}
+
+	require.Error(t, ValidateInput(param, "yesterday"))
+
+	in, err := APIValueToInput(param, "2021-04-16T08:30:00Z")
+	require.NoError(t, err)
+	require.Equal(t, "2021-04-16T01:30:00-07:00", in)
+}
@@ -0,0 +1,109 @@
+package params
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/params/validate"
+)
+
+// InvalidValue is a parameter value that failed validation.
+type InvalidValue struct {
+	Param api.Parameter
+	Err   error
+}
+
+// ValidationError reports every parameter that is missing a required value
+// or was given an invalid one, so that they can all be fixed at once.
+type ValidationError struct {
+	Missing []api.Parameter
+	Invalid []InvalidValue
+}
+
+// Error implementation.
+func (err ValidationError) Error() string {
+	var parts []string
+	if len(err.Missing) > 0 {
+		var flags []string
+		for _, p := range err.Missing {
+			flags = append(flags, "--"+p.Slug)
+		}
+		parts = append(parts, fmt.Sprintf("missing required parameters: %s", strings.Join(flags, ", ")))
+	}
+	for _, iv := range err.Invalid {
+		parts = append(parts, fmt.Sprintf("invalid value for --%s: %s", iv.Param.Slug, iv.Err))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ExplainError implementation.
+func (err ValidationError) ExplainError() string {
+	var b strings.Builder
+	if len(err.Missing) > 0 {
+		b.WriteString("Pass the missing parameters as flags:\n")
+		for _, p := range err.Missing {
+			fmt.Fprintf(&b, "  --%s <%s>", p.Slug, p.Type)
+			if p.Desc != "" {
+				fmt.Fprintf(&b, "  %s", p.Desc)
+			}
+			b.WriteString("\n")
+		}
+	}
+	for _, iv := range err.Invalid {
+		if iv.Param.Type == "" {
+			fmt.Fprintf(&b, "--%s is not a parameter of this task\n", iv.Param.Slug)
+			continue
+		}
+		fmt.Fprintf(&b, "--%s <%s>: %s\n", iv.Param.Slug, iv.Param.Type, iv.Err)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// Preflight checks that every required parameter of the task has a value,
+// either in values or as a default, and that every value is valid for its
+// parameter. Parameters hidden by the task's form are not required.
+//
+// It's used when values can't be prompted for, so that users learn about
+// every missing and invalid parameter at once rather than one API error at a
+// time.
+func Preflight(task api.Task, values api.Values) error {
+	verr := ValidationError{
+		Missing: missingParams(task, values),
+		Invalid: invalidValues(task, validate.Values(task.Parameters, values)),
+	}
+	if len(verr.Missing) > 0 || len(verr.Invalid) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// invalidValues attaches the task's parameters to validation errors.
+func invalidValues(task api.Task, errs validate.Errors) []InvalidValue {
+	var invalid []InvalidValue
+	for _, e := range errs {
+		param, ok := findParam(task.Parameters, e.Slug)
+		if !ok {
+			param = api.Parameter{Slug: e.Slug}
+		}
+		invalid = append(invalid, InvalidValue{Param: param, Err: e.Err})
+	}
+	return invalid
+}
+
+func missingParams(task api.Task, values api.Values) []api.Parameter {
+	var missing []api.Parameter
+	for _, field := range formFields(task) {
+		p := field.param
+		if p.Constraints.Optional || p.Default != nil {
+			continue
+		}
+		if !isVisible(task, p, values) {
+			continue
+		}
+		if v, ok := values[p.Slug]; !ok || v == nil || v == "" {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,58 @@
+package params
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflight(t *testing.T) {
+	require := require.New(t)
+
+	task := api.Task{
+		Parameters: api.Parameters{
+			{Slug: "name", Type: api.TypeString},
+			{Slug: "count", Type: api.TypeInteger},
+			{Slug: "region", Type: api.TypeString, Default: "us"},
+			{Slug: "note", Type: api.TypeString, Constraints: api.Constraints{Optional: true}},
+			{Slug: "reason", Type: api.TypeString},
+		},
+		Form: &api.Form{
+			Conditions: []api.FormCondition{
+				{Parameter: "reason", When: "region", Equals: "eu"},
+			},
+		},
+	}
+
+	err := Preflight(task, api.Values{"name": ""})
+	require.EqualError(err, "missing required parameters: --name, --count")
+
+	err = Preflight(task, api.Values{"name": "foo", "count": 1, "region": "eu"})
+	require.EqualError(err, "missing required parameters: --reason")
+
+	require.NoError(Preflight(task, api.Values{"name": "foo", "count": 1}))
+}
+
+func TestPreflightInvalid(t *testing.T) {
+	require := require.New(t)
+
+	task := api.Task{
+		Parameters: api.Parameters{
+			{Slug: "name", Type: api.TypeString, Constraints: api.Constraints{Regex: "^[A-Z]"}},
+			{Slug: "count", Type: api.TypeInteger},
+		},
+	}
+
+	err := Preflight(task, api.Values{"name": "foo", "zone": "us"})
+	require.EqualError(err, "missing required parameters: --count; "+
+		"invalid value for --name: must match regex pattern: ^[A-Z]; "+
+		"invalid value for --zone: unknown parameter")
+
+	var verr ValidationError
+	require.ErrorAs(err, &verr)
+	require.Equal("Pass the missing parameters as flags:\n"+
+		"  --count <integer>\n"+
+		"--name <string>: must match regex pattern: ^[A-Z]\n"+
+		"--zone is not a parameter of this task", verr.ExplainError())
+}
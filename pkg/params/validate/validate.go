@@ -0,0 +1,373 @@
+// Package validate checks parameter values against their parameter's type
+// and constraints, and converts them to API values.
+//
+// Prompts, flags and parameter files all validate with this package, so a
+// value is accepted or rejected the same way however it was entered.
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// DatetimeFormat is how the API expects datetime values.
+const DatetimeFormat = "2006-01-02T15:04:05Z"
+
+// Error is a parameter value that failed validation.
+type Error struct {
+	Slug string
+	Err  error
+}
+
+// Error implementation.
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Slug, e.Err)
+}
+
+// Unwrap implementation.
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// Errors is every value of a set of values that failed validation.
+type Errors []Error
+
+// Error implementation.
+func (errs Errors) Error() string {
+	parts := make([]string, len(errs))
+	for i, e := range errs {
+		parts[i] = e.Error()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Err returns errs as an error, or nil if there are none.
+func (errs Errors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Check checks that in, a value as it would be typed into the CLI, is valid
+// for param: that it is one of param's options, parses as param's type and
+// matches param's regex.
+//
+// An empty value is valid, since whether a value is required is checked
+// separately. This is best effort - the API may still reject valid values.
+func Check(param api.Parameter, in string) error {
+	if in == "" {
+		return nil
+	}
+
+	if len(param.Constraints.Options) > 0 {
+		v, ok := optionValue(param, in)
+		if !ok {
+			return errors.Errorf("expected one of: %s", strings.Join(OptionNames(param), ", "))
+		}
+		in = v
+	}
+
+	switch param.Type {
+	case api.TypeString, api.TypeEnum:
+		// Options are checked above, and replace the regex.
+		if pattern := param.Constraints.Regex; pattern != "" && len(param.Constraints.Options) == 0 {
+			matched, err := regexp.MatchString(pattern, in)
+			if err != nil {
+				return errors.Errorf("errored matching against regex: %s", err)
+			}
+			if !matched {
+				return errors.Errorf("must match regex pattern: %s", pattern)
+			}
+		}
+
+	case api.TypeBoolean:
+		if _, err := ParseBool(in); err != nil {
+			return errors.New("expected yes, no, true, false, 1 or 0")
+		}
+
+	case api.TypeInteger:
+		if _, err := strconv.Atoi(in); err != nil {
+			return errors.New("invalid integer")
+		}
+
+	case api.TypeFloat:
+		if _, err := strconv.ParseFloat(in, 64); err != nil {
+			return errors.New("invalid number")
+		}
+
+	case api.TypeUpload:
+		// TODO(amir): we need to support them with some special
+		// character perhaps `@` like curl?
+		return errors.New("uploads are not supported from the CLI")
+
+	case api.TypeDate:
+		if _, err := time.Parse("2006-01-02", in); err != nil {
+			return errors.New("expected to be formatted as '2016-01-02'")
+		}
+
+	case api.TypeDatetime:
+		if _, err := utils.ParseTime(in); err != nil {
+			return errors.New("expected to be formatted as '2016-01-02T15:04:05Z', or in local time as '2016-01-02T15:04'")
+		}
+	}
+	return nil
+}
+
+// Parse checks in with Check, then converts it to its API value. An empty
+// value converts to param's default.
+func Parse(param api.Parameter, in string) (interface{}, error) {
+	if err := Check(param, in); err != nil {
+		return nil, err
+	}
+	return Convert(param, in)
+}
+
+// Convert converts in, a value as it would be typed into the CLI, to its API
+// value without checking it against param's constraints. An empty value
+// converts to param's default.
+func Convert(param api.Parameter, in string) (interface{}, error) {
+	if in == "" {
+		return param.Default, nil
+	}
+	if v, ok := optionValue(param, in); ok {
+		in = v
+	}
+	switch param.Type {
+	case api.TypeString, api.TypeDate, api.TypeEnum:
+		return in, nil
+
+	case api.TypeDatetime:
+		// The API expects UTC, but users may type any time zone or none.
+		t, err := utils.ParseTime(in)
+		if err != nil {
+			return nil, err
+		}
+		return t.UTC().Format(DatetimeFormat), nil
+
+	case api.TypeBoolean:
+		return ParseBool(in)
+
+	case api.TypeInteger:
+		v, err := strconv.Atoi(in)
+		if err != nil {
+			return nil, errors.Wrap(err, "atoi")
+		}
+		return v, nil
+
+	case api.TypeFloat:
+		v, err := strconv.ParseFloat(in, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsefloat")
+		}
+		return v, nil
+
+	case api.TypeUpload:
+		// TODO: ideally we read the file input here for API
+		return nil, errors.New("uploads are not supported from the CLI")
+
+	case api.TypeConfigVar:
+		return map[string]interface{}{
+			"__airplaneType": "configvar",
+			"name":           in,
+		}, nil
+
+	default:
+		return in, nil
+	}
+}
+
+// ParseBool is a light wrapper around strconv.ParseBool with support for
+// yes and no.
+func ParseBool(v string) (bool, error) {
+	switch vl := strings.ToLower(v); vl {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	default:
+		return strconv.ParseBool(vl)
+	}
+}
+
+// Native checks and converts a value decoded from JSON or YAML, such as from
+// a parameter file. Values may be written natively or as they would be typed
+// into the CLI, so both 3 and "3" are valid integers. Multi parameters accept
+// a list of values, or a single one.
+func Native(param api.Parameter, raw interface{}) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if list, ok := raw.([]interface{}); ok {
+		if !param.Multi {
+			return nil, errors.New("expected a single value, not a list")
+		}
+		values := make([]interface{}, 0, len(list))
+		for _, item := range list {
+			v, err := Native(api.Parameter{Type: param.Type, Constraints: param.Constraints}, item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	}
+
+	in, err := nativeInput(param, raw)
+	if err != nil {
+		return nil, err
+	}
+	v, err := Parse(param, in)
+	if err != nil {
+		return nil, err
+	}
+	if param.Multi && v != nil {
+		v = []interface{}{v}
+	}
+	return v, nil
+}
+
+// nativeInput formats a decoded value as it would be typed into the CLI.
+func nativeInput(param api.Parameter, raw interface{}) (string, error) {
+	switch v := raw.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		// YAML decodes integers too large for an int64 as a uint64.
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		if param.Type == api.TypeDate {
+			return v.Format("2006-01-02"), nil
+		}
+		return v.Format(time.RFC3339), nil
+	default:
+		return "", errors.Errorf("expected a %s value", param.Type)
+	}
+}
+
+// Value checks an API value, as returned by Parse or read back from a run,
+// against param's type and constraints.
+func Value(param api.Parameter, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+	if list, ok := v.([]interface{}); ok {
+		if !param.Multi {
+			return errors.New("expected a single value, not a list")
+		}
+		for _, item := range list {
+			if err := Value(api.Parameter{Type: param.Type, Constraints: param.Constraints}, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch param.Type {
+	case api.TypeString, api.TypeEnum, api.TypeDate, api.TypeDatetime:
+		if _, ok := v.(string); !ok {
+			return errors.Errorf("expected a %s value", param.Type)
+		}
+	case api.TypeBoolean:
+		if _, ok := v.(bool); !ok {
+			return errors.New("expected true or false")
+		}
+	case api.TypeInteger:
+		switch n := v.(type) {
+		case int, int64:
+		case float64:
+			// Integers are float64 once decoded from JSON.
+			if n != float64(int64(n)) {
+				return errors.New("invalid integer")
+			}
+		default:
+			return errors.New("invalid integer")
+		}
+	case api.TypeFloat:
+		switch v.(type) {
+		case int, int64, float64:
+		default:
+			return errors.New("invalid number")
+		}
+	default:
+		// Uploads and config variables are references the API resolves.
+		return nil
+	}
+
+	in, err := nativeInput(param, v)
+	if err != nil {
+		return err
+	}
+	return Check(param, in)
+}
+
+// Values checks every value in values against its parameter in params, and
+// returns all of the failures at once, in the order the parameters are
+// defined. Values for unknown parameters fail too, after the rest.
+func Values(params api.Parameters, values api.Values) Errors {
+	var errs Errors
+	known := make(map[string]bool, len(params))
+	for _, p := range params {
+		known[p.Slug] = true
+		v, ok := values[p.Slug]
+		if !ok {
+			continue
+		}
+		if err := Value(p, v); err != nil {
+			errs = append(errs, Error{Slug: p.Slug, Err: err})
+		}
+	}
+
+	var unknown []string
+	for slug := range values {
+		if !known[slug] {
+			unknown = append(unknown, slug)
+		}
+	}
+	sort.Strings(unknown)
+	for _, slug := range unknown {
+		errs = append(errs, Error{Slug: slug, Err: errors.New("unknown parameter")})
+	}
+	return errs
+}
+
+// OptionNames returns the names of param's options as they would be typed:
+// their label if they have one, or their value otherwise.
+func OptionNames(param api.Parameter) []string {
+	names := make([]string, len(param.Constraints.Options))
+	for i, o := range param.Constraints.Options {
+		if o.Label != "" {
+			names[i] = o.Label
+		} else {
+			names[i] = fmt.Sprint(o.Value)
+		}
+	}
+	return names
+}
+
+// optionValue returns the value of the option of param that in names, by
+// value or by label, formatted as input.
+func optionValue(param api.Parameter, in string) (string, bool) {
+	for _, o := range param.Constraints.Options {
+		if v := fmt.Sprint(o.Value); v == in || (o.Label != "" && o.Label == in) {
+			return v, true
+		}
+	}
+	return "", false
+}
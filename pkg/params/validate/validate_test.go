@@ -0,0 +1,132 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		param    api.Parameter
+		in       string
+		expected interface{}
+		err      string
+	}{
+		{name: "integer", param: api.Parameter{Type: api.TypeInteger}, in: "3", expected: 3},
+		{name: "bad integer", param: api.Parameter{Type: api.TypeInteger}, in: "3.5", err: "invalid integer"},
+		{name: "boolean", param: api.Parameter{Type: api.TypeBoolean}, in: "yes", expected: true},
+		{name: "empty uses default", param: api.Parameter{Type: api.TypeString, Default: "us"}, in: "", expected: "us"},
+		{
+			name:     "regex",
+			param:    api.Parameter{Type: api.TypeString, Constraints: api.Constraints{Regex: "^[a-z]+$"}},
+			in:       "abc",
+			expected: "abc",
+		},
+		{
+			name:  "regex mismatch",
+			param: api.Parameter{Type: api.TypeString, Constraints: api.Constraints{Regex: "^[a-z]+$"}},
+			in:    "ABC",
+			err:   "must match regex pattern: ^[a-z]+$",
+		},
+		{
+			name: "option by label",
+			param: api.Parameter{Type: api.TypeInteger, Constraints: api.Constraints{Options: []api.ConstraintOption{
+				{Label: "One", Value: 1},
+				{Label: "Two", Value: 2},
+			}}},
+			in:       "Two",
+			expected: 2,
+		},
+		{
+			name: "not an option",
+			param: api.Parameter{Type: api.TypeString, Constraints: api.Constraints{Options: []api.ConstraintOption{
+				{Value: "us"},
+				{Value: "eu"},
+			}}},
+			in:  "ap",
+			err: "expected one of: us, eu",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := Parse(test.param, test.in)
+			if test.err != "" {
+				require.EqualError(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, v)
+		})
+	}
+}
+
+func TestNative(t *testing.T) {
+	require := require.New(t)
+
+	v, err := Native(api.Parameter{Type: api.TypeInteger}, 3)
+	require.NoError(err)
+	require.Equal(3, v)
+
+	v, err = Native(api.Parameter{Type: api.TypeInteger}, int64(4))
+	require.NoError(err)
+	require.Equal(4, v)
+
+	v, err = Native(api.Parameter{Type: api.TypeString}, uint64(18446744073709551615))
+	require.NoError(err)
+	require.Equal("18446744073709551615", v)
+
+	v, err = Native(api.Parameter{Type: api.TypeInteger, Multi: true}, []interface{}{1, "2"})
+	require.NoError(err)
+	require.Equal([]interface{}{1, 2}, v)
+
+	_, err = Native(api.Parameter{Type: api.TypeInteger}, []interface{}{1})
+	require.EqualError(err, "expected a single value, not a list")
+
+	_, err = Native(api.Parameter{Type: api.TypeString, Constraints: api.Constraints{Regex: "^a"}}, "b")
+	require.EqualError(err, "must match regex pattern: ^a")
+}
+
+func TestValues(t *testing.T) {
+	require := require.New(t)
+
+	params := api.Parameters{
+		{Slug: "name", Type: api.TypeString, Constraints: api.Constraints{Regex: "^[A-Z]"}},
+		{Slug: "count", Type: api.TypeInteger},
+		{Slug: "ratio", Type: api.TypeFloat},
+		{Slug: "dry_run", Type: api.TypeBoolean},
+		{Slug: "ids", Type: api.TypeInteger, Multi: true},
+	}
+
+	// Values read back from the API are decoded from JSON.
+	require.Empty(Values(params, api.Values{
+		"name":    "Alice",
+		"count":   float64(3),
+		"ratio":   0.5,
+		"dry_run": false,
+		"ids":     []interface{}{float64(1), float64(2)},
+	}))
+
+	errs := Values(params, api.Values{
+		"name":    "alice",
+		"count":   3.5,
+		"dry_run": "maybe",
+		"ids":     []interface{}{float64(1), "x"},
+		"zone":    "us",
+		"color":   "red",
+	})
+	require.Equal([]string{"name", "count", "dry_run", "ids", "color", "zone"}, slugs(errs))
+	require.EqualError(errs, "name: must match regex pattern: ^[A-Z]; count: invalid integer; "+
+		"dry_run: expected true or false; ids: invalid integer; color: unknown parameter; zone: unknown parameter")
+
+	require.NoError(Values(params, api.Values{"count": 1}).Err())
+}
+
+func slugs(errs Errors) []string {
+	var s []string
+	for _, e := range errs {
+		s = append(s, e.Slug)
+	}
+	return s
+}
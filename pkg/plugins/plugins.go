@@ -0,0 +1,189 @@
+// Package plugins finds and runs CLI plugins: executables named
+// airplane-<name> that extend the CLI with an `airplane <name>` command.
+//
+// Plugins are looked up in ~/.airplane/plugins, then on $PATH. They are run
+// with the arguments that follow their name, and with the CLI's host and
+// credentials in their environment so that they can call the API:
+//
+//	AP_HOST     the API host
+//	AP_TOKEN    the logged in user's token, if any
+//	AP_API_KEY  the API key, if any
+//	AP_TEAM_ID  the team of the API key, if any
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/pkg/errors"
+)
+
+// prefix is the start of the name of every plugin executable.
+const prefix = "airplane-"
+
+// Plugin is an executable that runs as `airplane <Name>`.
+type Plugin struct {
+	Name string
+	Path string
+}
+
+// Dir returns the directory plugins are installed in, ahead of $PATH.
+func Dir() string {
+	return filepath.Join(conf.Dir(), "plugins")
+}
+
+// dirs returns the directories to look for plugins in, in order.
+func dirs() []string {
+	return append([]string{Dir()}, filepath.SplitList(os.Getenv("PATH"))...)
+}
+
+// List returns the plugins that are installed, ordered by name. When two
+// directories have a plugin of the same name, the first one found wins, as
+// it would when running it.
+func List() []Plugin {
+	found := map[string]Plugin{}
+	for _, dir := range dirs() {
+		if dir == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, f := range files {
+			name, ok := pluginName(f)
+			if !ok {
+				continue
+			}
+			if _, ok := found[name]; !ok {
+				found[name] = Plugin{Name: name, Path: filepath.Join(dir, f.Name())}
+			}
+		}
+	}
+
+	plugins := make([]Plugin, 0, len(found))
+	for _, p := range found {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// Find returns the plugin run as `airplane <name>`, if one is installed.
+// Unlike List, it only looks for that plugin's executable, rather than
+// reading every directory on $PATH.
+func Find(name string) (Plugin, bool) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return Plugin{}, false
+	}
+	files := []string{prefix + name}
+	if runtime.GOOS == "windows" {
+		files = []string{prefix + name + ".exe", prefix + name + ".bat", prefix + name + ".cmd"}
+	}
+	for _, dir := range dirs() {
+		if dir == "" {
+			continue
+		}
+		for _, file := range files {
+			path := filepath.Join(dir, file)
+			f, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if n, ok := pluginName(f); ok && n == name {
+				return Plugin{Name: name, Path: path}, true
+			}
+		}
+	}
+	return Plugin{}, false
+}
+
+// pluginName returns the name of the plugin that f is, if it is one.
+func pluginName(f os.FileInfo) (string, bool) {
+	name := f.Name()
+	if f.IsDir() || !strings.HasPrefix(name, prefix) {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	} else if f.Mode()&0111 == 0 {
+		return "", false
+	}
+	name = strings.TrimPrefix(name, prefix)
+	return name, name != ""
+}
+
+// Env returns the environment variables that pass the client's host and
+// credentials to a plugin.
+func Env(client api.Client) []string {
+	host := client.Host
+	if host == "" {
+		host = api.Host
+	}
+	env := []string{"AP_HOST=" + host}
+	if client.Token != "" {
+		env = append(env, "AP_TOKEN="+client.Token)
+	}
+	if client.APIKey != "" {
+		env = append(env, "AP_API_KEY="+client.APIKey)
+	}
+	if client.TeamID != "" {
+		env = append(env, "AP_TEAM_ID="+client.TeamID)
+	}
+	return env
+}
+
+// ExitError is returned when a plugin exits with a non-zero code. The plugin
+// has already reported its errors, so the CLI should exit with the same code
+// without adding its own.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("plugin exited with code %d", e.Code)
+}
+
+// ExitCode implements utils.ErrorExitCode.
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// Run runs the plugin with args and the client's credentials, connected to
+// the CLI's standard input and output.
+func Run(ctx context.Context, p Plugin, client api.Client, args []string) error {
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), Env(client)...)
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			// The plugin was killed by a signal.
+			code = 1
+		}
+		return &ExitError{Code: code}
+	} else if err != nil {
+		return errors.Wrapf(err, "running plugin %s", p.Name)
+	}
+	return nil
+}
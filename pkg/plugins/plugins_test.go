@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by extension on Windows")
+	}
+	require := require.New(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	write := func(dir, name string, mode os.FileMode) {
+		require.NoError(os.MkdirAll(dir, 0755))
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode))
+	}
+	write(Dir(), "airplane-deploy-all", 0755)
+	write(bin, "airplane-deploy-all", 0755)
+	write(bin, "airplane-audit", 0755)
+	write(bin, "airplane-notes", 0644)
+	write(bin, "other", 0755)
+
+	require.Equal([]Plugin{
+		{Name: "audit", Path: filepath.Join(bin, "airplane-audit")},
+		{Name: "deploy-all", Path: filepath.Join(Dir(), "airplane-deploy-all")},
+	}, List())
+}
+
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by extension on Windows")
+	}
+	require := require.New(t)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+
+	write := func(dir, name string, mode os.FileMode) {
+		require.NoError(os.MkdirAll(dir, 0755))
+		require.NoError(ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode))
+	}
+	write(Dir(), "airplane-deploy-all", 0755)
+	write(bin, "airplane-deploy-all", 0755)
+	write(bin, "airplane-audit", 0755)
+	write(bin, "airplane-notes", 0644)
+
+	p, ok := Find("deploy-all")
+	require.True(ok)
+	require.Equal(Plugin{Name: "deploy-all", Path: filepath.Join(Dir(), "airplane-deploy-all")}, p)
+	p, ok = Find("audit")
+	require.True(ok)
+	require.Equal(Plugin{Name: "audit", Path: filepath.Join(bin, "airplane-audit")}, p)
+
+	for _, name := range []string{"notes", "missing", "", "../audit"} {
+		_, ok := Find(name)
+		require.False(ok, name)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	require.Equal(t, []string{"AP_HOST=" + api.Host}, Env(api.Client{}))
+	require.Equal(t, []string{
+		"AP_HOST=localhost:5000",
+		"AP_TOKEN=tkn",
+		"AP_API_KEY=key",
+		"AP_TEAM_ID=tea",
+	}, Env(api.Client{Host: "localhost:5000", Token: "tkn", APIKey: "key", TeamID: "tea"}))
+}
@@ -3,6 +3,7 @@ package print
 import (
 	"encoding/json"
 	"os"
+	"time"
 
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/ojson"
@@ -42,12 +43,12 @@ func (j *JSON) task(task api.Task) {
 
 // Runs implementation.
 func (j *JSON) runs(runs []api.Run) {
-	j.enc.Encode(runs)
+	j.enc.Encode(printRuns(runs))
 }
 
 // Run implementation.
 func (j *JSON) run(run api.Run) {
-	j.enc.Encode(run)
+	j.enc.Encode(newPrintRun(run, time.Now()))
 }
 
 // Outputs implementation.
@@ -59,3 +60,23 @@ func (j *JSON) outputs(outputs api.Outputs) {
 func (j *JSON) config(config api.Config) {
 	j.enc.Encode(config)
 }
+
+// EnvGroups implementation.
+func (j *JSON) envGroups(envGroups []api.EnvGroup) {
+	j.enc.Encode(envGroups)
+}
+
+// EnvGroup implementation.
+func (j *JSON) envGroup(envGroup api.EnvGroup) {
+	j.enc.Encode(envGroup)
+}
+
+// Agents implementation.
+func (j *JSON) agents(agents []api.Agent) {
+	j.enc.Encode(agents)
+}
+
+// Schedules implementation.
+func (j *JSON) schedules(schedules []api.Schedule) {
+	j.enc.Encode(schedules)
+}
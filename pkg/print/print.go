@@ -2,6 +2,7 @@ package print
 
 import (
 	"github.com/airplanedev/cli/pkg/api"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -12,6 +13,21 @@ var (
 	DefaultFormatter Formatter = Table{}
 )
 
+// ParseFormatter returns the formatter for an output format: json, yaml or
+// table.
+func ParseFormatter(format string) (Formatter, error) {
+	switch format {
+	case "json":
+		return NewJSONFormatter(), nil
+	case "yaml":
+		return YAML{}, nil
+	case "table":
+		return Table{}, nil
+	default:
+		return nil, errors.Errorf("unknown output format %q: expected json, yaml or table", format)
+	}
+}
+
 // Formatter represents an output formatter.
 type Formatter interface {
 	apiKeys([]api.APIKey)
@@ -21,6 +37,10 @@ type Formatter interface {
 	run(api.Run)
 	outputs(api.Outputs)
 	config(api.Config)
+	envGroups([]api.EnvGroup)
+	envGroup(api.EnvGroup)
+	agents([]api.Agent)
+	schedules([]api.Schedule)
 }
 
 // APIKeys prints one or more API keys.
@@ -58,6 +78,26 @@ func Config(config api.Config) {
 	DefaultFormatter.config(config)
 }
 
+// EnvGroups prints one or more env groups.
+func EnvGroups(envGroups []api.EnvGroup) {
+	DefaultFormatter.envGroups(envGroups)
+}
+
+// EnvGroup prints a single env group.
+func EnvGroup(envGroup api.EnvGroup) {
+	DefaultFormatter.envGroup(envGroup)
+}
+
+// Agents prints one or more agents.
+func Agents(agents []api.Agent) {
+	DefaultFormatter.agents(agents)
+}
+
+// Schedules prints one or more schedules.
+func Schedules(schedules []api.Schedule) {
+	DefaultFormatter.schedules(schedules)
+}
+
 // Print outputs obj based on DefaultFormatter
 // If JSON or YAML, uses that formatter to encode obj
 // Otherwise, calls defaultPrintFunc to render the obj
@@ -71,3 +111,22 @@ func Print(obj interface{}, defaultPrintFunc func()) {
 		defaultPrintFunc()
 	}
 }
+
+// Error prints err to stdout when using the JSON formatter, so that scripts
+// reading the output can tell what failed. It does nothing for other formats,
+// since errors are always logged to stderr.
+func Error(err error) {
+	f, ok := DefaultFormatter.(*JSON)
+	if !ok {
+		return
+	}
+
+	pe := printError{Error: errors.Cause(err).Error()}
+	var apiErr api.Error
+	if errors.As(err, &apiErr) {
+		pe.Code = apiErr.Code
+		pe.RequestID = apiErr.RequestID
+		pe.ServerRequestID = apiErr.ServerRequestID
+	}
+	f.Encode(pe)
+}
@@ -7,11 +7,13 @@ import (
 
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/airplanedev/cli/pkg/params"
+	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/airplanedev/ojson"
 	"github.com/olekukonko/tablewriter"
 )
@@ -25,13 +27,27 @@ type Table struct{}
 func (t Table) apiKeys(apiKeys []api.APIKey) {
 	tw := tablewriter.NewWriter(os.Stdout)
 	tw.SetBorder(false)
-	tw.SetHeader([]string{"id", "created at", "name"})
+	tw.SetHeader([]string{"id", "created at", "name", "scopes", "expires at"})
 
 	for _, k := range apiKeys {
+		scopes := "all"
+		if len(k.Scopes) > 0 {
+			var ss []string
+			for _, s := range k.Scopes {
+				ss = append(ss, string(s))
+			}
+			scopes = strings.Join(ss, ", ")
+		}
+		expiresAt := "never"
+		if k.ExpiresAt != nil {
+			expiresAt = utils.FormatTime(*k.ExpiresAt)
+		}
 		tw.Append([]string{
 			k.ID,
-			k.CreatedAt.Format(time.RFC3339),
+			utils.FormatTime(k.CreatedAt),
 			k.Name,
+			scopes,
+			expiresAt,
 		})
 	}
 
@@ -42,7 +58,7 @@ func (t Table) apiKeys(apiKeys []api.APIKey) {
 func (t Table) tasks(tasks []api.Task) {
 	tw := tablewriter.NewWriter(os.Stdout)
 	tw.SetBorder(false)
-	tw.SetHeader([]string{"name", "slug", "builder", "parameters"})
+	tw.SetHeader([]string{"name", "slug", "builder", "owner", "parameters"})
 	tw.SetRowLine(true)
 	tw.SetAutoWrapText(false)
 	tw.SetCaption(true, "* indicates a required parameter")
@@ -77,6 +93,7 @@ func (t Table) tasks(tasks []api.Task) {
 			t.Name,
 			t.Slug,
 			builder,
+			t.OwnerEmail(),
 			parametersStr,
 		})
 	}
@@ -92,6 +109,19 @@ func (t Table) task(task api.Task) {
 	fmt.Fprintln(os.Stdout, "Slug:       ", task.Slug)
 	fmt.Fprintln(os.Stdout, "Description:", task.Description)
 	fmt.Fprintln(os.Stdout, "Builder:    ", builderStr)
+	if owner := task.OwnerEmail(); owner != "" {
+		fmt.Fprintln(os.Stdout, "Owner:      ", owner)
+	}
+	if p := task.Pause; p.Active(time.Now()) {
+		paused := "until resumed"
+		if p.Until != nil {
+			paused = "until " + utils.FormatTime(*p.Until)
+		}
+		if p.Reason != "" {
+			paused += ": " + p.Reason
+		}
+		fmt.Fprintln(os.Stdout, "Paused:     ", paused)
+	}
 	fmt.Fprintln(os.Stdout, "")
 
 	if len(task.Parameters) > 0 {
@@ -130,26 +160,35 @@ func (t Table) task(task api.Task) {
 func (t Table) runs(runs []api.Run) {
 	tw := tablewriter.NewWriter(os.Stdout)
 	tw.SetBorder(false)
-	tw.SetHeader([]string{"id", "task", "status", "created at", "ended at"})
+	tw.SetHeader([]string{"id", "task", "status", "priority", "created at", "ended at", "queued", "duration"})
 
+	now := time.Now()
 	for _, run := range runs {
-		var endedAt string
+		var endedAt, queued, duration string
 
-		switch {
-		case run.SucceededAt != nil:
-			endedAt = run.SucceededAt.Format(time.RFC3339)
-		case run.FailedAt != nil:
-			endedAt = run.FailedAt.Format(time.RFC3339)
-		case run.CancelledAt != nil:
-			endedAt = run.CancelledAt.Format(time.RFC3339)
+		priority := string(run.Priority)
+		if priority == "" {
+			priority = string(api.RunPriorityNormal)
+		}
+		if ended := run.EndedAt(); ended != nil {
+			endedAt = utils.FormatTime(*ended)
+		}
+		if d, ok := run.QueuedDuration(now); ok {
+			queued = utils.FormatDuration(d)
+		}
+		if d, ok := run.RunDuration(now); ok {
+			duration = utils.FormatDuration(d)
 		}
 
 		tw.Append([]string{
 			run.RunID,
 			run.TaskName,
 			string(run.Status),
-			run.CreatedAt.Format(time.RFC3339),
+			priority,
+			utils.FormatTime(run.CreatedAt),
 			endedAt,
+			queued,
+			duration,
 		})
 	}
 
@@ -159,6 +198,10 @@ func (t Table) runs(runs []api.Run) {
 // Run implementation.
 func (t Table) run(run api.Run) {
 	t.runs([]api.Run{run})
+	if len(run.Labels) > 0 {
+		labels := utils.LabelsValue(run.Labels)
+		fmt.Fprintf(os.Stdout, "\nLabels: %s\n", labels.String())
+	}
 }
 
 // print outputs as table
@@ -288,3 +331,112 @@ func (t Table) config(config api.Config) {
 	}
 	fmt.Fprintln(os.Stdout, valueStr)
 }
+
+// EnvGroups implementation.
+func (t Table) envGroups(envGroups []api.EnvGroup) {
+	tw := tablewriter.NewWriter(os.Stdout)
+	tw.SetBorder(false)
+	tw.SetHeader([]string{"name", "env vars", "updated at"})
+
+	for _, g := range envGroups {
+		tw.Append([]string{
+			g.Name,
+			strconv.Itoa(len(g.Env)),
+			utils.FormatTime(g.UpdatedAt),
+		})
+	}
+
+	tw.Render()
+}
+
+// EnvGroup implementation.
+func (t Table) envGroup(envGroup api.EnvGroup) {
+	keys := make([]string, 0, len(envGroup.Env))
+	for k := range envGroup.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tw := tablewriter.NewWriter(os.Stdout)
+	tw.SetBorder(false)
+	tw.SetHeader([]string{"name", "value"})
+
+	for _, k := range keys {
+		v := envGroup.Env[k]
+		var valueStr string
+		switch {
+		case v.Config != nil:
+			valueStr = logger.Gray("config: %s", *v.Config)
+		case v.Value != nil:
+			valueStr = *v.Value
+		}
+		tw.Append([]string{k, valueStr})
+	}
+
+	tw.Render()
+}
+
+// Agents implementation.
+func (t Table) agents(agents []api.Agent) {
+	tw := tablewriter.NewWriter(os.Stdout)
+	tw.SetBorder(false)
+	tw.SetAutoWrapText(false)
+	tw.SetHeader([]string{"id", "hostname", "status", "labels", "last heartbeat"})
+
+	for _, a := range agents {
+		keys := make([]string, 0, len(a.Labels))
+		for k := range a.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		labels := make([]string, len(keys))
+		for i, k := range keys {
+			labels[i] = k + ":" + a.Labels[k]
+		}
+
+		var heartbeat string
+		if a.LastHeartbeatAt != nil {
+			heartbeat = utils.FormatTime(*a.LastHeartbeatAt)
+		}
+
+		tw.Append([]string{
+			a.ID,
+			a.Hostname,
+			string(a.Status),
+			strings.Join(labels, "\n"),
+			heartbeat,
+		})
+	}
+
+	tw.Render()
+}
+
+// Schedules implementation.
+func (t Table) schedules(schedules []api.Schedule) {
+	tw := tablewriter.NewWriter(os.Stdout)
+	tw.SetBorder(false)
+	tw.SetAutoWrapText(false)
+	tw.SetHeader([]string{"id", "slug", "task id", "cron", "timezone", "paused", "next run"})
+
+	for _, s := range schedules {
+		timezone := s.Timezone
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		var nextRun string
+		if s.NextRunAt != nil && !s.Paused {
+			nextRun = utils.FormatTime(*s.NextRunAt)
+		}
+		tw.Append([]string{
+			s.ID,
+			s.Slug,
+			s.TaskID,
+			s.CronExpr,
+			timezone,
+			strconv.FormatBool(s.Paused),
+			nextRun,
+		})
+	}
+
+	tw.Render()
+}
@@ -1,6 +1,9 @@
 package print
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/lib/pkg/build"
 )
@@ -16,8 +19,10 @@ type printTask struct {
 	Command                    []string             `json:"command" yaml:"command"`
 	Arguments                  []string             `json:"arguments" yaml:"arguments"`
 	Parameters                 api.Parameters       `json:"parameters" yaml:"parameters"`
+	Form                       *api.Form            `json:"form" yaml:"form,omitempty"`
 	Constraints                api.RunConstraints   `json:"constraints" yaml:"constraints"`
 	Env                        api.TaskEnv          `json:"env" yaml:"env"`
+	EnvGroups                  []string             `json:"envGroups" yaml:"envGroups"`
 	ResourceRequests           api.ResourceRequests `json:"resourceRequests" yaml:"resourceRequests"`
 	Resources                  api.Resources        `json:"resources" yaml:"resources"`
 	Kind                       build.TaskKind       `json:"builder" yaml:"builder"`
@@ -25,8 +30,18 @@ type printTask struct {
 	Repo                       string               `json:"repo" yaml:"repo"`
 	RequireExplicitPermissions bool                 `json:"requireExplicitPermissions" yaml:"-"`
 	Permissions                api.Permissions      `json:"permissions" yaml:"-"`
-	Timeout                    int                  `json:"timeout" yaml:"timeout"`
+	Concurrency                *api.Concurrency     `json:"concurrency" yaml:"concurrency,omitempty"`
+	Confirm                    string               `json:"confirm" yaml:"confirm,omitempty"`
+	Dependencies               *api.Dependencies    `json:"dependencies" yaml:"dependencies,omitempty"`
+	Timeout                    api.Duration         `json:"timeout" yaml:"timeout"`
 	InterpolationMode          string               `json:"-" yaml:"-"`
+
+	Creator *api.UserInfo `json:"creator,omitempty" yaml:"creator,omitempty"`
+	Owner   *api.UserInfo `json:"owner,omitempty" yaml:"owner,omitempty"`
+
+	Pause *api.TaskPause `json:"pause,omitempty" yaml:"pause,omitempty"`
+
+	Raw json.RawMessage `json:"-" yaml:"-"`
 }
 
 func printTasks(tasks []api.Task) []printTask {
@@ -36,3 +51,43 @@ func printTasks(tasks []api.Task) []printTask {
 	}
 	return pts
 }
+
+// printRun is a run with its computed durations.
+type printRun struct {
+	api.Run `yaml:",inline"`
+
+	// QueuedSeconds is how long the run waited before it started, and
+	// DurationSeconds how long it ran for, so far if it hasn't stopped.
+	QueuedSeconds   *float64 `json:"queuedSeconds,omitempty" yaml:"queuedSeconds,omitempty"`
+	DurationSeconds *float64 `json:"durationSeconds,omitempty" yaml:"durationSeconds,omitempty"`
+}
+
+func newPrintRun(run api.Run, now time.Time) printRun {
+	pr := printRun{Run: run}
+	if d, ok := run.QueuedDuration(now); ok {
+		s := d.Seconds()
+		pr.QueuedSeconds = &s
+	}
+	if d, ok := run.RunDuration(now); ok {
+		s := d.Seconds()
+		pr.DurationSeconds = &s
+	}
+	return pr
+}
+
+func printRuns(runs []api.Run) []printRun {
+	now := time.Now()
+	prs := make([]printRun, len(runs))
+	for i, r := range runs {
+		prs[i] = newPrintRun(r, now)
+	}
+	return prs
+}
+
+// printError is how errors are printed with the JSON formatter.
+type printError struct {
+	Error           string `json:"error"`
+	Code            int    `json:"code,omitempty"`
+	RequestID       string `json:"requestID,omitempty"`
+	ServerRequestID string `json:"serverRequestID,omitempty"`
+}
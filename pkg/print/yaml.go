@@ -2,9 +2,9 @@ package print
 
 import (
 	"os"
+	"time"
 
 	"github.com/airplanedev/cli/pkg/api"
-	"github.com/airplanedev/ojson"
 	"gopkg.in/yaml.v3"
 )
 
@@ -35,21 +35,40 @@ func (YAML) task(task api.Task) {
 
 // Runs implementation.
 func (YAML) runs(runs []api.Run) {
-	yaml.NewEncoder(os.Stdout).Encode(runs)
+	yaml.NewEncoder(os.Stdout).Encode(printRuns(runs))
 }
 
 // Run implementation.
 func (YAML) run(run api.Run) {
-	yaml.NewEncoder(os.Stdout).Encode(run)
+	yaml.NewEncoder(os.Stdout).Encode(newPrintRun(run, time.Now()))
 }
 
 // Outputs implementation.
 func (YAML) outputs(outputs api.Outputs) {
-	// TODO: update ojson to handle yaml properly
-	yaml.NewEncoder(os.Stdout).Encode(ojson.Value(outputs).V)
+	yaml.NewEncoder(os.Stdout).Encode(outputs)
 }
 
 // Config implementation.
 func (YAML) config(config api.Config) {
 	yaml.NewEncoder(os.Stdout).Encode(config)
 }
+
+// EnvGroups implementation.
+func (YAML) envGroups(envGroups []api.EnvGroup) {
+	yaml.NewEncoder(os.Stdout).Encode(envGroups)
+}
+
+// EnvGroup implementation.
+func (YAML) envGroup(envGroup api.EnvGroup) {
+	yaml.NewEncoder(os.Stdout).Encode(envGroup)
+}
+
+// Agents implementation.
+func (YAML) agents(agents []api.Agent) {
+	yaml.NewEncoder(os.Stdout).Encode(agents)
+}
+
+// Schedules implementation.
+func (YAML) schedules(schedules []api.Schedule) {
+	yaml.NewEncoder(os.Stdout).Encode(schedules)
+}
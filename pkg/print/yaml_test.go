@@ -0,0 +1,39 @@
+package print
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+// stdout returns what fn writes to os.Stdout.
+func stdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	old := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = old }()
+
+	fn()
+	require.NoError(t, w.Close())
+	buf, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	return string(buf)
+}
+
+func TestYAMLOutputs(t *testing.T) {
+	var outputs api.Outputs
+	require.NoError(t, json.Unmarshal([]byte(`{"output":[{"id":2,"name":"b"},{"id":1,"name":"a"}],"count":2}`), &outputs))
+
+	require.Equal(t, `output:
+    - id: 2
+      name: b
+    - id: 1
+      name: a
+count: 2
+`, stdout(t, func() { YAML{}.outputs(outputs) }))
+}
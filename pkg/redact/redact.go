@@ -0,0 +1,102 @@
+// Package redact masks secrets in run logs, so that values a task prints by
+// accident don't end up in terminals, CI logs or log sinks.
+package redact
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Usage documents the accepted rules, for use in flag descriptions.
+const Usage = "regex:<pattern> or keyword:<word>"
+
+// Mask replaces redacted text.
+const Mask = "[REDACTED]"
+
+// Redactor masks text matched by a set of rules. A nil Redactor masks
+// nothing.
+type Redactor struct {
+	rules []rule
+}
+
+type rule struct {
+	re *regexp.Regexp
+	// group is the submatch to mask, or 0 to mask the whole match.
+	group int
+}
+
+// Compile compiles rules, each of which is one of:
+//
+//	regex:<pattern>  masks matches of pattern, or only its first capture
+//	                 group if it has one
+//	keyword:<word>   masks the value after word and an = or :, such as
+//	                 the hunter2 in password=hunter2, ignoring case
+//
+// It returns nil if there are no rules.
+func Compile(rules []string) (*Redactor, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{}
+	for _, spec := range rules {
+		kind, arg := spec, ""
+		if i := strings.Index(spec, ":"); i >= 0 {
+			kind, arg = spec[:i], spec[i+1:]
+		}
+
+		switch kind {
+		case "regex":
+			re, err := regexp.Compile(arg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid redaction rule %q", spec)
+			}
+			if arg == "" {
+				return nil, errors.New("regex redaction rule requires a pattern: regex:<pattern>")
+			}
+			group := 0
+			if re.NumSubexp() > 0 {
+				group = 1
+			}
+			r.rules = append(r.rules, rule{re: re, group: group})
+		case "keyword":
+			if strings.TrimSpace(arg) == "" {
+				return nil, errors.New("keyword redaction rule requires a word: keyword:<word>")
+			}
+			re := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(arg) + `\b["']?\s*[=:]\s*("[^"]*"|'[^']*'|[^\s,;&]+)`)
+			r.rules = append(r.rules, rule{re: re, group: 1})
+		default:
+			return nil, errors.Errorf("unknown redaction rule %q: expected %s", spec, Usage)
+		}
+	}
+	return r, nil
+}
+
+// Redact returns s with every match of the rules masked.
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, rl := range r.rules {
+		matches := rl.re.FindAllStringSubmatchIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[2*rl.group], m[2*rl.group+1]
+			if start < 0 {
+				// The group didn't take part in the match.
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(Mask)
+			last = end
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
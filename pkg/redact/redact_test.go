@@ -0,0 +1,43 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	for _, spec := range []string{"regex:", "regex:(", "keyword:", "secret", "glob:*"} {
+		_, err := Compile([]string{spec})
+		require.Error(t, err, spec)
+	}
+
+	r, err := Compile(nil)
+	require.NoError(t, err)
+	require.Nil(t, r)
+	require.Equal(t, "password=hunter2", r.Redact("password=hunter2"))
+}
+
+func TestRedact(t *testing.T) {
+	r, err := Compile([]string{
+		"keyword:password",
+		"regex:sk_live_[0-9a-zA-Z]+",
+		"regex:token (\\w+)",
+	})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		in, out string
+	}{
+		{"nothing to see", "nothing to see"},
+		{"password=hunter2", "password=[REDACTED]"},
+		{"PASSWORD: hunter2 and more", "PASSWORD: [REDACTED] and more"},
+		{`{"password": "hunter 2"}`, `{"password": [REDACTED]}`},
+		{"db?user=a&password=b&x=1", "db?user=a&password=[REDACTED]&x=1"},
+		{"passwords are great", "passwords are great"},
+		{"keys sk_live_abc123 and sk_live_def456", "keys [REDACTED] and [REDACTED]"},
+		{"got token abc from vault", "got token [REDACTED] from vault"},
+	} {
+		require.Equal(t, test.out, r.Redact(test.in), test.in)
+	}
+}
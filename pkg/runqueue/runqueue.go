@@ -0,0 +1,241 @@
+// Package runqueue stores requests to execute tasks that could not be sent
+// to the API, so that they can be submitted once it is reachable again.
+//
+// The queue is stored as a single JSON file under ~/.airplane. Unlike the
+// cache, its contents are not safe to lose.
+package runqueue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/airplanedev/cli/pkg/params"
+	"github.com/pkg/errors"
+)
+
+// mu serializes read-modify-write cycles within this process.
+var mu sync.Mutex
+
+// Entry is a queued request to execute a task.
+type Entry struct {
+	ID string `json:"id"`
+	// Host is the API host the run is for.
+	Host     string `json:"host"`
+	TaskSlug string `json:"taskSlug"`
+	// Args are the task's parameter flags. They are parsed when the entry is
+	// submitted, since the task's parameters can't be looked up offline.
+	Args     []string  `json:"args,omitempty"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// Queue is a persistent FIFO queue of entries.
+type Queue struct {
+	path string
+}
+
+// Default returns the queue in the CLI's state directory.
+func Default() Queue {
+	return Queue{path: filepath.Join(conf.Dir(), "run-queue.json")}
+}
+
+// NewEntry returns an entry, not yet queued, for a request to run the task
+// with the given slug and parameter flags on host. Its ID is sent as the
+// run's idempotency key, so that an entry which reached the API before it
+// was queued, such as one whose request timed out, doesn't run twice.
+func NewEntry(host, slug string, args []string) (Entry, error) {
+	id, err := newID()
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{
+		ID:       id,
+		Host:     host,
+		TaskSlug: slug,
+		Args:     args,
+		QueuedAt: time.Now(),
+	}, nil
+}
+
+// Add appends a request to run the task with the given slug and parameter
+// flags on host, and returns its entry.
+func (q Queue) Add(host, slug string, args []string) (Entry, error) {
+	e, err := NewEntry(host, slug, args)
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, q.Push(e)
+}
+
+// Push appends e to the queue.
+func (q Queue) Push(e Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+	entries, err := q.read()
+	if err != nil {
+		return err
+	}
+	return q.write(append(entries, e))
+}
+
+// List returns the queued entries for host, oldest first.
+func (q Queue) List(host string) ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	entries, err := q.read()
+	if err != nil {
+		return nil, err
+	}
+	var res []Entry
+	for _, e := range entries {
+		if e.Host == host {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+// Remove removes the entry with the given ID, if it is queued.
+func (q Queue) Remove(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	entries, err := q.read()
+	if err != nil {
+		return err
+	}
+	res := entries[:0]
+	for _, e := range entries {
+		if e.ID != id {
+			res = append(res, e)
+		}
+	}
+	return q.write(res)
+}
+
+// Result is the outcome of submitting an entry.
+type Result struct {
+	Entry Entry
+	RunID string
+	// Err is set if the entry could not be submitted and was dropped, such
+	// as because its task no longer exists or its parameters are invalid.
+	Err error
+}
+
+// Flush submits the entries queued for host through client, oldest first. Each
+// entry is removed once it has been submitted or has failed for good.
+//
+// Flush stops at the first entry that fails because the API is unavailable,
+// or because the client isn't logged in or lacks permission, leaving it and
+// later entries queued to be flushed again, and returns that error.
+func Flush(ctx context.Context, client api.APIClient, host string, q Queue) ([]Result, error) {
+	entries, err := q.List(host)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, e := range entries {
+		runID, err := submit(ctx, client, e)
+		if api.IsUnavailable(err) || IsAuthError(err) || errors.Is(err, context.Canceled) {
+			return results, err
+		}
+		if err := q.Remove(e.ID); err != nil {
+			return results, err
+		}
+		results = append(results, Result{Entry: e, RunID: runID, Err: err})
+	}
+	return results, nil
+}
+
+// IsAuthError reports whether err means the client isn't logged in, or isn't
+// allowed to run the task, which logging in again may fix.
+func IsAuthError(err error) bool {
+	var apiErr api.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == 401 || apiErr.Code == 403
+	}
+	var denied api.PermissionDeniedError
+	return errors.As(err, &denied)
+}
+
+func submit(ctx context.Context, client api.APIClient, e Entry) (string, error) {
+	task, err := client.GetTask(ctx, e.TaskSlug)
+	if err != nil {
+		return "", err
+	}
+	values, err := params.FromFlags(task, e.Args)
+	if err != nil {
+		return "", err
+	}
+	if err := params.Preflight(task, values); err != nil {
+		return "", err
+	}
+	res, err := client.RunTask(ctx, api.RunTaskRequest{
+		TaskID:         task.ID,
+		ParamValues:    values,
+		IdempotencyKey: e.ID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.RunID, nil
+}
+
+func (q Queue) read() ([]Entry, error) {
+	buf, err := ioutil.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "runqueue: read")
+	}
+	var entries []Entry
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		// Don't silently drop queued runs: leave the file for the user to fix.
+		return nil, errors.Wrapf(err, "runqueue: %s is corrupt", q.path)
+	}
+	return entries, nil
+}
+
+// write replaces the queue's file atomically.
+func (q Queue) write(entries []Entry) error {
+	buf, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "runqueue: marshal")
+	}
+
+	dir := filepath.Dir(q.path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, "runqueue: mkdir")
+	}
+
+	f, err := ioutil.TempFile(dir, filepath.Base(q.path)+".*")
+	if err != nil {
+		return errors.Wrap(err, "runqueue: create temp file")
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return errors.Wrap(err, "runqueue: write")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "runqueue: close")
+	}
+	return errors.Wrap(os.Rename(f.Name(), q.path), "runqueue: rename")
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "runqueue: generating ID")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,154 @@
+package runqueue
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/api/apitest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	t.Run("list missing", func(t *testing.T) {
+		var assert = require.New(t)
+		var q = Queue{path: filepath.Join(tempdir(t), "run-queue.json")}
+
+		entries, err := q.List("api.airplane.dev")
+		assert.NoError(err)
+		assert.Len(entries, 0)
+	})
+
+	t.Run("add, list and remove", func(t *testing.T) {
+		var assert = require.New(t)
+		var q = Queue{path: filepath.Join(tempdir(t), "state", "run-queue.json")}
+
+		a, err := q.Add("api.airplane.dev", "first", []string{"--name=World"})
+		assert.NoError(err)
+		b, err := q.Add("api.airplane.dev", "second", nil)
+		assert.NoError(err)
+		_, err = q.Add("api.airstage.app", "other", nil)
+		assert.NoError(err)
+		assert.NotEqual(a.ID, b.ID)
+
+		entries, err := q.List("api.airplane.dev")
+		assert.NoError(err)
+		assert.Len(entries, 2)
+		assert.Equal("first", entries[0].TaskSlug)
+		assert.Equal([]string{"--name=World"}, entries[0].Args)
+		assert.Equal("second", entries[1].TaskSlug)
+
+		assert.NoError(q.Remove(a.ID))
+		entries, err = q.List("api.airplane.dev")
+		assert.NoError(err)
+		assert.Len(entries, 1)
+		assert.Equal(b.ID, entries[0].ID)
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		var assert = require.New(t)
+		var q = Queue{path: filepath.Join(tempdir(t), "run-queue.json")}
+
+		assert.NoError(ioutil.WriteFile(q.path, []byte("{"), 0600))
+		_, err := q.List("api.airplane.dev")
+		assert.Error(err)
+	})
+}
+
+func TestFlush(t *testing.T) {
+	var assert = require.New(t)
+	var q = Queue{path: filepath.Join(tempdir(t), "run-queue.json")}
+	var client = apitest.New()
+	task := client.AddTask(api.Task{Slug: "hello"})
+
+	_, err := q.Add("api.airplane.dev", "hello", nil)
+	assert.NoError(err)
+	_, err = q.Add("api.airplane.dev", "missing", nil)
+	assert.NoError(err)
+	_, err = q.Add("api.airstage.app", "hello", nil)
+	assert.NoError(err)
+
+	results, err := Flush(context.Background(), client, "api.airplane.dev", q)
+	assert.NoError(err)
+	assert.Len(results, 2)
+	assert.NoError(results[0].Err)
+	assert.NotEmpty(results[0].RunID)
+	assert.IsType(&api.TaskMissingError{}, results[1].Err)
+
+	runs := client.Runs()
+	assert.Len(runs, 1)
+	assert.Equal(task.ID, runs[0].TaskID)
+
+	// Both entries were removed, and the other host's entry was left alone.
+	entries, err := q.List("api.airplane.dev")
+	assert.NoError(err)
+	assert.Len(entries, 0)
+	entries, err = q.List("api.airstage.app")
+	assert.NoError(err)
+	assert.Len(entries, 1)
+}
+
+// unauthorized is a client whose credentials have expired.
+type unauthorized struct {
+	api.APIClient
+}
+
+func (unauthorized) GetTask(ctx context.Context, slug string) (api.Task, error) {
+	return api.Task{}, api.Error{Code: 401, Message: "unauthorized"}
+}
+
+func TestFlushKeepsEntries(t *testing.T) {
+	t.Run("on auth errors", func(t *testing.T) {
+		var assert = require.New(t)
+		var q = Queue{path: filepath.Join(tempdir(t), "run-queue.json")}
+		var client = apitest.New()
+		client.AddTask(api.Task{Slug: "hello"})
+		_, err := q.Add("api.airplane.dev", "hello", nil)
+		assert.NoError(err)
+
+		results, err := Flush(context.Background(), unauthorized{client}, "api.airplane.dev", q)
+		assert.True(IsAuthError(err))
+		assert.Len(results, 0)
+		entries, err := q.List("api.airplane.dev")
+		assert.NoError(err)
+		assert.Len(entries, 1)
+
+		// Once logged in again, the entry is submitted.
+		results, err = Flush(context.Background(), client, "api.airplane.dev", q)
+		assert.NoError(err)
+		assert.Len(results, 1)
+		assert.NoError(results[0].Err)
+	})
+
+	t.Run("idempotently", func(t *testing.T) {
+		var assert = require.New(t)
+		var q = Queue{path: filepath.Join(tempdir(t), "run-queue.json")}
+		var client = apitest.New()
+		task := client.AddTask(api.Task{Slug: "hello"})
+
+		// The run was started, but its response was lost, so it was queued.
+		e, err := NewEntry("api.airplane.dev", "hello", nil)
+		assert.NoError(err)
+		res, err := client.RunTask(context.Background(), api.RunTaskRequest{TaskID: task.ID, IdempotencyKey: e.ID})
+		assert.NoError(err)
+		assert.NoError(q.Push(e))
+
+		results, err := Flush(context.Background(), client, "api.airplane.dev", q)
+		assert.NoError(err)
+		assert.Len(results, 1)
+		assert.Equal(res.RunID, results[0].RunID)
+		assert.Len(client.Runs(), 1)
+	})
+}
+
+func tempdir(t testing.TB) string {
+	name, err := ioutil.TempDir("", "runqueue_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(name) })
+	return name
+}
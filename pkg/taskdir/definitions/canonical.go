@@ -0,0 +1,117 @@
+package definitions
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// canonicalKeys are listed first, in this order, in every mapping of a
+// canonical definition. All other keys follow in alphabetical order.
+var canonicalKeys = []string{"name", "slug", "description"}
+
+// MarshalCanonical marshals a Definition as canonical YAML.
+func (d Definition) MarshalCanonical() ([]byte, error) {
+	buf, err := yaml.Marshal(d)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling definition")
+	}
+	return CanonicalYAML(buf)
+}
+
+// CanonicalYAML reformats a YAML or JSON document so that two documents with
+// the same content are byte for byte identical, which keeps diffs between
+// definitions down to what actually changed.
+//
+// Canonical YAML orders mapping keys as described by canonicalKeys, omits
+// nulls and empty lists and maps, and is indented by two spaces. Comments
+// are dropped.
+func CanonicalYAML(buf []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf, &doc); err != nil {
+		return nil, errors.Wrap(err, "parsing definition")
+	}
+	if len(doc.Content) == 0 {
+		return nil, errors.New("definition is empty")
+	}
+	root := doc.Content[0]
+	canonicalize(root)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, errors.Wrap(err, "marshalling definition")
+	}
+	if err := enc.Close(); err != nil {
+		return nil, errors.Wrap(err, "marshalling definition")
+	}
+	return out.Bytes(), nil
+}
+
+// canonicalize rewrites n in canonical form, and reports whether it is empty
+// and should be omitted from its parent.
+func canonicalize(n *yaml.Node) bool {
+	n.HeadComment, n.LineComment, n.FootComment = "", "", ""
+	switch n.Kind {
+	case yaml.AliasNode:
+		*n = *n.Alias
+		return canonicalize(n)
+
+	case yaml.ScalarNode:
+		return n.Tag == "!!null"
+
+	case yaml.SequenceNode:
+		// Block style throughout, even if the input was JSON.
+		n.Style = 0
+		var items []*yaml.Node
+		for _, item := range n.Content {
+			if !canonicalize(item) {
+				items = append(items, item)
+			}
+		}
+		n.Content = items
+		return len(items) == 0
+
+	case yaml.MappingNode:
+		n.Style = 0
+		type pair struct{ k, v *yaml.Node }
+		var pairs []pair
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			k.HeadComment, k.LineComment, k.FootComment = "", "", ""
+			k.Style = 0
+			if !canonicalize(v) {
+				pairs = append(pairs, pair{k, v})
+			}
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return keyLess(pairs[i].k.Value, pairs[j].k.Value)
+		})
+		n.Content = n.Content[:0]
+		for _, p := range pairs {
+			n.Content = append(n.Content, p.k, p.v)
+		}
+		return len(pairs) == 0
+	}
+	return false
+}
+
+func keyLess(a, b string) bool {
+	ai, bi := keyRank(a), keyRank(b)
+	if ai != bi {
+		return ai < bi
+	}
+	return a < b
+}
+
+func keyRank(k string) int {
+	for i, c := range canonicalKeys {
+		if k == c {
+			return i
+		}
+	}
+	return len(canonicalKeys)
+}
@@ -0,0 +1,55 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalYAML(t *testing.T) {
+	t.Run("orders keys and omits empties", func(t *testing.T) {
+		out, err := CanonicalYAML([]byte(`
+timeout: 60
+# The task's slug.
+slug: hello
+constraints:
+    labels: []
+env:
+    B: {value: "", config: null}
+    A: {config: db_url}
+name: Hello
+parameters: [{type: shorttext, slug: who, name: Who, default: null}]
+`))
+		require.NoError(t, err)
+		require.Equal(t, `name: Hello
+slug: hello
+env:
+  A:
+    config: db_url
+  B:
+    value: ""
+parameters:
+  - name: Who
+    slug: who
+    type: shorttext
+timeout: 60
+`, string(out))
+	})
+
+	t.Run("is stable", func(t *testing.T) {
+		def := Definition{
+			Slug: "hello",
+			Name: "Hello",
+			Parameters: api.Parameters{
+				{Name: "Who", Slug: "who", Type: api.TypeString},
+			},
+			Python: &PythonDefinition{Entrypoint: "hello.py"},
+		}
+		first, err := def.MarshalCanonical()
+		require.NoError(t, err)
+		second, err := CanonicalYAML(first)
+		require.NoError(t, err)
+		require.Equal(t, string(first), string(second))
+	})
+}
@@ -0,0 +1,53 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CLIDefaults are defaults for `airplane execute`, kept in a definition's
+// x-cli block so that teams can record how a task is usually run next to the
+// task itself. They are only read by the CLI and are never sent to the API.
+//
+// Flags passed to execute take precedence over these defaults, except for log
+// sinks, output hooks and redaction rules, which are added to any passed with
+// --log-sink, --output-hook and --redact.
+type CLIDefaults struct {
+	// Output is the output format: json, yaml or table.
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// LogSinks are extra destinations that run logs are always copied to,
+	// such as a webhook that posts to a team's channel.
+	LogSinks []string `json:"logSinks,omitempty" yaml:"logSinks,omitempty"`
+	// OutputHooks reshape the run's outputs before they are printed, such as
+	// csv:rows.csv to also save a rows output as a spreadsheet.
+	OutputHooks []string `json:"outputHooks,omitempty" yaml:"outputHooks,omitempty"`
+	// Redact are rules masking secrets in the run's logs, such as
+	// keyword:password.
+	Redact []string `json:"redact,omitempty" yaml:"redact,omitempty"`
+	// Heartbeat and StallWarning are durations, such as 5m, with the same
+	// meaning as execute's --heartbeat and --stall-warning flags.
+	Heartbeat    string `json:"heartbeat,omitempty" yaml:"heartbeat,omitempty"`
+	StallWarning string `json:"stallWarning,omitempty" yaml:"stallWarning,omitempty"`
+}
+
+// HeartbeatDuration parses Heartbeat, returning false if it is unset.
+func (d CLIDefaults) HeartbeatDuration() (time.Duration, bool, error) {
+	return parseCLIDuration("heartbeat", d.Heartbeat)
+}
+
+// StallWarningDuration parses StallWarning, returning false if it is unset.
+func (d CLIDefaults) StallWarningDuration() (time.Duration, bool, error) {
+	return parseCLIDuration("stallWarning", d.StallWarning)
+}
+
+func parseCLIDuration(field, s string) (time.Duration, bool, error) {
+	if s == "" {
+		return 0, false, nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, false, errors.Errorf("x-cli.%s: expected a duration such as 5m, got %q", field, s)
+	}
+	return v, true, nil
+}
@@ -0,0 +1,54 @@
+package definitions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var cliYAML = []byte(
+	`name: Hello World
+slug: hello_world
+python:
+  entrypoint: hello_world.py
+x-cli:
+  output: json
+  logSinks:
+  - webhook:https://hooks.example.com/ops
+  stallWarning: 30m
+`)
+
+func TestCLIDefaults(t *testing.T) {
+	expected := &CLIDefaults{
+		Output:       "json",
+		LogSinks:     []string{"webhook:https://hooks.example.com/ops"},
+		StallWarning: "30m",
+	}
+
+	t.Run("0.2", func(t *testing.T) {
+		def, err := UnmarshalDefinition(cliYAML, "airplane.yml")
+		require.NoError(t, err)
+		require.Equal(t, expected, def.CLI)
+	})
+
+	t.Run("0.3", func(t *testing.T) {
+		var def Definition_0_3
+		require.NoError(t, def.Unmarshal(TaskDefFormatYAML, cliYAML))
+		require.Equal(t, expected, def.CLI)
+	})
+
+	t.Run("durations", func(t *testing.T) {
+		_, ok, err := expected.HeartbeatDuration()
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		v, ok, err := expected.StallWarningDuration()
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, 30*time.Minute, v)
+
+		_, _, err = CLIDefaults{Heartbeat: "often"}.HeartbeatDuration()
+		require.Error(t, err)
+	})
+}
@@ -1,6 +1,8 @@
 package definitions
 
 import (
+	"fmt"
+
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/lib/pkg/build"
 	"github.com/mitchellh/mapstructure"
@@ -21,7 +23,7 @@ type Definition_0_1 struct {
 	Builder        string             `yaml:"builder,omitempty"`
 	BuilderConfig  build.KindOptions  `yaml:"builderConfig,omitempty"`
 	Repo           string             `yaml:"repo,omitempty"`
-	Timeout        int                `yaml:"timeout,omitempty"`
+	Timeout        api.Duration       `yaml:"timeout,omitempty"`
 
 	// Root is a directory path relative to the parent directory of this
 	// task definition which defines what directory should be included
@@ -85,5 +87,32 @@ func (d Definition_0_1) upgrade() (Definition, error) {
 
 	}
 
+	def.deprecations = d.deprecations()
+
 	return def.upgrade()
 }
+
+// deprecations returns the fields of the 0.1 format that the definition
+// uses and that were dropped or replaced in later formats.
+func (d Definition_0_1) deprecations() []Deprecation {
+	var deprecations []Deprecation
+	if d.Builder != "" {
+		deprecations = append(deprecations, Deprecation{
+			Field:       "builder",
+			Replacement: fmt.Sprintf("Move builderConfig to a %s section instead, such as %s: {entrypoint: ...}.", d.Builder, d.Builder),
+		})
+	}
+	if len(d.Command) > 0 && d.Builder != "image" {
+		deprecations = append(deprecations, Deprecation{
+			Field:       "command",
+			Replacement: "It only applies to the image builder, and is ignored. Set the builder's entrypoint instead.",
+		})
+	}
+	if len(d.ResourceLimits) > 0 {
+		deprecations = append(deprecations, Deprecation{
+			Field:       "resourceLimits",
+			Replacement: "It is ignored. Use resourceRequests instead.",
+		})
+	}
+	return deprecations
+}
@@ -5,17 +5,24 @@ import (
 )
 
 type Definition_0_2 struct {
-	Slug             string               `yaml:"slug"`
-	Name             string               `yaml:"name"`
-	Description      string               `yaml:"description,omitempty"`
-	Arguments        []string             `yaml:"arguments,omitempty"`
-	Parameters       api.Parameters       `yaml:"parameters,omitempty"`
-	Constraints      api.RunConstraints   `yaml:"constraints,omitempty"`
-	Env              api.TaskEnv          `yaml:"env,omitempty"`
-	ResourceRequests api.ResourceRequests `yaml:"resourceRequests,omitempty"`
-	Resources        api.Resources        `yaml:"resources,omitempty"`
-	Repo             string               `yaml:"repo,omitempty"`
-	Timeout          int                  `yaml:"timeout,omitempty"`
+	Slug             string                        `yaml:"slug"`
+	Name             string                        `yaml:"name"`
+	Description      string                        `yaml:"description,omitempty"`
+	Arguments        []string                      `yaml:"arguments,omitempty"`
+	Parameters       api.Parameters                `yaml:"parameters,omitempty"`
+	Form             *api.Form                     `yaml:"form,omitempty"`
+	Constraints      api.RunConstraints            `yaml:"constraints,omitempty"`
+	Env              api.TaskEnv                   `yaml:"env,omitempty"`
+	EnvGroups        []string                      `yaml:"envGroups,omitempty"`
+	ResourceRequests api.ResourceRequests          `yaml:"resourceRequests,omitempty"`
+	Resources        api.Resources                 `yaml:"resources,omitempty"`
+	Repo             string                        `yaml:"repo,omitempty"`
+	Timeout          api.Duration                  `yaml:"timeout,omitempty"`
+	Concurrency      *api.Concurrency              `yaml:"concurrency,omitempty"`
+	Confirm          string                        `yaml:"confirm,omitempty"`
+	Dependencies     *api.Dependencies             `yaml:"dependencies,omitempty"`
+	Schedules        map[string]ScheduleDefinition `yaml:"schedules,omitempty"`
+	CLI              *CLIDefaults                  `yaml:"x-cli,omitempty"`
 
 	Deno       *DenoDefinition       `yaml:"deno,omitempty"`
 	Image      *ImageDefinition      `yaml:"image,omitempty"`
@@ -36,6 +43,10 @@ type Definition_0_2 struct {
 	//
 	// This field is ignored when using the "image" builder.
 	Root string `yaml:"root,omitempty"`
+
+	// deprecations are the deprecated fields of an older definition format
+	// that this definition was upgraded from.
+	deprecations []Deprecation
 }
 
 type ImageDefinition struct {
@@ -1,11 +1,14 @@
 package definitions
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/lib/pkg/build"
@@ -19,6 +22,7 @@ type Definition_0_3 struct {
 	Slug        string                    `json:"slug"`
 	Description string                    `json:"description,omitempty"`
 	Parameters  []ParameterDefinition_0_3 `json:"parameters,omitempty"`
+	Form        *api.Form                 `json:"form,omitempty"`
 
 	Deno       *DenoDefinition_0_3       `json:"deno,omitempty"`
 	Dockerfile *DockerfileDefinition_0_3 `json:"dockerfile,omitempty"`
@@ -33,12 +37,21 @@ type Definition_0_3 struct {
 
 	Permissions *PermissionDefinition_0_3 `json:"permissions,omitempty"`
 	Constraints *api.RunConstraints       `json:"constraints,omitempty"`
+	EnvGroups   []string                  `json:"envGroups,omitempty"`
 	// TODO: default 3600
-	Timeout int `json:"timeout,omitempty"`
+	Timeout     api.Duration     `json:"timeout,omitempty"`
+	Concurrency *api.Concurrency `json:"concurrency,omitempty"`
+	Confirm     string           `json:"confirm,omitempty"`
+	// Dependencies declares configs, resources and tasks the task relies on
+	// that its definition doesn't already reference.
+	Dependencies *api.Dependencies `json:"dependencies,omitempty"`
+	// Schedules are the task's schedules, keyed by slug.
+	Schedules map[string]ScheduleDefinition `json:"schedules,omitempty"`
+	CLI       *CLIDefaults                  `json:"x-cli,omitempty"`
 }
 
 type taskKind_0_3 interface {
-	fillInUpdateTaskRequest(context.Context, *api.Client, *api.UpdateTaskRequest) error
+	fillInUpdateTaskRequest(context.Context, api.APIClient, *api.UpdateTaskRequest) error
 	upgradeJST() error
 	getKindOptions() (build.KindOptions, error)
 	getEntrypoint() (string, error)
@@ -55,7 +68,7 @@ type ImageDefinition_0_3 struct {
 	Env     api.TaskEnv `json:"env,omitempty"`
 }
 
-func (d *ImageDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *ImageDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	req.Image = &d.Image
 	req.Command = d.Command
 	return nil
@@ -91,7 +104,7 @@ type DenoDefinition_0_3 struct {
 	Env       api.TaskEnv `json:"env,omitempty"`
 }
 
-func (d *DenoDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *DenoDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	req.Arguments = d.Arguments
 	return nil
 }
@@ -127,7 +140,7 @@ type DockerfileDefinition_0_3 struct {
 	Env        api.TaskEnv `json:"env,omitempty"`
 }
 
-func (d *DockerfileDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *DockerfileDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	return nil
 }
 
@@ -163,7 +176,7 @@ type GoDefinition_0_3 struct {
 	Env       api.TaskEnv `json:"env,omitempty"`
 }
 
-func (d *GoDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *GoDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	req.Arguments = d.Arguments
 	return nil
 }
@@ -202,7 +215,7 @@ type NodeDefinition_0_3 struct {
 	Env       api.TaskEnv `json:"env,omitempty"`
 }
 
-func (d *NodeDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *NodeDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	req.Arguments = d.Arguments
 	return nil
 }
@@ -241,7 +254,7 @@ type PythonDefinition_0_3 struct {
 	Env       api.TaskEnv `json:"env,omitempty"`
 }
 
-func (d *PythonDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *PythonDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	req.Arguments = d.Arguments
 	return nil
 }
@@ -279,7 +292,7 @@ type ShellDefinition_0_3 struct {
 	Env       api.TaskEnv `json:"env,omitempty"`
 }
 
-func (d *ShellDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *ShellDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	req.Arguments = d.Arguments
 	return nil
 }
@@ -315,7 +328,7 @@ type SQLDefinition_0_3 struct {
 	Parameters map[string]interface{} `json:"parameters,omitempty"`
 }
 
-func (d *SQLDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *SQLDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	resourcesByName, err := getResourcesByName(ctx, client)
 	if err != nil {
 		return err
@@ -370,7 +383,7 @@ type RESTDefinition_0_3 struct {
 	FormData  map[string]interface{} `json:"formData,omitempty"`
 }
 
-func (d *RESTDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d *RESTDefinition_0_3) fillInUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	resourcesByName, err := getResourcesByName(ctx, client)
 	if err != nil {
 		return err
@@ -422,6 +435,7 @@ type ParameterDefinition_0_3 struct {
 	// TODO: default to true
 	Required bool                   `json:"required,omitempty"`
 	Options  []OptionDefinition_0_3 `json:"options,omitempty"`
+	Multi    bool                   `json:"multi,omitempty"`
 }
 
 type OptionDefinition_0_3 struct {
@@ -512,6 +526,91 @@ func NewDefinition_0_3(name string, slug string, kind build.TaskKind, entrypoint
 	return def, nil
 }
 
+// NewDefinitionFromTask_0_3 creates a definition from an existing task, using
+// entrypoint in place of the task's own. Resources are not carried over since
+// they are referenced by name in definitions but by ID on tasks.
+func NewDefinitionFromTask_0_3(task api.Task, entrypoint string) (Definition_0_3, error) {
+	def, err := NewDefinition_0_3(task.Name, task.Slug, task.Kind, entrypoint)
+	if err != nil {
+		return Definition_0_3{}, err
+	}
+
+	def.Description = task.Description
+	def.Form = task.Form
+	def.EnvGroups = task.EnvGroups
+	def.Timeout = task.Timeout
+	def.Concurrency = task.Concurrency
+	def.Confirm = task.Confirm
+	def.Dependencies = task.Dependencies
+	if !task.Constraints.IsEmpty() {
+		constraints := task.Constraints
+		def.Constraints = &constraints
+	}
+
+	def.Parameters = make([]ParameterDefinition_0_3, len(task.Parameters))
+	for i, p := range task.Parameters {
+		pd := ParameterDefinition_0_3{
+			Name:        p.Name,
+			Slug:        p.Slug,
+			Description: p.Desc,
+			Default:     p.Default,
+			Required:    !p.Constraints.Optional,
+			Multi:       p.Multi,
+		}
+
+		switch {
+		case p.Type == api.TypeString && p.Component == api.ComponentTextarea:
+			pd.Type = "longtext"
+		case p.Type == api.TypeString && p.Component == api.ComponentEditorSQL:
+			pd.Type = "sql"
+		case p.Type == api.TypeString:
+			pd.Type = "shorttext"
+		default:
+			pd.Type = string(p.Type)
+		}
+
+		for _, o := range p.Constraints.Options {
+			pd.Options = append(pd.Options, OptionDefinition_0_3{
+				Label: o.Label,
+				Value: fmt.Sprint(o.Value),
+			})
+		}
+
+		def.Parameters[i] = pd
+	}
+
+	switch {
+	case def.Deno != nil:
+		def.Deno.Arguments = task.Arguments
+		def.Deno.Env = task.Env
+	case def.Dockerfile != nil:
+		def.Dockerfile.Env = task.Env
+	case def.Go != nil:
+		def.Go.Arguments = task.Arguments
+		def.Go.Env = task.Env
+	case def.Image != nil:
+		if task.Image != nil {
+			def.Image.Image = *task.Image
+		}
+		def.Image.Command = task.Command
+		def.Image.Env = task.Env
+	case def.Node != nil:
+		if v, ok := task.KindOptions["nodeVersion"].(string); ok {
+			def.Node.NodeVersion = v
+		}
+		def.Node.Arguments = task.Arguments
+		def.Node.Env = task.Env
+	case def.Python != nil:
+		def.Python.Arguments = task.Arguments
+		def.Python.Env = task.Env
+	case def.Shell != nil:
+		def.Shell.Arguments = task.Arguments
+		def.Shell.Env = task.Env
+	}
+
+	return def, nil
+}
+
 func (d Definition_0_3) Marshal(format TaskDefFormat) ([]byte, error) {
 	buf, err := json.MarshalIndent(d, "", "\t")
 	if err != nil {
@@ -533,7 +632,20 @@ func (d Definition_0_3) Marshal(format TaskDefFormat) ([]byte, error) {
 	return buf, nil
 }
 
+// Unmarshal reads a task definition in the given format. Fields that aren't
+// part of the definition format are ignored.
 func (d *Definition_0_3) Unmarshal(format TaskDefFormat, buf []byte) error {
+	return d.unmarshal(format, buf, false)
+}
+
+// UnmarshalStrict is like Unmarshal, but rejects fields that aren't part of
+// the definition format, such as a misspelled "paramters", instead of
+// ignoring them.
+func (d *Definition_0_3) UnmarshalStrict(format TaskDefFormat, buf []byte) error {
+	return d.unmarshal(format, buf, true)
+}
+
+func (d *Definition_0_3) unmarshal(format TaskDefFormat, buf []byte, strict bool) error {
 	var err error
 	switch format {
 	case TaskDefFormatYAML:
@@ -559,9 +671,30 @@ func (d *Definition_0_3) Unmarshal(format TaskDefFormat, buf []byte) error {
 		return errors.WithStack(ErrSchemaValidation{Errors: result.Errors()})
 	}
 
-	if err = json.Unmarshal(buf, &d); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	if err = dec.Decode(&d); err != nil {
+		if strict && strings.HasPrefix(err.Error(), "json: unknown field ") {
+			return errors.Errorf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+		}
+		return err
+	}
+
+	// The schema bounds timeouts given as numbers, but not as strings such
+	// as "2h", so both are checked here. A timeout that's set can't be zero.
+	var timeout struct {
+		Timeout *api.Duration `json:"timeout"`
+	}
+	if err := json.Unmarshal(buf, &timeout); err != nil {
 		return err
 	}
+	if timeout.Timeout != nil {
+		if err := timeout.Timeout.ValidateTimeout(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -613,12 +746,17 @@ func (d Definition_0_3) taskKind() (taskKind_0_3, error) {
 	}
 }
 
-func (d Definition_0_3) GetUpdateTaskRequest(ctx context.Context, client *api.Client, image *string) (api.UpdateTaskRequest, error) {
+func (d Definition_0_3) GetUpdateTaskRequest(ctx context.Context, client api.APIClient, image *string) (api.UpdateTaskRequest, error) {
 	req := api.UpdateTaskRequest{
-		Slug:        d.Slug,
-		Name:        d.Name,
-		Description: d.Description,
-		Timeout:     d.Timeout,
+		Slug:         d.Slug,
+		Name:         d.Name,
+		Description:  d.Description,
+		Form:         d.Form,
+		EnvGroups:    d.EnvGroups,
+		Timeout:      d.Timeout,
+		Concurrency:  d.Concurrency,
+		Confirm:      d.Confirm,
+		Dependencies: d.Dependencies,
 	}
 
 	if image != nil {
@@ -644,14 +782,25 @@ func (d Definition_0_3) GetUpdateTaskRequest(ctx context.Context, client *api.Cl
 	return req, nil
 }
 
-func (d Definition_0_3) addParametersToUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
-	req.Parameters = make([]api.Parameter, len(d.Parameters))
+func (d Definition_0_3) addParametersToUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
+	params, err := d.APIParameters()
+	if err != nil {
+		return err
+	}
+	req.Parameters = params
+	return nil
+}
+
+// APIParameters converts the definition's parameters to API parameters.
+func (d Definition_0_3) APIParameters() (api.Parameters, error) {
+	params := make(api.Parameters, len(d.Parameters))
 	for i, pd := range d.Parameters {
 		param := api.Parameter{
 			Name:    pd.Name,
 			Slug:    pd.Slug,
 			Desc:    pd.Description,
 			Default: pd.Default,
+			Multi:   pd.Multi,
 		}
 
 		switch pd.Type {
@@ -665,8 +814,13 @@ func (d Definition_0_3) addParametersToUpdateTaskRequest(ctx context.Context, cl
 			param.Component = api.ComponentEditorSQL
 		case "boolean", "upload", "integer", "float", "date", "datetime", "configvar":
 			param.Type = api.Type(pd.Type)
+		case "enum":
+			if len(pd.Options) == 0 {
+				return nil, errors.Errorf("parameter %s: enum parameters require options", pd.Slug)
+			}
+			param.Type = api.TypeEnum
 		default:
-			return errors.Errorf("unknown parameter type: %s", pd.Type)
+			return nil, errors.Errorf("unknown parameter type: %s", pd.Type)
 		}
 
 		if !pd.Required {
@@ -681,12 +835,12 @@ func (d Definition_0_3) addParametersToUpdateTaskRequest(ctx context.Context, cl
 			}
 		}
 
-		req.Parameters[i] = param
+		params[i] = param
 	}
-	return nil
+	return params, nil
 }
 
-func (d Definition_0_3) addPermissionsToUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d Definition_0_3) addPermissionsToUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	if d.Permissions != nil && !d.Permissions.isEmpty() {
 		req.RequireExplicitPermissions = true
 		// TODO: convert permissions.
@@ -694,7 +848,7 @@ func (d Definition_0_3) addPermissionsToUpdateTaskRequest(ctx context.Context, c
 	return nil
 }
 
-func (d Definition_0_3) addKindSpecificsToUpdateTaskRequest(ctx context.Context, client *api.Client, req *api.UpdateTaskRequest) error {
+func (d Definition_0_3) addKindSpecificsToUpdateTaskRequest(ctx context.Context, client api.APIClient, req *api.UpdateTaskRequest) error {
 	resourcesByName := map[string]api.Resource{}
 	if d.SQL != nil || d.REST != nil {
 		// Remap resources from ref -> name to ref -> id.
@@ -791,7 +945,15 @@ func (d *Definition_0_3) GetSlug() string {
 	return d.Slug
 }
 
-func getResourcesByName(ctx context.Context, client *api.Client) (map[string]api.Resource, error) {
+func (d *Definition_0_3) GetSchedules() map[string]ScheduleDefinition {
+	return d.Schedules
+}
+
+func (d *Definition_0_3) GetCLIDefaults() *CLIDefaults {
+	return d.CLI
+}
+
+func getResourcesByName(ctx context.Context, client api.APIClient) (map[string]api.Resource, error) {
 	// Remap resources from ref -> name to ref -> id.
 	resp, err := client.ListResources(ctx)
 	if err != nil {
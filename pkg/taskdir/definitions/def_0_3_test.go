@@ -1,8 +1,10 @@
 package definitions
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/airplanedev/cli/pkg/api"
 	"github.com/stretchr/testify/require"
 )
 
@@ -100,5 +102,43 @@ func TestDefinition_0_3(t *testing.T) {
 		assert.Equal(fullDef, d)
 	})
 
+	t.Run("duration timeout", func(t *testing.T) {
+		assert := require.New(t)
+		d := Definition_0_3{}
+		err := d.Unmarshal(TaskDefFormatYAML, bytes.Replace(fullYAML, []byte("timeout: 3600"), []byte("timeout: 1h"), 1))
+		assert.NoError(err)
+		assert.Equal(fullDef, d)
+	})
+
+	t.Run("timeout bounds", func(t *testing.T) {
+		for _, timeout := range []string{"1", "1s", "60m", "3600", "1h"} {
+			d := Definition_0_3{}
+			err := d.Unmarshal(TaskDefFormatYAML, bytes.Replace(fullYAML, []byte("timeout: 3600"), []byte("timeout: "+timeout), 1))
+			require.NoError(t, err, timeout)
+		}
+		for _, timeout := range []string{"0", "0s", "3601", "61m", "2h", "7200", "-5", "-5m", `"-5m"`} {
+			d := Definition_0_3{}
+			err := d.Unmarshal(TaskDefFormatYAML, bytes.Replace(fullYAML, []byte("timeout: 3600"), []byte("timeout: "+timeout), 1))
+			require.Error(t, err, timeout)
+		}
+	})
+
+	t.Run("enum parameters", func(t *testing.T) {
+		assert := require.New(t)
+		d := Definition_0_3{
+			Parameters: []ParameterDefinition_0_3{
+				{Slug: "env", Type: "enum", Options: []OptionDefinition_0_3{{Value: "prod"}, {Value: "staging"}}},
+			},
+		}
+		params, err := d.APIParameters()
+		assert.NoError(err)
+		assert.Equal(api.TypeEnum, params[0].Type)
+		assert.Len(params[0].Constraints.Options, 2)
+
+		d.Parameters[0].Options = nil
+		_, err = d.APIParameters()
+		assert.EqualError(err, "parameter env: enum parameters require options")
+	})
+
 	// TODO: add tests for non-zero defaults.
 }
@@ -1,9 +1,11 @@
 package definitions
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -31,11 +33,16 @@ func NewDefinitionFromTask(task api.Task) (Definition, error) {
 		Description:      task.Description,
 		Arguments:        task.Arguments,
 		Parameters:       task.Parameters,
+		Form:             task.Form,
 		Constraints:      task.Constraints,
 		Env:              task.Env,
+		EnvGroups:        task.EnvGroups,
 		ResourceRequests: task.ResourceRequests,
 		Repo:             task.Repo,
 		Timeout:          task.Timeout,
+		Concurrency:      task.Concurrency,
+		Confirm:          task.Confirm,
+		Dependencies:     task.Dependencies,
 	}
 
 	var taskDef interface{}
@@ -284,7 +291,15 @@ func (def *Definition) GetSlug() string {
 	return def.Slug
 }
 
-func (def *Definition) GetUpdateTaskRequest(ctx context.Context, client *api.Client, image *string) (api.UpdateTaskRequest, error) {
+func (def *Definition) GetSchedules() map[string]ScheduleDefinition {
+	return def.Schedules
+}
+
+func (def *Definition) GetCLIDefaults() *CLIDefaults {
+	return def.CLI
+}
+
+func (def *Definition) GetUpdateTaskRequest(ctx context.Context, client api.APIClient, image *string) (api.UpdateTaskRequest, error) {
 	kind, options, err := def.GetKindAndOptions()
 	if err != nil {
 		return api.UpdateTaskRequest{}, err
@@ -298,18 +313,36 @@ func (def *Definition) GetUpdateTaskRequest(ctx context.Context, client *api.Cli
 		Command:          []string{},
 		Arguments:        def.Arguments,
 		Parameters:       def.Parameters,
+		Form:             def.Form,
 		Constraints:      def.Constraints,
 		Env:              def.Env,
+		EnvGroups:        def.EnvGroups,
 		ResourceRequests: def.ResourceRequests,
 		Resources:        def.Resources,
 		Kind:             kind,
 		KindOptions:      options,
 		Repo:             def.Repo,
 		Timeout:          def.Timeout,
+		Concurrency:      def.Concurrency,
+		Confirm:          def.Confirm,
+		Dependencies:     def.Dependencies,
 	}, nil
 }
 
+// UnmarshalDefinition reads an airplane.yml definition. Fields that aren't
+// part of the definition format are ignored.
 func UnmarshalDefinition(buf []byte, defPath string) (Definition, error) {
+	return unmarshalDefinition(buf, defPath, false)
+}
+
+// UnmarshalDefinitionStrict is like UnmarshalDefinition, but rejects fields
+// that aren't part of the definition format, such as a misspelled
+// "paramters", instead of ignoring them.
+func UnmarshalDefinitionStrict(buf []byte, defPath string) (Definition, error) {
+	return unmarshalDefinition(buf, defPath, true)
+}
+
+func unmarshalDefinition(buf []byte, defPath string, strict bool) (Definition, error) {
 	// Validate definition against our Definition struct
 	if err := validateYAML(buf, Definition{}); err != nil {
 		// Try older definitions?
@@ -333,7 +366,13 @@ func UnmarshalDefinition(buf []byte, defPath string) (Definition, error) {
 	}
 
 	var def Definition
-	if err := yaml.Unmarshal(buf, &def); err != nil {
+	dec := yaml.NewDecoder(bytes.NewReader(buf))
+	dec.KnownFields(strict)
+	if err := dec.Decode(&def); err != nil && err != io.EOF {
+		var terr *yaml.TypeError
+		if strict && errors.As(err, &terr) {
+			return Definition{}, newErrReadDefinition(fmt.Sprintf("Error reading %s, unknown fields", defPath), terr.Errors...)
+		}
 		return Definition{}, errors.Wrap(err, "unmarshalling task definition")
 	}
 
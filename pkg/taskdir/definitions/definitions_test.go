@@ -0,0 +1,48 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalDefinitionStrict(t *testing.T) {
+	var buf = []byte(`slug: hello
+name: Hello
+node:
+  entrypoint: main.js
+  language: javascript
+  paramters: []
+`)
+
+	t.Run("valid", func(t *testing.T) {
+		def, err := UnmarshalDefinitionStrict(cliYAML, "airplane.yml")
+		require.NoError(t, err)
+		require.Equal(t, "hello_world", def.Slug)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := UnmarshalDefinitionStrict(buf, "airplane.yml")
+		require.Error(t, err)
+		var rerr errReadDefinition
+		require.True(t, errors.As(err, &rerr))
+		require.Contains(t, rerr.ExplainError(), "paramters")
+	})
+}
+
+func TestDefinition_0_3Strict(t *testing.T) {
+	var buf = []byte(`name: Hello World
+slug: hello_world
+paramters: []
+python:
+  entrypoint: hello_world.py
+`)
+
+	var def Definition_0_3
+	require.NoError(t, def.Unmarshal(TaskDefFormatYAML, buf))
+
+	require.NoError(t, (&Definition_0_3{}).UnmarshalStrict(TaskDefFormatYAML, fullYAML))
+	err := (&Definition_0_3{}).UnmarshalStrict(TaskDefFormatYAML, buf)
+	require.EqualError(t, err, `unknown field "paramters"`)
+}
@@ -0,0 +1,136 @@
+package definitions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/airplanedev/lib/pkg/build"
+)
+
+// Deprecation is a deprecated field in use, with what to use instead.
+type Deprecation struct {
+	// Field is the path of the field, such as node.arguments.
+	Field       string `json:"field" yaml:"field"`
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("%s is deprecated and will be removed. %s", d.Field, d.Replacement)
+}
+
+// WarnDeprecations warns about each deprecated field that name, a definition
+// path or task slug, uses.
+func WarnDeprecations(name string, deprecations []Deprecation) {
+	for _, d := range deprecations {
+		logger.Warning("%s: %s", name, d)
+	}
+}
+
+// TaskDeprecations returns the deprecated fields a task uses, as returned by
+// the API.
+func TaskDeprecations(task api.Task) []Deprecation {
+	var deprecations []Deprecation
+	if len(task.Command) > 0 && task.Kind != build.TaskKindImage {
+		deprecations = append(deprecations, Deprecation{
+			Field:       "command",
+			Replacement: fmt.Sprintf("It only applies to image tasks, and is ignored for %s tasks. Set the task's entrypoint instead.", task.Kind),
+		})
+	}
+	if task.InterpolationMode != "jst" {
+		deprecations = append(deprecations, argumentDeprecations("arguments", task.Arguments)...)
+	}
+	return deprecations
+}
+
+// templateRegex matches the expressions in an argument, such as {{JSON}},
+// {{#if verbose}} or {{{raw}}}.
+var templateRegex = regexp.MustCompile(`{{{?\s*([^{}]*?)\s*}?}}`)
+
+// jstGlobals are the bare names that JST expressions refer to. Any other bare
+// name is a handlebars parameter reference.
+var jstGlobals = map[string]bool{"params": true, "configs": true, "env": true, "session": true}
+
+var bareNameRegex = regexp.MustCompile(`^[A-Za-z_][\w-]*$`)
+
+// handlebarsExpr reports whether expr, a {{...}} template with the given
+// body, only makes sense as handlebars: a block helper, comment or partial; a
+// triple-stash; a path through this, @ or ..; or a bare parameter name.
+func handlebarsExpr(expr, body string) bool {
+	switch {
+	case strings.HasPrefix(expr, "{{{"):
+		return true
+	case body == "else", strings.HasPrefix(body, "else "):
+		return true
+	case body != "" && strings.ContainsRune("#/^>!@", rune(body[0])):
+		return true
+	case body == "this", strings.HasPrefix(body, "this."), strings.HasPrefix(body, "../"):
+		return true
+	case bareNameRegex.MatchString(body):
+		return !jstGlobals[body]
+	}
+	return false
+}
+
+// argumentDeprecations flags arguments that interpolate parameters with
+// handlebars, which JST replaces. {{JSON}} is flagged on its own, since
+// deploying with --jst upgrades it; other handlebars expressions have to be
+// rewritten by hand.
+func argumentDeprecations(field string, args []string) []Deprecation {
+	var deprecations []Deprecation
+	var json, other bool
+	for _, arg := range args {
+		for _, m := range templateRegex.FindAllStringSubmatch(arg, -1) {
+			expr := m[0]
+			switch {
+			case jsonRegex.MatchString(expr):
+				if !json {
+					json = true
+					deprecations = append(deprecations, Deprecation{
+						Field:       field + " with {{JSON}}",
+						Replacement: "Use {{JSON.stringify(params)}} instead, or deploy with --jst to upgrade it: https://apn.sh/jst-upgrade",
+					})
+				}
+			case handlebarsExpr(expr, m[1]):
+				if !other {
+					other = true
+					deprecations = append(deprecations, Deprecation{
+						Field:       fmt.Sprintf("%s with %s", field, expr),
+						Replacement: "Rewrite handlebars expressions as JS templates, such as {{params.slug}}, and deploy with --jst: https://apn.sh/jst-upgrade",
+					})
+				}
+			}
+		}
+	}
+	return deprecations
+}
+
+// GetDeprecations returns the deprecated fields the definition uses,
+// including fields of older definition formats that were upgraded away.
+func (def *Definition) GetDeprecations() []Deprecation {
+	deprecations := append([]Deprecation(nil), def.deprecations...)
+	return append(deprecations, argumentDeprecations("arguments", def.Arguments)...)
+}
+
+// GetDeprecations returns the deprecated fields the definition uses.
+func (d *Definition_0_3) GetDeprecations() []Deprecation {
+	var deprecations []Deprecation
+	if d.Deno != nil {
+		deprecations = append(deprecations, argumentDeprecations("deno.arguments", d.Deno.Arguments)...)
+	}
+	if d.Go != nil {
+		deprecations = append(deprecations, argumentDeprecations("go.arguments", d.Go.Arguments)...)
+	}
+	if d.Node != nil {
+		deprecations = append(deprecations, argumentDeprecations("node.arguments", d.Node.Arguments)...)
+	}
+	if d.Python != nil {
+		deprecations = append(deprecations, argumentDeprecations("python.arguments", d.Python.Arguments)...)
+	}
+	if d.Shell != nil {
+		deprecations = append(deprecations, argumentDeprecations("shell.arguments", d.Shell.Arguments)...)
+	}
+	return deprecations
+}
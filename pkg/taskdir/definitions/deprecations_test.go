@@ -0,0 +1,90 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecations(t *testing.T) {
+	t.Run("0.1 definition", func(t *testing.T) {
+		def, err := UnmarshalDefinition([]byte(`slug: hello_world
+name: Hello World
+builder: node
+builderConfig:
+  entrypoint: main.js
+  language: javascript
+command: ["node", "main.js"]
+arguments: ["{{JSON}}"]
+`), "airplane.yml")
+		require.NoError(t, err)
+
+		var fields []string
+		for _, d := range def.GetDeprecations() {
+			fields = append(fields, d.Field)
+		}
+		require.Equal(t, []string{"builder", "command", "arguments with {{JSON}}"}, fields)
+	})
+
+	t.Run("task definition", func(t *testing.T) {
+		var def Definition_0_3
+		require.NoError(t, def.Unmarshal(TaskDefFormatYAML, []byte(`name: Hello World
+slug: hello_world
+node:
+  entrypoint: main.ts
+  nodeVersion: "16"
+  arguments: ["{{ JSON }}"]
+`)))
+		deprecations := def.GetDeprecations()
+		require.Len(t, deprecations, 1)
+		require.Equal(t, "node.arguments with {{JSON}}", deprecations[0].Field)
+
+		def.Node.Arguments = []string{"{{JSON.stringify(params)}}"}
+		require.Empty(t, def.GetDeprecations())
+	})
+
+	t.Run("handlebars arguments", func(t *testing.T) {
+		for _, test := range []struct {
+			args   []string
+			fields []string
+		}{
+			{args: []string{"--name", "{{name}}"}, fields: []string{"arguments with {{name}}"}},
+			{args: []string{"{{#if verbose}}-v{{/if}}"}, fields: []string{"arguments with {{#if verbose}}"}},
+			{args: []string{"{{{ raw }}}"}, fields: []string{"arguments with {{{ raw }}}"}},
+			{args: []string{"{{@root.name}}"}, fields: []string{"arguments with {{@root.name}}"}},
+			{args: []string{"{{this.name}}"}, fields: []string{"arguments with {{this.name}}"}},
+			{
+				args:   []string{"{{JSON}}", "{{ name }}", "{{other}}"},
+				fields: []string{"arguments with {{JSON}}", "arguments with {{ name }}"},
+			},
+			// JST expressions.
+			{args: []string{"{{params.name}}", "{{JSON.stringify(params)}}", "{{params.n + 1}}", "{{env}}"}},
+			{args: []string{"no templates", "{}"}},
+		} {
+			var fields []string
+			for _, d := range argumentDeprecations("arguments", test.args) {
+				fields = append(fields, d.Field)
+			}
+			require.Equal(t, test.fields, fields, "%q", test.args)
+		}
+	})
+
+	t.Run("format", func(t *testing.T) {
+		d := Deprecation{Field: "builder", Replacement: "Use node instead."}
+		require.Equal(t, "builder is deprecated and will be removed. Use node instead.", d.String())
+	})
+
+	t.Run("task", func(t *testing.T) {
+		deprecations := TaskDeprecations(api.Task{
+			Kind:              "python",
+			Command:           []string{"python", "main.py"},
+			Arguments:         []string{"{{JSON}}"},
+			InterpolationMode: "jst",
+		})
+		require.Len(t, deprecations, 1)
+		require.Equal(t, "command", deprecations[0].Field)
+
+		require.Empty(t, TaskDeprecations(api.Task{Kind: "image", Command: []string{"echo"}}))
+	})
+}
@@ -0,0 +1,53 @@
+package definitions
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// descriptionFilePrefix marks a description that is kept in a file, such as
+// `description: file:./README.md`.
+const descriptionFilePrefix = "file:"
+
+// MaxDescriptionLength is the most characters of a description that are
+// uploaded. Longer descriptions are truncated.
+const MaxDescriptionLength = 10000
+
+// IsDescriptionFile reports whether desc references a file.
+func IsDescriptionFile(desc string) bool {
+	return strings.HasPrefix(desc, descriptionFilePrefix)
+}
+
+// ReadDescription returns desc, or the contents of the file it references if
+// it is written as file:<path>, with paths relative to dir. It reports
+// whether the description was truncated to MaxDescriptionLength.
+func ReadDescription(desc, dir string) (string, bool, error) {
+	if !IsDescriptionFile(desc) {
+		return desc, false, nil
+	}
+
+	path := strings.TrimSpace(strings.TrimPrefix(desc, descriptionFilePrefix))
+	if path == "" {
+		return "", false, errors.New("description: expected a path after file:")
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", false, errors.Wrap(err, "reading description")
+	}
+	if !utf8.Valid(buf) {
+		return "", false, errors.Errorf("description: %s is not valid UTF-8 text", path)
+	}
+
+	s := strings.TrimSpace(string(buf))
+	if utf8.RuneCountInString(s) <= MaxDescriptionLength {
+		return s, false, nil
+	}
+	return string([]rune(s)[:MaxDescriptionLength]), true, nil
+}
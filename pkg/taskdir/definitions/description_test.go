@@ -0,0 +1,48 @@
+package definitions
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDescription(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# Hello\n\nSays hello.\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "long.md"), []byte(strings.Repeat("é", MaxDescriptionLength+1)), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "binary.md"), []byte{0xff, 0xfe}, 0644))
+
+	t.Run("inline", func(t *testing.T) {
+		s, truncated, err := ReadDescription("Says hello.", dir)
+		require.NoError(t, err)
+		require.False(t, truncated)
+		require.Equal(t, "Says hello.", s)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		s, truncated, err := ReadDescription("file:./README.md", dir)
+		require.NoError(t, err)
+		require.False(t, truncated)
+		require.Equal(t, "# Hello\n\nSays hello.", s)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		s, truncated, err := ReadDescription("file:long.md", dir)
+		require.NoError(t, err)
+		require.True(t, truncated)
+		require.Equal(t, strings.Repeat("é", MaxDescriptionLength), s)
+	})
+
+	t.Run("not text", func(t *testing.T) {
+		_, _, err := ReadDescription("file:binary.md", dir)
+		require.Error(t, err)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, _, err := ReadDescription("file:nope.md", dir)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,215 @@
+package definitions
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/logger"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Change is a difference between two definitions of a task.
+type Change struct {
+	// Path is the field that changed, such as "image.image", "env.API_KEY"
+	// or "parameters.name". Parameters are identified by their slug.
+	Path string `json:"path" yaml:"path"`
+	// Old and New are the field's values, or nil if it was added or removed.
+	Old interface{} `json:"old,omitempty" yaml:"old,omitempty"`
+	New interface{} `json:"new,omitempty" yaml:"new,omitempty"`
+}
+
+// Added reports whether the field was added.
+func (c Change) Added() bool {
+	return c.Old == nil && c.New != nil
+}
+
+// Removed reports whether the field was removed.
+func (c Change) Removed() bool {
+	return c.Old != nil && c.New == nil
+}
+
+// String formats the change on a single line, such as "+ parameters.dry: ..."
+// for an added field or "~ timeout: 600 -> 300" for a changed one.
+func (c Change) String() string {
+	switch {
+	case c.Added():
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.New))
+	case c.Removed():
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, formatValue(c.Old), formatValue(c.New))
+	}
+}
+
+// Colored formats the change as String does, in green if the field was
+// added, red if it was removed or yellow if it changed.
+func (c Change) Colored() string {
+	switch {
+	case c.Added():
+		return logger.Green("%s", c)
+	case c.Removed():
+		return logger.Red("%s", c)
+	default:
+		return logger.Yellow("%s", c)
+	}
+}
+
+// formatValue formats a field's value on a single line.
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(buf)
+}
+
+// Diff returns the fields that differ between from and to, ordered by path.
+//
+// Both definitions are compared in canonical form, so formatting and empty
+// fields don't count as changes.
+func Diff(from, to Definition) ([]Change, error) {
+	a, err := canonicalValue(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := canonicalValue(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	diffValues("", a, b, &changes)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// DiffTask returns the fields that deploying def, whose root is root, would
+// change in task, or in a new task if task is nil.
+//
+// def is compared as it would be deployed, with its git template variables
+// expanded. Fields that tasks keep in another form are compared in the same
+// form: the resources of task are named after resources, and schedules,
+// which are deployed separately, and x-cli defaults, which only the CLI
+// reads, are left out.
+func DiffTask(def Definition, root string, task *api.Task, resources []api.Resource) ([]Change, error) {
+	local := def
+	local.Schedules = nil
+	local.CLI = nil
+	// Git variables are expanded in place, so expand a copy.
+	if def.Env != nil {
+		local.Env = make(api.TaskEnv, len(def.Env))
+		for k, v := range def.Env {
+			local.Env[k] = v
+		}
+	}
+	if err := ExpandGitVars(&local, root); err != nil {
+		return nil, err
+	}
+
+	var remote Definition
+	if task != nil {
+		var err error
+		if remote, err = NewDefinitionFromTask(*task); err != nil {
+			return nil, err
+		}
+		names := map[string]string{}
+		for _, r := range resources {
+			names[r.ID] = r.Name
+		}
+		if len(task.Resources) > 0 {
+			remote.Resources = api.Resources{}
+			for ref, id := range task.Resources {
+				name, ok := names[id]
+				if !ok {
+					// Deleted resources are shown by ID.
+					name = id
+				}
+				remote.Resources[ref] = name
+			}
+		}
+	}
+	return Diff(remote, local)
+}
+
+func canonicalValue(def Definition) (interface{}, error) {
+	buf, err := def.MarshalCanonical()
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := yaml.Unmarshal(buf, &v); err != nil {
+		return nil, errors.Wrap(err, "parsing definition")
+	}
+	return v, nil
+}
+
+func diffValues(path string, a, b interface{}, changes *[]Change) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+
+	am, aok := a.(map[string]interface{})
+	bm, bok := b.(map[string]interface{})
+	if aok && bok {
+		keys := map[string]bool{}
+		for k := range am {
+			keys[k] = true
+		}
+		for k := range bm {
+			keys[k] = true
+		}
+		for k := range keys {
+			diffValues(joinPath(path, k), am[k], bm[k], changes)
+		}
+		return
+	}
+
+	// Lists of objects with slugs, such as parameters, are compared by slug
+	// so that reordering or inserting an item doesn't change every other.
+	al, aok := bySlug(a)
+	bl, bok := bySlug(b)
+	if aok && bok {
+		diffValues(path, al, bl, changes)
+		return
+	}
+
+	*changes = append(*changes, Change{Path: path, Old: a, New: b})
+}
+
+// bySlug returns the items of v keyed by slug, if v is a list whose items all
+// have a unique slug.
+func bySlug(v interface{}) (map[string]interface{}, bool) {
+	list, ok := v.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, v == nil
+	}
+	items := map[string]interface{}{}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		slug, ok := m["slug"].(string)
+		if !ok || slug == "" || items[slug] != nil {
+			return nil, false
+		}
+		items[slug] = item
+	}
+	return items, true
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,49 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/airplanedev/cli/pkg/utils/pointers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	from := Definition{
+		Slug:  "hello",
+		Name:  "Hello",
+		Image: &ImageDefinition{Image: "ubuntu:20.04", Command: []string{"echo"}},
+		Parameters: api.Parameters{
+			{Slug: "name", Name: "Name", Type: api.TypeString},
+			{Slug: "count", Name: "Count", Type: api.TypeInteger},
+		},
+		Env: api.TaskEnv{"A": api.EnvVarValue{Value: pointers.String("1")}},
+	}
+	to := from
+	to.Image = &ImageDefinition{Image: "ubuntu:22.04", Command: []string{"echo"}}
+	to.Parameters = api.Parameters{
+		{Slug: "count", Name: "Count", Type: api.TypeInteger},
+		{Slug: "dry", Name: "Dry run", Type: api.TypeBoolean},
+	}
+	to.Env = api.TaskEnv{"B": api.EnvVarValue{Config: pointers.String("b")}}
+
+	changes, err := Diff(from, to)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	require.Equal(t, []string{"env.A", "env.B", "image.image", "parameters.dry", "parameters.name"}, paths)
+	require.True(t, changes[0].Removed())
+	require.True(t, changes[1].Added())
+	require.Equal(t, "ubuntu:20.04", changes[2].Old)
+	require.Equal(t, "ubuntu:22.04", changes[2].New)
+	require.Equal(t, `- env.A: {"value":"1"}`, changes[0].String())
+	require.Equal(t, `+ env.B: {"config":"b"}`, changes[1].String())
+	require.Equal(t, "~ image.image: ubuntu:20.04 -> ubuntu:22.04", changes[2].String())
+
+	changes, err = Diff(from, from)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -0,0 +1,203 @@
+package definitions
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+
+	"github.com/airplanedev/ojson"
+	"github.com/pkg/errors"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// EditParameters rewrites the parameters of the task definition at path with
+// edit, leaving the rest of the file untouched.
+//
+// Parameters that edit leaves unchanged keep their original formatting: in
+// YAML definitions that includes comments, and in JSON definitions key order.
+// The result is validated before it is written.
+func EditParameters(path string, edit func([]ParameterDefinition_0_3) ([]ParameterDefinition_0_3, error)) error {
+	format := GetTaskDefFormat(path)
+	if format == TaskDefFormatUnknown {
+		return errors.Errorf("%s is not a task definition: expected a .task.yaml or .task.json file", path)
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "reading task definition")
+	}
+	var def Definition_0_3
+	if err := def.Unmarshal(format, buf); err != nil {
+		return errors.Wrap(err, "unmarshalling task definition")
+	}
+
+	params, err := edit(append([]ParameterDefinition_0_3(nil), def.Parameters...))
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	if format == TaskDefFormatYAML {
+		out, err = editYAMLParameters(buf, def.Parameters, params)
+	} else {
+		out, err = editJSONParameters(buf, def.Parameters, params)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := (&Definition_0_3{}).Unmarshal(format, out); err != nil {
+		return errors.Wrap(err, "validating edited task definition")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(ioutil.WriteFile(path, out, info.Mode()), "writing task definition")
+}
+
+// unchanged returns the index of p in old if it is there unmodified, or -1.
+func unchanged(old []ParameterDefinition_0_3, p ParameterDefinition_0_3) int {
+	for i, o := range old {
+		if o.Slug == p.Slug && reflect.DeepEqual(o, p) {
+			return i
+		}
+	}
+	return -1
+}
+
+func editYAMLParameters(buf []byte, old, params []ParameterDefinition_0_3) ([]byte, error) {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(buf, &root); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling task definition")
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yamlv3.MappingNode {
+		return nil, errors.New("task definition is not a map")
+	}
+	doc := root.Content[0]
+
+	var oldNodes []*yamlv3.Node
+	var seq *yamlv3.Node
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "parameters" {
+			seq = doc.Content[i+1]
+			oldNodes = seq.Content
+			break
+		}
+	}
+
+	var nodes []*yamlv3.Node
+	for _, p := range params {
+		if i := unchanged(old, p); i >= 0 && i < len(oldNodes) {
+			nodes = append(nodes, oldNodes[i])
+			continue
+		}
+		node, err := toYAMLNode(p)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	switch {
+	case seq != nil:
+		seq.Kind = yamlv3.SequenceNode
+		seq.Tag = "!!seq"
+		seq.Style = 0
+		seq.Content = nodes
+	case len(nodes) > 0:
+		// Add parameters after the description, where init puts them.
+		at := len(doc.Content)
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			if v := doc.Content[i].Value; v == "name" || v == "slug" || v == "description" {
+				at = i + 2
+			}
+		}
+		pair := []*yamlv3.Node{
+			{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: "parameters"},
+			{Kind: yamlv3.SequenceNode, Tag: "!!seq", Content: nodes},
+		}
+		doc.Content = append(doc.Content[:at], append(pair, doc.Content[at:]...)...)
+	}
+
+	var out bytes.Buffer
+	enc := yamlv3.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, errors.Wrap(err, "marshalling task definition")
+	}
+	return out.Bytes(), nil
+}
+
+// toYAMLNode converts p to a block-style YAML node, using its JSON field names.
+func toYAMLNode(p ParameterDefinition_0_3) (*yamlv3.Node, error) {
+	buf, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(buf, &doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	resetStyle(node)
+	return node, nil
+}
+
+// resetStyle drops the flow and quoting styles nodes get when decoded from
+// JSON, so that they are written like the rest of a YAML file.
+func resetStyle(node *yamlv3.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetStyle(n)
+	}
+}
+
+func editJSONParameters(buf []byte, old, params []ParameterDefinition_0_3) ([]byte, error) {
+	var doc ojson.Value
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return nil, errors.Wrap(err, "unmarshalling task definition")
+	}
+	obj, ok := doc.V.(*ojson.Object)
+	if !ok {
+		return nil, errors.New("task definition is not an object")
+	}
+
+	var oldValues []interface{}
+	if v, ok := obj.Get("parameters"); ok {
+		oldValues, _ = v.([]interface{})
+	}
+
+	values := []interface{}{}
+	for _, p := range params {
+		if i := unchanged(old, p); i >= 0 && i < len(oldValues) {
+			values = append(values, oldValues[i])
+			continue
+		}
+		pbuf, err := json.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		var v ojson.Value
+		if err := json.Unmarshal(pbuf, &v); err != nil {
+			return nil, err
+		}
+		values = append(values, v.V)
+	}
+	if _, ok := obj.Get("parameters"); ok || len(values) > 0 {
+		obj.Set("parameters", values)
+	}
+
+	// Match the formatting of Definition_0_3.Marshal.
+	out, err := json.MarshalIndent(obj, "", "\t")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling task definition")
+	}
+	if bytes.HasSuffix(buf, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out, nil
+}
@@ -0,0 +1,102 @@
+package definitions
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditParametersYAML(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "hello.task.yaml")
+	require.NoError(ioutil.WriteFile(path, []byte(`# Greets people.
+name: Hello World
+slug: hello_world
+parameters:
+# Who to greet.
+- name: Name
+  slug: name
+  type: shorttext
+python:
+  entrypoint: hello_world.py
+`), 0644))
+
+	err := EditParameters(path, func(params []ParameterDefinition_0_3) ([]ParameterDefinition_0_3, error) {
+		return append(params, ParameterDefinition_0_3{Name: "Count", Slug: "count", Type: "integer", Required: true}), nil
+	})
+	require.NoError(err)
+
+	buf, err := ioutil.ReadFile(path)
+	require.NoError(err)
+	require.Equal(`# Greets people.
+name: Hello World
+slug: hello_world
+parameters:
+  # Who to greet.
+  - name: Name
+    slug: name
+    type: shorttext
+  - name: Count
+    slug: count
+    type: integer
+    required: true
+python:
+  entrypoint: hello_world.py
+`, string(buf))
+}
+
+func TestEditParametersJSON(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "hello.task.json")
+	require.NoError(ioutil.WriteFile(path, []byte(`{
+	"slug": "hello_world",
+	"name": "Hello World",
+	"parameters": [
+		{"slug": "name", "name": "Name", "type": "shorttext"},
+		{"slug": "count", "name": "Count", "type": "integer"}
+	],
+	"python": {"entrypoint": "hello_world.py"}
+}
+`), 0644))
+
+	err := EditParameters(path, func(params []ParameterDefinition_0_3) ([]ParameterDefinition_0_3, error) {
+		return params[1:], nil
+	})
+	require.NoError(err)
+
+	buf, err := ioutil.ReadFile(path)
+	require.NoError(err)
+	require.Equal(`{
+	"slug": "hello_world",
+	"name": "Hello World",
+	"parameters": [
+		{
+			"slug": "count",
+			"name": "Count",
+			"type": "integer"
+		}
+	],
+	"python": {
+		"entrypoint": "hello_world.py"
+	}
+}
+`, string(buf))
+}
+
+func TestEditParametersInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.task.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("name: Hello World\nslug: hello_world\npython:\n  entrypoint: hello_world.py\n"), 0644))
+
+	err := EditParameters(path, func(params []ParameterDefinition_0_3) ([]ParameterDefinition_0_3, error) {
+		return append(params, ParameterDefinition_0_3{Name: "Bad", Slug: "bad", Type: "nope"}), nil
+	})
+	require.Error(t, err)
+
+	buf, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "name: Hello World\nslug: hello_world\npython:\n  entrypoint: hello_world.py\n", string(buf))
+}
@@ -0,0 +1,169 @@
+package definitions
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/airplanedev/cli/pkg/api"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/pkg/errors"
+)
+
+// gitVarRegex matches git template variables, such as ${{ git.sha }}.
+var gitVarRegex = regexp.MustCompile(`\$\{\{\s*git\.(\w+)\s*\}\}`)
+
+// gitVars are the values of git template variables, describing the commit
+// of the repository being deployed from.
+type gitVars struct {
+	sha    string
+	branch string
+	tag    string
+	dirty  bool
+}
+
+func (v gitVars) lookup(name string) (string, bool) {
+	switch name {
+	case "sha":
+		return v.sha, true
+	case "branch":
+		return v.branch, true
+	case "tag":
+		return v.tag, true
+	case "dirty":
+		return strconv.FormatBool(v.dirty), true
+	default:
+		return "", false
+	}
+}
+
+// ExpandGitVars replaces git template variables in the env var values of def
+// with metadata from the repository containing dir. Outside of a
+// repository, the variables expand to empty strings.
+//
+// Values are replaced in place, so that both the build and the task pick them
+// up. Git is only inspected if a template variable is used.
+func ExpandGitVars(def DefinitionInterface, dir string) error {
+	env, err := def.GetEnv()
+	if err != nil {
+		return err
+	}
+
+	var vars *gitVars
+	for k, v := range env {
+		if v.Value == nil || !gitVarRegex.MatchString(*v.Value) {
+			continue
+		}
+		if vars == nil {
+			gv, err := getGitVars(dir)
+			if err != nil {
+				return errors.Wrap(err, "reading git metadata")
+			}
+			vars = &gv
+		}
+
+		var unknown string
+		value := gitVarRegex.ReplaceAllStringFunc(*v.Value, func(m string) string {
+			name := gitVarRegex.FindStringSubmatch(m)[1]
+			val, ok := vars.lookup(name)
+			if !ok && unknown == "" {
+				unknown = name
+			}
+			return val
+		})
+		if unknown != "" {
+			return errors.Errorf("env var %s: unknown template variable git.%s (expected git.sha, git.branch, git.tag or git.dirty)", k, unknown)
+		}
+		env[k] = api.EnvVarValue{Value: &value}
+	}
+	return nil
+}
+
+// UsedGitVars returns the values of the git template variables that the env
+// vars of def use, by name, or nil if it uses none. Git is only inspected if
+// a template variable is used.
+func UsedGitVars(def DefinitionInterface, dir string) (map[string]string, error) {
+	env, err := def.GetEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	var vars *gitVars
+	var used map[string]string
+	for _, v := range env {
+		if v.Value == nil {
+			continue
+		}
+		for _, m := range gitVarRegex.FindAllStringSubmatch(*v.Value, -1) {
+			if vars == nil {
+				gv, err := getGitVars(dir)
+				if err != nil {
+					return nil, errors.Wrap(err, "reading git metadata")
+				}
+				vars = &gv
+				used = map[string]string{}
+			}
+			if val, ok := vars.lookup(m[1]); ok {
+				used[m[1]] = val
+			}
+		}
+	}
+	return used, nil
+}
+
+// getGitVars reads git template variables from the repository containing dir.
+func getGitVars(dir string) (gitVars, error) {
+	var vars gitVars
+
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return vars, nil
+		}
+		return vars, err
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return vars, err
+	}
+	status, err := w.Status()
+	if err != nil {
+		return vars, err
+	}
+	vars.dirty = !status.IsClean()
+
+	h, err := repo.Head()
+	if err != nil {
+		return vars, err
+	}
+	vars.sha = h.Hash().String()
+	if h.Name().IsBranch() {
+		vars.branch = h.Name().Short()
+	}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return vars, err
+	}
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		target := ref.Hash()
+		// Annotated tags point to a tag object rather than the commit.
+		if tag, err := repo.TagObject(target); err == nil {
+			target = tag.Target
+		}
+		if target == h.Hash() {
+			vars.tag = ref.Name().Short()
+			return storer.ErrStop
+		}
+		return nil
+	})
+	if err != nil {
+		return vars, err
+	}
+
+	return vars, nil
+}
@@ -11,6 +11,14 @@ type DefinitionInterface interface {
 	GetKindAndOptions() (build.TaskKind, build.KindOptions, error)
 	GetEnv() (api.TaskEnv, error)
 	GetSlug() string
+	// GetSchedules returns the definition's schedules block, or nil if it
+	// has none.
+	GetSchedules() map[string]ScheduleDefinition
+	// GetCLIDefaults returns the definition's x-cli block, or nil if it has
+	// none.
+	GetCLIDefaults() *CLIDefaults
+	// GetDeprecations returns the deprecated fields the definition uses.
+	GetDeprecations() []Deprecation
 	UpgradeJST() error
-	GetUpdateTaskRequest(context.Context, *api.Client, *string) (api.UpdateTaskRequest, error)
+	GetUpdateTaskRequest(context.Context, api.APIClient, *string) (api.UpdateTaskRequest, error)
 }
@@ -0,0 +1,46 @@
+package definitions
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ScheduleDefinition is a schedule declared in a task definition's schedules
+// block, keyed by the schedule's slug. Deploying the task creates, updates and
+// deletes the task's schedules to match the block.
+type ScheduleDefinition struct {
+	Name        string `json:"name,omitempty" yaml:"name,omitempty"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// CronExpr is a five-field cron expression, such as "0 9 * * 1-5".
+	CronExpr string `json:"cron" yaml:"cron"`
+	// Timezone is an IANA time zone the cron expression is evaluated in.
+	// Defaults to UTC.
+	Timezone    string                 `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	ParamValues map[string]interface{} `json:"paramValues,omitempty" yaml:"paramValues,omitempty"`
+	Paused      bool                   `json:"paused,omitempty" yaml:"paused,omitempty"`
+}
+
+// ValidateSchedules checks a schedules block, returning the first problem
+// found in slug order.
+func ValidateSchedules(schedules map[string]ScheduleDefinition) error {
+	slugs := make([]string, 0, len(schedules))
+	for slug := range schedules {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	for _, slug := range slugs {
+		s := schedules[slug]
+		if s.CronExpr == "" {
+			return errors.Errorf("schedules.%s: cron is required", slug)
+		}
+		if s.Timezone != "" {
+			if _, err := time.LoadLocation(s.Timezone); err != nil {
+				return errors.Errorf("schedules.%s: unknown timezone %q", slug, s.Timezone)
+			}
+		}
+	}
+	return nil
+}
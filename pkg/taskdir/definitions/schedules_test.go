@@ -0,0 +1,61 @@
+package definitions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedules(t *testing.T) {
+	var buf = []byte(`name: Hello World
+slug: hello_world
+python:
+  entrypoint: hello_world.py
+schedules:
+  weekday_mornings:
+    cron: 0 9 * * 1-5
+    timezone: America/New_York
+    paramValues:
+      name: World
+  nightly:
+    cron: 0 0 * * *
+    paused: true
+`)
+
+	t.Run("task definition", func(t *testing.T) {
+		var def Definition_0_3
+		require.NoError(t, def.Unmarshal(TaskDefFormatYAML, buf))
+		require.Equal(t, map[string]ScheduleDefinition{
+			"weekday_mornings": {
+				CronExpr:    "0 9 * * 1-5",
+				Timezone:    "America/New_York",
+				ParamValues: map[string]interface{}{"name": "World"},
+			},
+			"nightly": {CronExpr: "0 0 * * *", Paused: true},
+		}, def.GetSchedules())
+		require.NoError(t, ValidateSchedules(def.Schedules))
+	})
+
+	t.Run("missing cron", func(t *testing.T) {
+		var def Definition_0_3
+		err := def.Unmarshal(TaskDefFormatYAML, []byte(`name: Hello World
+slug: hello_world
+python:
+  entrypoint: hello_world.py
+schedules:
+  nightly:
+    timezone: UTC
+`))
+		require.Error(t, err)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		require.NoError(t, ValidateSchedules(nil))
+		require.EqualError(t, ValidateSchedules(map[string]ScheduleDefinition{
+			"nightly": {},
+		}), "schedules.nightly: cron is required")
+		require.EqualError(t, ValidateSchedules(map[string]ScheduleDefinition{
+			"nightly": {CronExpr: "0 0 * * *", Timezone: "Mars/Olympus_Mons"},
+		}), `schedules.nightly: unknown timezone "Mars/Olympus_Mons"`)
+	})
+}
@@ -1,6 +1,8 @@
 package definitions
 
 import (
+	"encoding/json"
+
 	"github.com/alecthomas/jsonschema"
 	"github.com/pkg/errors"
 	"github.com/xeipuuv/gojsonschema"
@@ -31,8 +33,7 @@ func validateYAML(data []byte, schemaObj interface{}) error {
 		return errors.WithStack(ErrInvalidYAML{Msg: err.Error()})
 	}
 
-	r := &jsonschema.Reflector{PreferYAMLSchema: true}
-	schemaLoader := gojsonschema.NewGoLoader(r.Reflect(schemaObj))
+	schemaLoader := gojsonschema.NewGoLoader(reflectSchema(schemaObj))
 	docLoader := gojsonschema.NewGoLoader(obj)
 
 	result, err := gojsonschema.Validate(schemaLoader, docLoader)
@@ -46,3 +47,20 @@ func validateYAML(data []byte, schemaObj interface{}) error {
 
 	return nil
 }
+
+func reflectSchema(schemaObj interface{}) *jsonschema.Schema {
+	r := &jsonschema.Reflector{PreferYAMLSchema: true}
+	return r.Reflect(schemaObj)
+}
+
+// Schema returns the JSON Schema that airplane.yml definitions are validated
+// against.
+func Schema() ([]byte, error) {
+	return json.MarshalIndent(reflectSchema(Definition{}), "", "  ")
+}
+
+// Schema_0_3 returns the JSON Schema that task definition files, such as
+// my_task.task.yaml, are validated against.
+func Schema_0_3() []byte {
+	return []byte(schemaStr)
+}
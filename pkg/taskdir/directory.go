@@ -16,6 +16,10 @@ type TaskDirectory struct {
 	defPath string
 	// closer is used to clean up TaskDirectory.
 	closer io.Closer
+
+	// Strict rejects fields that aren't part of the definition format when
+	// the definition is read, instead of ignoring them.
+	Strict bool
 }
 
 // New creates a TaskDirectory struct with the (desired) definition file as input
@@ -3,13 +3,13 @@ package taskdir
 import (
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path"
 	"regexp"
 	"strings"
 
 	"github.com/airplanedev/cli/pkg/utils"
+	"github.com/airplanedev/cli/pkg/workspace"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/pkg/errors"
@@ -60,9 +60,9 @@ func openGitHubDirectory(file string) (string, io.Closer, error) {
 		return "", nil, err
 	}
 
-	tmpDir, err := ioutil.TempDir("", "airplane-*")
+	tmpDir, err := workspace.TempDir("github-")
 	if err != nil {
-		return "", nil, errors.Wrap(err, "creating temporary directory")
+		return "", nil, err
 	}
 
 	// TODO: consider using git 2.19's --filter option
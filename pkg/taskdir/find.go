@@ -0,0 +1,61 @@
+package taskdir
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/airplanedev/cli/pkg/taskdir/definitions"
+	"github.com/pkg/errors"
+)
+
+// FindDefinitions returns the task definitions in paths, in order. Files are
+// always included, whereas directories are searched for *.task.yaml,
+// *.task.json and airplane.yml files.
+func FindDefinitions(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading path")
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+
+		var found []string
+		err = filepath.Walk(p, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				if skippedDirs[info.Name()] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if name := info.Name(); definitions.IsTaskDef(path) || name == "airplane.yml" || name == "airplane.yaml" {
+				found = append(found, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "searching %s", p)
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// ReadAnyDefinitionFile reads the task definition in file, whichever format
+// it uses.
+func ReadAnyDefinitionFile(file string) (definitions.DefinitionInterface, error) {
+	dir, err := Open(file, definitions.IsTaskDef(file))
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+	return dir.ReadAnyDefinition()
+}
@@ -0,0 +1,107 @@
+package taskdir
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// skippedDirs are never searched when expanding a "**" pattern or finding
+// definitions.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"__pycache__":  true,
+	".git":         true,
+}
+
+// IsGlob reports whether path is a pattern rather than a path.
+func IsGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// Glob returns the files matching pattern, sorted. Patterns are as in
+// filepath.Match, except that a "**" path segment matches any number of
+// directories, such as ./tasks/**/*.task.yml. It is an error for a pattern to
+// match no files.
+func Glob(pattern string) ([]string, error) {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(pattern)), "/")
+
+	// Only walk from the part of the pattern without wildcards.
+	var root []string
+	for len(segments) > 0 && !IsGlob(segments[0]) {
+		root = append(root, segments[0])
+		segments = segments[1:]
+	}
+	base := filepath.FromSlash(strings.Join(root, "/"))
+	if base == "" {
+		base = "."
+	} else if strings.HasPrefix(pattern, "/") && !strings.HasPrefix(base, string(filepath.Separator)) {
+		base = string(filepath.Separator) + base
+	}
+	if len(segments) == 0 {
+		if _, err := os.Stat(base); err != nil {
+			return nil, errors.Errorf("no files match %s", pattern)
+		}
+		return []string{base}, nil
+	}
+
+	var matches []string
+	err := filepath.Walk(base, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != base && skippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		if ok, err := matchSegments(segments, strings.Split(filepath.ToSlash(rel), "/")); err != nil {
+			return err
+		} else if ok {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "expanding %s", pattern)
+	}
+	if len(matches) == 0 {
+		return nil, errors.Errorf("no files match %s", pattern)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// matchSegments reports whether the segments of a path match the segments of
+// a pattern.
+func matchSegments(pattern, path []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try matching the rest of the pattern at every depth.
+			for i := 0; i <= len(path); i++ {
+				if ok, err := matchSegments(pattern[1:], path[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(path) == 0 {
+			return false, nil
+		}
+		if ok, err := filepath.Match(pattern[0], path[0]); err != nil {
+			return false, errors.Wrap(err, "invalid pattern")
+		} else if !ok {
+			return false, nil
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0, nil
+}
@@ -0,0 +1,55 @@
+package taskdir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.task.yml",
+		"tasks/b.task.yml",
+		"tasks/nested/c.task.yml",
+		"tasks/nested/c.js",
+		"tasks/node_modules/d.task.yml",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	}
+
+	for _, test := range []struct {
+		pattern  string
+		expected []string
+		err      bool
+	}{
+		{pattern: "*.task.yml", expected: []string{"a.task.yml"}},
+		{pattern: "tasks/*.task.yml", expected: []string{"tasks/b.task.yml"}},
+		{pattern: "**/*.task.yml", expected: []string{"a.task.yml", "tasks/b.task.yml", "tasks/nested/c.task.yml"}},
+		{pattern: "tasks/**/*.task.yml", expected: []string{"tasks/b.task.yml", "tasks/nested/c.task.yml"}},
+		{pattern: "tasks/**/c.*", expected: []string{"tasks/nested/c.js", "tasks/nested/c.task.yml"}},
+		{pattern: "**/*.task.json", err: true},
+	} {
+		t.Run(test.pattern, func(t *testing.T) {
+			require := require.New(t)
+			matches, err := Glob(filepath.Join(dir, filepath.FromSlash(test.pattern)))
+			if test.err {
+				require.Error(err)
+				return
+			}
+			require.NoError(err)
+			var rel []string
+			for _, m := range matches {
+				r, err := filepath.Rel(dir, m)
+				require.NoError(err)
+				rel = append(rel, filepath.ToSlash(r))
+			}
+			require.Equal(test.expected, rel)
+		})
+	}
+}
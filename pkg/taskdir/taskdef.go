@@ -9,7 +9,6 @@ import (
 	"github.com/airplanedev/cli/pkg/taskdir/definitions"
 	"github.com/airplanedev/cli/pkg/utils"
 	"github.com/pkg/errors"
-	"gopkg.in/yaml.v3"
 )
 
 func (td TaskDirectory) ReadDefinition() (definitions.Definition, error) {
@@ -28,6 +27,9 @@ func (td TaskDirectory) ReadDefinition() (definitions.Definition, error) {
 		defPath = path
 	}
 
+	if td.Strict {
+		return definitions.UnmarshalDefinitionStrict(buf, defPath)
+	}
 	return definitions.UnmarshalDefinition(buf, defPath)
 }
 
@@ -48,7 +50,11 @@ func (td TaskDirectory) ReadDefinition_0_3() (definitions.Definition_0_3, error)
 	}
 
 	def := definitions.Definition_0_3{}
-	if err := def.Unmarshal(definitions.GetTaskDefFormat(defPath), buf); err != nil {
+	unmarshal := def.Unmarshal
+	if td.Strict {
+		unmarshal = def.UnmarshalStrict
+	}
+	if err := unmarshal(definitions.GetTaskDefFormat(defPath), buf); err != nil {
 		return definitions.Definition_0_3{}, errors.Wrap(err, "unmarshalling task definition")
 	}
 	return def, nil
@@ -66,9 +72,9 @@ func (td TaskDirectory) WriteSlug(slug string) error {
 }
 
 func (td TaskDirectory) WriteDefinition(def definitions.Definition) error {
-	data, err := yaml.Marshal(def)
+	data, err := def.MarshalCanonical()
 	if err != nil {
-		return errors.Wrap(err, "marshalling definition")
+		return err
 	}
 
 	if err := ioutil.WriteFile(td.defPath, data, 0664); err != nil {
@@ -77,3 +83,25 @@ func (td TaskDirectory) WriteDefinition(def definitions.Definition) error {
 
 	return nil
 }
+
+// ReadAnyDefinition reads the task definition, regardless of which version of
+// the definition format it uses.
+func (td TaskDirectory) ReadAnyDefinition() (definitions.DefinitionInterface, error) {
+	if definitions.IsTaskDef(td.defPath) {
+		def, err := td.ReadDefinition_0_3()
+		if err != nil {
+			return nil, err
+		}
+		return &def, nil
+	}
+
+	def, err := td.ReadDefinition()
+	if err != nil {
+		return nil, err
+	}
+	def, err = def.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
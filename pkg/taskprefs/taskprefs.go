@@ -0,0 +1,72 @@
+// Package taskprefs remembers the flags each task was last executed with, so
+// that they don't have to be passed every time.
+//
+// Preferences are stored in the CLI's state directory, keyed by task slug,
+// rather than in the cache, so that clearing the cache doesn't forget them.
+package taskprefs
+
+import (
+	"github.com/airplanedev/cli/pkg/cache"
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/airplanedev/cli/pkg/logger"
+)
+
+// bucket is where preferences are stored. It is resolved on each use, like
+// legacy, so that it follows $HOME.
+func bucket() cache.Bucket {
+	return cache.NewIn(conf.Dir(), "task-prefs")
+}
+
+// legacy is where preferences were stored before they moved out of the
+// cache. They are moved on first read.
+func legacy() cache.Bucket {
+	return cache.New("taskprefs")
+}
+
+// Prefs are the flags remembered for a task.
+type Prefs struct {
+	// Output is the --output format.
+	Output string `json:"output,omitempty" yaml:"output,omitempty"`
+	// LogSinks are the --log-sink destinations the run's logs are copied to.
+	LogSinks []string `json:"logSinks,omitempty" yaml:"logSinks,omitempty"`
+}
+
+// IsEmpty reports whether no flags are remembered.
+func (p Prefs) IsEmpty() bool {
+	return p.Output == "" && len(p.LogSinks) == 0
+}
+
+// Get returns the preferences remembered for the task with slug.
+func Get(slug string) (Prefs, error) {
+	var p Prefs
+	if ok, err := bucket().Get(slug, &p); err != nil || ok {
+		return p, err
+	}
+	if ok, err := legacy().Get(slug, &p); err != nil || !ok {
+		return Prefs{}, nil
+	}
+	if err := Set(slug, p); err != nil {
+		return p, err
+	}
+	if err := legacy().Delete(slug); err != nil {
+		logger.Debug("Removing cached task preferences: %v", err)
+	}
+	return p, nil
+}
+
+// Set remembers p for the task with slug, or forgets the task's preferences
+// if p is empty.
+func Set(slug string, p Prefs) error {
+	if p.IsEmpty() {
+		return Clear(slug)
+	}
+	return bucket().Set(slug, p)
+}
+
+// Clear forgets the preferences remembered for the task with slug.
+func Clear(slug string) error {
+	if err := legacy().Delete(slug); err != nil {
+		logger.Debug("Removing cached task preferences: %v", err)
+	}
+	return bucket().Delete(slug)
+}
@@ -0,0 +1,56 @@
+package taskprefs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefs(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("HOME", t.TempDir())
+
+	p, err := Get("hello")
+	require.NoError(err)
+	require.True(p.IsEmpty())
+
+	want := Prefs{Output: "json", LogSinks: []string{"file:run.log"}}
+	require.NoError(Set("hello", want))
+	p, err = Get("hello")
+	require.NoError(err)
+	require.Equal(want, p)
+
+	// Setting empty preferences forgets them.
+	require.NoError(Set("hello", Prefs{}))
+	p, err = Get("hello")
+	require.NoError(err)
+	require.True(p.IsEmpty())
+
+	require.NoError(Set("hello", want))
+	require.NoError(Clear("hello"))
+	p, err = Get("hello")
+	require.NoError(err)
+	require.True(p.IsEmpty())
+}
+
+func TestLegacyPrefs(t *testing.T) {
+	require := require.New(t)
+	t.Setenv("HOME", t.TempDir())
+
+	want := Prefs{Output: "yaml"}
+	require.NoError(legacy().Set("hello", want))
+
+	p, err := Get("hello")
+	require.NoError(err)
+	require.Equal(want, p)
+
+	// The preferences moved out of the cache.
+	var moved Prefs
+	ok, err := bucket().Get("hello", &moved)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(want, moved)
+	ok, err = legacy().Get("hello", &moved)
+	require.NoError(err)
+	require.False(ok)
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -42,38 +43,41 @@ func WithParentPersistentPreRunE(f func(cmd *cobra.Command, args []string) error
 //
 // Which could be set as: `--since="2020-01-02T01:02:03"`
 //
-// TimeValue's are alias types of time.Time. You can convert safely via `time.Time(tv)`.
-type TimeValue time.Time
+// Timestamps without a time zone are read in Location when Time is called,
+// so that flags like --utc take effect wherever they appear on the command line.
+//
+// A duration, such as `--since=24h`, is read as that long before Time is called.
+type TimeValue struct {
+	s string
+}
 
 var _ pflag.Value = &TimeValue{}
 
 func (tv *TimeValue) Set(s string) error {
-	// Cobra doesn't appear to support quoted strings with spaces:
-	// https://github.com/spf13/cobra/issues/1114
-	// If fixed, we could start supporting time formats with spaces like "2006-01-02 15:04:05".
-	for _, format := range []string{
-		// Overall, we are roughly looking for RFC3339 timestamps with some leeway
-		// to make timestamps easier to specify.
-		//
-		// Local time zones:
-		"2006-01-02",
-		"2006-01-02T15:04",
-		"2006-01-02T15:04:05",
-		// Explicit time zones:
-		"2006-01-02T15:04Z07:00",
-		"2006-01-02T15:04Z0700",
-		"2006-01-02T15:04:05Z07:00", // time.RFC3339: copied for comparison with other formats
-		"2006-01-02T15:04:05Z0700",
-	} {
-		v, err := time.ParseInLocation(format, s, time.Now().Location())
-		if err == nil {
-			*tv = TimeValue(v)
-			return nil
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return errors.New(`expected a positive duration such as "24h"`)
 		}
+		tv.s = s
+		return nil
 	}
+	if _, err := ParseTime(s); err != nil {
+		return errors.New(`expected a duration such as "24h", or a timestamp formatted as "2021-04-16" or "2021-04-16T01:30:59"`)
+	}
+	tv.s = s
+	return nil
+}
 
-	// If we did not find a match, return a helpful error message:
-	return errors.New(`expected timestamp formatted as "2021-04-16" or "2021-04-16T01:30:59"`)
+// Time returns the time the flag was set to, or the zero time if unset.
+func (tv TimeValue) Time() time.Time {
+	if tv.s == "" {
+		return time.Time{}
+	}
+	if d, err := time.ParseDuration(tv.s); err == nil {
+		return time.Now().Add(-d)
+	}
+	t, _ := ParseTime(tv.s)
+	return t
 }
 
 func (tv *TimeValue) Type() string {
@@ -84,12 +88,7 @@ func (tv *TimeValue) String() string {
 	if tv == nil {
 		return ""
 	}
-	t := time.Time(*tv)
-	if t.IsZero() {
-		return ""
-	}
-
-	return t.Format(time.RFC3339)
+	return tv.s
 }
 
 // NewlineFileValue is a pflag.Value that can be used to parse a file with newline
@@ -121,3 +120,41 @@ func (tv *NewlineFileValue) Type() string {
 func (tv *NewlineFileValue) String() string {
 	return fmt.Sprintf("%v", *tv)
 }
+
+// LabelsValue is a pflag.Value that collects key=value labels from a flag
+// that may be repeated, such as `--label reason=incident-1234`.
+type LabelsValue map[string]string
+
+var _ pflag.Value = &LabelsValue{}
+
+func (lv *LabelsValue) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return errors.Errorf("invalid label %q: expected key=value", s)
+	}
+	if *lv == nil {
+		*lv = LabelsValue{}
+	}
+	(*lv)[strings.TrimSpace(parts[0])] = parts[1]
+	return nil
+}
+
+func (lv *LabelsValue) Type() string {
+	return "key=value"
+}
+
+func (lv *LabelsValue) String() string {
+	if lv == nil || len(*lv) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(*lv))
+	for k := range *lv {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + (*lv)[k]
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeValue(t *testing.T) {
+	t.Run("unset", func(t *testing.T) {
+		var tv TimeValue
+		require.True(t, tv.Time().IsZero())
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		var tv TimeValue
+		require.NoError(t, tv.Set("24h"))
+		want := time.Now().Add(-24 * time.Hour)
+		require.WithinDuration(t, want, tv.Time(), time.Second)
+		require.Equal(t, "24h", tv.String())
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		location := Location
+		Location = time.UTC
+		defer func() { Location = location }()
+
+		var tv TimeValue
+		require.NoError(t, tv.Set("2021-04-16T01:30:59"))
+		require.Equal(t, time.Date(2021, 4, 16, 1, 30, 59, 0, time.UTC), tv.Time().UTC())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, s := range []string{"-1h", "yesterday", "2021-13-01"} {
+			var tv TimeValue
+			require.Error(t, tv.Set(s), s)
+		}
+	})
+}
@@ -4,3 +4,10 @@ type ErrorExplained interface {
 	Error() string
 	ExplainError() string
 }
+
+// ErrorExitCode is implemented by errors that have already been reported,
+// such as by a plugin or a child process, and only set the CLI's exit code.
+type ErrorExitCode interface {
+	Error() string
+	ExitCode() int
+}
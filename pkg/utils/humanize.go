@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dustin/go-humanize"
+)
+
+// FormatDuration formats d for people to read: milliseconds under a second,
+// seconds under a minute, minutes and seconds under an hour, and hours and
+// minutes beyond. Zero trailing units are left off, so a minute is "1m"
+// rather than "1m0s". Negative durations are formatted the same, with a
+// leading "-".
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		if d == math.MinInt64 {
+			// -d would overflow.
+			d++
+		}
+		return "-" + FormatDuration(-d)
+	}
+	if d >= time.Second {
+		d = d.Round(time.Second)
+	}
+	if d >= time.Hour {
+		d = d.Round(time.Minute)
+	}
+
+	switch {
+	case d == 0:
+		return "0s"
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return withRemainder(int(d.Minutes()), "m", int(d.Seconds())%60, "s")
+	default:
+		return withRemainder(int(d.Hours()), "h", int(d.Minutes())%60, "m")
+	}
+}
+
+func withRemainder(n int, unit string, rem int, remUnit string) string {
+	if rem == 0 {
+		return fmt.Sprintf("%d%s", n, unit)
+	}
+	return fmt.Sprintf("%d%s%d%s", n, unit, rem, remUnit)
+}
+
+// FormatBytes formats a size in bytes for people to read, such as "12 MB".
+func FormatBytes(n int64) string {
+	if n < 0 {
+		return "-" + humanize.Bytes(uint64(-n))
+	}
+	return humanize.Bytes(uint64(n))
+}
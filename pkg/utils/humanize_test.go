@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatDuration(t *testing.T) {
+	for _, test := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0s"},
+		{500 * time.Microsecond, "0ms"},
+		{250 * time.Millisecond, "250ms"},
+		{time.Second, "1s"},
+		{1500 * time.Millisecond, "2s"},
+		{59 * time.Second, "59s"},
+		{59*time.Second + 600*time.Millisecond, "1m"},
+		{90 * time.Second, "1m30s"},
+		{time.Hour, "1h"},
+		{time.Hour + 29*time.Second, "1h"},
+		{time.Hour + 30*time.Second, "1h1m"},
+		{26*time.Hour + 5*time.Minute, "26h5m"},
+		{-250 * time.Millisecond, "-250ms"},
+		{-90 * time.Second, "-1m30s"},
+		{-2 * time.Hour, "-2h"},
+		{math.MinInt64, "-2562047h47m"},
+	} {
+		t.Run(test.d.String(), func(t *testing.T) {
+			require.Equal(t, test.want, FormatDuration(test.d))
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	for _, test := range []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{999, "999 B"},
+		{12 * 1000 * 1000, "12 MB"},
+		{-1500, "-1.5 kB"},
+	} {
+		require.Equal(t, test.want, FormatBytes(test.n))
+	}
+}
@@ -2,21 +2,103 @@ package utils
 
 import (
 	"os"
+	"sync"
 
 	"github.com/AlecAivazis/survey/v2"
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/mattn/go-isatty"
 	"github.com/pkg/errors"
 )
 
+// ErrNoInput is returned by prompts when there is no terminal to prompt on,
+// or prompting was turned off with SetNoInput.
+var ErrNoInput = errors.New("cannot prompt for input: no terminal is attached or --no-input is set")
+
+// noInput turns off all prompts. See SetNoInput.
+var noInput bool
+
+// SetNoInput turns prompting off or on. With prompting off, commands use
+// their defaults or fail instead of asking questions, as they do when they
+// aren't run from a terminal.
+func SetNoInput(enabled bool) {
+	noInput = enabled
+}
+
+// tty is the controlling terminal, opened the first time it's needed.
+var tty struct {
+	once sync.Once
+	file *os.File
+}
+
+// isTerminal and openTTY are replaced in tests.
+var (
+	isTerminal = isatty.IsTerminal
+	openTTY    = func() (*os.File, error) { return os.Open("/dev/tty") }
+)
+
+// promptInput returns the terminal that prompts read answers from, or nil if
+// there isn't one. Prompts are written to stderr, which must be a terminal.
+// Answers are read from stdin if it's a terminal, and otherwise from the
+// controlling terminal, so that piping data into a command doesn't stop it
+// from asking questions.
+func promptInput() terminal.FileReader {
+	if noInput || !isTerminal(os.Stderr.Fd()) {
+		return nil
+	}
+	if StdinIsTerminal() {
+		return os.Stdin
+	}
+	tty.once.Do(func() {
+		// There's no controlling terminal on Windows, nor when the CLI
+		// runs without one, such as in CI.
+		f, err := openTTY()
+		if err != nil {
+			logger.Debug("Opening /dev/tty: %v", err)
+			return
+		}
+		tty.file = f
+	})
+	if tty.file == nil {
+		return nil
+	}
+	return tty.file
+}
+
+// CanPrompt reports whether the user can be prompted: prompting isn't turned
+// off, stderr is a terminal, and answers can be read from stdin or from the
+// controlling terminal.
+func CanPrompt() bool {
+	return promptInput() != nil
+}
+
+// StdinIsTerminal reports whether stdin is a terminal, rather than input
+// piped or redirected into the command.
+func StdinIsTerminal() bool {
+	return isTerminal(os.Stdin.Fd())
+}
+
+// AskOne asks a question with survey, writing to stderr and reading from the
+// terminal described in CanPrompt. Spinners are paused while the question is
+// asked. It returns ErrNoInput if the user can't be prompted.
+func AskOne(p survey.Prompt, response interface{}, opts ...survey.AskOpt) error {
+	in := promptInput()
+	if in == nil {
+		return ErrNoInput
+	}
+	resume := logger.PauseLoaders()
+	defer resume()
+	return survey.AskOne(p, response, append(opts, survey.WithStdio(in, os.Stderr, os.Stderr))...)
+}
+
 func Confirm(question string) (bool, error) {
 	ok := true
-	if err := survey.AskOne(
+	if err := AskOne(
 		&survey.Confirm{
 			Message: question,
 			Default: ok,
 		},
 		&ok,
-		survey.WithStdio(os.Stdin, os.Stderr, os.Stderr),
 	); err != nil {
 		return false, errors.Wrap(err, "confirming")
 	}
@@ -34,8 +116,3 @@ func ConfirmWithAssumptions(question string, assumeYes, assumeNo bool) (bool, er
 
 	return Confirm(question)
 }
-
-// CanPrompt checks that both stdin and stderr are terminal
-func CanPrompt() bool {
-	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stderr.Fd())
-}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTerminal makes the standard streams in terminals report as terminals,
+// and openTTY open file, or fail if it's empty.
+func fakeTerminal(t *testing.T, terminals []*os.File, file string) {
+	oldIsTerminal, oldOpenTTY := isTerminal, openTTY
+	resetTTY := func() {
+		if tty.file != nil {
+			tty.file.Close()
+		}
+		tty.once = sync.Once{}
+		tty.file = nil
+	}
+	t.Cleanup(func() {
+		isTerminal, openTTY = oldIsTerminal, oldOpenTTY
+		resetTTY()
+		SetNoInput(false)
+	})
+	resetTTY()
+
+	isTerminal = func(fd uintptr) bool {
+		for _, f := range terminals {
+			if f.Fd() == fd {
+				return true
+			}
+		}
+		return false
+	}
+	openTTY = func() (*os.File, error) {
+		if file == "" {
+			return nil, errors.New("no controlling terminal")
+		}
+		return os.Open(file)
+	}
+}
+
+func TestPromptInput(t *testing.T) {
+	ttyFile := filepath.Join(t.TempDir(), "tty")
+	require.NoError(t, ioutil.WriteFile(ttyFile, nil, 0600))
+
+	t.Run("terminal", func(t *testing.T) {
+		fakeTerminal(t, []*os.File{os.Stdin, os.Stderr}, "")
+		require.Equal(t, os.Stdin, promptInput())
+		require.True(t, CanPrompt())
+	})
+
+	t.Run("--no-input", func(t *testing.T) {
+		fakeTerminal(t, []*os.File{os.Stdin, os.Stderr}, ttyFile)
+		SetNoInput(true)
+		require.False(t, CanPrompt())
+		var answer string
+		require.Equal(t, ErrNoInput, AskOne(&survey.Input{Message: "Name:"}, &answer))
+	})
+
+	t.Run("stderr redirected", func(t *testing.T) {
+		fakeTerminal(t, []*os.File{os.Stdin}, ttyFile)
+		require.False(t, CanPrompt())
+	})
+
+	t.Run("piped stdin reads the controlling terminal", func(t *testing.T) {
+		fakeTerminal(t, []*os.File{os.Stderr}, ttyFile)
+		require.False(t, StdinIsTerminal())
+		in := promptInput()
+		require.NotNil(t, in)
+		require.Equal(t, ttyFile, in.(*os.File).Name())
+	})
+
+	t.Run("piped stdin without a controlling terminal", func(t *testing.T) {
+		fakeTerminal(t, []*os.File{os.Stderr}, "")
+		require.False(t, CanPrompt())
+		var answer string
+		require.Equal(t, ErrNoInput, AskOne(&survey.Input{Message: "Name:"}, &answer))
+	})
+}
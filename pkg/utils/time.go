@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Location is the time zone that times are shown in, and that times typed
+// without a zone are read in. It is the local time zone unless --utc is set.
+var Location = time.Local
+
+// FormatTime formats t as RFC3339 in Location.
+func FormatTime(t time.Time) string {
+	return t.In(Location).Format(time.RFC3339)
+}
+
+// ParseTime parses a timestamp typed by a user. Timestamps without a time
+// zone are read in Location.
+func ParseTime(s string) (time.Time, error) {
+	// Cobra doesn't appear to support quoted strings with spaces:
+	// https://github.com/spf13/cobra/issues/1114
+	// If fixed, we could start supporting time formats with spaces like "2006-01-02 15:04:05".
+	for _, format := range []string{
+		// Overall, we are roughly looking for RFC3339 timestamps with some leeway
+		// to make timestamps easier to specify.
+		//
+		// Local time zones:
+		"2006-01-02",
+		"2006-01-02T15:04",
+		"2006-01-02T15:04:05",
+		// Explicit time zones:
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02T15:04Z0700",
+		"2006-01-02T15:04:05Z07:00", // time.RFC3339: copied for comparison with other formats
+		"2006-01-02T15:04:05Z0700",
+	} {
+		v, err := time.ParseInLocation(format, s, Location)
+		if err == nil {
+			return v, nil
+		}
+	}
+
+	// If we did not find a match, return a helpful error message:
+	return time.Time{}, errors.New(`expected timestamp formatted as "2021-04-16" or "2021-04-16T01:30:59"`)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/airplanedev/cli/pkg/analytics"
+	"github.com/airplanedev/cli/pkg/api"
 	"github.com/airplanedev/cli/pkg/logger"
 	"github.com/airplanedev/cli/pkg/version"
 )
@@ -31,7 +32,7 @@ func CheckLatest(ctx context.Context) {
 		return
 	}
 	// Assumes not matching latest means you are behind:
-	if latest != "v"+version.Get() {
+	if latest != "v"+version.Get() && !logger.IsPlain() {
 		logger.Warning("A newer version of the Airplane CLI is available: %s", latest)
 		logger.Suggest(
 			"Visit the docs for upgrade instructions:",
@@ -48,7 +49,7 @@ func getLatest(ctx context.Context) (string, error) {
 	}
 	req.Header.Add("Accept", "application/vnd.github.v3+json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := api.DirectClient.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,75 @@
+// Package workspace manages the scratch directories that the CLI creates
+// while packaging, cloning and building tasks.
+//
+// All scratch directories live under a single managed directory inside of
+// the CLI state directory (~/.airplane/workspace) rather than the system temp
+// directory. This lets the CLI find and clean up trees that leaked when a
+// previous invocation crashed or was killed before it could clean up after
+// itself.
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/airplanedev/cli/pkg/conf"
+	"github.com/pkg/errors"
+)
+
+// DefaultMaxAge is the age after which an unused workspace is considered stale.
+const DefaultMaxAge = 24 * time.Hour
+
+// Dir returns the managed workspace directory.
+func Dir() string {
+	return filepath.Join(conf.Dir(), "workspace")
+}
+
+// TempDir creates a new, uniquely named directory within the managed
+// workspace and returns its path. The name of the directory begins with prefix.
+//
+// Every call returns a fresh directory, so concurrent builds (within a single
+// deploy or across CLI processes) never share a tree. Callers are responsible
+// for removing the directory once they are done with it.
+func TempDir(prefix string) (string, error) {
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return "", errors.Wrap(err, "creating workspace directory")
+	}
+
+	dir, err := ioutil.TempDir(Dir(), prefix)
+	if err != nil {
+		return "", errors.Wrap(err, "creating temporary directory")
+	}
+
+	return dir, nil
+}
+
+// GC removes workspace entries that have not been modified within maxAge.
+//
+// It returns the paths that were removed. A missing workspace directory is
+// not an error.
+func GC(maxAge time.Duration) ([]string, error) {
+	entries, err := ioutil.ReadDir(Dir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "reading workspace directory")
+	}
+
+	var removed []string
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if entry.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(Dir(), entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			return removed, errors.Wrapf(err, "removing %s", path)
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}